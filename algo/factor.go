@@ -0,0 +1,83 @@
+package algo
+
+import (
+	"errors"
+	"sort"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// factorAlgo scores the cross section with a factor model and creates the signals of the
+// target weights of a long-short portfolio.
+type factorAlgo struct {
+	gbt.Algo
+	model     *gbt.FactorModel
+	portfolio *gbt.LongShort
+}
+
+// FactorLongShort scores the snapshot of the universe with the factor model once all data
+// events of a time are streamed and creates a signal for each symbol of the long-short
+// portfolio, with the target weight as strength of the signal. A position of a symbol which
+// is not part of the portfolio anymore is exited. The orders are sized to the target weights
+// by the size handler WeightSize. On any other data event the algo returns false.
+func FactorLongShort(model *gbt.FactorModel, portfolio *gbt.LongShort) gbt.AlgoHandler {
+	return &factorAlgo{model: model, portfolio: portfolio}
+}
+
+// Run runs the algo.
+func (a *factorAlgo) Run(s gbt.StrategyHandler) (bool, error) {
+	data, ok := s.Data()
+	if !ok {
+		return false, errors.New("no data to score")
+	}
+	event, _ := s.Event()
+	if !crossSectionComplete(data, event) {
+		return false, nil
+	}
+
+	snapshot := gbt.Snapshot(data)
+	scores, err := a.model.Scores(snapshot, data)
+	if err != nil {
+		return false, err
+	}
+	weights, err := a.portfolio.Weights(scores, data)
+	if err != nil {
+		return false, err
+	}
+
+	var signals []gbt.SignalEvent
+	signal := func(symbol string, d gbt.Direction, weight float64) {
+		e := &gbt.Event{}
+		e.SetTime(event.Time())
+		e.SetSymbol(symbol)
+		signal := &gbt.Signal{Event: *e}
+		signal.SetDirection(d)
+		signal.SetStrength(weight)
+		signals = append(signals, signal)
+	}
+
+	portfolio, _ := s.Portfolio()
+	for _, symbol := range invested(portfolio, snapshot) {
+		if _, ok := weights[symbol]; !ok {
+			signal(symbol, gbt.EXT, 0)
+		}
+	}
+
+	symbols := make([]string, 0, len(weights))
+	for symbol := range weights {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		d := gbt.BOT
+		if weights[symbol] < 0 {
+			d = gbt.SLD
+		}
+		signal(symbol, d, weights[symbol])
+	}
+
+	if err := s.AddSignal(signals...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+package algo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestFactorLongShort(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2018-07-02")
+	bar := func(symbol string, value float64) *gbt.Bar {
+		event := &gbt.Event{}
+		event.SetTime(day)
+		event.SetSymbol(symbol)
+		return &gbt.Bar{Event: *event, Metric: gbt.Metric{"VALUE": value}}
+	}
+
+	data := &gbt.Data{}
+	data.SetStream([]gbt.DataEvent{bar("AAA", 3), bar("BBB", 2), bar("CCC", 1)})
+
+	strategy := &gbt.Strategy{}
+	strategy.SetData(data)
+	strategy.SetPortfolio(positions{qty: map[string]int64{"BBB": 100, "OLD": 50}})
+	algo := FactorLongShort(
+		&gbt.FactorModel{Factors: []gbt.Factor{gbt.MetricFactor("VALUE")}},
+		&gbt.LongShort{Long: 1, Short: 1, DollarNeutral: true},
+	)
+
+	// the algo waits for the last event of the day
+	for i := 0; i < 3; i++ {
+		event, _ := data.Next()
+		strategy.SetEvent(event)
+		ok, err := algo.Run(strategy)
+		if err != nil || ok != (i == 2) {
+			t.Errorf("FactorLongShort(): expected to run on the last event of the day only, actual %v %v at event %d", ok, err, i)
+		}
+	}
+
+	names := map[gbt.Direction]string{gbt.BOT: "BOT", gbt.SLD: "SLD", gbt.EXT: "EXT"}
+	result := []string{}
+	signals, _ := strategy.Signals()
+	for _, s := range signals {
+		result = append(result, s.Symbol()+" "+names[s.Direction()])
+		if w, ok := s.(gbt.Weighter); !ok || math.Abs(w.Strength()) != map[string]float64{"AAA": 0.5, "CCC": 0.5}[s.Symbol()] {
+			t.Errorf("FactorLongShort(): expected the target weight as strength of %v, actual %v", s.Symbol(), w.Strength())
+		}
+	}
+	exp := []string{"BBB EXT", "OLD EXT", "AAA BOT", "CCC SLD"}
+	if !reflect.DeepEqual(result, exp) {
+		t.Errorf("FactorLongShort(): expected signals %v, actual %v", exp, result)
+	}
+}
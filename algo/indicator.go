@@ -26,15 +26,15 @@ func (a *smaAlgo) Run(s gbt.StrategyHandler) (bool, error) {
 	symbol := event.Symbol()
 
 	// prepare list of floats
-	list := data.List(symbol)
+	window := data.Window(symbol, a.period)
 	var values []float64
 
-	if len(list) < a.period {
+	if len(window) < a.period {
 		return false, fmt.Errorf("invalid value length for indicator sma")
 	}
 
-	for i := 0; i < a.period; i++ {
-		values = append(values, list[len(list)-i-1].Price())
+	for _, event := range window {
+		values = append(values, event.Price())
 	}
 
 	// calculate SMA
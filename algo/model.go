@@ -0,0 +1,56 @@
+package algo
+
+import (
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// predictAlgo runs a model on a feature vector of the event metrics and creates a signal.
+type predictAlgo struct {
+	gbt.Algo
+	model       gbt.Model
+	features    []string
+	long, short float64
+	prediction  float64
+}
+
+// Predict runs the model on the metrics named by features of each data event, e.g. indicator values
+// calculated by preceding algos. The first output of the model is saved as PREDICTION metric.
+// A prediction of at least long creates a long signal, of at most short a short signal,
+// otherwise the algo returns false.
+func Predict(model gbt.Model, features []string, long, short float64) gbt.AlgoHandler {
+	return &predictAlgo{model: model, features: features, long: long, short: short}
+}
+
+// Run runs the algo.
+func (a *predictAlgo) Run(s gbt.StrategyHandler) (bool, error) {
+	event, _ := s.Event()
+
+	vector, err := gbt.FeatureVector(event, a.features...)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := a.model.Predict(vector)
+	if err != nil || len(out) == 0 {
+		return false, err
+	}
+	a.prediction = out[0]
+	event.Add("PREDICTION", a.prediction)
+
+	var direction string
+	switch {
+	case a.prediction >= a.long:
+		direction = "long"
+	case a.prediction <= a.short:
+		direction = "short"
+	default:
+		return false, nil
+	}
+
+	return CreateSignal(direction).Run(s)
+}
+
+// Value returns the last prediction of this Algo.
+func (a *predictAlgo) Value() float64 {
+	return a.prediction
+}
@@ -0,0 +1,44 @@
+package algo
+
+import (
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestPredictIntegration(t *testing.T) {
+	model := &gbt.LinearModel{Weights: []float64{1}}
+
+	var testCases = []struct {
+		msg    string
+		value  float64
+		expOk  bool
+		expDir gbt.Direction
+	}{
+		{"prediction above long threshold", 0.8, true, gbt.BOT},
+		{"prediction below short threshold", -0.8, true, gbt.SLD},
+		{"prediction between thresholds", 0.1, false, gbt.HLD},
+	}
+
+	for _, tc := range testCases {
+		event := testHelperMockData([]string{"2018-07-01"})[0].(*gbt.Bar)
+		event.Metric = gbt.Metric{"SCORE": tc.value}
+
+		strategy := &gbt.Strategy{}
+		strategy.SetEvent(event)
+
+		ok, err := Predict(model, []string{"SCORE"}, 0.5, -0.5).Run(strategy)
+		if ok != tc.expOk || err != nil {
+			t.Errorf("%v: Predict() expected %v, actual %v %v", tc.msg, tc.expOk, ok, err)
+			continue
+		}
+		if prediction, _ := event.Get("PREDICTION"); prediction != tc.value {
+			t.Errorf("%v: Predict() expected PREDICTION metric %v, actual %v", tc.msg, tc.value, prediction)
+		}
+
+		signals, _ := strategy.Signals()
+		if tc.expOk && (len(signals) != 1 || signals[0].Direction() != tc.expDir) {
+			t.Errorf("%v: Predict() expected signal %v, actual %#v", tc.msg, tc.expDir, signals)
+		}
+	}
+}
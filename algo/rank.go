@@ -0,0 +1,136 @@
+package algo
+
+import (
+	"errors"
+	"sort"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// selectRankedAlgo ranks the cross section of the data and selects the top and bottom symbols.
+type selectRankedAlgo struct {
+	gbt.Algo
+	metric      string
+	top, bottom int
+}
+
+// SelectRanked ranks the snapshot of the universe by a metric once all data events of a time
+// are streamed, e.g. a momentum indicator calculated by preceding algos, and creates long
+// signals for the top N and short signals for the bottom N symbols. A position of a symbol
+// which is not selected anymore is exited, a position of the selected direction is kept.
+// On any other data event the algo returns false.
+func SelectRanked(metric string, top, bottom int) gbt.AlgoHandler {
+	return &selectRankedAlgo{metric: metric, top: top, bottom: bottom}
+}
+
+// Run runs the algo.
+func (a *selectRankedAlgo) Run(s gbt.StrategyHandler) (bool, error) {
+	data, ok := s.Data()
+	if !ok {
+		return false, errors.New("no data to rank")
+	}
+	event, _ := s.Event()
+
+	if !crossSectionComplete(data, event) {
+		return false, nil
+	}
+
+	ranked := gbt.Rank(gbt.Snapshot(data), a.metric)
+	direction := make(map[string]gbt.Direction)
+	for i, e := range ranked {
+		switch {
+		case i < a.top:
+			direction[e.Symbol()] = gbt.BOT
+		case i >= len(ranked)-a.bottom:
+			direction[e.Symbol()] = gbt.SLD
+		}
+	}
+
+	var selected []string
+	for symbol := range direction {
+		selected = append(selected, symbol)
+	}
+	sort.Strings(selected)
+
+	portfolio, _ := s.Portfolio()
+	var signals []gbt.SignalEvent
+	signal := func(symbol string, d gbt.Direction) {
+		e := &gbt.Event{}
+		e.SetTime(event.Time())
+		e.SetSymbol(symbol)
+		signal := &gbt.Signal{Event: *e}
+		signal.SetDirection(d)
+		signals = append(signals, signal)
+	}
+
+	// exit the positions which are not selected or of the other direction
+	for _, symbol := range invested(portfolio, ranked) {
+		d, ok := direction[symbol]
+		if !ok || (d == gbt.BOT && !isLong(portfolio, symbol)) || (d == gbt.SLD && !isShort(portfolio, symbol)) {
+			signal(symbol, gbt.EXT)
+		}
+	}
+	for _, symbol := range selected {
+		d := direction[symbol]
+		if (d == gbt.BOT && !isLong(portfolio, symbol)) || (d == gbt.SLD && !isShort(portfolio, symbol)) {
+			signal(symbol, d)
+		}
+	}
+
+	if err := s.AddSignal(signals...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// crossSectionComplete checks if the event is the last data event of its time, so the
+// snapshot holds the complete cross section of the time.
+func crossSectionComplete(data gbt.DataHandler, event gbt.DataEvent) bool {
+	stream := data.Stream()
+	return len(stream) == 0 || stream[0].Time().After(event.Time())
+}
+
+// invested returns the sorted symbols of the open positions of the portfolio, of the ranked
+// symbols if the portfolio does not list its holdings.
+func invested(portfolio gbt.PortfolioHandler, ranked []gbt.DataEvent) []string {
+	if portfolio == nil {
+		return nil
+	}
+
+	var symbols []string
+	if h, ok := portfolio.(interface {
+		Holdings() map[string]gbt.Position
+	}); ok {
+		for symbol := range h.Holdings() {
+			if _, ok := portfolio.IsInvested(symbol); ok {
+				symbols = append(symbols, symbol)
+			}
+		}
+	} else {
+		for _, e := range ranked {
+			if _, ok := portfolio.IsInvested(e.Symbol()); ok {
+				symbols = append(symbols, e.Symbol())
+			}
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// isLong checks for a long position of the symbol.
+func isLong(portfolio gbt.PortfolioHandler, symbol string) bool {
+	if portfolio == nil {
+		return false
+	}
+	_, ok := portfolio.IsLong(symbol)
+	return ok
+}
+
+// isShort checks for a short position of the symbol.
+func isShort(portfolio gbt.PortfolioHandler, symbol string) bool {
+	if portfolio == nil {
+		return false
+	}
+	_, ok := portfolio.IsShort(symbol)
+	return ok
+}
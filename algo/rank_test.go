@@ -0,0 +1,97 @@
+package algo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// positions is a portfolio with fixed positions, a positive quantity is long.
+type positions struct {
+	gbt.PortfolioHandler
+	qty map[string]int64
+}
+
+func (p positions) IsInvested(symbol string) (gbt.Position, bool) {
+	return gbt.Position{}, p.qty[symbol] != 0
+}
+
+func (p positions) IsLong(symbol string) (gbt.Position, bool) {
+	return gbt.Position{}, p.qty[symbol] > 0
+}
+
+func (p positions) IsShort(symbol string) (gbt.Position, bool) {
+	return gbt.Position{}, p.qty[symbol] < 0
+}
+
+func (p positions) Holdings() map[string]gbt.Position {
+	holdings := make(map[string]gbt.Position)
+	for symbol := range p.qty {
+		holdings[symbol] = gbt.Position{}
+	}
+	return holdings
+}
+
+func TestSelectRanked(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2018-07-02")
+	bar := func(symbol string, t time.Time, momentum float64) *gbt.Bar {
+		event := &gbt.Event{}
+		event.SetTime(t)
+		event.SetSymbol(symbol)
+		return &gbt.Bar{Event: *event, Metric: gbt.Metric{"MOM": momentum}}
+	}
+
+	// testCases is a table for testing the signals of a selection
+	var testCases = []struct {
+		msg       string
+		positions map[string]int64
+		top       int
+		bottom    int
+		exp       []string
+	}{
+		{"top and bottom without positions:", nil, 1, 1, []string{"BBB BOT", "DDD SLD"}},
+		{"more selected than ranked:", nil, 3, 3, []string{"AAA BOT", "BBB BOT", "CCC BOT", "DDD SLD"}},
+		{"keep the selected positions:", map[string]int64{"BBB": 100, "DDD": -100}, 1, 1, []string{}},
+		{"exit positions not selected:", map[string]int64{"AAA": 100, "OLD": -100}, 1, 0, []string{"AAA EXT", "OLD EXT", "BBB BOT"}},
+		{"exit positions of the other direction:", map[string]int64{"BBB": -100}, 1, 0, []string{"BBB EXT", "BBB BOT"}},
+	}
+
+	for _, tc := range testCases {
+		data := &gbt.Data{}
+		data.SetStream([]gbt.DataEvent{
+			bar("AAA", day, 0.1), bar("BBB", day, 0.3), bar("CCC", day, 0.0), bar("DDD", day, -0.2),
+			bar("AAA", day.AddDate(0, 0, 1), 0.5),
+		})
+
+		strategy := &gbt.Strategy{}
+		strategy.SetData(data)
+		strategy.SetPortfolio(positions{qty: tc.positions})
+		algo := SelectRanked("MOM", tc.top, tc.bottom)
+
+		// the algo waits for the last event of the day
+		for i := 0; i < 4; i++ {
+			event, _ := data.Next()
+			strategy.SetEvent(event)
+			ok, err := algo.Run(strategy)
+			if err != nil || ok != (i == 3) {
+				t.Errorf("%v SelectRanked(): expected to run on the last event of the day only, actual %v %v at event %d", tc.msg, ok, err, i)
+			}
+		}
+
+		names := map[gbt.Direction]string{gbt.BOT: "BOT", gbt.SLD: "SLD", gbt.EXT: "EXT"}
+		result := []string{}
+		signals, _ := strategy.Signals()
+		for _, s := range signals {
+			result = append(result, s.Symbol()+" "+names[s.Direction()])
+		}
+		if !reflect.DeepEqual(result, tc.exp) {
+			t.Errorf("%v SelectRanked(): expected signals %v, actual %v", tc.msg, tc.exp, result)
+		}
+	}
+
+	if _, err := SelectRanked("MOM", 1, 1).Run(&gbt.Strategy{}); err == nil {
+		t.Errorf("SelectRanked(): expected an error without data")
+	}
+}
@@ -0,0 +1,97 @@
+//go:build apache
+// +build apache
+
+package arrow
+
+// The tests of the apache tag read the golden file with the Go implementation of Apache Arrow,
+// a reader of the IPC format independent of the writer of this package, which is not a
+// dependency of the module, e.g.
+//
+//	go get github.com/apache/arrow-go/v18
+//	go test -tags apache ./arrow
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apache "github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+func TestGoldenFileApache(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "golden.arrow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := ipc.NewFileReader(f, ipc.WithAllocator(memory.NewGoAllocator()))
+	if err != nil {
+		t.Fatalf("NewFileReader(): unexpected error %v", err)
+	}
+	defer r.Close()
+	if r.NumRecords() != 1 {
+		t.Fatalf("NumRecords(): expected a record batch, actual %v", r.NumRecords())
+	}
+
+	// testCases is a table for testing the schema of the golden file, only a column with
+	// nulls is nullable
+	var testCases = []struct {
+		name     string
+		typ      apache.DataType
+		nullable bool
+	}{
+		{"time", &apache.TimestampType{Unit: apache.Nanosecond, TimeZone: "UTC"}, true},
+		{"symbol", apache.BinaryTypes.String, false},
+		{"price", apache.PrimitiveTypes.Float64, false},
+		{"id", apache.PrimitiveTypes.Int64, false},
+	}
+
+	schema := r.Schema()
+	if len(schema.Fields()) != len(testCases) {
+		t.Fatalf("Schema(): expected %v fields, actual %v", len(testCases), schema)
+	}
+	for i, tc := range testCases {
+		field := schema.Field(i)
+		if field.Name != tc.name || !apache.TypeEqual(field.Type, tc.typ) || field.Nullable != tc.nullable {
+			t.Errorf("Schema(): expected field %v of type %v, actual %v", tc.name, tc.typ, field)
+		}
+	}
+
+	rec, err := r.Record(0)
+	if err != nil {
+		t.Fatalf("Record(): unexpected error %v", err)
+	}
+	if rec.NumRows() != 3 {
+		t.Fatalf("Record(): expected 3 rows, actual %v", rec.NumRows())
+	}
+
+	day := time.Date(2020, 1, 2, 8, 0, 0, 0, time.UTC)
+	times, ok := rec.Column(0).(*array.Timestamp)
+	if !ok || times.NullN() != 1 || !times.IsNull(1) ||
+		times.Value(0) != apache.Timestamp(day.UnixNano()) || times.Value(2) != apache.Timestamp(day.Add(time.Hour).UnixNano()) {
+		t.Errorf("Record(): unexpected time column %v", rec.Column(0))
+	}
+
+	symbols, ok := rec.Column(1).(*array.String)
+	if !ok || symbols.Value(0) != "TEST.DE" || symbols.Value(1) != "" || symbols.Value(2) != "ÄÖÜ" {
+		t.Errorf("Record(): unexpected symbol column %v", rec.Column(1))
+	}
+
+	// NaN is not equal to itself, the prices are compared by their bits
+	prices, ok := rec.Column(2).(*array.Float64)
+	if !ok || math.Float64bits(prices.Value(0)) != math.Float64bits(1.5) || !math.IsNaN(prices.Value(1)) ||
+		math.Float64bits(prices.Value(2)) != math.Float64bits(-2) {
+		t.Errorf("Record(): unexpected price column %v", rec.Column(2))
+	}
+
+	ids, ok := rec.Column(3).(*array.Int64)
+	if !ok || ids.Value(0) != 1 || ids.Value(1) != -2 || ids.Value(2) != 3 {
+		t.Errorf("Record(): unexpected id column %v", rec.Column(3))
+	}
+}
@@ -0,0 +1,280 @@
+// Package arrow exports the results of backtests, the equity curve, the trades and the bar
+// data, as Apache Arrow IPC files, also known as Feather version 2. The files are loaded
+// without parsing into pandas, polars or any other Arrow library, which is much faster
+// than csv for large result sets, e.g.
+//
+//	if err := arrow.EquityCurve(stats.EquityCurve()).WriteFile("equity.arrow"); err != nil {
+//		return err
+//	}
+//
+// and in Python
+//
+//	equity = pandas.read_feather("equity.arrow")
+//
+// The files are written without compression in a single record batch by the standard library.
+package arrow
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// magic starts and ends an Arrow IPC file.
+const magic = "ARROW1"
+
+// the metadata version V5, the message headers and the types of the Arrow format
+const (
+	metadataVersion = 4
+
+	headerSchema      = 1
+	headerRecordBatch = 3
+
+	typeInt           = 2
+	typeFloatingPoint = 3
+	typeUtf8          = 5
+	typeTimestamp     = 10
+
+	precisionDouble = 2
+	unitNanosecond  = 3
+)
+
+// column is a column of a table.
+type column struct {
+	name     string
+	typeID   byte
+	typ      object
+	nulls    int
+	validity []byte   // the validity bitmap, nil without nulls
+	buffers  [][]byte // the buffers of the values
+}
+
+// Table is a table of named columns of the same length, which is written as Arrow IPC file.
+type Table struct {
+	columns []column
+	rows    int
+	err     error
+}
+
+// NewTable creates an empty table.
+func NewTable() *Table {
+	return &Table{}
+}
+
+// Rows returns the number of rows of the table.
+func (t *Table) Rows() int {
+	return t.rows
+}
+
+// add adds a column of n rows, an error is returned on write.
+func (t *Table) add(n int, c column) {
+	if t.err != nil {
+		return
+	}
+	if len(t.columns) > 0 && n != t.rows {
+		t.err = fmt.Errorf("column %s has %d rows, expected %d", c.name, n, t.rows)
+		return
+	}
+	for _, existing := range t.columns {
+		if existing.name == c.name {
+			t.err = fmt.Errorf("column %s already exists", c.name)
+			return
+		}
+	}
+	t.rows = n
+	t.columns = append(t.columns, c)
+}
+
+// AddInt64 adds a column of 64 bit integers.
+func (t *Table) AddInt64(name string, values []int64) {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], uint64(v))
+	}
+	typ := tableOf(int32Field(64), boolField(true))
+	t.add(len(values), column{name: name, typeID: typeInt, typ: typ, buffers: [][]byte{data}})
+}
+
+// AddFloat64 adds a column of 64 bit floats, NaN is kept as value.
+func (t *Table) AddFloat64(name string, values []float64) {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	typ := tableOf(int16Field(precisionDouble))
+	t.add(len(values), column{name: name, typeID: typeFloatingPoint, typ: typ, buffers: [][]byte{data}})
+}
+
+// AddString adds a column of utf-8 strings.
+func (t *Table) AddString(name string, values []string) {
+	offsets := make([]byte, 4*(len(values)+1))
+	var data []byte
+	for i, v := range values {
+		data = append(data, v...)
+		binary.LittleEndian.PutUint32(offsets[4*(i+1):], uint32(len(data)))
+	}
+	if len(data) > math.MaxInt32 {
+		t.err = fmt.Errorf("column %s exceeds 2 GB of strings", name)
+		return
+	}
+	t.add(len(values), column{name: name, typeID: typeUtf8, typ: tableOf(), buffers: [][]byte{offsets, data}})
+}
+
+// AddTime adds a column of timestamps in nanoseconds of UTC, a zero time is null.
+func (t *Table) AddTime(name string, values []time.Time) {
+	c := column{name: name, typeID: typeTimestamp, typ: tableOf(int16Field(unitNanosecond), refField(stringOf("UTC")))}
+	data := make([]byte, 8*len(values))
+	validity := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v.IsZero() {
+			c.nulls++
+			continue
+		}
+		validity[i/8] |= 1 << uint(i%8)
+		binary.LittleEndian.PutUint64(data[8*i:], uint64(v.UnixNano()))
+	}
+	if c.nulls > 0 {
+		c.validity = validity
+	}
+	c.buffers = [][]byte{data}
+	t.add(len(values), c)
+}
+
+// schema returns the schema of the table.
+func (t *Table) schema() object {
+	var fields []object
+	for _, c := range t.columns {
+		fields = append(fields, tableOf(
+			refField(stringOf(c.name)),
+			boolField(c.nulls > 0),
+			int8Field(int8(c.typeID)),
+			refField(c.typ),
+			field{},
+			refField(vectorOf()),
+		))
+	}
+	return tableOf(int16Field(0), refField(vectorOf(fields...)))
+}
+
+// recordBatch returns the header and the body of the record batch of the table.
+func (t *Table) recordBatch() (object, []byte) {
+	var nodes, buffers, body []byte
+	addBuffer := func(data []byte) {
+		buffers = appendInt64(buffers, int64(len(body)), int64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+	for _, c := range t.columns {
+		nodes = appendInt64(nodes, int64(t.rows), int64(c.nulls))
+		addBuffer(c.validity)
+		for _, data := range c.buffers {
+			addBuffer(data)
+		}
+	}
+	header := tableOf(
+		int64Field(int64(t.rows)),
+		refField(structsOf(len(nodes)/16, nodes)),
+		refField(structsOf(len(buffers)/16, buffers)),
+	)
+	return header, body
+}
+
+// Write writes the table as Arrow IPC file.
+func (t *Table) Write(w io.Writer) error {
+	if t.err != nil {
+		return t.err
+	}
+	if len(t.columns) == 0 {
+		return errors.New("could not write table without columns")
+	}
+
+	fw := &fileWriter{w: w}
+	fw.write([]byte(magic + "\x00\x00"))
+	fw.message(headerSchema, t.schema(), nil)
+	header, body := t.recordBatch()
+	offset := fw.n
+	metadata := fw.message(headerRecordBatch, header, body)
+	fw.write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+
+	// the block of the record batch: the offset, the metadata length padded to 8 bytes
+	// and the body length
+	block := appendInt64(nil, offset, int64(metadata), int64(len(body)))
+	footer := finish(tableOf(
+		int16Field(metadataVersion),
+		refField(t.schema()),
+		refField(structsOf(0, nil)),
+		refField(structsOf(1, block)),
+	))
+	fw.write(footer)
+	fw.write(appendInt32(nil, int32(len(footer))))
+	fw.write([]byte(magic))
+	return fw.err
+}
+
+// WriteFile writes the table into an Arrow IPC file.
+func (t *Table) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := t.Write(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fileWriter writes an Arrow IPC file and counts the bytes written.
+type fileWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (fw *fileWriter) write(p []byte) {
+	if fw.err != nil {
+		return
+	}
+	n, err := fw.w.Write(p)
+	fw.n += int64(n)
+	fw.err = err
+}
+
+// message writes an encapsulated message with its body and returns the length of its
+// metadata, the continuation marker, the length and the padded flatbuffer.
+func (fw *fileWriter) message(headerType byte, header object, body []byte) int {
+	metadata := finish(tableOf(
+		int16Field(metadataVersion),
+		int8Field(int8(headerType)),
+		refField(header),
+		int64Field(int64(len(body))),
+	))
+	fw.write([]byte{0xff, 0xff, 0xff, 0xff})
+	fw.write(appendInt32(nil, int32(len(metadata))))
+	fw.write(metadata)
+	fw.write(body)
+	return 8 + len(metadata)
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendInt64(b []byte, values ...int64) []byte {
+	for _, v := range values {
+		b = append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24), byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+	}
+	return b
+}
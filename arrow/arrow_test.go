@@ -0,0 +1,380 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fbTable is a table of a flatbuffer read by the tests. The reader checks the alignment of
+// the scalars and offsets like the verifier of the flatbuffers library.
+type fbTable struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+// rootTable returns the root table of a flatbuffer.
+func rootTable(t *testing.T, buf []byte) fbTable {
+	return fbTable{t: t, buf: buf}.deref(0)
+}
+
+// aligned checks the alignment of a position.
+func (tb fbTable) aligned(pos, align int) int {
+	if pos%align != 0 || pos < 0 || pos+align > len(tb.buf) {
+		tb.t.Fatalf("misaligned or invalid position %d for %d bytes in %d bytes", pos, align, len(tb.buf))
+	}
+	return pos
+}
+
+// deref returns the table of the offset at a position.
+func (tb fbTable) deref(at int) fbTable {
+	pos := at + int(binary.LittleEndian.Uint32(tb.buf[tb.aligned(at, 4):]))
+	return fbTable{t: tb.t, buf: tb.buf, pos: tb.aligned(pos, 4)}
+}
+
+// field returns the position of a field, -1 if absent.
+func (tb fbTable) field(id int) int {
+	vtable := tb.pos - int(int32(binary.LittleEndian.Uint32(tb.buf[tb.pos:])))
+	tb.aligned(vtable, 2)
+	size := int(binary.LittleEndian.Uint16(tb.buf[vtable:]))
+	if 4+2*id >= size {
+		return -1
+	}
+	offset := int(binary.LittleEndian.Uint16(tb.buf[vtable+4+2*id:]))
+	if offset == 0 {
+		return -1
+	}
+	return tb.pos + offset
+}
+
+func (tb fbTable) int8(id int) int8 {
+	if at := tb.field(id); at >= 0 {
+		return int8(tb.buf[at])
+	}
+	return 0
+}
+
+func (tb fbTable) int16(id int) int16 {
+	if at := tb.field(id); at >= 0 {
+		return int16(binary.LittleEndian.Uint16(tb.buf[tb.aligned(at, 2):]))
+	}
+	return 0
+}
+
+func (tb fbTable) int32(id int) int32 {
+	if at := tb.field(id); at >= 0 {
+		return int32(binary.LittleEndian.Uint32(tb.buf[tb.aligned(at, 4):]))
+	}
+	return 0
+}
+
+func (tb fbTable) int64(id int) int64 {
+	if at := tb.field(id); at >= 0 {
+		return int64(binary.LittleEndian.Uint64(tb.buf[tb.aligned(at, 8):]))
+	}
+	return 0
+}
+
+func (tb fbTable) table(id int) fbTable {
+	at := tb.field(id)
+	if at < 0 {
+		tb.t.Fatalf("missing table field %d", id)
+	}
+	return tb.deref(at)
+}
+
+// vector returns the position and length of a vector field.
+func (tb fbTable) vector(id int) (int, int) {
+	at := tb.field(id)
+	if at < 0 {
+		tb.t.Fatalf("missing vector field %d", id)
+	}
+	pos := at + int(binary.LittleEndian.Uint32(tb.buf[tb.aligned(at, 4):]))
+	return pos + 4, int(binary.LittleEndian.Uint32(tb.buf[tb.aligned(pos, 4):]))
+}
+
+func (tb fbTable) string(id int) string {
+	pos, n := tb.vector(id)
+	if tb.buf[pos+n] != 0 {
+		tb.t.Fatalf("string without null terminator")
+	}
+	return string(tb.buf[pos : pos+n])
+}
+
+func (tb fbTable) tables(id int) []fbTable {
+	pos, n := tb.vector(id)
+	var tables []fbTable
+	for i := 0; i < n; i++ {
+		tables = append(tables, tb.deref(pos+4*i))
+	}
+	return tables
+}
+
+// structs returns the vector of structs of int64 values.
+func (tb fbTable) structs(id, fields int) [][]int64 {
+	pos, n := tb.vector(id)
+	tb.aligned(pos, 8)
+	var structs [][]int64
+	for i := 0; i < n; i++ {
+		var s []int64
+		for j := 0; j < fields; j++ {
+			s = append(s, int64(binary.LittleEndian.Uint64(tb.buf[pos+8*(fields*i+j):])))
+		}
+		structs = append(structs, s)
+	}
+	return structs
+}
+
+// readColumn is a column read from an Arrow IPC file.
+type readColumn struct {
+	name     string
+	typeID   int8
+	typ      fbTable
+	nullable bool
+	nulls    int64
+	buffers  [][]byte
+}
+
+// readFile reads the columns of an Arrow IPC file of a single record batch, as an Arrow
+// reader does, from the footer.
+func readFile(t *testing.T, file []byte) (int64, []readColumn) {
+	if !bytes.HasPrefix(file, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(file, []byte("ARROW1")) {
+		t.Fatalf("missing magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-10:]))
+	footer := rootTable(t, file[len(file)-10-footerLen:len(file)-10])
+	if v := footer.int16(0); v != 4 {
+		t.Fatalf("expected metadata version V5, actual %v", v)
+	}
+
+	var columns []readColumn
+	schema := footer.table(1)
+	if e := schema.int16(0); e != 0 {
+		t.Fatalf("expected little endian")
+	}
+	for _, f := range schema.tables(1) {
+		if _, n := f.vector(5); n != 0 {
+			t.Fatalf("expected no children")
+		}
+		columns = append(columns, readColumn{name: f.string(0), nullable: f.int8(1) == 1, typeID: f.int8(2), typ: f.table(3)})
+	}
+
+	blocks := footer.structs(3, 3)
+	if len(blocks) != 1 {
+		t.Fatalf("expected a record batch, actual %v", blocks)
+	}
+	offset, metaLen, bodyLen := blocks[0][0], blocks[0][1]&0xffffffff, blocks[0][2]
+	if offset%8 != 0 || metaLen%8 != 0 || binary.LittleEndian.Uint32(file[offset:]) != 0xffffffff {
+		t.Fatalf("invalid block %v", blocks[0])
+	}
+	if size := int64(binary.LittleEndian.Uint32(file[offset+4:])); size+8 != metaLen {
+		t.Fatalf("metadata size %d of block length %d", size, metaLen)
+	}
+	message := rootTable(t, file[offset+8:offset+metaLen])
+	if message.int16(0) != 4 || message.int8(1) != 3 || message.int64(3) != bodyLen {
+		t.Fatalf("expected a record batch message of body length %d", bodyLen)
+	}
+	body := file[offset+metaLen : offset+metaLen+bodyLen]
+
+	batch := message.table(2)
+	nodes := batch.structs(1, 2)
+	buffers := batch.structs(2, 2)
+	for i := range columns {
+		columns[i].nulls = nodes[i][1]
+		if nodes[i][0] != batch.int64(0) {
+			t.Fatalf("column %s of %d rows in a batch of %d", columns[i].name, nodes[i][0], batch.int64(0))
+		}
+		n := 2
+		if columns[i].typeID == typeUtf8 {
+			n = 3
+		}
+		for _, b := range buffers[:n] {
+			if b[0]%8 != 0 {
+				t.Fatalf("misaligned buffer %v", b)
+			}
+			columns[i].buffers = append(columns[i].buffers, body[b[0]:b[0]+b[1]])
+		}
+		buffers = buffers[n:]
+	}
+	return batch.int64(0), columns
+}
+
+// update rewrites the golden file with the output of the writer, which has to be checked
+// afterwards by testdata/golden.py against pyarrow and by the tests of the apache tag.
+var update = flag.Bool("update", false, "update the golden file of the arrow package")
+
+// newTestTable returns a table of all column types starting at day, with a null time, an
+// empty string and NaN.
+func newTestTable(day time.Time) *Table {
+	table := NewTable()
+	table.AddTime("time", []time.Time{day, {}, day.Add(time.Hour)})
+	table.AddString("symbol", []string{"TEST.DE", "", "ÄÖÜ"})
+	table.AddFloat64("price", []float64{1.5, math.NaN(), -2})
+	table.AddInt64("id", []int64{1, -2, 3})
+	return table
+}
+
+func TestTableWrite(t *testing.T) {
+	day := time.Date(2020, 1, 2, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	table := newTestTable(day)
+	if table.Rows() != 3 {
+		t.Errorf("Rows(): expected 3, actual %v", table.Rows())
+	}
+
+	var buf bytes.Buffer
+	if err := table.Write(&buf); err != nil {
+		t.Fatalf("Write(): unexpected error %v", err)
+	}
+	rows, columns := readFile(t, buf.Bytes())
+	if rows != 3 || len(columns) != 4 {
+		t.Fatalf("Write(): expected 3 rows of 4 columns, actual %v %v", rows, len(columns))
+	}
+
+	// testCases is a table for testing the columns of the file
+	var testCases = []struct {
+		name     string
+		typeID   int8
+		nullable bool
+		nulls    int64
+		exp      string
+	}{
+		{"time", typeTimestamp, true, 1, fmt.Sprint([]byte{5}, le(day.UnixNano(), 0, day.Add(time.Hour).UnixNano()))},
+		{"symbol", typeUtf8, false, 0, fmt.Sprint([]byte{}, le32(0, 7, 7, 13), []byte("TEST.DEÄÖÜ"))},
+		{"price", typeFloatingPoint, false, 0, fmt.Sprint([]byte{}, le(int64(math.Float64bits(1.5)), int64(math.Float64bits(math.NaN())), int64(math.Float64bits(-2))))},
+		{"id", typeInt, false, 0, fmt.Sprint([]byte{}, le(1, -2, 3))},
+	}
+
+	for i, tc := range testCases {
+		c := columns[i]
+		if c.name != tc.name || c.typeID != tc.typeID || c.nullable != tc.nullable || c.nulls != tc.nulls {
+			t.Errorf("Write(): expected column %v of type %v, actual %+v", tc.name, tc.typeID, c)
+		}
+		var buffers []interface{}
+		for _, b := range c.buffers {
+			buffers = append(buffers, b)
+		}
+		if actual := fmt.Sprint(buffers...); actual != tc.exp {
+			t.Errorf("Write(): column %v \nexpected %v, \nactual   %v", tc.name, tc.exp, actual)
+		}
+	}
+
+	if typ := columns[0].typ; typ.int16(0) != unitNanosecond || typ.string(1) != "UTC" {
+		t.Errorf("Write(): expected nanoseconds of UTC")
+	}
+	if typ := columns[2].typ; typ.int16(0) != precisionDouble {
+		t.Errorf("Write(): expected double precision")
+	}
+	if typ := columns[3].typ; typ.int32(0) != 64 || typ.int8(1) != 1 {
+		t.Errorf("Write(): expected signed 64 bit integers")
+	}
+}
+
+func TestGoldenFile(t *testing.T) {
+	path := filepath.Join("testdata", "golden.arrow")
+	var buf bytes.Buffer
+	if err := newTestTable(time.Date(2020, 1, 2, 8, 0, 0, 0, time.UTC)).Write(&buf); err != nil {
+		t.Fatalf("Write(): unexpected error %v", err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Errorf("Write(): expected the bytes of %s", path)
+	}
+
+	// the golden file is checked against pyarrow, the reference implementation, if installed
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not installed")
+	}
+	if err := exec.Command(python, "-c", "import pyarrow").Run(); err != nil {
+		t.Skip("pyarrow not installed")
+	}
+	if out, err := exec.Command(python, filepath.Join("testdata", "golden.py"), path).CombinedOutput(); err != nil {
+		t.Errorf("golden.py: expected %s to be read by pyarrow, actual %v\n%s", path, err, out)
+	}
+}
+
+func TestTableErrors(t *testing.T) {
+	// testCases is a table for testing invalid tables
+	var testCases = []struct {
+		msg    string
+		table  func() *Table
+		expErr string
+	}{
+		{"no columns:", NewTable, "without columns"},
+		{"different rows:", func() *Table {
+			t := NewTable()
+			t.AddInt64("a", []int64{1})
+			t.AddFloat64("b", []float64{1, 2})
+			return t
+		}, "column b has 2 rows, expected 1"},
+		{"duplicate column:", func() *Table {
+			t := NewTable()
+			t.AddInt64("a", []int64{1})
+			t.AddString("a", []string{"x"})
+			return t
+		}, "column a already exists"},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.table().Write(ioutil.Discard); err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%v Write(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
+
+func TestTableWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arrow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := NewTable()
+	table.AddInt64("id", []int64{1, 2})
+	path := filepath.Join(dir, "ids.arrow")
+	if err := table.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile(): unexpected error %v", err)
+	}
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, columns := readFile(t, file); rows != 2 || columns[0].name != "id" {
+		t.Errorf("WriteFile(): expected the table, actual %v rows %+v", rows, columns)
+	}
+	if err := table.WriteFile(filepath.Join(dir, "missing", "ids.arrow")); err == nil {
+		t.Errorf("WriteFile(): expected an error of a missing directory")
+	}
+}
+
+// le returns int64 values as little endian bytes.
+func le(values ...int64) []byte {
+	return appendInt64(nil, values...)
+}
+
+// le32 returns int32 values as little endian bytes.
+func le32(values ...int32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = appendInt32(b, v)
+	}
+	return b
+}
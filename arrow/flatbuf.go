@@ -0,0 +1,181 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// builder writes a flatbuffer front to back. A table is written before the objects it
+// references, so all offsets point forward as the format requires, and every scalar is
+// aligned to its size from the start of the buffer.
+type builder struct {
+	buf []byte
+}
+
+// object writes an object into the buffer and returns its position.
+type object func(b *builder) int
+
+// field is a field of a table, either a little endian scalar or a referenced object.
+// A field without both is absent.
+type field struct {
+	scalar []byte
+	ref    object
+}
+
+// finish writes the root table and returns the buffer, padded to 8 bytes.
+func finish(root object) []byte {
+	b := &builder{}
+	b.uint32(0)
+	pos := root(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	b.pad(8)
+	return b.buf
+}
+
+// table writes a vtable followed by a table of the fields by their id.
+func (b *builder) table(fields ...field) int {
+	type slot struct {
+		id, size int
+	}
+	var slots []slot
+	for id, f := range fields {
+		switch {
+		case f.ref != nil:
+			slots = append(slots, slot{id, 4})
+		case f.scalar != nil:
+			slots = append(slots, slot{id, len(f.scalar)})
+		}
+	}
+	// the largest fields first, the table starts 4 bytes before an 8 byte boundary
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].size > slots[j].size })
+	offsets := make([]int, len(fields))
+	size := 4
+	for _, s := range slots {
+		offsets[s.id] = size
+		size += s.size
+	}
+
+	b.pad(2)
+	vtable := len(b.buf)
+	b.uint16(4 + 2*len(fields))
+	b.uint16(size)
+	for _, o := range offsets {
+		b.uint16(o)
+	}
+
+	for len(b.buf)%8 != 4 {
+		b.buf = append(b.buf, 0)
+	}
+	table := len(b.buf)
+	b.uint32(table - vtable)
+	b.buf = append(b.buf, make([]byte, size-4)...)
+	for _, s := range slots {
+		if scalar := fields[s.id].scalar; scalar != nil {
+			copy(b.buf[table+offsets[s.id]:], scalar)
+		}
+	}
+	for _, s := range slots {
+		if ref := fields[s.id].ref; ref != nil {
+			b.offset(table+offsets[s.id], ref(b))
+		}
+	}
+	return table
+}
+
+// offset sets the offset at a position to an object.
+func (b *builder) offset(at, pos int) {
+	binary.LittleEndian.PutUint32(b.buf[at:], uint32(pos-at))
+}
+
+func (b *builder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *builder) uint16(v int) {
+	b.buf = append(b.buf, byte(v), byte(v>>8))
+}
+
+func (b *builder) uint32(v int) {
+	b.buf = append(b.buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// tableOf returns a table as object.
+func tableOf(fields ...field) object {
+	return func(b *builder) int {
+		return b.table(fields...)
+	}
+}
+
+// vectorOf returns a vector of objects, e.g. of tables.
+func vectorOf(objects ...object) object {
+	return func(b *builder) int {
+		b.pad(4)
+		pos := len(b.buf)
+		b.uint32(len(objects))
+		b.buf = append(b.buf, make([]byte, 4*len(objects))...)
+		for i, o := range objects {
+			b.offset(pos+4+4*i, o(b))
+		}
+		return pos
+	}
+}
+
+// structsOf returns a vector of n structs with an alignment of 8 bytes.
+func structsOf(n int, data []byte) object {
+	return func(b *builder) int {
+		for len(b.buf)%8 != 4 {
+			b.buf = append(b.buf, 0)
+		}
+		pos := len(b.buf)
+		b.uint32(n)
+		b.buf = append(b.buf, data...)
+		return pos
+	}
+}
+
+// stringOf returns a null terminated string.
+func stringOf(s string) object {
+	return func(b *builder) int {
+		b.pad(4)
+		pos := len(b.buf)
+		b.uint32(len(s))
+		b.buf = append(b.buf, s...)
+		b.buf = append(b.buf, 0)
+		return pos
+	}
+}
+
+func int8Field(v int8) field {
+	return field{scalar: []byte{byte(v)}}
+}
+
+func int16Field(v int16) field {
+	scalar := make([]byte, 2)
+	binary.LittleEndian.PutUint16(scalar, uint16(v))
+	return field{scalar: scalar}
+}
+
+func int32Field(v int32) field {
+	scalar := make([]byte, 4)
+	binary.LittleEndian.PutUint32(scalar, uint32(v))
+	return field{scalar: scalar}
+}
+
+func int64Field(v int64) field {
+	scalar := make([]byte, 8)
+	binary.LittleEndian.PutUint64(scalar, uint64(v))
+	return field{scalar: scalar}
+}
+
+func boolField(v bool) field {
+	if v {
+		return int8Field(1)
+	}
+	return int8Field(0)
+}
+
+func refField(o object) field {
+	return field{ref: o}
+}
@@ -0,0 +1,43 @@
+package arrow
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	buf := finish(tableOf(
+		int8Field(-3),
+		refField(stringOf("name")),
+		int64Field(1<<40),
+		field{},
+		int16Field(7),
+		refField(vectorOf(tableOf(int32Field(42)), tableOf())),
+		refField(structsOf(1, appendInt64(nil, 5, 6))),
+	))
+	if len(buf)%8 != 0 {
+		t.Errorf("finish(): expected a buffer padded to 8 bytes, actual %d", len(buf))
+	}
+
+	root := rootTable(t, buf)
+	if v := root.int8(0); v != -3 {
+		t.Errorf("table(): expected int8 -3, actual %v", v)
+	}
+	if v := root.string(1); v != "name" {
+		t.Errorf("table(): expected string name, actual %v", v)
+	}
+	if v := root.int64(2); v != 1<<40 {
+		t.Errorf("table(): expected int64 1<<40, actual %v", v)
+	}
+	if at := root.field(3); at != -1 {
+		t.Errorf("table(): expected an absent field, actual %v", at)
+	}
+	if v := root.int16(4); v != 7 {
+		t.Errorf("table(): expected int16 7, actual %v", v)
+	}
+	if tables := root.tables(5); len(tables) != 2 || tables[0].int32(0) != 42 || tables[1].field(0) != -1 {
+		t.Errorf("table(): expected a vector of two tables")
+	}
+	if s := root.structs(6, 2); len(s) != 1 || s[0][0] != 5 || s[0][1] != 6 {
+		t.Errorf("table(): expected a vector of a struct, actual %v", s)
+	}
+}
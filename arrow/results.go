@@ -0,0 +1,91 @@
+package arrow
+
+import (
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// EquityCurve returns a table of an equity curve with the columns time, equity, return and
+// drawdown.
+func EquityCurve(curve []gbt.EquityPoint) *Table {
+	times := make([]time.Time, len(curve))
+	equity := make([]float64, len(curve))
+	returns := make([]float64, len(curve))
+	drawdown := make([]float64, len(curve))
+	for i, p := range curve {
+		times[i] = p.Time
+		equity[i] = p.Equity
+		returns[i] = p.Return
+		drawdown[i] = p.Drawdown
+	}
+
+	t := NewTable()
+	t.AddTime("time", times)
+	t.AddFloat64("equity", equity)
+	t.AddFloat64("return", returns)
+	t.AddFloat64("drawdown", drawdown)
+	return t
+}
+
+// Trades returns a table of a trade blotter with the columns time, symbol, direction, qty,
+// price, cost and the ids fill_id, order_id, signal_id and data_id of the event lineage.
+func Trades(blotter []gbt.BlotterEntry) *Table {
+	n := len(blotter)
+	times := make([]time.Time, n)
+	symbols := make([]string, n)
+	directions := make([]string, n)
+	qty := make([]float64, n)
+	price := make([]float64, n)
+	cost := make([]float64, n)
+	ids := [4][]int64{make([]int64, n), make([]int64, n), make([]int64, n), make([]int64, n)}
+	for i, e := range blotter {
+		times[i] = e.Time
+		symbols[i] = e.Symbol
+		directions[i] = e.Direction.String()
+		qty[i] = e.Qty
+		price[i] = e.Price
+		cost[i] = e.Cost
+		ids[0][i], ids[1][i], ids[2][i], ids[3][i] = int64(e.FillID), int64(e.OrderID), int64(e.SignalID), int64(e.DataID)
+	}
+
+	t := NewTable()
+	t.AddTime("time", times)
+	t.AddString("symbol", symbols)
+	t.AddString("direction", directions)
+	t.AddFloat64("qty", qty)
+	t.AddFloat64("price", price)
+	t.AddFloat64("cost", cost)
+	t.AddInt64("fill_id", ids[0])
+	t.AddInt64("order_id", ids[1])
+	t.AddInt64("signal_id", ids[2])
+	t.AddInt64("data_id", ids[3])
+	return t
+}
+
+// Bars returns a table of the bars of a data stream with the columns time, symbol, open,
+// high, low, close, adj_close and volume. Other data events, e.g. ticks, are skipped.
+func Bars(stream []gbt.DataEvent) *Table {
+	var times []time.Time
+	var symbols []string
+	var columns [6][]float64
+	for _, e := range stream {
+		bar, ok := e.(*gbt.Bar)
+		if !ok {
+			continue
+		}
+		times = append(times, bar.Time())
+		symbols = append(symbols, bar.Symbol())
+		for i, v := range []float64{bar.Open, bar.High, bar.Low, bar.Close, bar.AdjClose, bar.Volume} {
+			columns[i] = append(columns[i], v)
+		}
+	}
+
+	t := NewTable()
+	t.AddTime("time", times)
+	t.AddString("symbol", symbols)
+	for i, name := range []string{"open", "high", "low", "close", "adj_close", "volume"} {
+		t.AddFloat64(name, columns[i])
+	}
+	return t
+}
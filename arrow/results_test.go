@@ -0,0 +1,91 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// newTestStatistic runs a trading backtest of the test data and returns its statistic and
+// data stream.
+func newTestStatistic(t *testing.T) (*gbt.Statistic, []gbt.DataEvent) {
+	d := &data.BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := d.Load([]string{"SDF.DE"}); err != nil {
+		t.Fatal(err)
+	}
+	var stream []gbt.DataEvent
+	for _, e := range d.Stream() {
+		if e.Time().Year() == 2016 {
+			stream = append(stream, e)
+		}
+	}
+	d.SetStream(stream)
+
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetSizeManager(&gbt.Size{DefaultSize: 100, DefaultValue: 10000})
+
+	test := gbt.New()
+	test.SetSymbols([]string{"SDF.DE"})
+	test.SetData(d)
+	test.SetPortfolio(portfolio)
+	test.SetStrategy(strategy.MovingAverageCross(5, 20))
+	if err := test.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return test.Stats().(*gbt.Statistic), stream
+}
+
+func TestResults(t *testing.T) {
+	stats, stream := newTestStatistic(t)
+	stream = append(stream, &gbt.Tick{Bid: 1, Ask: 2})
+
+	// testCases is a table for testing the tables of the results
+	var testCases = []struct {
+		msg     string
+		table   *Table
+		rows    int
+		columns []string
+	}{
+		{"equity curve:", EquityCurve(stats.EquityCurve()), len(stats.EquityCurve()),
+			[]string{"time", "equity", "return", "drawdown"}},
+		{"trades:", Trades(stats.Blotter()), len(stats.Transactions()),
+			[]string{"time", "symbol", "direction", "qty", "price", "cost", "fill_id", "order_id", "signal_id", "data_id"}},
+		{"bars without ticks:", Bars(stream), len(stream) - 1,
+			[]string{"time", "symbol", "open", "high", "low", "close", "adj_close", "volume"}},
+		{"no trades:", Trades(nil), 0,
+			[]string{"time", "symbol", "direction", "qty", "price", "cost", "fill_id", "order_id", "signal_id", "data_id"}},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := tc.table.Write(&buf); err != nil {
+			t.Fatalf("%v Write(): unexpected error %v", tc.msg, err)
+		}
+		rows, columns := readFile(t, buf.Bytes())
+		if int(rows) != tc.rows || len(columns) != len(tc.columns) {
+			t.Errorf("%v expected %d rows of %d columns, actual %d rows of %d", tc.msg, tc.rows, len(tc.columns), rows, len(columns))
+			continue
+		}
+		for i, c := range columns {
+			if c.name != tc.columns[i] {
+				t.Errorf("%v expected column %v, actual %v", tc.msg, tc.columns[i], c.name)
+			}
+		}
+	}
+
+	trades := Trades(stats.Blotter())
+	if trades.Rows() == 0 {
+		t.Fatalf("Trades(): expected trades of the backtest")
+	}
+	var buf bytes.Buffer
+	if err := trades.Write(&buf); err != nil {
+		t.Fatalf("Write(): unexpected error %v", err)
+	}
+	_, columns := readFile(t, buf.Bytes())
+	if direction := columns[2].buffers[2]; !bytes.HasPrefix(direction, []byte("buy")) {
+		t.Errorf("Trades(): expected the first trade a buy, actual %q", direction)
+	}
+}
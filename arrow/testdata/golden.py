@@ -0,0 +1,62 @@
+"""Checks golden.arrow against the reference implementation pyarrow.
+
+The file is written by TestGoldenFile of the arrow package. The script reads it with
+pyarrow, validates it in full and compares its schema and values with a table built by
+pyarrow. It exits with 1 on a difference, e.g.
+
+    python3 arrow/testdata/golden.py arrow/testdata/golden.arrow
+"""
+import datetime
+import math
+import sys
+
+import pyarrow as pa
+import pyarrow.ipc
+
+UTC = datetime.timezone.utc
+
+
+def expected():
+    day = datetime.datetime(2020, 1, 2, 8, 0, tzinfo=UTC)
+    # only a column with nulls is nullable
+    schema = pa.schema(
+        [
+            pa.field("time", pa.timestamp("ns", tz="UTC"), nullable=True),
+            pa.field("symbol", pa.utf8(), nullable=False),
+            pa.field("price", pa.float64(), nullable=False),
+            pa.field("id", pa.int64(), nullable=False),
+        ]
+    )
+    return pa.table(
+        [
+            pa.array([day, None, day + datetime.timedelta(hours=1)], pa.timestamp("ns", tz="UTC")),
+            pa.array(["TEST.DE", "", "ÄÖÜ"], pa.utf8()),
+            pa.array([1.5, math.nan, -2.0], pa.float64()),
+            pa.array([1, -2, 3], pa.int64()),
+        ],
+        schema=schema,
+    )
+
+
+def main(path):
+    with pa.ipc.open_file(path) as reader:
+        if reader.num_record_batches != 1:
+            sys.exit("expected a record batch, actual %d" % reader.num_record_batches)
+        table = reader.read_all()
+    table.validate(full=True)
+
+    exp = expected()
+    if not table.schema.equals(exp.schema):
+        sys.exit("expected schema\n%s\nactual\n%s" % (exp.schema, table.schema))
+    # NaN is not equal to itself, the price column is compared by its bits
+    for name in exp.column_names:
+        actual, want = table.column(name).to_pylist(), exp.column(name).to_pylist()
+        if name == "price":
+            actual, want = [repr(v) for v in actual], [repr(v) for v in want]
+        if actual != want:
+            sys.exit("column %s: expected %s, actual %s" % (name, want, actual))
+    print("ok", path, pa.__version__)
+
+
+if __name__ == "__main__":
+    main(sys.argv[1] if len(sys.argv) > 1 else "golden.arrow")
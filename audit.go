@@ -0,0 +1,190 @@
+package gobacktest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// AuditAction is a decision of the engine recorded in the audit trail.
+type AuditAction int
+
+// different audit actions
+const (
+	AuditSignal          AuditAction = iota // signal generated by the strategy
+	AuditSignalDiscarded                    // signal discarded, e.g. during the warm-up
+	AuditSignalIgnored                      // signal without an order, e.g. by the netting
+	AuditOrderCreated                       // order vetted by the portfolio and risk manager
+	AuditOrderRejected                      // order rejected by the portfolio, risk or the exchange
+	AuditOrderResting                       // order resting at the exchange without a direct fill
+	AuditFillApplied                        // fill booked into the portfolio
+	AuditFillFailed                         // fill not booked into the portfolio
+	AuditOrderCanceled                      // resting order canceled by the broker
+	AuditOrderModified                      // resting order modified by the broker
+)
+
+// String returns the name of the action.
+func (a AuditAction) String() string {
+	switch a {
+	case AuditSignal:
+		return "signal generated"
+	case AuditSignalDiscarded:
+		return "signal discarded"
+	case AuditSignalIgnored:
+		return "signal ignored"
+	case AuditOrderCreated:
+		return "order created"
+	case AuditOrderRejected:
+		return "order rejected"
+	case AuditOrderResting:
+		return "order resting"
+	case AuditFillApplied:
+		return "fill applied"
+	case AuditFillFailed:
+		return "fill failed"
+	case AuditOrderCanceled:
+		return "order canceled"
+	case AuditOrderModified:
+		return "order modified"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler, so the action is exported by its name.
+func (a AuditAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// AuditEntry is a single decision of the audit trail.
+type AuditEntry struct {
+	Seq       int         `json:"seq"`
+	Time      time.Time   `json:"time"`
+	Action    AuditAction `json:"action"`
+	Symbol    string      `json:"symbol"`
+	EventID   int         `json:"eventId,omitempty"`
+	ParentID  int         `json:"parentId,omitempty"`
+	Direction Direction   `json:"direction"`
+	Qty       float64     `json:"qty,omitempty"`
+	Price     float64     `json:"price,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// AuditLog is an append-only audit trail of the decisions of the engine.
+type AuditLog struct {
+	entries []AuditEntry
+}
+
+// NewAuditLog creates an empty audit trail.
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// Record appends a decision about an event to the audit trail.
+func (a *AuditLog) Record(action AuditAction, e EventHandler, reason string) {
+	entry := AuditEntry{
+		Seq:      len(a.entries) + 1,
+		Time:     e.Time(),
+		Action:   action,
+		Symbol:   e.Symbol(),
+		EventID:  eventID(e),
+		ParentID: parentID(e),
+		Reason:   reason,
+	}
+	// a rejection is recorded with the direction and qty of the rejected order
+	var details interface{} = e
+	if r, ok := e.(*Rejection); ok && r.Order != nil {
+		details = r.Order
+	}
+	if d, ok := details.(Directioner); ok {
+		entry.Direction = d.Direction()
+	}
+	if q, ok := details.(Quantifier); ok {
+		entry.Qty = q.Qty()
+	}
+	if f, ok := e.(FillEvent); ok {
+		entry.Price = f.Price()
+	}
+
+	a.entries = append(a.entries, entry)
+}
+
+// Entries returns a copy of the audit trail.
+func (a *AuditLog) Entries() []AuditEntry {
+	entries := make([]AuditEntry, len(a.entries))
+	copy(entries, a.entries)
+	return entries
+}
+
+// Filter returns the entries of the audit trail with the action.
+func (a *AuditLog) Filter(action AuditAction) []AuditEntry {
+	var entries []AuditEntry
+	for _, e := range a.entries {
+		if e.Action == action {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Reset implements Reseter to start a new audit trail.
+func (a *AuditLog) Reset() error {
+	a.entries = nil
+	return nil
+}
+
+// WriteCSV exports the audit trail as csv.
+func (a *AuditLog) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Seq", "Time", "Action", "Symbol", "EventID", "ParentID", "Direction", "Qty", "Price", "Reason"})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range a.entries {
+		err := writer.Write([]string{
+			strconv.Itoa(e.Seq),
+			e.Time.Format(time.RFC3339),
+			e.Action.String(),
+			e.Symbol,
+			strconv.Itoa(e.EventID),
+			strconv.Itoa(e.ParentID),
+			strconv.Itoa(int(e.Direction)),
+			strconv.FormatFloat(e.Qty, 'f', -1, 64),
+			strconv.FormatFloat(e.Price, 'f', -1, 64),
+			e.Reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON exports the audit trail as JSON Lines, one entry per line.
+func (a *AuditLog) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, e := range a.entries {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAudit sets the audit trail recording the decisions of the backtest.
+func (t *Backtest) SetAudit(a *AuditLog) {
+	t.audit = a
+}
+
+// record adds a decision to the audit trail, if one is set.
+func (t *Backtest) record(action AuditAction, e EventHandler, reason string) {
+	if t.audit == nil {
+		return
+	}
+	t.audit.Record(action, e, reason)
+}
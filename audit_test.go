@@ -0,0 +1,95 @@
+package gobacktest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBacktestAudit(t *testing.T) {
+	// testCases is a table for testing the audit trail of a backtest
+	var testCases = []struct {
+		msg        string
+		cash       float64
+		expActions []AuditAction
+	}{
+		{"filled orders:", 100000, []AuditAction{
+			AuditSignal, AuditOrderCreated, AuditFillApplied,
+			AuditSignal, AuditOrderCreated, AuditFillApplied,
+		}},
+		{"rejected orders:", 10, []AuditAction{
+			AuditSignal, AuditOrderRejected,
+			AuditSignal, AuditOrderRejected,
+		}},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100))
+
+		audit := NewAuditLog()
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		test.SetAudit(audit)
+		test.portfolio.(*Portfolio).initialCash = tc.cash
+
+		// a second run starts a new audit trail
+		for i := 0; i < 2; i++ {
+			if err := test.Run(); err != nil {
+				t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+			}
+
+			var actions []AuditAction
+			for _, e := range audit.Entries() {
+				actions = append(actions, e.Action)
+			}
+			if !reflect.DeepEqual(actions, tc.expActions) {
+				t.Errorf("%v run %v: expected actions %v, actual %v", tc.msg, i, tc.expActions, actions)
+			}
+			test.Reset()
+		}
+	}
+}
+
+func TestAuditLogRecord(t *testing.T) {
+	order := &Order{Event: Event{symbol: "TEST", eventID: 2, parentID: 1}, direction: BOT, qty: 10}
+	rejection := NewRejection(order, "not enough cash")
+	rejection.SetEventID(3)
+	rejection.SetParentID(1)
+	fill := &Fill{Event: Event{symbol: "TEST", eventID: 4, parentID: 2}, direction: BOT, qty: 10, price: 100}
+
+	audit := NewAuditLog()
+	audit.Record(AuditOrderCreated, order, "")
+	audit.Record(AuditOrderRejected, rejection, rejection.Reason())
+	audit.Record(AuditFillApplied, fill, "")
+
+	exp := []AuditEntry{
+		{Seq: 1, Action: AuditOrderCreated, Symbol: "TEST", EventID: 2, ParentID: 1, Direction: BOT, Qty: 10},
+		{Seq: 2, Action: AuditOrderRejected, Symbol: "TEST", EventID: 3, ParentID: 1, Direction: BOT, Qty: 10, Reason: "not enough cash"},
+		{Seq: 3, Action: AuditFillApplied, Symbol: "TEST", EventID: 4, ParentID: 2, Direction: BOT, Qty: 10, Price: 100},
+	}
+	if !reflect.DeepEqual(audit.Entries(), exp) {
+		t.Errorf("Entries(): \nexpected %+v, \nactual   %+v", exp, audit.Entries())
+	}
+	if rejected := audit.Filter(AuditOrderRejected); len(rejected) != 1 || rejected[0].Seq != 2 {
+		t.Errorf("Filter(): expected the rejection, actual %+v", rejected)
+	}
+
+	var csv bytes.Buffer
+	if err := audit.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV(): unexpected error %v", err)
+	}
+	if lines := strings.Count(csv.String(), "\n"); lines != 4 {
+		t.Errorf("WriteCSV(): expected 4 lines, actual %v", lines)
+	}
+
+	var json bytes.Buffer
+	if err := audit.WriteJSON(&json); err != nil {
+		t.Fatalf("WriteJSON(): unexpected error %v", err)
+	}
+	if !strings.Contains(json.String(), `"action":"order rejected"`) {
+		t.Errorf("WriteJSON(): expected the action by name, actual %v", json.String())
+	}
+}
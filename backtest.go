@@ -1,5 +1,12 @@
 package gobacktest
 
+import (
+	"context"
+	"hash"
+	"math/rand"
+	"time"
+)
+
 // DP sets the the precision of rounded floating numbers
 // used after calculations to format
 const DP = 4 // DP
@@ -17,7 +24,29 @@ type Backtest struct {
 	portfolio  PortfolioHandler
 	exchange   ExecutionHandler
 	statistic  StatisticHandler
-	eventQueue []EventHandler
+	eventQueue EventQueue
+	// data events per symbol during the warm-up of the strategy
+	warmUpCount map[string]int
+	regimes     []RegimeClassifier
+	clock       Clock
+	lastEventID int
+	// custom events and the index of the next one to queue
+	custom     []CustomEvent
+	customNext int
+	middleware []Middleware
+	logging    Logging
+	log        Logger
+	audit      *AuditLog
+	broker     Broker
+	progress   *progress
+	profile    *Profile
+	// processed data events of the run, their fingerprint and the checkpoint settings
+	dataCount   int
+	dataHash    hash.Hash64
+	checkpoints *checkpointing
+	// seed of all randomness and the source of the current run
+	seed *int64
+	rng  *rand.Rand
 }
 
 // New creates a default backtest with sensible defaults ready for use.
@@ -69,10 +98,30 @@ func (t *Backtest) SetStatistic(statistic StatisticHandler) {
 
 // Reset the backtest into a clean state with loaded data.
 func (t *Backtest) Reset() error {
-	t.eventQueue = nil
 	t.data.Reset()
+	return t.resetRun()
+}
+
+// resetRun resets the event queue, portfolio, statistic, exchange and strategy, but not the data.
+func (t *Backtest) resetRun() error {
+	t.eventQueue.Reset()
+	t.warmUpCount = nil
+	t.clock = Clock{}
+	t.lastEventID = 0
+	t.customNext = 0
+	t.dataCount = 0
+	t.dataHash = nil
+	if t.audit != nil {
+		t.audit.Reset()
+	}
+	if strategy, ok := t.strategy.(Reseter); ok {
+		strategy.Reset()
+	}
 	t.portfolio.Reset()
 	t.statistic.Reset()
+	if exchange, ok := t.exchange.(Reseter); ok {
+		exchange.Reset()
+	}
 	return nil
 }
 
@@ -83,43 +132,100 @@ func (t *Backtest) Stats() StatisticHandler {
 
 // Run starts the backtest.
 func (t *Backtest) Run() error {
+	return t.RunContext(context.Background())
+}
+
+// RunContext starts the backtest, which stops when the context is cancelled.
+// A cancelled backtest returns the error of the context, the statistics
+// hold the partial results up to the last processed data event.
+func (t *Backtest) RunContext(ctx context.Context) error {
 	// setup before the backtest runs
 	err := t.setup()
 	if err != nil {
 		return err
 	}
 
-	// poll event queue
-	for event, ok := t.nextEvent(); true; event, ok = t.nextEvent() {
-		// no event in the queue
-		if !ok {
-			// poll data stream
-			data, ok := t.data.Next()
-			// no more data, exit event loop
-			if !ok {
-				break
-			}
-			// found data event, add to event stream
-			t.eventQueue = append(t.eventQueue, data)
-			// start new event cycle
-			continue
+	if t.profile != nil {
+		return t.profile.run(ctx, t.run)
+	}
+	return t.run(ctx)
+}
+
+// run processes the data stream until the end of the data or the context is cancelled.
+func (t *Backtest) run(ctx context.Context) error {
+	t.progress.begin(len(t.data.Stream()))
+	// preallocate the statistics for the known data events
+	if r, ok := t.statistic.(interface{ Reserve(int) }); ok {
+		r.Reserve(len(t.data.Stream()))
+	}
+
+	for {
+		// process all events in the queue
+		if err := t.process(); err != nil {
+			return err
 		}
 
-		// processing event
-		err := t.eventLoop(event)
-		if err != nil {
+		// write the periodic checkpoint
+		if err := t.checkpoint(); err != nil {
 			return err
 		}
-		// event in queue found, add to event history
-		t.statistic.TrackEvent(event)
+
+		// stop polling the data stream, if the backtest is cancelled
+		if err := ctx.Err(); err != nil {
+			if t.checkpoints != nil {
+				if err := t.saveCheckpoint(); err != nil {
+					return err
+				}
+			}
+			if err := t.teardown(); err != nil {
+				return err
+			}
+			return err
+		}
+
+		// poll data stream, a live data stream waits for the next data event
+		var data DataEvent
+		var ok bool
+		if streamer, live := t.data.(ContextStreamer); live {
+			data, ok = streamer.NextContext(ctx)
+		} else {
+			data, ok = t.data.Next()
+		}
+		// no more data, process the remaining custom events and exit event loop
+		if !ok {
+			// a cancelled wait for data is handled at the top of the loop
+			if ctx.Err() != nil {
+				continue
+			}
+			if t.release(time.Time{}) {
+				continue
+			}
+			break
+		}
+		t.dataCount++
+		if t.checkpoints != nil {
+			t.fingerprint(data)
+		}
+		// custom events up to the data event are queued first
+		t.release(data.Time())
+		// found data event, add to event stream
+		t.enqueue(data, nil)
 	}
 
 	// teardown at the end of the backtest
-	err = t.teardown()
-	if err != nil {
-		return err
-	}
+	return t.teardown()
+}
 
+// process polls the event queue and processes all events until the queue is empty.
+func (t *Backtest) process() error {
+	handle := t.handler()
+	for event, ok := t.nextEvent(); ok; event, ok = t.nextEvent() {
+		// processing event through the middleware
+		if err := handle(event); err != nil {
+			return err
+		}
+		t.progress.event(event)
+	}
 	return nil
 }
 
@@ -128,6 +234,12 @@ func (t *Backtest) setup() error {
 	// before first run, set portfolio cash
 	t.portfolio.SetCash(t.portfolio.InitialCash())
 
+	// hand the loggers to the components
+	t.setLoggers()
+
+	// hand a newly seeded source to the components
+	t.setRand()
+
 	// make the data known to the strategy
 	err := t.strategy.SetData(t.data)
 	if err != nil {
@@ -140,70 +252,232 @@ func (t *Backtest) setup() error {
 		return err
 	}
 
+	// the portfolio checks its orders with the cost estimated by the exchange
+	if setter, ok := t.portfolio.(CostEstimatorSetter); ok {
+		if estimator, ok := t.exchange.(CostEstimator); ok {
+			setter.SetCostEstimator(estimator)
+		}
+	}
+
+	// the orders created by the exchange get their ids from the event sequence
+	if sequencer, ok := t.exchange.(Sequencer); ok {
+		sequencer.SetSequence(t.sequence)
+	}
+
+	// hand the broker to a strategy managing its own orders
+	if setter, ok := t.strategy.(BrokerSetter); ok {
+		if t.broker != nil {
+			setter.SetBroker(t.broker)
+		} else {
+			setter.SetBroker(t.Broker())
+		}
+	}
+
 	return nil
 }
 
 // teardown performs any cleaning operations at the end of the backtest.
 func (t *Backtest) teardown() error {
-	// no implementation yet
+	// send the final progress report
+	t.progress.end()
 	return nil
 }
 
 // nextEvent gets the next event from the events queue.
 func (t *Backtest) nextEvent() (e EventHandler, ok bool) {
-	// if event queue empty return false
-	if len(t.eventQueue) == 0 {
-		return e, false
-	}
+	// return the event with the highest priority, false if the queue is empty
+	return t.eventQueue.Pop()
+}
 
-	// return first element from the event queue
-	e = t.eventQueue[0]
-	t.eventQueue = t.eventQueue[1:]
+// enqueue adds an event to the event queue. An event without id gets the next id
+// and the id of the parent event, which caused it.
+func (t *Backtest) enqueue(e EventHandler, parent EventHandler) {
+	if l, ok := e.(Lineager); ok {
+		if l.EventID() == 0 {
+			t.lastEventID++
+			l.SetEventID(t.lastEventID)
+		}
+		if p, ok := parent.(Lineager); ok && l.ParentID() == 0 {
+			l.SetParentID(p.EventID())
+		}
+	}
+	t.eventQueue.Push(e)
+}
 
-	return e, true
+// sequence assigns the next event id to an order created outside of the event queue, e.g. a child
+// order of an execution algo, and tracks it for the lineage of its fills.
+func (t *Backtest) sequence(order *Order, parent EventHandler) {
+	t.lastEventID++
+	order.SetEventID(t.lastEventID)
+	if p, ok := parent.(Lineager); ok {
+		order.SetParentID(p.EventID())
+	}
+	t.statistic.TrackEvent(order)
+	t.record(AuditOrderCreated, order, "child order")
+	// the fills of the child order release the reservation of the parent order
+	if a, ok := t.portfolio.(adopter); ok {
+		a.adopt(order, parent)
+	}
 }
 
 // eventLoop directs the different events to their handler.
 func (t *Backtest) eventLoop(e EventHandler) error {
 	// type check for event type
 	switch event := e.(type) {
+	case CustomEvent:
+		// a custom event is handed to the strategy, if it handles custom events
+		handler, ok := t.strategy.(CustomEventHandler)
+		if !ok {
+			break
+		}
+		signals, err := handler.OnEvent(event)
+		if err != nil {
+			break
+		}
+		for _, signal := range signals {
+			t.enqueue(signal, event)
+			t.record(AuditSignal, signal, event.Kind())
+		}
+
 	case DataEvent:
+		// advance the clock of the engine
+		clock := t.tick(event)
+		if setter, ok := t.portfolio.(ClockSetter); ok {
+			setter.SetClock(clock)
+		}
+		// attach the market regime to the data event
+		t.classify(event)
 		// update portfolio to the last known price data
 		t.portfolio.Update(event)
 		// update statistics
 		t.statistic.Update(event, t.portfolio)
+		// a margin call liquidates positions before the strategy runs
+		if checker, ok := t.portfolio.(MarginChecker); ok {
+			if call, orders := checker.CheckMargin(event); call != nil {
+				t.enqueue(call, event)
+				for _, order := range orders {
+					t.enqueue(order, event)
+					t.record(AuditOrderCreated, order, "margin call")
+				}
+			}
+		}
+		// a breached risk rule halts the trading
+		if checker, ok := t.portfolio.(RuleChecker); ok {
+			if halt, orders := checker.CheckRules(event); halt != nil {
+				t.enqueue(halt, event)
+				for _, order := range orders {
+					t.enqueue(order, event)
+					t.record(AuditOrderCreated, order, "risk rule")
+				}
+			}
+		}
+		// close positions hit by an exit rule
+		if checker, ok := t.portfolio.(ExitChecker); ok {
+			for _, order := range checker.CheckExits(event) {
+				t.enqueue(order, event)
+				t.record(AuditOrderCreated, order, "exit rule")
+			}
+		}
+		// adjust existing positions, e.g. to a new exposure scale
+		if rebalancer, ok := t.portfolio.(Rebalancer); ok {
+			for _, order := range rebalancer.Rebalance(event) {
+				t.enqueue(order, event)
+				t.record(AuditOrderCreated, order, "rebalance")
+			}
+		}
 		// check if any orders are filled before proceding
-		t.exchange.OnData(event)
+		fills, err := t.exchange.OnData(event)
+		if err != nil {
+			break
+		}
+		for _, fill := range fills {
+			t.enqueue(fill, event)
+		}
 
 		// run strategy with this data event
+		warmingUp := t.warmingUp(event)
+		if !t.scheduled(clock) {
+			break
+		}
 		signals, err := t.strategy.OnData(event)
 		if err != nil {
 			break
 		}
+		// the signals of the warm-up period are discarded
+		if warmingUp {
+			if len(signals) > 0 {
+				logger(t.log).Debug("signals discarded during warm-up", "symbol", event.Symbol(), "time", event.Time(), "signals", len(signals))
+			}
+			for _, signal := range signals {
+				t.record(AuditSignalDiscarded, signal, "warm-up")
+			}
+			break
+		}
 		for _, signal := range signals {
-			t.eventQueue = append(t.eventQueue, signal)
+			t.enqueue(signal, event)
+			t.record(AuditSignal, signal, "")
 		}
 
 	case *Signal:
+		// an exit of both sides of a hedge is split into an exit signal per side
+		if splitter, ok := t.portfolio.(ExitSplitter); ok {
+			if signals := splitter.SplitExit(event); len(signals) > 0 {
+				for _, signal := range signals {
+					t.enqueue(signal, event)
+					t.record(AuditSignal, signal, "hedge exit")
+				}
+				break
+			}
+		}
 		order, err := t.portfolio.OnSignal(event, t.data)
-		if err != nil {
+		// a rejected order is added as rejection event to the event queue
+		if rejection, ok := err.(*Rejection); ok {
+			logger(t.log).Info("signal rejected", "symbol", event.Symbol(), "time", event.Time(), "reason", rejection.Reason())
+			t.enqueue(rejection, event)
+			t.record(AuditOrderRejected, rejection, rejection.Reason())
+			break
+		}
+		// an ignored signal results in no order
+		if err != nil || order == nil {
+			reason := ""
+			if err != nil {
+				reason = err.Error()
+			}
+			t.record(AuditSignalIgnored, event, reason)
 			break
 		}
-		t.eventQueue = append(t.eventQueue, order)
+		t.enqueue(order, event)
+		t.record(AuditOrderCreated, order, "")
 
 	case *Order:
 		fill, err := t.exchange.OnOrder(event, t.data)
-		if err != nil {
+		if rejection, ok := err.(*Rejection); ok {
+			logger(t.log).Info("order rejected", "symbol", event.Symbol(), "time", event.Time(), "reason", rejection.Reason())
+			t.enqueue(rejection, event)
+			t.record(AuditOrderRejected, rejection, rejection.Reason())
+			break
+		}
+		// order rests at the exchange, no direct fill
+		if err != nil || fill == nil {
+			t.record(AuditOrderResting, event, "")
 			break
 		}
-		t.eventQueue = append(t.eventQueue, fill)
+		t.enqueue(fill, event)
+
+	case *TradingHalted:
+		// notify the strategy about the trading halt
+		if observer, ok := t.strategy.(HaltObserver); ok {
+			observer.OnHalt(event)
+		}
 
 	case *Fill:
 		transaction, err := t.portfolio.OnFill(event, t.data)
 		if err != nil {
+			t.record(AuditFillFailed, event, err.Error())
 			break
 		}
 		t.statistic.TrackTransaction(transaction)
+		t.record(AuditFillApplied, event, "")
 	}
 
 	return nil
@@ -31,9 +31,9 @@ var queueTests = []struct {
 }{
 	{Backtest{}, nil, false}, // Test.eventQueue is empty
 	{Backtest{
-		eventQueue: []EventHandler{
+		eventQueue: *NewEventQueue(
 			&testEvent{},
-		},
+		),
 	}, &testEvent{}, true},
 }
 
@@ -45,3 +45,122 @@ func TestNextEvent(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkBacktestRun(b *testing.B) {
+	closes := make([]float64, 1000)
+	for i := range closes {
+		closes[i] = 100 + float64(i%10)
+	}
+	data := &Data{}
+	data.SetStream(newStressStream(closes...))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := test.Run(); err != nil {
+			b.Fatal(err)
+		}
+		test.Reset()
+	}
+}
+
+// doubleStrategy creates two buy signals on the first data event.
+type doubleStrategy struct {
+	*Strategy
+	done bool
+}
+
+func (s *doubleStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	if s.done {
+		return nil, nil
+	}
+	s.done = true
+	return []SignalEvent{
+		&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT},
+		&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT},
+	}, nil
+}
+
+func TestBacktestReservesOrderValue(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&doubleStrategy{Strategy: NewStrategy("double")})
+	// both orders of the default order value of 1000 are checked before the first fill
+	test.portfolio.(*Portfolio).initialCash = 1500
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	var rejections int
+	for _, e := range test.Stats().Events() {
+		if _, ok := e.(*Rejection); ok {
+			rejections++
+		}
+	}
+	if fills := len(test.Stats().Transactions()); fills != 1 || rejections != 1 {
+		t.Errorf("Run(): expected 1 fill and 1 rejection, actual %v fills, %v rejections", fills, rejections)
+	}
+	if cash := test.portfolio.Cash(); cash != 500 {
+		t.Errorf("Run(): expected cash 500, actual %v", cash)
+	}
+	if reserved := test.portfolio.(*Portfolio).reservedValue(); reserved != 0 {
+		t.Errorf("Run(): expected no reserved value after the fill, actual %v", reserved)
+	}
+}
+
+func TestBacktestRejectsOrderCost(t *testing.T) {
+	// testCases is a table for testing the cash check of an order including its cost
+	var testCases = []struct {
+		msg        string
+		commission float64
+		expReject  bool
+	}{
+		{"order covered by cash:", 0, false},
+		{"order covered by cash, but not its commission:", 5, true},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(100))
+
+		audit := NewAuditLog()
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		test.SetAudit(audit)
+		// the default size of an order value of 1000 uses all cash
+		test.portfolio.(*Portfolio).initialCash = 1000
+		test.exchange.(*Exchange).Commission = &FixedCommission{Commission: tc.commission}
+
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+		}
+
+		var rejected bool
+		for _, e := range audit.Entries() {
+			if e.Action == AuditOrderRejected {
+				rejected = true
+			}
+		}
+		var tracked bool
+		for _, e := range test.Stats().Events() {
+			if _, ok := e.(*Rejection); ok {
+				tracked = true
+			}
+		}
+		if rejected != tc.expReject || tracked != tc.expReject {
+			t.Errorf("%v Run(): expected rejection %v, actual audit %v, tracked %v", tc.msg, tc.expReject, rejected, tracked)
+		}
+		if cash := test.portfolio.Cash(); tc.expReject && cash != 1000 {
+			t.Errorf("%v Run(): expected the cash of the rejected order unchanged, actual %v", tc.msg, cash)
+		}
+	}
+}
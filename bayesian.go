@@ -0,0 +1,178 @@
+package gobacktest
+
+import (
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// BayesianOptimization searches the parameter space with a gaussian process surrogate model
+// of the objective. After InitialSamples random evaluations, each iteration evaluates the
+// candidate with the highest expected improvement over the best score so far.
+type BayesianOptimization struct {
+	Budget
+	InitialSamples int     // random evaluations before the model is used, defaults to 5
+	Iterations     int     // model guided evaluations, defaults to 25
+	Candidates     int     // random candidates scored per iteration, defaults to 500
+	LengthScale    float64 // of the squared exponential kernel on the unit cube, defaults to 0.2
+	Noise          float64 // variance added to the kernel diagonal, defaults to 1e-6
+	Xi             float64 // exploration bonus of the expected improvement, defaults to 0.01
+	Seed           int64
+}
+
+// Optimize evaluates the initial samples and the model guided iterations until the budget is used.
+func (b *BayesianOptimization) Optimize(space []Param, objective Objective) (OptimizationResult, error) {
+	s, err := newSearch(space, objective, b.Budget)
+	if err != nil {
+		return OptimizationResult{}, err
+	}
+	rng := rand.New(rand.NewSource(b.Seed))
+
+	initial := b.InitialSamples
+	if initial <= 0 {
+		initial = 5
+	}
+	iterations := b.Iterations
+	if iterations <= 0 {
+		iterations = 25
+	}
+	candidates := b.Candidates
+	if candidates <= 0 {
+		candidates = 500
+	}
+
+	var xs [][]float64
+	var ys []float64
+	observe := func(u []float64) (bool, error) {
+		score, err := s.evaluate(u)
+		if err != nil {
+			return true, err
+		}
+		// points without a finite score can not be modelled
+		if !math.IsInf(score, 0) {
+			xs = append(xs, u)
+			ys = append(ys, score)
+		}
+		return s.done(), nil
+	}
+
+	for i := 0; i < initial+iterations; i++ {
+		var next []float64
+		if i >= initial && len(xs) >= 2 {
+			next = b.propose(xs, ys, candidates, len(space), rng)
+		}
+		if next == nil {
+			next = randomPoint(len(space), rng)
+		}
+
+		done, err := observe(next)
+		if err != nil {
+			return s.result, err
+		}
+		if done {
+			return s.result, nil
+		}
+	}
+
+	return s.result, nil
+}
+
+// propose fits the gaussian process and returns the candidate with the highest expected improvement.
+func (b *BayesianOptimization) propose(xs [][]float64, ys []float64, candidates, dim int, rng *rand.Rand) []float64 {
+	lengthScale := b.LengthScale
+	if lengthScale <= 0 {
+		lengthScale = 0.2
+	}
+	noise := b.Noise
+	if noise <= 0 {
+		noise = 1e-6
+	}
+	xi := b.Xi
+	if xi <= 0 {
+		xi = 0.01
+	}
+
+	// standardise the scores
+	mean, stddev := stat.MeanStdDev(ys, nil)
+	if stddev == 0 || math.IsNaN(stddev) {
+		stddev = 1
+	}
+	n := len(xs)
+	y := mat.NewVecDense(n, nil)
+	best := math.Inf(-1)
+	for i, v := range ys {
+		z := (v - mean) / stddev
+		y.SetVec(i, z)
+		best = math.Max(best, z)
+	}
+
+	kernel := func(a, c []float64) float64 {
+		var d float64
+		for i := range a {
+			d += (a[i] - c[i]) * (a[i] - c[i])
+		}
+		return math.Exp(-d / (2 * lengthScale * lengthScale))
+	}
+
+	k := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			v := kernel(xs[i], xs[j])
+			if i == j {
+				v += noise
+			}
+			k.SetSym(i, j, v)
+		}
+	}
+
+	var chol mat.Cholesky
+	if ok := chol.Factorize(k); !ok {
+		return nil
+	}
+	var alpha mat.VecDense
+	if err := chol.SolveVecTo(&alpha, y); err != nil {
+		return nil
+	}
+
+	var bestPoint []float64
+	bestEI := math.Inf(-1)
+	kStar := mat.NewVecDense(n, nil)
+	var v mat.VecDense
+	for c := 0; c < candidates; c++ {
+		u := randomPoint(dim, rng)
+		for i := 0; i < n; i++ {
+			kStar.SetVec(i, kernel(u, xs[i]))
+		}
+
+		mu := mat.Dot(kStar, &alpha)
+		if err := chol.SolveVecTo(&v, kStar); err != nil {
+			continue
+		}
+		variance := 1 + noise - mat.Dot(kStar, &v)
+		if variance <= 0 {
+			continue
+		}
+		sigma := math.Sqrt(variance)
+
+		z := (mu - best - xi) / sigma
+		ei := (mu-best-xi)*distuv.UnitNormal.CDF(z) + sigma*distuv.UnitNormal.Prob(z)
+		if ei > bestEI {
+			bestEI = ei
+			bestPoint = u
+		}
+	}
+
+	return bestPoint
+}
+
+// randomPoint returns a uniform random point of the unit cube.
+func randomPoint(dim int, rng *rand.Rand) []float64 {
+	u := make([]float64, dim)
+	for i := range u {
+		u[i] = rng.Float64()
+	}
+	return u
+}
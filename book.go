@@ -0,0 +1,151 @@
+package gobacktest
+
+import (
+	"sort"
+)
+
+// BookLevel represents a single price level of an order book.
+type BookLevel struct {
+	Price  float64
+	Volume float64
+}
+
+// OrderBookEvent declares a level 2 order book event interface.
+type OrderBookEvent interface {
+	DataEvent
+	Spreader
+	Bids() []BookLevel
+	Asks() []BookLevel
+	IsSnapshot() bool
+}
+
+// Book declares a data event for the depth of an order book.
+// It either holds a complete snapshot of the book or a delta to the last known book,
+// where a level with zero volume removes the level from the book.
+type Book struct {
+	Event
+	Metric
+	BidLevels []BookLevel // sorted descending, best bid first
+	AskLevels []BookLevel // sorted ascending, best ask first
+	Snapshot  bool
+}
+
+// Bids returns the bid levels of the book.
+func (b Book) Bids() []BookLevel {
+	return b.BidLevels
+}
+
+// Asks returns the ask levels of the book.
+func (b Book) Asks() []BookLevel {
+	return b.AskLevels
+}
+
+// IsSnapshot returns if the book is a complete snapshot or a delta.
+func (b Book) IsSnapshot() bool {
+	return b.Snapshot
+}
+
+// BestBid returns the best bid level of the book.
+func (b Book) BestBid() (BookLevel, bool) {
+	if len(b.BidLevels) == 0 {
+		return BookLevel{}, false
+	}
+	return b.BidLevels[0], true
+}
+
+// BestAsk returns the best ask level of the book.
+func (b Book) BestAsk() (BookLevel, bool) {
+	if len(b.AskLevels) == 0 {
+		return BookLevel{}, false
+	}
+	return b.AskLevels[0], true
+}
+
+// Price returns the middle of the best bid and best ask.
+func (b Book) Price() float64 {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+
+	switch {
+	case okBid && okAsk:
+		return (bid.Price + ask.Price) / float64(2)
+	case okBid:
+		return bid.Price
+	case okAsk:
+		return ask.Price
+	}
+
+	return 0
+}
+
+// Spread returns the difference between best ask and best bid.
+func (b Book) Spread() float64 {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	if !okBid || !okAsk {
+		return 0
+	}
+
+	return ask.Price - bid.Price
+}
+
+// VolumeAt returns the volume resting at a price level on the side of a direction,
+// BOT for the bid side and SLD for the ask side.
+func (b Book) VolumeAt(dir Direction, price float64) float64 {
+	return volumeAtLevel(&b, dir, price)
+}
+
+// Apply updates the book with another book event. A snapshot replaces the book,
+// a delta updates the given levels and removes levels with zero volume.
+func (b *Book) Apply(update OrderBookEvent) {
+	b.SetTime(update.Time())
+
+	if update.IsSnapshot() {
+		b.BidLevels = append([]BookLevel{}, update.Bids()...)
+		b.AskLevels = append([]BookLevel{}, update.Asks()...)
+		b.sortLevels()
+		return
+	}
+
+	b.BidLevels = applyLevels(b.BidLevels, update.Bids())
+	b.AskLevels = applyLevels(b.AskLevels, update.Asks())
+	b.sortLevels()
+}
+
+// sortLevels sorts bids descending and asks ascending.
+func (b *Book) sortLevels() {
+	sort.Slice(b.BidLevels, func(i, j int) bool {
+		return b.BidLevels[i].Price > b.BidLevels[j].Price
+	})
+	sort.Slice(b.AskLevels, func(i, j int) bool {
+		return b.AskLevels[i].Price < b.AskLevels[j].Price
+	})
+}
+
+// applyLevels applies delta levels to a slice of levels.
+func applyLevels(levels, deltas []BookLevel) []BookLevel {
+	var result = []BookLevel{}
+
+	// copy all levels which are not touched by a delta
+	for _, level := range levels {
+		var found bool
+		for _, delta := range deltas {
+			if delta.Price == level.Price {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, level)
+		}
+	}
+
+	// add all deltas with a remaining volume
+	for _, delta := range deltas {
+		if delta.Volume > 0 {
+			result = append(result, delta)
+		}
+	}
+
+	return result
+}
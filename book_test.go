@@ -0,0 +1,87 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBookPrice(t *testing.T) {
+	var testCases = []struct {
+		msg       string
+		book      Book
+		expPrice  float64
+		expSpread float64
+	}{
+		{"empty book:",
+			Book{},
+			0, 0,
+		},
+		{"book with both sides:",
+			Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 100}, {Price: 9, Volume: 200}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 100}, {Price: 12, Volume: 200}},
+			},
+			10.5, 1,
+		},
+		{"book with bid side only:",
+			Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 100}},
+			},
+			10, 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		price := tc.book.Price()
+		spread := tc.book.Spread()
+		if price != tc.expPrice || spread != tc.expSpread {
+			t.Errorf("%v Price() Spread(): \nexpected %v %v, \nactual   %v %v",
+				tc.msg, tc.expPrice, tc.expSpread, price, spread)
+		}
+	}
+}
+
+func TestBookApply(t *testing.T) {
+	var testCases = []struct {
+		msg    string
+		book   *Book
+		update *Book
+		exp    *Book
+	}{
+		{"apply snapshot:",
+			&Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 100}},
+			},
+			&Book{
+				BidLevels: []BookLevel{{Price: 9, Volume: 50}, {Price: 10, Volume: 100}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 100}},
+				Snapshot:  true,
+			},
+			&Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 100}, {Price: 9, Volume: 50}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 100}},
+			},
+		},
+		{"apply delta:",
+			&Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 100}, {Price: 9, Volume: 50}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 100}},
+			},
+			&Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 0}, {Price: 9.5, Volume: 20}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 80}},
+			},
+			&Book{
+				BidLevels: []BookLevel{{Price: 9.5, Volume: 20}, {Price: 9, Volume: 50}},
+				AskLevels: []BookLevel{{Price: 11, Volume: 80}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.book.Apply(tc.update)
+		if !reflect.DeepEqual(tc.book, tc.exp) {
+			t.Errorf("%v Apply(): \nexpected %#v, \nactual   %#v", tc.msg, tc.exp, tc.book)
+		}
+	}
+}
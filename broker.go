@@ -0,0 +1,156 @@
+package gobacktest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Broker unifies the simulated and the live execution of orders. It submits, cancels and
+// modifies orders and reports the positions and cash of the account. A strategy implementing
+// BrokerSetter receives the broker of the backtest, so the same strategy code runs unchanged
+// against a live adapter implementing Broker.
+type Broker interface {
+	Submit(*Order) error
+	Cancel(id int) error
+	Modify(id int, change OrderChange) error
+	Orders() []OrderEvent // orders resting at the broker
+	Positions() map[string]Position
+	Cash() float64
+	Value() float64
+}
+
+// BrokerSetter receives the broker of a backtest, e.g. implemented by a strategy.
+type BrokerSetter interface {
+	SetBroker(Broker)
+}
+
+// OrderChange modifies a resting order, zero values keep the current value.
+type OrderChange struct {
+	Qty   float64
+	Limit float64
+	Stop  float64
+}
+
+// OrderCanceler cancels resting orders, e.g. implemented by the Exchange.
+type OrderCanceler interface {
+	CancelOrder(id int) error
+}
+
+// OrderModifier modifies resting orders, e.g. implemented by the Exchange.
+type OrderModifier interface {
+	ModifyOrder(id int, change OrderChange) error
+}
+
+// SimulatedBroker is the broker of a backtest. Submitted orders are processed by the exchange
+// of the backtest with the next event and fills are booked into its portfolio, like orders
+// of the portfolio. Unlike those, they bypass the size and risk management of the portfolio.
+type SimulatedBroker struct {
+	test *Backtest
+}
+
+// Broker returns the simulated broker of the backtest.
+func (t *Backtest) Broker() *SimulatedBroker {
+	return &SimulatedBroker{test: t}
+}
+
+// SetBroker sets the broker handed to a strategy implementing BrokerSetter, e.g. a live
+// broker adapter. Without a broker the strategy receives the simulated broker.
+func (t *Backtest) SetBroker(broker Broker) {
+	t.broker = broker
+}
+
+// Submit queues an order for the exchange. An order without time gets the time of the
+// latest data event of its symbol.
+func (b *SimulatedBroker) Submit(order *Order) error {
+	if order == nil || order.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	if order.Qty() <= 0 {
+		return fmt.Errorf("could not submit order for %s, qty %v not positive", order.Symbol(), order.Qty())
+	}
+	if order.Time().IsZero() {
+		latest := b.test.data.Latest(order.Symbol())
+		if latest == nil {
+			return fmt.Errorf("could not submit order for %s, no data", order.Symbol())
+		}
+		order.SetTime(latest.Time())
+	}
+
+	order.SetStatus(OrderNew)
+	b.test.enqueue(order, nil)
+	b.test.record(AuditOrderCreated, order, "submitted to the broker")
+	return nil
+}
+
+// Cancel cancels a resting order of the exchange.
+func (b *SimulatedBroker) Cancel(id int) error {
+	canceler, ok := b.test.exchange.(OrderCanceler)
+	if !ok {
+		return fmt.Errorf("could not cancel order, exchange %T does not cancel orders", b.test.exchange)
+	}
+	order, _ := b.order(id)
+	if err := canceler.CancelOrder(id); err != nil {
+		return err
+	}
+	if order != nil {
+		b.test.record(AuditOrderCanceled, order, "")
+	}
+	return nil
+}
+
+// Modify modifies a resting order of the exchange.
+func (b *SimulatedBroker) Modify(id int, change OrderChange) error {
+	modifier, ok := b.test.exchange.(OrderModifier)
+	if !ok {
+		return fmt.Errorf("could not modify order, exchange %T does not modify orders", b.test.exchange)
+	}
+	if err := modifier.ModifyOrder(id, change); err != nil {
+		return err
+	}
+	if order, ok := b.order(id); ok {
+		b.test.record(AuditOrderModified, order, "")
+	}
+	return nil
+}
+
+// Orders returns the orders resting at the exchange.
+func (b *SimulatedBroker) Orders() []OrderEvent {
+	if lister, ok := b.test.exchange.(interface{ Orders() ([]OrderEvent, bool) }); ok {
+		orders, _ := lister.Orders()
+		return orders
+	}
+	return nil
+}
+
+// Positions returns the open positions of the portfolio by symbol.
+func (b *SimulatedBroker) Positions() map[string]Position {
+	positions := make(map[string]Position)
+	if h, ok := b.test.portfolio.(interface{ Holdings() map[string]Position }); ok {
+		for symbol, pos := range h.Holdings() {
+			if pos.qty != 0 {
+				positions[symbol] = pos
+			}
+		}
+	}
+	return positions
+}
+
+// Cash returns the cash of the portfolio.
+func (b *SimulatedBroker) Cash() float64 {
+	return b.test.portfolio.Cash()
+}
+
+// Value returns the value of the portfolio.
+func (b *SimulatedBroker) Value() float64 {
+	return b.test.portfolio.Value()
+}
+
+// order returns a resting order by id.
+func (b *SimulatedBroker) order(id int) (OrderEvent, bool) {
+	for _, order := range b.Orders() {
+		if order.ID() == id {
+			return order, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,151 @@
+// Package alpaca integrates Alpaca Markets for US equities: historical bars for backtests,
+// live quotes over the websocket stream and order routing for paper and live accounts.
+//
+//	client := alpaca.NewClient(key, secret, true) // paper account
+//	feed := gbt.NewLiveData(100)
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetBroker(alpaca.NewBroker(client))
+//	go alpaca.NewFeed(client, "AAPL", "MSFT").Run(ctx, feed)
+//	err := test.RunContext(ctx)
+//
+// The historical bars of Data replace the csv files of a backtest:
+//
+//	data := &alpaca.Data{Client: client, Timeframe: "1Day", Start: start, End: end}
+//	test.SetData(data)
+package alpaca
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// default endpoints of the api
+const (
+	PaperURL  = "https://paper-api.alpaca.markets"
+	LiveURL   = "https://api.alpaca.markets"
+	DataURL   = "https://data.alpaca.markets"
+	StreamURL = "wss://stream.data.alpaca.markets/v2"
+)
+
+// Client is a client of the trading and market data api of Alpaca.
+type Client struct {
+	KeyID     string
+	SecretKey string
+	URL       string // trading api, PaperURL or LiveURL
+	DataURL   string // market data api, defaults to DataURL
+	StreamURL string // market data stream, defaults to StreamURL
+	// Feed selects the market data, iex for the free plan or sip, defaults to iex
+	Feed    string
+	HTTP    *http.Client  // defaults to http.DefaultClient
+	Timeout time.Duration // timeout of a request, defaults to 10 seconds
+}
+
+// NewClient creates a client of a paper or live account.
+func NewClient(keyID, secretKey string, paper bool) *Client {
+	c := &Client{KeyID: keyID, SecretKey: secretKey, URL: LiveURL}
+	if paper {
+		c.URL = PaperURL
+	}
+	return c
+}
+
+// feed returns the selected market data feed.
+func (c *Client) feed() string {
+	if c.Feed == "" {
+		return "iex"
+	}
+	return c.Feed
+}
+
+// header returns the authentication headers.
+func (c *Client) header() http.Header {
+	return http.Header{
+		"Apca-Api-Key-Id":     {c.KeyID},
+		"Apca-Api-Secret-Key": {c.SecretKey},
+	}
+}
+
+// trading sends a request to the trading api.
+func (c *Client) trading(method, path string, body, v interface{}) error {
+	return c.do(method, strings.TrimSuffix(orDefault(c.URL, PaperURL), "/")+path, body, v)
+}
+
+// data sends a request to the market data api.
+func (c *Client) data(path string, v interface{}) error {
+	return c.do(http.MethodGet, strings.TrimSuffix(orDefault(c.DataURL, DataURL), "/")+path, nil, v)
+}
+
+// do sends a request with an optional json body and decodes the json response into v.
+func (c *Client) do(method, url string, body, v interface{}) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var r io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(content)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+	req.Header = c.header()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	path := req.URL.Path
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &e) == nil && e.Message != "" {
+			return fmt.Errorf("%s %s: %s", method, path, e.Message)
+		}
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	if v == nil || len(content) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("%s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// orDefault returns the value or the default for an empty value.
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,114 @@
+package alpaca
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// api is a fake of the trading and market data api.
+type api struct {
+	mu      sync.Mutex
+	bodies  map[string]string // last body by method and path
+	queries map[string][]string
+	replies map[string]string // response by method and path
+}
+
+func newAPI() (*api, *httptest.Server) {
+	a := &api{bodies: make(map[string]string), queries: make(map[string][]string), replies: make(map[string]string)}
+	return a, httptest.NewServer(a)
+}
+
+func (a *api) reply(route, body string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.replies[route] = body
+}
+
+func (a *api) remove(route string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.replies, route)
+}
+
+func (a *api) body(route string) map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var v map[string]interface{}
+	json.Unmarshal([]byte(a.bodies[route]), &v)
+	return v
+}
+
+func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r.Header.Get("APCA-API-KEY-ID") != "key" || r.Header.Get("APCA-API-SECRET-KEY") != "secret" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":40310000,"message":"forbidden"}`))
+		return
+	}
+
+	route := r.Method + " " + r.URL.Path
+	body, _ := ioutil.ReadAll(r.Body)
+	a.bodies[route] = string(body)
+	a.queries[route] = append(a.queries[route], r.URL.RawQuery)
+
+	// a page of a paginated response is replied by route and page token
+	reply, ok := a.replies[route+"#"+r.URL.Query().Get("page_token")]
+	if !ok {
+		reply, ok = a.replies[route]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":40410000,"message":"not found"}`))
+		return
+	}
+	if reply == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(reply))
+}
+
+// newTestClient creates a client of the fake api.
+func newTestClient(url string) *Client {
+	c := NewClient("key", "secret", true)
+	c.URL = url
+	c.DataURL = url
+	return c
+}
+
+func TestClient(t *testing.T) {
+	a, ts := newAPI()
+	defer ts.Close()
+	a.reply("GET /ok", `{"value":1}`)
+
+	if c := NewClient("key", "secret", false); c.URL != LiveURL || c.feed() != "iex" {
+		t.Errorf("NewClient(): expected the live url and the iex feed, actual %v %v", c.URL, c.feed())
+	}
+
+	// testCases is a table for testing the requests of the client
+	var testCases = []struct {
+		msg    string
+		client *Client
+		path   string
+		expErr string
+	}{
+		{"valid response:", newTestClient(ts.URL), "/ok", ""},
+		{"unknown path:", newTestClient(ts.URL), "/missing", "GET /missing: not found"},
+		{"wrong key:", &Client{URL: ts.URL}, "/ok", "GET /ok: forbidden"},
+	}
+
+	for _, tc := range testCases {
+		var v struct{ Value int }
+		err := tc.client.trading(http.MethodGet, tc.path, nil, &v)
+		if (tc.expErr == "" && (err != nil || v.Value != 1)) || (tc.expErr != "" && (err == nil || err.Error() != tc.expErr)) {
+			t.Errorf("%v trading(): expected error %q, actual %v %+v", tc.msg, tc.expErr, err, v)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package alpaca
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/internal/orders"
+)
+
+// Broker implements gobacktest.Broker for an Alpaca account.
+// An order is placed with the client order id gbt-<session>-<id>, a replaced order keeps the
+// id of the engine and is tracked by the id of its replacement.
+type Broker struct {
+	*Client
+	// TIF is the time in force of market, limit and stop orders, defaults to day
+	TIF string
+	// OnError receives the failed requests of the orders, positions and account endpoints
+	OnError func(error)
+
+	orders  orders.Tracker
+	session int64 // makes the client order ids unique across sessions
+}
+
+// NewBroker creates a broker of the account of a client.
+func NewBroker(c *Client) *Broker {
+	return &Broker{Client: c, session: time.Now().Unix()}
+}
+
+// order is an order of the trading api.
+type order struct {
+	ID            string  `json:"id"`
+	ClientOrderID string  `json:"client_order_id"`
+	Status        string  `json:"status"`
+	Symbol        string  `json:"symbol"`
+	Qty           decimal `json:"qty"`
+	FilledQty     decimal `json:"filled_qty"`
+}
+
+// request is a new order of the trading api, the numbers are sent as strings.
+type request struct {
+	Symbol        string `json:"symbol"`
+	Qty           string `json:"qty"`
+	Side          string `json:"side"`
+	Type          string `json:"type"`
+	TimeInForce   string `json:"time_in_force"`
+	LimitPrice    string `json:"limit_price,omitempty"`
+	StopPrice     string `json:"stop_price,omitempty"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+}
+
+// Submit places an order.
+func (b *Broker) Submit(o *gbt.Order) error {
+	if o == nil || o.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	if o.Qty() <= 0 {
+		return fmt.Errorf("could not submit order for %s, qty %v not positive", o.Symbol(), o.Qty())
+	}
+
+	b.orders.Assign(o)
+	req, err := b.request(o)
+	if err != nil {
+		return err
+	}
+	req.ClientOrderID = fmt.Sprintf("gbt-%d-%d", b.session, o.ID())
+
+	var placed order
+	if err := b.trading(http.MethodPost, "/v2/orders", req, &placed); err != nil {
+		return fmt.Errorf("could not submit order for %s: %v", o.Symbol(), err)
+	}
+
+	if o.Time().IsZero() {
+		o.SetTime(time.Now())
+	}
+	o.SetStatus(gbt.OrderSubmitted)
+	b.orders.Add(o, placed.ID)
+	return nil
+}
+
+// Cancel cancels a resting order.
+func (b *Broker) Cancel(id int) error {
+	_, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	if err := b.trading(http.MethodDelete, "/v2/orders/"+remote, nil, nil); err != nil {
+		return fmt.Errorf("could not cancel order %v: %v", id, err)
+	}
+	b.orders.Finish(id, gbt.OrderCanceled)
+	return nil
+}
+
+// Modify replaces a resting order, the replacement keeps the id of the engine.
+func (b *Broker) Modify(id int, change gbt.OrderChange) error {
+	o, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	replace := make(map[string]string)
+	if change.Qty > 0 {
+		replace["qty"] = formatNumber(change.Qty)
+	}
+	if change.Limit > 0 {
+		replace["limit_price"] = formatNumber(change.Limit)
+	}
+	if change.Stop > 0 {
+		replace["stop_price"] = formatNumber(change.Stop)
+	}
+
+	var replaced order
+	if err := b.trading(http.MethodPatch, "/v2/orders/"+remote, replace, &replaced); err != nil {
+		return fmt.Errorf("could not modify order %v: %v", id, err)
+	}
+
+	b.orders.Update(id, func(o *gbt.Order) {
+		if change.Qty > 0 {
+			o.SetQty(change.Qty)
+		}
+		if change.Limit > 0 {
+			o.SetLimit(change.Limit)
+		}
+		if change.Stop > 0 {
+			o.SetStop(change.Stop)
+		}
+	})
+	b.orders.Add(o, replaced.ID)
+	return nil
+}
+
+// Orders returns the open orders. Filled, cancelled, expired and rejected orders are removed.
+func (b *Broker) Orders() []gbt.OrderEvent {
+	var all []order
+	if err := b.trading(http.MethodGet, "/v2/orders?status=all&limit=500", nil, &all); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch orders: %v", err))
+		return b.orders.Orders()
+	}
+
+	for _, o := range all {
+		tracked, ok := b.orders.Local(o.ID)
+		if !ok {
+			continue
+		}
+		switch o.Status {
+		case "filled":
+			b.orders.Finish(tracked.ID(), gbt.OrderFilled)
+		case "canceled", "expired", "rejected":
+			b.orders.Finish(tracked.ID(), gbt.OrderCanceled)
+		}
+	}
+	return b.orders.Orders()
+}
+
+// Positions returns the open positions by symbol.
+func (b *Broker) Positions() map[string]gbt.Position {
+	var held []struct {
+		Symbol        string  `json:"symbol"`
+		Qty           decimal `json:"qty"`
+		AvgEntryPrice decimal `json:"avg_entry_price"`
+		CurrentPrice  decimal `json:"current_price"`
+	}
+	positions := make(map[string]gbt.Position)
+	if err := b.trading(http.MethodGet, "/v2/positions", nil, &held); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch positions: %v", err))
+		return positions
+	}
+
+	now := time.Now()
+	for _, p := range held {
+		if p.Qty == 0 {
+			continue
+		}
+		positions[p.Symbol] = gbt.NewPosition(now, p.Symbol, float64(p.Qty), float64(p.AvgEntryPrice), float64(p.CurrentPrice))
+	}
+	return positions
+}
+
+// Cash returns the cash of the account.
+func (b *Broker) Cash() float64 {
+	cash, _ := b.account()
+	return cash
+}
+
+// Value returns the equity of the account.
+func (b *Broker) Value() float64 {
+	_, equity := b.account()
+	return equity
+}
+
+// account returns the cash and equity of the account.
+func (b *Broker) account() (float64, float64) {
+	var account struct {
+		Cash   decimal `json:"cash"`
+		Equity decimal `json:"equity"`
+	}
+	if err := b.trading(http.MethodGet, "/v2/account", nil, &account); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch account: %v", err))
+		return 0, 0
+	}
+	return float64(account.Cash), float64(account.Equity)
+}
+
+// request creates the order request of an order.
+func (b *Broker) request(o *gbt.Order) (request, error) {
+	req := request{Symbol: o.Symbol(), Qty: formatNumber(o.Qty()), TimeInForce: b.TIF}
+	if req.TimeInForce == "" {
+		req.TimeInForce = "day"
+	}
+
+	switch o.Direction() {
+	case gbt.BOT:
+		req.Side = "buy"
+	case gbt.SLD:
+		req.Side = "sell"
+	default:
+		return request{}, fmt.Errorf("could not submit order for %s, direction %v not supported", o.Symbol(), o.Direction())
+	}
+
+	switch o.Type() {
+	case gbt.MarketOrder:
+		req.Type = "market"
+	case gbt.MarketOnOpenOrder:
+		req.Type = "market"
+		req.TimeInForce = "opg"
+	case gbt.MarketOnCloseOrder:
+		req.Type = "market"
+		req.TimeInForce = "cls"
+	case gbt.LimitOrder:
+		req.Type = "limit"
+		req.LimitPrice = formatNumber(o.Limit())
+	case gbt.StopMarketOrder:
+		req.Type = "stop"
+		req.StopPrice = formatNumber(o.Stop())
+	case gbt.StopLimitOrder:
+		req.Type = "stop_limit"
+		req.LimitPrice = formatNumber(o.Limit())
+		req.StopPrice = formatNumber(o.Stop())
+	default:
+		return request{}, fmt.Errorf("could not submit order for %s, type %v not supported", o.Symbol(), o.Type())
+	}
+	return req, nil
+}
+
+// decimal is a number of the api, which sends most numbers as strings.
+type decimal float64
+
+// UnmarshalJSON implements json.Unmarshaler for strings, numbers and null.
+func (d *decimal) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var f *float64
+		if err := json.Unmarshal(b, &f); err != nil {
+			return err
+		}
+		if f != nil {
+			*d = decimal(*f)
+		}
+		return nil
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*d = decimal(f)
+	return nil
+}
+
+// formatNumber formats a number for the api.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,148 @@
+package alpaca
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// the broker implements the broker interface of the engine
+var _ gbt.Broker = &Broker{}
+
+// newOrder creates an order of the engine.
+func newOrder(symbol string, orderType gbt.OrderType, direction gbt.Direction, qty, limit float64) *gbt.Order {
+	order := &gbt.Order{}
+	order.SetSymbol(symbol)
+	order.SetType(orderType)
+	order.SetDirection(direction)
+	order.SetQty(qty)
+	order.SetLimit(limit)
+	return order
+}
+
+func TestBrokerOrders(t *testing.T) {
+	a, ts := newAPI()
+	defer ts.Close()
+	a.reply("POST /v2/orders", `{"id":"a1","status":"accepted","symbol":"AAPL","qty":"10"}`)
+	a.reply("PATCH /v2/orders/a1", `{"id":"a2","status":"accepted","symbol":"AAPL","qty":"20"}`)
+	a.reply("DELETE /v2/orders/a2", ``)
+
+	b := NewBroker(newTestClient(ts.URL))
+	order := newOrder("AAPL", gbt.LimitOrder, gbt.BOT, 10, 150.5)
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	req := a.body("POST /v2/orders")
+	if req["symbol"] != "AAPL" || req["qty"] != "10" || req["side"] != "buy" || req["type"] != "limit" || req["limit_price"] != "150.5" ||
+		req["time_in_force"] != "day" || !strings.HasSuffix(req["client_order_id"].(string), "-1") {
+		t.Errorf("Submit(): unexpected order request %v", req)
+	}
+	if order.ID() != 1 || order.Status() != gbt.OrderSubmitted {
+		t.Errorf("Submit(): expected a submitted order with id 1, actual %+v", order)
+	}
+
+	a.reply("GET /v2/orders", `[{"id":"a1","status":"accepted"},{"id":"x9","status":"filled"}]`)
+	if orders := b.Orders(); len(orders) != 1 || orders[0] != gbt.OrderEvent(order) {
+		t.Errorf("Orders(): expected the open order, actual %v", orders)
+	}
+
+	// the replacement gets a new order id of the api
+	if err := b.Modify(1, gbt.OrderChange{Qty: 20, Limit: 149}); err != nil {
+		t.Fatalf("Modify(): unexpected error %v", err)
+	}
+	if replace := a.body("PATCH /v2/orders/a1"); replace["qty"] != "20" || replace["limit_price"] != "149" || replace["stop_price"] != nil {
+		t.Errorf("Modify(): unexpected replace request %v", replace)
+	}
+	if order.Qty() != 20 || order.Limit() != 149 {
+		t.Errorf("Modify(): expected qty 20 at 149, actual %+v", order)
+	}
+
+	if err := b.Cancel(1); err != nil {
+		t.Fatalf("Cancel(): unexpected error %v", err)
+	}
+	if order.Status() != gbt.OrderCanceled || len(b.Orders()) != 0 {
+		t.Errorf("Cancel(): expected a canceled order, actual %v", order.Status())
+	}
+
+	// a filled order is removed from the open orders
+	a.reply("POST /v2/orders", `{"id":"a3","status":"accepted"}`)
+	filled := newOrder("AAPL", gbt.MarketOnCloseOrder, gbt.SLD, 5, 0)
+	if err := b.Submit(filled); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	if req := a.body("POST /v2/orders"); req["type"] != "market" || req["time_in_force"] != "cls" || req["side"] != "sell" {
+		t.Errorf("Submit(): expected a market on close order, actual %v", req)
+	}
+	a.reply("GET /v2/orders", `[{"id":"a3","status":"filled"}]`)
+	if orders := b.Orders(); len(orders) != 0 || filled.Status() != gbt.OrderFilled {
+		t.Errorf("Orders(): expected the filled order to be removed, actual %v", orders)
+	}
+
+	// testCases is a table for testing rejected orders
+	var testCases = []struct {
+		msg   string
+		order *gbt.Order
+	}{
+		{"order without qty:", newOrder("AAPL", gbt.MarketOrder, gbt.BOT, 0, 0)},
+		{"exit direction:", newOrder("AAPL", gbt.MarketOrder, gbt.EXT, 1, 0)},
+		{"order rejected by the api:", newOrder("FAIL", gbt.StopMarketOrder, gbt.BOT, 1, 0)},
+	}
+	a.remove("POST /v2/orders")
+	for _, tc := range testCases {
+		if err := b.Submit(tc.order); err == nil {
+			t.Errorf("%v Submit(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestBrokerAccount(t *testing.T) {
+	a, ts := newAPI()
+	defer ts.Close()
+	a.reply("GET /v2/positions", `[
+		{"symbol":"AAPL","qty":"10","avg_entry_price":"150","current_price":"155"},
+		{"symbol":"TSLA","qty":"-5","avg_entry_price":"700","current_price":"690"}
+	]`)
+	a.reply("GET /v2/account", `{"cash":"10000.5","equity":"11550"}`)
+
+	var errs []error
+	b := NewBroker(newTestClient(ts.URL))
+	b.OnError = func(err error) { errs = append(errs, err) }
+
+	positions := b.Positions()
+	if len(positions) != 2 || positions["AAPL"].Qty() != 10 || positions["AAPL"].AvgPrice() != 150 || positions["TSLA"].Qty() != -5 {
+		t.Errorf("Positions(): expected the open positions by symbol, actual %+v", positions)
+	}
+	if b.Cash() != 10000.5 || b.Value() != 11550 {
+		t.Errorf("Cash(): expected cash 10000.5 and value 11550, actual %v %v", b.Cash(), b.Value())
+	}
+
+	b.KeyID = "wrong"
+	if len(b.Positions()) != 0 || b.Value() != 0 || len(b.Orders()) != 0 || len(errs) != 3 {
+		t.Errorf("OnError: expected 3 errors, actual %v", errs)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	// testCases is a table for testing the numbers of the api
+	var testCases = []struct {
+		raw    string
+		expVal decimal
+		expErr bool
+	}{
+		{`"150.25"`, 150.25, false},
+		{`150.25`, 150.25, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"abc"`, 0, true},
+	}
+
+	for _, tc := range testCases {
+		var d decimal
+		err := json.Unmarshal([]byte(tc.raw), &d)
+		if d != tc.expVal || (err != nil) != tc.expErr {
+			t.Errorf("UnmarshalJSON(%v): expected %v with error %v, actual %v %v", tc.raw, tc.expVal, tc.expErr, d, err)
+		}
+	}
+}
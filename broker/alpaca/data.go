@@ -0,0 +1,91 @@
+package alpaca
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// Data loads the historical bars of the market data api into the data stream.
+// It expands the underlying data struct.
+type Data struct {
+	gbt.Data
+	Client     *Client
+	Timeframe  string // e.g. 1Min, 1Hour or 1Day, defaults to 1Day
+	Start, End time.Time
+	// Adjustment of the prices for corporate actions, raw, split, dividend or all, defaults to raw
+	Adjustment string
+}
+
+// Load the bars of the symbols into the stream ordered by date.
+func (d *Data) Load(symbols []string) error {
+	if d.Client == nil {
+		return errors.New("no alpaca client provided")
+	}
+	if len(symbols) == 0 {
+		return errors.New("no symbols provided")
+	}
+
+	for _, symbol := range symbols {
+		bars, err := d.Client.Bars(symbol, d.Timeframe, d.Start, d.End, d.Adjustment)
+		if err != nil {
+			return err
+		}
+		stream := d.Data.Stream()
+		for _, bar := range bars {
+			stream = append(stream, bar)
+		}
+		d.Data.SetStream(stream)
+	}
+	d.Data.SortStream()
+
+	return nil
+}
+
+// Bars fetches the historical bars of a symbol, following the pages of the api.
+func (c *Client) Bars(symbol, timeframe string, start, end time.Time, adjustment string) ([]*gbt.Bar, error) {
+	query := url.Values{}
+	query.Set("timeframe", orDefault(timeframe, "1Day"))
+	query.Set("adjustment", orDefault(adjustment, "raw"))
+	query.Set("feed", c.feed())
+	query.Set("limit", "10000")
+	if !start.IsZero() {
+		query.Set("start", start.UTC().Format(time.RFC3339))
+	}
+	if !end.IsZero() {
+		query.Set("end", end.UTC().Format(time.RFC3339))
+	}
+
+	var bars []*gbt.Bar
+	for {
+		var page struct {
+			Bars []struct {
+				T time.Time `json:"t"`
+				O float64   `json:"o"`
+				H float64   `json:"h"`
+				L float64   `json:"l"`
+				C float64   `json:"c"`
+				V float64   `json:"v"`
+			} `json:"bars"`
+			NextPageToken *string `json:"next_page_token"`
+		}
+		if err := c.data("/v2/stocks/"+url.PathEscape(symbol)+"/bars?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("could not fetch bars of %s: %v", symbol, err)
+		}
+
+		for _, b := range page.Bars {
+			bar := &gbt.Bar{Open: b.O, High: b.H, Low: b.L, Close: b.C, AdjClose: b.C, Volume: b.V}
+			bar.SetTime(b.T)
+			bar.SetSymbol(symbol)
+			bars = append(bars, bar)
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			return bars, nil
+		}
+		query.Set("page_token", *page.NextPageToken)
+	}
+}
@@ -0,0 +1,58 @@
+package alpaca
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDataLoad(t *testing.T) {
+	a, ts := newAPI()
+	defer ts.Close()
+	a.reply("GET /v2/stocks/AAPL/bars", `{"bars":[
+		{"t":"2021-01-04T05:00:00Z","o":133.52,"h":133.61,"l":126.76,"c":129.41,"v":143301887},
+		{"t":"2021-01-05T05:00:00Z","o":128.89,"h":131.74,"l":128.43,"c":131.01,"v":97664898}
+	],"symbol":"AAPL","next_page_token":null}`)
+	a.reply("GET /v2/stocks/MSFT/bars", `{"bars":[
+		{"t":"2021-01-04T05:00:00Z","o":222.53,"h":223,"l":214.81,"c":217.69,"v":37130050}
+	],"symbol":"MSFT","next_page_token":""}`)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &Data{Client: newTestClient(ts.URL), Start: start}
+	if err := d.Load([]string{"AAPL", "MSFT"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+
+	stream := d.Stream()
+	if len(stream) != 3 || stream[0].Time().After(stream[2].Time()) || stream[2].Symbol() != "AAPL" || stream[2].Price() != 131.01 {
+		t.Errorf("Load(): expected 3 bars ordered by date, actual %v", stream)
+	}
+	query := a.queries["GET /v2/stocks/AAPL/bars"][0]
+	for _, param := range []string{"timeframe=1Day", "feed=iex", "adjustment=raw", "start=2021-01-01T00%3A00%3A00Z"} {
+		if !strings.Contains(query, param) {
+			t.Errorf("Load(): expected %v in the query, actual %v", param, query)
+		}
+	}
+
+	if err := (&Data{Client: newTestClient(ts.URL)}).Load([]string{"TSLA"}); err == nil {
+		t.Errorf("Load(): expected an error for a failed request")
+	}
+	if err := (&Data{}).Load([]string{"AAPL"}); err == nil {
+		t.Errorf("Load(): expected an error without client")
+	}
+}
+
+func TestBarsPages(t *testing.T) {
+	a, ts := newAPI()
+	defer ts.Close()
+	a.reply("GET /v2/stocks/AAPL/bars", `{"bars":[{"t":"2021-01-04T05:00:00Z","c":1}],"next_page_token":"p2"}`)
+	a.reply("GET /v2/stocks/AAPL/bars#p2", `{"bars":[{"t":"2021-01-05T05:00:00Z","c":2}],"next_page_token":null}`)
+
+	bars, err := newTestClient(ts.URL).Bars("AAPL", "1Hour", time.Time{}, time.Time{}, "all")
+	if err != nil || len(bars) != 2 || bars[1].Close != 2 {
+		t.Fatalf("Bars(): expected the bars of both pages, actual %v %v", bars, err)
+	}
+	if query := a.queries["GET /v2/stocks/AAPL/bars"]; len(query) != 2 || !strings.Contains(query[0], "timeframe=1Hour") || !strings.Contains(query[1], "page_token=p2") {
+		t.Errorf("Bars(): expected a request per page, actual %v", query)
+	}
+}
@@ -0,0 +1,90 @@
+package alpaca
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/stream"
+)
+
+// Feed streams the quotes of symbols from the market data stream and publishes them as ticks.
+type Feed struct {
+	*Client
+	Symbols []string
+	// Stream sets the heartbeat and the reconnects of the stream
+	Stream stream.Options
+}
+
+// NewFeed creates a feed of the quotes of symbols.
+func NewFeed(c *Client, symbols ...string) *Feed {
+	return &Feed{Client: c, Symbols: symbols}
+}
+
+// message is a message of the market data stream.
+type message struct {
+	Type    string    `json:"T"`
+	Msg     string    `json:"msg"`
+	Code    int       `json:"code"`
+	Symbol  string    `json:"S"`
+	BidPx   float64   `json:"bp"`
+	BidSize float64   `json:"bs"`
+	AskPx   float64   `json:"ap"`
+	AskSize float64   `json:"as"`
+	Time    time.Time `json:"t"`
+}
+
+// venue implements the stream.Venue of a feed.
+type venue struct {
+	*Feed
+}
+
+// URL returns the url of the stream of the data feed.
+func (v venue) URL() string {
+	return strings.TrimSuffix(orDefault(v.StreamURL, StreamURL), "/") + "/" + v.feed()
+}
+
+// Subscribe authenticates and subscribes to the quotes of the symbols.
+func (v venue) Subscribe(conn stream.Conn) error {
+	if err := conn.WriteJSON(map[string]string{"action": "auth", "key": v.KeyID, "secret": v.SecretKey}); err != nil {
+		return err
+	}
+	return conn.WriteJSON(map[string]interface{}{"action": "subscribe", "quotes": v.Symbols})
+}
+
+// Parse maps the quotes of a message to ticks, an error message of the stream is permanent.
+func (v venue) Parse(msg []byte) ([]gbt.DataEvent, error) {
+	var messages []message
+	if err := json.Unmarshal(msg, &messages); err != nil {
+		return nil, fmt.Errorf("could not parse message: %v", err)
+	}
+
+	var events []gbt.DataEvent
+	for _, m := range messages {
+		switch m.Type {
+		case "error":
+			return nil, stream.Permanent(fmt.Errorf("stream error %v: %s", m.Code, m.Msg))
+		case "q":
+			tick := &gbt.Tick{Bid: m.BidPx, Ask: m.AskPx, BidVolume: m.BidSize, AskVolume: m.AskSize}
+			tick.SetSymbol(m.Symbol)
+			tick.SetTime(m.Time)
+			events = append(events, tick)
+		}
+	}
+	return events, nil
+}
+
+// Run authenticates, subscribes to the quotes of the symbols and publishes every quote into
+// the live data, until the context is cancelled or the stream fails permanently. A lost
+// connection is reconnected. The live data is closed on return, so the backtest finishes.
+func (f *Feed) Run(ctx context.Context, data *gbt.LiveData) error {
+	if len(f.Symbols) == 0 {
+		data.Close()
+		return errors.New("no symbols to stream")
+	}
+	return stream.NewConsumer(venue{f}, f.Stream).Run(ctx, data)
+}
@@ -0,0 +1,107 @@
+package alpaca
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/internal/websocket"
+)
+
+// newStream starts a fake of the market data stream, which answers the subscription
+// with the messages.
+func newStream(t *testing.T, messages ...string) (*httptest.Server, chan string) {
+	requests := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/iex" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage([]byte(`[{"T":"success","msg":"connected"}]`))
+		for i := 0; i < 2; i++ {
+			request, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			requests <- string(request)
+		}
+		for _, m := range messages {
+			conn.WriteMessage([]byte(m))
+		}
+		// keep the connection open until the client leaves
+		conn.ReadMessage()
+	}))
+	return ts, requests
+}
+
+func TestFeedRun(t *testing.T) {
+	ts, requests := newStream(t,
+		`[{"T":"success","msg":"authenticated"}]`,
+		`[{"T":"subscription","quotes":["AAPL"]}]`,
+		`[{"T":"q","S":"AAPL","bp":150.1,"bs":3,"ap":150.2,"as":2,"t":"2021-02-22T15:51:44.208Z"},{"T":"q","S":"AAPL","bp":150.2,"bs":1,"ap":150.3,"as":4,"t":"2021-02-22T15:51:45Z"}]`,
+	)
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	c.StreamURL = "ws" + strings.TrimPrefix(ts.URL, "http") + "/v2"
+	feed := gbt.NewLiveData(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- NewFeed(c, "AAPL").Run(ctx, feed) }()
+
+	var ticks []*gbt.Tick
+	for e, ok := feed.NextContext(ctx); ok; e, ok = feed.NextContext(ctx) {
+		ticks = append(ticks, e.(*gbt.Tick))
+		if len(ticks) == 2 {
+			cancel()
+		}
+	}
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run(): expected the cancelled context, actual %v", err)
+	}
+
+	if auth := <-requests; auth != `{"action":"auth","key":"key","secret":"secret"}` {
+		t.Errorf("Run(): unexpected authentication %v", auth)
+	}
+	if subscribe := <-requests; subscribe != `{"action":"subscribe","quotes":["AAPL"]}` {
+		t.Errorf("Run(): unexpected subscription %v", subscribe)
+	}
+	if len(ticks) != 2 {
+		t.Fatalf("Run(): expected 2 ticks, actual %v", len(ticks))
+	}
+	if tick := ticks[0]; tick.Symbol() != "AAPL" || tick.Bid != 150.1 || tick.Ask != 150.2 || tick.AskVolume != 2 || tick.Time().Second() != 44 {
+		t.Errorf("Run(): unexpected tick %+v", tick)
+	}
+}
+
+func TestFeedRunError(t *testing.T) {
+	ts, _ := newStream(t, `[{"T":"error","code":402,"msg":"auth failed"}]`)
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	c.StreamURL = "ws" + strings.TrimPrefix(ts.URL, "http") + "/v2/"
+	feed := gbt.NewLiveData(10)
+
+	err := NewFeed(c, "AAPL").Run(context.Background(), feed)
+	if err == nil || err.Error() != "stream error 402: auth failed" {
+		t.Errorf("Run(): expected the error of the stream, actual %v", err)
+	}
+	if feed.Publish(&gbt.Tick{}) {
+		t.Errorf("Run(): expected the live data to be closed")
+	}
+	if err := NewFeed(c).Run(context.Background(), gbt.NewLiveData(0)); err == nil {
+		t.Errorf("Run(): expected an error without symbols")
+	}
+}
@@ -0,0 +1,190 @@
+// Package binance connects strategies to the Binance spot and USDⓈ-M perpetual futures markets.
+// The Broker submits the orders and reports the balances of an account, the Feed streams
+// closed klines and book tickers over the websocket into the live data of a backtest:
+//
+//	client := binance.NewClient(key, secret, binance.Spot)
+//	feed := gbt.NewLiveData(100)
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetBroker(binance.NewBroker(client))
+//	go binance.NewFeed(client, "BTCUSDT").Run(ctx, feed)
+//	err := test.RunContext(ctx)
+//
+// The historical klines of Data replace the csv files of a backtest:
+//
+//	data := &binance.Data{Client: client, Interval: "1h", Start: start, End: end}
+//	test.SetData(data)
+//
+// The symbols of the engine are the symbols of Binance, e.g. BTCUSDT.
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Market selects the market of a client.
+type Market string
+
+// the supported markets
+const (
+	Spot    Market = "spot"
+	Futures Market = "futures" // USDⓈ-M perpetual futures
+)
+
+// default endpoints of the markets
+const (
+	SpotURL          = "https://api.binance.com"
+	FuturesURL       = "https://fapi.binance.com"
+	SpotStreamURL    = "wss://stream.binance.com:9443"
+	FuturesStreamURL = "wss://fstream.binance.com"
+)
+
+// Client is a client of the rest api of a market.
+type Client struct {
+	APIKey    string
+	SecretKey string
+	Market    Market
+	URL       string // defaults to the url of the market
+	StreamURL string // defaults to the stream url of the market
+	// RecvWindow sets the validity of a signed request, defaults to 5 seconds
+	RecvWindow time.Duration
+	HTTP       *http.Client  // defaults to http.DefaultClient
+	Timeout    time.Duration // timeout of a request, defaults to 10 seconds
+
+	now func() time.Time
+}
+
+// NewClient creates a client of a market.
+func NewClient(apiKey, secretKey string, market Market) *Client {
+	return &Client{APIKey: apiKey, SecretKey: secretKey, Market: market}
+}
+
+// futures returns if the client trades the futures market.
+func (c *Client) futures() bool {
+	return c.Market == Futures
+}
+
+// byMarket returns the value of the market, e.g. the path of an endpoint.
+func (c *Client) byMarket(spot, futures string) string {
+	if c.futures() {
+		return futures
+	}
+	return spot
+}
+
+// signed sends a request signed with the secret key.
+func (c *Client) signed(method, path string, params url.Values, v interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	window := c.RecvWindow
+	if window <= 0 {
+		window = 5 * time.Second
+	}
+	params.Set("timestamp", strconv.FormatInt(now().UnixNano()/int64(time.Millisecond), 10))
+	params.Set("recvWindow", strconv.FormatInt(int64(window/time.Millisecond), 10))
+
+	query := params.Encode()
+	mac := hmac.New(sha256.New, []byte(c.SecretKey))
+	mac.Write([]byte(query))
+	return c.do(method, path, query+"&signature="+hex.EncodeToString(mac.Sum(nil)), v)
+}
+
+// public sends an unsigned request.
+func (c *Client) public(path string, params url.Values, v interface{}) error {
+	return c.do(http.MethodGet, path, params.Encode(), v)
+}
+
+// do sends a request with the parameters in the query and decodes the json response into v.
+func (c *Client) do(method, path, query string, v interface{}) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	base := c.URL
+	if base == "" {
+		base = c.byMarket(SpotURL, FuturesURL)
+	}
+	u := strings.TrimSuffix(base, "/") + path
+	if query != "" {
+		u += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", c.APIKey)
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(content, &e) == nil && e.Msg != "" {
+			return fmt.Errorf("%s %s: %s (%d)", method, path, e.Msg, e.Code)
+		}
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("%s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// decimal is a number of the api, which sends prices and quantities as strings.
+type decimal float64
+
+// UnmarshalJSON implements json.Unmarshaler for strings and numbers.
+func (d *decimal) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*d = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*d = decimal(f)
+	return nil
+}
+
+// formatNumber formats a number for the api.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
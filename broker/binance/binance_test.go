@@ -0,0 +1,132 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// exchange is a fake of the rest api, which verifies the signatures.
+type exchange struct {
+	mu      sync.Mutex
+	params  map[string]url.Values // last parameters by method and path
+	replies map[string]string     // response by method and path, or by route#startTime for pages
+}
+
+func newExchange() (*exchange, *httptest.Server) {
+	e := &exchange{params: make(map[string]url.Values), replies: make(map[string]string)}
+	return e, httptest.NewServer(e)
+}
+
+func (e *exchange) reply(route, body string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.replies[route] = body
+}
+
+func (e *exchange) param(route, name string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.params[route].Get(name)
+}
+
+func (e *exchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	route := r.Method + " " + r.URL.Path
+	query := r.URL.RawQuery
+	if i := strings.Index(query, "&signature="); i >= 0 {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(query[:i]))
+		if r.Header.Get("X-MBX-APIKEY") != "key" || query[i+len("&signature="):] != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+			return
+		}
+	}
+	e.params[route] = r.URL.Query()
+
+	reply, ok := e.replies[route+"#"+r.URL.Query().Get("startTime")]
+	if !ok {
+		reply, ok = e.replies[route]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(reply))
+}
+
+// newTestClient creates a client of the fake api.
+func newTestClient(url string, market Market) *Client {
+	c := NewClient("key", "secret", market)
+	c.URL = url
+	return c
+}
+
+func TestClientSigned(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("GET /api/v3/account", `{"balances":[]}`)
+
+	c := newTestClient(ts.URL, Spot)
+	c.now = func() time.Time { return time.Unix(1499827319, 559000000) }
+	if err := c.signed(http.MethodGet, "/api/v3/account", url.Values{"symbol": {"LTCBTC"}}, nil); err != nil {
+		t.Fatalf("signed(): unexpected error %v", err)
+	}
+	if e.param("GET /api/v3/account", "timestamp") != "1499827319559" || e.param("GET /api/v3/account", "recvWindow") != "5000" {
+		t.Errorf("signed(): expected the timestamp and receive window, actual %v", e.params)
+	}
+
+	// testCases is a table for testing failed requests
+	var testCases = []struct {
+		msg    string
+		client *Client
+		path   string
+		expErr string
+	}{
+		{"wrong secret:", &Client{APIKey: "key", SecretKey: "wrong", URL: ts.URL}, "/api/v3/account", "GET /api/v3/account: Signature for this request is not valid. (-1022)"},
+		{"error of the api:", c, "/api/v3/missing", "GET /api/v3/missing: Invalid symbol. (-1121)"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.client.signed(http.MethodGet, tc.path, nil, nil)
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("%v signed(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	// testCases is a table for testing the numbers of the api
+	var testCases = []struct {
+		raw    string
+		expVal decimal
+		expErr bool
+	}{
+		{`"0.00150000"`, 0.0015, false},
+		{`25.35`, 25.35, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"abc"`, 0, true},
+	}
+
+	for _, tc := range testCases {
+		var d decimal
+		err := json.Unmarshal([]byte(tc.raw), &d)
+		if d != tc.expVal || (err != nil) != tc.expErr {
+			t.Errorf("UnmarshalJSON(%v): expected %v with error %v, actual %v %v", tc.raw, tc.expVal, tc.expErr, d, err)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/internal/orders"
+)
+
+// Broker implements gobacktest.Broker for a spot or futures account.
+// Binance numbers the orders per symbol, so an order is tracked by its symbol and order id.
+//
+// On the spot market the cash is the balance of the Quote asset, the other assets with a balance
+// are positions in their symbol with the Quote asset, valued at the latest price. On the futures
+// market the cash is the wallet balance and the value the margin balance.
+type Broker struct {
+	*Client
+	Quote string // quote asset of the cash, defaults to USDT
+	// OnError receives the failed requests of the open orders, the order status and the
+	// account, on futures of the position risk
+	OnError func(error)
+
+	orders  orders.Tracker
+	session int64 // makes the client order ids unique across sessions
+}
+
+// NewBroker creates a broker of the account of a client.
+func NewBroker(c *Client) *Broker {
+	return &Broker{Client: c, session: time.Now().Unix()}
+}
+
+// order is an order of the api.
+type order struct {
+	Symbol  string `json:"symbol"`
+	OrderID int64  `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+// Submit places an order.
+func (b *Broker) Submit(o *gbt.Order) error {
+	if o == nil || o.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	if o.Qty() <= 0 {
+		return fmt.Errorf("could not submit order for %s, qty %v not positive", o.Symbol(), o.Qty())
+	}
+
+	b.orders.Assign(o)
+	params, err := b.params(o)
+	if err != nil {
+		return err
+	}
+
+	var placed order
+	if err := b.signed(http.MethodPost, b.byMarket("/api/v3/order", "/fapi/v1/order"), params, &placed); err != nil {
+		return fmt.Errorf("could not submit order for %s: %v", o.Symbol(), err)
+	}
+
+	if o.Time().IsZero() {
+		o.SetTime(time.Now())
+	}
+	o.SetStatus(gbt.OrderSubmitted)
+	b.orders.Add(o, remoteID(o.Symbol(), placed.OrderID))
+	return nil
+}
+
+// Cancel cancels a resting order.
+func (b *Broker) Cancel(id int) error {
+	o, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{"symbol": {o.Symbol()}, "orderId": {orderID(remote)}}
+	if err := b.signed(http.MethodDelete, b.byMarket("/api/v3/order", "/fapi/v1/order"), params, nil); err != nil {
+		return fmt.Errorf("could not cancel order %v: %v", id, err)
+	}
+	b.orders.Finish(id, gbt.OrderCanceled)
+	return nil
+}
+
+// Modify changes a resting order. On the spot market the order is replaced with the
+// cancel-replace endpoint, on the futures market limit orders are modified in place.
+func (b *Broker) Modify(id int, change gbt.OrderChange) error {
+	o, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	modified := *o
+	if change.Qty > 0 {
+		modified.SetQty(change.Qty)
+	}
+	if change.Limit > 0 {
+		modified.SetLimit(change.Limit)
+	}
+	if change.Stop > 0 {
+		modified.SetStop(change.Stop)
+	}
+	params, err := b.params(&modified)
+	if err != nil {
+		return err
+	}
+
+	newID := remote
+	if b.futures() {
+		if o.Type() != gbt.LimitOrder {
+			return fmt.Errorf("could not modify order %v, only limit orders can be modified on the futures market", id)
+		}
+		modify := url.Values{
+			"symbol":   {o.Symbol()},
+			"orderId":  {orderID(remote)},
+			"side":     params["side"],
+			"quantity": params["quantity"],
+			"price":    params["price"],
+		}
+		if err := b.signed(http.MethodPut, "/fapi/v1/order", modify, nil); err != nil {
+			return fmt.Errorf("could not modify order %v: %v", id, err)
+		}
+	} else {
+		params.Set("cancelReplaceMode", "STOP_ON_FAILURE")
+		params.Set("cancelOrderId", orderID(remote))
+		var replaced struct {
+			NewOrderResponse order `json:"newOrderResponse"`
+		}
+		if err := b.signed(http.MethodPost, "/api/v3/order/cancelReplace", params, &replaced); err != nil {
+			return fmt.Errorf("could not modify order %v: %v", id, err)
+		}
+		newID = remoteID(o.Symbol(), replaced.NewOrderResponse.OrderID)
+	}
+
+	b.orders.Update(id, func(o *gbt.Order) {
+		o.SetQty(modified.Qty())
+		o.SetLimit(modified.Limit())
+		o.SetStop(modified.Stop())
+	})
+	b.orders.Add(o, newID)
+	return nil
+}
+
+// Orders returns the open orders. The status of a tracked order, which is no longer open,
+// is queried and the order is removed.
+func (b *Broker) Orders() []gbt.OrderEvent {
+	var open []order
+	if err := b.signed(http.MethodGet, b.byMarket("/api/v3/openOrders", "/fapi/v1/openOrders"), nil, &open); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch orders: %v", err))
+		return b.orders.Orders()
+	}
+	isOpen := make(map[string]bool)
+	for _, o := range open {
+		isOpen[remoteID(o.Symbol, o.OrderID)] = true
+	}
+
+	for _, tracked := range b.orders.Orders() {
+		_, remote, err := b.orders.Lookup(tracked.ID())
+		if err != nil || isOpen[remote] {
+			continue
+		}
+
+		var status order
+		params := url.Values{"symbol": {tracked.Symbol()}, "orderId": {orderID(remote)}}
+		if err := b.signed(http.MethodGet, b.byMarket("/api/v3/order", "/fapi/v1/order"), params, &status); err != nil {
+			orders.Report(b.OnError, fmt.Errorf("could not fetch order %v: %v", tracked.ID(), err))
+			continue
+		}
+		switch status.Status {
+		case "FILLED":
+			b.orders.Finish(tracked.ID(), gbt.OrderFilled)
+		case "CANCELED", "EXPIRED", "REJECTED", "EXPIRED_IN_MATCH":
+			b.orders.Finish(tracked.ID(), gbt.OrderCanceled)
+		}
+	}
+	return b.orders.Orders()
+}
+
+// Positions returns the open positions by symbol.
+func (b *Broker) Positions() map[string]gbt.Position {
+	positions := make(map[string]gbt.Position)
+	now := time.Now()
+
+	if b.futures() {
+		var risks []struct {
+			Symbol      string  `json:"symbol"`
+			PositionAmt decimal `json:"positionAmt"`
+			EntryPrice  decimal `json:"entryPrice"`
+			MarkPrice   decimal `json:"markPrice"`
+		}
+		if err := b.signed(http.MethodGet, "/fapi/v2/positionRisk", nil, &risks); err != nil {
+			orders.Report(b.OnError, fmt.Errorf("could not fetch positions: %v", err))
+			return positions
+		}
+		for _, p := range risks {
+			if p.PositionAmt != 0 {
+				positions[p.Symbol] = gbt.NewPosition(now, p.Symbol, float64(p.PositionAmt), float64(p.EntryPrice), float64(p.MarkPrice))
+			}
+		}
+		return positions
+	}
+
+	balances, prices, err := b.spot()
+	if err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch positions: %v", err))
+		return positions
+	}
+	for asset, qty := range balances {
+		symbol := asset + b.quote()
+		price, ok := prices[symbol]
+		if asset == b.quote() || qty == 0 || !ok {
+			continue
+		}
+		// the entry price of a balance is unknown, it is valued at the latest price
+		positions[symbol] = gbt.NewPosition(now, symbol, qty, price, price)
+	}
+	return positions
+}
+
+// Cash returns the balance of the quote asset, or the wallet balance of the futures account.
+func (b *Broker) Cash() float64 {
+	if b.futures() {
+		cash, _ := b.futuresAccount()
+		return cash
+	}
+
+	balances, _, err := b.spot()
+	if err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch cash: %v", err))
+		return 0
+	}
+	return balances[b.quote()]
+}
+
+// Value returns the value of the account in the quote asset, or the margin balance of the futures account.
+func (b *Broker) Value() float64 {
+	if b.futures() {
+		_, value := b.futuresAccount()
+		return value
+	}
+
+	balances, prices, err := b.spot()
+	if err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch value: %v", err))
+		return 0
+	}
+	value := balances[b.quote()]
+	for asset, qty := range balances {
+		if price, ok := prices[asset+b.quote()]; ok && asset != b.quote() {
+			value += qty * price
+		}
+	}
+	return value
+}
+
+// spot returns the balances of the spot account by asset and the latest prices by symbol.
+func (b *Broker) spot() (map[string]float64, map[string]float64, error) {
+	var account struct {
+		Balances []struct {
+			Asset  string  `json:"asset"`
+			Free   decimal `json:"free"`
+			Locked decimal `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := b.signed(http.MethodGet, "/api/v3/account", nil, &account); err != nil {
+		return nil, nil, err
+	}
+	balances := make(map[string]float64)
+	for _, balance := range account.Balances {
+		if qty := float64(balance.Free + balance.Locked); qty != 0 {
+			balances[balance.Asset] = qty
+		}
+	}
+
+	var tickers []struct {
+		Symbol string  `json:"symbol"`
+		Price  decimal `json:"price"`
+	}
+	if err := b.public("/api/v3/ticker/price", nil, &tickers); err != nil {
+		return nil, nil, err
+	}
+	prices := make(map[string]float64)
+	for _, ticker := range tickers {
+		prices[ticker.Symbol] = float64(ticker.Price)
+	}
+	return balances, prices, nil
+}
+
+// futuresAccount returns the wallet and margin balance of the futures account.
+func (b *Broker) futuresAccount() (float64, float64) {
+	var account struct {
+		TotalWalletBalance decimal `json:"totalWalletBalance"`
+		TotalMarginBalance decimal `json:"totalMarginBalance"`
+	}
+	if err := b.signed(http.MethodGet, "/fapi/v2/account", nil, &account); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch account: %v", err))
+		return 0, 0
+	}
+	return float64(account.TotalWalletBalance), float64(account.TotalMarginBalance)
+}
+
+// params creates the parameters of a new order.
+func (b *Broker) params(o *gbt.Order) (url.Values, error) {
+	params := url.Values{
+		"symbol":           {o.Symbol()},
+		"quantity":         {formatNumber(o.Qty())},
+		"newClientOrderId": {fmt.Sprintf("gbt-%d-%d", b.session, o.ID())},
+	}
+
+	switch o.Direction() {
+	case gbt.BOT:
+		params.Set("side", "BUY")
+	case gbt.SLD:
+		params.Set("side", "SELL")
+	default:
+		return nil, fmt.Errorf("could not submit order for %s, direction %v not supported", o.Symbol(), o.Direction())
+	}
+
+	switch o.Type() {
+	case gbt.MarketOrder:
+		params.Set("type", "MARKET")
+	case gbt.LimitOrder:
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "GTC")
+		params.Set("price", formatNumber(o.Limit()))
+	case gbt.StopMarketOrder:
+		params.Set("type", b.byMarket("STOP_LOSS", "STOP_MARKET"))
+		params.Set("stopPrice", formatNumber(o.Stop()))
+	case gbt.StopLimitOrder:
+		params.Set("type", b.byMarket("STOP_LOSS_LIMIT", "STOP"))
+		params.Set("timeInForce", "GTC")
+		params.Set("price", formatNumber(o.Limit()))
+		params.Set("stopPrice", formatNumber(o.Stop()))
+	default:
+		return nil, fmt.Errorf("could not submit order for %s, type %v not supported", o.Symbol(), o.Type())
+	}
+	return params, nil
+}
+
+// quote returns the quote asset of the cash.
+func (b *Broker) quote() string {
+	if b.Quote == "" {
+		return "USDT"
+	}
+	return b.Quote
+}
+
+// remoteID returns the tracked id of an order, the order ids of Binance are unique per symbol.
+func remoteID(symbol string, id int64) string {
+	return symbol + ":" + strconv.FormatInt(id, 10)
+}
+
+// orderID returns the order id of Binance of a tracked id.
+func orderID(remote string) string {
+	return remote[strings.LastIndex(remote, ":")+1:]
+}
@@ -0,0 +1,173 @@
+package binance
+
+import (
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// the broker implements the broker interface of the engine
+var _ gbt.Broker = &Broker{}
+
+// newOrder creates an order of the engine.
+func newOrder(symbol string, orderType gbt.OrderType, direction gbt.Direction, qty, limit float64) *gbt.Order {
+	order := &gbt.Order{}
+	order.SetSymbol(symbol)
+	order.SetType(orderType)
+	order.SetDirection(direction)
+	order.SetQty(qty)
+	order.SetLimit(limit)
+	return order
+}
+
+func TestBrokerSpotOrders(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("POST /api/v3/order", `{"symbol":"BTCUSDT","orderId":28,"status":"NEW"}`)
+	e.reply("POST /api/v3/order/cancelReplace", `{"cancelResult":"SUCCESS","newOrderResult":"SUCCESS","newOrderResponse":{"symbol":"BTCUSDT","orderId":29,"status":"NEW"}}`)
+	e.reply("DELETE /api/v3/order", `{"symbol":"BTCUSDT","orderId":29,"status":"CANCELED"}`)
+
+	b := NewBroker(newTestClient(ts.URL, Spot))
+	order := newOrder("BTCUSDT", gbt.LimitOrder, gbt.BOT, 0.5, 30000)
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	route := "POST /api/v3/order"
+	if e.param(route, "symbol") != "BTCUSDT" || e.param(route, "side") != "BUY" || e.param(route, "type") != "LIMIT" ||
+		e.param(route, "quantity") != "0.5" || e.param(route, "price") != "30000" || e.param(route, "timeInForce") != "GTC" {
+		t.Errorf("Submit(): unexpected order request %v", e.params[route])
+	}
+
+	e.reply("GET /api/v3/openOrders", `[{"symbol":"BTCUSDT","orderId":28,"status":"NEW"}]`)
+	if orders := b.Orders(); len(orders) != 1 || orders[0] != gbt.OrderEvent(order) {
+		t.Errorf("Orders(): expected the open order, actual %v", orders)
+	}
+
+	// the spot market replaces the order
+	if err := b.Modify(1, gbt.OrderChange{Limit: 29500}); err != nil {
+		t.Fatalf("Modify(): unexpected error %v", err)
+	}
+	route = "POST /api/v3/order/cancelReplace"
+	if e.param(route, "cancelOrderId") != "28" || e.param(route, "price") != "29500" || e.param(route, "cancelReplaceMode") != "STOP_ON_FAILURE" || order.Limit() != 29500 {
+		t.Errorf("Modify(): unexpected replace request %v", e.params[route])
+	}
+
+	if err := b.Cancel(1); err != nil {
+		t.Fatalf("Cancel(): unexpected error %v", err)
+	}
+	if e.param("DELETE /api/v3/order", "orderId") != "29" || order.Status() != gbt.OrderCanceled {
+		t.Errorf("Cancel(): expected the replacement to be canceled, actual %v", e.params["DELETE /api/v3/order"])
+	}
+
+	// an order missing from the open orders is queried
+	e.reply("POST /api/v3/order", `{"symbol":"BTCUSDT","orderId":30,"status":"NEW"}`)
+	filled := newOrder("BTCUSDT", gbt.StopMarketOrder, gbt.SLD, 0.5, 0)
+	filled.SetStop(28000)
+	if err := b.Submit(filled); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	if e.param("POST /api/v3/order", "type") != "STOP_LOSS" || e.param("POST /api/v3/order", "stopPrice") != "28000" {
+		t.Errorf("Submit(): expected a stop loss order, actual %v", e.params["POST /api/v3/order"])
+	}
+	e.reply("GET /api/v3/openOrders", `[]`)
+	e.reply("GET /api/v3/order", `{"symbol":"BTCUSDT","orderId":30,"status":"FILLED"}`)
+	if orders := b.Orders(); len(orders) != 0 || filled.Status() != gbt.OrderFilled {
+		t.Errorf("Orders(): expected the filled order to be removed, actual %v", orders)
+	}
+}
+
+func TestBrokerFuturesOrders(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("POST /fapi/v1/order", `{"symbol":"BTCUSDT","orderId":7,"status":"NEW"}`)
+	e.reply("PUT /fapi/v1/order", `{"symbol":"BTCUSDT","orderId":7,"status":"NEW"}`)
+
+	b := NewBroker(newTestClient(ts.URL, Futures))
+	limit := newOrder("BTCUSDT", gbt.LimitOrder, gbt.SLD, 1, 31000)
+	if err := b.Submit(limit); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	if err := b.Modify(limit.ID(), gbt.OrderChange{Qty: 2}); err != nil {
+		t.Fatalf("Modify(): unexpected error %v", err)
+	}
+	if e.param("PUT /fapi/v1/order", "orderId") != "7" || e.param("PUT /fapi/v1/order", "quantity") != "2" || e.param("PUT /fapi/v1/order", "price") != "31000" {
+		t.Errorf("Modify(): unexpected modify request %v", e.params["PUT /fapi/v1/order"])
+	}
+
+	stop := newOrder("BTCUSDT", gbt.StopLimitOrder, gbt.BOT, 1, 32000)
+	stop.SetStop(31900)
+	if err := b.Submit(stop); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	if e.param("POST /fapi/v1/order", "type") != "STOP" {
+		t.Errorf("Submit(): expected a stop order, actual %v", e.params["POST /fapi/v1/order"])
+	}
+	if err := b.Modify(stop.ID(), gbt.OrderChange{Limit: 32100}); err == nil {
+		t.Errorf("Modify(): expected an error for a stop order on the futures market")
+	}
+
+	// testCases is a table for testing rejected orders
+	var testCases = []struct {
+		msg   string
+		order *gbt.Order
+	}{
+		{"order without symbol:", newOrder("", gbt.MarketOrder, gbt.BOT, 1, 0)},
+		{"market on close:", newOrder("BTCUSDT", gbt.MarketOnCloseOrder, gbt.BOT, 1, 0)},
+		{"hold direction:", newOrder("BTCUSDT", gbt.MarketOrder, gbt.HLD, 1, 0)},
+	}
+	for _, tc := range testCases {
+		if err := b.Submit(tc.order); err == nil {
+			t.Errorf("%v Submit(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestBrokerAccount(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("GET /api/v3/account", `{"balances":[
+		{"asset":"BTC","free":"0.5","locked":"0.5"},
+		{"asset":"USDT","free":"1000","locked":"0"},
+		{"asset":"XYZ","free":"10","locked":"0"},
+		{"asset":"ETH","free":"0","locked":"0"}
+	]}`)
+	e.reply("GET /api/v3/ticker/price", `[{"symbol":"BTCUSDT","price":"30000"},{"symbol":"ETHUSDT","price":"2000"}]`)
+	e.reply("GET /fapi/v2/positionRisk", `[
+		{"symbol":"BTCUSDT","positionAmt":"-0.1","entryPrice":"31000","markPrice":"30000"},
+		{"symbol":"ETHUSDT","positionAmt":"0","entryPrice":"0","markPrice":"2000"}
+	]`)
+	e.reply("GET /fapi/v2/account", `{"totalWalletBalance":"5000","totalMarginBalance":"5100"}`)
+
+	// testCases is a table for testing the balances of the markets
+	var testCases = []struct {
+		msg      string
+		market   Market
+		expQty   float64
+		expCash  float64
+		expValue float64
+	}{
+		{"spot:", Spot, 1, 1000, 31000},
+		{"futures:", Futures, -0.1, 5000, 5100},
+	}
+
+	for _, tc := range testCases {
+		b := NewBroker(newTestClient(ts.URL, tc.market))
+		b.OnError = func(err error) { t.Errorf("%v unexpected error %v", tc.msg, err) }
+
+		positions := b.Positions()
+		if len(positions) != 1 || positions["BTCUSDT"].Qty() != tc.expQty {
+			t.Errorf("%v Positions(): expected %v BTCUSDT, actual %+v", tc.msg, tc.expQty, positions)
+		}
+		if b.Cash() != tc.expCash || b.Value() != tc.expValue {
+			t.Errorf("%v Cash(): expected cash %v and value %v, actual %v %v", tc.msg, tc.expCash, tc.expValue, b.Cash(), b.Value())
+		}
+	}
+
+	var errs []error
+	b := NewBroker(newTestClient(ts.URL, Spot))
+	b.SecretKey = "wrong"
+	b.OnError = func(err error) { errs = append(errs, err) }
+	if len(b.Positions()) != 0 || b.Cash() != 0 || b.Value() != 0 || len(b.Orders()) != 0 || len(errs) != 4 {
+		t.Errorf("OnError: expected 4 errors, actual %v", errs)
+	}
+}
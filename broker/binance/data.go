@@ -0,0 +1,145 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// klineLimit is the max number of klines of a request.
+const klineLimit = 1000
+
+// Data loads the historical klines of the market into the data stream.
+// It expands the underlying data struct.
+type Data struct {
+	gbt.Data
+	Client     *Client
+	Interval   string // e.g. 1m, 1h or 1d, defaults to 1d
+	Start, End time.Time
+}
+
+// Load the bars of the symbols into the stream ordered by date.
+func (d *Data) Load(symbols []string) error {
+	if d.Client == nil {
+		return errors.New("no binance client provided")
+	}
+	if len(symbols) == 0 {
+		return errors.New("no symbols provided")
+	}
+
+	for _, symbol := range symbols {
+		bars, err := d.Client.Klines(symbol, d.Interval, d.Start, d.End)
+		if err != nil {
+			return err
+		}
+		stream := d.Data.Stream()
+		for _, bar := range bars {
+			stream = append(stream, bar)
+		}
+		d.Data.SetStream(stream)
+	}
+	d.Data.SortStream()
+
+	return nil
+}
+
+// Klines fetches the closed klines of a symbol as bars at the end of their interval,
+// following the pages of the api.
+func (c *Client) Klines(symbol, interval string, start, end time.Time) ([]*gbt.Bar, error) {
+	if interval == "" {
+		interval = "1d"
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	limit := now()
+	if !end.IsZero() && end.Before(limit) {
+		limit = end
+	}
+
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("interval", interval)
+	query.Set("limit", strconv.Itoa(klineLimit))
+	if !start.IsZero() {
+		query.Set("startTime", strconv.FormatInt(start.UnixNano()/int64(time.Millisecond), 10))
+	}
+	if !end.IsZero() {
+		query.Set("endTime", strconv.FormatInt(end.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	var bars []*gbt.Bar
+	for {
+		var klines [][]json.RawMessage
+		if err := c.public(c.byMarket("/api/v3/klines", "/fapi/v1/klines"), query, &klines); err != nil {
+			return nil, fmt.Errorf("could not fetch klines of %s: %v", symbol, err)
+		}
+
+		var openTime int64
+		for _, k := range klines {
+			var values [7]decimal
+			if len(k) < len(values) {
+				return nil, fmt.Errorf("could not parse kline of %s with %d fields", symbol, len(k))
+			}
+			for i := range values {
+				if err := json.Unmarshal(k[i], &values[i]); err != nil {
+					return nil, fmt.Errorf("could not parse kline of %s: %v", symbol, err)
+				}
+			}
+			openTime = int64(values[0])
+
+			// the close time is the last millisecond of the interval
+			closed := millis(int64(values[6]) + 1)
+			if closed.After(limit) {
+				continue
+			}
+			bar := &gbt.Bar{Open: float64(values[1]), High: float64(values[2]), Low: float64(values[3]), Close: float64(values[4]), AdjClose: float64(values[4]), Volume: float64(values[5])}
+			bar.SetSymbol(symbol)
+			bar.SetTime(closed)
+			bars = append(bars, bar)
+		}
+
+		if len(klines) < klineLimit {
+			return bars, nil
+		}
+		query.Set("startTime", strconv.FormatInt(openTime+1, 10))
+	}
+}
+
+// Symbol is a trading symbol of the market.
+type Symbol struct {
+	Symbol string `json:"symbol"`
+	Base   string `json:"baseAsset"`
+	Quote  string `json:"quoteAsset"`
+	Margin string `json:"marginAsset"` // only on the futures market
+}
+
+// Symbols returns the symbols of the market open for trading, on the futures market
+// only the perpetual contracts.
+func (c *Client) Symbols() ([]Symbol, error) {
+	var info struct {
+		Symbols []struct {
+			Symbol
+			Status       string `json:"status"`
+			ContractType string `json:"contractType"`
+		} `json:"symbols"`
+	}
+	if err := c.public(c.byMarket("/api/v3/exchangeInfo", "/fapi/v1/exchangeInfo"), nil, &info); err != nil {
+		return nil, fmt.Errorf("could not fetch symbols: %v", err)
+	}
+
+	var symbols []Symbol
+	for _, s := range info.Symbols {
+		if s.Status != "TRADING" || (c.futures() && s.ContractType != "PERPETUAL") {
+			continue
+		}
+		symbols = append(symbols, s.Symbol)
+	}
+	return symbols, nil
+}
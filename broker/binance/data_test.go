@@ -0,0 +1,111 @@
+package binance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// klines returns n klines of 1m, starting at the open time in milliseconds.
+func klines(open int64, n int) string {
+	var k []string
+	for i := int64(0); i < int64(n); i++ {
+		t := open + i*60000
+		k = append(k, fmt.Sprintf(`[%d,"1.0","2.0","0.5","1.5","10",%d,"15",3,"5","7.5","0"]`, t, t+59999))
+	}
+	return "[" + strings.Join(k, ",") + "]"
+}
+
+func TestClientKlines(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+
+	start := time.Unix(1600000020, 0).UTC() // 1600000020000 ms
+	e.reply("GET /api/v3/klines#1600000020000", klines(1600000020000, klineLimit))
+	e.reply("GET /api/v3/klines#1600059960001", klines(1600059960001+59999, 2))
+
+	c := newTestClient(ts.URL, Spot)
+	// the last kline is not closed yet
+	c.now = func() time.Time { return millis(1600059960001 + 59999 + 60000) }
+	bars, err := c.Klines("BTCUSDT", "1m", start, time.Time{})
+	if err != nil {
+		t.Fatalf("Klines(): unexpected error %v", err)
+	}
+	if len(bars) != klineLimit+1 {
+		t.Fatalf("Klines(): expected %d bars over two pages, actual %d", klineLimit+1, len(bars))
+	}
+	if bar := bars[0]; bar.Symbol() != "BTCUSDT" || bar.Open != 1 || bar.High != 2 || bar.Low != 0.5 || bar.Close != 1.5 || bar.Volume != 10 || !bar.Time().Equal(millis(1600000080000)) {
+		t.Errorf("Klines(): unexpected first bar %+v", bar)
+	}
+	if e.param("GET /api/v3/klines", "interval") != "1m" || e.param("GET /api/v3/klines", "limit") != "1000" {
+		t.Errorf("Klines(): unexpected query %v", e.params["GET /api/v3/klines"])
+	}
+
+	// testCases is a table for testing failed klines
+	var testCases = []struct {
+		msg   string
+		reply string
+	}{
+		{"short kline:", `[[1600000020000,"1.0"]]`},
+		{"invalid number:", `[[1600000020000,"a","2.0","0.5","1.5","10",1600000079999]]`},
+		{"invalid json:", `{}`},
+	}
+
+	for _, tc := range testCases {
+		e.reply("GET /fapi/v1/klines", tc.reply)
+		if _, err := newTestClient(ts.URL, Futures).Klines("BTCUSDT", "", time.Time{}, time.Time{}); err == nil {
+			t.Errorf("%v Klines(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestDataLoad(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("GET /api/v3/klines", klines(1600000020000, 3))
+
+	d := &Data{Client: newTestClient(ts.URL, Spot), Interval: "1m"}
+	if err := d.Load([]string{"BTCUSDT", "ETHUSDT"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+	if stream := d.Stream(); len(stream) != 6 || !stream[0].Time().Equal(stream[1].Time()) {
+		t.Errorf("Load(): expected 6 sorted bars, actual %v", stream)
+	}
+
+	if err := (&Data{}).Load([]string{"BTCUSDT"}); err == nil {
+		t.Errorf("Load(): expected an error without client")
+	}
+	if err := d.Load(nil); err == nil {
+		t.Errorf("Load(): expected an error without symbols")
+	}
+}
+
+func TestClientSymbols(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("GET /api/v3/exchangeInfo", `{"symbols":[
+		{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT"},
+		{"symbol":"LUNAUSDT","status":"BREAK","baseAsset":"LUNA","quoteAsset":"USDT"}
+	]}`)
+	e.reply("GET /fapi/v1/exchangeInfo", `{"symbols":[
+		{"symbol":"BTCUSDT","status":"TRADING","contractType":"PERPETUAL","baseAsset":"BTC","quoteAsset":"USDT","marginAsset":"USDT"},
+		{"symbol":"BTCUSDT_240329","status":"TRADING","contractType":"CURRENT_QUARTER","baseAsset":"BTC","quoteAsset":"USDT","marginAsset":"USDT"}
+	]}`)
+
+	// testCases is a table for testing the symbols of the markets
+	var testCases = []struct {
+		market Market
+		exp    Symbol
+	}{
+		{Spot, Symbol{Symbol: "BTCUSDT", Base: "BTC", Quote: "USDT"}},
+		{Futures, Symbol{Symbol: "BTCUSDT", Base: "BTC", Quote: "USDT", Margin: "USDT"}},
+	}
+
+	for _, tc := range testCases {
+		symbols, err := newTestClient(ts.URL, tc.market).Symbols()
+		if err != nil || len(symbols) != 1 || symbols[0] != tc.exp {
+			t.Errorf("%v Symbols(): expected %+v, actual %+v %v", tc.market, tc.exp, symbols, err)
+		}
+	}
+}
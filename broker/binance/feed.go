@@ -0,0 +1,166 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/stream"
+)
+
+// Feed streams the klines and book tickers of symbols from the websocket of the market.
+// A closed kline is published as bar at the end of its interval, a book ticker as tick.
+type Feed struct {
+	*Client
+	Symbols []string
+	// Klines sets the interval of the klines, e.g. 1m or 1h, empty for no klines
+	Klines string
+	// BookTicker streams the best bid and ask of the symbols
+	BookTicker bool
+	// Stream sets the heartbeat and the reconnects of the stream
+	Stream stream.Options
+}
+
+// NewFeed creates a feed of the 1m klines and the book tickers of symbols.
+func NewFeed(c *Client, symbols ...string) *Feed {
+	return &Feed{Client: c, Symbols: symbols, Klines: "1m", BookTicker: true}
+}
+
+// streams returns the names of the subscribed streams.
+func (f *Feed) streams() []string {
+	var streams []string
+	for _, symbol := range f.Symbols {
+		symbol = strings.ToLower(symbol)
+		if f.Klines != "" {
+			streams = append(streams, symbol+"@kline_"+f.Klines)
+		}
+		if f.BookTicker {
+			streams = append(streams, symbol+"@bookTicker")
+		}
+	}
+	return streams
+}
+
+// venue implements the stream.Venue of a feed.
+type venue struct {
+	*Feed
+}
+
+// URL returns the url of the combined streams of the symbols.
+func (v venue) URL() string {
+	base := v.StreamURL
+	if base == "" {
+		base = v.byMarket(SpotStreamURL, FuturesStreamURL)
+	}
+	return strings.TrimSuffix(base, "/") + "/stream?streams=" + strings.Join(v.streams(), "/")
+}
+
+// Subscribe does nothing, the streams are subscribed by the url.
+func (v venue) Subscribe(conn stream.Conn) error {
+	return nil
+}
+
+// Parse maps a message of the combined streams to data events.
+func (v venue) Parse(msg []byte) ([]gbt.DataEvent, error) {
+	var m struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, fmt.Errorf("could not parse message: %v", err)
+	}
+	event, err := parseEvent(m.Stream, m.Data)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	return []gbt.DataEvent{event}, nil
+}
+
+// Recover fetches the klines of the symbols closed since a time.
+func (v venue) Recover(since time.Time) ([]gbt.DataEvent, error) {
+	if v.Klines == "" {
+		return nil, nil
+	}
+	var events []gbt.DataEvent
+	for _, symbol := range v.Symbols {
+		bars, err := v.Client.Klines(symbol, v.Klines, since, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		for _, bar := range bars {
+			events = append(events, bar)
+		}
+	}
+	return events, nil
+}
+
+// Run publishes the events of the streams into the live data, until the context is cancelled
+// or the stream fails permanently. A lost connection is reconnected and the klines closed
+// meanwhile are recovered. The live data is closed on return, so the backtest finishes.
+func (f *Feed) Run(ctx context.Context, data *gbt.LiveData) error {
+	if len(f.streams()) == 0 {
+		data.Close()
+		return errors.New("no streams to subscribe")
+	}
+	return stream.NewConsumer(venue{f}, f.Stream).Run(ctx, data)
+}
+
+// parseEvent maps the data of a stream to a data event, nil for an open kline.
+func parseEvent(stream string, data json.RawMessage) (gbt.DataEvent, error) {
+	switch {
+	case strings.Contains(stream, "@kline_"):
+		var e struct {
+			Symbol string `json:"s"`
+			Kline  struct {
+				CloseTime int64   `json:"T"`
+				Open      decimal `json:"o"`
+				High      decimal `json:"h"`
+				Low       decimal `json:"l"`
+				Close     decimal `json:"c"`
+				Volume    decimal `json:"v"`
+				Closed    bool    `json:"x"`
+			} `json:"k"`
+		}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("could not parse kline: %v", err)
+		}
+		k := e.Kline
+		if !k.Closed {
+			return nil, nil
+		}
+		bar := &gbt.Bar{Open: float64(k.Open), High: float64(k.High), Low: float64(k.Low), Close: float64(k.Close), AdjClose: float64(k.Close), Volume: float64(k.Volume)}
+		bar.SetSymbol(e.Symbol)
+		// the close time is the last millisecond of the interval
+		bar.SetTime(millis(k.CloseTime + 1))
+		return bar, nil
+
+	case strings.HasSuffix(stream, "@bookTicker"):
+		var e struct {
+			Symbol    string  `json:"s"`
+			Bid       decimal `json:"b"`
+			BidVolume decimal `json:"B"`
+			Ask       decimal `json:"a"`
+			AskVolume decimal `json:"A"`
+			Time      int64   `json:"T"` // transaction time, only on the futures market
+		}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("could not parse book ticker: %v", err)
+		}
+		tick := &gbt.Tick{Bid: float64(e.Bid), Ask: float64(e.Ask), BidVolume: float64(e.BidVolume), AskVolume: float64(e.AskVolume)}
+		tick.SetSymbol(e.Symbol)
+		if e.Time > 0 {
+			tick.SetTime(millis(e.Time))
+		}
+		return tick, nil
+	}
+	return nil, nil
+}
+
+// millis returns the time of a timestamp in milliseconds.
+func millis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,127 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/internal/websocket"
+)
+
+func TestFeedRun(t *testing.T) {
+	streams := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streams <- r.URL.Query().Get("streams")
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for _, m := range []string{
+			`{"stream":"btcusdt@kline_1m","data":{"e":"kline","s":"BTCUSDT","k":{"T":1499644799999,"o":"1","h":"3","l":"0.5","c":"2","v":"100","x":false}}}`,
+			`{"stream":"btcusdt@kline_1m","data":{"e":"kline","s":"BTCUSDT","k":{"T":1499644799999,"o":"1","h":"3","l":"0.5","c":"2.5","v":"120","x":true}}}`,
+			`{"stream":"btcusdt@bookTicker","data":{"u":400900217,"s":"BTCUSDT","b":"25.35","B":"31.21","a":"25.36","A":"40.66"}}`,
+		} {
+			conn.WriteMessage([]byte(m))
+		}
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL, Spot)
+	c.StreamURL = "ws" + strings.TrimPrefix(ts.URL, "http")
+	feed := gbt.NewLiveData(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- NewFeed(c, "BTCUSDT").Run(ctx, feed) }()
+
+	var events []gbt.DataEvent
+	for e, ok := feed.NextContext(ctx); ok; e, ok = feed.NextContext(ctx) {
+		events = append(events, e)
+		if len(events) == 2 {
+			cancel()
+		}
+	}
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run(): expected the cancelled context, actual %v", err)
+	}
+
+	if s := <-streams; s != "btcusdt@kline_1m/btcusdt@bookTicker" {
+		t.Errorf("Run(): unexpected streams %v", s)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Run(): expected the closed kline and the tick, actual %v", events)
+	}
+	if bar, ok := events[0].(*gbt.Bar); !ok || bar.Close != 2.5 || bar.Volume != 120 || !bar.Time().Equal(time.Unix(1499644800, 0)) {
+		t.Errorf("Run(): expected the closed kline at the end of the interval, actual %+v", events[0])
+	}
+	if tick, ok := events[1].(*gbt.Tick); !ok || tick.Symbol() != "BTCUSDT" || tick.Bid != 25.35 || tick.AskVolume != 40.66 || tick.Time().IsZero() {
+		t.Errorf("Run(): expected the book ticker, actual %+v", events[1])
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	// testCases is a table for testing the mapping of the streams
+	var testCases = []struct {
+		msg     string
+		stream  string
+		data    string
+		expTime time.Time
+		expNil  bool
+		expErr  bool
+	}{
+		{"futures book ticker:", "btcusdt@bookTicker", `{"e":"bookTicker","s":"BTCUSDT","b":"1","a":"2","T":1568014460891}`, time.Unix(1568014460, 891000000), false, false},
+		{"open kline:", "btcusdt@kline_1h", `{"s":"BTCUSDT","k":{"x":false}}`, time.Time{}, true, false},
+		{"invalid kline:", "btcusdt@kline_1h", `{"k":[]}`, time.Time{}, true, true},
+		{"unknown stream:", "btcusdt@trade", `{}`, time.Time{}, true, false},
+	}
+
+	for _, tc := range testCases {
+		event, err := parseEvent(tc.stream, json.RawMessage(tc.data))
+		if (err != nil) != tc.expErr || (event == nil) != tc.expNil {
+			t.Errorf("%v parseEvent(): expected nil %v and error %v, actual %v %v", tc.msg, tc.expNil, tc.expErr, event, err)
+			continue
+		}
+		if event != nil && !event.Time().Equal(tc.expTime) {
+			t.Errorf("%v parseEvent(): expected time %v, actual %v", tc.msg, tc.expTime, event.Time())
+		}
+	}
+
+	if err := (&Feed{Client: &Client{}}).Run(context.Background(), gbt.NewLiveData(0)); err == nil {
+		t.Errorf("Run(): expected an error without streams")
+	}
+}
+
+func TestVenueRecover(t *testing.T) {
+	e, ts := newExchange()
+	defer ts.Close()
+	e.reply("GET /api/v3/klines", klines(1600000020000, 2))
+
+	c := newTestClient(ts.URL, Spot)
+	c.now = func() time.Time { return millis(1600000140000) }
+	v := venue{NewFeed(c, "BTCUSDT", "ETHUSDT")}
+
+	events, err := v.Recover(millis(1600000020000))
+	if err != nil {
+		t.Fatalf("Recover(): unexpected error %v", err)
+	}
+	if len(events) != 4 || events[2].Symbol() != "ETHUSDT" || !events[1].Time().Equal(millis(1600000140000)) {
+		t.Errorf("Recover(): expected 2 bars of each symbol, actual %v", events)
+	}
+	if e.param("GET /api/v3/klines", "startTime") != "1600000020000" || e.param("GET /api/v3/klines", "interval") != "1m" {
+		t.Errorf("Recover(): unexpected query %v", e.params["GET /api/v3/klines"])
+	}
+
+	v.Klines = ""
+	if events, err := v.Recover(millis(1600000020000)); err != nil || len(events) != 0 {
+		t.Errorf("Recover(): expected no recovery without klines, actual %v %v", events, err)
+	}
+}
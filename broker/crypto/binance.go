@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/binance"
+)
+
+// binanceFactory returns the factory of a market of binance.
+func binanceFactory(market binance.Market) Factory {
+	return func(c Config) (Exchange, error) {
+		client := binance.NewClient(c.APIKey, c.Secret, market)
+		client.URL = c.URL
+		client.StreamURL = c.StreamURL
+		return &binanceExchange{client: client}, nil
+	}
+}
+
+// binanceExchange adapts the binance package.
+type binanceExchange struct {
+	client *binance.Client
+}
+
+func (e *binanceExchange) Markets() ([]Market, error) {
+	symbols, err := e.client.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	markets := make([]Market, 0, len(symbols))
+	for _, s := range symbols {
+		markets = append(markets, Market{
+			Symbol: Symbol(s.Base, s.Quote, s.Margin),
+			ID:     s.Symbol,
+			Base:   s.Base,
+			Quote:  s.Quote,
+			Settle: s.Margin,
+		})
+	}
+	return markets, nil
+}
+
+func (e *binanceExchange) Klines(id, interval string, start, end time.Time) ([]*gbt.Bar, error) {
+	return e.client.Klines(id, interval, start, end)
+}
+
+func (e *binanceExchange) Broker() gbt.Broker {
+	return binance.NewBroker(e.client)
+}
+
+func (e *binanceExchange) Feed(interval string, ids ...string) Feed {
+	feed := binance.NewFeed(e.client, ids...)
+	feed.Klines = interval
+	return feed
+}
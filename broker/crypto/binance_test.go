@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dirkolbrich/gobacktest/broker/binance"
+)
+
+func TestBinanceExchange(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/exchangeInfo":
+			w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"TRADING","contractType":"PERPETUAL","baseAsset":"BTC","quoteAsset":"USDT","marginAsset":"USDT"}]}`))
+		case "/fapi/v1/klines":
+			w.Write([]byte(`[[1600000020000,"1.0","2.0","0.5","1.5","10",1600000079999,"15",3,"5","7.5","0"]]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	venue, err := Open(Config{Exchange: "binanceusdm", APIKey: "key", Secret: "secret", URL: ts.URL, StreamURL: "ws://localhost"})
+	if err != nil {
+		t.Fatalf("Open(): unexpected error %v", err)
+	}
+	exp := Market{Symbol: "BTC/USDT:USDT", ID: "BTCUSDT", Base: "BTC", Quote: "USDT", Settle: "USDT"}
+	if m, ok := venue.Market("BTC/USDT:USDT"); !ok || m != exp {
+		t.Errorf("Market(): expected %+v, actual %+v", exp, m)
+	}
+
+	d := venue.Data("1m", time.Time{}, time.Time{})
+	if err := d.Load([]string{"BTC/USDT:USDT"}); err != nil || len(d.Stream()) != 1 || d.Stream()[0].Symbol() != "BTC/USDT:USDT" {
+		t.Errorf("Load(): expected the bar of the unified symbol, actual %v %v", d.Stream(), err)
+	}
+
+	if b, ok := venue.Exchange.Broker().(*binance.Broker); !ok || b.Market != binance.Futures || b.URL != ts.URL {
+		t.Errorf("Broker(): expected a broker of the futures market, actual %+v", b)
+	}
+	if f, ok := venue.Exchange.Feed("", "BTCUSDT").(*binance.Feed); !ok || f.Klines != "" || !f.BookTicker || f.StreamURL != "ws://localhost" {
+		t.Errorf("Feed(): expected a feed of the book tickers, actual %+v", f)
+	}
+
+	if _, err := Open(Config{Exchange: "binance", URL: ts.URL}); err == nil {
+		t.Errorf("Open(): expected the error of the markets")
+	}
+}
@@ -0,0 +1,186 @@
+// Package crypto is a thin unified interface over crypto exchanges, in the style of CCXT.
+// A venue is opened by the name of its exchange from a configuration, strategies trade the
+// unified symbols BASE/QUOTE, e.g. BTC/USDT, or BASE/QUOTE:SETTLE for perpetual contracts,
+// e.g. BTC/USDT:USDT, so switching the venue does not change the data or the broker glue:
+//
+//	venue, err := crypto.Open(crypto.Config{Exchange: "binance", APIKey: key, Secret: secret})
+//	feed := gbt.NewLiveData(100)
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetBroker(venue.Broker())
+//	go venue.Feed("1m", "BTC/USDT").Run(ctx, feed)
+//	err = test.RunContext(ctx)
+//
+// The built-in exchanges are binance for the spot market and binanceusdm for the USDⓈ-M
+// perpetual futures of Binance, further exchanges are added with Register.
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/binance"
+)
+
+// Market is a market of an exchange.
+type Market struct {
+	Symbol string // unified symbol, e.g. BTC/USDT or BTC/USDT:USDT
+	ID     string // symbol of the exchange, e.g. BTCUSDT
+	Base   string
+	Quote  string
+	Settle string // settlement asset of a contract, empty on the spot market
+}
+
+// Exchange is the adapter of a crypto exchange, which works with the symbols of the exchange.
+type Exchange interface {
+	// Markets returns the markets open for trading.
+	Markets() ([]Market, error)
+	// Klines fetches the closed klines of a symbol as bars, the interval is one of
+	// 1m, 5m, 15m, 1h, 4h or 1d.
+	Klines(id, interval string, start, end time.Time) ([]*gbt.Bar, error)
+	Broker() gbt.Broker
+	// Feed streams the klines of the interval and the quotes of the symbols,
+	// an empty interval streams only the quotes.
+	Feed(interval string, ids ...string) Feed
+}
+
+// Feed publishes live data events into the live data of a backtest.
+type Feed interface {
+	Run(ctx context.Context, data *gbt.LiveData) error
+}
+
+// Config selects an exchange and its account, e.g. decoded from a configuration file.
+type Config struct {
+	Exchange string `json:"exchange"`
+	APIKey   string `json:"apiKey"`
+	Secret   string `json:"secret"`
+	// URL and StreamURL override the endpoints of the exchange, e.g. for a testnet
+	URL       string `json:"url"`
+	StreamURL string `json:"streamUrl"`
+}
+
+// Factory creates the adapter of an exchange from a configuration.
+type Factory func(Config) (Exchange, error)
+
+var (
+	mu        sync.RWMutex
+	exchanges = map[string]Factory{
+		"binance":     binanceFactory(binance.Spot),
+		"binanceusdm": binanceFactory(binance.Futures),
+	}
+)
+
+// Register registers the factory of an exchange, which can then be opened by name.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	exchanges[name] = factory
+}
+
+// Exchanges returns the names of the registered exchanges in alphabetical order.
+func Exchanges() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(exchanges))
+	for name := range exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Venue is an opened exchange, which translates the unified symbols.
+type Venue struct {
+	Exchange Exchange
+
+	markets []Market
+	symbols map[string]Market // by unified symbol
+	ids     map[string]Market // by symbol of the exchange
+}
+
+// Open creates the adapter of the configured exchange and loads its markets.
+func Open(c Config) (*Venue, error) {
+	mu.RLock()
+	factory, ok := exchanges[c.Exchange]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("could not open exchange, unknown exchange %q", c.Exchange)
+	}
+
+	exchange, err := factory(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not open exchange %s: %v", c.Exchange, err)
+	}
+	return NewVenue(exchange)
+}
+
+// NewVenue loads the markets of an exchange.
+func NewVenue(exchange Exchange) (*Venue, error) {
+	if exchange == nil {
+		return nil, errors.New("no exchange provided")
+	}
+	markets, err := exchange.Markets()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Venue{
+		Exchange: exchange,
+		markets:  markets,
+		symbols:  make(map[string]Market, len(markets)),
+		ids:      make(map[string]Market, len(markets)),
+	}
+	for _, m := range markets {
+		v.symbols[m.Symbol] = m
+		v.ids[m.ID] = m
+	}
+	return v, nil
+}
+
+// Markets returns the markets of the venue.
+func (v *Venue) Markets() []Market {
+	return v.markets
+}
+
+// Market returns the market of a unified symbol.
+func (v *Venue) Market(symbol string) (Market, bool) {
+	m, ok := v.symbols[symbol]
+	return m, ok
+}
+
+// resolve returns the symbols of the exchange of unified symbols.
+func (v *Venue) resolve(symbols []string) ([]string, error) {
+	ids := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		m, ok := v.symbols[symbol]
+		if !ok {
+			return nil, fmt.Errorf("unknown symbol %q", symbol)
+		}
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+// symbol returns the unified symbol of a symbol of the exchange,
+// an unknown symbol is returned unchanged.
+func (v *Venue) symbol(id string) string {
+	if m, ok := v.ids[id]; ok {
+		return m.Symbol
+	}
+	return id
+}
+
+// Symbol returns the unified symbol of base and quote, with the settlement asset of a contract.
+func Symbol(base, quote, settle string) string {
+	symbol := strings.ToUpper(base) + "/" + strings.ToUpper(quote)
+	if settle != "" {
+		symbol += ":" + strings.ToUpper(settle)
+	}
+	return symbol
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// fakeExchange is an exchange with the symbols BTC-USD and ETH-USD.
+type fakeExchange struct {
+	broker *fakeBroker
+	ticks  []*gbt.Tick
+	err    error
+}
+
+func (e *fakeExchange) Markets() ([]Market, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []Market{
+		{Symbol: "BTC/USD", ID: "BTC-USD", Base: "BTC", Quote: "USD"},
+		{Symbol: "ETH/USD", ID: "ETH-USD", Base: "ETH", Quote: "USD"},
+	}, nil
+}
+
+func (e *fakeExchange) Klines(id, interval string, start, end time.Time) ([]*gbt.Bar, error) {
+	if id != "BTC-USD" && id != "ETH-USD" {
+		return nil, errors.New("unknown symbol")
+	}
+	var bars []*gbt.Bar
+	for i := 0; i < 2; i++ {
+		bar := &gbt.Bar{Close: float64(i + 1)}
+		bar.SetSymbol(id)
+		bar.SetTime(time.Date(2020, 1, i+1, 0, 0, 0, 0, time.UTC))
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+func (e *fakeExchange) Broker() gbt.Broker {
+	return e.broker
+}
+
+func (e *fakeExchange) Feed(interval string, ids ...string) Feed {
+	return fakeFeed(e.ticks)
+}
+
+// fakeFeed publishes its ticks and waits for the cancellation.
+type fakeFeed []*gbt.Tick
+
+func (f fakeFeed) Run(ctx context.Context, data *gbt.LiveData) error {
+	defer data.Close()
+	for _, tick := range f {
+		if !data.Publish(tick) {
+			return nil
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRegister(t *testing.T) {
+	Register("fake", func(c Config) (Exchange, error) {
+		if c.APIKey == "" {
+			return nil, errors.New("no api key")
+		}
+		return &fakeExchange{}, nil
+	})
+
+	// testCases is a table for testing the opening of exchanges
+	var testCases = []struct {
+		msg    string
+		config Config
+		expErr bool
+	}{
+		{"registered exchange:", Config{Exchange: "fake", APIKey: "key"}, false},
+		{"failing factory:", Config{Exchange: "fake"}, true},
+		{"unknown exchange:", Config{Exchange: "unknown"}, true},
+	}
+
+	for _, tc := range testCases {
+		venue, err := Open(tc.config)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Open(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if venue != nil && len(venue.Markets()) != 2 {
+			t.Errorf("%v Open(): expected the markets to be loaded, actual %v", tc.msg, venue.Markets())
+		}
+	}
+
+	if names := Exchanges(); !reflect.DeepEqual(names, []string{"binance", "binanceusdm", "fake"}) {
+		t.Errorf("Exchanges(): unexpected exchanges %v", names)
+	}
+}
+
+func TestNewVenue(t *testing.T) {
+	venue, err := NewVenue(&fakeExchange{})
+	if err != nil {
+		t.Fatalf("NewVenue(): unexpected error %v", err)
+	}
+	if m, ok := venue.Market("ETH/USD"); !ok || m.ID != "ETH-USD" {
+		t.Errorf("Market(): expected the market of ETH/USD, actual %+v", m)
+	}
+	if _, ok := venue.Market("ETH-USD"); ok {
+		t.Errorf("Market(): expected no market for the symbol of the exchange")
+	}
+
+	if _, err := NewVenue(nil); err == nil {
+		t.Errorf("NewVenue(): expected an error without exchange")
+	}
+	if _, err := NewVenue(&fakeExchange{err: errors.New("down")}); err == nil {
+		t.Errorf("NewVenue(): expected the error of the markets")
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	// testCases is a table for testing the unified symbols
+	var testCases = []struct {
+		base, quote, settle string
+		exp                 string
+	}{
+		{"btc", "usdt", "", "BTC/USDT"},
+		{"BTC", "USDT", "USDT", "BTC/USDT:USDT"},
+	}
+
+	for _, tc := range testCases {
+		if symbol := Symbol(tc.base, tc.quote, tc.settle); symbol != tc.exp {
+			t.Errorf("Symbol(%v, %v, %v): expected %v, actual %v", tc.base, tc.quote, tc.settle, tc.exp, symbol)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// Data loads the historical klines of a venue into the data stream under their unified symbols.
+// It expands the underlying data struct.
+type Data struct {
+	gbt.Data
+	Venue      *Venue
+	Interval   string // defaults to 1d
+	Start, End time.Time
+}
+
+// Data returns a data handler of the klines of the venue within the time range.
+func (v *Venue) Data(interval string, start, end time.Time) *Data {
+	return &Data{Venue: v, Interval: interval, Start: start, End: end}
+}
+
+// Load the bars of the unified symbols into the stream ordered by date.
+func (d *Data) Load(symbols []string) error {
+	if d.Venue == nil {
+		return errors.New("no venue provided")
+	}
+	if len(symbols) == 0 {
+		return errors.New("no symbols provided")
+	}
+	ids, err := d.Venue.resolve(symbols)
+	if err != nil {
+		return err
+	}
+
+	interval := d.Interval
+	if interval == "" {
+		interval = "1d"
+	}
+	for i, id := range ids {
+		bars, err := d.Venue.Exchange.Klines(id, interval, d.Start, d.End)
+		if err != nil {
+			return err
+		}
+		stream := d.Data.Stream()
+		for _, bar := range bars {
+			bar.SetSymbol(symbols[i])
+			stream = append(stream, bar)
+		}
+		d.Data.SetStream(stream)
+	}
+	d.Data.SortStream()
+
+	return nil
+}
+
+// Broker translates the unified symbols for the broker of an exchange.
+type Broker struct {
+	venue  *Venue
+	broker gbt.Broker
+}
+
+// Broker returns the broker of the venue, trading the unified symbols.
+func (v *Venue) Broker() *Broker {
+	return &Broker{venue: v, broker: v.Exchange.Broker()}
+}
+
+// Submit places an order of a unified symbol. The order keeps its unified symbol.
+func (b *Broker) Submit(order *gbt.Order) error {
+	if order == nil || order.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	ids, err := b.venue.resolve([]string{order.Symbol()})
+	if err != nil {
+		return err
+	}
+
+	symbol := order.Symbol()
+	order.SetSymbol(ids[0])
+	defer order.SetSymbol(symbol)
+	return b.broker.Submit(order)
+}
+
+// Cancel cancels an order by id.
+func (b *Broker) Cancel(id int) error {
+	return b.broker.Cancel(id)
+}
+
+// Modify modifies an order by id.
+func (b *Broker) Modify(id int, change gbt.OrderChange) error {
+	return b.broker.Modify(id, change)
+}
+
+// Orders returns the open orders with their unified symbols.
+func (b *Broker) Orders() []gbt.OrderEvent {
+	orders := b.broker.Orders()
+	for _, order := range orders {
+		order.SetSymbol(b.venue.symbol(order.Symbol()))
+	}
+	return orders
+}
+
+// Positions returns the open positions by unified symbol.
+func (b *Broker) Positions() map[string]gbt.Position {
+	positions := make(map[string]gbt.Position)
+	now := time.Now()
+	for id, p := range b.broker.Positions() {
+		symbol := b.venue.symbol(id)
+		positions[symbol] = gbt.NewPosition(now, symbol, p.Qty(), p.AvgPrice(), p.MarketPrice())
+	}
+	return positions
+}
+
+// Cash returns the cash of the account.
+func (b *Broker) Cash() float64 {
+	return b.broker.Cash()
+}
+
+// Value returns the value of the account.
+func (b *Broker) Value() float64 {
+	return b.broker.Value()
+}
+
+// VenueFeed publishes the live data of an exchange under the unified symbols.
+type VenueFeed struct {
+	venue    *Venue
+	interval string
+	symbols  []string
+}
+
+// Feed returns a feed of the klines of the interval and the quotes of the unified symbols.
+func (v *Venue) Feed(interval string, symbols ...string) *VenueFeed {
+	return &VenueFeed{venue: v, interval: interval, symbols: symbols}
+}
+
+// Run publishes the events of the exchange into the live data, until the context is cancelled,
+// the feed of the exchange fails or the live data is closed. The live data is closed on return.
+func (f *VenueFeed) Run(ctx context.Context, data *gbt.LiveData) error {
+	defer data.Close()
+
+	ids, err := f.venue.resolve(f.symbols)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the exchange publishes into a relay, which only keeps the latest event
+	relay := gbt.NewLiveData(len(ids) + 1)
+	relay.SetHistoryLimit(1)
+	done := make(chan error, 1)
+	go func() { done <- f.venue.Exchange.Feed(f.interval, ids...).Run(ctx, relay) }()
+
+	// a closed live data stops the relay
+	go func() {
+		select {
+		case <-data.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for e, ok := relay.NextContext(ctx); ok; e, ok = relay.NextContext(ctx) {
+		e.SetSymbol(f.venue.symbol(e.Symbol()))
+		if !data.Publish(e) {
+			break
+		}
+	}
+	cancel()
+
+	err = <-done
+	select {
+	case <-data.Done():
+		return nil
+	default:
+		return err
+	}
+}
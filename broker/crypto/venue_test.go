@@ -0,0 +1,160 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// fakeBroker records the orders with the symbols of the exchange.
+type fakeBroker struct {
+	submitted []string
+	orders    []gbt.OrderEvent
+}
+
+func (b *fakeBroker) Submit(order *gbt.Order) error {
+	b.submitted = append(b.submitted, order.Symbol())
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+func (b *fakeBroker) Cancel(id int) error                         { return nil }
+func (b *fakeBroker) Modify(id int, change gbt.OrderChange) error { return nil }
+func (b *fakeBroker) Orders() []gbt.OrderEvent                    { return b.orders }
+func (b *fakeBroker) Cash() float64                               { return 1000 }
+func (b *fakeBroker) Value() float64                              { return 1500 }
+
+func (b *fakeBroker) Positions() map[string]gbt.Position {
+	return map[string]gbt.Position{
+		"BTC-USD": gbt.NewPosition(time.Now(), "BTC-USD", 0.5, 900, 1000),
+	}
+}
+
+func TestVenueData(t *testing.T) {
+	venue, _ := NewVenue(&fakeExchange{})
+
+	d := venue.Data("1d", time.Time{}, time.Time{})
+	if err := d.Load([]string{"ETH/USD", "BTC/USD"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+	stream := d.Stream()
+	if len(stream) != 4 || stream[0].Symbol() != "BTC/USD" || stream[1].Symbol() != "ETH/USD" {
+		t.Errorf("Load(): expected sorted bars of the unified symbols, actual %v", stream)
+	}
+
+	// testCases is a table for testing failed loads
+	var testCases = []struct {
+		msg     string
+		data    *Data
+		symbols []string
+	}{
+		{"no venue:", &Data{}, []string{"BTC/USD"}},
+		{"no symbols:", venue.Data("", time.Time{}, time.Time{}), nil},
+		{"unknown symbol:", venue.Data("", time.Time{}, time.Time{}), []string{"BTC-USD"}},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.data.Load(tc.symbols); err == nil {
+			t.Errorf("%v Load(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestVenueBroker(t *testing.T) {
+	fake := &fakeBroker{}
+	venue, _ := NewVenue(&fakeExchange{broker: fake})
+	b := venue.Broker()
+
+	order := &gbt.Order{}
+	order.SetSymbol("BTC/USD")
+	order.SetQty(1)
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	if len(fake.submitted) != 1 || fake.submitted[0] != "BTC-USD" || order.Symbol() != "BTC/USD" {
+		t.Errorf("Submit(): expected the symbol of the exchange at the broker, actual %v, order %v", fake.submitted, order.Symbol())
+	}
+
+	unknown := &gbt.Order{}
+	unknown.SetSymbol("BTC-USD")
+	if err := b.Submit(unknown); err == nil {
+		t.Errorf("Submit(): expected an error for an unknown symbol")
+	}
+	if err := b.Submit(&gbt.Order{}); err == nil {
+		t.Errorf("Submit(): expected an error without symbol")
+	}
+
+	// an order with the symbol of the exchange is reported with the unified symbol
+	native := &gbt.Order{}
+	native.SetSymbol("ETH-USD")
+	fake.orders = append(fake.orders, native)
+	if orders := b.Orders(); len(orders) != 2 || orders[1].Symbol() != "ETH/USD" {
+		t.Errorf("Orders(): expected the unified symbols, actual %v", orders)
+	}
+
+	positions := b.Positions()
+	if p, ok := positions["BTC/USD"]; !ok || p.Symbol() != "BTC/USD" || p.Qty() != 0.5 || p.AvgPrice() != 900 || p.MarketPrice() != 1000 {
+		t.Errorf("Positions(): expected the position of BTC/USD, actual %+v", positions)
+	}
+	if b.Cash() != 1000 || b.Value() != 1500 || b.Cancel(1) != nil || b.Modify(1, gbt.OrderChange{}) != nil {
+		t.Errorf("Broker: expected the account of the exchange")
+	}
+}
+
+// newTicks returns ticks of the symbols of the exchange.
+func newTicks(ids ...string) []*gbt.Tick {
+	var ticks []*gbt.Tick
+	for _, id := range ids {
+		tick := &gbt.Tick{Bid: 1, Ask: 2}
+		tick.SetSymbol(id)
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+func TestVenueFeed(t *testing.T) {
+	// testCases is a table for testing the end of a feed
+	var testCases = []struct {
+		msg       string
+		n         int // events read before the live data is closed, 0 to cancel the context
+		expEvents int
+		expErr    error
+	}{
+		{"closed live data:", 2, 2, nil},
+		{"cancelled context:", 0, 3, context.Canceled},
+	}
+
+	for _, tc := range testCases {
+		ticks := newTicks("BTC-USD", "ETH-USD", "BTC-USD")
+		venue, _ := NewVenue(&fakeExchange{ticks: ticks})
+		ctx, cancel := context.WithCancel(context.Background())
+		data := gbt.NewLiveData(10)
+		done := make(chan error)
+		go func() { done <- venue.Feed("1m", "BTC/USD", "ETH/USD").Run(ctx, data) }()
+
+		var symbols []string
+		for e, ok := data.NextContext(ctx); ok; e, ok = data.NextContext(ctx) {
+			symbols = append(symbols, e.Symbol())
+			if len(symbols) == tc.n {
+				data.Close()
+			}
+			if tc.n == 0 && len(symbols) == len(ticks) {
+				cancel()
+			}
+		}
+		if err := <-done; err != tc.expErr {
+			t.Errorf("%v Run(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+		if len(symbols) < tc.expEvents || symbols[0] != "BTC/USD" || symbols[1] != "ETH/USD" {
+			t.Errorf("%v Run(): expected the unified symbols, actual %v", tc.msg, symbols)
+		}
+		cancel()
+	}
+
+	venue, _ := NewVenue(&fakeExchange{})
+	if err := venue.Feed("1m", "DOGE/USD").Run(context.Background(), gbt.NewLiveData(1)); err == nil {
+		t.Errorf("Run(): expected an error for an unknown symbol")
+	}
+}
@@ -0,0 +1,398 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/internal/orders"
+)
+
+// Broker implements gobacktest.Broker over a FIX session. Orders are routed as new order
+// singles, cancel and cancel/replace requests, the execution reports update the orders.
+// Each request has a new ClOrdID, an order is tracked by the ClOrdID of its last request.
+//
+// FIX has no standard account data, so the positions and the cash are booked from the fills
+// of the execution reports, starting with the cash of NewBroker. This includes the fills of
+// orders entered at the OMS, a position is valued at the price of its last fill.
+type Broker struct {
+	Session Transport
+	Account string // sent as Account, if set
+	TIF     string // TimeInForce of the orders, defaults to 0 for day
+	// Timeout sets the wait for the acknowledgement of a request, defaults to 10 seconds
+	Timeout time.Duration
+	// OnError receives the rejects of the counterparty without pending request
+	OnError func(error)
+
+	orders orders.Tracker
+
+	mu        sync.Mutex
+	cash      float64
+	positions map[string]*gbt.Position
+	execs     map[string]bool       // processed exec ids
+	pending   map[string]chan error // acknowledgements by ClOrdID of the request
+	nextID    int
+	prefix    string // makes the ClOrdIDs unique across sessions
+}
+
+// Transport is the FIX session the broker routes its orders over, the built-in Session or,
+// built with the quickfix tag, a QuickFIX session.
+type Transport interface {
+	// Send sends an application message, the session sets the header.
+	Send(*Message) error
+	// Receive sets the receiver of the application messages and the session level rejects.
+	Receive(func(*Message))
+	// Done returns a channel, which is closed when the session ends.
+	Done() <-chan struct{}
+}
+
+// NewBroker creates a broker routing the orders over a session with the cash of the account.
+// It receives the application messages of the session.
+func NewBroker(s Transport, cash float64) *Broker {
+	b := &Broker{
+		Session:   s,
+		cash:      cash,
+		positions: make(map[string]*gbt.Position),
+		execs:     make(map[string]bool),
+		pending:   make(map[string]chan error),
+		prefix:    strconv.FormatInt(time.Now().Unix(), 36),
+	}
+	s.Receive(b.handle)
+	return b
+}
+
+// Submit sends a new order single and waits for its acknowledgement.
+func (b *Broker) Submit(order *gbt.Order) error {
+	if order == nil || order.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	if order.Qty() <= 0 {
+		return fmt.Errorf("could not submit order for %s, qty %v not positive", order.Symbol(), order.Qty())
+	}
+
+	b.orders.Assign(order)
+	m := NewMessage(msgNewOrderSingle)
+	if err := b.order(m, order, order.Qty(), order.Limit(), order.Stop()); err != nil {
+		return err
+	}
+	m.Set(tagTransactTime, time.Now().UTC().Format(timeFormat))
+
+	id := b.clOrdID(order)
+	m.Set(tagClOrdID, id)
+	order.SetStatus(gbt.OrderSubmitted)
+	b.orders.Add(order, id)
+	if err := b.request(id, m); err != nil {
+		b.orders.Finish(order.ID(), gbt.OrderInvalid)
+		return fmt.Errorf("could not submit order for %s: %v", order.Symbol(), err)
+	}
+	return nil
+}
+
+// Cancel sends a cancel request for an order and waits for the cancellation.
+func (b *Broker) Cancel(id int) error {
+	order, orig, err := b.orders.Lookup(id)
+	if err != nil {
+		return fmt.Errorf("could not cancel order: %v", err)
+	}
+
+	m := NewMessage(msgOrderCancelRequest).
+		Set(tagOrigClOrdID, orig).
+		Set(tagSymbol, order.Symbol()).
+		Set(tagSide, side(order.Direction())).
+		Set(tagOrderQty, formatNumber(order.Qty())).
+		Set(tagTransactTime, time.Now().UTC().Format(timeFormat))
+	if b.Account != "" {
+		m.Set(tagAccount, b.Account)
+	}
+	cancel := b.clOrdID(order)
+	m.Set(tagClOrdID, cancel)
+
+	if err := b.request(cancel, m); err != nil {
+		return fmt.Errorf("could not cancel order %v: %v", id, err)
+	}
+	b.orders.Finish(id, gbt.OrderCanceled)
+	return nil
+}
+
+// Modify sends a cancel/replace request for an order and waits for the replacement.
+func (b *Broker) Modify(id int, change gbt.OrderChange) error {
+	order, orig, err := b.orders.Lookup(id)
+	if err != nil {
+		return fmt.Errorf("could not modify order: %v", err)
+	}
+
+	qty, limit, stop := order.Qty(), order.Limit(), order.Stop()
+	if change.Qty > 0 {
+		qty = change.Qty
+	}
+	if change.Limit > 0 {
+		limit = change.Limit
+	}
+	if change.Stop > 0 {
+		stop = change.Stop
+	}
+
+	m := NewMessage(msgOrderCancelReplace).Set(tagOrigClOrdID, orig)
+	if err := b.order(m, order, qty, limit, stop); err != nil {
+		return err
+	}
+	m.Set(tagTransactTime, time.Now().UTC().Format(timeFormat))
+	replace := b.clOrdID(order)
+	m.Set(tagClOrdID, replace)
+
+	if err := b.request(replace, m); err != nil {
+		return fmt.Errorf("could not modify order %v: %v", id, err)
+	}
+	b.orders.Update(id, func(o *gbt.Order) {
+		o.SetQty(qty)
+		o.SetLimit(limit)
+		o.SetStop(stop)
+	})
+	return nil
+}
+
+// Orders returns the working orders.
+func (b *Broker) Orders() []gbt.OrderEvent {
+	return b.orders.Orders()
+}
+
+// Positions returns the positions booked from the fills by symbol.
+func (b *Broker) Positions() map[string]gbt.Position {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	positions := make(map[string]gbt.Position)
+	for symbol, p := range b.positions {
+		if p.Qty() != 0 {
+			positions[symbol] = *p
+		}
+	}
+	return positions
+}
+
+// Cash returns the cash booked from the fills.
+func (b *Broker) Cash() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cash
+}
+
+// Value returns the cash and the positions valued at the price of their last fill.
+func (b *Broker) Value() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	value := b.cash
+	for _, p := range b.positions {
+		value += p.Qty() * p.MarketPrice()
+	}
+	return value
+}
+
+// order sets the fields of a new order single or a cancel/replace request.
+func (b *Broker) order(m *Message, order *gbt.Order, qty, limit, stop float64) error {
+	if order.Direction() != gbt.BOT && order.Direction() != gbt.SLD {
+		return fmt.Errorf("could not route order for %s with direction %v", order.Symbol(), order.Direction())
+	}
+
+	tif := b.TIF
+	if tif == "" {
+		tif = "0"
+	}
+	var ordType string
+	switch order.Type() {
+	case gbt.MarketOrder:
+		ordType = "1"
+	case gbt.MarketOnOpenOrder:
+		ordType, tif = "1", "2" // at the opening
+	case gbt.MarketOnCloseOrder:
+		ordType, tif = "1", "7" // at the close
+	case gbt.LimitOrder:
+		ordType = "2"
+		m.Set(tagPrice, formatNumber(limit))
+	case gbt.StopMarketOrder:
+		ordType = "3"
+		m.Set(tagStopPx, formatNumber(stop))
+	case gbt.StopLimitOrder:
+		ordType = "4"
+		m.Set(tagPrice, formatNumber(limit))
+		m.Set(tagStopPx, formatNumber(stop))
+	default:
+		return fmt.Errorf("could not route order for %s, unsupported order type %v", order.Symbol(), order.Type())
+	}
+
+	if b.Account != "" {
+		m.Set(tagAccount, b.Account)
+	}
+	// automated execution without intervention of the broker
+	m.Set(tagHandlInst, "1").
+		Set(tagSymbol, order.Symbol()).
+		Set(tagSide, side(order.Direction())).
+		Set(tagOrderQty, formatNumber(qty)).
+		Set(tagOrdType, ordType).
+		Set(tagTimeInForce, tif)
+	return nil
+}
+
+// request sends a message and waits for the acknowledgement of its ClOrdID.
+func (b *Broker) request(id string, m *Message) error {
+	ack := make(chan error, 1)
+	b.mu.Lock()
+	b.pending[id] = ack
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, id)
+		b.mu.Unlock()
+	}()
+
+	if err := b.Session.Send(m); err != nil {
+		return err
+	}
+
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	select {
+	case err := <-ack:
+		return err
+	case <-b.Session.Done():
+		return ErrClosed
+	case <-time.After(timeout):
+		return errors.New("no acknowledgement of the counterparty")
+	}
+}
+
+// acknowledge hands the result of a request to the waiting request.
+func (b *Broker) acknowledge(id string, err error) {
+	b.mu.Lock()
+	ack, ok := b.pending[id]
+	b.mu.Unlock()
+	if ok {
+		select {
+		case ack <- err:
+		default: // already acknowledged
+		}
+		return
+	}
+	orders.Report(b.OnError, err)
+}
+
+// handle processes the application messages of the session.
+func (b *Broker) handle(m *Message) {
+	switch m.Type() {
+	case msgExecutionReport:
+		b.execution(m)
+	case msgOrderCancelReject:
+		b.acknowledge(m.Get(tagClOrdID), fmt.Errorf("rejected: %s", m.Get(tagText)))
+	case msgReject:
+		orders.Report(b.OnError, fmt.Errorf("message %s rejected: %s", m.Get(tagRefSeqNum), m.Get(tagText)))
+	}
+}
+
+// execution processes an execution report.
+func (b *Broker) execution(m *Message) {
+	id := m.Get(tagClOrdID)
+	order, ok := b.orders.Local(id)
+	if !ok {
+		order, ok = b.orders.Local(m.Get(tagOrigClOrdID))
+	}
+
+	// a resent execution report is processed once
+	if execID := m.Get(tagExecID); execID != "" {
+		b.mu.Lock()
+		seen := b.execs[execID]
+		b.execs[execID] = true
+		b.mu.Unlock()
+		if seen {
+			return
+		}
+	}
+
+	switch m.Get(tagExecType) {
+	case "0": // new
+		b.acknowledge(id, nil)
+	case "5": // replaced, the order continues under the new ClOrdID
+		if ok {
+			b.orders.Add(order, id)
+		}
+		b.acknowledge(id, nil)
+	case "4", "C": // canceled, expired
+		if ok {
+			b.orders.Finish(order.ID(), gbt.OrderCanceled)
+		}
+		b.acknowledge(id, nil)
+	case "8": // rejected
+		if ok {
+			b.orders.Finish(order.ID(), gbt.OrderInvalid)
+		}
+		b.acknowledge(id, fmt.Errorf("rejected: %s", m.Get(tagText)))
+	case "F": // trade
+		b.trade(m, order)
+		b.acknowledge(id, nil)
+	}
+}
+
+// trade books the fill of an execution report into the order, the position and the cash.
+func (b *Broker) trade(m *Message, order *gbt.Order) {
+	qty, price := m.Float(tagLastQty), m.Float(tagLastPx)
+	if qty <= 0 {
+		return
+	}
+	direction := gbt.BOT
+	if s := m.Get(tagSide); s == "2" || s == "5" {
+		direction = gbt.SLD
+	}
+	timestamp, err := time.Parse(timeFormat, m.Get(tagTransactTime))
+	if err != nil {
+		timestamp = time.Now()
+	}
+	fill := gbt.NewFill(timestamp, m.Get(tagSymbol), direction, qty, price, m.Float(tagCommission))
+
+	if order != nil {
+		b.orders.Update(order.ID(), func(o *gbt.Order) { o.Update(fill) })
+		if m.Get(tagOrdStatus) == "2" {
+			b.orders.Finish(order.ID(), gbt.OrderFilled)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p, ok := b.positions[fill.Symbol()]; ok {
+		p.Update(fill)
+	} else {
+		p := &gbt.Position{}
+		p.Create(fill)
+		b.positions[fill.Symbol()] = p
+	}
+	if direction == gbt.BOT {
+		b.cash -= fill.NetValue()
+	} else {
+		b.cash += fill.NetValue()
+	}
+}
+
+// clOrdID returns a new ClOrdID of an order.
+func (b *Broker) clOrdID(order *gbt.Order) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	return fmt.Sprintf("%s-%d-%d", b.prefix, order.ID(), b.nextID)
+}
+
+// side returns the side of a direction.
+func side(direction gbt.Direction) string {
+	if direction == gbt.SLD {
+		return "2"
+	}
+	return "1"
+}
+
+// formatNumber formats a number for a message.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,207 @@
+package fix
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// the broker implements the broker interface of the engine
+var _ gbt.Broker = &Broker{}
+
+// oms replies to the orders like an order management system: limit orders rest, market orders
+// are filled in two executions, orders of the symbol REJECT and replaces of qty 999 are rejected.
+func oms() func(*Message) []*Message {
+	var execID int
+	return func(m *Message) []*Message {
+		report := func(execType, status string) *Message {
+			execID++
+			return NewMessage(msgExecutionReport).
+				Set(tagClOrdID, m.Get(tagClOrdID)).
+				Set(tagOrigClOrdID, m.Get(tagOrigClOrdID)).
+				Set(tagExecID, strconv.Itoa(execID)).
+				Set(tagExecType, execType).
+				Set(tagOrdStatus, status).
+				Set(tagSymbol, m.Get(tagSymbol)).
+				Set(tagSide, m.Get(tagSide))
+		}
+
+		switch m.Type() {
+		case msgNewOrderSingle:
+			if m.Get(tagSymbol) == "REJECT" {
+				return []*Message{report("8", "8").Set(tagText, "unknown symbol")}
+			}
+			if m.Get(tagOrdType) != "1" {
+				return []*Message{report("0", "0")}
+			}
+			return []*Message{
+				report("0", "0"),
+				report("F", "1").Set(tagLastQty, "40").Set(tagLastPx, "100").Set(tagCommission, "1").Set(tagTransactTime, "20170601-12:00:00.000"),
+				report("F", "2").Set(tagLastQty, "60").Set(tagLastPx, "101").Set(tagCommission, "1"),
+			}
+		case msgOrderCancelRequest:
+			return []*Message{report("4", "4")}
+		case msgOrderCancelReplace:
+			if m.Get(tagOrderQty) == "999" {
+				return []*Message{NewMessage(msgOrderCancelReject).Set(tagClOrdID, m.Get(tagClOrdID)).Set(tagText, "too late to replace")}
+			}
+			return []*Message{report("5", "0")}
+		}
+		return nil
+	}
+}
+
+// newOrder creates an order of the engine.
+func newOrder(symbol string, orderType gbt.OrderType, direction gbt.Direction, qty, limit float64) *gbt.Order {
+	order := &gbt.Order{}
+	order.SetSymbol(symbol)
+	order.SetType(orderType)
+	order.SetDirection(direction)
+	order.SetQty(qty)
+	order.SetLimit(limit)
+	return order
+}
+
+func TestBrokerOrders(t *testing.T) {
+	a := newAcceptor(t, oms())
+	defer a.close()
+	s := newTestSession(t, a)
+	defer s.Close()
+	b := NewBroker(s, 100000)
+	b.Account = "ACC1"
+
+	order := newOrder("TEST.DE", gbt.LimitOrder, gbt.BOT, 100, 99.5)
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	m := a.expect(t, msgNewOrderSingle)
+	if m.Get(tagOrdType) != "2" || m.Get(tagPrice) != "99.5" || m.Get(tagSide) != "1" || m.Get(tagOrderQty) != "100" ||
+		m.Get(tagTimeInForce) != "0" || m.Get(tagHandlInst) != "1" || m.Get(tagAccount) != "ACC1" {
+		t.Errorf("Submit(): unexpected new order single %v", m)
+	}
+	if orders := b.Orders(); len(orders) != 1 || order.ID() != 1 || order.Status() != gbt.OrderSubmitted {
+		t.Errorf("Orders(): expected the working order, actual %v", orders)
+	}
+
+	if err := b.Modify(1, gbt.OrderChange{Limit: 99}); err != nil {
+		t.Fatalf("Modify(): unexpected error %v", err)
+	}
+	replace := a.expect(t, msgOrderCancelReplace)
+	if replace.Get(tagOrigClOrdID) != m.Get(tagClOrdID) || replace.Get(tagPrice) != "99" || replace.Get(tagOrderQty) != "100" || order.Limit() != 99 {
+		t.Errorf("Modify(): unexpected cancel/replace request %v", replace)
+	}
+
+	if err := b.Modify(1, gbt.OrderChange{Qty: 999}); err == nil || !strings.Contains(err.Error(), "too late to replace") {
+		t.Errorf("Modify(): expected the cancel reject, actual %v", err)
+	}
+	if order.Qty() != 100 {
+		t.Errorf("Modify(): expected the qty of the rejected replace to be kept, actual %v", order.Qty())
+	}
+
+	if err := b.Cancel(1); err != nil {
+		t.Fatalf("Cancel(): unexpected error %v", err)
+	}
+	if cancel := a.expect(t, msgOrderCancelRequest); cancel.Get(tagOrigClOrdID) != replace.Get(tagClOrdID) {
+		t.Errorf("Cancel(): expected the ClOrdID of the replacement, actual %v", cancel)
+	}
+	if len(b.Orders()) != 0 || order.Status() != gbt.OrderCanceled {
+		t.Errorf("Cancel(): expected the order to be canceled, actual %v", order.Status())
+	}
+	if err := b.Cancel(1); err == nil {
+		t.Errorf("Cancel(): expected an error for an unknown order")
+	}
+}
+
+func TestBrokerFills(t *testing.T) {
+	a := newAcceptor(t, oms())
+	defer a.close()
+	s := newTestSession(t, a)
+	defer s.Close()
+	b := NewBroker(s, 100000)
+
+	order := newOrder("TEST.DE", gbt.MarketOrder, gbt.BOT, 100, 0)
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(b.Orders()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if order.Status() != gbt.OrderFilled || order.QtyFilled() != 100 {
+		t.Errorf("Submit(): expected the order to be filled, actual %v with %v", order.Status(), order.QtyFilled())
+	}
+	position := b.Positions()["TEST.DE"]
+	if position.Qty() != 100 || position.AvgPrice() != 100.6 || position.MarketPrice() != 101 {
+		t.Errorf("Positions(): expected 100 at 100.6, actual %v at %v", position.Qty(), position.AvgPrice())
+	}
+	if b.Cash() != 89938 || b.Value() != 100038 {
+		t.Errorf("Cash(): expected cash 89938 and value 100038, actual %v %v", b.Cash(), b.Value())
+	}
+
+	// testCases is a table for testing rejected orders
+	var testCases = []struct {
+		msg    string
+		order  *gbt.Order
+		expErr string
+	}{
+		{"rejected by the counterparty:", newOrder("REJECT", gbt.MarketOrder, gbt.SLD, 10, 0), "unknown symbol"},
+		{"hold direction:", newOrder("TEST.DE", gbt.MarketOrder, gbt.HLD, 10, 0), "direction"},
+		{"no qty:", newOrder("TEST.DE", gbt.MarketOrder, gbt.BOT, 0, 0), "not positive"},
+	}
+
+	for _, tc := range testCases {
+		if err := b.Submit(tc.order); err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%v Submit(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
+
+func TestBrokerExecutions(t *testing.T) {
+	b := NewBroker(&Session{}, 1000)
+	var errs []error
+	b.OnError = func(err error) { errs = append(errs, err) }
+
+	// a fill of an order entered at the OMS, sent twice
+	fill := NewMessage(msgExecutionReport).
+		Set(tagExecID, "E1").
+		Set(tagExecType, "F").
+		Set(tagSymbol, "TEST.DE").
+		Set(tagSide, "5").
+		Set(tagLastQty, "10").
+		Set(tagLastPx, "20")
+	b.handle(fill)
+	b.handle(fill)
+
+	if p := b.Positions()["TEST.DE"]; p.Qty() != -10 || b.Cash() != 1200 || b.Value() != 1000 {
+		t.Errorf("handle(): expected a single short sale, actual qty %v cash %v", p.Qty(), b.Cash())
+	}
+
+	b.handle(NewMessage(msgReject).Set(tagRefSeqNum, "7").Set(tagText, "invalid tag"))
+	if len(errs) != 1 || errs[0].Error() != "message 7 rejected: invalid tag" {
+		t.Errorf("OnError: expected the reject, actual %v", errs)
+	}
+}
+
+func TestBrokerTimeout(t *testing.T) {
+	a := newAcceptor(t, oms())
+	defer a.close()
+	s := newTestSession(t, a)
+	defer s.Close()
+	a.mu.Lock()
+	a.silent = true
+	a.mu.Unlock()
+
+	b := NewBroker(s, 1000)
+	b.Timeout = 50 * time.Millisecond
+	order := newOrder("TEST.DE", gbt.LimitOrder, gbt.BOT, 1, 10)
+	if err := b.Submit(order); err == nil || !strings.Contains(err.Error(), "no acknowledgement") {
+		t.Errorf("Submit(): expected the timeout, actual %v", err)
+	}
+	if order.Status() != gbt.OrderInvalid || len(b.Orders()) != 0 {
+		t.Errorf("Submit(): expected the order to be invalid, actual %v", order.Status())
+	}
+}
@@ -0,0 +1,264 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// soh is the delimiter of the fields.
+const soh = '\x01'
+
+// the tags used by the session and the broker
+const (
+	tagAccount         = 1
+	tagBeginSeqNo      = 7
+	tagBeginString     = 8
+	tagBodyLength      = 9
+	tagCheckSum        = 10
+	tagClOrdID         = 11
+	tagCommission      = 12
+	tagEndSeqNo        = 16
+	tagExecID          = 17
+	tagHandlInst       = 21
+	tagLastPx          = 31
+	tagLastQty         = 32
+	tagMsgSeqNum       = 34
+	tagMsgType         = 35
+	tagNewSeqNo        = 36
+	tagOrderQty        = 38
+	tagOrdStatus       = 39
+	tagOrdType         = 40
+	tagOrigClOrdID     = 41
+	tagPossDupFlag     = 43
+	tagPrice           = 44
+	tagRefSeqNum       = 45
+	tagSenderCompID    = 49
+	tagSendingTime     = 52
+	tagSide            = 54
+	tagSymbol          = 55
+	tagTargetCompID    = 56
+	tagText            = 58
+	tagTimeInForce     = 59
+	tagTransactTime    = 60
+	tagEncryptMethod   = 98
+	tagStopPx          = 99
+	tagHeartBtInt      = 108
+	tagTestReqID       = 112
+	tagOrigSendingTime = 122
+	tagGapFillFlag     = 123
+	tagResetSeqNumFlag = 141
+	tagExecType        = 150
+	tagUsername        = 553
+	tagPassword        = 554
+)
+
+// the message types
+const (
+	msgHeartbeat          = "0"
+	msgTestRequest        = "1"
+	msgResendRequest      = "2"
+	msgReject             = "3"
+	msgSequenceReset      = "4"
+	msgLogout             = "5"
+	msgExecutionReport    = "8"
+	msgOrderCancelReject  = "9"
+	msgLogon              = "A"
+	msgNewOrderSingle     = "D"
+	msgOrderCancelRequest = "F"
+	msgOrderCancelReplace = "G"
+)
+
+// headerTags are the fields of the standard header after the message type, in the order of encoding.
+var headerTags = []int{tagSenderCompID, tagTargetCompID, tagMsgSeqNum, tagPossDupFlag, tagSendingTime, tagOrigSendingTime}
+
+// Field is a field of a message.
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message is a message of the tag=value encoding. BeginString, BodyLength and CheckSum
+// are added on encoding, the fields are kept in their order.
+type Message struct {
+	fields []Field
+}
+
+// NewMessage creates a message of a type, e.g. D for a new order single.
+func NewMessage(msgType string) *Message {
+	return (&Message{}).Set(tagMsgType, msgType)
+}
+
+// Type returns the message type.
+func (m *Message) Type() string {
+	return m.Get(tagMsgType)
+}
+
+// Fields returns the fields of the message.
+func (m *Message) Fields() []Field {
+	return m.fields
+}
+
+// Has returns if the message contains a tag.
+func (m *Message) Has(tag int) bool {
+	for _, f := range m.fields {
+		if f.Tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of a tag, empty if the tag is missing.
+func (m *Message) Get(tag int) string {
+	for _, f := range m.fields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Int returns the value of a tag as integer, 0 if the tag is missing or invalid.
+func (m *Message) Int(tag int) int {
+	i, _ := strconv.Atoi(m.Get(tag))
+	return i
+}
+
+// Float returns the value of a tag as float, 0 if the tag is missing or invalid.
+func (m *Message) Float(tag int) float64 {
+	f, _ := strconv.ParseFloat(m.Get(tag), 64)
+	return f
+}
+
+// Set sets the value of a tag, a new tag is appended.
+func (m *Message) Set(tag int, value string) *Message {
+	for i, f := range m.fields {
+		if f.Tag == tag {
+			m.fields[i].Value = value
+			return m
+		}
+	}
+	m.fields = append(m.fields, Field{Tag: tag, Value: value})
+	return m
+}
+
+// String returns the message with | as delimiter, e.g. for logging.
+func (m *Message) String() string {
+	parts := make([]string, 0, len(m.fields))
+	for _, f := range m.fields {
+		parts = append(parts, strconv.Itoa(f.Tag)+"="+f.Value)
+	}
+	return strings.Join(parts, "|")
+}
+
+// encode returns the message in the tag=value encoding, with the header fields first.
+func (m *Message) encode(beginString string) []byte {
+	var body bytes.Buffer
+	write := func(tag int, value string) {
+		body.WriteString(strconv.Itoa(tag))
+		body.WriteByte('=')
+		body.WriteString(value)
+		body.WriteByte(soh)
+	}
+
+	write(tagMsgType, m.Type())
+	header := map[int]bool{tagBeginString: true, tagBodyLength: true, tagCheckSum: true, tagMsgType: true}
+	for _, tag := range headerTags {
+		header[tag] = true
+		if m.Has(tag) {
+			write(tag, m.Get(tag))
+		}
+	}
+	for _, f := range m.fields {
+		if !header[f.Tag] {
+			write(f.Tag, f.Value)
+		}
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "8=%s%c9=%d%c", beginString, soh, body.Len(), soh)
+	msg.Write(body.Bytes())
+	fmt.Fprintf(&msg, "10=%03d%c", checksum(msg.Bytes()), soh)
+	return msg.Bytes()
+}
+
+// checksum returns the sum of the bytes modulo 256.
+func checksum(b []byte) int {
+	var sum int
+	for _, c := range b {
+		sum += int(c)
+	}
+	return sum % 256
+}
+
+// readMessage reads the next message and verifies its body length and checksum.
+func readMessage(r *bufio.Reader) (*Message, error) {
+	begin, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	if begin.Tag != tagBeginString {
+		return nil, fmt.Errorf("message starts with tag %d", begin.Tag)
+	}
+	length, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(length.Value)
+	if length.Tag != tagBodyLength || err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid body length %q", length.Value)
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	sum, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	head := fmt.Sprintf("8=%s%c9=%s%c", begin.Value, soh, length.Value, soh)
+	if expected := (checksum([]byte(head)) + checksum(body)) % 256; sum.Tag != tagCheckSum || sum.Value != fmt.Sprintf("%03d", expected) {
+		return nil, fmt.Errorf("invalid checksum %q, expected %03d", sum.Value, expected)
+	}
+
+	m := &Message{fields: []Field{begin}}
+	for _, raw := range bytes.Split(bytes.TrimSuffix(body, []byte{soh}), []byte{soh}) {
+		f, err := parseField(string(raw))
+		if err != nil {
+			return nil, err
+		}
+		m.fields = append(m.fields, f)
+	}
+	if m.Type() == "" {
+		return nil, errors.New("message without type")
+	}
+	return m, nil
+}
+
+// readField reads a field up to the delimiter.
+func readField(r *bufio.Reader) (Field, error) {
+	raw, err := r.ReadString(soh)
+	if err != nil {
+		return Field{}, err
+	}
+	return parseField(strings.TrimSuffix(raw, string(soh)))
+}
+
+// parseField parses a tag=value field.
+func parseField(raw string) (Field, error) {
+	i := strings.IndexByte(raw, '=')
+	if i <= 0 {
+		return Field{}, fmt.Errorf("invalid field %q", raw)
+	}
+	tag, err := strconv.Atoi(raw[:i])
+	if err != nil {
+		return Field{}, fmt.Errorf("invalid tag of field %q", raw)
+	}
+	return Field{Tag: tag, Value: raw[i+1:]}, nil
+}
@@ -0,0 +1,64 @@
+package fix
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestMessageEncode(t *testing.T) {
+	m := NewMessage(msgHeartbeat).
+		Set(tagTestReqID, "TEST").
+		Set(tagSenderCompID, "CLIENT").
+		Set(tagTargetCompID, "BROKER").
+		Set(tagMsgSeqNum, "2").
+		Set(tagSendingTime, "20170601-12:00:00.000")
+
+	exp := "8=FIX.4.4|9=64|35=0|49=CLIENT|56=BROKER|34=2|52=20170601-12:00:00.000|112=TEST|10=081|"
+	if encoded := strings.Replace(string(m.encode("FIX.4.4")), "\x01", "|", -1); encoded != exp {
+		t.Errorf("encode(): expected the header first\nexpected %v\nactual   %v", exp, encoded)
+	}
+
+	decoded, err := readMessage(bufio.NewReader(strings.NewReader(string(m.encode("FIX.4.4")))))
+	if err != nil {
+		t.Fatalf("readMessage(): unexpected error %v", err)
+	}
+	if decoded.Type() != msgHeartbeat || decoded.Get(tagBeginString) != "FIX.4.4" || decoded.Int(tagMsgSeqNum) != 2 || decoded.Get(tagTestReqID) != "TEST" {
+		t.Errorf("readMessage(): unexpected message %v", decoded)
+	}
+}
+
+func TestMessageFields(t *testing.T) {
+	m := NewMessage(msgExecutionReport).Set(tagLastQty, "10").Set(tagLastPx, "99.5").Set(tagLastQty, "20")
+
+	if m.Float(tagLastQty) != 20 || m.Float(tagLastPx) != 99.5 || m.Int(tagText) != 0 || m.Has(tagText) || !m.Has(tagLastPx) {
+		t.Errorf("Set(): expected the replaced value, actual %v", m)
+	}
+	if s := m.String(); s != "35=8|32=20|31=99.5" {
+		t.Errorf("String(): unexpected %v", s)
+	}
+	if len(m.Fields()) != 3 {
+		t.Errorf("Fields(): expected 3 fields, actual %v", m.Fields())
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	// testCases is a table for testing invalid messages
+	var testCases = []struct {
+		msg string
+		raw string
+	}{
+		{"wrong checksum:", "8=FIX.4.4|9=5|35=0|10=000|"},
+		{"missing begin string:", "9=5|35=0|10=000|"},
+		{"invalid body length:", "8=FIX.4.4|9=x|35=0|10=000|"},
+		{"invalid field:", "8=FIX.4.4|9=4|350|10=000|"},
+		{"truncated body:", "8=FIX.4.4|9=50|35=0|"},
+	}
+
+	for _, tc := range testCases {
+		raw := strings.Replace(tc.raw, "|", "\x01", -1)
+		if _, err := readMessage(bufio.NewReader(strings.NewReader(raw))); err == nil {
+			t.Errorf("%v readMessage(): expected an error", tc.msg)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+//go:build quickfix
+// +build quickfix
+
+package fix
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// QuickFIX is a Transport on an initiator of quickfixgo. The settings define a single session,
+// e.g.
+//
+//	[DEFAULT]
+//	SocketConnectHost=fix.example.com
+//	SocketConnectPort=9876
+//	HeartBtInt=30
+//	ReconnectInterval=5
+//
+//	[SESSION]
+//	BeginString=FIX.4.4
+//	SenderCompID=CLIENT
+//	TargetCompID=BROKER
+//
+// The messages are kept in memory and not logged, unless Store and Log are set,
+// e.g. to the file store of quickfixgo.
+type QuickFIX struct {
+	Settings *quickfix.Settings
+	Store    quickfix.MessageStoreFactory // defaults to the memory store
+	Log      quickfix.LogFactory          // defaults to no log
+	// Username and Password are sent on the logon, if set
+	Username string
+	Password string
+
+	mu        sync.Mutex
+	initiator *quickfix.Initiator
+	session   quickfix.SessionID
+	receive   func(*Message)
+	logon     chan struct{}
+	logonOnce sync.Once
+	done      chan struct{}
+	doneOnce  sync.Once
+}
+
+// NewQuickFIX creates a transport with the settings of quickfixgo read from r.
+func NewQuickFIX(r io.Reader) (*QuickFIX, error) {
+	settings, err := quickfix.ParseSettings(r)
+	if err != nil {
+		return nil, err
+	}
+	return &QuickFIX{Settings: settings}, nil
+}
+
+// Start starts the initiator and waits for the logon of the session. Lost connections are
+// reconnected by quickfixgo until Close.
+func (q *QuickFIX) Start(ctx context.Context) error {
+	if q.Settings == nil {
+		return errors.New("could not start fix session without settings")
+	}
+	store, log := q.Store, q.Log
+	if store == nil {
+		store = quickfix.NewMemoryStoreFactory()
+	}
+	if log == nil {
+		log = quickfix.NewNullLogFactory()
+	}
+
+	q.mu.Lock()
+	q.logon = make(chan struct{})
+	q.done = make(chan struct{})
+	q.mu.Unlock()
+
+	initiator, err := quickfix.NewInitiator(q, store, q.Settings, log)
+	if err != nil {
+		return err
+	}
+	if err := initiator.Start(); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.initiator = initiator
+	q.mu.Unlock()
+
+	select {
+	case <-q.logon:
+		return nil
+	case <-ctx.Done():
+		q.Close()
+		return ctx.Err()
+	}
+}
+
+// Close logs out and stops the initiator.
+func (q *QuickFIX) Close() error {
+	q.mu.Lock()
+	initiator := q.initiator
+	q.initiator = nil
+	q.mu.Unlock()
+
+	if initiator != nil {
+		initiator.Stop()
+	}
+	q.doneOnce.Do(func() {
+		if q.done != nil {
+			close(q.done)
+		}
+	})
+	return nil
+}
+
+// Send implements Transport, the header is set by quickfixgo.
+func (q *QuickFIX) Send(m *Message) error {
+	q.mu.Lock()
+	started, session := q.initiator != nil, q.session
+	q.mu.Unlock()
+	if !started {
+		return ErrClosed
+	}
+
+	msg := quickfix.NewMessage()
+	for _, f := range m.Fields() {
+		if f.Tag == tagMsgType {
+			msg.Header.SetField(quickfix.Tag(f.Tag), quickfix.FIXString(f.Value))
+			continue
+		}
+		msg.Body.SetField(quickfix.Tag(f.Tag), quickfix.FIXString(f.Value))
+	}
+	return quickfix.SendToTarget(msg, session)
+}
+
+// Receive implements Transport.
+func (q *QuickFIX) Receive(fn func(*Message)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.receive = fn
+}
+
+// Done implements Transport, the channel is closed by Close.
+func (q *QuickFIX) Done() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.done
+}
+
+// OnCreate implements quickfix.Application.
+func (q *QuickFIX) OnCreate(session quickfix.SessionID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.session = session
+}
+
+// OnLogon implements quickfix.Application.
+func (q *QuickFIX) OnLogon(quickfix.SessionID) {
+	q.logonOnce.Do(func() { close(q.logon) })
+}
+
+// OnLogout implements quickfix.Application.
+func (q *QuickFIX) OnLogout(quickfix.SessionID) {}
+
+// ToAdmin implements quickfix.Application, it adds the credentials to the logon.
+func (q *QuickFIX) ToAdmin(msg *quickfix.Message, _ quickfix.SessionID) {
+	if msgType, err := msg.MsgType(); err != nil || msgType != msgLogon {
+		return
+	}
+	if q.Username != "" {
+		msg.Body.SetField(quickfix.Tag(tagUsername), quickfix.FIXString(q.Username))
+	}
+	if q.Password != "" {
+		msg.Body.SetField(quickfix.Tag(tagPassword), quickfix.FIXString(q.Password))
+	}
+}
+
+// ToApp implements quickfix.Application.
+func (q *QuickFIX) ToApp(*quickfix.Message, quickfix.SessionID) error {
+	return nil
+}
+
+// FromAdmin implements quickfix.Application, it hands the session level rejects to the receiver.
+func (q *QuickFIX) FromAdmin(msg *quickfix.Message, _ quickfix.SessionID) quickfix.MessageRejectError {
+	if msgType, err := msg.MsgType(); err == nil && msgType == msgReject {
+		q.deliver(msg)
+	}
+	return nil
+}
+
+// FromApp implements quickfix.Application, it hands the application messages to the receiver.
+func (q *QuickFIX) FromApp(msg *quickfix.Message, _ quickfix.SessionID) quickfix.MessageRejectError {
+	q.deliver(msg)
+	return nil
+}
+
+// deliver converts a message of quickfixgo and hands it to the receiver.
+func (q *QuickFIX) deliver(msg *quickfix.Message) {
+	q.mu.Lock()
+	receive := q.receive
+	q.mu.Unlock()
+	if receive == nil {
+		return
+	}
+
+	m, err := readMessage(bufio.NewReader(strings.NewReader(msg.String())))
+	if err != nil {
+		return
+	}
+	receive(m)
+}
@@ -0,0 +1,174 @@
+//go:build quickfix
+// +build quickfix
+
+package fix
+
+// The tests of the quickfix tag run the QuickFIX transport against an acceptor of quickfixgo,
+// which is not a dependency of the module, e.g.
+//
+//	go get github.com/quickfixgo/quickfix
+//	go test -tags quickfix ./broker/fix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// quickfixAcceptor is the application of the acceptor of the test, it hands the logon and the
+// application messages received to the test as raw messages.
+type quickfixAcceptor struct {
+	logon    chan quickfix.SessionID
+	admin    chan string
+	received chan string
+}
+
+func (a *quickfixAcceptor) OnCreate(quickfix.SessionID)                   {}
+func (a *quickfixAcceptor) OnLogon(session quickfix.SessionID)            { a.logon <- session }
+func (a *quickfixAcceptor) OnLogout(quickfix.SessionID)                   {}
+func (a *quickfixAcceptor) ToAdmin(*quickfix.Message, quickfix.SessionID) {}
+func (a *quickfixAcceptor) ToApp(*quickfix.Message, quickfix.SessionID) error {
+	return nil
+}
+
+func (a *quickfixAcceptor) FromAdmin(msg *quickfix.Message, _ quickfix.SessionID) quickfix.MessageRejectError {
+	if msgType, err := msg.MsgType(); err == nil && msgType == msgLogon {
+		a.admin <- msg.String()
+	}
+	return nil
+}
+
+func (a *quickfixAcceptor) FromApp(msg *quickfix.Message, _ quickfix.SessionID) quickfix.MessageRejectError {
+	a.received <- msg.String()
+	return nil
+}
+
+// freePort returns a free local port.
+func freePort(t *testing.T) int {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func TestQuickFIX(t *testing.T) {
+	port := freePort(t)
+
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SocketAcceptPort=%d
+HeartBtInt=30
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=BROKER
+TargetCompID=CLIENT
+`, port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &quickfixAcceptor{
+		logon:    make(chan quickfix.SessionID, 1),
+		admin:    make(chan string, 1),
+		received: make(chan string, 1),
+	}
+	acc, err := quickfix.NewAcceptor(app, quickfix.NewMemoryStoreFactory(), settings, quickfix.NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Stop()
+
+	q, err := NewQuickFIX(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SocketConnectHost=127.0.0.1
+SocketConnectPort=%d
+HeartBtInt=30
+ReconnectInterval=1
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=CLIENT
+TargetCompID=BROKER
+`, port)))
+	if err != nil {
+		t.Fatalf("NewQuickFIX(): unexpected error %v", err)
+	}
+	q.Username = "user"
+	received := make(chan *Message, 1)
+	q.Receive(func(m *Message) { received <- m })
+
+	if err := q.Send(NewMessage(msgNewOrderSingle)); err != ErrClosed {
+		t.Errorf("Send(): expected ErrClosed before the start, actual %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := q.Start(ctx); err != nil {
+		t.Fatalf("Start(): unexpected error %v", err)
+	}
+
+	var session quickfix.SessionID
+	select {
+	case session = <-app.logon:
+	case <-ctx.Done():
+		t.Fatal("Start(): expected the logon at the acceptor")
+	}
+	if logon := <-app.admin; !strings.Contains(logon, "\x01553=user\x01") {
+		t.Errorf("Start(): expected the username in the logon, actual %q", logon)
+	}
+
+	// an order is sent to the acceptor
+	order := NewMessage(msgNewOrderSingle).Set(tagClOrdID, "1").Set(tagSymbol, "TEST.DE").Set(tagSide, "1").Set(tagOrderQty, "10")
+	if err := q.Send(order); err != nil {
+		t.Fatalf("Send(): unexpected error %v", err)
+	}
+	select {
+	case raw := <-app.received:
+		for _, field := range []string{"\x0135=D\x01", "\x0111=1\x01", "\x0155=TEST.DE\x01", "\x0138=10\x01"} {
+			if !strings.Contains(raw, field) {
+				t.Errorf("Send(): expected the field %q in the order, actual %q", field, raw)
+			}
+		}
+	case <-ctx.Done():
+		t.Fatal("Send(): expected the order at the acceptor")
+	}
+
+	// an execution report is handed to the receiver
+	report := quickfix.NewMessage()
+	report.Header.SetField(quickfix.Tag(tagMsgType), quickfix.FIXString(msgExecutionReport))
+	report.Body.SetField(quickfix.Tag(tagClOrdID), quickfix.FIXString("1"))
+	report.Body.SetField(quickfix.Tag(tagLastQty), quickfix.FIXString("10"))
+	if err := quickfix.SendToTarget(report, session); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-received:
+		if m.Type() != msgExecutionReport || m.Get(tagClOrdID) != "1" || m.Float(tagLastQty) != 10 {
+			t.Errorf("Receive(): expected the execution report, actual %v", m)
+		}
+	case <-ctx.Done():
+		t.Fatal("Receive(): expected the execution report")
+	}
+
+	if err := q.Close(); err != nil {
+		t.Errorf("Close(): unexpected error %v", err)
+	}
+	select {
+	case <-q.Done():
+	default:
+		t.Errorf("Done(): expected the channel to be closed after Close")
+	}
+	if err := q.Send(order); err != ErrClosed {
+		t.Errorf("Send(): expected ErrClosed after Close, actual %v", err)
+	}
+}
@@ -0,0 +1,396 @@
+// Package fix routes the orders of a strategy over a FIX 4.4 session to an OMS or EMS
+// and books the fills of their execution reports.
+//
+// A production session runs on quickfixgo, github.com/quickfixgo/quickfix, with its message
+// store, the resend of messages, the session schedule and the validation by data dictionary.
+// The QuickFIX transport is built with the quickfix tag, after adding the module with
+// go get github.com/quickfixgo/quickfix:
+//
+//	settings, err := os.Open("initiator.cfg")
+//	if err != nil {
+//		return err
+//	}
+//	session, err := fix.NewQuickFIX(settings)
+//	if err != nil {
+//		return err
+//	}
+//	if err := session.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer session.Close()
+//
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetBroker(fix.NewBroker(session, 100000))
+//	err = test.RunContext(ctx)
+//
+// Without the tag, Session is a minimal initiator built on the standard library, e.g. for tests
+// against a simulator. It handles the logon, heartbeats, test requests and sequence gaps, but it
+// keeps no message store, the sequence numbers are reset on every logon:
+//
+//	session := &fix.Session{SenderCompID: "CLIENT", TargetCompID: "BROKER"}
+//	if err := session.Dial(ctx, "fix.example.com:9876"); err != nil {
+//		return err
+//	}
+//	defer session.Close()
+package fix
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// timeFormat is the format of the UTC timestamps.
+const timeFormat = "20060102-15:04:05.000"
+
+// ErrClosed is returned for a message sent on a closed session.
+var ErrClosed = errors.New("fix session closed")
+
+// Session is the initiator of a FIX session. The sequence numbers are reset on the logon,
+// a resend request of the counterparty is answered with a gap fill, as the orders
+// of a previous connection are not sent again.
+type Session struct {
+	BeginString  string // defaults to FIX.4.4
+	SenderCompID string
+	TargetCompID string
+	// Username and Password are sent on the logon, if set
+	Username string
+	Password string
+	// HeartBtInt sets the heartbeat interval, defaults to 30 seconds
+	HeartBtInt time.Duration
+	TLS        *tls.Config // connects with tls, if set
+	// OnMessage receives the application messages and the session level rejects,
+	// it is called from the reader of the session
+	OnMessage func(*Message)
+
+	mu       sync.Mutex // guards the connection and the sequence numbers
+	conn     net.Conn
+	outSeq   int // next outgoing sequence number
+	inSeq    int // next expected incoming sequence number
+	lastSent time.Time
+	lastRecv time.Time
+	testReq  bool // a test request is pending
+	logout   bool // the logout is initiated by the session
+
+	done chan struct{}
+	once sync.Once
+	err  error
+}
+
+// Dial connects to the acceptor at addr and logs on.
+func (s *Session) Dial(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if s.TLS != nil {
+		tlsConn := tls.Client(conn, s.TLS)
+		if deadline, ok := ctx.Deadline(); ok {
+			tlsConn.SetDeadline(deadline)
+		}
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return err
+		}
+		tlsConn.SetDeadline(time.Time{})
+		conn = tlsConn
+	}
+	return s.Start(ctx, conn)
+}
+
+// Start logs on over a connection and runs the session until Close, a logout of
+// the counterparty or a failure of the connection.
+func (s *Session) Start(ctx context.Context, conn net.Conn) error {
+	s.mu.Lock()
+	s.conn = conn
+	s.outSeq, s.inSeq = 1, 1
+	s.done = make(chan struct{})
+	s.once = sync.Once{}
+	s.err = nil
+	s.logout = false
+	s.mu.Unlock()
+
+	logon := NewMessage(msgLogon).
+		Set(tagEncryptMethod, "0").
+		Set(tagHeartBtInt, strconv.Itoa(int(s.heartBtInt()/time.Second))).
+		Set(tagResetSeqNumFlag, "Y")
+	if s.Username != "" {
+		logon.Set(tagUsername, s.Username)
+	}
+	if s.Password != "" {
+		logon.Set(tagPassword, s.Password)
+	}
+	if err := s.Send(logon); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// the context bounds the wait for the logon
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	r := bufio.NewReader(conn)
+	reply, err := readMessage(r)
+	close(stop)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("could not logon: %v", err)
+	}
+	switch reply.Type() {
+	case msgLogon:
+	case msgLogout:
+		conn.Close()
+		return fmt.Errorf("logon rejected: %s", reply.Get(tagText))
+	default:
+		conn.Close()
+		return fmt.Errorf("could not logon, unexpected message type %s", reply.Type())
+	}
+
+	s.mu.Lock()
+	s.inSeq = reply.Int(tagMsgSeqNum) + 1
+	s.lastRecv = time.Now()
+	s.mu.Unlock()
+
+	go s.read(r)
+	go s.heartbeat()
+	return nil
+}
+
+// Send sends a message, the header fields are set by the session.
+func (s *Session) Send(m *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.send(m, 0)
+}
+
+// send writes a message with the next sequence number, or with seq for a gap fill.
+// It is called under the lock.
+func (s *Session) send(m *Message, seq int) error {
+	if s.conn == nil || s.closed() {
+		return ErrClosed
+	}
+	if seq == 0 {
+		seq = s.outSeq
+		s.outSeq++
+	}
+	m.Set(tagSenderCompID, s.SenderCompID).
+		Set(tagTargetCompID, s.TargetCompID).
+		Set(tagMsgSeqNum, strconv.Itoa(seq)).
+		Set(tagSendingTime, time.Now().UTC().Format(timeFormat))
+
+	s.lastSent = time.Now()
+	_, err := s.conn.Write(m.encode(s.beginString()))
+	return err
+}
+
+// Close logs out and closes the connection. It waits for the logout of the counterparty
+// up to the heartbeat interval.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.conn == nil || s.closed() {
+		s.mu.Unlock()
+		return nil
+	}
+	s.logout = true
+	err := s.send(NewMessage(msgLogout), 0)
+	done := s.done
+	s.mu.Unlock()
+
+	if err == nil {
+		select {
+		case <-done:
+		case <-time.After(s.heartBtInt()):
+		}
+	}
+	s.finish(nil)
+	return err
+}
+
+// Receive implements Transport by setting OnMessage.
+func (s *Session) Receive(fn func(*Message)) {
+	s.OnMessage = fn
+}
+
+// Done returns a channel, which is closed when the session ends.
+func (s *Session) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// Err returns the error, which ended the session, nil after a logout.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// read receives the messages until the connection fails.
+func (s *Session) read(r *bufio.Reader) {
+	for {
+		m, err := readMessage(r)
+		if err != nil {
+			s.finish(err)
+			return
+		}
+		if err := s.handle(m); err != nil {
+			s.finish(err)
+			return
+		}
+	}
+}
+
+// handle processes the session level messages and checks the sequence numbers,
+// the application messages are handed to OnMessage.
+func (s *Session) handle(m *Message) error {
+	s.mu.Lock()
+	s.lastRecv = time.Now()
+	s.testReq = false
+
+	seq := m.Int(tagMsgSeqNum)
+	if m.Type() == msgSequenceReset {
+		if next := m.Int(tagNewSeqNo); next > s.inSeq {
+			s.inSeq = next
+		}
+		s.mu.Unlock()
+		return nil
+	}
+	switch {
+	case seq > s.inSeq:
+		// request the missing messages, the message itself is processed
+		resend := NewMessage(msgResendRequest).
+			Set(tagBeginSeqNo, strconv.Itoa(s.inSeq)).
+			Set(tagEndSeqNo, "0")
+		if err := s.send(resend, 0); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		s.inSeq = seq + 1
+	case seq < s.inSeq:
+		if m.Get(tagPossDupFlag) != "Y" {
+			s.mu.Unlock()
+			return fmt.Errorf("sequence number %d too low, expected %d", seq, s.inSeq)
+		}
+	default:
+		s.inSeq++
+	}
+
+	var err error
+	switch m.Type() {
+	case msgHeartbeat, msgLogon:
+	case msgTestRequest:
+		err = s.send(NewMessage(msgHeartbeat).Set(tagTestReqID, m.Get(tagTestReqID)), 0)
+	case msgResendRequest:
+		// gap fill up to the next sequence number
+		fill := NewMessage(msgSequenceReset).
+			Set(tagGapFillFlag, "Y").
+			Set(tagPossDupFlag, "Y").
+			Set(tagNewSeqNo, strconv.Itoa(s.outSeq))
+		err = s.send(fill, m.Int(tagBeginSeqNo))
+	case msgLogout:
+		initiated := s.logout
+		if !initiated {
+			s.send(NewMessage(msgLogout), 0)
+		}
+		s.mu.Unlock()
+		if initiated {
+			s.finish(nil)
+			return nil
+		}
+		return fmt.Errorf("logout by counterparty: %s", m.Get(tagText))
+	default:
+		s.mu.Unlock()
+		if s.OnMessage != nil {
+			s.OnMessage(m)
+		}
+		return nil
+	}
+	s.mu.Unlock()
+	return err
+}
+
+// heartbeat sends the heartbeats and test requests and ends a session without messages
+// of the counterparty for two heartbeat intervals.
+func (s *Session) heartbeat() {
+	interval := s.heartBtInt()
+	ticker := time.NewTicker(interval / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			var err error
+			switch {
+			case now.Sub(s.lastRecv) >= 2*interval:
+				err = errors.New("heartbeat timeout")
+			case now.Sub(s.lastRecv) >= interval+interval/5 && !s.testReq:
+				s.testReq = true
+				err = s.send(NewMessage(msgTestRequest).Set(tagTestReqID, now.UTC().Format(timeFormat)), 0)
+			case now.Sub(s.lastSent) >= interval:
+				err = s.send(NewMessage(msgHeartbeat), 0)
+			}
+			s.mu.Unlock()
+			if err != nil {
+				s.finish(err)
+				return
+			}
+		}
+	}
+}
+
+// finish ends the session with an error and closes the connection.
+func (s *Session) finish(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		close(s.done)
+		s.mu.Unlock()
+	})
+}
+
+// closed returns if the session ended, it is called under the lock.
+func (s *Session) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// beginString returns the version of the protocol.
+func (s *Session) beginString() string {
+	if s.BeginString == "" {
+		return "FIX.4.4"
+	}
+	return s.BeginString
+}
+
+// heartBtInt returns the heartbeat interval.
+func (s *Session) heartBtInt() time.Duration {
+	if s.HeartBtInt <= 0 {
+		return 30 * time.Second
+	}
+	return s.HeartBtInt
+}
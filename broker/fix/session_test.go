@@ -0,0 +1,253 @@
+package fix
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// acceptor is a fake counterparty of a single session. It answers the logon, the logout
+// and the test requests and replies to the application messages with the messages of reply.
+type acceptor struct {
+	ln       net.Listener
+	reply    func(*Message) []*Message
+	received chan *Message
+
+	mu     sync.Mutex
+	conn   net.Conn
+	seq    int
+	silent bool // answers nothing after the logon
+}
+
+func newAcceptor(t *testing.T, reply func(*Message) []*Message) *acceptor {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	a := &acceptor{ln: ln, reply: reply, received: make(chan *Message, 100)}
+	go a.serve()
+	return a
+}
+
+func (a *acceptor) addr() string {
+	return a.ln.Addr().String()
+}
+
+func (a *acceptor) close() {
+	a.ln.Close()
+	a.mu.Lock()
+	if a.conn != nil {
+		a.conn.Close()
+	}
+	a.mu.Unlock()
+}
+
+func (a *acceptor) serve() {
+	conn, err := a.ln.Accept()
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	a.conn = conn
+	a.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	for {
+		m, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		a.received <- m
+
+		switch m.Type() {
+		case msgLogon:
+			a.send(NewMessage(msgLogon).Set(tagHeartBtInt, m.Get(tagHeartBtInt)))
+		case msgLogout:
+			a.send(NewMessage(msgLogout))
+			conn.Close()
+			return
+		case msgTestRequest:
+			if !a.quiet() {
+				a.send(NewMessage(msgHeartbeat).Set(tagTestReqID, m.Get(tagTestReqID)))
+			}
+		case msgHeartbeat, msgResendRequest, msgSequenceReset:
+		default:
+			if a.reply != nil && !a.quiet() {
+				for _, r := range a.reply(m) {
+					a.send(r)
+				}
+			}
+		}
+	}
+}
+
+// quiet returns if the acceptor answers nothing.
+func (a *acceptor) quiet() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.silent
+}
+
+// send sends a message with the next sequence number, unless it has a sequence number.
+func (a *acceptor) send(m *Message) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !m.Has(tagMsgSeqNum) {
+		a.seq++
+		m.Set(tagMsgSeqNum, strconv.Itoa(a.seq))
+	}
+	m.Set(tagSenderCompID, "BROKER").Set(tagTargetCompID, "CLIENT").Set(tagSendingTime, time.Now().UTC().Format(timeFormat))
+	a.conn.Write(m.encode("FIX.4.4"))
+}
+
+// expect returns the next received message of a type.
+func (a *acceptor) expect(t *testing.T, msgType string) *Message {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case m := <-a.received:
+			if m.Type() == msgType {
+				return m
+			}
+		case <-timeout:
+			t.Fatalf("expected a message of type %s", msgType)
+			return nil
+		}
+	}
+}
+
+// newTestSession returns a session logged on at a fake acceptor.
+func newTestSession(t *testing.T, a *acceptor) *Session {
+	s := &Session{SenderCompID: "CLIENT", TargetCompID: "BROKER", Username: "user", Password: "secret", HeartBtInt: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Dial(ctx, a.addr()); err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	return s
+}
+
+func TestSessionLogon(t *testing.T) {
+	a := newAcceptor(t, nil)
+	defer a.close()
+	s := newTestSession(t, a)
+
+	logon := a.expect(t, msgLogon)
+	if logon.Get(tagResetSeqNumFlag) != "Y" || logon.Get(tagUsername) != "user" || logon.Get(tagPassword) != "secret" ||
+		logon.Get(tagHeartBtInt) != "1" || logon.Get(tagSenderCompID) != "CLIENT" || logon.Get(tagMsgSeqNum) != "1" {
+		t.Errorf("Dial(): unexpected logon %v", logon)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close(): unexpected error %v", err)
+	}
+	a.expect(t, msgLogout)
+	<-s.Done()
+	if s.Err() != nil {
+		t.Errorf("Err(): expected no error after the logout, actual %v", s.Err())
+	}
+	if err := s.Send(NewMessage(msgHeartbeat)); err != ErrClosed {
+		t.Errorf("Send(): expected ErrClosed, actual %v", err)
+	}
+}
+
+func TestSessionLogonRejected(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		readMessage(bufio.NewReader(conn))
+		conn.Write(NewMessage(msgLogout).Set(tagMsgSeqNum, "1").Set(tagText, "invalid password").encode("FIX.4.4"))
+	}()
+
+	s := &Session{SenderCompID: "CLIENT", TargetCompID: "BROKER"}
+	if err := s.Dial(context.Background(), ln.Addr().String()); err == nil || !strings.Contains(err.Error(), "invalid password") {
+		t.Errorf("Dial(): expected the rejected logon, actual %v", err)
+	}
+}
+
+func TestSessionAdmin(t *testing.T) {
+	a := newAcceptor(t, nil)
+	defer a.close()
+	s := newTestSession(t, a)
+	defer s.Close()
+	a.expect(t, msgLogon)
+
+	// a test request is answered with a heartbeat
+	a.send(NewMessage(msgTestRequest).Set(tagTestReqID, "ping"))
+	if m := a.expect(t, msgHeartbeat); m.Get(tagTestReqID) != "ping" {
+		t.Errorf("TestRequest: expected a heartbeat with the id, actual %v", m)
+	}
+
+	// a resend request is answered with a gap fill
+	a.send(NewMessage(msgResendRequest).Set(tagBeginSeqNo, "1").Set(tagEndSeqNo, "0"))
+	if m := a.expect(t, msgSequenceReset); m.Get(tagGapFillFlag) != "Y" || m.Get(tagMsgSeqNum) != "1" || m.Int(tagNewSeqNo) != 3 {
+		t.Errorf("ResendRequest: expected a gap fill from 1 to 3, actual %v", m)
+	}
+
+	// a gap of the counterparty is requested, the message is processed
+	a.mu.Lock()
+	a.seq += 5
+	a.mu.Unlock()
+	a.send(NewMessage(msgTestRequest).Set(tagTestReqID, "gap"))
+	if m := a.expect(t, msgResendRequest); m.Get(tagBeginSeqNo) != "4" || m.Get(tagEndSeqNo) != "0" {
+		t.Errorf("gap: expected a resend request from 4, actual %v", m)
+	}
+	a.expect(t, msgHeartbeat)
+
+	// a sequence number too low without PossDupFlag ends the session
+	a.send(NewMessage(msgHeartbeat).Set(tagMsgSeqNum, "2"))
+	<-s.Done()
+	if s.Err() == nil || !strings.Contains(s.Err().Error(), "too low") {
+		t.Errorf("Err(): expected the sequence number error, actual %v", s.Err())
+	}
+}
+
+func TestSessionEnd(t *testing.T) {
+	// testCases is a table for testing the end of a session by the counterparty
+	var testCases = []struct {
+		msg    string
+		end    func(*acceptor)
+		expErr string
+	}{
+		{"logout:", func(a *acceptor) { a.send(NewMessage(msgLogout).Set(tagText, "maintenance")) }, "logout by counterparty: maintenance"},
+		{"heartbeat timeout:", func(a *acceptor) {
+			a.mu.Lock()
+			a.silent = true
+			a.mu.Unlock()
+		}, "heartbeat timeout"},
+	}
+
+	for _, tc := range testCases {
+		a := newAcceptor(t, nil)
+		s := &Session{SenderCompID: "CLIENT", TargetCompID: "BROKER", HeartBtInt: 100 * time.Millisecond}
+		if err := s.Dial(context.Background(), a.addr()); err != nil {
+			t.Fatalf("%v Dial(): unexpected error %v", tc.msg, err)
+		}
+		a.expect(t, msgLogon)
+		tc.end(a)
+
+		select {
+		case <-s.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%v Done(): expected the end of the session", tc.msg)
+		}
+		if s.Err() == nil || s.Err().Error() != tc.expErr {
+			t.Errorf("%v Err(): expected %q, actual %v", tc.msg, tc.expErr, s.Err())
+		}
+		a.close()
+	}
+}
@@ -0,0 +1,289 @@
+package ib
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/broker/internal/orders"
+)
+
+// Broker implements gobacktest.Broker for an account of the gateway.
+// An order is placed with the id of the engine as its cOID and tracked by the order id of the gateway.
+type Broker struct {
+	*Client
+	Account string
+	TIF     string // time in force of the orders, defaults to DAY
+	// Confirm confirms the warnings of the gateway on an order, e.g. a price far from the market,
+	// else such an order fails with the warning
+	Confirm bool
+	// OnError receives the failed portfolio and order list requests of the gateway,
+	// e.g. of an expired session
+	OnError func(error)
+
+	orders orders.Tracker
+}
+
+// NewBroker creates a broker of an account.
+func NewBroker(c *Client, account string) *Broker {
+	return &Broker{Client: c, Account: account}
+}
+
+// errNoReply is returned for an order request without a reply of the gateway.
+var errNoReply = errors.New("no reply of the gateway")
+
+// ticket is an order request of the gateway.
+type ticket struct {
+	AcctID    string  `json:"acctId"`
+	Conid     int     `json:"conid"`
+	COID      string  `json:"cOID,omitempty"`
+	OrderType string  `json:"orderType"`
+	Price     float64 `json:"price,omitempty"`
+	AuxPrice  float64 `json:"auxPrice,omitempty"`
+	Side      string  `json:"side"`
+	Quantity  float64 `json:"quantity"`
+	TIF       string  `json:"tif"`
+}
+
+// reply is a response of the gateway to an order request, either the placed order
+// or a warning to confirm.
+type reply struct {
+	ID          string   `json:"id"`
+	Message     []string `json:"message"`
+	OrderID     string   `json:"order_id"`
+	OrderStatus string   `json:"order_status"`
+}
+
+// Submit places an order at the gateway.
+func (b *Broker) Submit(order *gbt.Order) error {
+	if order == nil || order.Symbol() == "" {
+		return errors.New("could not submit order without symbol")
+	}
+	if order.Qty() <= 0 {
+		return fmt.Errorf("could not submit order for %s, qty %v not positive", order.Symbol(), order.Qty())
+	}
+
+	b.orders.Assign(order)
+	t, err := b.ticket(order)
+	if err != nil {
+		return err
+	}
+	t.COID = strconv.Itoa(order.ID())
+
+	var replies []reply
+	if err := b.do(http.MethodPost, "/iserver/account/"+b.Account+"/orders", map[string][]ticket{"orders": {t}}, &replies); err != nil {
+		return fmt.Errorf("could not submit order for %s: %v", order.Symbol(), err)
+	}
+	placed, err := b.confirm(replies)
+	if err != nil {
+		return fmt.Errorf("could not submit order for %s: %v", order.Symbol(), err)
+	}
+
+	if order.Time().IsZero() {
+		order.SetTime(time.Now())
+	}
+	order.SetStatus(gbt.OrderSubmitted)
+	b.orders.Add(order, placed.OrderID)
+	return nil
+}
+
+// Cancel cancels a resting order.
+func (b *Broker) Cancel(id int) error {
+	_, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	if err := b.do(http.MethodDelete, "/iserver/account/"+b.Account+"/order/"+remote, nil, nil); err != nil {
+		return fmt.Errorf("could not cancel order %v: %v", id, err)
+	}
+
+	b.orders.Finish(id, gbt.OrderCanceled)
+	return nil
+}
+
+// Modify modifies a resting order.
+func (b *Broker) Modify(id int, change gbt.OrderChange) error {
+	order, remote, err := b.orders.Lookup(id)
+	if err != nil {
+		return err
+	}
+
+	modified := *order
+	if change.Qty > 0 {
+		modified.SetQty(change.Qty)
+	}
+	if change.Limit > 0 {
+		modified.SetLimit(change.Limit)
+	}
+	if change.Stop > 0 {
+		modified.SetStop(change.Stop)
+	}
+	t, err := b.ticket(&modified)
+	if err != nil {
+		return err
+	}
+
+	var replies []reply
+	if err := b.do(http.MethodPost, "/iserver/account/"+b.Account+"/order/"+remote, t, &replies); err != nil {
+		return fmt.Errorf("could not modify order %v: %v", id, err)
+	}
+	if _, err := b.confirm(replies); err != nil {
+		return fmt.Errorf("could not modify order %v: %v", id, err)
+	}
+
+	b.orders.Update(id, func(order *gbt.Order) {
+		order.SetQty(modified.Qty())
+		order.SetLimit(modified.Limit())
+		order.SetStop(modified.Stop())
+	})
+	return nil
+}
+
+// Orders returns the orders resting at the gateway. Filled and cancelled orders are removed.
+func (b *Broker) Orders() []gbt.OrderEvent {
+	var live struct {
+		Orders []struct {
+			OrderID int    `json:"orderId"`
+			Status  string `json:"status"`
+		} `json:"orders"`
+	}
+	if err := b.do(http.MethodGet, "/iserver/account/orders", nil, &live); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch orders: %v", err))
+		return b.orders.Orders()
+	}
+
+	for _, o := range live.Orders {
+		order, ok := b.orders.Local(strconv.Itoa(o.OrderID))
+		if !ok {
+			continue
+		}
+		switch o.Status {
+		case "Filled":
+			b.orders.Finish(order.ID(), gbt.OrderFilled)
+		case "Cancelled", "ApiCancelled", "Inactive":
+			b.orders.Finish(order.ID(), gbt.OrderCanceled)
+		}
+	}
+	return b.orders.Orders()
+}
+
+// Positions returns the positions of the account by symbol.
+func (b *Broker) Positions() map[string]gbt.Position {
+	var held []struct {
+		Conid        int     `json:"conid"`
+		ContractDesc string  `json:"contractDesc"`
+		Position     float64 `json:"position"`
+		MktPrice     float64 `json:"mktPrice"`
+		AvgPrice     float64 `json:"avgPrice"`
+	}
+	positions := make(map[string]gbt.Position)
+	if err := b.do(http.MethodGet, "/portfolio/"+b.Account+"/positions/0", nil, &held); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch positions: %v", err))
+		return positions
+	}
+
+	now := time.Now()
+	for _, p := range held {
+		if p.Position == 0 {
+			continue
+		}
+		symbol := b.symbol(p.Conid, p.ContractDesc)
+		positions[symbol] = gbt.NewPosition(now, symbol, p.Position, p.AvgPrice, p.MktPrice)
+	}
+	return positions
+}
+
+// Cash returns the total cash of the account.
+func (b *Broker) Cash() float64 {
+	return b.summary("totalcashvalue")
+}
+
+// Value returns the net liquidation value of the account.
+func (b *Broker) Value() float64 {
+	return b.summary("netliquidation")
+}
+
+// summary returns a value of the account summary.
+func (b *Broker) summary(key string) float64 {
+	var summary map[string]struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := b.do(http.MethodGet, "/portfolio/"+b.Account+"/summary", nil, &summary); err != nil {
+		orders.Report(b.OnError, fmt.Errorf("could not fetch %s: %v", key, err))
+		return 0
+	}
+	return summary[key].Amount
+}
+
+// ticket creates the order request of an order.
+func (b *Broker) ticket(order *gbt.Order) (ticket, error) {
+	conid, err := b.conid(order.Symbol())
+	if err != nil {
+		return ticket{}, err
+	}
+
+	t := ticket{AcctID: b.Account, Conid: conid, Quantity: order.Qty(), TIF: b.TIF}
+	if t.TIF == "" {
+		t.TIF = "DAY"
+	}
+
+	switch order.Direction() {
+	case gbt.BOT:
+		t.Side = "BUY"
+	case gbt.SLD:
+		t.Side = "SELL"
+	default:
+		return ticket{}, fmt.Errorf("could not submit order for %s, direction %v not supported", order.Symbol(), order.Direction())
+	}
+
+	switch order.Type() {
+	case gbt.MarketOrder:
+		t.OrderType = "MKT"
+	case gbt.MarketOnOpenOrder:
+		t.OrderType = "MKT"
+		t.TIF = "OPG"
+	case gbt.MarketOnCloseOrder:
+		t.OrderType = "MOC"
+	case gbt.LimitOrder:
+		t.OrderType = "LMT"
+		t.Price = order.Limit()
+	case gbt.StopMarketOrder:
+		t.OrderType = "STP"
+		t.Price = order.Stop()
+	case gbt.StopLimitOrder:
+		t.OrderType = "STOP_LIMIT"
+		t.Price = order.Limit()
+		t.AuxPrice = order.Stop()
+	default:
+		return ticket{}, fmt.Errorf("could not submit order for %s, type %v not supported", order.Symbol(), order.Type())
+	}
+	return t, nil
+}
+
+// confirm answers the warnings of the gateway, until the order is placed.
+func (b *Broker) confirm(replies []reply) (reply, error) {
+	// the gateway asks at most a few questions per order
+	for i := 0; i < 5; i++ {
+		if len(replies) == 0 {
+			return reply{}, errNoReply
+		}
+		r := replies[0]
+		if r.OrderID != "" {
+			return r, nil
+		}
+		if !b.Confirm {
+			return reply{}, fmt.Errorf("confirmation required: %s", strings.Join(r.Message, " "))
+		}
+
+		replies = nil
+		if err := b.do(http.MethodPost, "/iserver/reply/"+r.ID, map[string]bool{"confirmed": true}, &replies); err != nil {
+			return reply{}, err
+		}
+	}
+	return reply{}, errors.New("too many confirmations")
+}
@@ -0,0 +1,154 @@
+package ib
+
+import (
+	"errors"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// the broker implements the broker interface of the engine
+var _ gbt.Broker = &Broker{}
+
+// newOrder creates an order of the engine.
+func newOrder(symbol string, orderType gbt.OrderType, direction gbt.Direction, qty, limit float64) *gbt.Order {
+	order := &gbt.Order{}
+	order.SetSymbol(symbol)
+	order.SetType(orderType)
+	order.SetDirection(direction)
+	order.SetQty(qty)
+	order.SetLimit(limit)
+	return order
+}
+
+func TestBrokerOrders(t *testing.T) {
+	g, ts := newGateway()
+	defer ts.Close()
+	g.reply("POST /iserver/account/DU1/orders", `[{"id":"q1","message":["price exceeds the percentage constraint"]}]`)
+	g.reply("POST /iserver/reply/q1", `[{"order_id":"1001","order_status":"Submitted"}]`)
+	g.reply("POST /iserver/account/DU1/order/1001", `[{"order_id":"1001","order_status":"Submitted"}]`)
+	g.reply("DELETE /iserver/account/DU1/order/1001", `{"msg":"Request was submitted","order_id":1001}`)
+
+	b := NewBroker(NewClient(ts.URL+"/v1/api", map[string]int{"AAPL": 265598}), "DU1")
+
+	// the warning of the gateway is not confirmed by default
+	order := newOrder("AAPL", gbt.LimitOrder, gbt.BOT, 10, 150)
+	if err := b.Submit(order); err == nil {
+		t.Fatalf("Submit(): expected an error for an unconfirmed warning")
+	}
+
+	b.Confirm = true
+	if err := b.Submit(order); err != nil {
+		t.Fatalf("Submit(): unexpected error %v", err)
+	}
+	ticket := g.body("POST /iserver/account/DU1/orders")["orders"].([]interface{})[0].(map[string]interface{})
+	if ticket["conid"] != 265598.0 || ticket["orderType"] != "LMT" || ticket["price"] != 150.0 || ticket["side"] != "BUY" || ticket["tif"] != "DAY" || ticket["cOID"] != "1" {
+		t.Errorf("Submit(): unexpected order request %v", ticket)
+	}
+	if order.ID() != 1 || order.Status() != gbt.OrderSubmitted || order.Time().IsZero() {
+		t.Errorf("Submit(): expected a submitted order with id 1, actual %+v", order)
+	}
+
+	// the order rests at the gateway
+	g.reply("GET /iserver/account/orders", `{"orders":[{"orderId":1001,"status":"Submitted"},{"orderId":999,"status":"Filled"}]}`)
+	if orders := b.Orders(); len(orders) != 1 || orders[0] != gbt.OrderEvent(order) {
+		t.Errorf("Orders(): expected the resting order, actual %v", orders)
+	}
+
+	if err := b.Modify(1, gbt.OrderChange{Qty: 20, Limit: 145}); err != nil {
+		t.Fatalf("Modify(): unexpected error %v", err)
+	}
+	modified := g.body("POST /iserver/account/DU1/order/1001")
+	if modified["quantity"] != 20.0 || modified["price"] != 145.0 || order.Qty() != 20 || order.Limit() != 145 {
+		t.Errorf("Modify(): expected qty 20 at 145, actual request %v and order %+v", modified, order)
+	}
+
+	if err := b.Cancel(1); err != nil {
+		t.Fatalf("Cancel(): unexpected error %v", err)
+	}
+	if order.Status() != gbt.OrderCanceled || len(b.Orders()) != 0 {
+		t.Errorf("Cancel(): expected a canceled order, actual %v", order.Status())
+	}
+	if err := b.Cancel(1); err == nil {
+		t.Errorf("Cancel(): expected an error for an unknown order")
+	}
+
+	// a filled order is removed from the resting orders
+	g.reply("POST /iserver/account/DU1/orders", `[{"order_id":"1002","order_status":"Submitted"}]`)
+	filled := newOrder("AAPL", gbt.MarketOrder, gbt.SLD, 5, 0)
+	if err := b.Submit(filled); err != nil || filled.ID() != 2 {
+		t.Fatalf("Submit(): expected order 2, actual %v %v", filled.ID(), err)
+	}
+	g.reply("GET /iserver/account/orders", `{"orders":[{"orderId":1002,"status":"Filled"}]}`)
+	if orders := b.Orders(); len(orders) != 0 || filled.Status() != gbt.OrderFilled {
+		t.Errorf("Orders(): expected the filled order to be removed, actual %v", orders)
+	}
+}
+
+func TestBrokerTicket(t *testing.T) {
+	b := NewBroker(NewClient("", map[string]int{"AAPL": 1}), "DU1")
+
+	// testCases is a table for testing the order requests of the engine orders
+	var testCases = []struct {
+		msg       string
+		order     *gbt.Order
+		expType   string
+		expTIF    string
+		expPrices [2]float64
+		expErr    bool
+	}{
+		{"market order:", newOrder("AAPL", gbt.MarketOrder, gbt.BOT, 1, 0), "MKT", "DAY", [2]float64{}, false},
+		{"market on open:", newOrder("AAPL", gbt.MarketOnOpenOrder, gbt.BOT, 1, 0), "MKT", "OPG", [2]float64{}, false},
+		{"market on close:", newOrder("AAPL", gbt.MarketOnCloseOrder, gbt.SLD, 1, 0), "MOC", "DAY", [2]float64{}, false},
+		{"stop limit:", func() *gbt.Order {
+			o := newOrder("AAPL", gbt.StopLimitOrder, gbt.SLD, 1, 95)
+			o.SetStop(96)
+			return o
+		}(), "STOP_LIMIT", "DAY", [2]float64{95, 96}, false},
+		{"unknown symbol:", newOrder("MSFT", gbt.MarketOrder, gbt.BOT, 1, 0), "", "", [2]float64{}, true},
+		{"exit direction:", newOrder("AAPL", gbt.MarketOrder, gbt.EXT, 1, 0), "", "", [2]float64{}, true},
+	}
+
+	for _, tc := range testCases {
+		ticket, err := b.ticket(tc.order)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v ticket(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if ticket.OrderType != tc.expType || ticket.TIF != tc.expTIF || [2]float64{ticket.Price, ticket.AuxPrice} != tc.expPrices {
+			t.Errorf("%v ticket(): expected %v %v at %v, actual %+v", tc.msg, tc.expType, tc.expTIF, tc.expPrices, ticket)
+		}
+	}
+}
+
+func TestBrokerAccount(t *testing.T) {
+	g, ts := newGateway()
+	defer ts.Close()
+	g.reply("GET /portfolio/DU1/positions/0", `[
+		{"conid":265598,"contractDesc":"AAPL","position":10,"mktPrice":155,"avgPrice":150},
+		{"conid":8314,"contractDesc":"IBM","position":-5,"mktPrice":140,"avgPrice":145},
+		{"conid":4815,"contractDesc":"MSFT","position":0,"mktPrice":300,"avgPrice":0}
+	]`)
+	g.reply("GET /portfolio/DU1/summary", `{"totalcashvalue":{"amount":10000},"netliquidation":{"amount":11550}}`)
+
+	var errs []error
+	b := NewBroker(NewClient(ts.URL, map[string]int{"APPLE": 265598}), "DU1")
+	b.OnError = func(err error) { errs = append(errs, err) }
+
+	positions := b.Positions()
+	if len(positions) != 2 || positions["APPLE"].Qty() != 10 || positions["APPLE"].MarketPrice() != 155 || positions["IBM"].Qty() != -5 {
+		t.Errorf("Positions(): expected the open positions by symbol, actual %+v", positions)
+	}
+	if b.Cash() != 10000 || b.Value() != 11550 {
+		t.Errorf("Cash(): expected cash 10000 and value 11550, actual %v %v", b.Cash(), b.Value())
+	}
+
+	// errors of requests without error result are handed to OnError
+	b.Account = "DU2"
+	if len(b.Positions()) != 0 || b.Cash() != 0 || len(b.Orders()) != 0 || len(errs) != 3 {
+		t.Errorf("OnError: expected 3 errors, actual %v", errs)
+	}
+	if err := b.Submit(newOrder("APPLE", gbt.MarketOrder, gbt.BOT, 0, 0)); err == nil || errors.Unwrap(err) != nil {
+		t.Errorf("Submit(): expected an error for an order without qty, actual %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package ib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// snapshot fields of the gateway: bid, ask size, ask, bid size
+const snapshotFields = "84,85,86,88"
+
+// Feed polls the quotes of the contracts of the client from the snapshot endpoint of the
+// Client Portal API and publishes them as ticks, it does not stream the quotes like TWS.
+type Feed struct {
+	*Client
+	Interval time.Duration // between the polls, defaults to 1 second
+	// OnError receives the errors of the polls, a failed poll is retried with the next one
+	OnError func(error)
+}
+
+// NewFeed creates a feed of the contracts of a client.
+func NewFeed(c *Client) *Feed {
+	return &Feed{Client: c}
+}
+
+// Run publishes a tick for every changed quote into the live data, until the context
+// is cancelled. The live data is closed on return, so the backtest finishes.
+func (f *Feed) Run(ctx context.Context, data *gbt.LiveData) error {
+	defer data.Close()
+
+	interval := f.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	// last published bid and ask by symbol
+	last := make(map[string][2]float64)
+	for {
+		ticks, err := f.poll()
+		if err != nil && f.OnError != nil {
+			f.OnError(err)
+		}
+		for _, tick := range ticks {
+			quote := [2]float64{tick.Bid, tick.Ask}
+			if prev, ok := last[tick.Symbol()]; ok && prev == quote {
+				continue
+			}
+			last[tick.Symbol()] = quote
+			if !data.Publish(tick) {
+				return nil
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll fetches a snapshot of the quotes. The gateway answers the first request of a contract
+// without quotes, those contracts are skipped.
+func (f *Feed) poll() ([]*gbt.Tick, error) {
+	conids := make([]string, 0, len(f.Contracts))
+	for _, conid := range f.Contracts {
+		conids = append(conids, strconv.Itoa(conid))
+	}
+	sort.Strings(conids)
+
+	var snapshot []map[string]json.RawMessage
+	path := "/iserver/marketdata/snapshot?conids=" + strings.Join(conids, ",") + "&fields=" + snapshotFields
+	if err := f.do(http.MethodGet, path, nil, &snapshot); err != nil {
+		return nil, fmt.Errorf("could not poll quotes: %v", err)
+	}
+
+	var ticks []*gbt.Tick
+	for _, quote := range snapshot {
+		var conid int
+		if err := json.Unmarshal(quote["conid"], &conid); err != nil {
+			continue
+		}
+		bid, okBid := field(quote["84"])
+		ask, okAsk := field(quote["86"])
+		if !okBid || !okAsk {
+			continue
+		}
+
+		tick := &gbt.Tick{Bid: bid, Ask: ask}
+		tick.BidVolume, _ = field(quote["88"])
+		tick.AskVolume, _ = field(quote["85"])
+		tick.SetSymbol(f.symbol(conid, strconv.Itoa(conid)))
+
+		var updated int64
+		if json.Unmarshal(quote["_updated"], &updated) == nil && updated > 0 {
+			tick.SetTime(time.Unix(0, updated*int64(time.Millisecond)))
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks, nil
+}
+
+// field parses a snapshot field. The gateway sends the values as strings with thousands separators,
+// prefixed with C for the prior close and H for a halted contract.
+func field(raw json.RawMessage) (float64, bool) {
+	var s string
+	if len(raw) == 0 || json.Unmarshal(raw, &s) != nil {
+		return 0, false
+	}
+	s = strings.TrimLeft(strings.ReplaceAll(s, ",", ""), "CH")
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
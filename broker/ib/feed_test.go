@@ -0,0 +1,70 @@
+package ib
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestFeedRun(t *testing.T) {
+	g, ts := newGateway()
+	defer ts.Close()
+	// the first request of a contract has no quotes
+	g.reply("GET /iserver/marketdata/snapshot", `[{"conid":265598}]`)
+
+	feed := gbt.NewLiveData(10)
+	f := NewFeed(NewClient(ts.URL, map[string]int{"AAPL": 265598}))
+	f.Interval = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- f.Run(ctx, feed) }()
+
+	time.Sleep(20 * time.Millisecond)
+	g.reply("GET /iserver/marketdata/snapshot", `[{"conid":265598,"84":"1,150.10","86":"1,150.30","88":"300","85":"C200","_updated":1496318400000}]`)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run(): expected the cancelled context, actual %v", err)
+	}
+
+	// the unchanged quote is published once and the feed is closed
+	var ticks []*gbt.Tick
+	for e, ok := feed.Next(); ok; e, ok = feed.Next() {
+		ticks = append(ticks, e.(*gbt.Tick))
+	}
+	if len(ticks) != 1 {
+		t.Fatalf("Run(): expected a single tick, actual %v", len(ticks))
+	}
+	tick := ticks[0]
+	if tick.Symbol() != "AAPL" || tick.Bid != 1150.1 || tick.Ask != 1150.3 || tick.BidVolume != 300 || tick.AskVolume != 200 || !tick.Time().Equal(time.Unix(1496318400, 0)) {
+		t.Errorf("Run(): unexpected tick %+v at %v", tick, tick.Time())
+	}
+}
+
+func TestField(t *testing.T) {
+	// testCases is a table for testing the parsing of snapshot fields
+	var testCases = []struct {
+		raw    string
+		expVal float64
+		expOk  bool
+	}{
+		{`"150.25"`, 150.25, true},
+		{`"1,300"`, 1300, true},
+		{`"C148.5"`, 148.5, true},
+		{`"H148.5"`, 148.5, true},
+		{`""`, 0, false},
+		{`150`, 0, false},
+		{``, 0, false},
+	}
+
+	for _, tc := range testCases {
+		val, ok := field(json.RawMessage(tc.raw))
+		if val != tc.expVal || ok != tc.expOk {
+			t.Errorf("field(%v): expected %v %v, actual %v %v", tc.raw, tc.expVal, tc.expOk, val, ok)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package ib connects strategies to Interactive Brokers through the Client Portal API
+// of the IB Gateway, so a strategy validated in a backtest trades live with the same code.
+//
+// The Broker implements gobacktest.Broker, it is handed to a strategy with SetBroker of the
+// backtest. The Feed polls the quotes of the contracts and publishes them into the live data
+// of a paper trading or live backtest:
+//
+//	client := ib.NewClient("https://localhost:5000/v1/api", map[string]int{"AAPL": 265598})
+//	feed := gbt.NewLiveData(100)
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetBroker(ib.NewBroker(client, "DU123456"))
+//	go ib.NewFeed(client).Run(ctx, feed)
+//	err := test.RunContext(ctx)
+//
+// The gateway has to be running and authenticated, the session is kept alive by the gateway.
+//
+// The package is a Client Portal adapter: it talks to the REST API of the gateway, not the socket
+// API of TWS, so there is no streaming market data. The Feed polls the snapshot endpoint every
+// Interval, ticks between two polls are not seen, and a quote is published with the delay of the
+// poll. Strategies which need every tick have to use a TWS client instead.
+package ib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client is a client of the Client Portal API of the IB Gateway.
+type Client struct {
+	URL string // base url of the api, defaults to https://localhost:5000/v1/api
+	// Contracts maps the symbols of the strategy to the contract ids (conid) of IB
+	Contracts map[string]int
+	// HTTP defaults to http.DefaultClient, the gateway serves a self-signed certificate by default
+	HTTP    *http.Client
+	Timeout time.Duration // timeout of a request, defaults to 10 seconds
+}
+
+// NewClient creates a client of the gateway at url.
+func NewClient(url string, contracts map[string]int) *Client {
+	return &Client{URL: url, Contracts: contracts}
+}
+
+// conid returns the contract id of a symbol.
+func (c *Client) conid(symbol string) (int, error) {
+	conid, ok := c.Contracts[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no contract for symbol %s", symbol)
+	}
+	return conid, nil
+}
+
+// symbol returns the symbol of a contract id, or the fallback for an unknown contract.
+func (c *Client) symbol(conid int, fallback string) string {
+	for symbol, id := range c.Contracts {
+		if id == conid {
+			return symbol
+		}
+	}
+	return fallback
+}
+
+// do sends a request with an optional json body and decodes the json response into v.
+func (c *Client) do(method, path string, body, v interface{}) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var r io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(content)
+	}
+
+	base := c.URL
+	if base == "" {
+		base = "https://localhost:5000/v1/api"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(content, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s %s: %s", method, path, e.Error)
+		}
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("%s %s: %v", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package ib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gateway is a fake of the Client Portal API of the IB Gateway.
+type gateway struct {
+	mu       sync.Mutex
+	requests []string          // method and path of the requests
+	bodies   map[string]string // last body by method and path
+	replies  map[string]string // response by method and path
+}
+
+func newGateway() (*gateway, *httptest.Server) {
+	g := &gateway{bodies: make(map[string]string), replies: make(map[string]string)}
+	return g, httptest.NewServer(g)
+}
+
+func (g *gateway) reply(route, body string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.replies[route] = body
+}
+
+func (g *gateway) body(route string) map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var v map[string]interface{}
+	json.Unmarshal([]byte(g.bodies[route]), &v)
+	return v
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	route := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/v1/api")
+	body, _ := ioutil.ReadAll(r.Body)
+	g.requests = append(g.requests, route)
+	g.bodies[route] = string(body)
+
+	reply, ok := g.replies[route]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"unknown route"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(reply))
+}
+
+func TestClientDo(t *testing.T) {
+	g, ts := newGateway()
+	defer ts.Close()
+	g.reply("GET /ok", `{"value":1}`)
+	g.reply("GET /invalid", `{"value":`)
+
+	c := NewClient(ts.URL+"/v1/api/", nil)
+
+	// testCases is a table for testing the requests of the client
+	var testCases = []struct {
+		msg    string
+		path   string
+		expErr string
+	}{
+		{"valid response:", "/ok", ""},
+		{"error of the gateway:", "/missing", "GET /missing: unknown route"},
+		{"invalid json:", "/invalid", "GET /invalid: unexpected end of JSON input"},
+	}
+
+	for _, tc := range testCases {
+		var v struct{ Value int }
+		err := c.do(http.MethodGet, tc.path, nil, &v)
+		if (tc.expErr == "" && (err != nil || v.Value != 1)) || (tc.expErr != "" && (err == nil || err.Error() != tc.expErr)) {
+			t.Errorf("%v do(): expected error %q, actual %v %+v", tc.msg, tc.expErr, err, v)
+		}
+	}
+
+	if _, err := c.conid("AAPL"); err == nil {
+		t.Errorf("conid(): expected an error for an unknown symbol")
+	}
+}
@@ -0,0 +1,117 @@
+// Package orders tracks the orders of the engine submitted to a live broker,
+// which identifies them by its own order ids.
+package orders
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// Tracker maps the order ids of the engine to the order ids of a broker. The orders are tracked
+// by the id of the engine, Assign gives an order without id the next free id. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu     sync.Mutex
+	orders map[int]*gbt.Order
+	ids    map[int]string // order id of the broker by order id of the engine
+	nextID int
+}
+
+// Assign gives an order without id the next free id.
+func (t *Tracker) Assign(order *gbt.Order) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if order.ID() == 0 {
+		order.SetID(t.nextID + 1)
+	}
+	if order.ID() > t.nextID {
+		t.nextID = order.ID()
+	}
+}
+
+// Add tracks an order placed at the broker with the order id of the broker.
+func (t *Tracker) Add(order *gbt.Order, remote string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// check for nil map, else initialise the map
+	if t.orders == nil {
+		t.orders = make(map[int]*gbt.Order)
+		t.ids = make(map[int]string)
+	}
+	t.orders[order.ID()] = order
+	t.ids[order.ID()] = remote
+}
+
+// Lookup returns a tracked order and its order id of the broker.
+func (t *Tracker) Lookup(id int) (*gbt.Order, string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	order, ok := t.orders[id]
+	if !ok {
+		return nil, "", fmt.Errorf("order %v not found", id)
+	}
+	return order, t.ids[id], nil
+}
+
+// Local returns the tracked order of an order id of the broker.
+func (t *Tracker) Local(remote string) (*gbt.Order, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, r := range t.ids {
+		if r == remote {
+			return t.orders[id], true
+		}
+	}
+	return nil, false
+}
+
+// Finish sets the final status of an order and stops tracking it.
+func (t *Tracker) Finish(id int, status gbt.OrderStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if order, ok := t.orders[id]; ok {
+		order.SetStatus(status)
+	}
+	delete(t.orders, id)
+	delete(t.ids, id)
+}
+
+// Update changes a tracked order under the lock of the tracker.
+func (t *Tracker) Update(id int, change func(*gbt.Order)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if order, ok := t.orders[id]; ok {
+		change(order)
+	}
+}
+
+// Orders returns the tracked orders sorted by id.
+func (t *Tracker) Orders() []gbt.OrderEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	orders := make([]gbt.OrderEvent, 0, len(t.orders))
+	for _, order := range t.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID() < orders[j].ID() })
+	return orders
+}
+
+// Report hands an error to the error handler of a broker, if both are set. The methods Orders,
+// Positions, Cash and Value of gobacktest.Broker have no error result, a broker reports the
+// errors of their requests instead.
+func Report(handler func(error), err error) {
+	if handler != nil && err != nil {
+		handler(err)
+	}
+}
@@ -0,0 +1,59 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestTracker(t *testing.T) {
+	var tracker Tracker
+
+	// orders without id get the next free id after the highest known id
+	first, second, third := &gbt.Order{}, &gbt.Order{}, &gbt.Order{}
+	second.SetID(5)
+	for i, order := range []*gbt.Order{first, second, third} {
+		tracker.Assign(order)
+		tracker.Add(order, []string{"a", "b", "c"}[i])
+	}
+	if first.ID() != 1 || second.ID() != 5 || third.ID() != 6 {
+		t.Errorf("Assign(): expected ids 1, 5 and 6, actual %v %v %v", first.ID(), second.ID(), third.ID())
+	}
+
+	if order, remote, err := tracker.Lookup(5); err != nil || order != second || remote != "b" {
+		t.Errorf("Lookup(): expected order 5 as b, actual %v %v %v", order, remote, err)
+	}
+	if _, _, err := tracker.Lookup(2); err == nil {
+		t.Errorf("Lookup(): expected an error for an unknown order")
+	}
+	if order, ok := tracker.Local("c"); !ok || order != third {
+		t.Errorf("Local(): expected order 6, actual %v %v", order, ok)
+	}
+
+	// a replaced order keeps its id with the new order id of the broker
+	tracker.Add(third, "d")
+	if _, ok := tracker.Local("c"); ok {
+		t.Errorf("Add(): expected the replaced order id to be unknown")
+	}
+
+	tracker.Update(1, func(o *gbt.Order) { o.SetQty(10) })
+	tracker.Finish(5, gbt.OrderFilled)
+	orders := tracker.Orders()
+	if len(orders) != 2 || orders[0].ID() != 1 || orders[1].ID() != 6 || first.Qty() != 10 || second.Status() != gbt.OrderFilled {
+		t.Errorf("Orders(): expected the orders 1 and 6, actual %v", orders)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var received []error
+	handler := func(err error) { received = append(received, err) }
+
+	// a nil handler or a nil error is dropped
+	Report(nil, errors.New("dropped"))
+	Report(handler, nil)
+	Report(handler, errors.New("failed"))
+	if len(received) != 1 || received[0].Error() != "failed" {
+		t.Errorf("Report(): expected the error failed, actual %v", received)
+	}
+}
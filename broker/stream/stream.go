@@ -0,0 +1,354 @@
+// Package stream implements a websocket consumer of live market data, the base of the live
+// data adapters.
+//
+// A Venue maps the protocol of a venue: the url of its stream, the subscription after each
+// connect and the mapping of its messages into data events like Tick and Bar. The Consumer
+// keeps the connection alive with pings, reconnects with an exponential backoff and recovers
+// the data events missed while disconnected from a venue implementing Recoverer.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/internal/websocket"
+)
+
+// Conn is the connection handed to a venue to authenticate and subscribe.
+type Conn interface {
+	WriteMessage(p []byte) error
+	WriteJSON(v interface{}) error
+}
+
+// Venue maps the protocol of the market data stream of a venue.
+type Venue interface {
+	// URL returns the url of the stream.
+	URL() string
+	// Subscribe is called after each connect, e.g. to authenticate and subscribe.
+	Subscribe(conn Conn) error
+	// Parse maps a message to data events, none for a message without market data.
+	Parse(msg []byte) ([]gbt.DataEvent, error)
+}
+
+// Recoverer is implemented by a venue, which fetches the data events since a time, e.g. the
+// bars from a rest api. After a reconnect the data events missed are recovered through it.
+type Recoverer interface {
+	Recover(since time.Time) ([]gbt.DataEvent, error)
+}
+
+// permanentError marks an error, which ends the run without reconnect.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error of a venue as permanent, e.g. a failed authentication.
+// A permanent error ends the run of the consumer instead of a reconnect.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Options are the options of a consumer.
+type Options struct {
+	Header http.Header // sent with the connect
+	// Heartbeat sets the interval of the pings, defaults to 15 seconds, negative for no pings
+	Heartbeat time.Duration
+	// Timeout sets the wait for the next message or pong until a reconnect,
+	// defaults to twice the heartbeat
+	Timeout time.Duration
+	// MinBackoff and MaxBackoff limit the wait before a reconnect, which doubles with every
+	// failed attempt, defaults to 500 milliseconds and 30 seconds
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRetries limits the failed attempts in a row, 0 for no limit
+	MaxRetries int
+	// OnError receives the errors leading to a reconnect and the failed recoveries
+	OnError func(error)
+}
+
+// Consumer consumes the market data stream of a venue and publishes the data events into
+// a live data handler.
+//
+// A data event older than the last published data event of the same symbol and type is
+// dropped, a recovered data event has to be newer. So the data events overlapping on a
+// reconnect are published once.
+type Consumer struct {
+	Venue Venue
+	Options
+
+	mu         sync.Mutex
+	last       map[string]time.Time // time of the last data event by type and symbol
+	reconnects int
+	recovered  int
+}
+
+// NewConsumer creates a consumer of the stream of a venue.
+func NewConsumer(venue Venue, options Options) *Consumer {
+	return &Consumer{Venue: venue, Options: options}
+}
+
+// Reconnects returns the number of reconnects.
+func (c *Consumer) Reconnects() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reconnects
+}
+
+// Recovered returns the number of data events published by the recoveries.
+func (c *Consumer) Recovered() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recovered
+}
+
+// Run publishes the data events of the stream into the live data, until the context is
+// cancelled, the live data is closed or the stream fails permanently. The live data is
+// closed on return, so the backtest finishes.
+func (c *Consumer) Run(ctx context.Context, data *gbt.LiveData) error {
+	defer data.Close()
+	if c.Venue == nil {
+		return errors.New("no venue to consume")
+	}
+
+	// a closed live data ends the run
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-data.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var failures int
+	for attempt := 0; ; attempt++ {
+		received, err := c.session(ctx, data, attempt > 0)
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		select {
+		case <-data.Done():
+			return nil
+		default:
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if received {
+			failures = 0
+		}
+		failures++
+		if c.MaxRetries > 0 && failures > c.MaxRetries {
+			return fmt.Errorf("could not reconnect after %d retries: %v", c.MaxRetries, err)
+		}
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+
+		select {
+		case <-time.After(c.backoff(failures)):
+		case <-ctx.Done():
+			if parent.Err() != nil {
+				return parent.Err()
+			}
+			return nil
+		}
+		c.mu.Lock()
+		c.reconnects++
+		c.mu.Unlock()
+	}
+}
+
+// session connects, subscribes and publishes the data events until the stream fails.
+// It returns if a message was received.
+func (c *Consumer) session(ctx context.Context, data *gbt.LiveData, reconnect bool) (bool, error) {
+	conn, err := websocket.Dial(ctx, c.Venue.URL(), c.Header)
+	if err != nil {
+		return false, fmt.Errorf("could not connect to the stream: %v", err)
+	}
+	defer conn.Close()
+
+	// a cancelled context interrupts the blocking read
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	heartbeat, timeout := c.heartbeat()
+	extend := func() {
+		if timeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(timeout))
+		}
+	}
+	conn.OnPong = func([]byte) { extend() }
+	if heartbeat > 0 {
+		go func() {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if conn.Ping(nil) != nil {
+						return
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	if err := c.Venue.Subscribe(conn); err != nil {
+		return false, fmt.Errorf("could not subscribe: %w", err)
+	}
+	if reconnect {
+		if !c.recover(data) {
+			return false, nil
+		}
+	}
+
+	var received bool
+	for {
+		extend()
+		msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return received, ctx.Err()
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return received, fmt.Errorf("no message from the stream for %v", timeout)
+			}
+			return received, fmt.Errorf("could not read the stream: %v", err)
+		}
+		received = true
+
+		events, err := c.Venue.Parse(msg)
+		if err != nil {
+			return received, err
+		}
+		if !c.publish(data, events, false) {
+			return received, nil
+		}
+	}
+}
+
+// recover publishes the data events missed since the earliest last data event,
+// it returns false if the live data is closed.
+func (c *Consumer) recover(data *gbt.LiveData) bool {
+	r, ok := c.Venue.(Recoverer)
+	if !ok {
+		return true
+	}
+	var since time.Time
+	c.mu.Lock()
+	for _, t := range c.last {
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	c.mu.Unlock()
+	if since.IsZero() {
+		return true
+	}
+
+	events, err := r.Recover(since)
+	if err != nil {
+		if c.OnError != nil {
+			c.OnError(fmt.Errorf("could not recover the data events since %v: %v", since, err))
+		}
+		return true
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time().Before(events[j].Time())
+	})
+	return c.publish(data, events, true)
+}
+
+// publish publishes the data events, which are not older than the last data event of their
+// type and symbol. It returns false if the live data is closed.
+func (c *Consumer) publish(data *gbt.LiveData, events []gbt.DataEvent, recovered bool) bool {
+	for _, e := range events {
+		key := fmt.Sprintf("%T %s", e, e.Symbol())
+
+		c.mu.Lock()
+		last, seen := c.last[key]
+		c.mu.Unlock()
+		if t := e.Time(); seen && !t.IsZero() && (t.Before(last) || recovered && !t.After(last)) {
+			continue
+		}
+
+		if !data.Publish(e) {
+			return false
+		}
+
+		c.mu.Lock()
+		// check for nil map, else initialise the map
+		if c.last == nil {
+			c.last = make(map[string]time.Time)
+		}
+		c.last[key] = e.Time()
+		if recovered {
+			c.recovered++
+		}
+		c.mu.Unlock()
+	}
+	return true
+}
+
+// heartbeat returns the interval of the pings and the read timeout.
+func (c *Consumer) heartbeat() (time.Duration, time.Duration) {
+	heartbeat := c.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = 15 * time.Second
+	}
+	timeout := c.Timeout
+	if timeout == 0 && heartbeat > 0 {
+		timeout = 2 * heartbeat
+	}
+	return heartbeat, timeout
+}
+
+// backoff returns the wait before the next attempt after a number of failed attempts.
+func (c *Consumer) backoff(failures int) time.Duration {
+	min, max := c.MinBackoff, c.MaxBackoff
+	if min <= 0 {
+		min = 500 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	wait := min
+	for i := 1; i < failures && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
@@ -0,0 +1,274 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/internal/websocket"
+)
+
+// newServer starts a fake stream, which sends the messages of a session on each connect.
+// The connection is dropped after the messages, except after those of the last session.
+func newServer(t *testing.T, sessions ...[]string) *httptest.Server {
+	var mu sync.Mutex
+	var n int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		mu.Lock()
+		i := n
+		n++
+		mu.Unlock()
+		if i >= len(sessions) {
+			return
+		}
+
+		// the subscription
+		if _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		for _, m := range sessions[i] {
+			conn.WriteMessage([]byte(m))
+		}
+		if i == len(sessions)-1 {
+			conn.ReadMessage()
+		}
+	}))
+}
+
+// fakeVenue maps messages like {"s":"TEST","t":1,"c":10} to bars at t seconds, a message
+// with an error to a permanent error. It recovers the bars of the recovery since a time.
+type fakeVenue struct {
+	url      string
+	recovery []int64
+
+	mu         sync.Mutex
+	subscribes int
+	since      []time.Time
+}
+
+func (v *fakeVenue) URL() string {
+	return v.url
+}
+
+func (v *fakeVenue) Subscribe(conn Conn) error {
+	v.mu.Lock()
+	v.subscribes++
+	v.mu.Unlock()
+	return conn.WriteJSON(map[string]string{"action": "subscribe"})
+}
+
+func (v *fakeVenue) Parse(msg []byte) ([]gbt.DataEvent, error) {
+	var m struct {
+		Symbol string  `json:"s"`
+		Time   int64   `json:"t"`
+		Close  float64 `json:"c"`
+		Error  string  `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	if m.Error != "" {
+		return nil, Permanent(errors.New(m.Error))
+	}
+	return []gbt.DataEvent{newBar(m.Symbol, m.Time, m.Close)}, nil
+}
+
+func (v *fakeVenue) Recover(since time.Time) ([]gbt.DataEvent, error) {
+	v.mu.Lock()
+	v.since = append(v.since, since)
+	v.mu.Unlock()
+
+	var events []gbt.DataEvent
+	// in reverse order, the consumer sorts them
+	for i := len(v.recovery) - 1; i >= 0; i-- {
+		events = append(events, newBar("TEST", v.recovery[i], 0))
+	}
+	return events, nil
+}
+
+func newBar(symbol string, t int64, price float64) *gbt.Bar {
+	bar := &gbt.Bar{Close: price}
+	bar.SetSymbol(symbol)
+	bar.SetTime(time.Unix(t, 0))
+	return bar
+}
+
+func bar(t int64) string {
+	return fmt.Sprintf(`{"s":"TEST","t":%d,"c":10}`, t)
+}
+
+func wsURL(ts *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+// collect runs the consumer until n data events are received and returns their times.
+func collect(t *testing.T, c *Consumer, n int) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	data := gbt.NewLiveData(10)
+	done := make(chan error)
+	go func() { done <- c.Run(ctx, data) }()
+
+	var times []int64
+	for e, ok := data.NextContext(ctx); ok; e, ok = data.NextContext(ctx) {
+		times = append(times, e.Time().Unix())
+		if len(times) == n {
+			data.Close()
+		}
+	}
+	return times, <-done
+}
+
+func TestConsumerReconnect(t *testing.T) {
+	ts := newServer(t,
+		[]string{bar(1), bar(2)},
+		[]string{bar(3), bar(5)},
+	)
+	defer ts.Close()
+
+	v := &fakeVenue{url: wsURL(ts), recovery: []int64{2, 3, 4}}
+	var errs []error
+	c := NewConsumer(v, Options{MinBackoff: time.Millisecond, OnError: func(err error) { errs = append(errs, err) }})
+
+	times, err := collect(t, c, 5)
+	if err != nil {
+		t.Errorf("Run(): expected no error for the closed live data, actual %v", err)
+	}
+	if fmt.Sprint(times) != "[1 2 3 4 5]" {
+		t.Errorf("Run(): expected the bars once in order, actual %v", times)
+	}
+	if c.Reconnects() != 1 || c.Recovered() != 2 {
+		t.Errorf("Run(): expected 1 reconnect with 2 recovered bars, actual %v %v", c.Reconnects(), c.Recovered())
+	}
+	if v.subscribes != 2 || len(v.since) != 1 || v.since[0].Unix() != 2 {
+		t.Errorf("Run(): expected 2 subscriptions and a recovery since 2, actual %v %v", v.subscribes, v.since)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "could not read the stream") {
+		t.Errorf("OnError: expected the dropped connection, actual %v", errs)
+	}
+}
+
+func TestConsumerErrors(t *testing.T) {
+	// a silent stream, which neither sends nor answers the pings
+	silent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}))
+	defer silent.Close()
+	permanent := newServer(t, []string{bar(1), `{"error":"auth failed"}`})
+	defer permanent.Close()
+	closed := newServer(t)
+	closed.Close()
+
+	// testCases is a table for testing the end of a run
+	var testCases = []struct {
+		msg     string
+		url     string
+		options Options
+		expErr  string
+	}{
+		{"permanent error:", wsURL(permanent), Options{}, "auth failed"},
+		{"heartbeat timeout:", wsURL(silent), Options{Heartbeat: 20 * time.Millisecond, MinBackoff: time.Millisecond, MaxRetries: 1},
+			"could not reconnect after 1 retries: no message from the stream for 40ms"},
+		{"no connection:", wsURL(closed), Options{MinBackoff: time.Millisecond, MaxRetries: 2}, "could not reconnect after 2 retries: could not connect"},
+		{"no venue:", "", Options{}, "no venue"},
+	}
+
+	for _, tc := range testCases {
+		c := NewConsumer(&fakeVenue{url: tc.url}, tc.options)
+		if tc.url == "" {
+			c.Venue = nil
+		}
+		data := gbt.NewLiveData(10)
+		err := c.Run(context.Background(), data)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expErr) {
+			t.Errorf("%v Run(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+		if data.Publish(&gbt.Tick{}) {
+			t.Errorf("%v Run(): expected the live data to be closed", tc.msg)
+		}
+	}
+}
+
+func TestConsumerCancel(t *testing.T) {
+	ts := newServer(t, []string{bar(1)})
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	data := gbt.NewLiveData(10)
+	done := make(chan error)
+	go func() { done <- NewConsumer(&fakeVenue{url: wsURL(ts)}, Options{}).Run(ctx, data) }()
+
+	if _, ok := data.NextContext(ctx); !ok {
+		t.Fatalf("Run(): expected a bar")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run(): expected the cancelled context, actual %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run(): expected the end of the run")
+	}
+}
+
+func TestConsumerPublish(t *testing.T) {
+	c := &Consumer{}
+	data := gbt.NewLiveData(10)
+
+	c.publish(data, []gbt.DataEvent{newBar("A", 2, 1), newBar("B", 1, 1), newBar("A", 1, 1), newBar("A", 2, 1), &gbt.Tick{}}, false)
+	c.publish(data, []gbt.DataEvent{newBar("A", 2, 1), newBar("A", 3, 1), newBar("B", 1, 1)}, true)
+
+	var events []string
+	for len(events) < 5 {
+		e, _ := data.Next()
+		events = append(events, fmt.Sprintf("%T %s %d", e, e.Symbol(), e.Time().Unix()))
+	}
+	if s := strings.Join(events[:3], ","); s != "*gobacktest.Bar A 2,*gobacktest.Bar B 1,*gobacktest.Bar A 2" || !strings.HasPrefix(events[3], "*gobacktest.Tick") {
+		t.Errorf("publish(): expected the older live bar to be dropped, actual %v", events)
+	}
+	if events[4] != "*gobacktest.Bar A 3" || c.Recovered() != 1 {
+		t.Errorf("publish(): expected only the newer recovered bar, actual %v", events[4:])
+	}
+}
+
+func TestConsumerBackoff(t *testing.T) {
+	// testCases is a table for testing the backoff
+	var testCases = []struct {
+		msg      string
+		options  Options
+		failures int
+		exp      time.Duration
+	}{
+		{"first attempt:", Options{}, 1, 500 * time.Millisecond},
+		{"doubled:", Options{}, 3, 2 * time.Second},
+		{"limited:", Options{}, 20, 30 * time.Second},
+		{"custom:", Options{MinBackoff: time.Second, MaxBackoff: 3 * time.Second}, 3, 3 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		c := NewConsumer(nil, tc.options)
+		if wait := c.backoff(tc.failures); wait != tc.exp {
+			t.Errorf("%v backoff(): expected %v, actual %v", tc.msg, tc.exp, wait)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package gobacktest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// brokerStrategy manages its own orders with the broker.
+type brokerStrategy struct {
+	*Strategy
+	broker Broker
+	day    int
+	errs   []error
+}
+
+func (s *brokerStrategy) SetBroker(b Broker) {
+	s.broker = b
+}
+
+func (s *brokerStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.day++
+	switch s.day {
+	case 1:
+		// two resting limit orders
+		for _, limit := range []float64{90, 80} {
+			order := &Order{Event: Event{symbol: "TEST.DE"}, orderType: LimitOrder, direction: BOT, qty: 10, limitPrice: limit}
+			s.errs = append(s.errs, s.broker.Submit(order))
+		}
+	case 2:
+		orders := s.broker.Orders()
+		if len(orders) != 2 {
+			s.errs = append(s.errs, fmt.Errorf("expected 2 resting orders, actual %v", len(orders)))
+			break
+		}
+		s.errs = append(s.errs, s.broker.Modify(orders[0].ID(), OrderChange{Qty: 20, Limit: 95}))
+		s.errs = append(s.errs, s.broker.Cancel(orders[1].ID()))
+	}
+	return nil, nil
+}
+
+// newBrokerStream creates daily bars with the close as low and high price.
+func newBrokerStream(closes ...float64) []DataEvent {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	var stream []DataEvent
+	for i, c := range closes {
+		stream = append(stream, &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: "TEST.DE"}, Low: c, High: c, Close: c})
+	}
+	return stream
+}
+
+func TestSimulatedBroker(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newBrokerStream(100, 100, 95, 90, 100))
+
+	exchange := NewExchange()
+	exchange.FillModel = &TouchFillModel{}
+
+	strategy := &brokerStrategy{Strategy: NewStrategy("broker")}
+	test := New()
+	test.SetData(data)
+	test.SetExchange(exchange)
+	test.SetStrategy(strategy)
+	audit := NewAuditLog()
+	test.SetAudit(audit)
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	for _, err := range strategy.errs {
+		if err != nil {
+			t.Errorf("broker: unexpected error %v", err)
+		}
+	}
+
+	// the modified order is filled at its new limit, the canceled order never
+	broker := test.Broker()
+	positions := broker.Positions()
+	if pos, ok := positions["TEST.DE"]; !ok || pos.qty != 20 || len(positions) != 1 {
+		t.Errorf("Positions(): expected 20 TEST.DE, actual %+v", positions)
+	}
+	if broker.Cash() != 100000-20*95 || broker.Value() != 100000-20*95+20*100 {
+		t.Errorf("Cash(): expected cash %v and value %v, actual %v %v", 100000-20*95, 100000+20*5, broker.Cash(), broker.Value())
+	}
+	if orders := broker.Orders(); len(orders) != 0 {
+		t.Errorf("Orders(): expected no resting orders, actual %v", orders)
+	}
+
+	var canceled, modified int
+	for _, e := range audit.Entries() {
+		switch e.Action {
+		case AuditOrderCanceled:
+			canceled++
+		case AuditOrderModified:
+			modified++
+		}
+	}
+	if canceled != 1 || modified != 1 {
+		t.Errorf("audit: expected a canceled and a modified order, actual %v %v", canceled, modified)
+	}
+}
+
+func TestSimulatedBrokerErrors(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newBrokerStream(100))
+
+	test := New()
+	test.SetData(data)
+	test.SetExchange(NewExchange())
+	broker := test.Broker()
+
+	// testCases is a table for testing the errors of the simulated broker
+	var testCases = []struct {
+		msg string
+		err error
+	}{
+		{"order without symbol:", broker.Submit(&Order{qty: 1})},
+		{"order without qty:", broker.Submit(&Order{Event: Event{symbol: "TEST.DE"}})},
+		{"order without data:", broker.Submit(&Order{Event: Event{symbol: "TEST.DE"}, qty: 1})},
+		{"cancel of an unknown order:", broker.Cancel(1)},
+		{"modify of an unknown order:", broker.Modify(1, OrderChange{Limit: 10})},
+	}
+
+	for _, tc := range testCases {
+		if tc.err == nil {
+			t.Errorf("%v expected an error", tc.msg)
+		}
+	}
+
+	// an exchange without cancel
+	test.SetExchange(NewRouter(&RoundRobinRouting{}))
+	if err := broker.Cancel(1); err == nil {
+		t.Errorf("Cancel(): expected an error for an exchange without cancel")
+	}
+}
+
+func TestBacktestSetBroker(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newBrokerStream(100))
+
+	other := New()
+	other.SetData(&Data{})
+	live := other.Broker()
+	strategy := &brokerStrategy{Strategy: NewStrategy("broker")}
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(strategy)
+	test.SetBroker(live)
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if strategy.broker != Broker(live) {
+		t.Errorf("SetBroker(): expected the strategy to receive the set broker, actual %v", strategy.broker)
+	}
+}
@@ -0,0 +1,116 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// CashFlow is an external cash flow into or out of the portfolio,
+// a positive amount is a deposit, a negative amount a withdrawal.
+type CashFlow struct {
+	Time   time.Time
+	Amount float64
+}
+
+// CashFlower returns the cash flows which were booked into a portfolio.
+type CashFlower interface {
+	CashFlows() []CashFlow
+}
+
+// MonthlyCashFlows creates a cash flow of the same amount on the day of the start date
+// in each month up to the end date, e.g. a monthly savings plan.
+func MonthlyCashFlows(start, end time.Time, amount float64) []CashFlow {
+	var flows []CashFlow
+	for t, i := start, 0; !t.After(end); t = start.AddDate(0, i, 0) {
+		flows = append(flows, CashFlow{Time: t, Amount: amount})
+		i++
+	}
+	return flows
+}
+
+// sortCashFlows sorts cash flows by time.
+func sortCashFlows(flows []CashFlow) {
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].Time.Before(flows[j].Time)
+	})
+}
+
+// TimeWeightedReturn returns the total return of the equity curve with the effect
+// of external cash flows removed, linking the returns between the cash flows.
+func (s Statistic) TimeWeightedReturn() (float64, error) {
+	if len(s.equity) == 0 {
+		return 0, errors.New("could not calculate timeWeightedReturn, no equity points found")
+	}
+
+	twr := 1.0
+	for i := 1; i < len(s.equity); i++ {
+		last := s.equity[i-1].equity
+		if last == 0 {
+			continue
+		}
+		// the cash flow is booked before the valuation of the equity point
+		twr *= (s.equity[i].equity - s.equity[i].cashFlow) / last
+	}
+
+	return math.Round((twr-1)*math.Pow10(DP)) / math.Pow10(DP), nil
+}
+
+// MoneyWeightedReturn returns the annualised internal rate of return of the equity curve,
+// treating the first equity and all cash flows as investments and the last equity as payout.
+func (s Statistic) MoneyWeightedReturn() (float64, error) {
+	first, ok := s.firstEquityPoint()
+	if !ok {
+		return 0, errors.New("could not calculate moneyWeightedReturn, no equity points found")
+	}
+	last, _ := s.lastEquityPoint()
+
+	var flows []CashFlow
+	flows = append(flows, CashFlow{Time: first.timestamp, Amount: -first.equity})
+	for _, ep := range s.equity[1:] {
+		if ep.cashFlow != 0 {
+			flows = append(flows, CashFlow{Time: ep.timestamp, Amount: -ep.cashFlow})
+		}
+	}
+	flows = append(flows, CashFlow{Time: last.timestamp, Amount: last.equity})
+
+	irr, err := internalRateOfReturn(flows)
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(irr*math.Pow10(DP)) / math.Pow10(DP), nil
+}
+
+// internalRateOfReturn solves the annual rate for a net present value of zero by bisection.
+func internalRateOfReturn(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, errors.New("could not calculate internal rate of return, not enough cash flows")
+	}
+
+	start := flows[0].Time
+	npv := func(rate float64) float64 {
+		var v float64
+		for _, f := range flows {
+			years := f.Time.Sub(start).Hours() / 24 / 365
+			v += f.Amount / math.Pow(1+rate, years)
+		}
+		return v
+	}
+
+	low, high := -0.9999, 10.0
+	if npv(low)*npv(high) > 0 {
+		return 0, errors.New("could not calculate internal rate of return, no sign change in cash flows")
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if npv(low)*npv(mid) <= 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return (low + high) / 2, nil
+}
@@ -0,0 +1,111 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonthlyCashFlows(t *testing.T) {
+	start, _ := time.Parse("2006-01-02", "2017-01-15")
+	end, _ := time.Parse("2006-01-02", "2017-03-31")
+
+	exp := []CashFlow{
+		{Time: start, Amount: 100},
+		{Time: start.AddDate(0, 1, 0), Amount: 100},
+		{Time: start.AddDate(0, 2, 0), Amount: 100},
+	}
+
+	flows := MonthlyCashFlows(start, end, 100)
+	if !reflect.DeepEqual(flows, exp) {
+		t.Errorf("MonthlyCashFlows(): \nexpected %#v, \nactual   %#v", exp, flows)
+	}
+}
+
+func TestPortfolioBookCashFlows(t *testing.T) {
+	day1, _ := time.Parse("2006-01-02", "2017-06-01")
+	day2, _ := time.Parse("2006-01-02", "2017-06-02")
+	day3, _ := time.Parse("2006-01-02", "2017-06-03")
+
+	p := &Portfolio{cash: 1000}
+	p.SetCashFlows(CashFlow{Time: day3, Amount: -200}, CashFlow{Time: day2, Amount: 500})
+
+	p.Update(&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}})
+	if p.Cash() != 1000 || len(p.CashFlows()) != 0 {
+		t.Errorf("bookCashFlows(): expected no cash flow before its time, actual cash %v", p.Cash())
+	}
+
+	p.Update(&Bar{Event: Event{timestamp: day3, symbol: "TEST.DE"}})
+	if p.Cash() != 1300 || len(p.CashFlows()) != 2 {
+		t.Errorf("bookCashFlows(): expected cash %v with 2 cash flows, actual cash %v with %v",
+			1300.0, p.Cash(), len(p.CashFlows()))
+	}
+}
+
+func TestStatisticCashFlowReturns(t *testing.T) {
+	day1, _ := time.Parse("2006-01-02", "2017-01-01")
+	day2, _ := time.Parse("2006-01-02", "2017-07-02")
+	day3, _ := time.Parse("2006-01-02", "2018-01-01")
+
+	// 10% return in the first period, a deposit of 1000, 0% return in the second period
+	s := Statistic{
+		equity: []equityPoint{
+			{timestamp: day1, equity: 1000},
+			{timestamp: day2, equity: 2100, cashFlow: 1000},
+			{timestamp: day3, equity: 2100},
+		},
+	}
+
+	twr, err := s.TimeWeightedReturn()
+	if err != nil || twr != 0.1 {
+		t.Errorf("TimeWeightedReturn(): \nexpected %v, \nactual   %v %v", 0.1, twr, err)
+	}
+
+	// the money weighted return is lower, as the deposit earned nothing
+	mwr, err := s.MoneyWeightedReturn()
+	if err != nil || mwr <= 0 || mwr >= 0.1 {
+		t.Errorf("MoneyWeightedReturn(): \nexpected between 0 and 0.1, \nactual   %v %v", mwr, err)
+	}
+
+	if _, err := (Statistic{}).TimeWeightedReturn(); err == nil {
+		t.Errorf("TimeWeightedReturn(): expected error without equity points")
+	}
+}
+
+func TestCashFlowDrawdown(t *testing.T) {
+	day, _ := time.Parse("2006-01-02 15:04", "2017-06-01 09:00")
+
+	p := &Portfolio{cash: 1000}
+	p.SetCashFlows(CashFlow{Time: day.Add(time.Hour), Amount: -500})
+	p.SetRiskRules(&DrawdownHalt{MaxDrawdown: 0.1}, &DailyLossLimit{MaxLossPercent: 0.2})
+	s := &Statistic{}
+
+	// testCases is a table for testing the drawdown and the risk rules with a withdrawal
+	var testCases = []struct {
+		msg         string
+		time        time.Time
+		loss        float64
+		expDrawdown float64
+		expReturn   float64
+		expHalt     bool
+	}{
+		{"start at the high-water mark:", day, 0, 0, 0, false},
+		{"withdrawal is no drawdown:", day.Add(2 * time.Hour), 0, 0, 0, false},
+		{"loss after the withdrawal:", day.Add(3 * time.Hour), 60, -0.12, -0.06, true},
+	}
+
+	for _, tc := range testCases {
+		bar := &Bar{Event: Event{timestamp: tc.time, symbol: "TEST.DE"}}
+		p.Update(bar)
+		p.cash -= tc.loss
+		s.Update(bar, p)
+		halt, _ := p.CheckRules(bar)
+
+		e, _ := s.lastEquityPoint()
+		total, _ := s.TotalEquityReturn()
+		if e.drawdown != tc.expDrawdown || total != tc.expReturn || (halt != nil) != tc.expHalt {
+			t.Errorf("%v Update(): \nexpected drawdown %v return %v halt %v, \nactual   drawdown %v return %v halt %v",
+				tc.msg, tc.expDrawdown, tc.expReturn, tc.expHalt, e.drawdown, total, halt)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package chart renders the results of a backtest as images, the equity curve, the underwater
+// plot of the drawdowns and the price of a symbol with the entries and exits of the trades.
+//
+// A chart is written as SVG or PNG, saved standalone with Save or embedded in an HTML report,
+// e.g. the page of WriteHTML. PNG images are drawn without text, the title and the axis
+// labels are part of the SVG only.
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// The default size of a chart in pixels.
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 400
+)
+
+// The margins of the plot area inside the chart.
+const (
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 30
+	marginBottom = 30
+)
+
+// The default colors of the lines and markers.
+const (
+	ColorEquity   = "#1f77b4"
+	ColorDrawdown = "#d62728"
+	ColorPrice    = "#444444"
+	ColorEntry    = "#2ca02c"
+	ColorExit     = "#d62728"
+)
+
+// Point is a value at a time.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Line is a line of a chart. An area line is filled down, or up, to the zero line.
+type Line struct {
+	Name   string
+	Color  string // hex color, e.g. #1f77b4, defaults to ColorEquity
+	Points []Point
+	Area   bool
+}
+
+// color returns the color of the line, the color of the equity if not set.
+func (l Line) color() string {
+	if l.Color == "" {
+		return ColorEquity
+	}
+	return l.Color
+}
+
+// Marker marks a trade on a chart, an entry as triangle pointing up, an exit as triangle
+// pointing down.
+type Marker struct {
+	Time  time.Time
+	Value float64
+	Entry bool
+}
+
+// Chart is a chart of lines over time.
+type Chart struct {
+	Title   string
+	Width   int // defaults to DefaultWidth
+	Height  int // defaults to DefaultHeight
+	Lines   []Line
+	Markers []Marker
+}
+
+// Equity returns the chart of the equity curve of a backtest.
+func Equity(stats *gbt.Statistic) *Chart {
+	line := Line{Name: "Equity", Color: ColorEquity}
+	for _, ep := range stats.EquityCurve() {
+		line.Points = append(line.Points, Point{Time: ep.Time, Value: ep.Equity})
+	}
+	return &Chart{Title: "Equity", Lines: []Line{line}}
+}
+
+// Underwater returns the underwater plot of a backtest, the drawdown from the high-water mark
+// in percent.
+func Underwater(stats *gbt.Statistic) *Chart {
+	line := Line{Name: "Drawdown", Color: ColorDrawdown, Area: true}
+	for _, ep := range stats.EquityCurve() {
+		line.Points = append(line.Points, Point{Time: ep.Time, Value: ep.Drawdown * 100})
+	}
+	return &Chart{Title: "Drawdown %", Lines: []Line{line}}
+}
+
+// Price returns the chart of the price of a symbol with a marker for each fill of the symbol.
+// A fill is an entry if it opens or adds to a position, an exit if it reduces or closes it.
+func Price(symbol string, stream []gbt.DataEvent, fills []gbt.FillEvent) *Chart {
+	line := Line{Name: symbol, Color: ColorPrice}
+	for _, e := range stream {
+		if e.Symbol() == symbol {
+			line.Points = append(line.Points, Point{Time: e.Time(), Value: e.Price()})
+		}
+	}
+
+	c := &Chart{Title: symbol, Lines: []Line{line}}
+	var position float64
+	for _, f := range fills {
+		if f.Symbol() != symbol {
+			continue
+		}
+		qty := f.Qty()
+		if f.Direction() == gbt.SLD {
+			qty = -qty
+		}
+		// an entry moves the position away from flat
+		entry := position == 0 || (position > 0) == (qty > 0)
+		position += qty
+		c.Markers = append(c.Markers, Marker{Time: f.Time(), Value: f.Price(), Entry: entry})
+	}
+	return c
+}
+
+// Save writes the chart to a file, as SVG or PNG by the extension of the path.
+func (c *Chart) Save(path string) error {
+	var write func(*os.File) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		write = func(f *os.File) error { return c.WriteSVG(f) }
+	case ".png":
+		write = func(f *os.File) error { return c.WritePNG(f) }
+	default:
+		return fmt.Errorf("could not save chart %s, unknown image format", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// size returns the width and height of the chart.
+func (c *Chart) size() (int, int) {
+	width, height := c.Width, c.Height
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+	return width, height
+}
+
+// frame maps the times and values of a chart to pixels of the plot area.
+type frame struct {
+	start, end  time.Time
+	low, high   float64
+	left, right float64
+	top, bottom float64
+}
+
+// frame returns the frame of all points and markers of the chart.
+func (c *Chart) frame() (frame, error) {
+	width, height := c.size()
+	if width <= marginLeft+marginRight || height <= marginTop+marginBottom {
+		return frame{}, fmt.Errorf("could not render chart of %dx%d pixels, too small", width, height)
+	}
+
+	f := frame{
+		low: math.Inf(1), high: math.Inf(-1),
+		left: marginLeft, right: float64(width - marginRight),
+		top: marginTop, bottom: float64(height - marginBottom),
+	}
+	var area bool
+	extend := func(t time.Time, v float64) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return
+		}
+		if f.start.IsZero() || t.Before(f.start) {
+			f.start = t
+		}
+		if t.After(f.end) {
+			f.end = t
+		}
+		f.low = math.Min(f.low, v)
+		f.high = math.Max(f.high, v)
+	}
+	for _, l := range c.Lines {
+		for _, p := range l.Points {
+			extend(p.Time, p.Value)
+		}
+		area = area || l.Area
+	}
+	for _, m := range c.Markers {
+		extend(m.Time, m.Value)
+	}
+	if math.IsInf(f.low, 0) {
+		return frame{}, errors.New("could not render chart without points")
+	}
+
+	// an area is filled to the zero line
+	if area {
+		f.low = math.Min(f.low, 0)
+		f.high = math.Max(f.high, 0)
+	}
+	if f.high == f.low {
+		f.low--
+		f.high++
+	}
+	return f, nil
+}
+
+// x returns the horizontal pixel of a time.
+func (f frame) x(t time.Time) float64 {
+	span := f.end.Sub(f.start)
+	if span <= 0 {
+		return (f.left + f.right) / 2
+	}
+	return f.left + float64(t.Sub(f.start))/float64(span)*(f.right-f.left)
+}
+
+// y returns the vertical pixel of a value.
+func (f frame) y(v float64) float64 {
+	return f.bottom - (v-f.low)/(f.high-f.low)*(f.bottom-f.top)
+}
+
+// ticks returns n+1 evenly spaced values from the low to the high of the frame.
+func (f frame) ticks(n int) []float64 {
+	ticks := make([]float64, n+1)
+	for i := range ticks {
+		ticks[i] = f.low + float64(i)*(f.high-f.low)/float64(n)
+	}
+	return ticks
+}
@@ -0,0 +1,162 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// newTestBacktest runs a trading backtest of the test data and returns its data stream and
+// statistic.
+func newTestBacktest(t *testing.T) ([]gbt.DataEvent, *gbt.Statistic) {
+	d := &data.BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := d.Load([]string{"SDF.DE"}); err != nil {
+		t.Fatal(err)
+	}
+	var stream []gbt.DataEvent
+	for _, e := range d.Stream() {
+		if e.Time().Year() == 2016 {
+			stream = append(stream, e)
+		}
+	}
+	d.SetStream(stream)
+
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetSizeManager(&gbt.Size{DefaultSize: 100, DefaultValue: 10000})
+
+	test := gbt.New()
+	test.SetSymbols([]string{"SDF.DE"})
+	test.SetData(d)
+	test.SetPortfolio(portfolio)
+	test.SetStrategy(strategy.MovingAverageCross(5, 20))
+	if err := test.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return stream, test.Stats().(*gbt.Statistic)
+}
+
+// points returns a point per day from a date.
+func points(start time.Time, values ...float64) []Point {
+	var points []Point
+	for i, v := range values {
+		points = append(points, Point{Time: start.AddDate(0, 0, i), Value: v})
+	}
+	return points
+}
+
+func TestCharts(t *testing.T) {
+	stream, stats := newTestBacktest(t)
+	curve := stats.EquityCurve()
+
+	equity := Equity(stats)
+	if len(equity.Lines) != 1 || len(equity.Lines[0].Points) != len(curve) || equity.Lines[0].Points[0].Value != curve[0].Equity {
+		t.Errorf("Equity(): expected a point for each equity point, actual %+v", equity.Lines)
+	}
+
+	underwater := Underwater(stats)
+	line := underwater.Lines[0]
+	if !line.Area || len(line.Points) != len(curve) {
+		t.Fatalf("Underwater(): expected an area of the drawdowns, actual %+v", line)
+	}
+	for i, p := range line.Points {
+		if p.Value > 0 || p.Value != curve[i].Drawdown*100 {
+			t.Errorf("Underwater(): expected the drawdown in percent, actual %v at %v", p.Value, p.Time)
+			break
+		}
+	}
+
+	price := Price("SDF.DE", stream, stats.Transactions())
+	if len(price.Lines[0].Points) != len(stream) || len(price.Markers) != len(stats.Transactions()) {
+		t.Fatalf("Price(): expected a point per bar and a marker per fill, actual %v %v", len(price.Lines[0].Points), len(price.Markers))
+	}
+	// the moving average cross enters a long position and exits it again
+	var exits int
+	for i, m := range price.Markers {
+		fill := stats.Transactions()[i]
+		if m.Value != fill.Price() || !m.Time.Equal(fill.Time()) {
+			t.Errorf("Price(): expected the marker at the fill %v %v, actual %+v", fill.Time(), fill.Price(), m)
+		}
+		if !m.Entry {
+			exits++
+		}
+	}
+	if !price.Markers[0].Entry || exits == 0 {
+		t.Errorf("Price(): expected an entry first and exits, actual %+v", price.Markers)
+	}
+	if empty := Price("OTHER", stream, stats.Transactions()); len(empty.Lines[0].Points) != 0 || len(empty.Markers) != 0 {
+		t.Errorf("Price(): expected no points of another symbol, actual %+v", empty)
+	}
+}
+
+func TestChartFrame(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// testCases is a table for testing the frame of a chart
+	var testCases = []struct {
+		msg     string
+		chart   Chart
+		expLow  float64
+		expHigh float64
+		expErr  bool
+	}{
+		{"line:", Chart{Lines: []Line{{Points: points(day, 10, 20, 15)}}}, 10, 20, false},
+		{"markers extend the frame:", Chart{Lines: []Line{{Points: points(day, 10, 20)}}, Markers: []Marker{{Time: day, Value: 25}}}, 10, 25, false},
+		{"area includes the zero line:", Chart{Lines: []Line{{Points: points(day, -5, -10), Area: true}}}, -10, 0, false},
+		{"flat line:", Chart{Lines: []Line{{Points: points(day, 5, 5)}}}, 4, 6, false},
+		{"no points:", Chart{Lines: []Line{{}}}, 0, 0, true},
+		{"too small:", Chart{Width: 50, Lines: []Line{{Points: points(day, 1)}}}, 0, 0, true},
+	}
+
+	for _, tc := range testCases {
+		f, err := tc.chart.frame()
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v frame(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if err == nil && (f.low != tc.expLow || f.high != tc.expHigh) {
+			t.Errorf("%v frame(): expected %v to %v, actual %v to %v", tc.msg, tc.expLow, tc.expHigh, f.low, f.high)
+		}
+	}
+
+	f, _ := (&Chart{Lines: []Line{{Points: points(day, 10, 20)}}}).frame()
+	if f.x(day) != marginLeft || f.x(day.AddDate(0, 0, 1)) != DefaultWidth-marginRight ||
+		f.y(10) != DefaultHeight-marginBottom || f.y(20) != marginTop {
+		t.Errorf("frame(): expected the points at the corners of the plot area, actual %+v", f)
+	}
+}
+
+func TestChartSave(t *testing.T) {
+	dir := t.TempDir()
+	c := &Chart{Title: "Test", Lines: []Line{{Points: points(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 1, 2, 3)}}}
+
+	for _, name := range []string{"chart.svg", "chart.PNG"} {
+		path := filepath.Join(dir, name)
+		if err := c.Save(path); err != nil {
+			t.Fatalf("Save(%s): unexpected error %v", name, err)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil || len(content) == 0 {
+			t.Errorf("Save(%s): expected the image, actual %v", name, err)
+		}
+		if strings.HasSuffix(name, ".svg") && !bytes.HasPrefix(content, []byte("<svg")) {
+			t.Errorf("Save(%s): expected an SVG image, actual %q", name, content)
+		}
+		if strings.HasSuffix(name, ".PNG") {
+			if _, err := png.Decode(bytes.NewReader(content)); err != nil {
+				t.Errorf("Save(%s): expected a PNG image, actual %v", name, err)
+			}
+		}
+	}
+
+	if err := c.Save(filepath.Join(dir, "chart.gif")); err == nil || !strings.Contains(err.Error(), "unknown image format") {
+		t.Errorf("Save(): expected an error of the image format, actual %v", err)
+	}
+}
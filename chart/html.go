@@ -0,0 +1,57 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"io"
+)
+
+// HTML returns the chart as inline SVG, e.g. to embed it in the template of a report.
+func (c *Chart) HTML() (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := c.WriteSVG(&buf); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// DataURI returns the chart as PNG data URI, e.g. for the src of an img element of an
+// HTML report or an email.
+func (c *Chart) DataURI() (template.URL, error) {
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf); err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// page is the template of a standalone HTML report of charts.
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: sans-serif">
+<h1>{{.Title}}</h1>
+{{range .Charts}}<div>{{.}}</div>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes a standalone HTML report with the charts as inline SVG.
+func WriteHTML(w io.Writer, title string, charts ...*Chart) error {
+	data := struct {
+		Title  string
+		Charts []template.HTML
+	}{Title: title}
+	for _, c := range charts {
+		svg, err := c.HTML()
+		if err != nil {
+			return err
+		}
+		data.Charts = append(data.Charts, svg)
+	}
+	return page.Execute(w, data)
+}
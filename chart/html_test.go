@@ -0,0 +1,32 @@
+package chart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHTML(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	equity := &Chart{Title: "Equity", Lines: []Line{{Points: points(day, 100, 110)}}}
+	drawdown := &Chart{Title: "Drawdown", Lines: []Line{{Points: points(day, 0, -1), Area: true}}}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, "Report <1>", equity, drawdown); err != nil {
+		t.Fatalf("WriteHTML(): unexpected error %v", err)
+	}
+	page := buf.String()
+	if strings.Count(page, "<svg ") != 2 || !strings.Contains(page, "<h1>Report &lt;1&gt;</h1>") {
+		t.Errorf("WriteHTML(): expected the escaped title and both charts inline, actual\n%s", page)
+	}
+
+	if err := WriteHTML(&buf, "empty", &Chart{}); err == nil {
+		t.Errorf("WriteHTML(): expected an error of the empty chart")
+	}
+
+	uri, err := equity.DataURI()
+	if err != nil || !strings.HasPrefix(string(uri), "data:image/png;base64,") {
+		t.Errorf("DataURI(): expected a PNG data uri, actual %.40s %v", uri, err)
+	}
+}
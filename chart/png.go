@@ -0,0 +1,134 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WritePNG writes the chart as PNG image, without the title and the axis labels.
+func (c *Chart) WritePNG(w io.Writer) error {
+	img, err := c.Image()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Image draws the chart as image, without the title and the axis labels.
+func (c *Chart) Image() (*image.RGBA, error) {
+	f, err := c.frame()
+	if err != nil {
+		return nil, err
+	}
+	width, height := c.size()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	grid := color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	for _, v := range f.ticks(yTicks) {
+		line(img, f.left, f.y(v), f.right, f.y(v), grid)
+	}
+	border := color.RGBA{0x99, 0x99, 0x99, 0xff}
+	line(img, f.left, f.top, f.right, f.top, border)
+	line(img, f.left, f.bottom, f.right, f.bottom, border)
+	line(img, f.left, f.top, f.left, f.bottom, border)
+	line(img, f.right, f.top, f.right, f.bottom, border)
+
+	for _, l := range c.Lines {
+		col, err := parseColor(l.color())
+		if err != nil {
+			return nil, err
+		}
+		if l.Area {
+			fill := col
+			fill.A = 0x4c
+			for i := 1; i < len(l.Points); i++ {
+				a, b := l.Points[i-1], l.Points[i]
+				area(img, f.x(a.Time), f.y(a.Value), f.x(b.Time), f.y(b.Value), f.y(0), fill)
+			}
+		}
+		for i := 1; i < len(l.Points); i++ {
+			a, b := l.Points[i-1], l.Points[i]
+			line(img, f.x(a.Time), f.y(a.Value), f.x(b.Time), f.y(b.Value), col)
+		}
+	}
+
+	entry, _ := parseColor(ColorEntry)
+	exit, _ := parseColor(ColorExit)
+	for _, m := range c.Markers {
+		if m.Entry {
+			triangle(img, f.x(m.Time), f.y(m.Value), 8, entry)
+		} else {
+			triangle(img, f.x(m.Time), f.y(m.Value), -8, exit)
+		}
+	}
+
+	return img, nil
+}
+
+// parseColor parses a hex color, e.g. #1f77b4.
+func parseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("could not parse color %q, expected #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("could not parse color %q, expected #rrggbb", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+}
+
+// line draws a line between two points.
+func line(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		img.SetRGBA(int(math.Round(x0+t*(x1-x0))), int(math.Round(y0+t*(y1-y0))), c)
+	}
+}
+
+// area fills the area between a line and the zero line, column by column.
+func area(img *image.RGBA, x0, y0, x1, y1, zero float64, c color.RGBA) {
+	for x := math.Round(x0); x <= math.Round(x1); x++ {
+		y := y0
+		if x1 > x0 {
+			y = y0 + (x-x0)/(x1-x0)*(y1-y0)
+		}
+		top, bottom := math.Min(y, zero), math.Max(y, zero)
+		for py := math.Round(top); py <= math.Round(bottom); py++ {
+			blend(img, int(x), int(py), c)
+		}
+	}
+}
+
+// triangle draws a filled triangle with the tip at a point, pointing up for a positive height.
+func triangle(img *image.RGBA, x, y, height float64, c color.RGBA) {
+	rows := int(math.Abs(height))
+	for i := 0; i <= rows; i++ {
+		half := float64(i) * 5 / math.Abs(height)
+		py := y + math.Copysign(float64(i), height)
+		line(img, x-half, py, x+half, py, c)
+	}
+}
+
+// blend draws a translucent color over a pixel.
+func blend(img *image.RGBA, x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(img.Bounds())) {
+		return
+	}
+	bg := img.RGBAAt(x, y)
+	a := float64(c.A) / 0xff
+	mix := func(fg, bg uint8) uint8 {
+		return uint8(math.Round(float64(fg)*a + float64(bg)*(1-a)))
+	}
+	img.SetRGBA(x, y, color.RGBA{mix(c.R, bg.R), mix(c.G, bg.G), mix(c.B, bg.B), 0xff})
+}
@@ -0,0 +1,87 @@
+package chart
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestImage(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &Chart{
+		Width:  200,
+		Height: 100,
+		Lines:  []Line{{Points: points(day, -1, -2, 0), Area: true}},
+		// an entry at the low
+		Markers: []Marker{{Time: day.AddDate(0, 0, 1), Value: -2, Entry: true}},
+	}
+
+	img, err := c.Image()
+	if err != nil {
+		t.Fatalf("Image(): unexpected error %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("Image(): expected 200x100 pixels, actual %v", b)
+	}
+
+	f, _ := c.frame()
+	line, _ := parseColor(ColorEquity)
+	entry, _ := parseColor(ColorEntry)
+
+	// testCases is a table for testing the pixels of the image
+	var testCases = []struct {
+		msg string
+		x   float64
+		y   float64
+		exp func(color.RGBA) bool
+	}{
+		{"line at the start:", f.x(day), f.y(-1), func(c color.RGBA) bool { return c == line }},
+		{"entry below the tip:", f.x(day.AddDate(0, 0, 1)), f.y(-2) + 4, func(c color.RGBA) bool { return c == entry }},
+		{"translucent area:", f.x(day.AddDate(0, 0, 1)), f.y(-1), func(c color.RGBA) bool {
+			return c != line && c != color.RGBA{0xff, 0xff, 0xff, 0xff} && c.B > c.R
+		}},
+		{"white background:", 2, 2, func(c color.RGBA) bool { return c == color.RGBA{0xff, 0xff, 0xff, 0xff} }},
+	}
+
+	for _, tc := range testCases {
+		if px := img.RGBAAt(int(tc.x+0.5), int(tc.y+0.5)); !tc.exp(px) {
+			t.Errorf("%v Image(): unexpected color %v at %v,%v", tc.msg, px, tc.x, tc.y)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf); err != nil {
+		t.Fatalf("WritePNG(): unexpected error %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Errorf("WritePNG(): expected a PNG image, actual %v", err)
+	}
+
+	c.Lines[0].Color = "blue"
+	if _, err := c.Image(); err == nil {
+		t.Errorf("Image(): expected an error of the color")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	// testCases is a table for testing the parsing of hex colors
+	var testCases = []struct {
+		color  string
+		exp    color.RGBA
+		expErr bool
+	}{
+		{"#1f77b4", color.RGBA{0x1f, 0x77, 0xb4, 0xff}, false},
+		{"2ca02c", color.RGBA{0x2c, 0xa0, 0x2c, 0xff}, false},
+		{"#fff", color.RGBA{}, true},
+		{"#gggggg", color.RGBA{}, true},
+	}
+
+	for _, tc := range testCases {
+		c, err := parseColor(tc.color)
+		if c != tc.exp || (err != nil) != tc.expErr {
+			t.Errorf("parseColor(%q): expected %v error %v, actual %v %v", tc.color, tc.exp, tc.expErr, c, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yTicks is the number of intervals between the labels of the value axis.
+const yTicks = 4
+
+// WriteSVG writes the chart as SVG image.
+func (c *Chart) WriteSVG(w io.Writer) error {
+	f, err := c.frame()
+	if err != nil {
+		return err
+	}
+	width, height := c.size()
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	if c.Title != "" {
+		fmt.Fprintf(b, `<text x="%s" y="18" font-size="14">%s</text>`+"\n", coord(f.left), html.EscapeString(c.Title))
+	}
+
+	// grid and labels of the value axis
+	for _, v := range f.ticks(yTicks) {
+		y := coord(f.y(v))
+		fmt.Fprintf(b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#e0e0e0"/>`+"\n", coord(f.left), y, coord(f.right), y)
+		fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n",
+			coord(f.left-6), y, strconv.FormatFloat(v, 'g', 6, 64))
+	}
+	// labels of the time axis
+	fmt.Fprintf(b, `<text x="%s" y="%s">%s</text>`+"\n", coord(f.left), coord(f.bottom+18), f.start.Format("2006-01-02"))
+	fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="end">%s</text>`+"\n", coord(f.right), coord(f.bottom+18), f.end.Format("2006-01-02"))
+	fmt.Fprintf(b, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#999999"/>`+"\n",
+		coord(f.left), coord(f.top), coord(f.right-f.left), coord(f.bottom-f.top))
+
+	for _, l := range c.Lines {
+		if len(l.Points) == 0 {
+			continue
+		}
+		points := make([]string, len(l.Points))
+		for i, p := range l.Points {
+			points[i] = coord(f.x(p.Time)) + "," + coord(f.y(p.Value))
+		}
+		if l.Area {
+			zero := coord(f.y(0))
+			first, last := l.Points[0], l.Points[len(l.Points)-1]
+			polygon := append([]string{coord(f.x(first.Time)) + "," + zero}, points...)
+			polygon = append(polygon, coord(f.x(last.Time))+","+zero)
+			fmt.Fprintf(b, `<polygon points="%s" fill="%s" fill-opacity="0.3" stroke="none"/>`+"\n", strings.Join(polygon, " "), html.EscapeString(l.color()))
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"><title>%s</title></polyline>`+"\n",
+			strings.Join(points, " "), html.EscapeString(l.color()), html.EscapeString(l.Name))
+	}
+
+	for _, m := range c.Markers {
+		x, y := f.x(m.Time), f.y(m.Value)
+		// the tip of the triangle points at the price
+		tip, base, color := y, y+8, ColorEntry
+		if !m.Entry {
+			base, color = y-8, ColorExit
+		}
+		fmt.Fprintf(b, `<polygon points="%s,%s %s,%s %s,%s" fill="%s"/>`+"\n",
+			coord(x), coord(tip), coord(x-5), coord(base), coord(x+5), coord(base), color)
+	}
+
+	fmt.Fprintln(b, `</svg>`)
+	return b.Flush()
+}
+
+// coord formats a coordinate of the SVG.
+func coord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,65 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteSVG(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &Chart{
+		Title: "SDF.DE <test>",
+		Lines: []Line{
+			{Name: "Price", Color: ColorPrice, Points: points(day, 10, 20, 15)},
+			{Name: "Drawdown", Points: points(day, 0, -2, -1), Area: true},
+		},
+		Markers: []Marker{{Time: day, Value: 10, Entry: true}, {Time: day.AddDate(0, 0, 2), Value: 15}},
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteSVG(&buf); err != nil {
+		t.Fatalf("WriteSVG(): unexpected error %v", err)
+	}
+	svg := buf.String()
+
+	// the image is well-formed xml
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err.Error() != "EOF" {
+				t.Fatalf("WriteSVG(): expected well-formed xml, actual %v\n%s", err, svg)
+			}
+			break
+		}
+	}
+
+	// testCases is a table for testing the elements of the image
+	var testCases = []struct {
+		msg     string
+		element string
+	}{
+		{"escaped title:", "SDF.DE &lt;test&gt;"},
+		{"first and last date:", ">2020-01-01<"},
+		{"line of the price:", `stroke="#444444" stroke-width="1.5"><title>Price</title>`},
+		{"line with the default color:", `stroke="#1f77b4" stroke-width="1.5"><title>Drawdown</title>`},
+		{"area to the zero line:", `fill="#1f77b4" fill-opacity="0.3"`},
+		{"entry marker:", `fill="` + ColorEntry + `"`},
+		{"exit marker:", `fill="` + ColorExit + `"`},
+	}
+
+	for _, tc := range testCases {
+		if !strings.Contains(svg, tc.element) {
+			t.Errorf("%v WriteSVG(): expected %q in\n%s", tc.msg, tc.element, svg)
+		}
+	}
+	if !strings.Contains(svg, ">2020-01-03<") {
+		t.Errorf("WriteSVG(): expected the last date, actual\n%s", svg)
+	}
+
+	if err := (&Chart{}).WriteSVG(&buf); err == nil {
+		t.Errorf("WriteSVG(): expected an error without points")
+	}
+}
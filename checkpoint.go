@@ -0,0 +1,222 @@
+package gobacktest
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint is a snapshot of a running backtest. It holds the state of the portfolio, the resting
+// orders of the exchange, the statistics and the strategy, if it is a Snapshotter. A resumed backtest
+// restores the state and continues after the Cursor without processing the earlier data events again,
+// so side effects of the strategy or the handlers, e.g. notifications or orders to a broker, do not
+// fire twice. Data identifies the data events up to the Cursor, Cash and Value verify the restored portfolio.
+type Checkpoint struct {
+	Cursor      int                        `json:"cursor"` // number of processed data events
+	Time        time.Time                  `json:"time"`   // time of the last processed data event
+	Events      int                        `json:"events"` // number of tracked events
+	Cash        float64                    `json:"cash"`
+	Value       float64                    `json:"value"`
+	Data        string                     `json:"data,omitempty"` // fingerprint of the processed data events
+	Clock       Clock                      `json:"clock"`
+	LastEventID int                        `json:"lastEventID"`
+	CustomNext  int                        `json:"customNext,omitempty"`
+	WarmUp      map[string]int             `json:"warmUp,omitempty"`
+	State       map[string]json.RawMessage `json:"state,omitempty"` // state of the portfolio, exchange, statistic and strategy
+	Created     time.Time                  `json:"created"`
+}
+
+// Checkpoint returns a snapshot of the current state of the backtest.
+func (t *Backtest) Checkpoint() (Checkpoint, error) {
+	c := Checkpoint{
+		Cursor:      t.dataCount,
+		Time:        t.clock.Now,
+		Cash:        t.portfolio.Cash(),
+		Value:       t.portfolio.Value(),
+		Clock:       t.clock,
+		LastEventID: t.lastEventID,
+		CustomNext:  t.customNext,
+		WarmUp:      t.warmUpCount,
+		State:       make(map[string]json.RawMessage),
+		Created:     time.Now(),
+	}
+	if t.dataHash != nil {
+		c.Data = fmt.Sprintf("%016x", t.dataHash.Sum64())
+	}
+	if t.statistic != nil {
+		c.Events = len(t.statistic.Events())
+	}
+
+	for name, component := range t.snapshotters() {
+		state, err := snapshot(component)
+		if err != nil {
+			return c, fmt.Errorf("could not snapshot the %s: %v", name, err)
+		}
+		if state != nil {
+			c.State[name] = state
+		}
+	}
+	return c, nil
+}
+
+// snapshotters returns the components of the backtest which are saved into a checkpoint.
+func (t *Backtest) snapshotters() map[string]interface{} {
+	return map[string]interface{}{
+		"portfolio": t.portfolio,
+		"exchange":  t.exchange,
+		"statistic": t.statistic,
+		"strategy":  t.strategy,
+	}
+}
+
+// SaveCheckpoint writes a checkpoint to a file. The file is replaced atomically,
+// so a crash while writing keeps the previous checkpoint.
+func SaveCheckpoint(path string, c Checkpoint) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadCheckpoint reads a checkpoint from a file.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	var c Checkpoint
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// checkpointing writes a checkpoint every n data events.
+type checkpointing struct {
+	path  string
+	every int
+}
+
+// SetCheckpoints writes a checkpoint of the backtest to the file every n data events
+// and when the backtest is cancelled.
+func (t *Backtest) SetCheckpoints(path string, every int) {
+	if every < 1 {
+		every = 1
+	}
+	t.checkpoints = &checkpointing{path: path, every: every}
+}
+
+// Resume restores the state of a checkpoint and continues the backtest until the end of the data.
+// The backtest has to be set up with the same data, strategy and handlers as the checkpointed run,
+// the data is moved to the cursor of the checkpoint without processing the data events again.
+// Random sources start again from the seed of the backtest.
+func (t *Backtest) Resume(ctx context.Context, c Checkpoint) error {
+	if err := t.setup(); err != nil {
+		return err
+	}
+	if err := t.seek(c); err != nil {
+		return err
+	}
+	if err := t.restore(c); err != nil {
+		return err
+	}
+
+	if t.profile != nil {
+		return t.profile.run(ctx, t.run)
+	}
+	return t.run(ctx)
+}
+
+// seek moves the data to the cursor of a checkpoint and checks that it is the checkpointed data.
+func (t *Backtest) seek(c Checkpoint) error {
+	t.dataHash = nil
+	var last DataEvent
+	for t.dataCount < c.Cursor {
+		data, ok := t.data.Next()
+		if !ok {
+			return fmt.Errorf("checkpoint at data event %d beyond the end of the data at %d", c.Cursor, t.dataCount)
+		}
+		t.dataCount++
+		t.fingerprint(data)
+		last = data
+	}
+
+	switch {
+	case last != nil && !last.Time().Equal(c.Time):
+		return fmt.Errorf("checkpoint does not match the data: time %v, data %v", c.Time, last.Time())
+	case c.Data != "" && c.Data != fmt.Sprintf("%016x", t.dataHash.Sum64()):
+		return fmt.Errorf("checkpoint does not match the data up to data event %d", c.Cursor)
+	}
+	return nil
+}
+
+// restore restores the state of the engine and its components from a checkpoint.
+func (t *Backtest) restore(c Checkpoint) error {
+	t.clock = c.Clock
+	t.lastEventID = c.LastEventID
+	t.customNext = c.CustomNext
+	t.warmUpCount = c.WarmUp
+
+	for name, component := range t.snapshotters() {
+		if err := restore(component, c.State[name]); err != nil {
+			return fmt.Errorf("could not restore the %s: %v", name, err)
+		}
+	}
+
+	cash, value := t.portfolio.Cash(), t.portfolio.Value()
+	if math.Abs(cash-c.Cash) > 1e-6 || math.Abs(value-c.Value) > 1e-6 {
+		return fmt.Errorf("checkpoint does not match the restored portfolio: cash %v value %v, restored cash %v value %v", c.Cash, c.Value, cash, value)
+	}
+	return nil
+}
+
+// checkpoint writes the periodic checkpoints.
+func (t *Backtest) checkpoint() error {
+	if t.checkpoints == nil || t.dataCount == 0 || t.dataCount%t.checkpoints.every != 0 {
+		return nil
+	}
+	return t.saveCheckpoint()
+}
+
+// saveCheckpoint writes a checkpoint of the current state to the file of the checkpoint settings.
+func (t *Backtest) saveCheckpoint() error {
+	c, err := t.Checkpoint()
+	if err != nil {
+		return err
+	}
+	return SaveCheckpoint(t.checkpoints.path, c)
+}
+
+// fingerprint adds a data event to the fingerprint of the processed data.
+func (t *Backtest) fingerprint(data DataEvent) {
+	if t.dataHash == nil {
+		t.dataHash = fnv.New64a()
+	}
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], uint64(data.Time().UnixNano()))
+	binary.LittleEndian.PutUint64(b[8:], math.Float64bits(data.Price()))
+	t.dataHash.Write(b[:])
+	io.WriteString(t.dataHash, data.Symbol())
+}
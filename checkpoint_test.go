@@ -0,0 +1,120 @@
+package gobacktest
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resumableStrategy buys on every data event and saves the number of data events into a checkpoint.
+type resumableStrategy struct {
+	*Strategy
+	cancel func()
+	after  int
+	events int
+	calls  int // data events of the current run, not saved
+}
+
+func (s *resumableStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.events++
+	s.calls++
+	if s.cancel != nil && s.events == s.after {
+		s.cancel()
+	}
+	return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}}, nil
+}
+
+func (s *resumableStrategy) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(s.events)
+}
+
+func (s *resumableStrategy) Restore(state json.RawMessage) error {
+	return json.Unmarshal(state, &s.events)
+}
+
+func TestBacktestCheckpointResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "run.json")
+
+	closes := []float64{100, 101, 102, 103, 104}
+	build := func(strategy StrategyHandler, closes ...float64) *Backtest {
+		data := &Data{}
+		data.SetStream(newStressStream(closes...))
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(strategy)
+		return test
+	}
+
+	// the uninterrupted run as reference
+	full := build(&countingStrategy{Strategy: NewStrategy("counting")}, closes...)
+	if err := full.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the interrupted run is stopped after three data events
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := build(&resumableStrategy{Strategy: NewStrategy("resumable"), cancel: cancel, after: 3}, closes...)
+	stopped.SetCheckpoints(path, 2)
+	if err := stopped.RunContext(ctx); err != context.Canceled {
+		t.Fatalf("RunContext(): expected error %v, actual %v", context.Canceled, err)
+	}
+
+	c, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint(): unexpected error %v", err)
+	}
+	if c.Cursor != 3 || c.Events != 12 || len(c.State) != 4 {
+		t.Errorf("LoadCheckpoint(): expected cursor 3 with 12 events and the state of 4 components, actual %+v", c)
+	}
+
+	// testCases is a table for testing the resume of a checkpoint
+	var testCases = []struct {
+		msg    string
+		closes []float64
+		expErr bool
+	}{
+		{"same data:", closes, false},
+		{"changed data:", []float64{100, 101, 99, 103, 104}, true},
+		{"data too short:", []float64{100, 101}, true},
+	}
+
+	for _, tc := range testCases {
+		strategy := &resumableStrategy{Strategy: NewStrategy("resumable")}
+		resumed := build(strategy, tc.closes...)
+		resumed.SetCheckpoints(path+"."+tc.msg, 2)
+
+		err := resumed.Resume(context.Background(), c)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Resume(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		if resumed.portfolio.Value() != full.portfolio.Value() || len(resumed.statistic.Transactions()) != len(full.statistic.Transactions()) ||
+			len(resumed.statistic.Events()) != len(full.statistic.Events()) || len(resumed.statistic.(*Statistic).equity) != len(full.statistic.(*Statistic).equity) {
+			t.Errorf("%v Resume(): expected the state of the uninterrupted run, actual value %v with %v transactions and %v events",
+				tc.msg, resumed.portfolio.Value(), len(resumed.statistic.Transactions()), len(resumed.statistic.Events()))
+		}
+		// the data events before the checkpoint are not processed again
+		if strategy.calls != 2 || strategy.events != 5 {
+			t.Errorf("%v Resume(): expected 2 processed of 5 data events, actual %v of %v", tc.msg, strategy.calls, strategy.events)
+		}
+
+		// the resumed run writes new checkpoints only after the restored state
+		latest, err := LoadCheckpoint(path + "." + tc.msg)
+		if err != nil || latest.Cursor != 4 {
+			t.Errorf("%v Resume(): expected a new checkpoint at 4, actual %+v %v", tc.msg, latest, err)
+		}
+	}
+}
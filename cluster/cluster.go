@@ -0,0 +1,82 @@
+// Package cluster distributes the parameter sweep of an optimization over worker nodes.
+//
+// A Coordinator splits the grid of the strategy parameters of a config into units and
+// leases them to the workers, which run the backtests and report the scores back.
+// The coordinator aggregates the scores into the result of the optimization. Units of
+// workers which fail to report within the timeout are leased again to another worker.
+//
+// Coordinator and Worker talk JSON over HTTP with the endpoints
+//
+//	POST /lease   lease the next unit, 204 if all units are leased, 410 if the sweep is finished
+//	POST /report  report the scores of a unit
+//	GET  /status  progress of the sweep
+//
+// The transport is HTTP instead of gRPC, which keeps the package free of the grpc dependency,
+// the workers need nothing but the standard library. The coordinator trusts the reported
+// scores, set the same Token on the coordinator and the workers, so only workers holding
+// it lease and report units. The token is sent in plain text, use TLS outside of trusted networks.
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// Unit is a batch of parameter sets of the sweep, evaluated by a single worker.
+type Unit struct {
+	ID     int           `json:"id"`
+	Config config.Config `json:"config"`
+	Metric string        `json:"metric"`
+	Params []gbt.Params  `json:"params"`
+}
+
+// UnitResult reports the scores of a unit in the order of its parameter sets, undefined
+// scores, e.g. a sharpe ratio without volatility, are nil. A unit which could not be evaluated
+// reports the Error instead.
+type UnitResult struct {
+	ID     int        `json:"id"`
+	Worker string     `json:"worker"`
+	Scores []*float64 `json:"scores"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// Metrics are the scores of the optimization by name, all to be maximised.
+var Metrics = map[string]func(gbt.StatisticHandler) float64{
+	"sharpe":   func(s gbt.StatisticHandler) float64 { return s.SharpRatio(0) },
+	"sortino":  func(s gbt.StatisticHandler) float64 { return s.SortinoRatio(0) },
+	"drawdown": func(s gbt.StatisticHandler) float64 { return s.MaxDrawdown() },
+	"return": func(s gbt.StatisticHandler) float64 {
+		r, _ := s.TotalEquityReturn()
+		return r
+	},
+}
+
+// Evaluate runs the backtest of the config with the strategy parameters replaced by params
+// and returns the score of the metric.
+func Evaluate(ctx context.Context, c config.Config, params gbt.Params, metric string) (float64, error) {
+	score, ok := Metrics[metric]
+	if !ok {
+		return 0, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	trial := c
+	trial.Strategy.Params = gbt.Params{}
+	for k, v := range c.Strategy.Params {
+		trial.Strategy.Params[k] = v
+	}
+	for k, v := range params {
+		trial.Strategy.Params[k] = v
+	}
+
+	test, err := trial.Build()
+	if err != nil {
+		return 0, err
+	}
+	if err := test.RunContext(ctx); err != nil {
+		return 0, err
+	}
+	return score(test.Stats()), nil
+}
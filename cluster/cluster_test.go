@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// testConfig is a config of the test data.
+var testConfig = config.Config{
+	Symbols: []string{"SDF.DE"},
+	Data:    config.DataConfig{Dir: "../examples/testdata/bar/", Start: "2016-01-01", End: "2017-06-30"},
+	Strategy: config.StrategyConfig{
+		Name:   "moving-average-cross",
+		Params: gbt.Params{"short": 5, "long": 20},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	ret, err := Evaluate(context.Background(), testConfig, gbt.Params{"short": 10}, "return")
+	if err != nil {
+		t.Fatalf("Evaluate(): unexpected error %v", err)
+	}
+
+	// the params replace the params of the config
+	c := testConfig
+	c.Strategy.Params = gbt.Params{"short": 10, "long": 20}
+	test, _ := c.Build()
+	test.Run()
+	expected, _ := test.Stats().TotalEquityReturn()
+	if ret != expected {
+		t.Errorf("Evaluate(): expected return %v, actual %v", expected, ret)
+	}
+	if testConfig.Strategy.Params["short"] != 5 {
+		t.Errorf("Evaluate(): expected the params of the config unchanged, actual %v", testConfig.Strategy.Params)
+	}
+
+	// testCases is a table for testing the errors of Evaluate
+	var testCases = []struct {
+		msg    string
+		params gbt.Params
+		metric string
+	}{
+		{"unknown metric:", nil, "unknown"},
+		{"invalid params:", gbt.Params{"short": -1}, "sharpe"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Evaluate(context.Background(), testConfig, tc.params, tc.metric); err == nil {
+			t.Errorf("%v\nEvaluate(): expected an error", tc.msg)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package cluster
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// ErrFinished is returned by Lease after all units are reported.
+var ErrFinished = errors.New("sweep finished")
+
+// Coordinator leases the units of a parameter sweep to the workers and aggregates their scores.
+type Coordinator struct {
+	Timeout time.Duration // time of a worker to report a leased unit, defaults to 5 minutes
+	// Token is the shared secret of the workers, if set requests without the bearer token
+	// are rejected with 401
+	Token string
+
+	mu      sync.Mutex
+	config  config.Config
+	metric  string
+	grid    []gbt.Params
+	units   []*unit
+	scores  []float64
+	pending int
+	err     error
+	workers map[string]time.Time
+	done    chan struct{}
+}
+
+// unit is the state of a unit of the sweep.
+type unit struct {
+	start, end int // range of the grid
+	worker     string
+	leased     time.Time
+	reported   bool
+}
+
+// Status is the progress of a sweep.
+type Status struct {
+	Units    int      `json:"units"`
+	Reported int      `json:"reported"`
+	Leased   int      `json:"leased"`
+	Workers  []string `json:"workers"` // workers seen, in no particular order
+	Error    string   `json:"error,omitempty"`
+}
+
+// NewCoordinator creates the coordinator of the grid search of the config over the parameter space,
+// split into units of batch parameter sets.
+func NewCoordinator(c config.Config, space []gbt.Param, grid *gbt.GridSearch, metric string, batch int) (*Coordinator, error) {
+	if _, ok := Metrics[metric]; !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	if grid == nil {
+		grid = &gbt.GridSearch{}
+	}
+	params, err := grid.Grid(space)
+	if err != nil {
+		return nil, err
+	}
+	if batch <= 0 {
+		batch = 10
+	}
+
+	co := &Coordinator{
+		config:  c,
+		metric:  metric,
+		grid:    params,
+		scores:  make([]float64, len(params)),
+		workers: make(map[string]time.Time),
+		done:    make(chan struct{}),
+	}
+	for start := 0; start < len(params); start += batch {
+		end := start + batch
+		if end > len(params) {
+			end = len(params)
+		}
+		co.units = append(co.units, &unit{start: start, end: end})
+	}
+	co.pending = len(co.units)
+	return co, nil
+}
+
+// Lease returns the next unit for a worker. A unit is leased again after the timeout
+// without a report. Lease returns false if all units are currently leased and ErrFinished
+// after all units are reported.
+func (co *Coordinator) Lease(worker string) (Unit, bool, error) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	co.workers[worker] = time.Now()
+	if co.pending == 0 || co.err != nil {
+		return Unit{}, false, ErrFinished
+	}
+
+	timeout := co.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	for id, u := range co.units {
+		if u.reported || (!u.leased.IsZero() && time.Since(u.leased) < timeout) {
+			continue
+		}
+		u.worker = worker
+		u.leased = time.Now()
+		return Unit{ID: id, Config: co.config, Metric: co.metric, Params: co.grid[u.start:u.end]}, true, nil
+	}
+	return Unit{}, false, nil
+}
+
+// Report records the scores of a unit. Reports of already reported units are ignored,
+// the error of a unit stops the sweep.
+func (co *Coordinator) Report(r UnitResult) error {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	if r.ID < 0 || r.ID >= len(co.units) {
+		return fmt.Errorf("unknown unit %d", r.ID)
+	}
+	u := co.units[r.ID]
+	if u.reported || co.pending == 0 || co.err != nil {
+		return nil
+	}
+
+	if r.Error != "" {
+		co.err = fmt.Errorf("unit %d failed on worker %s: %s", r.ID, r.Worker, r.Error)
+		close(co.done)
+		return nil
+	}
+	if len(r.Scores) != u.end-u.start {
+		return fmt.Errorf("unit %d: expected %d scores, got %d", r.ID, u.end-u.start, len(r.Scores))
+	}
+
+	for i, score := range r.Scores {
+		co.scores[u.start+i] = math.Inf(-1)
+		if score != nil {
+			co.scores[u.start+i] = *score
+		}
+	}
+	u.reported = true
+	co.pending--
+	if co.pending == 0 {
+		close(co.done)
+	}
+	return nil
+}
+
+// Status returns the progress of the sweep.
+func (co *Coordinator) Status() Status {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	status := Status{Units: len(co.units)}
+	for _, u := range co.units {
+		switch {
+		case u.reported:
+			status.Reported++
+		case !u.leased.IsZero():
+			status.Leased++
+		}
+	}
+	for w := range co.workers {
+		status.Workers = append(status.Workers, w)
+	}
+	if co.err != nil {
+		status.Error = co.err.Error()
+	}
+	return status
+}
+
+// Wait blocks until all units are reported and returns the aggregated result in the order
+// of the grid. The result of a cancelled context holds the trials reported so far.
+func (co *Coordinator) Wait(ctx context.Context) (gbt.OptimizationResult, error) {
+	select {
+	case <-co.done:
+	case <-ctx.Done():
+	}
+
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	result := gbt.OptimizationResult{Score: math.Inf(-1)}
+	for _, u := range co.units {
+		if !u.reported {
+			continue
+		}
+		for i := u.start; i < u.end; i++ {
+			score := co.scores[i]
+			result.Trials = append(result.Trials, gbt.Trial{Params: co.grid[i], Score: score})
+			if score > result.Score || result.Best == nil {
+				result.Best = co.grid[i]
+				result.Score = score
+			}
+		}
+	}
+
+	switch {
+	case co.err != nil:
+		return result, co.err
+	case co.pending > 0:
+		result.Stopped = "cancelled"
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (co *Coordinator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !co.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/lease" && r.Method == http.MethodPost:
+		var req struct {
+			Worker string `json:"worker"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		u, ok, err := co.Lease(req.Worker)
+		switch {
+		case errors.Is(err, ErrFinished):
+			writeError(w, http.StatusGone, err)
+		case !ok:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusOK, u)
+		}
+
+	case r.URL.Path == "/report" && r.Method == http.MethodPost:
+		var result UnitResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := co.Report(result); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.URL.Path == "/status" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, co.Status())
+
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// authorized returns if the request carries the token of the coordinator, always true without token.
+func (co *Coordinator) authorized(r *http.Request) bool {
+	if co.Token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(co.Token)) == 1
+}
+
+// writeJSON writes a json response.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
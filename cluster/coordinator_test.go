@@ -0,0 +1,181 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// testSpace is the parameter space of the test sweep with 6 parameter sets.
+var testSpace = []gbt.Param{
+	{Name: "short", Min: 3, Max: 5, Step: 1},
+	{Name: "long", Min: 15, Max: 20, Step: 5},
+}
+
+// scores returns the scores of the params, the short value as score.
+func scores(params []gbt.Params) []*float64 {
+	s := make([]*float64, len(params))
+	for i, p := range params {
+		v := p["short"]
+		s[i] = &v
+	}
+	return s
+}
+
+func TestCoordinatorLease(t *testing.T) {
+	co, err := NewCoordinator(testConfig, testSpace, nil, "sharpe", 4)
+	if err != nil {
+		t.Fatalf("NewCoordinator(): unexpected error %v", err)
+	}
+
+	first, ok, err := co.Lease("a")
+	if !ok || err != nil || first.ID != 0 || len(first.Params) != 4 || first.Metric != "sharpe" {
+		t.Fatalf("Lease(): expected the first unit of 4 params, actual %+v %v %v", first, ok, err)
+	}
+	second, ok, _ := co.Lease("b")
+	if !ok || second.ID != 1 || len(second.Params) != 2 {
+		t.Fatalf("Lease(): expected the second unit of 2 params, actual %+v %v", second, ok)
+	}
+	if _, ok, err := co.Lease("c"); ok || err != nil {
+		t.Errorf("Lease(): expected no unit while all are leased, actual %v %v", ok, err)
+	}
+
+	// the lease of the first unit expires
+	co.Timeout = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	again, ok, _ := co.Lease("c")
+	if !ok || again.ID != 0 && again.ID != 1 {
+		t.Fatalf("Lease(): expected an expired unit to be leased again, actual %+v %v", again, ok)
+	}
+	co.Timeout = time.Hour
+
+	for _, u := range []Unit{first, second, first} {
+		if err := co.Report(UnitResult{ID: u.ID, Worker: "a", Scores: scores(u.Params)}); err != nil {
+			t.Fatalf("Report(): unexpected error %v", err)
+		}
+	}
+	if _, _, err := co.Lease("a"); !errors.Is(err, ErrFinished) {
+		t.Errorf("Lease(): expected ErrFinished, actual %v", err)
+	}
+	if status := co.Status(); status.Units != 2 || status.Reported != 2 || len(status.Workers) != 3 {
+		t.Errorf("Status(): unexpected status %+v", status)
+	}
+
+	result, err := co.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait(): unexpected error %v", err)
+	}
+	grid, _ := (&gbt.GridSearch{}).Grid(testSpace)
+	if len(result.Trials) != len(grid) {
+		t.Fatalf("Wait(): expected %v trials, actual %v", len(grid), len(result.Trials))
+	}
+	for i, trial := range result.Trials {
+		if !reflect.DeepEqual(trial.Params, grid[i]) {
+			t.Errorf("Wait(): expected trial %v with params %v, actual %v", i, grid[i], trial.Params)
+		}
+	}
+	if result.Score != 5 || result.Best["short"] != 5 || result.Best["long"] != 15 {
+		t.Errorf("Wait(): expected best short 5 long 15, actual %v %v", result.Best, result.Score)
+	}
+}
+
+func TestCoordinatorReport(t *testing.T) {
+	co, _ := NewCoordinator(testConfig, testSpace, nil, "sharpe", 4)
+
+	// testCases is a table for testing the errors of a report
+	var testCases = []struct {
+		msg    string
+		result UnitResult
+	}{
+		{"unknown unit:", UnitResult{ID: 5}},
+		{"missing scores:", UnitResult{ID: 0, Scores: make([]*float64, 1)}},
+	}
+
+	for _, tc := range testCases {
+		if err := co.Report(tc.result); err == nil {
+			t.Errorf("%v\nReport(): expected an error", tc.msg)
+		}
+	}
+
+	// undefined scores are the lowest scores
+	co.Report(UnitResult{ID: 0, Scores: make([]*float64, 4)})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := co.Wait(ctx)
+	if err != context.Canceled || result.Stopped != "cancelled" {
+		t.Errorf("Wait(): expected a cancelled sweep, actual %v %q", err, result.Stopped)
+	}
+	if len(result.Trials) != 4 || !math.IsInf(result.Trials[0].Score, -1) {
+		t.Errorf("Wait(): expected the 4 reported trials with undefined scores, actual %v", result.Trials)
+	}
+
+	// a failed unit stops the sweep
+	co.Report(UnitResult{ID: 1, Worker: "a", Error: "no data"})
+	if _, err := co.Wait(context.Background()); err == nil {
+		t.Errorf("Wait(): expected the error of the failed unit")
+	}
+	if _, _, err := co.Lease("a"); !errors.Is(err, ErrFinished) {
+		t.Errorf("Lease(): expected ErrFinished after a failed unit, actual %v", err)
+	}
+
+	if _, err := NewCoordinator(testConfig, testSpace, nil, "unknown", 4); err == nil {
+		t.Errorf("NewCoordinator(): expected an error for an unknown metric")
+	}
+}
+
+func TestCoordinatorToken(t *testing.T) {
+	co, err := NewCoordinator(testConfig, testSpace, nil, "sharpe", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co.Token = "secret"
+	ts := httptest.NewServer(co)
+	defer ts.Close()
+
+	// testCases is a table for testing the authentication of the workers
+	var testCases = []struct {
+		msg     string
+		method  string
+		path    string
+		auth    string
+		body    string
+		expCode int
+	}{
+		{"report without token:", http.MethodPost, "/report", "", `{"id": 0, "scores": [1, 1, 1, 1]}`, http.StatusUnauthorized},
+		{"report with wrong token:", http.MethodPost, "/report", "Bearer wrong", `{"id": 0, "scores": [1, 1, 1, 1]}`, http.StatusUnauthorized},
+		{"status without token:", http.MethodGet, "/status", "", "", http.StatusUnauthorized},
+		{"token without bearer scheme:", http.MethodGet, "/status", "secret", "", http.StatusOK},
+		{"lease with token:", http.MethodPost, "/lease", "Bearer secret", `{"worker": "a"}`, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(tc.method, ts.URL+tc.path, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.expCode {
+			t.Errorf("%v %v %v: expected status %v, actual %v", tc.msg, tc.method, tc.path, tc.expCode, resp.StatusCode)
+		}
+	}
+
+	// the rejected reports are not booked
+	if status := co.Status(); status.Reported != 0 {
+		t.Errorf("Status(): expected no reported unit, actual %+v", status)
+	}
+}
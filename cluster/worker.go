@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnauthorized is returned by Run if the coordinator rejects the token of the worker.
+var ErrUnauthorized = errors.New("unauthorized by the coordinator")
+
+// Worker leases units from a coordinator, evaluates them and reports the scores,
+// until the sweep is finished or the context is cancelled.
+type Worker struct {
+	URL     string // base url of the coordinator
+	Name    string // defaults to the host name and process id
+	Token   string // shared secret of the coordinator, if set
+	DataDir string // if set, relative data directories of the config are resolved within it
+	// Parallel sets the backtests of a unit running at the same time, defaults to the number of CPUs
+	Parallel int
+	// Poll sets the wait before the next lease, if no unit is available or the coordinator
+	// is not reachable, defaults to 1 second
+	Poll   time.Duration
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// Run works on the units of the coordinator. Failed requests to the coordinator are retried,
+// so workers can be started before the coordinator, a rejected token stops the worker.
+func (w *Worker) Run(ctx context.Context) error {
+	if w.Name == "" {
+		host, _ := os.Hostname()
+		w.Name = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	poll := w.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+
+	for {
+		u, status, err := w.lease(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case status == http.StatusGone:
+			return nil
+		case status == http.StatusUnauthorized:
+			return ErrUnauthorized
+		case err == nil && status == http.StatusOK:
+			result := w.evaluate(ctx, u)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// retry the report, the scores are lost otherwise
+			for {
+				err := w.report(ctx, result)
+				if err == nil {
+					break
+				}
+				if errors.Is(err, ErrUnauthorized) {
+					return err
+				}
+				if err := sleep(ctx, poll); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := sleep(ctx, poll); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for the duration or until the context is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// evaluate runs the backtests of a unit.
+func (w *Worker) evaluate(ctx context.Context, u Unit) UnitResult {
+	result := UnitResult{ID: u.ID, Worker: w.Name, Scores: make([]*float64, len(u.Params))}
+
+	c := u.Config
+	if w.DataDir != "" && !filepath.IsAbs(c.Data.Dir) {
+		c.Data.Dir = filepath.Join(w.DataDir, c.Data.Dir) + string(filepath.Separator)
+	}
+
+	parallel := w.Parallel
+	if parallel <= 0 {
+		parallel = runtime.NumCPU()
+	}
+	slots := make(chan struct{}, parallel)
+	errs := make([]error, len(u.Params))
+
+	var wg sync.WaitGroup
+	for i := range u.Params {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			score, err := Evaluate(ctx, c, u.Params[i], u.Metric)
+			if err != nil {
+				errs[i] = fmt.Errorf("params %v: %v", u.Params[i], err)
+				return
+			}
+			if !math.IsNaN(score) && !math.IsInf(score, 0) {
+				result.Scores[i] = &score
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var messages []string
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		result.Scores = nil
+		result.Error = strings.Join(messages, "; ")
+	}
+	return result
+}
+
+// lease requests the next unit of the coordinator.
+func (w *Worker) lease(ctx context.Context) (Unit, int, error) {
+	var u Unit
+	resp, err := w.post(ctx, "/lease", map[string]string{"worker": w.Name})
+	if err != nil {
+		return u, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		err = json.NewDecoder(resp.Body).Decode(&u)
+	}
+	return u, resp.StatusCode, err
+}
+
+// report sends the scores of a unit to the coordinator.
+func (w *Worker) report(ctx context.Context, result UnitResult) error {
+	resp, err := w.post(ctx, "/report", result)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("report of unit %d: unexpected status %s", result.ID, resp.Status)
+	}
+	return nil
+}
+
+// post sends v as json to the path of the coordinator.
+func (w *Worker) post(ctx context.Context, path string, v interface{}) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(w.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
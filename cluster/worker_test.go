@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestWorkerRun(t *testing.T) {
+	co, err := NewCoordinator(testConfig, testSpace, nil, "return", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(co)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			w := &Worker{URL: ts.URL, Name: name, Parallel: 2, Poll: 10 * time.Millisecond}
+			errs[i] = w.Run(ctx)
+		}(i, name)
+	}
+
+	result, err := co.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait(): unexpected error %v", err)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Run(): expected the workers to stop after the sweep, actual %v", err)
+		}
+	}
+
+	// the distributed sweep equals the local grid search
+	local, _ := (&gbt.GridSearch{}).Optimize(testSpace, func(p gbt.Params) (float64, error) {
+		return Evaluate(ctx, testConfig, p, "return")
+	})
+	if len(result.Trials) != len(local.Trials) {
+		t.Fatalf("Wait(): expected %v trials, actual %v", len(local.Trials), len(result.Trials))
+	}
+	for i := range local.Trials {
+		if result.Trials[i].Score != local.Trials[i].Score {
+			t.Errorf("Wait(): expected trial %v to be %v, actual %v", i, local.Trials[i], result.Trials[i])
+		}
+	}
+
+	var status Status
+	resp, err := http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Units != 3 || status.Reported != 3 || len(status.Workers) == 0 {
+		t.Errorf("GET /status: unexpected status %+v", status)
+	}
+}
+
+func TestWorkerError(t *testing.T) {
+	c := testConfig
+	c.Data.Dir = "missing/"
+	co, _ := NewCoordinator(c, testSpace, nil, "return", 10)
+	ts := httptest.NewServer(co)
+	defer ts.Close()
+
+	w := &Worker{URL: ts.URL, Name: "a", Poll: 10 * time.Millisecond}
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if _, err := co.Wait(context.Background()); err == nil {
+		t.Errorf("Wait(): expected the error of the missing data")
+	}
+
+	// a worker retries until the context is cancelled, if the coordinator is not reachable
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w = &Worker{URL: "http://127.0.0.1:1", Name: "b", Poll: 10 * time.Millisecond}
+	if err := w.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Run(): expected the error of the context, actual %v", err)
+	}
+}
+
+func TestWorkerToken(t *testing.T) {
+	co, err := NewCoordinator(testConfig, testSpace, nil, "return", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co.Token = "secret"
+	ts := httptest.NewServer(co)
+	defer ts.Close()
+
+	// a worker with the wrong token stops instead of polling
+	w := &Worker{URL: ts.URL, Name: "a", Token: "wrong", Poll: 10 * time.Millisecond}
+	if err := w.Run(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Run(): expected ErrUnauthorized, actual %v", err)
+	}
+
+	w = &Worker{URL: ts.URL, Name: "b", Token: "secret", Parallel: 2, Poll: 10 * time.Millisecond}
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if _, err := co.Wait(context.Background()); err != nil {
+		t.Errorf("Wait(): unexpected error %v", err)
+	}
+}
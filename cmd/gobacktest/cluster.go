@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dirkolbrich/gobacktest/cluster"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// linger is the time the coordinator keeps serving after the sweep is finished.
+var linger = 2 * time.Second
+
+// coordinateCommand distributes the grid search of a configuration file to the workers.
+func coordinateCommand(ctx context.Context, args []string, out io.Writer) error {
+	var params paramFlags
+
+	flags := flag.NewFlagSet("coordinate", flag.ContinueOnError)
+	addr := flags.String("addr", ":9090", "address to listen on for the workers")
+	token := flags.String("token", os.Getenv("GOBACKTEST_TOKEN"), "shared secret of the workers, defaults to $GOBACKTEST_TOKEN")
+	flags.Var(&params, "param", "range of a strategy parameter as name=min:max:step, repeatable")
+	metric := flags.String("metric", "sharpe", "score to maximise: sharpe, sortino, return or drawdown")
+	batch := flags.Int("batch", 10, "number of parameter sets of a work unit")
+	timeout := flags.Duration("timeout", 5*time.Minute, "time of a worker to report a unit, before it is leased again")
+	top := flags.Int("top", 10, "number of the best trials to print")
+	file := flags.String("out", "", "csv file for all trials, none is written if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a single config file")
+	}
+	if len(params) == 0 {
+		return errors.New("expected at least one -param")
+	}
+
+	c, err := config.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	co, err := cluster.NewCoordinator(c, params, nil, *metric, *batch)
+	if err != nil {
+		return err
+	}
+	co.Timeout = *timeout
+	co.Token = *token
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var serveErr error
+	served := make(chan struct{})
+	srv := &http.Server{Addr: *addr, Handler: co}
+	go func() {
+		defer close(served)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr = err
+			cancel()
+		}
+	}()
+	fmt.Fprintf(out, "coordinating %d units on %s\n", co.Status().Units, *addr)
+
+	result, err := co.Wait(waitCtx)
+	if err == nil {
+		// give the idle workers a poll to learn that the sweep is finished
+		time.Sleep(linger)
+	}
+	shutdown, stop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stop()
+	srv.Shutdown(shutdown)
+	<-served
+
+	switch {
+	case serveErr != nil:
+		return serveErr
+	case err != nil && !errors.Is(err, context.Canceled):
+		return err
+	}
+	return printResult(out, result, params, *metric, *top, *file)
+}
+
+// workCommand evaluates the units of a coordinator until its sweep is finished.
+func workCommand(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("work", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the worker, defaults to the host name and process id")
+	token := flags.String("token", os.Getenv("GOBACKTEST_TOKEN"), "shared secret of the coordinator, defaults to $GOBACKTEST_TOKEN")
+	dataDir := flags.String("data", "", "directory of the data, relative data directories of the config are resolved within it")
+	parallel := flags.Int("parallel", 0, "number of backtests running at the same time, defaults to the number of CPUs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected the url of the coordinator")
+	}
+
+	w := &cluster.Worker{URL: flags.Arg(0), Name: *name, Token: *token, DataDir: *dataDir, Parallel: *parallel}
+	fmt.Fprintf(out, "working for %s\n", w.URL)
+	return w.Run(ctx)
+}
@@ -0,0 +1,85 @@
+// Command gobacktest runs backtests defined in configuration files.
+//
+// Usage:
+//
+//	gobacktest run [-out dir] [-arrow] config.toml
+//	gobacktest optimize [-param name=min:max:step]... [-metric sharpe] [-top 10] [-out trials.csv] config.toml
+//	gobacktest report [-csv fills.csv] events.jsonl
+//	gobacktest serve [-addr localhost:8080] [-data dir] [-workers n]
+//	gobacktest coordinate [-addr :9090] [-token secret] [-param name=min:max:step]... [-metric sharpe] [-batch 10] [-out trials.csv] config.toml
+//	gobacktest work [-token secret] [-data dir] [-parallel n] http://coordinator:9090
+//
+// The run command prints a summary of the backtest and writes the event stream, the trade blotter,
+// the daily returns and the audit trail into the report directory. The optimize command searches
+// the grid of the strategy parameters and prints the best trials. The report command summarizes an
+// event stream written by a run. The serve command runs the REST API of the server package. The
+// coordinate command distributes the grid search of optimize to work commands on other machines,
+// which authenticate with the shared token of -token or the environment variable GOBACKTEST_TOKEN.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// command is a sub command of the tool.
+type command struct {
+	run   func(ctx context.Context, args []string, out io.Writer) error
+	usage string
+}
+
+var commands = map[string]command{
+	"run":        {runCommand, "run a backtest and write its reports"},
+	"optimize":   {optimizeCommand, "search the best strategy parameters"},
+	"report":     {reportCommand, "summarize the event stream of a run"},
+	"serve":      {serveCommand, "serve the REST API to run backtests"},
+	"coordinate": {coordinateCommand, "distribute the parameter search to workers"},
+	"work":       {workCommand, "evaluate the parameter search of a coordinator"},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(execute(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// execute runs the sub command of the arguments and returns the exit code.
+func execute(ctx context.Context, args []string, out, errOut io.Writer) int {
+	if len(args) == 0 {
+		usage(errOut)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(errOut, "gobacktest: unknown command %q\n", args[0])
+		usage(errOut)
+		return 2
+	}
+
+	if err := cmd.run(ctx, args[1:], out); err != nil {
+		fmt.Fprintf(errOut, "gobacktest %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the available commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gobacktest <command> [flags] <file>")
+	fmt.Fprintln(w, "\ncommands:")
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
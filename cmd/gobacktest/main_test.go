@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+var testConfig = `
+symbols = ["SDF.DE"]
+seed = 1
+
+[data]
+dir = "../../examples/testdata/bar/"
+start = "2016-01-01"
+end = "2017-06-30"
+
+[strategy]
+name = "moving-average-cross"
+params = { short = 5, long = 20 }
+`
+
+// testDir creates a directory with the test config.
+func testDir(t *testing.T) (dir, config string) {
+	dir, err := ioutil.TempDir("", "gobacktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = filepath.Join(dir, "backtest.toml")
+	if err := ioutil.WriteFile(config, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, config
+}
+
+func TestExecute(t *testing.T) {
+	// testCases is a table for testing the exit codes of the commands
+	var testCases = []struct {
+		msg     string
+		args    []string
+		expCode int
+		expErr  string
+	}{
+		{"no command:", nil, 2, "usage"},
+		{"unknown command:", []string{"deploy"}, 2, "unknown command"},
+		{"run without config:", []string{"run"}, 1, "expected a single config file"},
+		{"run with missing config:", []string{"run", "missing.toml"}, 1, "missing.toml"},
+		{"optimize without param:", []string{"optimize", "config.toml"}, 1, "expected at least one -param"},
+		{"report without file:", []string{"report"}, 1, "expected a single events file"},
+	}
+
+	for _, tc := range testCases {
+		var out, errOut bytes.Buffer
+		code := execute(context.Background(), tc.args, &out, &errOut)
+		if code != tc.expCode || !strings.Contains(errOut.String(), tc.expErr) {
+			t.Errorf("%v execute(): expected code %v with %q, actual %v with %q", tc.msg, tc.expCode, tc.expErr, code, errOut.String())
+		}
+	}
+}
+
+func TestRunAndReport(t *testing.T) {
+	dir, config := testDir(t)
+	defer os.RemoveAll(dir)
+	reports := filepath.Join(dir, "reports")
+
+	var out, errOut bytes.Buffer
+	if code := execute(context.Background(), []string{"run", "-out", reports, "-arrow", config}, &out, &errOut); code != 0 {
+		t.Fatalf("run: expected code 0, actual %v: %v", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "moving-average-cross") || !strings.Contains(out.String(), "Sharpe") {
+		t.Errorf("run: expected a summary, actual %v", out.String())
+	}
+	for _, name := range []string{"events.jsonl", "audit.csv", "blotter.csv", "returns.csv", "charts.html", "equity.arrow", "trades.arrow"} {
+		if _, err := os.Stat(filepath.Join(reports, name)); err != nil {
+			t.Errorf("run: expected report %v, actual %v", name, err)
+		}
+	}
+	if charts, _ := ioutil.ReadFile(filepath.Join(reports, "charts.html")); strings.Count(string(charts), "<svg ") != 3 || !strings.Contains(string(charts), "SDF.DE") {
+		t.Errorf("run: expected the equity, drawdown and price charts of SDF.DE")
+	}
+
+	out.Reset()
+	fills := filepath.Join(dir, "fills.csv")
+	args := []string{"report", "-csv", fills, filepath.Join(reports, "events.jsonl")}
+	if code := execute(context.Background(), args, &out, &errOut); code != 0 {
+		t.Fatalf("report: expected code 0, actual %v: %v", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "SDF.DE") || !strings.Contains(out.String(), "fill") {
+		t.Errorf("report: expected the fills of SDF.DE, actual %v", out.String())
+	}
+	content, err := ioutil.ReadFile(fills)
+	if err != nil || !strings.HasPrefix(string(content), "Time,Symbol,ID,Direction") {
+		t.Errorf("report: expected the fills csv, actual %q %v", content, err)
+	}
+}
+
+func TestRunNotify(t *testing.T) {
+	var kinds []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m struct{ Kind string }
+		json.NewDecoder(r.Body).Decode(&m)
+		kinds = append(kinds, m.Kind)
+	}))
+	defer ts.Close()
+
+	dir, config := testDir(t)
+	defer os.RemoveAll(dir)
+	content := testConfig + "\n[notify]\nwebhook = \"" + ts.URL + "\"\nevents = [\"done\"]\n"
+	if err := ioutil.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	if code := execute(context.Background(), []string{"run", config}, &out, &errOut); code != 0 {
+		t.Fatalf("run: expected code 0, actual %v: %v", code, errOut.String())
+	}
+	if len(kinds) != 1 || kinds[0] != "done" {
+		t.Errorf("run: expected a single done notification, actual %v", kinds)
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	dir, config := testDir(t)
+	defer os.RemoveAll(dir)
+	trials := filepath.Join(dir, "trials.csv")
+
+	var out, errOut bytes.Buffer
+	args := []string{"optimize", "-param", "short=5:10:5", "-param", "long=20:30:10", "-metric", "return", "-top", "2", "-out", trials, config}
+	if code := execute(context.Background(), args, &out, &errOut); code != 0 {
+		t.Fatalf("optimize: expected code 0, actual %v: %v", code, errOut.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "return") {
+		t.Errorf("optimize: expected a header and the top 2 trials, actual %v", out.String())
+	}
+	content, _ := ioutil.ReadFile(trials)
+	if rows := strings.Split(strings.TrimSpace(string(content)), "\n"); len(rows) != 5 || rows[0] != "short,long,Score" {
+		t.Errorf("optimize: expected a header and 4 trials, actual %q", content)
+	}
+}
+
+func TestCoordinate(t *testing.T) {
+	dir, config := testDir(t)
+	defer os.RemoveAll(dir)
+	linger = 200 * time.Millisecond
+
+	// reserve a free port for the coordinator
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	workCode := make(chan int)
+	var workOut bytes.Buffer
+	go func() {
+		workCode <- execute(ctx, []string{"work", "-name", "test", "http://" + addr}, &workOut, &workOut)
+	}()
+
+	var out, errOut bytes.Buffer
+	args := []string{"coordinate", "-addr", addr, "-param", "short=5:10:5", "-param", "long=20:30:10", "-metric", "return", "-batch", "3", "-top", "2", config}
+	if code := execute(ctx, args, &out, &errOut); code != 0 {
+		t.Fatalf("coordinate: expected code 0, actual %v: %v", code, errOut.String())
+	}
+	if code := <-workCode; code != 0 {
+		t.Errorf("work: expected code 0, actual %v: %v", code, workOut.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || lines[0] != "coordinating 2 units on "+addr || !strings.Contains(lines[1], "return") {
+		t.Errorf("coordinate: expected the units, a header and the top 2 trials, actual %v", out.String())
+	}
+}
+
+func TestParamFlags(t *testing.T) {
+	// testCases is a table for testing the parsing of parameter ranges
+	var testCases = []struct {
+		value    string
+		expParam gbt.Param
+		expErr   bool
+	}{
+		{"short=5:50:5", gbt.Param{Name: "short", Min: 5, Max: 50, Step: 5}, false},
+		{"rate=0.1:0.5", gbt.Param{Name: "rate", Min: 0.1, Max: 0.5}, false},
+		{"short=5", gbt.Param{}, true},
+		{"=1:2", gbt.Param{}, true},
+		{"short=a:b", gbt.Param{}, true},
+		{"short=1:2:3:4", gbt.Param{}, true},
+	}
+
+	for _, tc := range testCases {
+		var params paramFlags
+		err := params.Set(tc.value)
+		if (err != nil) != tc.expErr {
+			t.Errorf("Set(%v): expected error %v, actual %v", tc.value, tc.expErr, err)
+			continue
+		}
+		if !tc.expErr && !reflect.DeepEqual(params[0], tc.expParam) {
+			t.Errorf("Set(%v): expected %+v, actual %+v", tc.value, tc.expParam, params[0])
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/cluster"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// paramFlags collects the repeated -param flags.
+type paramFlags []gbt.Param
+
+func (p *paramFlags) String() string {
+	var s []string
+	for _, param := range *p {
+		s = append(s, fmt.Sprintf("%s=%v:%v:%v", param.Name, param.Min, param.Max, param.Step))
+	}
+	return strings.Join(s, ",")
+}
+
+// Set parses a parameter range as name=min:max or name=min:max:step.
+func (p *paramFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid param %q, expected name=min:max:step", value)
+	}
+
+	bounds := strings.Split(parts[1], ":")
+	if len(bounds) < 2 || len(bounds) > 3 {
+		return fmt.Errorf("invalid param %q, expected name=min:max:step", value)
+	}
+	var values []float64
+	for _, b := range bounds {
+		f, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return fmt.Errorf("invalid param %q: %v", value, err)
+		}
+		values = append(values, f)
+	}
+
+	param := gbt.Param{Name: parts[0], Min: values[0], Max: values[1]}
+	if len(values) == 3 {
+		param.Step = values[2]
+	}
+	*p = append(*p, param)
+	return nil
+}
+
+// optimizeCommand searches the grid of the strategy parameters of a configuration file.
+func optimizeCommand(ctx context.Context, args []string, out io.Writer) error {
+	var params paramFlags
+
+	flags := flag.NewFlagSet("optimize", flag.ContinueOnError)
+	flags.Var(&params, "param", "range of a strategy parameter as name=min:max:step, repeatable")
+	metric := flags.String("metric", "sharpe", "score to maximise: sharpe, sortino, return or drawdown")
+	top := flags.Int("top", 10, "number of the best trials to print")
+	file := flags.String("out", "", "csv file for all trials, none is written if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a single config file")
+	}
+	if len(params) == 0 {
+		return errors.New("expected at least one -param")
+	}
+	if _, ok := cluster.Metrics[*metric]; !ok {
+		return fmt.Errorf("unknown metric %q", *metric)
+	}
+
+	c, err := config.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	objective := func(p gbt.Params) (float64, error) {
+		return cluster.Evaluate(ctx, c, p, *metric)
+	}
+
+	optimizer := &gbt.GridSearch{Budget: gbt.Budget{Context: ctx}}
+	result, err := optimizer.Optimize(params, objective)
+	if err != nil {
+		return err
+	}
+
+	return printResult(out, result, params, *metric, *top, *file)
+}
+
+// printResult prints the best trials of an optimization and writes all trials into the file.
+func printResult(out io.Writer, result gbt.OptimizationResult, params []gbt.Param, metric string, top int, file string) error {
+	names := paramNames(params)
+	printTrials(out, names, metric, result.Top(top))
+	if result.Stopped != "" {
+		fmt.Fprintf(out, "\noptimization stopped: %s\n", result.Stopped)
+	}
+
+	if file == "" {
+		return nil
+	}
+	return writeFile(file, func(w io.Writer) error {
+		return writeTrials(w, names, result.Trials)
+	})
+}
+
+// paramNames returns the names of the parameters in their order.
+func paramNames(params []gbt.Param) []string {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// printTrials prints the trials as table.
+func printTrials(w io.Writer, names []string, metric string, trials []gbt.Trial) {
+	for _, name := range names {
+		fmt.Fprintf(w, "%12s", name)
+	}
+	fmt.Fprintf(w, " %12s\n", metric)
+
+	for _, trial := range trials {
+		for _, name := range names {
+			fmt.Fprintf(w, "%12v", trial.Params[name])
+		}
+		fmt.Fprintf(w, " %12.4f\n", trial.Score)
+	}
+}
+
+// writeTrials writes all trials as csv.
+func writeTrials(w io.Writer, names []string, trials []gbt.Trial) error {
+	writer := csv.NewWriter(w)
+
+	header := append(append([]string{}, names...), "Score")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, trial := range trials {
+		var record []string
+		for _, name := range names {
+			record = append(record, strconv.FormatFloat(trial.Params[name], 'f', -1, 64))
+		}
+		record = append(record, strconv.FormatFloat(trial.Score, 'f', gbt.DP, 64))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// symbolReport sums up the fills of a symbol.
+type symbolReport struct {
+	fills  int
+	bought float64
+	sold   float64
+	cost   float64
+}
+
+// reportCommand summarizes an event stream written by a run.
+func reportCommand(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	file := flags.String("csv", "", "csv file for all fills, none is written if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a single events file")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	records, err := gbt.ReadEvents(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	printReport(out, records)
+
+	if *file == "" {
+		return nil
+	}
+	return writeFile(*file, func(w io.Writer) error {
+		return writeFills(w, records)
+	})
+}
+
+// printReport prints the events by type, the fills by symbol and the rejections by reason.
+func printReport(w io.Writer, records []gbt.EventRecord) {
+	types := map[string]int{}
+	symbols := map[string]*symbolReport{}
+	reasons := map[string]int{}
+
+	for _, r := range records {
+		types[r.Type]++
+		switch r.Type {
+		case "fill":
+			s, ok := symbols[r.Symbol]
+			if !ok {
+				s = &symbolReport{}
+				symbols[r.Symbol] = s
+			}
+			s.fills++
+			s.cost += r.Values["cost"]
+			if r.Direction == gbt.BOT {
+				s.bought += r.Values["qty"]
+			} else {
+				s.sold += r.Values["qty"]
+			}
+		case "rejection":
+			reasons[r.Reason]++
+		}
+	}
+
+	if len(records) > 0 {
+		fmt.Fprintf(w, "%d events from %s to %s\n", len(records),
+			records[0].Time.Format("2006-01-02"), records[len(records)-1].Time.Format("2006-01-02"))
+	}
+
+	fmt.Fprintf(w, "\n%-12s %8s\n", "Type", "Count")
+	for _, t := range sortedKeys(types) {
+		fmt.Fprintf(w, "%-12s %8d\n", t, types[t])
+	}
+
+	fmt.Fprintf(w, "\n%-12s %8s %12s %12s %12s\n", "Symbol", "Fills", "Bought", "Sold", "Cost")
+	var names []string
+	for name := range symbols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := symbols[name]
+		fmt.Fprintf(w, "%-12s %8d %12.2f %12.2f %12.2f\n", name, s.fills, s.bought, s.sold, s.cost)
+	}
+
+	if len(reasons) > 0 {
+		fmt.Fprintf(w, "\n%-40s %8s\n", "Rejection", "Count")
+		for _, reason := range sortedKeys(reasons) {
+			fmt.Fprintf(w, "%-40s %8d\n", reason, reasons[reason])
+		}
+	}
+}
+
+// sortedKeys returns the keys of a count map in alphabetical order.
+func sortedKeys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeFills writes the fills of an event stream as csv.
+func writeFills(w io.Writer, records []gbt.EventRecord) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Time", "Symbol", "ID", "Direction", "Qty", "Price", "Commission", "ExchangeFee", "Cost"})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if r.Type != "fill" {
+			continue
+		}
+		err := writer.Write([]string{
+			r.Time.Format(time.RFC3339),
+			r.Symbol,
+			strconv.Itoa(r.ID),
+			r.Direction.String(),
+			strconv.FormatFloat(r.Values["qty"], 'f', -1, 64),
+			strconv.FormatFloat(r.Values["price"], 'f', -1, 64),
+			strconv.FormatFloat(r.Values["commission"], 'f', -1, 64),
+			strconv.FormatFloat(r.Values["exchangeFee"], 'f', -1, 64),
+			strconv.FormatFloat(r.Values["cost"], 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
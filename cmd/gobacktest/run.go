@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/arrow"
+	"github.com/dirkolbrich/gobacktest/chart"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// runCommand runs the backtest of a configuration file.
+func runCommand(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	dir := flags.String("out", "", "directory for the reports, none are written if empty")
+	feather := flags.Bool("arrow", false, "write the equity curve and trades also as Arrow IPC files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a single config file")
+	}
+
+	c, err := config.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	test, err := c.Build()
+	if err != nil {
+		return err
+	}
+	audit := gbt.NewAuditLog()
+	test.SetAudit(audit)
+
+	notifier, err := c.Notify.Notifier()
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		notifier.OnError = func(err error) { fmt.Fprintf(out, "notify: %v\n", err) }
+		test.Use(notifier.Middleware())
+	}
+
+	start := time.Now()
+	err = test.RunContext(ctx)
+	duration := time.Since(start)
+	if notifier != nil {
+		notifier.Done(c.Strategy.Name, test.Stats(), err)
+	}
+	if err != nil {
+		return err
+	}
+
+	printSummary(out, c.Strategy.Name, test.Stats(), duration)
+
+	if *dir == "" {
+		return nil
+	}
+	if err := writeReports(*dir, test, audit, *feather); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nreports written to %s\n", *dir)
+	return nil
+}
+
+// printSummary prints the key figures of a backtest.
+func printSummary(w io.Writer, name string, stats gbt.StatisticHandler, duration time.Duration) {
+	ret, _ := stats.TotalEquityReturn()
+
+	fmt.Fprintf(w, "%-20s %s\n", "Strategy", name)
+	fmt.Fprintf(w, "%-20s %d\n", "Events", len(stats.Events()))
+	fmt.Fprintf(w, "%-20s %d\n", "Trades", len(stats.Transactions()))
+	fmt.Fprintf(w, "%-20s %.4f\n", "Return", ret)
+	fmt.Fprintf(w, "%-20s %.4f\n", "Max drawdown", stats.MaxDrawdown())
+	fmt.Fprintf(w, "%-20s %v\n", "Drawdown duration", stats.MaxDrawdownDuration())
+	fmt.Fprintf(w, "%-20s %.4f\n", "Sharpe", stats.SharpRatio(0))
+	fmt.Fprintf(w, "%-20s %.4f\n", "Sortino", stats.SortinoRatio(0))
+	if m, ok := stats.(gbt.CustomMetricer); ok {
+		metrics := m.CustomMetrics()
+		for _, name := range gbt.RegisteredMetrics() {
+			if v, ok := metrics[name]; ok {
+				fmt.Fprintf(w, "%-20s %.4f\n", name, v)
+			}
+		}
+	}
+	fmt.Fprintf(w, "%-20s %v\n", "Duration", duration.Round(time.Millisecond))
+}
+
+// writeReports writes the event stream, trade blotter, daily returns, charts and audit trail
+// into the directory, the equity curve and trades as Arrow IPC files if requested.
+func writeReports(dir string, test *gbt.Backtest, audit *gbt.AuditLog, feather bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	err := writeFile(filepath.Join(dir, "events.jsonl"), func(w io.Writer) error {
+		return gbt.WriteEvents(w, test.Stats().Events())
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeFile(filepath.Join(dir, "audit.csv"), audit.WriteCSV)
+	if err != nil {
+		return err
+	}
+
+	if stats, ok := test.Stats().(*gbt.Statistic); ok {
+		err = writeFile(filepath.Join(dir, "blotter.csv"), func(w io.Writer) error {
+			return writeBlotter(w, stats.Blotter())
+		})
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(filepath.Join(dir, "returns.csv"), func(w io.Writer) error {
+			return gbt.WriteReturnsCSV(w, stats.DatedDailyReturns(), nil)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(filepath.Join(dir, "charts.html"), func(w io.Writer) error {
+			return writeCharts(w, stats)
+		})
+		if err != nil {
+			return err
+		}
+
+		if feather {
+			if err := arrow.EquityCurve(stats.EquityCurve()).WriteFile(filepath.Join(dir, "equity.arrow")); err != nil {
+				return err
+			}
+			if err := arrow.Trades(stats.Blotter()).WriteFile(filepath.Join(dir, "trades.arrow")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeCharts writes the charts of the equity curve, the drawdowns and the price of each traded
+// symbol with its trades as HTML page.
+func writeCharts(w io.Writer, stats *gbt.Statistic) error {
+	var stream []gbt.DataEvent
+	for _, e := range stats.Events() {
+		if d, ok := e.(gbt.DataEvent); ok {
+			stream = append(stream, d)
+		}
+	}
+
+	charts := []*chart.Chart{chart.Equity(stats), chart.Underwater(stats)}
+	traded := make(map[string]bool)
+	for _, f := range stats.Transactions() {
+		if !traded[f.Symbol()] {
+			traded[f.Symbol()] = true
+			charts = append(charts, chart.Price(f.Symbol(), stream, stats.Transactions()))
+		}
+	}
+	return chart.WriteHTML(w, "Backtest charts", charts...)
+}
+
+// writeFile creates a file and writes its content.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeBlotter writes the trade blotter as csv.
+func writeBlotter(w io.Writer, blotter []gbt.BlotterEntry) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Time", "Symbol", "Direction", "Qty", "Price", "Cost", "FillID", "OrderID", "SignalID", "DataID"})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range blotter {
+		err := writer.Write([]string{
+			e.Time.Format(time.RFC3339),
+			e.Symbol,
+			e.Direction.String(),
+			strconv.FormatFloat(e.Qty, 'f', -1, 64),
+			strconv.FormatFloat(e.Price, 'f', -1, 64),
+			strconv.FormatFloat(e.Cost, 'f', -1, 64),
+			strconv.Itoa(e.FillID),
+			strconv.Itoa(e.OrderID),
+			strconv.Itoa(e.SignalID),
+			strconv.Itoa(e.DataID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
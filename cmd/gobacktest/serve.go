@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dirkolbrich/gobacktest/metrics"
+	"github.com/dirkolbrich/gobacktest/server"
+)
+
+// serveCommand runs the REST API server with its metrics until the context is cancelled.
+func serveCommand(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	dataDir := flags.String("data", "", "directory of the data, the data directories of the jobs are resolved within it")
+	workers := flags.Int("workers", 0, "number of jobs running at the same time, defaults to the number of CPUs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	s := server.New()
+	s.DataDir = *dataDir
+	s.Workers = *workers
+	s.Metrics = metrics.NewExporter()
+	defer s.Close()
+
+	srv := &http.Server{Addr: *addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(out, "listening on %s\n", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package gobacktest
+
+import (
+	"math"
+	"sort"
+)
+
+// Combiner merges the signals of several strategies for a symbol into a single signal.
+// The signals are ordered like the strategies, a strategy without a signal for the symbol is nil.
+type Combiner interface {
+	Combine([]SignalEvent) (*Signal, bool)
+}
+
+// MajorityVote emits the direction of more than half of all strategies.
+type MajorityVote struct{}
+
+// Combine returns a signal in the direction of the majority.
+func (c *MajorityVote) Combine(signals []SignalEvent) (*Signal, bool) {
+	votes := make(map[Direction]int)
+	var first SignalEvent
+	for _, s := range signals {
+		if s == nil {
+			continue
+		}
+		if first == nil {
+			first = s
+		}
+		votes[s.Direction()]++
+	}
+
+	for dir, n := range votes {
+		if 2*n > len(signals) {
+			return newCombinedSignal(first, dir), true
+		}
+	}
+	return nil, false
+}
+
+// AllAgree emits a signal only if all strategies signal the same direction.
+type AllAgree struct{}
+
+// Combine returns a signal if all strategies agree on the direction.
+func (c *AllAgree) Combine(signals []SignalEvent) (*Signal, bool) {
+	if len(signals) == 0 || signals[0] == nil {
+		return nil, false
+	}
+	dir := signals[0].Direction()
+	for _, s := range signals[1:] {
+		if s == nil || s.Direction() != dir {
+			return nil, false
+		}
+	}
+	return newCombinedSignal(signals[0], dir), true
+}
+
+// WeightedAverage averages the signed strength of all strategies with their weights.
+// A long signal without a set strength counts as 1.0, a short signal as -1.0, any other signal as 0.
+// The combined signal carries the average as strength and is only emitted,
+// if the absolute average reaches the Threshold. Without Weights all strategies weigh equally.
+type WeightedAverage struct {
+	Weights   []float64
+	Threshold float64
+}
+
+// Combine returns a signal with the weighted average strength.
+func (c *WeightedAverage) Combine(signals []SignalEvent) (*Signal, bool) {
+	var first SignalEvent
+	var sum, weights float64
+	for i, s := range signals {
+		w := 1.0
+		if i < len(c.Weights) {
+			w = c.Weights[i]
+		}
+		weights += w
+
+		if s == nil {
+			continue
+		}
+		if first == nil {
+			first = s
+		}
+		sum += w * signedStrength(s)
+	}
+	if first == nil || weights == 0 {
+		return nil, false
+	}
+
+	avg := math.Round(sum/weights*math.Pow10(DP)) / math.Pow10(DP)
+	if avg == 0 || math.Abs(avg) < c.Threshold {
+		return nil, false
+	}
+
+	dir := BOT
+	if avg < 0 {
+		dir = SLD
+	}
+	signal := newCombinedSignal(first, dir)
+	signal.SetStrength(avg)
+	return signal, true
+}
+
+// signedStrength returns the strength of a signal with the sign of its direction.
+func signedStrength(s SignalEvent) float64 {
+	var sign float64
+	switch s.Direction() {
+	case BOT:
+		sign = 1
+	case SLD:
+		sign = -1
+	default:
+		return 0
+	}
+
+	strength := 1.0
+	if w, ok := s.(Weighter); ok && w.HasStrength() {
+		strength = math.Abs(w.Strength())
+	}
+	return sign * strength
+}
+
+// newCombinedSignal creates a signal in a direction with time and symbol of a source signal.
+func newCombinedSignal(source SignalEvent, dir Direction) *Signal {
+	return &Signal{
+		Event:     Event{timestamp: source.Time(), symbol: source.Symbol()},
+		direction: dir,
+	}
+}
+
+// Combination is a strategy, which runs several strategies on each data event
+// and merges their signals per symbol with a combiner into a single signal stream.
+type Combination struct {
+	*Strategy
+	combiner   Combiner
+	strategies []StrategyHandler
+}
+
+// NewCombination creates a strategy combining the signals of the strategies.
+func NewCombination(name string, combiner Combiner, strategies ...StrategyHandler) *Combination {
+	return &Combination{
+		Strategy:   NewStrategy(name),
+		combiner:   combiner,
+		strategies: strategies,
+	}
+}
+
+// SetData sets the data property of the combination and all combined strategies.
+func (c *Combination) SetData(data DataHandler) error {
+	c.Strategy.SetData(data)
+	for _, s := range c.strategies {
+		if err := s.SetData(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetPortfolio sets the portfolio property of the combination and all combined strategies.
+func (c *Combination) SetPortfolio(portfolio PortfolioHandler) error {
+	c.Strategy.SetPortfolio(portfolio)
+	for _, s := range c.strategies {
+		if err := s.SetPortfolio(portfolio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnData runs all combined strategies and returns the combined signal of each symbol.
+func (c *Combination) OnData(event DataEvent) ([]SignalEvent, error) {
+	bySymbol := make(map[string][]SignalEvent)
+	for i, s := range c.strategies {
+		signals, err := s.OnData(event)
+		if err != nil {
+			return nil, err
+		}
+		for _, signal := range signals {
+			if _, ok := bySymbol[signal.Symbol()]; !ok {
+				bySymbol[signal.Symbol()] = make([]SignalEvent, len(c.strategies))
+			}
+			// the last signal of a strategy for a symbol counts
+			bySymbol[signal.Symbol()][i] = signal
+		}
+	}
+
+	symbols := make([]string, 0, len(bySymbol))
+	for symbol := range bySymbol {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var combined []SignalEvent
+	for _, symbol := range symbols {
+		if signal, ok := c.combiner.Combine(bySymbol[symbol]); ok {
+			combined = append(combined, signal)
+		}
+	}
+	return combined, nil
+}
+
+// Reset implements Reseter to reset all combined strategies.
+func (c *Combination) Reset() error {
+	for _, s := range c.strategies {
+		if r, ok := s.(Reseter); ok {
+			if err := r.Reset(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCombiners(t *testing.T) {
+	long := &Signal{Event: Event{symbol: "TEST.DE"}, direction: BOT}
+	short := &Signal{Event: Event{symbol: "TEST.DE"}, direction: SLD}
+	weakShort := &Signal{Event: Event{symbol: "TEST.DE"}, direction: SLD, strength: -0.5, hasStrength: true}
+
+	// testCases is a table for testing the signal combiners
+	var testCases = []struct {
+		msg       string
+		combiner  Combiner
+		signals   []SignalEvent
+		expSignal *Signal
+		expOk     bool
+	}{
+		{"majority long:",
+			&MajorityVote{}, []SignalEvent{long, long, short},
+			&Signal{Event: Event{symbol: "TEST.DE"}, direction: BOT}, true,
+		},
+		{"no majority:",
+			&MajorityVote{}, []SignalEvent{long, nil, short},
+			nil, false,
+		},
+		{"all agree:",
+			&AllAgree{}, []SignalEvent{short, short},
+			&Signal{Event: Event{symbol: "TEST.DE"}, direction: SLD}, true,
+		},
+		{"one strategy without signal:",
+			&AllAgree{}, []SignalEvent{short, nil},
+			nil, false,
+		},
+		{"weighted average long:",
+			&WeightedAverage{Weights: []float64{3, 1}}, []SignalEvent{long, weakShort},
+			&Signal{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0.625, hasStrength: true}, true,
+		},
+		{"weighted average short:",
+			&WeightedAverage{}, []SignalEvent{nil, short},
+			&Signal{Event: Event{symbol: "TEST.DE"}, direction: SLD, strength: -0.5, hasStrength: true}, true,
+		},
+		{"weighted average below threshold:",
+			&WeightedAverage{Threshold: 0.6}, []SignalEvent{nil, short},
+			nil, false,
+		},
+		{"weighted average cancels out:",
+			&WeightedAverage{}, []SignalEvent{long, short},
+			nil, false,
+		},
+	}
+
+	for _, tc := range testCases {
+		signal, ok := tc.combiner.Combine(tc.signals)
+		if !reflect.DeepEqual(signal, tc.expSignal) || ok != tc.expOk {
+			t.Errorf("%v Combine(): \nexpected %#v %v, \nactual %#v %v", tc.msg, tc.expSignal, tc.expOk, signal, ok)
+		}
+	}
+}
+
+func TestCombinationOnData(t *testing.T) {
+	c := NewCombination("vote", &MajorityVote{},
+		&countingStrategy{Strategy: NewStrategy("a")},
+		&countingStrategy{Strategy: NewStrategy("b")},
+		&buyOnceStrategy{Strategy: NewStrategy("c")},
+	)
+
+	bar := &Bar{Event: Event{symbol: "TEST.DE"}}
+	for i := 0; i < 2; i++ {
+		signals, err := c.OnData(bar)
+		if err != nil || len(signals) != 1 || signals[0].Direction() != BOT {
+			t.Errorf("OnData(): expected one long signal, actual %#v %v", signals, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gobacktest
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComplianceRule is the basic interface for a pre-trade compliance rule.
+// A violated rule returns an error with the reason.
+type ComplianceRule interface {
+	Name() string
+	Check(OrderEvent, DataEvent) error
+}
+
+// RestrictedList rejects all orders of the restricted symbols.
+type RestrictedList struct {
+	Symbols []string
+}
+
+// Name returns the name of the rule.
+func (r *RestrictedList) Name() string {
+	return "restricted list"
+}
+
+// Check rejects an order of a restricted symbol.
+func (r *RestrictedList) Check(order OrderEvent, data DataEvent) error {
+	for _, s := range r.Symbols {
+		if s == order.Symbol() {
+			return fmt.Errorf("%s is restricted", order.Symbol())
+		}
+	}
+	return nil
+}
+
+// Blackout rejects all orders of the symbols within a time window, e.g. before earnings.
+// Without Symbols the blackout applies to all symbols.
+type Blackout struct {
+	Start   time.Time
+	End     time.Time
+	Symbols []string
+}
+
+// Name returns the name of the rule.
+func (r *Blackout) Name() string {
+	return "blackout window"
+}
+
+// Check rejects an order within the blackout window.
+func (r *Blackout) Check(order OrderEvent, data DataEvent) error {
+	t := order.Time()
+	if t.Before(r.Start) || t.After(r.End) || !containsSymbol(r.Symbols, order.Symbol()) {
+		return nil
+	}
+	return fmt.Errorf("%s in blackout from %s to %s", order.Symbol(), r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+}
+
+// MaxNotional rejects orders with a notional value above Max.
+type MaxNotional struct {
+	Max         float64
+	Instruments *InstrumentRegistry
+}
+
+// Name returns the name of the rule.
+func (r *MaxNotional) Name() string {
+	return "max order notional"
+}
+
+// Check rejects an order above the max notional value.
+func (r *MaxNotional) Check(order OrderEvent, data DataEvent) error {
+	if data == nil {
+		return nil
+	}
+
+	notional := r.Instruments.Get(order.Symbol()).Notional(order.Qty(), data.Price())
+	if notional > r.Max {
+		return fmt.Errorf("notional %.2f above max %.2f", notional, r.Max)
+	}
+	return nil
+}
+
+// ComplianceRecord is an entry of the compliance audit trail.
+type ComplianceRecord struct {
+	Time      time.Time
+	Symbol    string
+	OrderID   int
+	EventID   int // lineage id of the order event
+	Direction Direction
+	Qty       float64
+	Rule      string // the rule which rejected the order, empty if passed
+	Reason    string
+}
+
+// Passed returns if the order passed all rules.
+func (r ComplianceRecord) Passed() bool {
+	return r.Rule == ""
+}
+
+// Compliance wraps an execution handler and checks every order against all rules before execution.
+// Every checked order is recorded in the audit trail.
+type Compliance struct {
+	ExecutionHandler
+	Rules []ComplianceRule
+	audit []ComplianceRecord
+}
+
+// NewCompliance creates a compliance check on top of an execution handler.
+func NewCompliance(exchange ExecutionHandler, rules ...ComplianceRule) *Compliance {
+	return &Compliance{
+		ExecutionHandler: exchange,
+		Rules:            rules,
+	}
+}
+
+// OnOrder rejects an order violating a rule, otherwise executes it.
+func (c *Compliance) OnOrder(order OrderEvent, data DataHandler) (*Fill, error) {
+	latest := data.Latest(order.Symbol())
+
+	record := ComplianceRecord{
+		Time:      order.Time(),
+		Symbol:    order.Symbol(),
+		OrderID:   order.ID(),
+		EventID:   eventID(order),
+		Direction: order.Direction(),
+		Qty:       order.Qty(),
+	}
+
+	for _, rule := range c.Rules {
+		if err := rule.Check(order, latest); err != nil {
+			record.Rule = rule.Name()
+			record.Reason = err.Error()
+			c.audit = append(c.audit, record)
+
+			order.SetStatus(OrderInvalid)
+			return nil, NewRejection(order, rule.Name()+": "+err.Error())
+		}
+	}
+
+	c.audit = append(c.audit, record)
+	return c.ExecutionHandler.OnOrder(order, data)
+}
+
+// Audit returns the audit trail of all checked orders.
+func (c *Compliance) Audit() []ComplianceRecord {
+	return c.audit
+}
+
+// Rejected returns the audit records of all rejected orders.
+func (c *Compliance) Rejected() []ComplianceRecord {
+	var rejected []ComplianceRecord
+	for _, r := range c.audit {
+		if !r.Passed() {
+			rejected = append(rejected, r)
+		}
+	}
+	return rejected
+}
+
+// Reset implements Reseter to clear the audit trail and reset the underlying execution handler.
+func (c *Compliance) Reset() error {
+	c.audit = nil
+	if exchange, ok := c.ExecutionHandler.(Reseter); ok {
+		return exchange.Reset()
+	}
+	return nil
+}
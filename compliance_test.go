@@ -0,0 +1,77 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComplianceOnOrder(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	exchange := &Exchange{
+		Symbol:      "TEST",
+		Commission:  &FixedCommission{Commission: 0},
+		ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+	}
+	c := NewCompliance(exchange,
+		&RestrictedList{Symbols: []string{"BAD.DE"}},
+		&Blackout{Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 2), Symbols: []string{"TEST.DE"}},
+		&MaxNotional{Max: 5000},
+	)
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 100},
+			"BAD.DE":  &Bar{Close: 100},
+		},
+	}
+
+	// testCases is a table for testing the compliance rules
+	var testCases = []struct {
+		msg     string
+		order   *Order
+		expRule string
+	}{
+		{"passes all rules:",
+			&Order{Event: Event{timestamp: day, symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			"",
+		},
+		{"restricted symbol:",
+			&Order{Event: Event{timestamp: day, symbol: "BAD.DE"}, direction: BOT, qty: 10},
+			"restricted list",
+		},
+		{"within blackout:",
+			&Order{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "TEST.DE"}, direction: SLD, qty: 10},
+			"blackout window",
+		},
+		{"above max notional:",
+			&Order{Event: Event{timestamp: day, symbol: "TEST.DE"}, direction: BOT, qty: 51},
+			"max order notional",
+		},
+	}
+
+	for _, tc := range testCases {
+		fill, err := c.OnOrder(tc.order, data)
+		if tc.expRule == "" {
+			if err != nil || fill == nil {
+				t.Errorf("%v OnOrder(): expected fill, actual %#v %v", tc.msg, fill, err)
+			}
+			continue
+		}
+		if _, ok := err.(*Rejection); !ok || fill != nil {
+			t.Errorf("%v OnOrder(): expected rejection, actual %#v %v", tc.msg, fill, err)
+		}
+	}
+
+	audit := c.Audit()
+	if len(audit) != len(testCases) {
+		t.Fatalf("Audit(): expected %v records, actual %v", len(testCases), len(audit))
+	}
+	for i, tc := range testCases {
+		if audit[i].Rule != tc.expRule {
+			t.Errorf("%v Audit(): expected rule %q, actual %q", tc.msg, tc.expRule, audit[i].Rule)
+		}
+	}
+	if len(c.Rejected()) != 3 {
+		t.Errorf("Rejected(): expected 3 records, actual %v", len(c.Rejected()))
+	}
+}
@@ -0,0 +1,478 @@
+// Package config builds a complete backtest from a configuration file,
+// so a run can be reproduced and shared as a single file.
+//
+// JSON files are decoded with the standard library, TOML and YAML files with the built-in
+// decoders. The YAML decoder covers the subset used by configuration files, a complete
+// decoder from an external package replaces it by registering it once, e.g.
+//
+//	config.RegisterFormat(".yaml", yaml.Unmarshal) // gopkg.in/yaml.v3
+//
+// A TOML configuration looks like
+//
+//	symbols = ["SDF.DE"]
+//	cash = 100000
+//	seed = 42
+//
+//	[data]
+//	dir = "testdata/bar/"
+//	start = "2010-01-01"
+//	end = "2017-12-31"
+//
+//	[commission]
+//	type = "percentage"
+//	value = 0.001
+//
+//	[strategy]
+//	name = "moving-average-cross"
+//	params = { short = 50, long = 200 }
+//
+//	[notify]
+//	slack = "https://hooks.slack.com/services/..."
+//	events = ["halt", "done"]
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/notify"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// Config is the configuration of a backtest.
+type Config struct {
+	Symbols     []string         `json:"symbols"`
+	Cash        float64          `json:"cash"`
+	Seed        *int64           `json:"seed"`
+	Data        DataConfig       `json:"data"`
+	Commission  CommissionConfig `json:"commission"`
+	ExchangeFee float64          `json:"exchangeFee"`
+	Size        SizeConfig       `json:"size"`
+	Risk        RiskConfig       `json:"risk"`
+	Strategy    StrategyConfig   `json:"strategy"`
+	Notify      NotifyConfig     `json:"notify"`
+}
+
+// DataConfig sets the data source and the date range of the backtest.
+// Dates are formatted as 2006-01-02, an empty date leaves the range open.
+type DataConfig struct {
+	Type  string `json:"type"` // "csv", the default, "metatrader" or "lean"
+	Dir   string `json:"dir"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// the suffix of the MetaTrader files, e.g. "1440" of EURUSD1440.hst, and the time zone
+	// of the server of the broker, e.g. "Europe/Athens"
+	Suffix   string `json:"suffix"`
+	Timezone string `json:"timezone"`
+	// the security type, market and resolution of the data folder of Lean in Dir,
+	// e.g. "equity", "usa" and "daily"
+	SecurityType string `json:"securityType"`
+	Market       string `json:"market"`
+	Resolution   string `json:"resolution"`
+}
+
+// CommissionConfig sets the commission of the exchange.
+// Type is "fixed", the default, "percentage", "threshold" or "value".
+type CommissionConfig struct {
+	Type     string  `json:"type"`
+	Value    float64 `json:"value"`
+	MinValue float64 `json:"minValue"` // minimum trade value of the threshold commission
+	Min      float64 `json:"min"`      // minimum commission of the value commission
+	Max      float64 `json:"max"`      // maximum commission of the value commission
+}
+
+// SizeConfig sets the size manager of the portfolio.
+type SizeConfig struct {
+	DefaultSize  float64 `json:"defaultSize"`
+	DefaultValue float64 `json:"defaultValue"`
+}
+
+// RiskConfig sets the risk rules of the portfolio. Zero values disable a rule.
+type RiskConfig struct {
+	MaxDrawdown    float64 `json:"maxDrawdown"`
+	MaxDailyLoss   float64 `json:"maxDailyLoss"`
+	MaxDailyLossPc float64 `json:"maxDailyLossPercent"`
+	Flatten        bool    `json:"flatten"`
+	ShortSelling   bool    `json:"shortSelling"`
+	// the trading session of an intraday strategy, times are formatted as 15:04
+	Session SessionConfig `json:"session"`
+}
+
+// SessionConfig sets the time of day of the trading and when to close all positions, e.g.
+// from 09:45 to 15:30 and flat at 15:55 in America/New_York. An empty start disables the session.
+type SessionConfig struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	FlatAt   string `json:"flatAt"`
+	Timezone string `json:"timezone"`
+}
+
+// StrategyConfig selects a registered strategy by name with its parameters.
+type StrategyConfig struct {
+	Name   string     `json:"name"`
+	Params gbt.Params `json:"params"`
+}
+
+// NotifyConfig sets the notifications of a run. Events selects the kinds fill, halt,
+// rejection and done, all by default.
+type NotifyConfig struct {
+	Webhook  string         `json:"webhook"`
+	Slack    string         `json:"slack"` // url of the incoming webhook
+	Telegram TelegramConfig `json:"telegram"`
+	Events   []string       `json:"events"`
+}
+
+// TelegramConfig sets the bot token and the chat of the Telegram notifications.
+type TelegramConfig struct {
+	Token string `json:"token"`
+	Chat  string `json:"chat"`
+}
+
+// Decoder decodes a file format into v, with the signature of json.Unmarshal.
+type Decoder func(data []byte, v interface{}) error
+
+// StrategyFactory creates a strategy with the parameters of the configuration.
+type StrategyFactory func(params gbt.Params) (gbt.StrategyHandler, error)
+
+var (
+	mu      sync.RWMutex
+	formats = map[string]Decoder{
+		".json": json.Unmarshal,
+		".toml": UnmarshalTOML,
+		".yaml": UnmarshalYAML,
+		".yml":  UnmarshalYAML,
+	}
+	strategies = map[string]StrategyFactory{
+		"buy-and-hold": func(params gbt.Params) (gbt.StrategyHandler, error) {
+			return strategy.BuyAndHold(), nil
+		},
+		"moving-average-cross": func(params gbt.Params) (gbt.StrategyHandler, error) {
+			short, long := int(params["short"]), int(params["long"])
+			if short <= 0 || long <= 0 {
+				return nil, errors.New("moving-average-cross needs the params short and long")
+			}
+			return strategy.MovingAverageCross(short, long), nil
+		},
+	}
+)
+
+// RegisterFormat registers the decoder of a file extension, e.g. ".yaml".
+func RegisterFormat(ext string, dec Decoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	formats[strings.ToLower(ext)] = dec
+}
+
+// RegisterStrategy registers a strategy, which can then be selected by name.
+func RegisterStrategy(name string, factory StrategyFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	strategies[name] = factory
+}
+
+// Load reads a configuration file, the format is chosen by the file extension.
+func Load(path string) (Config, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return Parse(content, filepath.Ext(path))
+}
+
+// Parse decodes a configuration in the format of the file extension.
+// The document is decoded into a generic map first and then converted
+// into the configuration, so all formats share the field names.
+func Parse(content []byte, ext string) (Config, error) {
+	mu.RLock()
+	dec, ok := formats[strings.ToLower(ext)]
+	mu.RUnlock()
+	if !ok {
+		return Config{}, fmt.Errorf("could not parse config, unknown format %q", ext)
+	}
+
+	var doc map[string]interface{}
+	if err := dec(content, &doc); err != nil {
+		return Config{}, fmt.Errorf("could not parse config: %v", err)
+	}
+
+	raw, err := json.Marshal(normalize(doc))
+	if err != nil {
+		return Config{}, fmt.Errorf("could not parse config: %v", err)
+	}
+
+	var c Config
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return Config{}, fmt.Errorf("could not parse config: %v", err)
+	}
+	return c, nil
+}
+
+// normalize converts the map[interface{}]interface{} of some yaml decoders into
+// maps with string keys, which can be encoded as json.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	}
+	return v
+}
+
+// Build creates the backtest of the configuration with loaded data.
+func (c Config) Build() (*gbt.Backtest, error) {
+	if len(c.Symbols) == 0 {
+		return nil, errors.New("could not build backtest, no symbols given")
+	}
+
+	test := gbt.New()
+	test.SetSymbols(c.Symbols)
+	if c.Seed != nil {
+		test.SetSeed(*c.Seed)
+	}
+
+	d, err := c.Data.build(c.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	test.SetData(d)
+
+	commission, err := c.Commission.build()
+	if err != nil {
+		return nil, err
+	}
+	exchange := gbt.NewExchange()
+	exchange.Commission = commission
+	exchange.ExchangeFee = &gbt.FixedExchangeFee{ExchangeFee: c.ExchangeFee}
+	test.SetExchange(exchange)
+
+	portfolio := gbt.NewPortfolio()
+	if c.Cash > 0 {
+		portfolio.SetInitialCash(c.Cash)
+	}
+	if c.Size.DefaultSize > 0 || c.Size.DefaultValue > 0 {
+		portfolio.SetSizeManager(&gbt.Size{DefaultSize: c.Size.DefaultSize, DefaultValue: c.Size.DefaultValue})
+	}
+	portfolio.SetShortSelling(c.Risk.ShortSelling)
+	rules, err := c.Risk.rules()
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) > 0 {
+		portfolio.SetRiskRules(rules...)
+	}
+	test.SetPortfolio(portfolio)
+
+	s, err := c.Strategy.build(c.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	test.SetStrategy(s)
+
+	return test, nil
+}
+
+// Notifier returns the notifier of the configured senders, nil if none is configured.
+func (c NotifyConfig) Notifier() (*notify.Notifier, error) {
+	for _, kind := range c.Events {
+		switch kind {
+		case notify.KindFill, notify.KindHalt, notify.KindRejection, notify.KindDone:
+		default:
+			return nil, fmt.Errorf("could not build notifier, unknown event %q", kind)
+		}
+	}
+
+	n := &notify.Notifier{Events: c.Events}
+	if c.Webhook != "" {
+		n.Senders = append(n.Senders, &notify.Webhook{URL: c.Webhook})
+	}
+	if c.Slack != "" {
+		n.Senders = append(n.Senders, &notify.Slack{URL: c.Slack})
+	}
+	if c.Telegram.Token != "" || c.Telegram.Chat != "" {
+		if c.Telegram.Token == "" || c.Telegram.Chat == "" {
+			return nil, errors.New("could not build notifier, telegram needs a token and a chat")
+		}
+		n.Senders = append(n.Senders, &notify.Telegram{Token: c.Telegram.Token, ChatID: c.Telegram.Chat})
+	}
+	if len(n.Senders) == 0 {
+		return nil, nil
+	}
+	return n, nil
+}
+
+// build loads the data of the symbols within the date range.
+func (c DataConfig) build(symbols []string) (gbt.DataHandler, error) {
+	start, err := parseDate(c.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(c.End)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Type {
+	case "", "csv":
+		d := &data.BarEventFromCSVFile{FileDir: c.Dir}
+		if err := d.Load(symbols); err != nil {
+			return nil, err
+		}
+		d.SetStream(between(d.Stream(), start, end))
+		return d, nil
+	case "metatrader":
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("could not build data: %v", err)
+		}
+		d := &data.BarEventFromMetaTrader{FileDir: c.Dir, Suffix: c.Suffix, Location: loc}
+		if err := d.Load(symbols); err != nil {
+			return nil, err
+		}
+		d.SetStream(between(d.Stream(), start, end))
+		return d, nil
+	case "lean":
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("could not build data: %v", err)
+		}
+		d := &data.BarEventFromLean{Root: c.Dir, SecurityType: c.SecurityType, Market: c.Market, Resolution: c.Resolution, Start: start, End: end, Location: loc}
+		if err := d.Load(symbols); err != nil {
+			return nil, err
+		}
+		d.SetStream(between(d.Stream(), start, end))
+		return d, nil
+	}
+	return nil, fmt.Errorf("could not build data, unknown type %q", c.Type)
+}
+
+// parseDate parses a date of the configuration, an empty date is the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return t, fmt.Errorf("could not parse date %q: %v", s, err)
+	}
+	return t, nil
+}
+
+// between returns the data events within the date range, a zero time leaves the range open.
+func between(stream []gbt.DataEvent, start, end time.Time) []gbt.DataEvent {
+	var filtered []gbt.DataEvent
+	for _, e := range stream {
+		if !start.IsZero() && e.Time().Before(start) {
+			continue
+		}
+		if !end.IsZero() && e.Time().After(end) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+// build creates the commission handler.
+func (c CommissionConfig) build() (gbt.CommissionHandler, error) {
+	switch c.Type {
+	case "", "fixed":
+		return &gbt.FixedCommission{Commission: c.Value}, nil
+	case "percentage":
+		return &gbt.PercentageCommission{Commission: c.Value}, nil
+	case "threshold":
+		return &gbt.TresholdFixedCommission{Commission: c.Value, MinValue: c.MinValue}, nil
+	case "value":
+		return &gbt.ValueCommission{Commission: c.Value, MinCommission: c.Min, MaxCommission: c.Max}, nil
+	}
+	return nil, fmt.Errorf("could not build commission, unknown type %q", c.Type)
+}
+
+// rules returns the configured risk rules.
+func (c RiskConfig) rules() ([]gbt.RiskRule, error) {
+	var rules []gbt.RiskRule
+	if c.MaxDrawdown > 0 {
+		rules = append(rules, &gbt.DrawdownHalt{MaxDrawdown: c.MaxDrawdown, Flatten: c.Flatten})
+	}
+	if c.MaxDailyLoss > 0 || c.MaxDailyLossPc > 0 {
+		rules = append(rules, &gbt.DailyLossLimit{MaxLoss: c.MaxDailyLoss, MaxLossPercent: c.MaxDailyLossPc, Flatten: c.Flatten})
+	}
+	if c.Session.Start != "" {
+		session, err := c.Session.build()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, session)
+	}
+	return rules, nil
+}
+
+// build creates the trading session of the time of day.
+func (c SessionConfig) build() (*gbt.TradingSession, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("could not build session: %v", err)
+	}
+	session := &gbt.TradingSession{Location: loc}
+	for _, v := range []struct {
+		value string
+		d     *time.Duration
+	}{{c.Start, &session.Start}, {c.End, &session.End}, {c.FlatAt, &session.FlatAt}} {
+		if v.value == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", v.value)
+		if err != nil {
+			return nil, fmt.Errorf("could not build session, invalid time %q", v.value)
+		}
+		*v.d = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return session, nil
+}
+
+// build creates the registered strategy and adds an asset for each symbol.
+func (c StrategyConfig) build(symbols []string) (gbt.StrategyHandler, error) {
+	mu.RLock()
+	factory, ok := strategies[c.Name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("could not build strategy, unknown strategy %q", c.Name)
+	}
+
+	s, err := factory(c.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if children, ok := s.(interface {
+		SetChildren(...gbt.NodeHandler) gbt.NodeHandler
+	}); ok {
+		var assets []gbt.NodeHandler
+		for _, symbol := range symbols {
+			assets = append(assets, gbt.NewAsset(symbol))
+		}
+		children.SetChildren(assets...)
+	}
+
+	return s, nil
+}
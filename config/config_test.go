@@ -0,0 +1,306 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+var testTOML = `
+symbols = ["SDF.DE"]
+cash = 50000
+seed = 42
+exchangeFee = 1
+
+[data]
+dir = "../examples/testdata/bar/"
+start = "2017-01-01"
+end = "2017-06-30"
+
+[commission]
+type = "percentage"
+value = 0.001
+
+[size]
+defaultSize = 10
+defaultValue = 500
+
+[risk]
+maxDrawdown = 0.2
+
+[strategy]
+name = "moving-average-cross"
+params = { short = 5, long = 20 }
+
+[notify]
+slack = "https://hooks.slack.com/services/T0/B0/X0"
+events = ["halt", "done"]
+
+[notify.telegram]
+token = "123:abc"
+chat = "42"
+`
+
+var testYAML = `
+# the toml config as yaml
+symbols: [SDF.DE]
+cash: 50000
+seed: 42
+exchangeFee: 1
+
+data:
+  dir: ../examples/testdata/bar/
+  start: "2017-01-01"
+  end: 2017-06-30
+
+commission: {type: percentage, value: 0.001}
+
+size:
+  defaultSize: 10
+  defaultValue: 500
+
+risk:
+  maxDrawdown: 0.2
+
+strategy:
+  name: moving-average-cross
+  params:
+    short: 5
+    long: 20
+
+notify:
+  slack: https://hooks.slack.com/services/T0/B0/X0
+  events:
+  - halt
+  - done
+  telegram:
+    token: "123:abc"
+    chat: '42'
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testTOML), ".toml")
+	if err != nil {
+		t.Fatalf("Parse(): unexpected error %v", err)
+	}
+
+	if len(c.Symbols) != 1 || c.Symbols[0] != "SDF.DE" {
+		t.Errorf("Parse(): expected symbols [SDF.DE], actual %v", c.Symbols)
+	}
+	if c.Cash != 50000 || c.Seed == nil || *c.Seed != 42 || c.ExchangeFee != 1 {
+		t.Errorf("Parse(): unexpected cash %v, seed %v or fee %v", c.Cash, c.Seed, c.ExchangeFee)
+	}
+	if c.Data.Start != "2017-01-01" || c.Commission.Type != "percentage" || c.Commission.Value != 0.001 {
+		t.Errorf("Parse(): unexpected data %+v or commission %+v", c.Data, c.Commission)
+	}
+	if c.Size.DefaultSize != 10 || c.Risk.MaxDrawdown != 0.2 {
+		t.Errorf("Parse(): unexpected size %+v or risk %+v", c.Size, c.Risk)
+	}
+	if c.Strategy.Name != "moving-average-cross" || c.Strategy.Params["short"] != 5 || c.Strategy.Params["long"] != 20 {
+		t.Errorf("Parse(): unexpected strategy %+v", c.Strategy)
+	}
+	if c.Notify.Slack == "" || len(c.Notify.Events) != 2 || c.Notify.Telegram.Chat != "42" {
+		t.Errorf("Parse(): unexpected notify %+v", c.Notify)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	// testCases is a table for testing the notifier of the config
+	var testCases = []struct {
+		msg        string
+		notify     NotifyConfig
+		expSenders int
+		expErr     bool
+	}{
+		{"no senders:", NotifyConfig{Events: []string{"done"}}, 0, false},
+		{"all senders:", NotifyConfig{Webhook: "http://localhost/hook", Slack: "http://localhost/slack", Telegram: TelegramConfig{Token: "t", Chat: "c"}}, 3, false},
+		{"unknown event:", NotifyConfig{Webhook: "http://localhost/hook", Events: []string{"tick"}}, 0, true},
+		{"telegram without chat:", NotifyConfig{Telegram: TelegramConfig{Token: "t"}}, 0, true},
+	}
+
+	for _, tc := range testCases {
+		n, err := tc.notify.Notifier()
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v\nNotifier(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if tc.expSenders == 0 && n != nil || tc.expSenders > 0 && (n == nil || len(n.Senders) != tc.expSenders) {
+			t.Errorf("%v\nNotifier(): expected %v senders, actual %+v", tc.msg, tc.expSenders, n)
+		}
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tomlConfig, err := Parse([]byte(testTOML), ".toml")
+	if err != nil {
+		t.Fatalf("Parse(): unexpected error %v", err)
+	}
+
+	raw, _ := json.Marshal(tomlConfig)
+	jsonConfig, err := Parse(raw, ".JSON")
+	if err != nil {
+		t.Fatalf("Parse(): unexpected error %v", err)
+	}
+	if jsonConfig.Strategy.Params["long"] != 20 || *jsonConfig.Seed != 42 {
+		t.Errorf("Parse(): expected the json config to match the toml config, actual %+v", jsonConfig)
+	}
+
+	yamlConfig, err := Parse([]byte(testYAML), ".yaml")
+	if err != nil {
+		t.Fatalf("Parse(): unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(yamlConfig, tomlConfig) {
+		t.Errorf("Parse(): expected the yaml config to match the toml config\nexpected %+v\nactual %+v", tomlConfig, yamlConfig)
+	}
+
+	if _, err := Parse(raw, ".ini"); err == nil {
+		t.Errorf("Parse(): expected an error for an unregistered format")
+	}
+
+	// a registered decoder, e.g. of a yaml package with map[interface{}]interface{}
+	RegisterFormat(".ini", func(data []byte, v interface{}) error {
+		*v.(*map[string]interface{}) = map[string]interface{}{
+			"symbols":  []interface{}{"SDF.DE"},
+			"strategy": map[interface{}]interface{}{"name": "buy-and-hold"},
+		}
+		return nil
+	})
+	iniConfig, err := Parse(nil, ".ini")
+	if err != nil {
+		t.Fatalf("Parse(): unexpected error %v", err)
+	}
+	if iniConfig.Strategy.Name != "buy-and-hold" {
+		t.Errorf("Parse(): expected the strategy of the registered decoder, actual %+v", iniConfig.Strategy)
+	}
+}
+
+func TestLoadAndBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// testCases is a table for testing the configuration files of each format
+	var testCases = []struct {
+		msg  string
+		file string
+		data string
+	}{
+		{"toml file:", "backtest.toml", testTOML},
+		{"yaml file:", "backtest.yaml", testYAML},
+	}
+
+	// the date range limits the data
+	start, _ := time.Parse("2006-01-02", "2017-01-01")
+	end, _ := time.Parse("2006-01-02", "2017-06-30")
+
+	for _, tc := range testCases {
+		path := filepath.Join(dir, tc.file)
+		if err := ioutil.WriteFile(path, []byte(tc.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("%v\nLoad(): unexpected error %v", tc.msg, err)
+		}
+		test, err := c.Build()
+		if err != nil {
+			t.Fatalf("%v\nBuild(): unexpected error %v", tc.msg, err)
+		}
+
+		if seed, ok := test.Seed(); !ok || seed != 42 {
+			t.Errorf("%v\nBuild(): expected seed 42, actual %v %v", tc.msg, seed, ok)
+		}
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v\nRun(): unexpected error %v", tc.msg, err)
+		}
+
+		events := test.Stats().Events()
+		if len(events) == 0 {
+			t.Fatalf("%v\nRun(): expected tracked events", tc.msg)
+		}
+		for _, e := range events {
+			if e.Time().Before(start) || e.Time().After(end) {
+				t.Errorf("%v\nRun(): expected events within the date range, actual %v", tc.msg, e.Time())
+				break
+			}
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	valid := Config{
+		Symbols:  []string{"SDF.DE"},
+		Data:     DataConfig{Dir: "../examples/testdata/bar/"},
+		Strategy: StrategyConfig{Name: "buy-and-hold"},
+	}
+
+	// testCases is a table for testing invalid configurations
+	var testCases = []struct {
+		msg    string
+		modify func(c *Config)
+	}{
+		{"no symbols:", func(c *Config) { c.Symbols = nil }},
+		{"unknown data type:", func(c *Config) { c.Data.Type = "parquet" }},
+		{"invalid date:", func(c *Config) { c.Data.Start = "01.01.2017" }},
+		{"unknown time zone:", func(c *Config) { c.Data.Type, c.Data.Timezone = "metatrader", "Mars/Olympus" }},
+		{"no metatrader file:", func(c *Config) { c.Data.Type = "metatrader" }},
+		{"unsupported lean security type:", func(c *Config) { c.Data.Type, c.Data.SecurityType = "lean", "option" }},
+		{"invalid session time:", func(c *Config) { c.Risk.Session.Start = "9:45am" }},
+		{"unknown session time zone:", func(c *Config) { c.Risk.Session = SessionConfig{Start: "09:45", Timezone: "Mars/Olympus"} }},
+		{"unknown commission:", func(c *Config) { c.Commission.Type = "tiered" }},
+		{"unknown strategy:", func(c *Config) { c.Strategy.Name = "unknown" }},
+		{"missing strategy params:", func(c *Config) { c.Strategy.Name = "moving-average-cross" }},
+	}
+
+	if _, err := valid.Build(); err != nil {
+		t.Fatalf("Build(): unexpected error %v", err)
+	}
+
+	for _, tc := range testCases {
+		c := valid
+		tc.modify(&c)
+		if _, err := c.Build(); err == nil {
+			t.Errorf("%v Build(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	RegisterStrategy("failing", func(params gbt.Params) (gbt.StrategyHandler, error) {
+		return nil, errors.New("failing strategy")
+	})
+
+	c := Config{
+		Symbols:  []string{"SDF.DE"},
+		Data:     DataConfig{Dir: "../examples/testdata/bar/"},
+		Strategy: StrategyConfig{Name: "failing"},
+	}
+	if _, err := c.Build(); err == nil || err.Error() != "failing strategy" {
+		t.Errorf("Build(): expected the error of the registered strategy, actual %v", err)
+	}
+}
+
+func TestSessionConfig(t *testing.T) {
+	c := RiskConfig{MaxDrawdown: 0.2, Session: SessionConfig{Start: "09:45", End: "15:30", FlatAt: "15:55", Timezone: "UTC"}}
+	rules, err := c.rules()
+	if err != nil {
+		t.Fatalf("rules(): unexpected error %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("rules(): expected the drawdown halt and the session, actual %v", rules)
+	}
+	session, ok := rules[1].(*gbt.TradingSession)
+	if !ok || session.Start != 9*time.Hour+45*time.Minute || session.End != 15*time.Hour+30*time.Minute || session.FlatAt != 15*time.Hour+55*time.Minute {
+		t.Errorf("rules(): unexpected session %+v", rules[1])
+	}
+}
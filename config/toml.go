@@ -0,0 +1,399 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalTOML decodes a TOML document into v, with the signature of json.Unmarshal.
+// It supports the subset of TOML used by configuration files: tables, arrays of tables,
+// dotted keys, strings, integers, floats, booleans, arrays and inline tables.
+// Dates and times are decoded as strings. The document is converted into v by its json encoding.
+func UnmarshalTOML(data []byte, v interface{}) error {
+	p := &tomlParser{s: string(data), line: 1}
+	doc, err := p.parse()
+	if err != nil {
+		return err
+	}
+
+	if m, ok := v.(*map[string]interface{}); ok {
+		*m = doc
+		return nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// tomlParser is a recursive descent parser of a TOML document.
+type tomlParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+// parse parses the document into nested maps.
+func (p *tomlParser) parse() (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	current := root
+
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			table, err := p.header(root)
+			if err != nil {
+				return nil, err
+			}
+			current = table
+		} else if err := p.keyValue(current); err != nil {
+			return nil, err
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// header parses a [table] or [[array of tables]] header and returns the table.
+func (p *tomlParser) header(root map[string]interface{}) (map[string]interface{}, error) {
+	p.pos++
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.pos++
+	}
+
+	p.skipSpace(false)
+	keys, err := p.keys()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if !p.consume("]") || (array && !p.consume("]")) {
+		return nil, p.errorf("unterminated table header")
+	}
+
+	parent, err := p.table(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+
+	if array {
+		table := map[string]interface{}{}
+		switch existing := parent[last].(type) {
+		case nil:
+			parent[last] = []interface{}{table}
+		case []interface{}:
+			parent[last] = append(existing, table)
+		default:
+			return nil, p.errorf("key %q is not an array of tables", last)
+		}
+		return table, nil
+	}
+
+	return p.table(parent, []string{last})
+}
+
+// table walks the keys from the map, creating missing tables.
+// A key holding an array of tables refers to its last table.
+func (p *tomlParser) table(m map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, k := range keys {
+		switch next := m[k].(type) {
+		case nil:
+			table := map[string]interface{}{}
+			m[k] = table
+			m = table
+		case map[string]interface{}:
+			m = next
+		case []interface{}:
+			table, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("key %q is not a table", k)
+			}
+			m = table
+		default:
+			return nil, p.errorf("key %q is not a table", k)
+		}
+	}
+	return m, nil
+}
+
+// keyValue parses a key = value pair into the table.
+func (p *tomlParser) keyValue(table map[string]interface{}) error {
+	keys, err := p.keys()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if !p.consume("=") {
+		return p.errorf("expected = after key %q", strings.Join(keys, "."))
+	}
+	p.skipSpace(false)
+
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.table(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, ok := parent[last]; ok {
+		return p.errorf("duplicate key %q", last)
+	}
+	parent[last] = value
+	return nil
+}
+
+// keys parses a bare, quoted or dotted key.
+func (p *tomlParser) keys() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace(false)
+		if p.eof() {
+			return nil, p.errorf("expected key")
+		}
+
+		var key string
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKey(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("invalid key")
+			}
+			key = p.s[start:p.pos]
+		}
+		keys = append(keys, key)
+
+		p.skipSpace(false)
+		if !p.consume(".") {
+			return keys, nil
+		}
+	}
+}
+
+// value parses a single value.
+func (p *tomlParser) value() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected value")
+	}
+
+	switch p.peek() {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]} \t\r\n#", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	number := strings.Replace(token, "_", "", -1)
+	if i, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		return f, nil
+	}
+	// dates and times are kept as strings
+	if len(token) >= 8 && (strings.Count(token, "-") >= 2 || strings.Count(token, ":") >= 2) {
+		return token, nil
+	}
+	return nil, p.errorf("invalid value %q", token)
+}
+
+// array parses an array, which can span several lines.
+func (p *tomlParser) array() ([]interface{}, error) {
+	p.pos++
+	values := []interface{}{}
+	for {
+		p.skipSpace(true)
+		if p.consume("]") {
+			return values, nil
+		}
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		p.skipSpace(true)
+		if p.consume("]") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// inlineTable parses an inline table on a single line.
+func (p *tomlParser) inlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := map[string]interface{}{}
+	for {
+		p.skipSpace(false)
+		if p.consume("}") {
+			return table, nil
+		}
+
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace(false)
+		if p.consume("}") {
+			return table, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// str parses a basic "string" with escapes or a literal 'string'.
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\' && quote == '"':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			esc := p.peek()
+			p.pos++
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\':
+				b.WriteByte(esc)
+			default:
+				return "", p.errorf("invalid escape \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// endOfLine expects the end of a line after an optional comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.consume("\r\n") || p.consume("\n") {
+		p.line++
+		return nil
+	}
+	return p.errorf("unexpected %q", p.s[p.pos:p.pos+1])
+}
+
+// skipSpace skips spaces and tabs, with newlines also empty lines and comments.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			if !newlines {
+				return
+			}
+			p.pos++
+			p.line++
+		case '#':
+			if !newlines {
+				return
+			}
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// skipComment skips a comment up to the end of the line.
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.s[p.pos]
+}
+
+// consume advances over the prefix, if the remaining document starts with it.
+func (p *tomlParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// isBareKey returns if the character is allowed in a bare key.
+func isBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
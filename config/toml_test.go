@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalTOML(t *testing.T) {
+	// testCases is a table for testing the decoding of toml documents
+	var testCases = []struct {
+		msg    string
+		doc    string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"key value pairs:",
+			"# comment\na = 1\nb = 1.5 # trailing\nc = \"x\\\"y\"\nd = 'C:\\dir'\ne = true\nf = 1_000\n",
+			map[string]interface{}{"a": int64(1), "b": 1.5, "c": "x\"y", "d": "C:\\dir", "e": true, "f": int64(1000)},
+			false},
+		{"tables and dotted keys:",
+			"[data]\ndir = \"bar/\"\n[data.range]\nstart = 2017-01-01\nx.y = 2\n",
+			map[string]interface{}{"data": map[string]interface{}{
+				"dir":   "bar/",
+				"range": map[string]interface{}{"start": "2017-01-01", "x": map[string]interface{}{"y": int64(2)}},
+			}},
+			false},
+		{"arrays and inline tables:",
+			"symbols = [\n  \"A\", # first\n  \"B\",\n]\nparams = { short = 5, long = 20 }\nempty = []\n",
+			map[string]interface{}{
+				"symbols": []interface{}{"A", "B"},
+				"params":  map[string]interface{}{"short": int64(5), "long": int64(20)},
+				"empty":   []interface{}{},
+			},
+			false},
+		{"array of tables:",
+			"[[jobs]]\nname = \"a\"\n[[jobs]]\nname = \"b\"\n",
+			map[string]interface{}{"jobs": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b"},
+			}},
+			false},
+		{"duplicate key:", "a = 1\na = 2\n", nil, true},
+		{"missing value:", "a = \n", nil, true},
+		{"unterminated string:", "a = \"x\n", nil, true},
+		{"two values on a line:", "a = 1 b = 2\n", nil, true},
+		{"invalid value:", "a = yes\n", nil, true},
+	}
+
+	for _, tc := range testCases {
+		var doc map[string]interface{}
+		err := UnmarshalTOML([]byte(tc.doc), &doc)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v UnmarshalTOML(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if !tc.expErr && !reflect.DeepEqual(doc, tc.exp) {
+			t.Errorf("%v UnmarshalTOML(): \nexpected %#v, \nactual   %#v", tc.msg, tc.exp, doc)
+		}
+	}
+}
+
+func TestUnmarshalTOMLStruct(t *testing.T) {
+	var v struct {
+		Name  string   `json:"name"`
+		Sizes []int    `json:"sizes"`
+		Rate  *float64 `json:"rate"`
+	}
+
+	err := UnmarshalTOML([]byte("name = \"test\"\nsizes = [1, 2]\nrate = 0.5\n"), &v)
+	if err != nil {
+		t.Fatalf("UnmarshalTOML(): unexpected error %v", err)
+	}
+	if v.Name != "test" || !reflect.DeepEqual(v.Sizes, []int{1, 2}) || v.Rate == nil || *v.Rate != 0.5 {
+		t.Errorf("UnmarshalTOML(): unexpected struct %+v", v)
+	}
+}
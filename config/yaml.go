@@ -0,0 +1,453 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalYAML decodes a YAML document into v, with the signature of json.Unmarshal.
+// It supports the subset of YAML used by configuration files: block mappings and sequences
+// by indentation, flow sequences and mappings, plain, single and double quoted scalars and
+// comments. Anchors, tags, multi-line scalars and multiple documents are not supported.
+// Dates and times are decoded as strings. The document is converted into v by its json encoding.
+func UnmarshalYAML(data []byte, v interface{}) error {
+	p, err := newYAMLParser(string(data))
+	if err != nil {
+		return err
+	}
+	doc, err := p.parse()
+	if err != nil {
+		return err
+	}
+
+	if m, ok := v.(*map[string]interface{}); ok {
+		root, ok := doc.(map[string]interface{})
+		if !ok && doc != nil {
+			return fmt.Errorf("yaml: document is not a mapping")
+		}
+		*m = root
+		return nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// yamlLine is a line of a document without indentation and comment.
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+// yamlParser parses the block structure of a document line by line.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// newYAMLParser splits the document into its lines, empty lines and comments are dropped.
+func newYAMLParser(s string) (*yamlParser, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(s, "\n") {
+		raw = strings.TrimRight(stripComment(raw), " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" {
+			continue
+		}
+		if text[0] == '\t' {
+			return nil, fmt.Errorf("yaml: line %d: tab in indentation", i+1)
+		}
+		if text == "---" || text == "..." {
+			if len(p.lines) > 0 && text == "---" {
+				return nil, fmt.Errorf("yaml: line %d: multiple documents", i+1)
+			}
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{indent: len(raw) - len(text), text: text, num: i + 1})
+	}
+	return p, nil
+}
+
+// parse parses the document, nil for an empty document.
+func (p *yamlParser) parse() (interface{}, error) {
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	v, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf(p.lines[p.pos], "unexpected indentation")
+	}
+	return v, nil
+}
+
+// block parses the mapping or sequence starting at the current line.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// mapping parses the key value pairs of a block mapping at an indentation.
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l, "unexpected indentation")
+		}
+		if isSequenceItem(l.text) {
+			return nil, p.errorf(l, "expected key, found sequence item")
+		}
+
+		key, rest, ok, err := splitKey(l.text)
+		if err != nil {
+			return nil, p.errorf(l, "%v", err)
+		}
+		if !ok {
+			return nil, p.errorf(l, "expected key: value")
+		}
+		if _, ok := m[key]; ok {
+			return nil, p.errorf(l, "duplicate key %q", key)
+		}
+		p.pos++
+
+		v, err := p.node(l, rest, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// sequence parses the items of a block sequence at an indentation.
+func (p *yamlParser) sequence(indent int) ([]interface{}, error) {
+	values := []interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !isSequenceItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf(l, "unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if _, _, ok, _ := splitKey(rest); ok && rest[0] != '[' && rest[0] != '{' {
+			// a mapping as item, its keys are indented to the first key
+			p.lines[p.pos] = yamlLine{indent: indent + len(l.text) - len(rest), text: rest, num: l.num}
+			v, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			continue
+		}
+
+		p.pos++
+		v, err := p.node(l, rest, indent, false)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// node parses the value of a key or a sequence item, inline or as nested block.
+// The sequence of a key may start at the indentation of the key.
+func (p *yamlParser) node(l yamlLine, rest string, indent int, key bool) (interface{}, error) {
+	if rest != "" {
+		return parseYAMLValue(rest, l, p)
+	}
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+
+	next := p.lines[p.pos]
+	if next.indent > indent || (key && next.indent == indent && isSequenceItem(next.text)) {
+		return p.block(next.indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) errorf(l yamlLine, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", l.num, fmt.Sprintf(format, args...))
+}
+
+// isSequenceItem returns if the line is an item of a block sequence.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a line into the key and the rest after the colon.
+// ok is false, if the line is no key value pair.
+func splitKey(text string) (key, rest string, ok bool, err error) {
+	if text == "" {
+		return "", "", false, nil
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		f := &yamlFlow{s: text}
+		key, err = f.quoted()
+		if err != nil {
+			return "", "", false, err
+		}
+		after := text[f.pos:]
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false, nil
+		}
+		return key, strings.TrimSpace(after[1:]), true, nil
+	}
+
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false, nil
+		}
+		i = len(text) - 1
+	}
+	key = strings.TrimSpace(text[:i])
+	if key == "" || strings.ContainsAny(key[:1], "[{") {
+		return "", "", false, nil
+	}
+	return key, strings.TrimSpace(text[i+1:]), true, nil
+}
+
+// stripComment removes a comment from a line, a # starts a comment at the beginning of the
+// line or after a space, outside of quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseYAMLValue parses an inline value, a scalar or a flow collection.
+func parseYAMLValue(s string, l yamlLine, p *yamlParser) (interface{}, error) {
+	switch s[0] {
+	case '|', '>':
+		return nil, p.errorf(l, "multi-line scalars are not supported")
+	case '&', '*', '!':
+		return nil, p.errorf(l, "anchors, aliases and tags are not supported")
+	}
+
+	f := &yamlFlow{s: s}
+	v, err := f.value(false)
+	if err != nil {
+		return nil, p.errorf(l, "%v", err)
+	}
+	f.skipSpace()
+	if !f.eof() {
+		return nil, p.errorf(l, "unexpected %q after value", s[f.pos:])
+	}
+	return v, nil
+}
+
+// yamlFlow parses a value on a single line, flow collections recursively.
+type yamlFlow struct {
+	s   string
+	pos int
+}
+
+// value parses a scalar or a flow collection. Inside of a flow collection
+// a plain scalar ends at a comma or a closing bracket.
+func (f *yamlFlow) value(flow bool) (interface{}, error) {
+	f.skipSpace()
+	if f.eof() {
+		if flow {
+			return nil, fmt.Errorf("expected value")
+		}
+		return nil, nil
+	}
+
+	switch f.s[f.pos] {
+	case '"', '\'':
+		return f.quoted()
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	}
+
+	start := f.pos
+	for !f.eof() && !(flow && strings.IndexByte(",]}", f.s[f.pos]) >= 0) {
+		if flow && f.s[f.pos] == ':' && f.pos+1 < len(f.s) && f.s[f.pos+1] == ' ' {
+			break
+		}
+		f.pos++
+	}
+	return plainScalar(strings.TrimSpace(f.s[start:f.pos])), nil
+}
+
+// sequence parses a flow sequence [a, b].
+func (f *yamlFlow) sequence() ([]interface{}, error) {
+	f.pos++
+	values := []interface{}{}
+	for {
+		f.skipSpace()
+		if f.consume(']') {
+			return values, nil
+		}
+		v, err := f.value(true)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		f.skipSpace()
+		if f.consume(']') {
+			return values, nil
+		}
+		if !f.consume(',') {
+			return nil, fmt.Errorf("expected , or ] in flow sequence")
+		}
+	}
+}
+
+// mapping parses a flow mapping {a: 1, b: 2}.
+func (f *yamlFlow) mapping() (map[string]interface{}, error) {
+	f.pos++
+	m := map[string]interface{}{}
+	for {
+		f.skipSpace()
+		if f.consume('}') {
+			return m, nil
+		}
+		k, err := f.value(true)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		f.skipSpace()
+		if !f.consume(':') {
+			return nil, fmt.Errorf("expected : after key %q in flow mapping", key)
+		}
+		v, err := f.value(true)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+		m[key] = v
+
+		f.skipSpace()
+		if f.consume('}') {
+			return m, nil
+		}
+		if !f.consume(',') {
+			return nil, fmt.Errorf("expected , or } in flow mapping")
+		}
+	}
+}
+
+// quoted parses a "double quoted" scalar with escapes or a 'single quoted' scalar.
+func (f *yamlFlow) quoted() (string, error) {
+	quote := f.s[f.pos]
+	f.pos++
+
+	var b strings.Builder
+	for !f.eof() {
+		c := f.s[f.pos]
+		f.pos++
+		switch {
+		case c == quote && quote == '\'' && !f.eof() && f.s[f.pos] == '\'':
+			// '' is a single quote in a single quoted scalar
+			b.WriteByte('\'')
+			f.pos++
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"':
+			if f.eof() {
+				return "", fmt.Errorf("unterminated string")
+			}
+			esc := f.s[f.pos]
+			f.pos++
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			default:
+				return "", fmt.Errorf("invalid escape \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+func (f *yamlFlow) skipSpace() {
+	for !f.eof() && (f.s[f.pos] == ' ' || f.s[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) consume(c byte) bool {
+	if !f.eof() && f.s[f.pos] == c {
+		f.pos++
+		return true
+	}
+	return false
+}
+
+func (f *yamlFlow) eof() bool {
+	return f.pos >= len(f.s)
+}
+
+// plainScalar resolves the type of a plain scalar, null, a boolean, an integer,
+// a float or else a string.
+func plainScalar(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	// numbers start with a digit, a sign or a dot, which excludes words like inf or nan
+	if strings.ContainsAny(s[:1], "0123456789+-.") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalYAML(t *testing.T) {
+	// testCases is a table for testing the decoding of yaml documents
+	var testCases = []struct {
+		msg    string
+		doc    string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"scalars:",
+			"---\n# comment\na: 1\nb: 1.5 # trailing\nc: \"x\\\"y # no comment\"\nd: 'it''s'\ne: true\nf: ~\ng: plain text\nh: 2017-01-01\ni: https://example.com/#top\n",
+			map[string]interface{}{"a": int64(1), "b": 1.5, "c": "x\"y # no comment", "d": "it's", "e": true, "f": nil,
+				"g": "plain text", "h": "2017-01-01", "i": "https://example.com/#top"},
+			false},
+		{"nested mappings:",
+			"data:\n  dir: bar/\n  range:\n    start: 2017-01-01\n  type: csv\nempty:\n",
+			map[string]interface{}{
+				"data": map[string]interface{}{
+					"dir":   "bar/",
+					"range": map[string]interface{}{"start": "2017-01-01"},
+					"type":  "csv",
+				},
+				"empty": nil,
+			},
+			false},
+		{"block sequences:",
+			"symbols:\n- A\n- B\nsizes:\n  - 1\n  - 2\njobs:\n  - name: a\n    size: 1\n  - name: b\n",
+			map[string]interface{}{
+				"symbols": []interface{}{"A", "B"},
+				"sizes":   []interface{}{int64(1), int64(2)},
+				"jobs": []interface{}{
+					map[string]interface{}{"name": "a", "size": int64(1)},
+					map[string]interface{}{"name": "b"},
+				},
+			},
+			false},
+		{"flow collections:",
+			"symbols: [A, \"B\", 'C']\nparams: {short: 5, long: 20, nested: [1, {x: y}]}\nempty: []\n",
+			map[string]interface{}{
+				"symbols": []interface{}{"A", "B", "C"},
+				"params": map[string]interface{}{"short": int64(5), "long": int64(20),
+					"nested": []interface{}{int64(1), map[string]interface{}{"x": "y"}}},
+				"empty": []interface{}{},
+			},
+			false},
+		{"quoted keys:", "\"a b\": 1\n'c': [x]\n", map[string]interface{}{"a b": int64(1), "c": []interface{}{"x"}}, false},
+		{"empty document:", "# only a comment\n", nil, false},
+		{"duplicate key:", "a: 1\na: 2\n", nil, true},
+		{"unexpected indentation:", "a: 1\n  b: 2\n", nil, true},
+		{"tab indentation:", "a:\n\tb: 2\n", nil, true},
+		{"unterminated string:", "a: \"x\n", nil, true},
+		{"unterminated flow sequence:", "a: [1, 2\n", nil, true},
+		{"value after string:", "a: \"x\" y\n", nil, true},
+		{"multi-line scalar:", "a: |\n  x\n", nil, true},
+		{"anchor:", "a: &x 1\n", nil, true},
+		{"not a mapping:", "- a\n- b\n", nil, true},
+		{"multiple documents:", "a: 1\n---\nb: 2\n", nil, true},
+	}
+
+	for _, tc := range testCases {
+		var doc map[string]interface{}
+		err := UnmarshalYAML([]byte(tc.doc), &doc)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v UnmarshalYAML(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if !tc.expErr && !reflect.DeepEqual(doc, tc.exp) {
+			t.Errorf("%v UnmarshalYAML(): \nexpected %#v, \nactual   %#v", tc.msg, tc.exp, doc)
+		}
+	}
+}
+
+func TestUnmarshalYAMLStruct(t *testing.T) {
+	var v struct {
+		Name  string   `json:"name"`
+		Sizes []int    `json:"sizes"`
+		Rate  *float64 `json:"rate"`
+	}
+
+	err := UnmarshalYAML([]byte("name: test\nsizes: [1, 2]\nrate: 0.5\n"), &v)
+	if err != nil {
+		t.Fatalf("UnmarshalYAML(): unexpected error %v", err)
+	}
+	if v.Name != "test" || !reflect.DeepEqual(v.Sizes, []int{1, 2}) || v.Rate == nil || *v.Rate != 0.5 {
+		t.Errorf("UnmarshalYAML(): unexpected struct %+v", v)
+	}
+}
@@ -0,0 +1,114 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+)
+
+// Constraint is the basic interface for a portfolio constraint, which is checked
+// for every order before its submission. A violated constraint returns an error.
+type Constraint interface {
+	Check(order OrderEvent, price float64, positions map[string]Position, equity float64) error
+}
+
+// MaxPositions limits the number of open positions.
+type MaxPositions struct {
+	Max int
+}
+
+// Check rejects an order which opens a new position above the max number of positions.
+func (c *MaxPositions) Check(order OrderEvent, price float64, positions map[string]Position, equity float64) error {
+	if pos, ok := positions[order.Symbol()]; ok && pos.qty != 0 {
+		return nil
+	}
+
+	var open int
+	for _, pos := range positions {
+		if pos.qty != 0 {
+			open++
+		}
+	}
+
+	if open >= c.Max {
+		return fmt.Errorf("max positions %d reached", c.Max)
+	}
+	return nil
+}
+
+// MaxWeight limits the weight of a single symbol in the equity of the portfolio, e.g. 0.1 for 10%.
+type MaxWeight struct {
+	Max float64
+}
+
+// Check rejects an order which increases the weight of its symbol above the max weight.
+func (c *MaxWeight) Check(order OrderEvent, price float64, positions map[string]Position, equity float64) error {
+	before := positions[order.Symbol()].qty
+	after := qtyAfter(order, before)
+	if math.Abs(after) <= math.Abs(before) || equity <= 0 {
+		return nil
+	}
+
+	weight := math.Abs(after) * price / equity
+	if weight > c.Max {
+		return fmt.Errorf("weight %.4f of %s above max weight %.4f", weight, order.Symbol(), c.Max)
+	}
+	return nil
+}
+
+// GroupLimit limits the gross exposure of a group of symbols, e.g. a sector or an asset class,
+// as share of the equity of the portfolio. Symbols without a group are not limited.
+type GroupLimit struct {
+	Groups map[string]string  // symbol to group
+	Limits map[string]float64 // group to max exposure
+}
+
+// Check rejects an order which increases the exposure of its group above the limit.
+func (c *GroupLimit) Check(order OrderEvent, price float64, positions map[string]Position, equity float64) error {
+	group, ok := c.Groups[order.Symbol()]
+	if !ok {
+		return nil
+	}
+	limit, ok := c.Limits[group]
+	if !ok || equity <= 0 {
+		return nil
+	}
+
+	before := positions[order.Symbol()].qty
+	after := qtyAfter(order, before)
+	if math.Abs(after) <= math.Abs(before) {
+		return nil
+	}
+
+	// sum up the exposure of all other symbols of the group
+	exposure := math.Abs(after) * price
+	for symbol, pos := range positions {
+		if symbol != order.Symbol() && c.Groups[symbol] == group {
+			exposure += pos.marketValue
+		}
+	}
+
+	if share := exposure / equity; share > limit {
+		return fmt.Errorf("exposure %.4f of group %s above limit %.4f", share, group, limit)
+	}
+	return nil
+}
+
+// GroupByAssetClass returns the asset class of the registered instruments as groups for a GroupLimit.
+func GroupByAssetClass(r *InstrumentRegistry) map[string]string {
+	groups := make(map[string]string)
+	for _, symbol := range r.Symbols() {
+		groups[symbol] = r.Get(symbol).AssetClass.String()
+	}
+	return groups
+}
+
+// qtyAfter returns the qty of a position after an order is filled.
+func qtyAfter(order OrderEvent, qty float64) float64 {
+	switch order.Direction() {
+	case BOT:
+		return qty + order.Qty()
+	case SLD:
+		return qty - order.Qty()
+	}
+	return qty
+}
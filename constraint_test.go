@@ -0,0 +1,56 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	positions := map[string]Position{
+		"A.DE": {symbol: "A.DE", qty: 10, marketValue: 1000},
+		"B.DE": {symbol: "B.DE", qty: 20, marketValue: 2000},
+	}
+	newOrder := func(symbol string, dir Direction, qty float64) *Order {
+		return &Order{Event: Event{timestamp: exampleTime, symbol: symbol}, direction: dir, qty: qty}
+	}
+	sectors := &GroupLimit{
+		Groups: map[string]string{"A.DE": "tech", "B.DE": "tech", "C.DE": "tech", "D.DE": "energy"},
+		Limits: map[string]float64{"tech": 0.4},
+	}
+
+	// testCases is a table for testing the portfolio constraints
+	var testCases = []struct {
+		msg        string
+		constraint Constraint
+		order      *Order
+		price      float64
+		expErr     bool
+	}{
+		{"max positions, new position rejected:", &MaxPositions{Max: 2}, newOrder("C.DE", BOT, 1), 100, true},
+		{"max positions, existing position allowed:", &MaxPositions{Max: 2}, newOrder("A.DE", BOT, 1), 100, false},
+		{"max weight, within weight:", &MaxWeight{Max: 0.2}, newOrder("A.DE", BOT, 10), 100, false},
+		{"max weight, above weight:", &MaxWeight{Max: 0.2}, newOrder("A.DE", BOT, 11), 100, true},
+		{"max weight, reducing position allowed:", &MaxWeight{Max: 0.1}, newOrder("B.DE", SLD, 5), 100, false},
+		{"group limit, above limit:", sectors, newOrder("C.DE", BOT, 11), 100, true},
+		{"group limit, within limit:", sectors, newOrder("C.DE", BOT, 10), 100, false},
+		{"group limit, other group:", sectors, newOrder("D.DE", BOT, 50), 100, false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.constraint.Check(tc.order, tc.price, positions, 10000)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Check(): \nexpected error %v, \nactual   %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
+
+func TestGroupByAssetClass(t *testing.T) {
+	r := NewInstrumentRegistry(Instrument{Symbol: "BTCUSD", AssetClass: Crypto}, Instrument{Symbol: "SPY", AssetClass: ETF})
+
+	groups := GroupByAssetClass(r)
+	if groups["BTCUSD"] != "Crypto" || groups["SPY"] != "ETF" {
+		t.Errorf("GroupByAssetClass(): unexpected groups %v", groups)
+	}
+}
@@ -0,0 +1,104 @@
+package gobacktest
+
+import (
+	"context"
+	"testing"
+)
+
+// cancellingStrategy cancels the context after a number of data events.
+type cancellingStrategy struct {
+	*Strategy
+	cancel func()
+	after  int
+	events int
+}
+
+func (s *cancellingStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.events++
+	if s.events == s.after {
+		s.cancel()
+	}
+	return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}}, nil
+}
+
+func TestBacktestRunContext(t *testing.T) {
+	// testCases is a table for testing the cancellation of a backtest
+	var testCases = []struct {
+		msg       string
+		after     int
+		expErr    error
+		expEvents int
+	}{
+		{"cancelled after two events:", 2, context.Canceled, 2},
+		{"not cancelled:", 10, nil, 5},
+	}
+
+	for _, tc := range testCases {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100, 100, 100, 100))
+
+		strategy := &cancellingStrategy{Strategy: NewStrategy("cancelling"), cancel: cancel, after: tc.after}
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(strategy)
+
+		err := test.RunContext(ctx)
+		cancel()
+		if err != tc.expErr {
+			t.Errorf("%v RunContext(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+		if strategy.events != tc.expEvents {
+			t.Errorf("%v RunContext(): expected %v events, actual %v", tc.msg, tc.expEvents, strategy.events)
+		}
+		// the signals of the processed events are completed as partial results
+		if fills := len(test.statistic.Transactions()); fills != tc.expEvents {
+			t.Errorf("%v RunContext(): expected %v fills, actual %v", tc.msg, tc.expEvents, fills)
+		}
+	}
+}
+
+func TestMultiStrategyRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	strategy := &cancellingStrategy{Strategy: NewStrategy("cancelling"), cancel: cancel, after: 1}
+
+	m := NewMultiStrategy(100000)
+	m.SetData(data)
+	m.AddStrategy("sleeve", 1, strategy)
+
+	if err := m.RunContext(ctx); err != context.Canceled {
+		t.Errorf("RunContext(): expected error %v, actual %v", context.Canceled, err)
+	}
+	if strategy.events != 1 {
+		t.Errorf("RunContext(): expected 1 event, actual %v", strategy.events)
+	}
+}
+
+func TestOptimizeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	evals := 0
+	objective := func(p Params) (float64, error) {
+		evals++
+		if evals == 3 {
+			cancel()
+		}
+		return p["x"], nil
+	}
+
+	g := &GridSearch{Budget: Budget{Context: ctx}}
+	result, err := g.Optimize([]Param{{Name: "x", Min: 0, Max: 10, Step: 1}}, objective)
+	if err != nil {
+		t.Fatalf("Optimize(): unexpected error %v", err)
+	}
+	if result.Stopped != "cancelled" || len(result.Trials) != 3 {
+		t.Errorf("Optimize(): expected cancelled after 3 trials, actual %q after %v", result.Stopped, len(result.Trials))
+	}
+}
@@ -0,0 +1,44 @@
+package gobacktest
+
+import (
+	"math"
+)
+
+// Conviction returns the share of a full size position from the strength and confidence
+// of a signal or an order, between 0.0 and 1.0. An unset strength or confidence counts as full,
+// a strength set to 0 as zero size.
+func Conviction(w Weighter) float64 {
+	strength := math.Abs(w.Strength())
+	if !w.HasStrength() || strength > 1 {
+		strength = 1
+	}
+
+	confidence := w.Confidence()
+	if confidence <= 0 || confidence > 1 {
+		confidence = 1
+	}
+
+	return strength * confidence
+}
+
+// ConvictionSize is a size handler overlay, which scales the qty of the underlying size handler
+// by the conviction of the order. Exit orders always close the complete position.
+type ConvictionSize struct {
+	SizeHandler
+	Instruments *InstrumentRegistry
+}
+
+// SizeOrder sizes an order with the underlying size handler and scales its qty by the conviction.
+func (c *ConvictionSize) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler) (*Order, error) {
+	o, err := c.SizeHandler.SizeOrder(order, data, pf)
+	if err != nil || o == nil {
+		return o, err
+	}
+
+	if order.Direction() == EXT {
+		return o, nil
+	}
+
+	o.SetQty(c.Instruments.Get(o.Symbol()).LotSize.Round(o.Qty() * Conviction(o)))
+	return o, nil
+}
@@ -0,0 +1,65 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestConviction(t *testing.T) {
+	// testCases is a table for testing the conviction of a signal
+	var testCases = []struct {
+		msg        string
+		set        bool
+		strength   float64
+		confidence float64
+		exp        float64
+	}{
+		{"unset strength and confidence:", false, 0, 0, 1},
+		{"zero strength:", true, 0, 0, 0},
+		{"half strength:", true, 0.5, 0, 0.5},
+		{"short strength:", true, -0.5, 0, 0.5},
+		{"strength and confidence:", true, 0.5, 0.5, 0.25},
+		{"strength above range:", true, 2, 0.8, 0.8},
+	}
+
+	for _, tc := range testCases {
+		s := &Signal{}
+		if tc.set {
+			s.SetStrength(tc.strength)
+		}
+		s.SetConfidence(tc.confidence)
+		if c := Conviction(s); c != tc.exp {
+			t.Errorf("%v Conviction(): \nexpected %#v, \nactual %#v", tc.msg, tc.exp, c)
+		}
+	}
+}
+
+func TestConvictionSizeOrder(t *testing.T) {
+	size := &ConvictionSize{SizeHandler: &Size{DefaultSize: 100, DefaultValue: 100000}}
+
+	// testCases is a table for testing the conviction size handler
+	var testCases = []struct {
+		msg    string
+		order  *Order
+		expQty float64
+	}{
+		{"full size without conviction:",
+			&Order{direction: BOT},
+			100,
+		},
+		{"scaled by strength:",
+			&Order{direction: BOT, strength: 0.5, hasStrength: true},
+			50,
+		},
+		{"scaled by strength and confidence, rounded down:",
+			&Order{direction: SLD, strength: -0.5, hasStrength: true, confidence: 0.33},
+			16,
+		},
+	}
+
+	for _, tc := range testCases {
+		o, err := size.SizeOrder(tc.order, &Bar{Close: 10}, &Portfolio{})
+		if err != nil || o.Qty() != tc.expQty {
+			t.Errorf("%v SizeOrder(): \nexpected %#v, \nactual %#v %v", tc.msg, tc.expQty, o.Qty(), err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// CorrelationLimit is a constraint, which limits the exposure of a cluster of correlated symbols.
+// The cluster of an order contains its symbol and all held symbols with a correlation of the
+// returns over the Lookback period of at least Threshold, e.g. 0.7. The gross exposure of the
+// cluster after the order must not exceed MaxExposure as share of the equity, e.g. 0.3.
+// With Downsize set, the order qty is reduced to the remaining capacity in whole units instead of rejected.
+type CorrelationLimit struct {
+	Data        DataHandler
+	Lookback    int // number of returns, defaults to 20
+	Threshold   float64
+	MaxExposure float64
+	Downsize    bool
+}
+
+// Check rejects or downsizes an order which pushes the exposure of its cluster above the limit.
+func (c *CorrelationLimit) Check(order OrderEvent, price float64, positions map[string]Position, equity float64) error {
+	if c.Data == nil || equity <= 0 || price <= 0 {
+		return nil
+	}
+
+	before := positions[order.Symbol()].qty
+	after := qtyAfter(order, before)
+	if math.Abs(after) <= math.Abs(before) {
+		return nil
+	}
+
+	// sum up the exposure of all held symbols correlated with the order symbol
+	var exposure float64
+	var cluster []string
+	for symbol, pos := range positions {
+		if symbol == order.Symbol() || pos.qty == 0 {
+			continue
+		}
+		if corr, ok := c.Correlation(order.Symbol(), symbol); ok && corr >= c.Threshold {
+			exposure += pos.marketValue
+			cluster = append(cluster, symbol)
+		}
+	}
+	if len(cluster) == 0 {
+		return nil
+	}
+	sort.Strings(cluster)
+
+	limit := c.MaxExposure * equity
+	if exposure+math.Abs(after)*price <= limit {
+		return nil
+	}
+
+	// reduce the order to the remaining capacity of the cluster
+	capacity := math.Floor((limit-exposure)/price) - math.Abs(before)
+	if c.Downsize && capacity > 0 {
+		order.SetQty(capacity)
+		return nil
+	}
+
+	return fmt.Errorf("exposure of correlated cluster %v above limit %.4f", append(cluster, order.Symbol()), c.MaxExposure)
+}
+
+// Correlation returns the correlation of the returns of two symbols over the lookback period.
+func (c *CorrelationLimit) Correlation(a, b string) (float64, bool) {
+	x, y := alignedReturns(c.Data.Window(a, c.lookback()+1), c.Data.Window(b, c.lookback()+1))
+	if len(x) < 2 {
+		return 0, false
+	}
+
+	corr := stat.Correlation(x, y, nil)
+	if math.IsNaN(corr) {
+		return 0, false
+	}
+	return corr, true
+}
+
+// CorrelationMatrix returns the correlation matrix of the returns of the symbols,
+// symbols without enough common data have a correlation of 0.
+func (c *CorrelationLimit) CorrelationMatrix(symbols []string) [][]float64 {
+	matrix := make([][]float64, len(symbols))
+	for i := range symbols {
+		matrix[i] = make([]float64, len(symbols))
+		matrix[i][i] = 1
+	}
+
+	for i := range symbols {
+		for j := i + 1; j < len(symbols); j++ {
+			corr, _ := c.Correlation(symbols[i], symbols[j])
+			matrix[i][j] = corr
+			matrix[j][i] = corr
+		}
+	}
+
+	return matrix
+}
+
+// lookback returns the number of returns for the correlation estimate.
+func (c *CorrelationLimit) lookback() int {
+	if c.Lookback <= 0 {
+		return 20
+	}
+	return c.Lookback
+}
+
+// alignedReturns returns the returns of two price series at the common timestamps.
+func alignedReturns(a, b []DataEvent) (x, y []float64) {
+	prices := make(map[int64]float64)
+	for _, e := range b {
+		prices[e.Time().UnixNano()] = e.Price()
+	}
+
+	var lastA, lastB float64
+	for _, e := range a {
+		priceB, ok := prices[e.Time().UnixNano()]
+		if !ok {
+			continue
+		}
+		if lastA != 0 && lastB != 0 {
+			x = append(x, e.Price()/lastA-1)
+			y = append(y, priceB/lastB-1)
+		}
+		lastA, lastB = e.Price(), priceB
+	}
+
+	return x, y
+}
@@ -0,0 +1,93 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+// newCorrelationData creates a data handler with the close prices of several symbols on consecutive days.
+func newCorrelationData(prices map[string][]float64) *Data {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	data := &Data{list: make(map[string][]DataEvent)}
+	for symbol, closes := range prices {
+		for i, c := range closes {
+			data.list[symbol] = append(data.list[symbol], &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: symbol}, Close: c})
+		}
+	}
+	return data
+}
+
+func TestCorrelationLimitCorrelation(t *testing.T) {
+	data := newCorrelationData(map[string][]float64{
+		"A.DE": {100, 102, 101, 104, 103},
+		"B.DE": {50, 51, 50.5, 52, 51.5},
+		"C.DE": {10, 9.8, 9.9, 9.6, 9.7},
+	})
+	c := &CorrelationLimit{Data: data}
+
+	if corr, ok := c.Correlation("A.DE", "B.DE"); !ok || corr < 0.99 {
+		t.Errorf("Correlation(): expected high correlation, actual %v %v", corr, ok)
+	}
+	if corr, ok := c.Correlation("A.DE", "C.DE"); !ok || corr > -0.9 {
+		t.Errorf("Correlation(): expected negative correlation, actual %v %v", corr, ok)
+	}
+	if _, ok := c.Correlation("A.DE", "X.DE"); ok {
+		t.Errorf("Correlation(): expected no correlation without data")
+	}
+
+	matrix := c.CorrelationMatrix([]string{"A.DE", "B.DE"})
+	if matrix[0][0] != 1 || matrix[0][1] != matrix[1][0] {
+		t.Errorf("CorrelationMatrix(): expected symmetric matrix, actual %v", matrix)
+	}
+}
+
+func TestCorrelationLimitCheck(t *testing.T) {
+	data := newCorrelationData(map[string][]float64{
+		"A.DE": {100, 102, 101, 104, 103},
+		"B.DE": {50, 51, 50.5, 52, 51.5},
+		"C.DE": {10, 9.8, 9.9, 9.6, 9.7},
+	})
+	positions := map[string]Position{
+		"B.DE": {symbol: "B.DE", qty: 40, marketValue: 2000},
+		"C.DE": {symbol: "C.DE", qty: 200, marketValue: 2000},
+	}
+
+	// testCases is a table for testing the correlation limit
+	var testCases = []struct {
+		msg    string
+		limit  *CorrelationLimit
+		order  *Order
+		expQty float64
+		expErr bool
+	}{
+		{"within limit:",
+			&CorrelationLimit{Data: data, Threshold: 0.7, MaxExposure: 0.3},
+			&Order{Event: Event{symbol: "A.DE"}, direction: BOT, qty: 10},
+			10, false,
+		},
+		{"above limit rejected:",
+			&CorrelationLimit{Data: data, Threshold: 0.7, MaxExposure: 0.3},
+			&Order{Event: Event{symbol: "A.DE"}, direction: BOT, qty: 11},
+			11, true,
+		},
+		{"above limit downsized:",
+			&CorrelationLimit{Data: data, Threshold: 0.7, MaxExposure: 0.3, Downsize: true},
+			&Order{Event: Event{symbol: "A.DE"}, direction: BOT, qty: 15},
+			10, false,
+		},
+		{"uncorrelated position not counted:",
+			&CorrelationLimit{Data: data, Threshold: 0.7, MaxExposure: 0.3},
+			&Order{Event: Event{symbol: "C.DE"}, direction: BOT, qty: 100},
+			100, false,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.limit.Check(tc.order, 100, positions, 10000)
+		if (err != nil) != tc.expErr || tc.order.Qty() != tc.expQty {
+			t.Errorf("%v Check(): \nexpected qty %v error %v, \nactual   qty %v error %v",
+				tc.msg, tc.expQty, tc.expErr, tc.order.Qty(), err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gobacktest
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradeCosts are the costs of trades relative to their decision price, positive for a cost.
+// Slippage is the difference of the fill price to the decision price without the market
+// impact, e.g. the spread, the delay of a resting order or the rounding to the tick size.
+type TradeCosts struct {
+	Notional    float64 // traded value at the decision price
+	Slippage    float64
+	Impact      float64
+	Commission  float64
+	ExchangeFee float64
+}
+
+// Total returns the sum of all costs.
+func (c TradeCosts) Total() float64 {
+	return c.Slippage + c.Impact + c.Commission + c.ExchangeFee
+}
+
+// Bps returns the total costs in basis points of the notional.
+func (c TradeCosts) Bps() float64 {
+	if c.Notional == 0 {
+		return 0
+	}
+	return c.Total() / c.Notional * 10000
+}
+
+// add adds the costs of a trade.
+func (c *TradeCosts) add(o TradeCosts) {
+	c.Notional += o.Notional
+	c.Slippage += o.Slippage
+	c.Impact += o.Impact
+	c.Commission += o.Commission
+	c.ExchangeFee += o.ExchangeFee
+}
+
+// TradeCost is the cost attribution of a transaction.
+type TradeCost struct {
+	Time          time.Time
+	Symbol        string
+	Direction     Direction
+	Qty           float64
+	DecisionPrice float64 // price of the data event which caused the trade
+	FillPrice     float64
+	FillID        int
+	TradeCosts
+}
+
+// impacter returns the market impact per share of a fill.
+type impacter interface {
+	Impact() float64
+}
+
+// CostAttribution returns the costs of each transaction split into slippage, market impact,
+// commission and exchange fee. The decision price is the price of the data event at the root
+// of the lineage of the fill, e.g. the bar the strategy created the signal on. A fill without
+// a data event in its lineage has no slippage.
+func (s Statistic) CostAttribution() []TradeCost {
+	index := s.eventIndex()
+
+	var costs []TradeCost
+	for _, fill := range s.transactionHistory {
+		sign := 1.0
+		if fill.Direction() == SLD {
+			sign = -1
+		}
+		var impact float64
+		if i, ok := fill.(impacter); ok {
+			impact = i.Impact()
+		}
+
+		// the decision price is the price of the root data event
+		decision := fill.Price() - sign*impact
+		for id := parentID(fill); id != 0; {
+			parent, ok := index[id]
+			if !ok {
+				break
+			}
+			if data, ok := parent.(DataEvent); ok {
+				decision = data.Price()
+			}
+			id = parentID(parent)
+		}
+
+		// the costs in money of the qty of contracts
+		qty := fill.Qty() * contractMultiplier(fill)
+		cost := TradeCost{
+			Time:          fill.Time(),
+			Symbol:        fill.Symbol(),
+			Direction:     fill.Direction(),
+			Qty:           fill.Qty(),
+			DecisionPrice: decision,
+			FillPrice:     fill.Price(),
+			FillID:        eventID(fill),
+			TradeCosts: TradeCosts{
+				Notional:    qty * decision,
+				Impact:      qty * impact,
+				Commission:  fill.Commission(),
+				ExchangeFee: fill.ExchangeFee(),
+			},
+		}
+		cost.Slippage = sign*qty*(fill.Price()-decision) - cost.Impact
+		costs = append(costs, cost)
+	}
+
+	return costs
+}
+
+// TotalCosts returns the costs of all transactions.
+func (s Statistic) TotalCosts() TradeCosts {
+	var total TradeCosts
+	for _, c := range s.CostAttribution() {
+		total.add(c.TradeCosts)
+	}
+	return total
+}
+
+// CostsBySymbol returns the costs of all transactions of each symbol.
+func (s Statistic) CostsBySymbol() map[string]TradeCosts {
+	costs := make(map[string]TradeCosts)
+	for _, c := range s.CostAttribution() {
+		total := costs[c.Symbol]
+		total.add(c.TradeCosts)
+		costs[c.Symbol] = total
+	}
+	return costs
+}
+
+// printCosts prints the attribution of the costs of all transactions.
+func (s Statistic) printCosts() {
+	if len(s.transactionHistory) == 0 {
+		return
+	}
+	c := s.TotalCosts()
+	fmt.Printf("Costs: slippage %.2f impact %.2f commission %.2f exchange fee %.2f, total %.2f (%.1f bps)\n",
+		c.Slippage, c.Impact, c.Commission, c.ExchangeFee, c.Total(), c.Bps())
+}
@@ -0,0 +1,93 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCostAttribution(t *testing.T) {
+	bar := &Bar{Event: Event{eventID: 1, symbol: "TEST.DE"}, Close: 100}
+	signal := &Signal{Event: Event{eventID: 2, parentID: 1}}
+	order := &Order{Event: Event{eventID: 3, parentID: 2}}
+
+	// testCases is a table for testing the cost attribution of a fill
+	var testCases = []struct {
+		msg         string
+		fill        *Fill
+		expDecision float64
+		exp         TradeCosts
+	}{
+		{"buy with impact and slippage:",
+			&Fill{Event: Event{eventID: 4, parentID: 3, symbol: "TEST.DE"}, direction: BOT, qty: 10, price: 101.5, impact: 0.5, commission: 2, exchangeFee: 1},
+			100, TradeCosts{Notional: 1000, Slippage: 10, Impact: 5, Commission: 2, ExchangeFee: 1}},
+		{"sell better than the decision price:",
+			&Fill{Event: Event{eventID: 5, parentID: 3, symbol: "TEST.DE"}, direction: SLD, qty: 10, price: 101},
+			100, TradeCosts{Notional: 1000, Slippage: -10}},
+		{"fill without lineage:",
+			&Fill{Event: Event{symbol: "TEST.DE"}, direction: SLD, qty: 10, price: 99.5, impact: 0.5},
+			100, TradeCosts{Notional: 1000, Impact: 5}},
+	}
+
+	for _, tc := range testCases {
+		s := Statistic{
+			eventHistory:       []EventHandler{bar, signal, order, tc.fill},
+			transactionHistory: []FillEvent{tc.fill},
+		}
+		costs := s.CostAttribution()
+		if len(costs) != 1 {
+			t.Errorf("%v CostAttribution(): expected one trade, actual %v", tc.msg, len(costs))
+			continue
+		}
+		c := costs[0]
+		if c.DecisionPrice != tc.expDecision || math.Abs(c.Slippage-tc.exp.Slippage) > 1e-9 || c.Impact != tc.exp.Impact ||
+			c.Notional != tc.exp.Notional || c.Commission != tc.exp.Commission || c.ExchangeFee != tc.exp.ExchangeFee {
+			t.Errorf("%v CostAttribution(): \nexpected %v %+v, \nactual   %v %+v", tc.msg, tc.expDecision, tc.exp, c.DecisionPrice, c.TradeCosts)
+		}
+	}
+}
+
+func TestTradeCostsTotal(t *testing.T) {
+	c := TradeCosts{Notional: 1000, Slippage: 10, Impact: 5, Commission: 2, ExchangeFee: 1}
+	if c.Total() != 18 || c.Bps() != 180 {
+		t.Errorf("Total(): expected 18 or 180 bps, actual %v %v", c.Total(), c.Bps())
+	}
+	if (TradeCosts{}).Bps() != 0 {
+		t.Errorf("Bps(): expected 0 without notional")
+	}
+}
+
+func TestBacktestCostAttribution(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var stream []DataEvent
+	for i := 0; i < 3; i++ {
+		stream = append(stream, &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: "TEST.DE"}, Close: 100, Volume: 1000})
+	}
+	data := &Data{}
+	data.SetStream(stream)
+
+	exchange := NewExchange()
+	exchange.Commission = &FixedCommission{Commission: 1}
+	exchange.ImpactModel = &LinearImpact{Coefficient: 0.1}
+
+	test := New()
+	test.SetData(data)
+	test.SetExchange(exchange)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	s := test.statistic.(*Statistic)
+	total := s.TotalCosts()
+	if len(s.Transactions()) == 0 || total.Impact <= 0 || math.Abs(total.Slippage) > 1e-6 {
+		t.Errorf("TotalCosts(): expected the impact of market orders without slippage, actual %+v", total)
+	}
+	if total.Commission != float64(len(s.Transactions())) {
+		t.Errorf("TotalCosts(): expected a commission of 1 per trade, actual %v", total.Commission)
+	}
+	if bySymbol := s.CostsBySymbol(); bySymbol["TEST.DE"] != total {
+		t.Errorf("CostsBySymbol(): expected the total of the symbol, actual %+v", bySymbol)
+	}
+}
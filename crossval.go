@@ -0,0 +1,238 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Evaluator runs a backtest with the parameters on a segment of the data stream and returns its score.
+type Evaluator func(Params, []DataEvent) (float64, error)
+
+// Segment is a time range of the data stream, the end is exclusive.
+type Segment struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains checks if a time is within the segment.
+func (s Segment) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// Events returns the data events of a stream within the segments.
+func Events(stream []DataEvent, segments ...Segment) []DataEvent {
+	var events []DataEvent
+	for _, e := range stream {
+		for _, s := range segments {
+			if s.Contains(e.Time()) {
+				events = append(events, e)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// Degradation returns the relative loss of the out-of-sample score against the in-sample score,
+// e.g. 0.5 if the out-of-sample score is half the in-sample score.
+func Degradation(inSample, outOfSample float64) float64 {
+	if inSample == 0 {
+		return 0
+	}
+	d := (inSample - outOfSample) / math.Abs(inSample)
+	return math.Round(d*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// defaultOverfit is the degradation above which a result is flagged as overfitted.
+const defaultOverfit = 0.5
+
+// TrainTestSplit partitions the data stream by time into a train, a validation and a test segment,
+// e.g. Train 0.6 and Validation 0.2 leave the last 20% for the test. The parameters are optimized
+// on the train segment only, and then scored once on the validation and the test segment.
+type TrainTestSplit struct {
+	Optimizer  Optimizer
+	Train      float64
+	Validation float64
+	Overfit    float64 // degradation flagged as overfitting, defaults to 0.5
+}
+
+// SplitReport holds the scores of the best parameters on each segment.
+type SplitReport struct {
+	Best            Params
+	Train           Segment
+	Validation      Segment
+	Test            Segment
+	TrainScore      float64
+	ValidationScore float64
+	TestScore       float64
+	Degradation     float64 // of the test against the train score
+	Overfit         bool
+}
+
+// Run optimizes on the train segment and scores the best parameters out of sample.
+func (s *TrainTestSplit) Run(space []Param, stream []DataEvent, evaluate Evaluator) (SplitReport, error) {
+	if s.Train <= 0 || s.Validation < 0 || s.Train+s.Validation >= 1 {
+		return SplitReport{}, errors.New("invalid split, train and validation must leave a test segment")
+	}
+	times := distinctTimes(stream)
+	if len(times) < 3 {
+		return SplitReport{}, errors.New("could not split data stream, not enough data")
+	}
+
+	n := len(times)
+	trainEnd := int(math.Round(float64(n) * s.Train))
+	validationEnd := int(math.Round(float64(n) * (s.Train + s.Validation)))
+	if trainEnd < 1 || validationEnd >= n {
+		return SplitReport{}, errors.New("could not split data stream, segment without data")
+	}
+
+	end := times[n-1].Add(time.Nanosecond)
+	report := SplitReport{
+		Train:      Segment{Start: times[0], End: times[trainEnd]},
+		Validation: Segment{Start: times[trainEnd], End: times[validationEnd]},
+		Test:       Segment{Start: times[validationEnd], End: end},
+	}
+
+	train := Events(stream, report.Train)
+	result, err := s.Optimizer.Optimize(space, func(p Params) (float64, error) {
+		return evaluate(p, train)
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Best = result.Best
+	report.TrainScore = result.Score
+
+	if s.Validation > 0 {
+		report.ValidationScore, err = evaluate(result.Best, Events(stream, report.Validation))
+		if err != nil {
+			return report, err
+		}
+	}
+	report.TestScore, err = evaluate(result.Best, Events(stream, report.Test))
+	if err != nil {
+		return report, err
+	}
+
+	report.Degradation = Degradation(report.TrainScore, report.TestScore)
+	report.Overfit = report.Degradation > overfitLimit(s.Overfit)
+	return report, nil
+}
+
+// PurgedKFold splits the data stream by time into K folds. Each fold is the test segment once,
+// while the parameters are optimized on the other folds. Train data within Purge before
+// and within Embargo after the test fold is removed, to prevent leakage of overlapping trades.
+type PurgedKFold struct {
+	Optimizer Optimizer
+	K         int // defaults to 5
+	Purge     time.Duration
+	Embargo   time.Duration
+	Overfit   float64 // degradation flagged as overfitting, defaults to 0.5
+}
+
+// FoldReport holds the result of a single fold.
+type FoldReport struct {
+	Test       Segment
+	Train      []Segment
+	Best       Params
+	TrainScore float64
+	TestScore  float64
+}
+
+// CVReport holds the results of all folds and the average degradation out of sample.
+type CVReport struct {
+	Folds       []FoldReport
+	TrainScore  float64 // average over all folds
+	TestScore   float64 // average over all folds
+	Degradation float64
+	Overfit     bool
+}
+
+// Run optimizes and scores every fold.
+func (k *PurgedKFold) Run(space []Param, stream []DataEvent, evaluate Evaluator) (CVReport, error) {
+	folds := k.K
+	if folds <= 0 {
+		folds = 5
+	}
+	times := distinctTimes(stream)
+	if folds < 2 || len(times) < folds {
+		return CVReport{}, errors.New("could not split data stream, not enough data for the folds")
+	}
+
+	// boundaries of the folds
+	bounds := make([]time.Time, folds+1)
+	for i := 0; i < folds; i++ {
+		bounds[i] = times[i*len(times)/folds]
+	}
+	bounds[folds] = times[len(times)-1].Add(time.Nanosecond)
+
+	var report CVReport
+	for i := 0; i < folds; i++ {
+		test := Segment{Start: bounds[i], End: bounds[i+1]}
+
+		// train on the folds before and after the test fold, without purge and embargo
+		var train []Segment
+		if before := (Segment{Start: bounds[0], End: test.Start.Add(-k.Purge)}); before.End.After(before.Start) {
+			train = append(train, before)
+		}
+		if after := (Segment{Start: test.End.Add(k.Embargo), End: bounds[folds]}); after.End.After(after.Start) {
+			train = append(train, after)
+		}
+
+		events := Events(stream, train...)
+		if len(events) == 0 {
+			return report, errors.New("could not split data stream, train folds without data")
+		}
+
+		result, err := k.Optimizer.Optimize(space, func(p Params) (float64, error) {
+			return evaluate(p, events)
+		})
+		if err != nil {
+			return report, err
+		}
+		score, err := evaluate(result.Best, Events(stream, test))
+		if err != nil {
+			return report, err
+		}
+
+		report.Folds = append(report.Folds, FoldReport{
+			Test:       test,
+			Train:      train,
+			Best:       result.Best,
+			TrainScore: result.Score,
+			TestScore:  score,
+		})
+		report.TrainScore += result.Score / float64(folds)
+		report.TestScore += score / float64(folds)
+	}
+
+	report.Degradation = Degradation(report.TrainScore, report.TestScore)
+	report.Overfit = report.Degradation > overfitLimit(k.Overfit)
+	return report, nil
+}
+
+// overfitLimit returns the degradation limit, defaults to 0.5.
+func overfitLimit(limit float64) float64 {
+	if limit <= 0 {
+		return defaultOverfit
+	}
+	return limit
+}
+
+// distinctTimes returns the sorted distinct timestamps of a data stream.
+func distinctTimes(stream []DataEvent) []time.Time {
+	set := make(map[time.Time]bool)
+	var times []time.Time
+	for _, e := range stream {
+		if !set[e.Time()] {
+			set[e.Time()] = true
+			times = append(times, e.Time())
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	return times
+}
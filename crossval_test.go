@@ -0,0 +1,75 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+// meanCloseEvaluator scores the parameter x by its distance to the mean close price of the events.
+func meanCloseEvaluator(p Params, events []DataEvent) (float64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	var sum float64
+	for _, e := range events {
+		sum += e.Price()
+	}
+	return 100 - (p["x"]-sum/float64(len(events)))*(p["x"]-sum/float64(len(events))), nil
+}
+
+func TestTrainTestSplitRun(t *testing.T) {
+	// the price level changes after the train segment
+	stream := newStressStream(10, 10, 10, 10, 10, 10, 20, 20, 20, 20)
+	space := []Param{{Name: "x", Min: 0, Max: 30, Step: 1}}
+
+	split := &TrainTestSplit{Optimizer: &GridSearch{}, Train: 0.6, Validation: 0.2}
+	report, err := split.Run(space, stream, meanCloseEvaluator)
+	if err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	if !report.Train.Start.Equal(day) || !report.Validation.Start.Equal(day.AddDate(0, 0, 6)) || !report.Test.Start.Equal(day.AddDate(0, 0, 8)) {
+		t.Errorf("Run(): unexpected segments %#v", report)
+	}
+	if report.Best["x"] != 10 || report.TrainScore != 100 || report.TestScore != 0 {
+		t.Errorf("Run(): expected best x 10 with train 100 and test 0, actual %v %v %v", report.Best, report.TrainScore, report.TestScore)
+	}
+	if report.Degradation != 1 || !report.Overfit {
+		t.Errorf("Run(): expected degradation 1 flagged as overfit, actual %v %v", report.Degradation, report.Overfit)
+	}
+
+	if _, err := (&TrainTestSplit{Optimizer: &GridSearch{}, Train: 0.8, Validation: 0.2}).Run(space, stream, meanCloseEvaluator); err == nil {
+		t.Errorf("Run(): expected error without test segment")
+	}
+}
+
+func TestPurgedKFoldRun(t *testing.T) {
+	stream := newStressStream(10, 10, 10, 10, 10, 10, 10, 10, 10, 10)
+	space := []Param{{Name: "x", Min: 0, Max: 30, Step: 1}}
+
+	cv := &PurgedKFold{Optimizer: &GridSearch{}, K: 5, Purge: 24 * time.Hour, Embargo: 24 * time.Hour}
+	report, err := cv.Run(space, stream, meanCloseEvaluator)
+	if err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if len(report.Folds) != 5 {
+		t.Fatalf("Run(): expected 5 folds, actual %v", len(report.Folds))
+	}
+
+	// the second fold covers day 2 and 3, purge removes day 1, embargo removes day 4
+	fold := report.Folds[1]
+	train := Events(stream, fold.Train...)
+	if len(train) != 6 {
+		t.Errorf("Run(): expected 6 train events in the second fold, actual %v", len(train))
+	}
+	for _, e := range train {
+		if fold.Test.Contains(e.Time()) {
+			t.Errorf("Run(): train event %v within the test fold", e.Time())
+		}
+	}
+
+	if report.TestScore != 100 || report.Degradation != 0 || report.Overfit {
+		t.Errorf("Run(): expected no degradation, actual %#v", report)
+	}
+}
@@ -0,0 +1,119 @@
+package gobacktest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CustomEvent declares a user-defined event, e.g. an earnings release or a funding rate.
+// The kind names the event type in a recorded event stream.
+type CustomEvent interface {
+	EventHandler
+	Kind() string
+}
+
+// CustomEventHandler is implemented by a strategy to receive custom events.
+// The strategy type-switches on the concrete event and may answer with signals.
+type CustomEventHandler interface {
+	OnEvent(CustomEvent) ([]SignalEvent, error)
+}
+
+// ValueRecorder is implemented by a custom event to store its values in an event record.
+type ValueRecorder interface {
+	RecordValues() map[string]float64
+}
+
+// TextRecorder is implemented by a custom event to store its text fields in an event record.
+type TextRecorder interface {
+	RecordText() map[string]string
+}
+
+// EventDecoder restores a custom event from its record.
+type EventDecoder func(EventRecord) (CustomEvent, error)
+
+// eventDecoders holds the registered decoders of custom events by kind.
+var eventDecoders = struct {
+	sync.RWMutex
+	m map[string]EventDecoder
+}{m: make(map[string]EventDecoder)}
+
+// RegisterEvent registers the decoder of a custom event kind, so a recorded event stream
+// containing the custom event can be replayed.
+func RegisterEvent(kind string, decoder EventDecoder) error {
+	switch kind {
+	case "", "bar", "tick", "book", "signal", "order", "fill", "rejection":
+		return fmt.Errorf("can not register event kind %q", kind)
+	}
+
+	eventDecoders.Lock()
+	defer eventDecoders.Unlock()
+	eventDecoders.m[kind] = decoder
+	return nil
+}
+
+// decodeEvent restores a custom event with the decoder registered for the kind of the record.
+func decodeEvent(r EventRecord) (CustomEvent, error) {
+	eventDecoders.RLock()
+	decoder, ok := eventDecoders.m[r.Type]
+	eventDecoders.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("can not replay event of type %s", r.Type)
+	}
+	return decoder(r)
+}
+
+// ReplayCustom returns the registered custom events of a recorded event stream,
+// ready to be added to a new run. Records of unregistered kinds are skipped.
+func ReplayCustom(records []EventRecord) ([]CustomEvent, error) {
+	var events []CustomEvent
+
+	for _, r := range records {
+		eventDecoders.RLock()
+		_, ok := eventDecoders.m[r.Type]
+		eventDecoders.RUnlock()
+		if !ok {
+			continue
+		}
+
+		e, err := decodeEvent(r)
+		if err != nil {
+			return events, err
+		}
+		// a replayed run assigns new ids
+		if l, ok := e.(Lineager); ok {
+			l.SetEventID(0)
+			l.SetParentID(0)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// AddEvents adds custom events to the backtest. Each custom event is queued with the
+// data events of its time and processed after the data events of the same time.
+func (t *Backtest) AddEvents(events ...CustomEvent) {
+	t.custom = append(t.custom, events...)
+	sort.SliceStable(t.custom, func(i, j int) bool {
+		return t.custom[i].Time().Before(t.custom[j].Time())
+	})
+}
+
+// release queues all pending custom events up to the time, a zero time releases all.
+// It returns true if any event was queued.
+func (t *Backtest) release(until time.Time) bool {
+	released := false
+	for t.customNext < len(t.custom) {
+		e := t.custom[t.customNext]
+		if !until.IsZero() && e.Time().After(until) {
+			break
+		}
+		t.enqueue(e, nil)
+		t.customNext++
+		released = true
+	}
+	return released
+}
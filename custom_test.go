@@ -0,0 +1,116 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// earningsEvent is a custom event for testing.
+type earningsEvent struct {
+	Event
+	Surprise float64
+}
+
+func (e earningsEvent) Kind() string {
+	return "earnings"
+}
+
+func (e earningsEvent) RecordValues() map[string]float64 {
+	return map[string]float64{"surprise": e.Surprise}
+}
+
+// earningsStrategy buys on a positive earnings surprise and records the order of received events.
+type earningsStrategy struct {
+	*Strategy
+	received []string
+}
+
+func (s *earningsStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.received = append(s.received, "bar")
+	return nil, nil
+}
+
+func (s *earningsStrategy) OnEvent(event CustomEvent) ([]SignalEvent, error) {
+	switch e := event.(type) {
+	case *earningsEvent:
+		s.received = append(s.received, e.Kind())
+		if e.Surprise > 0 {
+			return []SignalEvent{&Signal{Event: Event{timestamp: e.Time(), symbol: e.Symbol()}, direction: BOT}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestBacktestCustomEvents(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	strategy := &earningsStrategy{Strategy: NewStrategy("earnings")}
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(strategy)
+	test.AddEvents(
+		&earningsEvent{Event: Event{timestamp: day.AddDate(0, 0, 5), symbol: "TEST.DE"}, Surprise: -1},
+		&earningsEvent{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "TEST.DE"}, Surprise: 0.2},
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := test.Run(); err != nil {
+			t.Fatalf("Run(): unexpected error %v", err)
+		}
+
+		exp := []string{"bar", "bar", "earnings", "bar", "earnings"}
+		if !reflect.DeepEqual(strategy.received, exp) {
+			t.Errorf("run %v: expected events %v, actual %v", i, exp, strategy.received)
+		}
+		if fills := len(test.statistic.Transactions()); fills != 1 {
+			t.Errorf("run %v: expected 1 fill, actual %v", i, fills)
+		}
+
+		strategy.received = nil
+		test.Reset()
+	}
+}
+
+func TestRegisterEvent(t *testing.T) {
+	if err := RegisterEvent("bar", nil); err == nil {
+		t.Errorf("RegisterEvent(bar): expected error, actual nil")
+	}
+
+	err := RegisterEvent("earnings", func(r EventRecord) (CustomEvent, error) {
+		return &earningsEvent{Event: Event{timestamp: r.Time, symbol: r.Symbol, eventID: r.ID, parentID: r.Parent}, Surprise: r.Values["surprise"]}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterEvent(earnings): unexpected error %v", err)
+	}
+
+	ts := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	event := &earningsEvent{Event: Event{timestamp: ts, symbol: "TEST", eventID: 3, parentID: 1}, Surprise: 0.5}
+
+	records := NewEventRecords([]EventHandler{
+		&Bar{Event: Event{timestamp: ts, symbol: "TEST", eventID: 1}},
+		event,
+		&testEvent{},
+	})
+
+	restored, err := records[1].Event()
+	if err != nil {
+		t.Fatalf("Event(): unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(restored, event) {
+		t.Errorf("Event(): expected %#v, actual %#v", event, restored)
+	}
+
+	custom, err := ReplayCustom(records)
+	if err != nil {
+		t.Fatalf("ReplayCustom(): unexpected error %v", err)
+	}
+	exp := []CustomEvent{&earningsEvent{Event: Event{timestamp: ts, symbol: "TEST"}, Surprise: 0.5}}
+	if !reflect.DeepEqual(custom, exp) {
+		t.Errorf("ReplayCustom(): expected %#v, actual %#v", exp, custom)
+	}
+}
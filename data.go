@@ -2,6 +2,7 @@ package gobacktest
 
 import (
 	"sort"
+	"time"
 )
 
 // DataHandler is the combined data interface.
@@ -23,14 +24,18 @@ type DataStreamer interface {
 	History() []DataEvent
 	Latest(string) DataEvent
 	List(string) []DataEvent
+	Window(string, int) []DataEvent
+	Between(string, time.Time, time.Time) []DataEvent
 }
 
 // Data is a basic data provider struct.
 type Data struct {
-	latest  map[string]DataEvent
-	list    map[string][]DataEvent
-	stream  []DataEvent
-	history []DataEvent
+	latest       map[string]DataEvent
+	list         map[string][]DataEvent
+	stream       []DataEvent
+	history      []DataEvent
+	historyLimit int
+	log          Logger
 }
 
 // Load data events into a stream.
@@ -49,6 +54,11 @@ func (d *Data) Reset() error {
 	return nil
 }
 
+// SetLogger implements LogSetter.
+func (d *Data) SetLogger(l Logger) {
+	d.log = l
+}
+
 // Stream returns the data stream.
 func (d *Data) Stream() []DataEvent {
 	return d.stream
@@ -59,6 +69,13 @@ func (d *Data) SetStream(stream []DataEvent) {
 	d.stream = stream
 }
 
+// SetHistoryLimit limits the number of data events kept in the history
+// and in the list of each symbol. A limit of 0 keeps all data events.
+// A limited history can not be replayed completely after a Reset.
+func (d *Data) SetHistoryLimit(n int) {
+	d.historyLimit = n
+}
+
 // Next returns the first element of the data stream,
 // deletes it from the data stream and appends it to the historic data stream.
 func (d *Data) Next() (dh DataEvent, ok bool) {
@@ -70,12 +87,17 @@ func (d *Data) Next() (dh DataEvent, ok bool) {
 	dh = d.stream[0]
 	d.stream = d.stream[1:] // delete first element from stream
 	d.history = append(d.history, dh)
+	if d.historyLimit > 0 && len(d.history) > d.historyLimit {
+		d.history = d.history[len(d.history)-d.historyLimit:]
+	}
 
 	// update list of current data events
 	d.updateLatest(dh)
 	// update list of data events for single symbol
 	d.updateList(dh)
 
+	logger(d.log).Debug("data event", "symbol", dh.Symbol(), "time", dh.Time(), "price", dh.Price())
+
 	return dh, true
 }
 
@@ -94,6 +116,40 @@ func (d *Data) List(symbol string) []DataEvent {
 	return d.list[symbol]
 }
 
+// Window returns the last n already streamed data events for a symbol,
+// ordered from oldest to latest. If less than n events are known, all are returned.
+func (d *Data) Window(symbol string, n int) []DataEvent {
+	list := d.list[symbol]
+	if n <= 0 || len(list) == 0 {
+		return []DataEvent{}
+	}
+
+	if n > len(list) {
+		n = len(list)
+	}
+
+	// return a copy, so the underlying list can not be altered
+	window := make([]DataEvent, n)
+	copy(window, list[len(list)-n:])
+
+	return window
+}
+
+// Between returns all already streamed data events for a symbol
+// within the time range from and to, both inclusive.
+func (d *Data) Between(symbol string, from, to time.Time) []DataEvent {
+	var events = []DataEvent{}
+
+	for _, event := range d.list[symbol] {
+		if event.Time().Before(from) || event.Time().After(to) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
 // SortStream sorts the data stream in ascending order.
 func (d *Data) SortStream() {
 	sort.Slice(d.stream, func(i, j int) bool {
@@ -126,7 +182,11 @@ func (d *Data) updateList(event DataEvent) {
 		d.list = make(map[string][]DataEvent)
 	}
 
-	d.list[event.Symbol()] = append(d.list[event.Symbol()], event)
+	list := append(d.list[event.Symbol()], event)
+	if d.historyLimit > 0 && len(list) > d.historyLimit {
+		list = list[len(list)-d.historyLimit:]
+	}
+	d.list[event.Symbol()] = list
 }
 
 // DataEvent declares a data event interface
@@ -155,7 +215,7 @@ type Bar struct {
 	Low      float64
 	Close    float64
 	AdjClose float64
-	Volume   int64
+	Volume   float64
 }
 
 // Price returns the close price of the bar event.
@@ -180,8 +240,8 @@ type Tick struct {
 	Metric
 	Bid       float64
 	Ask       float64
-	BidVolume int64
-	AskVolume int64
+	BidVolume float64
+	AskVolume float64
 }
 
 // Price returns the middle of Bid and Ask.
@@ -0,0 +1,127 @@
+package data
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Decompressor wraps a compressed reader into a reader of the uncompressed content.
+type Decompressor func(io.Reader) (io.ReadCloser, error)
+
+// decompressors holds the registered decompressors by file extension.
+var decompressors = struct {
+	sync.RWMutex
+	m map[string]Decompressor
+}{
+	m: map[string]Decompressor{
+		".gz": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+}
+
+// RegisterDecompressor registers a decompressor for a file extension, e.g. ".zst".
+// Gzip compressed files with the ".gz" extension are supported out of the box.
+// The zstd format is not part of the standard library, it can be plugged in with
+// any zstd implementation, e.g. with github.com/klauspost/compress/zstd:
+//
+//	data.RegisterDecompressor(".zst", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+func RegisterDecompressor(ext string, fn Decompressor) {
+	decompressors.Lock()
+	defer decompressors.Unlock()
+	decompressors.m[ext] = fn
+}
+
+// decompressorFor returns the registered decompressor for the extension of a file name.
+func decompressorFor(name string) (string, Decompressor, bool) {
+	decompressors.RLock()
+	defer decompressors.RUnlock()
+
+	for ext, fn := range decompressors.m {
+		if strings.HasSuffix(name, ext) {
+			return ext, fn, true
+		}
+	}
+	return "", nil, false
+}
+
+// openFile opens a file and transparently decompresses it,
+// if a decompressor for the file extension is registered.
+func openFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, fn, ok := decompressorFor(path)
+	if !ok {
+		return file, nil
+	}
+
+	reader, err := fn(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &multiCloser{Reader: reader, closers: []io.Closer{reader, file}}, nil
+}
+
+// multiCloser closes all underlying readers.
+type multiCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes all underlying readers and returns the first error.
+func (mc *multiCloser) Close() (err error) {
+	for _, c := range mc.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// csvFileName returns the name of an existing csv file for a symbol within a directory,
+// looking for uncompressed and compressed variants. It defaults to the uncompressed name.
+func csvFileName(dir, symbol string) string {
+	name := symbol + ".csv"
+	if _, err := os.Stat(dir + name); err == nil {
+		return name
+	}
+
+	decompressors.RLock()
+	defer decompressors.RUnlock()
+
+	for ext := range decompressors.m {
+		if _, err := os.Stat(dir + name + ext); err == nil {
+			return name + ext
+		}
+	}
+
+	return name
+}
+
+// trimCSVExt removes a csv extension incl. a compression extension from a file name.
+// It returns false if the file is not a csv file.
+func trimCSVExt(filename string) (string, bool) {
+	if ext, _, ok := decompressorFor(filename); ok {
+		filename = strings.TrimSuffix(filename, ext)
+	}
+
+	if !strings.HasSuffix(filename, ".csv") {
+		return filename, false
+	}
+
+	return strings.TrimSuffix(filename, ".csv"), true
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadGzipCSVFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobacktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := os.Create(filepath.Join(dir, "TEST.DE.csv.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(file)
+	zw.Write([]byte("Date,Open,High,Low,Close,Adj Close,Volume\n" +
+		"2017-07-24,8.00,12.00,7.00,10.00,10.00,100\n" +
+		"2017-07-25,9.00,13.50,8.00,12.00,12.00,110\n"))
+	zw.Close()
+	file.Close()
+
+	var testCases = []struct {
+		msg     string
+		symbols []string
+	}{
+		{"load compressed file by symbol:", []string{"TEST.DE"}},
+		{"load compressed file from directory:", []string{}},
+	}
+
+	for _, tc := range testCases {
+		data := &BarEventFromCSVFile{FileDir: dir + "/"}
+		err := data.Load(tc.symbols)
+		if err != nil {
+			t.Errorf("%v Load(): unexpected error %v", tc.msg, err)
+		}
+		if len(data.Stream()) != 2 {
+			t.Errorf("%v Load(): expected %v events, actual %v", tc.msg, 2, len(data.Stream()))
+		}
+	}
+}
+
+func TestTrimCSVExt(t *testing.T) {
+	var testCases = []struct {
+		filename string
+		expName  string
+		expOk    bool
+	}{
+		{"TEST.DE.csv", "TEST.DE", true},
+		{"TEST.DE.csv.gz", "TEST.DE", true},
+		{"TEST.DE.txt", "TEST.DE.txt", false},
+		{"test.db", "test.db", false},
+	}
+
+	for _, tc := range testCases {
+		name, ok := trimCSVExt(tc.filename)
+		if name != tc.expName || ok != tc.expOk {
+			t.Errorf("trimCSVExt(%v): \nexpected %v %v, \nactual   %v %v",
+				tc.filename, tc.expName, tc.expOk, name, ok)
+		}
+	}
+}
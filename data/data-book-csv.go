@@ -0,0 +1,141 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// BookEventFromCSVFile loads level 2 order book data from csv files.
+// Each line of a file holds a single price level with the columns
+// Date (RFC3339), Type (snapshot or delta), Side (bid or ask), Price and Volume.
+// Consecutive lines with the same date and type are combined into one book event.
+// If Snapshots is set, deltas are applied to the last known book and
+// each event in the stream holds the complete book.
+type BookEventFromCSVFile struct {
+	gbt.Data
+	FileDir   string
+	Snapshots bool
+}
+
+// Load book events into the stream ordered by date.
+func (d *BookEventFromCSVFile) Load(symbols []string) (err error) {
+	// check file location
+	if len(d.FileDir) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+
+	files := make(map[string]string)
+
+	// read all files from directory
+	if len(symbols) == 0 {
+		files, err = fetchFilesFromDir(d.FileDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// construct filenames for provided symbols
+	for _, symbol := range symbols {
+		files[symbol] = csvFileName(d.FileDir, symbol)
+	}
+
+	for symbol, file := range files {
+		lines, err := readCSVFile(d.FileDir + file)
+		if err != nil {
+			return err
+		}
+		log.Printf("%v book lines found.\n", len(lines))
+
+		books, err := createBookEventsFromLines(lines, symbol)
+		if err != nil {
+			return err
+		}
+
+		if d.Snapshots {
+			books = reconstructBooks(books)
+		}
+
+		for _, book := range books {
+			d.Data.SetStream(append(d.Data.Stream(), book))
+		}
+	}
+
+	// sort data stream
+	d.Data.SortStream()
+
+	return nil
+}
+
+// createBookEventsFromLines combines consecutive lines with the same date and type into book events.
+func createBookEventsFromLines(lines []map[string]string, symbol string) ([]*gbt.Book, error) {
+	var books []*gbt.Book
+	var current *gbt.Book
+
+	for i, line := range lines {
+		date, err := time.Parse(time.RFC3339Nano, line["Date"])
+		if err != nil {
+			return books, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		snapshot := strings.ToLower(line["Type"]) == "snapshot"
+
+		price, err := strconv.ParseFloat(line["Price"], 64)
+		if err != nil {
+			return books, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		volume, err := strconv.ParseFloat(line["Volume"], 64)
+		if err != nil {
+			return books, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		// start a new book event on change of date or type
+		if current == nil || !current.Time().Equal(date) || current.IsSnapshot() != snapshot {
+			event := &gbt.Event{}
+			event.SetTime(date)
+			event.SetSymbol(strings.ToUpper(symbol))
+
+			current = &gbt.Book{Event: *event, Metric: gbt.Metric{}, Snapshot: snapshot}
+			books = append(books, current)
+		}
+
+		level := gbt.BookLevel{Price: price, Volume: volume}
+		switch strings.ToLower(line["Side"]) {
+		case "bid":
+			current.BidLevels = append(current.BidLevels, level)
+		case "ask":
+			current.AskLevels = append(current.AskLevels, level)
+		default:
+			return books, fmt.Errorf("line %d: invalid side %q", i+1, line["Side"])
+		}
+	}
+
+	return books, nil
+}
+
+// reconstructBooks applies each book event to the last known book and returns complete snapshots.
+func reconstructBooks(books []*gbt.Book) []*gbt.Book {
+	var result []*gbt.Book
+	state := &gbt.Book{}
+
+	for _, book := range books {
+		state.Apply(book)
+
+		snapshot := &gbt.Book{
+			Event:     book.Event,
+			Metric:    gbt.Metric{},
+			BidLevels: append([]gbt.BookLevel{}, state.BidLevels...),
+			AskLevels: append([]gbt.BookLevel{}, state.AskLevels...),
+			Snapshot:  true,
+		}
+		result = append(result, snapshot)
+	}
+
+	return result
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestCreateBookEventsFromLines(t *testing.T) {
+	var lines = []map[string]string{
+		{"Date": "2018-06-01T09:00:00Z", "Type": "snapshot", "Side": "bid", "Price": "10", "Volume": "100"},
+		{"Date": "2018-06-01T09:00:00Z", "Type": "snapshot", "Side": "ask", "Price": "11", "Volume": "100"},
+		{"Date": "2018-06-01T09:00:01Z", "Type": "delta", "Side": "bid", "Price": "10", "Volume": "0"},
+		{"Date": "2018-06-01T09:00:01Z", "Type": "delta", "Side": "bid", "Price": "9.5", "Volume": "50"},
+	}
+
+	books, err := createBookEventsFromLines(lines, "test.de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 2 {
+		t.Fatalf("createBookEventsFromLines(): expected %v books, actual %v", 2, len(books))
+	}
+	if books[0].Symbol() != "TEST.DE" || !books[0].IsSnapshot() || books[1].IsSnapshot() {
+		t.Errorf("createBookEventsFromLines(): unexpected books %#v", books)
+	}
+
+	snapshots := reconstructBooks(books)
+	exp := []gbt.BookLevel{{Price: 9.5, Volume: 50}}
+	if !reflect.DeepEqual(snapshots[1].Bids(), exp) || !snapshots[1].IsSnapshot() {
+		t.Errorf("reconstructBooks(): \nexpected %#v, \nactual   %#v", exp, snapshots[1].Bids())
+	}
+}
@@ -0,0 +1,197 @@
+package data
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// DefaultChunkSize is the default number of lines read ahead per file.
+const DefaultChunkSize = 1000
+
+// BarEventFromCSVStream streams the market data from csv files in chunks,
+// instead of loading the complete history into memory up front.
+// Each file must be ordered by date ascending. Combined with a history limit
+// on the underlying data struct the memory usage stays bounded.
+type BarEventFromCSVStream struct {
+	gbt.Data
+	FileDir   string
+	ChunkSize int
+	symbols   []string
+	cursors   []*csvCursor
+}
+
+// csvCursor reads a single csv file chunk by chunk.
+type csvCursor struct {
+	symbol string
+	file   io.ReadCloser // nil after the file is closed
+	reader *csv.Reader
+	keys   []string
+	chunk  []gbt.DataEvent
+	done   bool
+}
+
+// Load opens the files for the symbols and reads the first chunk of each file.
+func (d *BarEventFromCSVStream) Load(symbols []string) (err error) {
+	// check file location
+	if len(d.FileDir) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+
+	if d.ChunkSize <= 0 {
+		d.ChunkSize = DefaultChunkSize
+	}
+
+	files := make(map[string]string)
+
+	// read all files from directory
+	if len(symbols) == 0 {
+		files, err = fetchFilesFromDir(d.FileDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// construct filenames for provided symbols
+	for _, symbol := range symbols {
+		files[symbol] = csvFileName(d.FileDir, symbol)
+	}
+	log.Printf("Streaming %v symbol files.\n", len(files))
+
+	d.symbols = symbols
+	for symbol, file := range files {
+		cursor, err := openCSVCursor(d.FileDir+file, symbol)
+		if err != nil {
+			d.close()
+			return err
+		}
+		if err := cursor.fill(d.ChunkSize); err != nil {
+			d.close()
+			return err
+		}
+		d.cursors = append(d.cursors, cursor)
+	}
+
+	return nil
+}
+
+// Next returns the next data event over all files ordered by date.
+func (d *BarEventFromCSVStream) Next() (gbt.DataEvent, bool) {
+	var next *csvCursor
+
+	for _, cursor := range d.cursors {
+		if len(cursor.chunk) == 0 {
+			if err := cursor.fill(d.ChunkSize); err != nil {
+				log.Println(err)
+			}
+		}
+		if len(cursor.chunk) == 0 {
+			continue
+		}
+
+		if next == nil || before(cursor.chunk[0], next.chunk[0]) {
+			next = cursor
+		}
+	}
+
+	// no more data in any file
+	if next == nil {
+		return nil, false
+	}
+
+	event := next.chunk[0]
+	next.chunk = next.chunk[1:]
+
+	// let the underlying data struct keep track of latest, list and history
+	d.Data.SetStream([]gbt.DataEvent{event})
+	return d.Data.Next()
+}
+
+// Reset implements Reseter, it closes all files and opens them again from the start.
+func (d *BarEventFromCSVStream) Reset() error {
+	d.close()
+	d.Data.Reset()
+	d.Data.SetStream(nil)
+
+	return d.Load(d.symbols)
+}
+
+// close closes all open files.
+func (d *BarEventFromCSVStream) close() {
+	for _, cursor := range d.cursors {
+		cursor.close()
+	}
+	d.cursors = nil
+}
+
+// openCSVCursor opens a csv file and reads the header line.
+func openCSVCursor(path, symbol string) (*csvCursor, error) {
+	file, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.Comma = ','
+
+	keys, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &csvCursor{symbol: symbol, file: file, reader: reader, keys: keys}, nil
+}
+
+// fill reads up to n lines into the chunk of the cursor.
+func (c *csvCursor) fill(n int) error {
+	if c.done {
+		return nil
+	}
+
+	for len(c.chunk) < n {
+		record, err := c.reader.Read()
+		if err == io.EOF {
+			c.done = true
+			return c.close()
+		}
+		if err != nil {
+			return err
+		}
+
+		line := make(map[string]string)
+		for i, v := range record {
+			line[c.keys[i]] = v
+		}
+
+		event, err := createBarEventFromLine(line, c.symbol)
+		if err != nil {
+			// skip lines which could not be parsed
+			continue
+		}
+		c.chunk = append(c.chunk, event)
+	}
+
+	return nil
+}
+
+// close closes the file of the cursor, a closed file is not closed again.
+func (c *csvCursor) close() error {
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// before checks if a data event comes before another, sorted by date and symbol.
+func before(a, b gbt.DataEvent) bool {
+	if a.Time().Equal(b.Time()) {
+		return a.Symbol() < b.Symbol()
+	}
+	return a.Time().Before(b.Time())
+}
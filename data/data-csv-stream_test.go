@@ -0,0 +1,78 @@
+package data
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBarEventFromCSVStreamNext(t *testing.T) {
+	var symbols = []string{"BAS.DE", "SDF.DE"}
+
+	// load the complete files as reference
+	full := &BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := full.Load(symbols); err != nil {
+		t.Fatal(err)
+	}
+	exp := full.Stream()
+
+	stream := &BarEventFromCSVStream{FileDir: "../examples/testdata/bar/", ChunkSize: 7}
+	if err := stream.Load(symbols); err != nil {
+		t.Fatal(err)
+	}
+	stream.SetHistoryLimit(10)
+
+	var count int
+	for event, ok := stream.Next(); ok; event, ok = stream.Next() {
+		if !reflect.DeepEqual(event, exp[count]) {
+			t.Fatalf("Next() event %v: \nexpected %#v, \nactual   %#v", count, exp[count], event)
+		}
+		count++
+	}
+
+	if count != len(exp) {
+		t.Errorf("Next(): expected %v events, actual %v", len(exp), count)
+	}
+	if len(stream.History()) != 10 {
+		t.Errorf("History(): expected %v events, actual %v", 10, len(stream.History()))
+	}
+
+	// reset and stream again from the start
+	if err := stream.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	event, ok := stream.Next()
+	if !ok || !reflect.DeepEqual(event, exp[0]) {
+		t.Errorf("Reset(): \nexpected %#v, \nactual   %#v", exp[0], event)
+	}
+}
+
+// countCloser counts the calls of Close.
+type countCloser struct {
+	io.Reader
+	closed int
+}
+
+func (c *countCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestCSVCursorClose(t *testing.T) {
+	file := &countCloser{Reader: strings.NewReader("Date,Open,High,Low,Close,Adj Close,Volume\n")}
+	cursor := &csvCursor{symbol: "TEST.DE", file: file, reader: csv.NewReader(file)}
+	cursor.keys, _ = cursor.reader.Read()
+
+	// the file is closed at the end of the file and not again with the stream
+	if err := cursor.fill(10); err != nil || !cursor.done {
+		t.Fatalf("fill(): expected the end of the file, actual %v", err)
+	}
+	stream := &BarEventFromCSVStream{cursors: []*csvCursor{cursor}}
+	stream.close()
+
+	if file.closed != 1 {
+		t.Errorf("close(): expected the file closed once, actual %v times", file.closed)
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -42,7 +40,7 @@ func (d *BarEventFromCSVFile) Load(symbols []string) (err error) {
 
 	// construct filenames for provided symbols
 	for _, symbol := range symbols {
-		file := symbol + ".csv"
+		file := csvFileName(d.FileDir, symbol)
 		files[symbol] = file
 	}
 	log.Printf("Loading %v symbol files.\n", len(files))
@@ -97,13 +95,12 @@ func fetchFilesFromDir(dir string) (m map[string]string, err error) {
 		}
 
 		filename := file.Name()
-		extension := filepath.Ext(filename)
-		// file is not CSV
-		if extension != ".csv" {
+		// file is not CSV or compressed CSV
+		name, ok := trimCSVExt(filename)
+		if !ok {
 			continue
 		}
 
-		name := filename[0 : len(filename)-len(extension)]
 		m[name] = filename
 	}
 	return m, nil
@@ -111,10 +108,11 @@ func fetchFilesFromDir(dir string) (m map[string]string, err error) {
 
 // readCSVFile opens and reads a csv file line by line
 // and returns a slice with a key/value map for each line.
+// Compressed files are decompressed transparently.
 func readCSVFile(path string) (lines []map[string]string, err error) {
 	log.Printf("Loading from %s.\n", path)
 	// open file
-	file, err := os.Open(path)
+	file, err := openFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +170,7 @@ func createBarEventFromLine(line map[string]string, symbol string) (bar *gbt.Bar
 	if err != nil {
 		return bar, err
 	}
-	volume, err := strconv.ParseInt(line["Volume"], 10, 64)
+	volume, err := strconv.ParseFloat(line["Volume"], 64)
 	if err != nil {
 		return bar, err
 	}
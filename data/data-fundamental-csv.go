@@ -0,0 +1,97 @@
+package data
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// FundamentalsFromCSVFile loads point-in-time fundamental reports from csv files, one file per symbol.
+// Each line holds a report with the columns Date, the date the report became available,
+// Period, the end of the fiscal period, and a column per reported field, e.g. EPS, Revenue or BookValue.
+// Empty fields are skipped, a restatement is a later line for the same period.
+type FundamentalsFromCSVFile struct {
+	FileDir string
+	store   gbt.Fundamentals
+}
+
+// Load the fundamental reports into the store.
+func (d *FundamentalsFromCSVFile) Load(symbols []string) (err error) {
+	// check file location
+	if len(d.FileDir) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+
+	files := make(map[string]string)
+
+	// read all files from directory
+	if len(symbols) == 0 {
+		files, err = fetchFilesFromDir(d.FileDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// construct filenames for provided symbols
+	for _, symbol := range symbols {
+		files[symbol] = csvFileName(d.FileDir, symbol)
+	}
+
+	for symbol, file := range files {
+		lines, err := readCSVFile(d.FileDir + file)
+		if err != nil {
+			return err
+		}
+		log.Printf("%v fundamental lines found.\n", len(lines))
+
+		for _, line := range lines {
+			report, err := createFundamentalFromLine(line, symbol)
+			if err != nil {
+				continue
+			}
+			d.store.Add(report)
+		}
+	}
+
+	return nil
+}
+
+// Fundamentals returns the point-in-time store of the loaded reports.
+func (d *FundamentalsFromCSVFile) Fundamentals() *gbt.Fundamentals {
+	return &d.store
+}
+
+// createFundamentalFromLine takes a key/value map and a symbol and builds a fundamental report.
+func createFundamentalFromLine(line map[string]string, symbol string) (*gbt.Fundamental, error) {
+	date, err := time.Parse("2006-01-02", line["Date"])
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := time.Parse("2006-01-02", line["Period"])
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64)
+	for k, v := range line {
+		if k == "Date" || k == "Period" || v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = f
+	}
+
+	event := &gbt.Event{}
+	event.SetTime(date)
+	event.SetSymbol(strings.ToUpper(symbol))
+
+	return &gbt.Fundamental{Event: *event, Period: period, Values: values}, nil
+}
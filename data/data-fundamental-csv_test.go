@@ -0,0 +1,69 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCreateFundamentalFromLine(t *testing.T) {
+	// testCases is a table for testing the parsing of a fundamental line
+	var testCases = []struct {
+		msg       string
+		line      map[string]string
+		expErr    bool
+		expValues map[string]float64
+	}{
+		{"all fields:", map[string]string{"Date": "2018-01-10", "Period": "2017-12-31", "EPS": "1.2", "Revenue": "100"}, false, map[string]float64{"EPS": 1.2, "Revenue": 100}},
+		{"empty field:", map[string]string{"Date": "2018-01-10", "Period": "2017-12-31", "EPS": "1.2", "Revenue": ""}, false, map[string]float64{"EPS": 1.2}},
+		{"invalid period:", map[string]string{"Date": "2018-01-10", "Period": "Q4"}, true, nil},
+		{"invalid value:", map[string]string{"Date": "2018-01-10", "Period": "2017-12-31", "EPS": "n/a"}, true, nil},
+	}
+
+	for _, tc := range testCases {
+		f, err := createFundamentalFromLine(tc.line, "test.de")
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v createFundamentalFromLine(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(f.Values, tc.expValues) || f.Symbol() != "TEST.DE" {
+			t.Errorf("%v createFundamentalFromLine(): expected %v, actual %v", tc.msg, tc.expValues, f.Values)
+		}
+	}
+}
+
+func TestFundamentalsFromCSVFileLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fundamentals")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := "Date,Period,EPS,BookValue\n" +
+		"2018-01-10,2017-12-31,1.2,50\n" +
+		"2018-01-20,2017-12-31,1.0,\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "TEST.DE.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &FundamentalsFromCSVFile{FileDir: dir + "/"}
+	if err := d.Load([]string{"TEST.DE"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+
+	store := d.Fundamentals()
+	before := time.Date(2018, 1, 15, 0, 0, 0, 0, time.UTC)
+	if eps, _ := store.Value("TEST.DE", "EPS", before); eps != 1.2 {
+		t.Errorf("Value(EPS) before the restatement: expected 1.2, actual %v", eps)
+	}
+	after := time.Date(2018, 1, 25, 0, 0, 0, 0, time.UTC)
+	if eps, _ := store.Value("TEST.DE", "EPS", after); eps != 1.0 {
+		t.Errorf("Value(EPS) after the restatement: expected 1.0, actual %v", eps)
+	}
+}
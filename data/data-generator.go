@@ -0,0 +1,165 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// BarEventFromGenerator generates synthetic bars of random price paths, e.g. to test the
+// robustness of a strategy or to feed unit tests. The log price of each symbol follows
+//
+//	dx = (Drift - Volatility²/2 - JumpIntensity*k) dt + Reversion (ln Mean - x) dt + Volatility dW + J dN
+//
+// which is a geometric brownian motion without Reversion and jumps, mean reverting to the
+// price Mean with Reversion (Ornstein-Uhlenbeck on the log price) and a Merton jump diffusion
+// with a JumpIntensity. The log jumps J are normal with JumpMean and JumpVolatility, k is
+// their expected relative size, so the jumps keep the expected return of the Drift.
+//
+// Drift, Volatility and JumpIntensity are annual, a bar is 1/PeriodsPerYear of a year. Each
+// bar is simulated in Steps steps, the high and low are the extremes of the steps. The paths
+// of the same Seed are the same. In a backtest with a seed, the generator implements RandSetter
+// and generates the bars again from the source of the backtest at the start of each run.
+type BarEventFromGenerator struct {
+	gbt.Data
+	Start          time.Time
+	Interval       time.Duration // the time between bars, one day if zero
+	Bars           int           // the number of bars of each symbol
+	PeriodsPerYear float64       // 252 if zero
+	Steps          int           // 10 if zero
+	Seed           int64
+
+	Price          float64 // the first price, 100 if zero
+	Volume         float64 // the volume of each bar
+	Drift          float64
+	Volatility     float64
+	Reversion      float64
+	Mean           float64 // the price of the mean reversion, Price if zero
+	JumpIntensity  float64 // the expected number of jumps per year
+	JumpMean       float64
+	JumpVolatility float64
+
+	symbols []string
+	rng     *rand.Rand
+}
+
+// Load generates the bars of the symbols into the stream ordered by date.
+func (d *BarEventFromGenerator) Load(symbols []string) error {
+	if len(symbols) == 0 {
+		return errors.New("no symbols for generated data provided")
+	}
+	if d.Bars <= 0 {
+		return errors.New("no number of generated bars provided")
+	}
+	if d.Volatility < 0 || d.Reversion < 0 || d.JumpIntensity < 0 || d.JumpVolatility < 0 || d.Price < 0 || d.Mean < 0 {
+		return errors.New("negative parameter of generated data")
+	}
+
+	d.symbols = symbols
+	d.generateStream()
+	return nil
+}
+
+// SetRand implements RandSetter. The bars of the loaded symbols are generated again
+// from the source instead of Seed.
+func (d *BarEventFromGenerator) SetRand(r *rand.Rand) {
+	d.rng = r
+	if len(d.symbols) == 0 {
+		return
+	}
+	d.Data.Reset()
+	d.Data.SetStream(nil)
+	d.generateStream()
+}
+
+// generateStream generates the bars of all symbols into the stream ordered by date.
+func (d *BarEventFromGenerator) generateStream() {
+	rng := d.rng
+	if rng == nil {
+		rng = rand.New(rand.NewSource(d.Seed))
+	}
+	for _, symbol := range d.symbols {
+		for _, bar := range d.generate(symbol, rng) {
+			d.Data.SetStream(append(d.Data.Stream(), bar))
+		}
+	}
+
+	// sort data stream
+	d.Data.SortStream()
+}
+
+// generate returns the bars of a price path of the symbol.
+func (d *BarEventFromGenerator) generate(symbol string, rng *rand.Rand) []*gbt.Bar {
+	interval, periods, steps := d.Interval, d.PeriodsPerYear, d.Steps
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+	if periods == 0 {
+		periods = 252
+	}
+	if steps == 0 {
+		steps = 10
+	}
+	price := d.Price
+	if price == 0 {
+		price = 100
+	}
+	mean := d.Mean
+	if mean == 0 {
+		mean = price
+	}
+
+	dt := 1 / periods / float64(steps)
+	k := math.Exp(d.JumpMean+d.JumpVolatility*d.JumpVolatility/2) - 1
+	drift := (d.Drift - d.Volatility*d.Volatility/2 - d.JumpIntensity*k) * dt
+	diffusion := d.Volatility * math.Sqrt(dt)
+	x := math.Log(price)
+
+	bars := make([]*gbt.Bar, d.Bars)
+	for i := range bars {
+		openPrice := math.Exp(x)
+		highPrice, lowPrice := openPrice, openPrice
+		for s := 0; s < steps; s++ {
+			x += drift + d.Reversion*(math.Log(mean)-x)*dt + diffusion*rng.NormFloat64()
+			for n := poisson(d.JumpIntensity*dt, rng); n > 0; n-- {
+				x += d.JumpMean + d.JumpVolatility*rng.NormFloat64()
+			}
+			p := math.Exp(x)
+			highPrice = math.Max(highPrice, p)
+			lowPrice = math.Min(lowPrice, p)
+		}
+
+		event := gbt.Event{}
+		event.SetTime(d.Start.Add(time.Duration(i) * interval))
+		event.SetSymbol(symbol)
+		closePrice := math.Exp(x)
+		bars[i] = &gbt.Bar{
+			Event:    event,
+			Metric:   gbt.Metric{},
+			Open:     openPrice,
+			High:     highPrice,
+			Low:      lowPrice,
+			Close:    closePrice,
+			AdjClose: closePrice,
+			Volume:   d.Volume,
+		}
+	}
+	return bars
+}
+
+// poisson draws the number of events of a poisson distribution with the expected number lambda.
+func poisson(lambda float64, rng *rand.Rand) int {
+	if lambda <= 0 {
+		return 0
+	}
+	limit, p := math.Exp(-lambda), rng.Float64()
+	n := 0
+	for p > limit {
+		p *= rng.Float64()
+		n++
+	}
+	return n
+}
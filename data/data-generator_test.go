@@ -0,0 +1,203 @@
+package data
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"gonum.org/v1/gonum/stat"
+)
+
+// closes returns the closing prices of the bars of a symbol.
+func closes(d *BarEventFromGenerator, symbol string) []float64 {
+	var prices []float64
+	for _, e := range d.Stream() {
+		if e.Symbol() == symbol {
+			prices = append(prices, e.(*gbt.Bar).Close)
+		}
+	}
+	return prices
+}
+
+func TestBarEventFromGeneratorLoad(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// testCases is a table for testing the processes of the generator
+	var testCases = []struct {
+		msg       string
+		data      *BarEventFromGenerator
+		expLast   float64
+		tolerance float64
+	}{
+		{"drift without volatility:",
+			&BarEventFromGenerator{Bars: 252, Drift: 0.1},
+			100 * math.Exp(0.1), 1e-9},
+		{"mean reversion without volatility:",
+			&BarEventFromGenerator{Bars: 252, Price: 50, Mean: 80, Reversion: 20},
+			80, 1e-6},
+	}
+
+	for _, tc := range testCases {
+		tc.data.Start = start
+		if err := tc.data.Load([]string{"TEST"}); err != nil {
+			t.Errorf("%v Load(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		prices := closes(tc.data, "TEST")
+		if len(prices) != tc.data.Bars {
+			t.Errorf("%v Load(): expected %d bars, actual %d", tc.msg, tc.data.Bars, len(prices))
+			continue
+		}
+		if last := prices[len(prices)-1]; math.Abs(last-tc.expLast) > tc.tolerance*tc.expLast {
+			t.Errorf("%v Load(): expected the last close %v, actual %v", tc.msg, tc.expLast, last)
+		}
+	}
+}
+
+func TestBarEventFromGeneratorPaths(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &BarEventFromGenerator{Start: start, Bars: 10000, Seed: 1, Drift: 0.1, Volatility: 0.2, Volume: 1000}
+	if err := d.Load([]string{"AAA", "BBB"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+
+	stream := d.Stream()
+	if len(stream) != 20000 {
+		t.Fatalf("Load(): expected 20000 bars, actual %d", len(stream))
+	}
+	if !stream[0].Time().Equal(start) || !stream[len(stream)-1].Time().Equal(start.AddDate(0, 0, 9999)) {
+		t.Errorf("Load(): expected daily bars from %v, actual %v to %v", start, stream[0].Time(), stream[len(stream)-1].Time())
+	}
+	for _, e := range stream {
+		bar := e.(*gbt.Bar)
+		if bar.High < math.Max(bar.Open, bar.Close) || bar.Low > math.Min(bar.Open, bar.Close) || bar.Volume != 1000 {
+			t.Fatalf("Load(): expected the high and low around open and close, actual %+v", bar)
+		}
+	}
+
+	// the volatility of the log returns is the annual volatility of a day
+	prices := closes(d, "AAA")
+	returns := make([]float64, len(prices)-1)
+	for i := range returns {
+		returns[i] = math.Log(prices[i+1] / prices[i])
+	}
+	if vol := stat.StdDev(returns, nil) * math.Sqrt(252); math.Abs(vol-0.2) > 0.01 {
+		t.Errorf("Load(): expected an annual volatility of 0.2, actual %v", vol)
+	}
+
+	// the same seed generates the same paths, the symbols differ
+	same := &BarEventFromGenerator{Start: start, Bars: 10000, Seed: 1, Drift: 0.1, Volatility: 0.2}
+	if err := same.Load([]string{"AAA", "BBB"}); err != nil {
+		t.Fatal(err)
+	}
+	if closes(same, "AAA")[9999] != prices[9999] {
+		t.Errorf("Load(): expected the same path of the same seed")
+	}
+	if closes(d, "BBB")[9999] == prices[9999] {
+		t.Errorf("Load(): expected different paths of the symbols")
+	}
+}
+
+func TestBarEventFromGeneratorSetRand(t *testing.T) {
+	load := func() *BarEventFromGenerator {
+		d := &BarEventFromGenerator{Bars: 100, Seed: 1, Volatility: 0.2}
+		if err := d.Load([]string{"AAA", "BBB"}); err != nil {
+			t.Fatalf("Load(): unexpected error %v", err)
+		}
+		return d
+	}
+	seeded := closes(load(), "AAA")
+
+	// the source of the backtest replaces the seed of the generator
+	d := load()
+	d.SetRand(rand.New(rand.NewSource(7)))
+	prices := closes(d, "AAA")
+	if len(d.Stream()) != 200 || prices[99] == seeded[99] {
+		t.Fatalf("SetRand(): expected 200 bars of a different path, actual %d bars", len(d.Stream()))
+	}
+
+	// a new run with the same seed generates the same paths
+	d.Next()
+	d.SetRand(rand.New(rand.NewSource(7)))
+	if len(d.History()) != 0 || len(d.Stream()) != 200 || closes(d, "AAA")[99] != prices[99] {
+		t.Errorf("SetRand(): expected the same path of the same source")
+	}
+
+	// the generator draws from the source of a seeded backtest
+	test := gbt.New()
+	test.SetSeed(7)
+	test.SetData(d)
+	test.SetStrategy(gbt.NewStrategy("none"))
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	var last float64
+	for _, e := range d.History() {
+		if e.Symbol() == "AAA" {
+			last = e.Price()
+		}
+	}
+	if len(d.History()) != 200 || last != prices[99] {
+		t.Errorf("Run(): expected the path of the seed of the backtest, actual %d bars closing at %v", len(d.History()), last)
+	}
+}
+
+func TestBarEventFromGeneratorJumps(t *testing.T) {
+	d := &BarEventFromGenerator{Bars: 2520, Seed: 1, JumpIntensity: 50, JumpMean: -0.05}
+	if err := d.Load([]string{"TEST"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+
+	// without volatility the log returns are the compensation and the jumps
+	compensation := -50 * (math.Exp(-0.05) - 1) / 252
+	prices := append([]float64{100}, closes(d, "TEST")...)
+	var jumps float64
+	for i := 1; i < len(prices); i++ {
+		n := (math.Log(prices[i]/prices[i-1]) - compensation) / -0.05
+		if math.Abs(n-math.Round(n)) > 1e-6 || n < -1e-6 {
+			t.Fatalf("Load(): expected log returns of whole jumps, actual %v jumps at bar %d", n, i)
+		}
+		jumps += math.Round(n)
+	}
+	if math.Abs(jumps-500) > 75 {
+		t.Errorf("Load(): expected about 500 jumps in 10 years, actual %v", jumps)
+	}
+}
+
+func TestBarEventFromGeneratorErrors(t *testing.T) {
+	// testCases is a table for testing invalid parameters
+	var testCases = []struct {
+		msg     string
+		data    *BarEventFromGenerator
+		symbols []string
+	}{
+		{"no symbols:", &BarEventFromGenerator{Bars: 10}, nil},
+		{"no bars:", &BarEventFromGenerator{}, []string{"TEST"}},
+		{"negative volatility:", &BarEventFromGenerator{Bars: 10, Volatility: -0.2}, []string{"TEST"}},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.data.Load(tc.symbols); err == nil {
+			t.Errorf("%v Load(): expected an error", tc.msg)
+		}
+	}
+}
+
+func TestPoisson(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var sum int
+	for i := 0; i < 10000; i++ {
+		sum += poisson(2.5, rng)
+	}
+	if mean := float64(sum) / 10000; math.Abs(mean-2.5) > 0.1 {
+		t.Errorf("poisson(): expected a mean of 2.5, actual %v", mean)
+	}
+	if n := poisson(0, rng); n != 0 {
+		t.Errorf("poisson(): expected no events, actual %d", n)
+	}
+}
+
+// check the interface of the generator
+var _ gbt.DataHandler = &BarEventFromGenerator{}
@@ -0,0 +1,271 @@
+package data
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// BarEventFromLean loads the bars of the data folder of QuantConnect Lean, e.g. to reuse the
+// data of a migration from QuantConnect. The zipped files are read from the layout of Lean
+//
+//	<Root>/<SecurityType>/<Market>/daily/<symbol>.zip
+//	<Root>/<SecurityType>/<Market>/hour/<symbol>.zip
+//	<Root>/<SecurityType>/<Market>/minute/<symbol>/<yyyymmdd>_trade.zip
+//	<Root>/<SecurityType>/<Market>/second/<symbol>/<yyyymmdd>_quote.zip
+//
+// of the security types equity, forex and crypto. The trade bars are preferred, the bars of
+// quote files, e.g. of forex, are the middle of bid and ask with the closing bid and ask as
+// metrics "bid" and "ask". The prices of equities are stored by Lean in 1/10000 of the
+// currency and scaled back.
+//
+// Lean stores the times in the time zone of the data, e.g. America/New_York for US equities,
+// which is set as Location, UTC if not set.
+type BarEventFromLean struct {
+	gbt.Data
+	Root         string
+	SecurityType string // equity, forex or crypto
+	Market       string // e.g. usa, oanda or coinbase
+	Resolution   string // daily, hour, minute or second
+	Start        time.Time
+	End          time.Time // the days of minute and second data, all if zero
+	Location     *time.Location
+}
+
+// Load the bars of the symbols into the stream ordered by date.
+func (d *BarEventFromLean) Load(symbols []string) error {
+	if len(d.Root) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+	switch d.SecurityType {
+	case "equity", "forex", "crypto":
+	default:
+		return fmt.Errorf("unsupported lean security type %q", d.SecurityType)
+	}
+	if len(symbols) == 0 {
+		return errors.New("no symbols for lean data provided")
+	}
+
+	loc := d.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	dir := filepath.Join(d.Root, d.SecurityType, strings.ToLower(d.Market), d.Resolution)
+
+	for _, symbol := range symbols {
+		var bars []*gbt.Bar
+		var err error
+		switch d.Resolution {
+		case "daily", "hour":
+			bars, err = d.readZip(filepath.Join(dir, strings.ToLower(symbol)+".zip"), symbol, time.Time{}, loc)
+		case "minute", "second":
+			bars, err = d.readDays(filepath.Join(dir, strings.ToLower(symbol)), symbol, loc)
+		default:
+			return fmt.Errorf("unsupported lean resolution %q", d.Resolution)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("%v bars of %s found.\n", len(bars), symbol)
+
+		for _, bar := range bars {
+			d.Data.SetStream(append(d.Data.Stream(), bar))
+		}
+	}
+
+	// sort data stream
+	d.Data.SortStream()
+
+	return nil
+}
+
+// readDays reads the daily files of a symbol within the days of the range, the trade files
+// if there are any, else the quote files.
+func (d *BarEventFromLean) readDays(dir, symbol string, loc *time.Location) ([]*gbt.Bar, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	days := map[string][]string{}
+	for _, info := range infos {
+		name := info.Name()
+		for _, kind := range []string{"trade", "quote"} {
+			if strings.HasSuffix(name, "_"+kind+".zip") {
+				days[kind] = append(days[kind], name)
+			}
+		}
+	}
+	files := days["trade"]
+	if len(files) == 0 {
+		files = days["quote"]
+	}
+	sort.Strings(files)
+
+	var bars []*gbt.Bar
+	for _, name := range files {
+		day, err := time.ParseInLocation("20060102", name[:strings.Index(name, "_")], loc)
+		if err != nil {
+			continue
+		}
+		if (!d.Start.IsZero() && !day.AddDate(0, 0, 1).After(d.Start)) || (!d.End.IsZero() && day.After(d.End)) {
+			continue
+		}
+		dayBars, err := d.readZip(filepath.Join(dir, name), symbol, day, loc)
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, dayBars...)
+	}
+	return bars, nil
+}
+
+// readZip reads the bars of the csv files in a zip file. The lines of a file of a day start
+// with the milliseconds since midnight, the lines of daily and hourly files with the time.
+func (d *BarEventFromLean) readZip(path, symbol string, day time.Time, loc *time.Location) ([]*gbt.Bar, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no lean data of %s: %v", symbol, err)
+		}
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	defer r.Close()
+
+	scale := 1.0
+	if d.SecurityType == "equity" {
+		scale = 10000
+	}
+
+	var bars []*gbt.Bar
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".csv") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		fileBars, err := readLeanCSV(rc, strings.ToUpper(symbol), day, loc, scale, strings.Contains(f.Name, "_quote"))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s of %s: %v", f.Name, path, err)
+		}
+		bars = append(bars, fileBars...)
+	}
+	return bars, nil
+}
+
+// readLeanCSV reads the lines of a csv file of Lean. A trade line holds the time, open, high,
+// low, close and volume, a quote line the time, the open, high, low, close and size of the
+// bid and of the ask.
+func readLeanCSV(r io.Reader, symbol string, day time.Time, loc *time.Location, scale float64, quote bool) ([]*gbt.Bar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var bars []*gbt.Bar
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return bars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		bar, err := parseLeanRecord(record, symbol, day, loc, scale, quote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d: %v", line, err)
+		}
+		bars = append(bars, bar)
+	}
+}
+
+// parseLeanRecord parses a line of a trade or quote file.
+func parseLeanRecord(record []string, symbol string, day time.Time, loc *time.Location, scale float64, quote bool) (*gbt.Bar, error) {
+	if (quote && len(record) < 10) || (!quote && len(record) < 6) {
+		return nil, fmt.Errorf("%d columns", len(record))
+	}
+
+	var t time.Time
+	if day.IsZero() {
+		var err error
+		if t, err = time.ParseInLocation("20060102 15:04", record[0], loc); err != nil {
+			return nil, err
+		}
+	} else {
+		ms, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		t = day.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	// values returns the values of columns, nil if the columns are empty
+	values := func(columns []string) ([]float64, error) {
+		var v []float64
+		for _, c := range columns {
+			if c == "" {
+				return nil, nil
+			}
+			f, err := strconv.ParseFloat(c, 64)
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, f/scale)
+		}
+		return v, nil
+	}
+
+	event := gbt.Event{}
+	event.SetTime(t)
+	event.SetSymbol(symbol)
+	bar := &gbt.Bar{Event: event, Metric: gbt.Metric{}}
+
+	if !quote {
+		prices, err := values(record[1:5])
+		if err != nil || prices == nil {
+			return nil, fmt.Errorf("invalid prices %v", record[1:5])
+		}
+		bar.Open, bar.High, bar.Low, bar.Close = prices[0], prices[1], prices[2], prices[3]
+		if bar.Volume, err = strconv.ParseFloat(record[5], 64); err != nil {
+			return nil, err
+		}
+		bar.AdjClose = bar.Close
+		return bar, nil
+	}
+
+	bid, err := values(record[1:5])
+	if err != nil {
+		return nil, err
+	}
+	ask, err := values(record[6:10])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bid == nil && ask == nil:
+		return nil, errors.New("quote without bid and ask")
+	case bid == nil:
+		bid = ask
+	case ask == nil:
+		ask = bid
+	}
+	bar.Open, bar.High, bar.Low, bar.Close = (bid[0]+ask[0])/2, (bid[1]+ask[1])/2, (bid[2]+ask[2])/2, (bid[3]+ask[3])/2
+	bar.AdjClose = bar.Close
+	bar.Metric["bid"] = bid[3]
+	bar.Metric["ask"] = ask[3]
+	return bar, nil
+}
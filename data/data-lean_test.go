@@ -0,0 +1,133 @@
+package data
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeZip writes a zip file of a single csv file.
+func writeZip(t *testing.T, path, name, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	entry, err := w.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write([]byte(content))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBarEventFromLeanLoad(t *testing.T) {
+	root, err := ioutil.TempDir("", "lean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeZip(t, filepath.Join(root, "equity/usa/daily/spy.zip"), "spy.csv",
+		"20131001 00:00,1650000,1660000,1640000,1655000,1000\n20131002 00:00,1655000,1670000,1650000,1665000,2000\n")
+	writeZip(t, filepath.Join(root, "equity/usa/minute/spy/20131007_trade.zip"), "20131007_spy_minute_trade.csv",
+		"34260000,1650000,1660000,1640000,1655000,100\n34320000,1655000,1670000,1650000,1665000,200\n")
+	writeZip(t, filepath.Join(root, "equity/usa/minute/spy/20131008_trade.zip"), "20131008_spy_minute_trade.csv",
+		"34260000,1650000,1660000,1640000,1655000,100\n")
+	writeZip(t, filepath.Join(root, "equity/usa/minute/spy/20131007_quote.zip"), "20131007_spy_minute_quote.csv",
+		"34260000,1,1,1,1,1,1,1,1,1,1\n")
+	writeZip(t, filepath.Join(root, "forex/oanda/minute/eurusd/20140501_quote.zip"), "20140501_eurusd_minute_quote.csv",
+		"0,1.25,1.5,1,1.25,0,1.5,1.75,1.25,1.5,0\n60000,,,,,0,1.39,1.4,1.38,1.395,0\n")
+	writeZip(t, filepath.Join(root, "crypto/coinbase/hour/btcusd.zip"), "btcusd.csv",
+		"20170101 01:00,970.5,975,969,972.25,12.5\n")
+
+	newYork := time.FixedZone("EDT", -4*3600)
+
+	// testCases is a table for testing the data layouts of Lean
+	var testCases = []struct {
+		msg      string
+		data     *BarEventFromLean
+		symbol   string
+		expBars  int
+		expTime  time.Time
+		expClose float64
+	}{
+		{"daily equity:", &BarEventFromLean{SecurityType: "equity", Market: "usa", Resolution: "daily", Location: newYork},
+			"SPY", 2, time.Date(2013, 10, 1, 0, 0, 0, 0, newYork), 165.5},
+		{"minute equity trades:", &BarEventFromLean{SecurityType: "equity", Market: "usa", Resolution: "minute", Location: newYork},
+			"spy", 3, time.Date(2013, 10, 7, 9, 31, 0, 0, newYork), 165.5},
+		{"minute equity of a day:", &BarEventFromLean{SecurityType: "equity", Market: "usa", Resolution: "minute",
+			Start: time.Date(2013, 10, 8, 0, 0, 0, 0, time.UTC), End: time.Date(2013, 10, 8, 0, 0, 0, 0, time.UTC)},
+			"SPY", 1, time.Date(2013, 10, 8, 9, 31, 0, 0, time.UTC), 165.5},
+		{"minute forex quotes:", &BarEventFromLean{SecurityType: "forex", Market: "oanda", Resolution: "minute"},
+			"EURUSD", 2, time.Date(2014, 5, 1, 0, 0, 0, 0, time.UTC), 1.375},
+		{"hourly crypto:", &BarEventFromLean{SecurityType: "crypto", Market: "coinbase", Resolution: "hour"},
+			"BTCUSD", 1, time.Date(2017, 1, 1, 1, 0, 0, 0, time.UTC), 972.25},
+	}
+
+	for _, tc := range testCases {
+		tc.data.Root = root
+		if err := tc.data.Load([]string{tc.symbol}); err != nil {
+			t.Errorf("%v Load(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		stream := tc.data.Stream()
+		if len(stream) != tc.expBars {
+			t.Errorf("%v Load(): expected %d bars, actual %d", tc.msg, tc.expBars, len(stream))
+			continue
+		}
+		first := stream[0]
+		if !first.Time().Equal(tc.expTime) || first.Symbol() != strings.ToUpper(tc.symbol) || first.Price() != tc.expClose {
+			t.Errorf("%v Load(): expected the close %v of %v at %v, actual %v %v %v", tc.msg, tc.expClose, tc.symbol, tc.expTime, first.Price(), first.Symbol(), first.Time())
+		}
+	}
+
+	// the quote bar without bid is the ask
+	d := &BarEventFromLean{Root: root, SecurityType: "forex", Market: "oanda", Resolution: "minute"}
+	if err := d.Load([]string{"EURUSD"}); err != nil {
+		t.Fatal(err)
+	}
+	last := d.Stream()[1]
+	if bid, _ := last.Get("bid"); bid != 1.395 || last.Price() != 1.395 {
+		t.Errorf("Load(): expected the ask as bid of the quote without bid, actual %v %v", bid, last.Price())
+	}
+}
+
+func TestBarEventFromLeanErrors(t *testing.T) {
+	root, err := ioutil.TempDir("", "lean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeZip(t, filepath.Join(root, "equity/usa/daily/bad.zip"), "bad.csv", "20131001 00:00,1650000,x,1640000,1655000,1000\n")
+
+	// testCases is a table for testing invalid data
+	var testCases = []struct {
+		msg    string
+		data   *BarEventFromLean
+		symbol string
+		expErr string
+	}{
+		{"no root:", &BarEventFromLean{SecurityType: "equity"}, "SPY", "no directory"},
+		{"unsupported type:", &BarEventFromLean{Root: root, SecurityType: "option"}, "SPY", "unsupported lean security type"},
+		{"unsupported resolution:", &BarEventFromLean{Root: root, SecurityType: "equity", Market: "usa", Resolution: "tick"}, "SPY", "unsupported lean resolution"},
+		{"missing file:", &BarEventFromLean{Root: root, SecurityType: "equity", Market: "usa", Resolution: "daily"}, "SPY", "no lean data of SPY"},
+		{"invalid price:", &BarEventFromLean{Root: root, SecurityType: "equity", Market: "usa", Resolution: "daily"}, "BAD", "invalid line 1"},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.data.Load([]string{tc.symbol}); err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%v Load(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
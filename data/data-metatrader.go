@@ -0,0 +1,311 @@
+package data
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// BarEventFromMetaTrader loads the bar history of MetaTrader 4 and 5 terminals, e.g. of
+// FX pairs. For each symbol the directory holds a file <symbol><Suffix>.hst of the history
+// center of MetaTrader 4, or a file <symbol><Suffix>.csv exported by MetaTrader 4 or 5.
+//
+// MetaTrader stores the wall clock of the server of the broker, the times are read in the
+// Location of the server, UTC if not set. The spread of the bars in points is added as
+// metric "spread", if the file holds it.
+type BarEventFromMetaTrader struct {
+	gbt.Data
+	FileDir  string
+	Suffix   string // e.g. "1440" of the daily history EURUSD1440.hst or "_D1" of EURUSD_D1.csv
+	Location *time.Location
+}
+
+// Load the bars of the symbols, all files of the directory if no symbols are given, into the
+// stream ordered by date.
+func (d *BarEventFromMetaTrader) Load(symbols []string) (err error) {
+	// check file location
+	if len(d.FileDir) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+
+	files := make(map[string]string)
+
+	// read all files from directory
+	if len(symbols) == 0 {
+		files, err = d.fetchFiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	// construct filenames for provided symbols
+	for _, symbol := range symbols {
+		name := symbol + d.Suffix + ".hst"
+		if _, err := os.Stat(d.FileDir + name); err != nil {
+			name = csvFileName(d.FileDir, symbol+d.Suffix)
+		}
+		files[symbol] = name
+	}
+
+	for symbol, file := range files {
+		bars, err := d.readFile(file, strings.ToUpper(symbol))
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", file, err)
+		}
+		log.Printf("%v bars of %s found.\n", len(bars), symbol)
+
+		for _, bar := range bars {
+			d.Data.SetStream(append(d.Data.Stream(), bar))
+		}
+	}
+
+	// sort data stream
+	d.Data.SortStream()
+
+	return nil
+}
+
+// fetchFiles returns the history files of the directory by symbol.
+func (d *BarEventFromMetaTrader) fetchFiles() (map[string]string, error) {
+	infos, err := ioutil.ReadDir(d.FileDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		name, ok := trimCSVExt(info.Name())
+		if !ok {
+			if !strings.HasSuffix(info.Name(), ".hst") {
+				continue
+			}
+			name = strings.TrimSuffix(info.Name(), ".hst")
+		}
+		if !strings.HasSuffix(name, d.Suffix) {
+			continue
+		}
+		files[strings.TrimSuffix(name, d.Suffix)] = info.Name()
+	}
+	return files, nil
+}
+
+// readFile reads the bars of a history file.
+func (d *BarEventFromMetaTrader) readFile(file, symbol string) ([]*gbt.Bar, error) {
+	f, err := openFile(d.FileDir + file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	loc := d.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if strings.HasSuffix(file, ".hst") {
+		return readHST(bufio.NewReader(f), symbol, loc)
+	}
+	return readMetaTraderCSV(f, symbol, loc)
+}
+
+// readHST reads the bars of a history file of MetaTrader 4. The version 401 of the current
+// terminals holds the time, open, high, low, close, tick volume, spread and real volume,
+// the version 400 of old terminals the time, open, low, high, close and volume.
+func readHST(r io.Reader, symbol string, loc *time.Location) ([]*gbt.Bar, error) {
+	// the header holds the version, copyright, symbol, period, digits and reserved fields
+	header := make([]byte, 148)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("invalid hst header: %v", err)
+	}
+	version := binary.LittleEndian.Uint32(header)
+
+	var size int
+	switch version {
+	case 400:
+		size = 44
+	case 401:
+		size = 60
+	default:
+		return nil, fmt.Errorf("unsupported hst version %d", version)
+	}
+
+	var bars []*gbt.Bar
+	record := make([]byte, size)
+	float := func(offset int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(record[offset:]))
+	}
+	for {
+		_, err := io.ReadFull(r, record)
+		if err == io.EOF {
+			return bars, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid hst record %d: %v", len(bars)+1, err)
+		}
+
+		var bar *gbt.Bar
+		if version == 400 {
+			t := int64(binary.LittleEndian.Uint32(record))
+			bar = newMetaTraderBar(symbol, serverTime(t, loc), float(4), float(20), float(12), float(28), float(36))
+		} else {
+			t := int64(binary.LittleEndian.Uint64(record))
+			volume := float64(binary.LittleEndian.Uint64(record[52:]))
+			if volume == 0 {
+				volume = float64(binary.LittleEndian.Uint64(record[40:]))
+			}
+			bar = newMetaTraderBar(symbol, serverTime(t, loc), float(8), float(16), float(24), float(32), volume)
+			bar.Metric = gbt.Metric{"spread": float64(int32(binary.LittleEndian.Uint32(record[48:])))}
+		}
+		bars = append(bars, bar)
+	}
+}
+
+// serverTime returns the wall clock of seconds since 1970 in the location of the server.
+func serverTime(seconds int64, loc *time.Location) time.Time {
+	t := time.Unix(seconds, 0).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+// mt4Columns are the columns of a csv file exported by MetaTrader 4, which has no header.
+var mt4Columns = []string{"<DATE>", "<TIME>", "<OPEN>", "<HIGH>", "<LOW>", "<CLOSE>", "<TICKVOL>"}
+
+// readMetaTraderCSV reads the bars of a csv file exported by MetaTrader. The export of
+// MetaTrader 5 is tab separated with a header of the columns <DATE>, <TIME>, <OPEN>,
+// <HIGH>, <LOW>, <CLOSE>, <TICKVOL>, <VOL> and <SPREAD>, the time is missing for daily
+// bars. The export of MetaTrader 4 is comma separated without header.
+func readMetaTraderCSV(r io.Reader, symbol string, loc *time.Location) ([]*gbt.Bar, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(256)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	switch {
+	case strings.Contains(string(first), "\t"):
+		reader.Comma = '\t'
+	case strings.Contains(string(first), ";"):
+		reader.Comma = ';'
+	}
+
+	columns := make(map[string]int)
+	for i, name := range mt4Columns {
+		columns[name] = i
+	}
+
+	var bars []*gbt.Bar
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return bars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.HasPrefix(record[0], "<") {
+			columns = make(map[string]int)
+			for i, name := range record {
+				columns[strings.ToUpper(strings.TrimSpace(name))] = i
+			}
+			continue
+		}
+
+		bar, err := parseMetaTraderRecord(record, columns, symbol, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d: %v", line, err)
+		}
+		bars = append(bars, bar)
+	}
+}
+
+// parseMetaTraderRecord parses a line of a csv file by the index of its columns.
+func parseMetaTraderRecord(record []string, columns map[string]int, symbol string, loc *time.Location) (*gbt.Bar, error) {
+	value := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+	number := func(name string) (float64, error) {
+		v, ok := value(name)
+		if !ok || v == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	}
+
+	date, _ := value("<DATE>")
+	clock, ok := value("<TIME>")
+	if !ok || clock == "" {
+		clock = "00:00:00"
+	}
+	if len(clock) == 5 {
+		clock += ":00"
+	}
+	t, err := time.ParseInLocation("2006.01.02 15:04:05", date+" "+clock, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices [4]float64
+	for i, name := range []string{"<OPEN>", "<HIGH>", "<LOW>", "<CLOSE>"} {
+		if _, ok := value(name); !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+		if prices[i], err = number(name); err != nil {
+			return nil, err
+		}
+	}
+	volume, err := number("<VOL>")
+	if err != nil {
+		return nil, err
+	}
+	if volume == 0 {
+		if volume, err = number("<TICKVOL>"); err != nil {
+			return nil, err
+		}
+	}
+
+	bar := newMetaTraderBar(symbol, t, prices[0], prices[1], prices[2], prices[3], volume)
+	if _, ok := value("<SPREAD>"); ok {
+		spread, err := number("<SPREAD>")
+		if err != nil {
+			return nil, err
+		}
+		bar.Metric = gbt.Metric{"spread": spread}
+	}
+	return bar, nil
+}
+
+// newMetaTraderBar creates a bar, the close is not adjusted.
+func newMetaTraderBar(symbol string, t time.Time, openPrice, highPrice, lowPrice, closePrice, volume float64) *gbt.Bar {
+	event := gbt.Event{}
+	event.SetTime(t)
+	event.SetSymbol(symbol)
+
+	return &gbt.Bar{
+		Event:    event,
+		Metric:   gbt.Metric{},
+		Open:     openPrice,
+		High:     highPrice,
+		Low:      lowPrice,
+		Close:    closePrice,
+		AdjClose: closePrice,
+		Volume:   volume,
+	}
+}
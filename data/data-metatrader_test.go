@@ -0,0 +1,210 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// hst returns a MetaTrader 4 history file of a version with records of time, open, high,
+// low, close, volume and spread.
+func hst(version uint32, records ...[7]float64) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, 148)
+	binary.LittleEndian.PutUint32(header, version)
+	copy(header[68:], "EURUSD")
+	buf.Write(header)
+
+	for _, r := range records {
+		if version == 400 {
+			binary.Write(&buf, binary.LittleEndian, int32(r[0]))
+			binary.Write(&buf, binary.LittleEndian, []float64{r[1], r[3], r[2], r[4], r[5]})
+			continue
+		}
+		binary.Write(&buf, binary.LittleEndian, int64(r[0]))
+		binary.Write(&buf, binary.LittleEndian, []float64{r[1], r[2], r[3], r[4]})
+		binary.Write(&buf, binary.LittleEndian, int64(r[5])) // tick volume
+		binary.Write(&buf, binary.LittleEndian, int32(r[6]))
+		binary.Write(&buf, binary.LittleEndian, int64(0)) // no real volume
+	}
+	return buf.Bytes()
+}
+
+func TestReadHST(t *testing.T) {
+	day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+	server := time.FixedZone("EET", 2*3600)
+
+	// testCases is a table for testing the versions of history files
+	var testCases = []struct {
+		msg       string
+		file      []byte
+		expBars   int
+		expSpread float64
+		expErr    string
+	}{
+		{"version 401:", hst(401, [7]float64{float64(day), 1.1, 1.2, 1.0, 1.15, 500, 12}, [7]float64{float64(day + 86400), 1.15, 1.25, 1.1, 1.2, 600, 10}), 2, 12, ""},
+		{"version 400:", hst(400, [7]float64{float64(day), 1.1, 1.2, 1.0, 1.15, 500}), 1, -1, ""},
+		{"unknown version:", hst(402), 0, 0, "unsupported hst version 402"},
+		{"truncated record:", hst(401, [7]float64{float64(day)})[:170], 0, 0, "invalid hst record 1"},
+		{"truncated header:", make([]byte, 100), 0, 0, "invalid hst header"},
+	}
+
+	for _, tc := range testCases {
+		bars, err := readHST(bytes.NewReader(tc.file), "EURUSD", server)
+		if tc.expErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				t.Errorf("%v readHST(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+			}
+			continue
+		}
+		if err != nil || len(bars) != tc.expBars {
+			t.Errorf("%v readHST(): expected %d bars, actual %d %v", tc.msg, tc.expBars, len(bars), err)
+			continue
+		}
+
+		bar := bars[0]
+		expTime := time.Date(2020, 1, 2, 0, 0, 0, 0, server)
+		if !bar.Time().Equal(expTime) || bar.Symbol() != "EURUSD" {
+			t.Errorf("%v readHST(): expected the wall clock of the server %v, actual %v", tc.msg, expTime, bar.Time())
+		}
+		if bar.Open != 1.1 || bar.High != 1.2 || bar.Low != 1.0 || bar.Close != 1.15 || bar.AdjClose != 1.15 || bar.Volume != 500 {
+			t.Errorf("%v readHST(): unexpected bar %+v", tc.msg, bar)
+		}
+		spread, ok := bar.Get("spread")
+		if (tc.expSpread >= 0) != ok || (ok && spread != tc.expSpread) {
+			t.Errorf("%v readHST(): expected spread %v, actual %v %v", tc.msg, tc.expSpread, spread, ok)
+		}
+	}
+}
+
+func TestReadMetaTraderCSV(t *testing.T) {
+	// testCases is a table for testing the csv exports of MetaTrader
+	var testCases = []struct {
+		msg       string
+		file      string
+		expTime   time.Time
+		expVolume float64
+		expSpread float64
+		expErr    string
+	}{
+		{
+			"MetaTrader 5 export:",
+			"<DATE>\t<TIME>\t<OPEN>\t<HIGH>\t<LOW>\t<CLOSE>\t<TICKVOL>\t<VOL>\t<SPREAD>\n" +
+				"2020.01.02\t09:30:00\t1.1\t1.2\t1.0\t1.15\t500\t0\t12\n",
+			time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC), 500, 12, "",
+		},
+		{
+			"MetaTrader 5 daily export with real volume:",
+			"<DATE>\t<OPEN>\t<HIGH>\t<LOW>\t<CLOSE>\t<TICKVOL>\t<VOL>\t<SPREAD>\n" +
+				"2020.01.02\t1.1\t1.2\t1.0\t1.15\t500\t7000\t12\n",
+			time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), 7000, 12, "",
+		},
+		{
+			"MetaTrader 4 export:",
+			"2020.01.02,09:30,1.1,1.2,1.0,1.15,500\n",
+			time.Date(2020, 1, 2, 9, 30, 0, 0, time.UTC), 500, -1, "",
+		},
+		{
+			"invalid date:",
+			"2020-01-02,09:30,1.1,1.2,1.0,1.15,500\n",
+			time.Time{}, 0, 0, "invalid line 1",
+		},
+		{
+			"missing close:",
+			"<DATE>\t<TIME>\t<OPEN>\t<HIGH>\t<LOW>\n2020.01.02\t09:30:00\t1.1\t1.2\t1.0\n",
+			time.Time{}, 0, 0, "invalid line 2: missing column <CLOSE>",
+		},
+	}
+
+	for _, tc := range testCases {
+		bars, err := readMetaTraderCSV(strings.NewReader(tc.file), "EURUSD", time.UTC)
+		if tc.expErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				t.Errorf("%v readMetaTraderCSV(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+			}
+			continue
+		}
+		if err != nil || len(bars) != 1 {
+			t.Errorf("%v readMetaTraderCSV(): expected a bar, actual %v %v", tc.msg, bars, err)
+			continue
+		}
+
+		bar := bars[0]
+		if !bar.Time().Equal(tc.expTime) || bar.Open != 1.1 || bar.High != 1.2 || bar.Low != 1.0 || bar.Close != 1.15 || bar.Volume != tc.expVolume {
+			t.Errorf("%v readMetaTraderCSV(): unexpected bar %+v", tc.msg, bar)
+		}
+		spread, ok := bar.Get("spread")
+		if (tc.expSpread >= 0) != ok || (ok && spread != tc.expSpread) {
+			t.Errorf("%v readMetaTraderCSV(): expected spread %v, actual %v %v", tc.msg, tc.expSpread, spread, ok)
+		}
+	}
+}
+
+func TestBarEventFromMetaTraderLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metatrader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	day := float64(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix())
+	files := map[string][]byte{
+		"EURUSD1440.hst": hst(401, [7]float64{day + 86400, 1, 1, 1, 1, 1, 1}, [7]float64{day, 1, 1, 1, 1, 1, 1}),
+		"GBPUSD1440.csv": []byte("2020.01.03,00:00,1.3,1.3,1.3,1.3,100\n"),
+		"EURUSD60.hst":   hst(401, [7]float64{day, 1, 1, 1, 1, 1, 1}),
+		"README.txt":     []byte("not a history file"),
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// testCases is a table for testing the loaded symbols
+	var testCases = []struct {
+		msg        string
+		symbols    []string
+		expSymbols []string
+	}{
+		{"all daily files:", nil, []string{"EURUSD", "EURUSD", "GBPUSD"}},
+		{"single symbol:", []string{"GBPUSD"}, []string{"GBPUSD"}},
+	}
+
+	for _, tc := range testCases {
+		d := &BarEventFromMetaTrader{FileDir: dir + "/", Suffix: "1440"}
+		if err := d.Load(tc.symbols); err != nil {
+			t.Fatalf("%v Load(): unexpected error %v", tc.msg, err)
+		}
+
+		var symbols []string
+		var last time.Time
+		for _, e := range d.Stream() {
+			symbols = append(symbols, e.Symbol())
+			if e.Time().Before(last) {
+				t.Errorf("%v Load(): expected a stream ordered by date", tc.msg)
+			}
+			last = e.Time()
+		}
+		if strings.Join(symbols, ",") != strings.Join(tc.expSymbols, ",") {
+			t.Errorf("%v Load(): expected symbols %v, actual %v", tc.msg, tc.expSymbols, symbols)
+		}
+	}
+
+	d := &BarEventFromMetaTrader{FileDir: dir + "/"}
+	if err := d.Load([]string{"USDJPY"}); err == nil || !strings.Contains(err.Error(), "USDJPY.csv") {
+		t.Errorf("Load(): expected an error of the missing file, actual %v", err)
+	}
+	if err := (&BarEventFromMetaTrader{}).Load(nil); err == nil {
+		t.Errorf("Load(): expected an error without directory")
+	}
+}
+
+// check the interface of the loader
+var _ gbt.DataHandler = &BarEventFromMetaTrader{}
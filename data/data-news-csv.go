@@ -0,0 +1,100 @@
+package data
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// NewsEventFromCSVFile loads timestamped news from csv files, one file per symbol.
+// Each line of a file holds a single news item with the columns
+// Date (RFC3339 or 2006-01-02), Headline, Source and Sentiment.
+// The news are added to a backtest alongside the price data with AddEvents.
+type NewsEventFromCSVFile struct {
+	FileDir string
+	events  []gbt.CustomEvent
+}
+
+// Load news events ordered by date.
+func (d *NewsEventFromCSVFile) Load(symbols []string) (err error) {
+	// check file location
+	if len(d.FileDir) == 0 {
+		return errors.New("no directory for data provided: ")
+	}
+
+	files := make(map[string]string)
+
+	// read all files from directory
+	if len(symbols) == 0 {
+		files, err = fetchFilesFromDir(d.FileDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// construct filenames for provided symbols
+	for _, symbol := range symbols {
+		files[symbol] = csvFileName(d.FileDir, symbol)
+	}
+
+	for symbol, file := range files {
+		lines, err := readCSVFile(d.FileDir + file)
+		if err != nil {
+			return err
+		}
+		log.Printf("%v news lines found.\n", len(lines))
+
+		for _, line := range lines {
+			news, err := createNewsEventFromLine(line, symbol)
+			if err != nil {
+				continue
+			}
+			d.events = append(d.events, news)
+		}
+	}
+
+	// sort news by date, then by symbol
+	sort.SliceStable(d.events, func(i, j int) bool {
+		if d.events[i].Time().Equal(d.events[j].Time()) {
+			return d.events[i].Symbol() < d.events[j].Symbol()
+		}
+		return d.events[i].Time().Before(d.events[j].Time())
+	})
+
+	return nil
+}
+
+// Events returns the loaded news events.
+func (d *NewsEventFromCSVFile) Events() []gbt.CustomEvent {
+	return d.events
+}
+
+// createNewsEventFromLine takes a key/value map and a symbol and builds a news event.
+func createNewsEventFromLine(line map[string]string, symbol string) (*gbt.News, error) {
+	date, err := time.Parse(time.RFC3339, line["Date"])
+	if err != nil {
+		date, err = time.Parse("2006-01-02", line["Date"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sentiment float64
+	if s := line["Sentiment"]; s != "" {
+		sentiment, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	event := &gbt.Event{}
+	event.SetTime(date)
+	event.SetSymbol(strings.ToUpper(symbol))
+
+	return gbt.NewNews(*event, line["Headline"], line["Source"], sentiment), nil
+}
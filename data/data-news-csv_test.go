@@ -0,0 +1,66 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateNewsEventFromLine(t *testing.T) {
+	// testCases is a table for testing the parsing of a news line
+	var testCases = []struct {
+		msg    string
+		line   map[string]string
+		expErr bool
+		exp    time.Time
+	}{
+		{"rfc3339 date:", map[string]string{"Date": "2018-06-01T09:30:00Z", "Headline": "Profit up", "Source": "wire", "Sentiment": "0.6"}, false, time.Date(2018, 6, 1, 9, 30, 0, 0, time.UTC)},
+		{"plain date:", map[string]string{"Date": "2018-06-01", "Headline": "Profit up"}, false, time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"invalid date:", map[string]string{"Date": "yesterday"}, true, time.Time{}},
+		{"invalid sentiment:", map[string]string{"Date": "2018-06-01", "Sentiment": "good"}, true, time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		news, err := createNewsEventFromLine(tc.line, "test.de")
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v createNewsEventFromLine(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !news.Time().Equal(tc.exp) || news.Symbol() != "TEST.DE" || news.Headline() != tc.line["Headline"] {
+			t.Errorf("%v createNewsEventFromLine(): unexpected news %#v", tc.msg, news)
+		}
+	}
+}
+
+func TestNewsEventFromCSVFileLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := "Date,Headline,Source,Sentiment\n" +
+		"2018-06-02,Guidance cut,wire,-0.8\n" +
+		"2018-06-01,Profit up,wire,0.6\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "TEST.DE.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	news := &NewsEventFromCSVFile{FileDir: dir + "/"}
+	if err := news.Load([]string{"TEST.DE"}); err != nil {
+		t.Fatalf("Load(): unexpected error %v", err)
+	}
+
+	events := news.Events()
+	if len(events) != 2 {
+		t.Fatalf("Load(): expected 2 news, actual %v", len(events))
+	}
+	if events[0].Time().After(events[1].Time()) || events[0].Kind() != "news" {
+		t.Errorf("Load(): expected news sorted by date, actual %v %v", events[0].Time(), events[1].Time())
+	}
+}
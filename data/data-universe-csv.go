@@ -0,0 +1,43 @@
+package data
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// UniverseFromCSVFile reads the constituents of a universe from a csv file. Each line holds
+// the columns Symbol, Added, the first date of the membership, and Removed, the date the
+// symbol was removed, empty if it is still a member. A symbol added again has another line.
+// Dates are formatted as 2006-01-02.
+func UniverseFromCSVFile(path string) (*gbt.Universe, error) {
+	lines, err := readCSVFile(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("%v constituent lines found.\n", len(lines))
+
+	universe := &gbt.Universe{}
+	for i, line := range lines {
+		symbol := strings.ToUpper(strings.TrimSpace(line["Symbol"]))
+		if symbol == "" {
+			return nil, fmt.Errorf("invalid line %d of %s: no symbol", i+2, path)
+		}
+		added, err := time.Parse("2006-01-02", line["Added"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d of %s: %v", i+2, path, err)
+		}
+		var removed time.Time
+		if line["Removed"] != "" {
+			if removed, err = time.Parse("2006-01-02", line["Removed"]); err != nil {
+				return nil, fmt.Errorf("invalid line %d of %s: %v", i+2, path, err)
+			}
+		}
+		universe.Add(symbol, added, removed)
+	}
+
+	return universe, nil
+}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUniverseFromCSVFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "universe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// testCases is a table for testing the constituent files
+	var testCases = []struct {
+		msg        string
+		content    string
+		expMembers []string
+		expErr     string
+	}{
+		{"constituents:",
+			"Symbol,Added,Removed\nsdf.de,2010-01-01,\nBAS.DE,2010-01-01,2016-01-01\nBAY.DE,2015-01-01,2015-06-01\nBAY.DE,2016-01-01,\n",
+			[]string{"BAY.DE", "SDF.DE"}, ""},
+		{"invalid added date:", "Symbol,Added,Removed\nSDF.DE,01.01.2010,\n", nil, "invalid line 2"},
+		{"invalid removed date:", "Symbol,Added,Removed\nSDF.DE,2010-01-01,never\n", nil, "invalid line 2"},
+		{"no symbol:", "Symbol,Added,Removed\n,2010-01-01,\n", nil, "no symbol"},
+	}
+
+	for i, tc := range testCases {
+		path := filepath.Join(dir, strings.Repeat("u", i+1)+".csv")
+		if err := ioutil.WriteFile(path, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		universe, err := UniverseFromCSVFile(path)
+		if tc.expErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				t.Errorf("%v UniverseFromCSVFile(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v UniverseFromCSVFile(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		if members := universe.Members(time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(members, tc.expMembers) {
+			t.Errorf("%v UniverseFromCSVFile(): expected members %v, actual %v", tc.msg, tc.expMembers, members)
+		}
+	}
+
+	if _, err := UniverseFromCSVFile(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Errorf("UniverseFromCSVFile(): expected an error of a missing file")
+	}
+}
@@ -3,6 +3,7 @@ package gobacktest
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestDataReset(t *testing.T) {
@@ -252,3 +253,121 @@ func TestUpdateList(t *testing.T) {
 		}
 	}
 }
+
+func TestDataWindow(t *testing.T) {
+	var data = &Data{
+		list: map[string][]DataEvent{
+			"TEST.DE": {
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 90},
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 100},
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 110},
+			},
+		},
+	}
+
+	var testCases = []struct {
+		msg    string
+		symbol string
+		n      int
+		exp    []DataEvent
+	}{
+		{"test window smaller than list",
+			"TEST.DE", 2,
+			[]DataEvent{
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 100},
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 110},
+			},
+		},
+		{"test window bigger than list",
+			"TEST.DE", 5,
+			[]DataEvent{
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 90},
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 100},
+				&Bar{Event: Event{symbol: "TEST.DE"}, Close: 110},
+			},
+		},
+		{"test window of unknown symbol",
+			"BAS.DE", 2,
+			[]DataEvent{},
+		},
+		{"test zero window",
+			"TEST.DE", 0,
+			[]DataEvent{},
+		},
+	}
+
+	for _, tc := range testCases {
+		window := data.Window(tc.symbol, tc.n)
+		if !reflect.DeepEqual(window, tc.exp) {
+			t.Errorf("%v Window(%v, %v): \nexpected %#v, \nactual   %#v",
+				tc.msg, tc.symbol, tc.n, tc.exp, window)
+		}
+	}
+}
+
+func TestDataBetween(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01")
+	var day2, _ = time.Parse("2006-01-02", "2018-06-02")
+	var day3, _ = time.Parse("2006-01-02", "2018-06-03")
+
+	var data = &Data{
+		list: map[string][]DataEvent{
+			"TEST.DE": {
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Close: 90},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Close: 100},
+				&Bar{Event: Event{timestamp: day3, symbol: "TEST.DE"}, Close: 110},
+			},
+		},
+	}
+
+	var testCases = []struct {
+		msg      string
+		from, to time.Time
+		exp      []DataEvent
+	}{
+		{"test range within list",
+			day2, day3,
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Close: 100},
+				&Bar{Event: Event{timestamp: day3, symbol: "TEST.DE"}, Close: 110},
+			},
+		},
+		{"test range outside of list",
+			day3.Add(time.Hour), day3.Add(2 * time.Hour),
+			[]DataEvent{},
+		},
+	}
+
+	for _, tc := range testCases {
+		events := data.Between("TEST.DE", tc.from, tc.to)
+		if !reflect.DeepEqual(events, tc.exp) {
+			t.Errorf("%v Between(%v, %v): \nexpected %#v, \nactual   %#v",
+				tc.msg, tc.from, tc.to, tc.exp, events)
+		}
+	}
+}
+
+func TestDataHistoryLimit(t *testing.T) {
+	var data = &Data{
+		stream: []DataEvent{
+			&Bar{Event: Event{symbol: "TEST.DE"}, Close: 90},
+			&Bar{Event: Event{symbol: "TEST.DE"}, Close: 100},
+			&Bar{Event: Event{symbol: "TEST.DE"}, Close: 110},
+		},
+	}
+	data.SetHistoryLimit(2)
+
+	for _, ok := data.Next(); ok; _, ok = data.Next() {
+	}
+
+	var exp = []DataEvent{
+		&Bar{Event: Event{symbol: "TEST.DE"}, Close: 100},
+		&Bar{Event: Event{symbol: "TEST.DE"}, Close: 110},
+	}
+	if !reflect.DeepEqual(data.History(), exp) {
+		t.Errorf("History(): \nexpected %#v, \nactual   %#v", exp, data.History())
+	}
+	if !reflect.DeepEqual(data.List("TEST.DE"), exp) {
+		t.Errorf("List(): \nexpected %#v, \nactual   %#v", exp, data.List("TEST.DE"))
+	}
+}
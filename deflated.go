@@ -0,0 +1,151 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// eulerGamma is the Euler-Mascheroni constant.
+const eulerGamma = 0.5772156649015329
+
+// ProbabilisticSharpe returns the probability that the true sharpe ratio of the returns
+// exceeds the benchmark sharpe ratio, adjusted for the skewness and kurtosis of the returns.
+// Both sharpe ratios are per period, not annualised.
+func ProbabilisticSharpe(returns []float64, benchmark float64) float64 {
+	n := float64(len(returns))
+	if n < 3 {
+		return 0
+	}
+
+	mean, stddev := stat.MeanStdDev(returns, nil)
+	if stddev == 0 {
+		return 0
+	}
+	sr := mean / stddev
+	skew := stat.Skew(returns, nil)
+	kurtosis := stat.ExKurtosis(returns, nil) + 3
+
+	denom := 1 - skew*sr + (kurtosis-1)/4*sr*sr
+	if denom <= 0 || math.IsNaN(denom) {
+		return 0
+	}
+
+	psr := distuv.UnitNormal.CDF((sr - benchmark) * math.Sqrt(n-1) / math.Sqrt(denom))
+	return math.Round(psr*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// ExpectedMaxSharpe returns the expected maximum sharpe ratio of a number of trials
+// with a true sharpe ratio of zero, given the variance of the sharpe ratios of all trials.
+func ExpectedMaxSharpe(trialSharpes []float64) float64 {
+	n := float64(len(trialSharpes))
+	if n < 2 {
+		return 0
+	}
+
+	variance := stat.Variance(trialSharpes, nil)
+	z1 := distuv.UnitNormal.Quantile(1 - 1/n)
+	z2 := distuv.UnitNormal.Quantile(1 - 1/(n*math.E))
+
+	return math.Sqrt(variance) * ((1-eulerGamma)*z1 + eulerGamma*z2)
+}
+
+// DeflatedSharpe returns the probabilistic sharpe ratio of the returns of the selected run
+// against the expected maximum sharpe ratio of all trials, which corrects the selection bias
+// of an optimization. The trial sharpe ratios are per period, e.g. from SharpeOfReturns.
+func DeflatedSharpe(returns []float64, trialSharpes []float64) float64 {
+	return ProbabilisticSharpe(returns, ExpectedMaxSharpe(trialSharpes))
+}
+
+// SharpeOfReturns returns the per period sharpe ratio of returns, without risk free rate.
+func SharpeOfReturns(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	mean, stddev := stat.MeanStdDev(returns, nil)
+	if stddev == 0 {
+		return 0
+	}
+	return mean / stddev
+}
+
+// DeflatedSharpe returns the deflated sharpe ratio of the daily returns of the backtest
+// against the per period sharpe ratios of all trials of an optimization.
+func (s Statistic) DeflatedSharpe(trialSharpes []float64) float64 {
+	return DeflatedSharpe(s.DailyReturns(), trialSharpes)
+}
+
+// RealityCheck is White's reality check for data snooping. It tests if the best of several
+// strategies beats the benchmark, using a stationary bootstrap of the excess returns.
+type RealityCheck struct {
+	Bootstraps  int     // defaults to 1000
+	BlockLength float64 // mean block length of the stationary bootstrap, defaults to 10
+	Seed        int64
+}
+
+// PValue returns the p-value of the null hypothesis, that no strategy beats the benchmark.
+// Each row holds the excess returns of a strategy over the benchmark, all of equal length.
+func (r *RealityCheck) PValue(excess [][]float64) (float64, error) {
+	if len(excess) == 0 || len(excess[0]) < 2 {
+		return 0, errors.New("could not run reality check, not enough returns")
+	}
+	n := len(excess[0])
+	for _, row := range excess {
+		if len(row) != n {
+			return 0, errors.New("could not run reality check, returns of unequal length")
+		}
+	}
+
+	bootstraps := r.Bootstraps
+	if bootstraps <= 0 {
+		bootstraps = 1000
+	}
+	block := r.BlockLength
+	if block < 1 {
+		block = 10
+	}
+	rng := rand.New(rand.NewSource(r.Seed))
+
+	means := make([]float64, len(excess))
+	observed := math.Inf(-1)
+	for k, row := range excess {
+		means[k] = stat.Mean(row, nil)
+		observed = math.Max(observed, math.Sqrt(float64(n))*means[k])
+	}
+
+	var exceed int
+	index := make([]int, n)
+	for b := 0; b < bootstraps; b++ {
+		// stationary bootstrap of the time indices
+		index[0] = rng.Intn(n)
+		for t := 1; t < n; t++ {
+			if rng.Float64() < 1/block {
+				index[t] = rng.Intn(n)
+			} else {
+				index[t] = (index[t-1] + 1) % n
+			}
+		}
+
+		best := math.Inf(-1)
+		for k, row := range excess {
+			var sum float64
+			for _, i := range index {
+				sum += row[i]
+			}
+			// the bootstrap mean centred on the sample mean
+			v := math.Sqrt(float64(n)) * (sum/float64(n) - means[k])
+			if v > best {
+				best = v
+			}
+		}
+		if best >= observed {
+			exceed++
+		}
+	}
+
+	p := float64(exceed) / float64(bootstraps)
+	return math.Round(p*math.Pow10(DP)) / math.Pow10(DP), nil
+}
@@ -0,0 +1,89 @@
+package gobacktest
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// testReturns creates normally distributed returns with a mean and standard deviation.
+func testReturns(n int, mean, stddev float64, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	returns := make([]float64, n)
+	for i := range returns {
+		returns[i] = mean + stddev*rng.NormFloat64()
+	}
+	return returns
+}
+
+func TestProbabilisticSharpe(t *testing.T) {
+	// testCases is a table for testing the probabilistic sharpe ratio
+	var testCases = []struct {
+		msg       string
+		returns   []float64
+		benchmark float64
+		min, max  float64
+	}{
+		{"not enough returns:", []float64{0.01, 0.02}, 0, 0, 0},
+		{"strong positive returns:", testReturns(500, 0.002, 0.01, 1), 0, 0.99, 1},
+		{"zero mean returns:", testReturns(500, 0, 0.01, 2), 0, 0.05, 0.95},
+		{"benchmark above the sharpe ratio:", testReturns(500, 0.002, 0.01, 1), 0.5, 0, 0.01},
+	}
+
+	for _, tc := range testCases {
+		psr := ProbabilisticSharpe(tc.returns, tc.benchmark)
+		if psr < tc.min || psr > tc.max {
+			t.Errorf("%v ProbabilisticSharpe(): expected between %v and %v, actual %v", tc.msg, tc.min, tc.max, psr)
+		}
+	}
+}
+
+func TestDeflatedSharpe(t *testing.T) {
+	returns := testReturns(250, 0.001, 0.01, 3)
+
+	// few trials with similar sharpe ratios barely deflate
+	few := []float64{0.09, 0.1}
+	// many trials with dispersed sharpe ratios raise the bar
+	var many []float64
+	for i := 0; i < 1000; i++ {
+		many = append(many, float64(i%20-10)*0.02)
+	}
+
+	if ExpectedMaxSharpe(many) <= ExpectedMaxSharpe(few) {
+		t.Errorf("ExpectedMaxSharpe(): expected a higher max sharpe for many dispersed trials")
+	}
+	if DeflatedSharpe(returns, many) >= DeflatedSharpe(returns, few) {
+		t.Errorf("DeflatedSharpe(): expected a lower deflated sharpe for many trials, actual %v >= %v",
+			DeflatedSharpe(returns, many), DeflatedSharpe(returns, few))
+	}
+}
+
+func TestRealityCheckPValue(t *testing.T) {
+	check := &RealityCheck{Bootstraps: 500, Seed: 1}
+
+	// testCases is a table for testing the reality check
+	var testCases = []struct {
+		msg      string
+		excess   [][]float64
+		min, max float64
+	}{
+		{"strategy clearly beats the benchmark:",
+			[][]float64{testReturns(250, 0.005, 0.01, 1), testReturns(250, 0, 0.01, 2)},
+			0, 0.05,
+		},
+		{"no strategy beats the benchmark:",
+			[][]float64{testReturns(250, 0, 0.01, 3), testReturns(250, -0.001, 0.01, 4)},
+			0.1, 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		p, err := check.PValue(tc.excess)
+		if err != nil || p < tc.min || p > tc.max {
+			t.Errorf("%v PValue(): expected between %v and %v, actual %v %v", tc.msg, tc.min, tc.max, p, err)
+		}
+	}
+
+	if _, err := check.PValue([][]float64{{0.1, 0.2}, {0.1}}); err == nil {
+		t.Errorf("PValue(): expected error for returns of unequal length")
+	}
+}
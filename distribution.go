@@ -0,0 +1,146 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Skewness returns the skewness of the daily returns, negative if large losses are more
+// likely than large gains.
+func (s Statistic) Skewness() float64 {
+	returns := s.DailyReturns()
+	if len(returns) < 3 {
+		return 0
+	}
+	return stat.Skew(returns, nil)
+}
+
+// Kurtosis returns the excess kurtosis of the daily returns, positive for fatter tails than
+// of a normal distribution.
+func (s Statistic) Kurtosis() float64 {
+	returns := s.DailyReturns()
+	if len(returns) < 4 {
+		return 0
+	}
+	return stat.ExKurtosis(returns, nil)
+}
+
+// BestDay returns the day with the highest return, false without daily returns.
+func (s Statistic) BestDay() (DailyReturn, bool) {
+	returns := s.DatedDailyReturns()
+	if len(returns) == 0 {
+		return DailyReturn{}, false
+	}
+
+	best := returns[0]
+	for _, r := range returns[1:] {
+		if r.Return > best.Return {
+			best = r
+		}
+	}
+	return best, true
+}
+
+// WorstDay returns the day with the lowest return, false without daily returns.
+func (s Statistic) WorstDay() (DailyReturn, bool) {
+	returns := s.DatedDailyReturns()
+	if len(returns) == 0 {
+		return DailyReturn{}, false
+	}
+
+	worst := returns[0]
+	for _, r := range returns[1:] {
+		if r.Return < worst.Return {
+			worst = r
+		}
+	}
+	return worst, true
+}
+
+// PositiveDays returns the share of the days with a positive return.
+func (s Statistic) PositiveDays() float64 {
+	returns := s.DailyReturns()
+	if len(returns) == 0 {
+		return 0
+	}
+
+	var positive int
+	for _, r := range returns {
+		if r > 0 {
+			positive++
+		}
+	}
+	return float64(positive) / float64(len(returns))
+}
+
+// TailRatio returns the ratio of the 95th percentile of the daily returns to the absolute
+// 5th percentile, above 1 if the large gains exceed the large losses.
+func (s Statistic) TailRatio() float64 {
+	returns := s.DailyReturns()
+	if len(returns) == 0 {
+		return 0
+	}
+	sort.Float64s(returns)
+
+	left := math.Abs(stat.Quantile(0.05, stat.Empirical, returns, nil))
+	if left == 0 {
+		return 0
+	}
+	right := math.Abs(stat.Quantile(0.95, stat.Empirical, returns, nil))
+	return right / left
+}
+
+// HistogramBin is a bin of a histogram of returns, Low is included, High excluded except of
+// the last bin.
+type HistogramBin struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// ReturnHistogram returns the histogram of the daily returns with bins of equal width between
+// the worst and the best day.
+func (s Statistic) ReturnHistogram(bins int) []HistogramBin {
+	returns := s.DailyReturns()
+	if len(returns) == 0 || bins <= 0 {
+		return nil
+	}
+	sort.Float64s(returns)
+
+	low, high := returns[0], returns[len(returns)-1]
+	width := (high - low) / float64(bins)
+	histogram := make([]HistogramBin, bins)
+	for i := range histogram {
+		histogram[i].Low = low + float64(i)*width
+		histogram[i].High = low + float64(i+1)*width
+	}
+	histogram[bins-1].High = high
+
+	for _, r := range returns {
+		i := bins - 1
+		if width > 0 {
+			i = int((r - low) / width)
+		}
+		if i >= bins {
+			i = bins - 1
+		}
+		histogram[i].Count++
+	}
+
+	return histogram
+}
+
+// printDistribution prints the statistics of the distribution of the daily returns.
+func (s Statistic) printDistribution() {
+	best, ok := s.BestDay()
+	if !ok {
+		return
+	}
+	worst, _ := s.WorstDay()
+	fmt.Printf("Daily returns: skewness %.4f kurtosis %.4f, best %.4f on %s, worst %.4f on %s, positive %.2f%%, tail ratio %.4f\n",
+		s.Skewness(), s.Kurtosis(), best.Return, best.Date.Format("2006-01-02"), worst.Return, worst.Date.Format("2006-01-02"),
+		s.PositiveDays()*100, s.TailRatio())
+}
@@ -0,0 +1,69 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestStatisticDistribution(t *testing.T) {
+	returns := []float64{0.01, -0.02, 0.03, 0, -0.01, 0.02, 0.01, -0.03, 0.04, 0.01}
+	s := newDailyStatistic(returns)
+
+	// testCases is a table for testing the statistics of the distribution of the daily returns
+	var testCases = []struct {
+		msg    string
+		metric func() float64
+		exp    float64
+	}{
+		{"skewness:", s.Skewness, stat.Skew(returns, nil)},
+		{"kurtosis:", s.Kurtosis, stat.ExKurtosis(returns, nil)},
+		{"positive days:", s.PositiveDays, 0.6},
+		{"tail ratio:", s.TailRatio, 4.0 / 3},
+	}
+
+	for _, tc := range testCases {
+		if v := tc.metric(); math.Abs(v-tc.exp) > 1e-6 {
+			t.Errorf("%v expected %v, actual %v", tc.msg, tc.exp, v)
+		}
+	}
+
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	if best, ok := s.BestDay(); !ok || !best.Date.Equal(day.AddDate(0, 0, 9)) || math.Abs(best.Return-0.04) > 1e-9 {
+		t.Errorf("BestDay(): expected 0.04 on the 9th day, actual %+v", best)
+	}
+	if worst, ok := s.WorstDay(); !ok || !worst.Date.Equal(day.AddDate(0, 0, 8)) || math.Abs(worst.Return+0.03) > 1e-9 {
+		t.Errorf("WorstDay(): expected -0.03 on the 8th day, actual %+v", worst)
+	}
+}
+
+func TestStatisticReturnHistogram(t *testing.T) {
+	s := newDailyStatistic([]float64{0.01, -0.02, 0.03, 0, -0.01, 0.02, 0.01, -0.03, 0.04, 0.01})
+
+	histogram := s.ReturnHistogram(2)
+	if len(histogram) != 2 || histogram[0].Count != 4 || histogram[1].Count != 6 {
+		t.Fatalf("ReturnHistogram(): expected bins of 4 and 6 returns, actual %+v", histogram)
+	}
+	if math.Abs(histogram[0].Low+0.03) > 1e-9 || math.Abs(histogram[0].High-0.005) > 1e-9 || math.Abs(histogram[1].High-0.04) > 1e-9 {
+		t.Errorf("ReturnHistogram(): expected bins from -0.03 to 0.04, actual %+v", histogram)
+	}
+
+	// equal returns fall into the last bin
+	flat := newDailyStatistic([]float64{0, 0})
+	if histogram := flat.ReturnHistogram(3); len(histogram) != 3 || histogram[2].Count != 2 {
+		t.Errorf("ReturnHistogram(): expected all returns in the last bin, actual %+v", histogram)
+	}
+
+	var empty Statistic
+	if empty.ReturnHistogram(10) != nil || s.ReturnHistogram(0) != nil {
+		t.Errorf("ReturnHistogram(): expected no histogram without returns or bins")
+	}
+	if _, ok := empty.BestDay(); ok {
+		t.Errorf("BestDay(): expected no best day without returns")
+	}
+	if empty.Skewness() != 0 || empty.Kurtosis() != 0 || empty.PositiveDays() != 0 || empty.TailRatio() != 0 {
+		t.Errorf("expected no distribution statistics without returns")
+	}
+}
@@ -0,0 +1,111 @@
+package gobacktest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// AnnualizedReturn returns the compound annual growth rate of the time-weighted return over
+// the calendar time of the equity curve, a year of 365.25 days.
+func (s Statistic) AnnualizedReturn() (float64, error) {
+	twr, err := s.TimeWeightedReturn()
+	if err != nil {
+		return 0, err
+	}
+
+	first, _ := s.firstEquityPoint()
+	last, _ := s.lastEquityPoint()
+	years := last.timestamp.Sub(first.timestamp).Hours() / 24 / 365.25
+	if years <= 0 {
+		return 0, errors.New("could not calculate annualizedReturn, equity curve spans no time")
+	}
+	if twr <= -1 {
+		return -1, nil
+	}
+
+	return math.Pow(1+twr, 1/years) - 1, nil
+}
+
+// UlcerIndex returns the ulcer index, the root mean square of the drawdowns of the daily
+// closing equity, as positive fraction, e.g. 0.05 for 5%. Unlike the max drawdown it measures
+// the depth and the duration of all drawdowns. The daily equity is adjusted for cash flows.
+func (s Statistic) UlcerIndex() float64 {
+	returns := s.DatedDailyReturns()
+	if len(returns) == 0 {
+		return 0
+	}
+
+	// the first day starts at the high-water mark
+	wealth, high, sum := 1.0, 1.0, 0.0
+	for _, r := range returns {
+		wealth *= 1 + r.Return
+		if wealth > high {
+			high = wealth
+		}
+		drawdown := (wealth - high) / high
+		sum += drawdown * drawdown
+	}
+
+	return math.Sqrt(sum / float64(len(returns)+1))
+}
+
+// UlcerPerformanceIndex returns the ulcer performance index, or Martin ratio, the annualized
+// return above the annual risk free rate per unit of the ulcer index.
+func (s Statistic) UlcerPerformanceIndex(riskfree float64) float64 {
+	ulcer := s.UlcerIndex()
+	annual, err := s.AnnualizedReturn()
+	if err != nil || ulcer == 0 {
+		return 0
+	}
+	return (annual - riskfree) / ulcer
+}
+
+// MARRatio returns the MAR ratio, the annualized return divided by the max drawdown over the
+// whole equity curve.
+func (s Statistic) MARRatio() float64 {
+	drawdown := math.Abs(s.MaxDrawdown())
+	annual, err := s.AnnualizedReturn()
+	if err != nil || drawdown == 0 {
+		return 0
+	}
+	return annual / drawdown
+}
+
+// RecoveryFactor returns the net profit, without external cash flows, divided by the largest
+// drawdown of the equity as amount.
+func (s Statistic) RecoveryFactor() float64 {
+	if len(s.equity) == 0 {
+		return 0
+	}
+
+	var flows, high, drawdown float64
+	for _, ep := range s.equity {
+		flows += ep.cashFlow
+		// a cash flow shifts the high-water mark
+		high += ep.cashFlow
+		if ep.equity > high {
+			high = ep.equity
+		}
+		if high-ep.equity > drawdown {
+			drawdown = high - ep.equity
+		}
+	}
+	if drawdown == 0 {
+		return 0
+	}
+
+	first, _ := s.firstEquityPoint()
+	last, _ := s.lastEquityPoint()
+	profit := last.equity - first.equity - (flows - first.cashFlow)
+	return profit / drawdown
+}
+
+// printDrawdownRatios prints the drawdown based ratios.
+func (s Statistic) printDrawdownRatios() {
+	if len(s.equity) == 0 {
+		return
+	}
+	fmt.Printf("Ulcer index: %.4f UPI: %.4f, MAR ratio: %.4f, Recovery factor: %.4f\n",
+		s.UlcerIndex(), s.UlcerPerformanceIndex(0), s.MARRatio(), s.RecoveryFactor())
+}
@@ -0,0 +1,55 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStatisticDrawdownRatios(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := Statistic{
+		equity: []equityPoint{
+			{timestamp: day, equity: 100},
+			{timestamp: day.AddDate(0, 0, 1), equity: 110},
+			{timestamp: day.AddDate(0, 0, 2), equity: 99, drawdown: -0.1},
+			{timestamp: day.Add(8766 * time.Hour), equity: 121}, // a year of 365.25 days
+		},
+	}
+
+	// testCases is a table for testing the drawdown based ratios
+	var testCases = []struct {
+		msg    string
+		metric func() float64
+		exp    float64
+	}{
+		{"annualized return:", func() float64 { r, _ := s.AnnualizedReturn(); return r }, 0.21},
+		{"ulcer index:", s.UlcerIndex, 0.05},
+		{"ulcer performance index:", func() float64 { return s.UlcerPerformanceIndex(0.01) }, 4},
+		{"MAR ratio:", s.MARRatio, 2.1},
+		{"recovery factor:", s.RecoveryFactor, 21.0 / 11},
+	}
+
+	for _, tc := range testCases {
+		if v := tc.metric(); math.Abs(v-tc.exp) > 1e-9 {
+			t.Errorf("%v expected %v, actual %v", tc.msg, tc.exp, v)
+		}
+	}
+}
+
+func TestStatisticDrawdownRatiosEmpty(t *testing.T) {
+	var s Statistic
+	if _, err := s.AnnualizedReturn(); err == nil {
+		t.Errorf("AnnualizedReturn(): expected an error without equity points")
+	}
+	if s.UlcerIndex() != 0 || s.UlcerPerformanceIndex(0) != 0 || s.MARRatio() != 0 || s.RecoveryFactor() != 0 {
+		t.Errorf("expected no ratios without equity points")
+	}
+
+	// a rising equity curve has no drawdown
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	s.equity = []equityPoint{{timestamp: day, equity: 100}, {timestamp: day.AddDate(0, 0, 1), equity: 110}}
+	if s.UlcerPerformanceIndex(0) != 0 || s.MARRatio() != 0 || s.RecoveryFactor() != 0 {
+		t.Errorf("expected no ratios without drawdown")
+	}
+}
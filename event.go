@@ -26,6 +26,9 @@ type Symboler interface {
 type Event struct {
 	timestamp time.Time
 	symbol    string
+	eventID   int // unique id of the event within a backtest
+	parentID  int // id of the event which caused this event
+	tags      map[string]string
 }
 
 // Time returns the timestamp of an event
@@ -48,6 +51,35 @@ func (e *Event) SetSymbol(s string) {
 	e.symbol = s
 }
 
+// EventID returns the unique id of the event.
+func (e Event) EventID() int {
+	return e.eventID
+}
+
+// SetEventID sets the unique id of the event.
+func (e *Event) SetEventID(id int) {
+	e.eventID = id
+}
+
+// ParentID returns the id of the event which caused the event, 0 for a root event.
+func (e Event) ParentID() int {
+	return e.parentID
+}
+
+// SetParentID sets the id of the event which caused the event.
+func (e *Event) SetParentID(id int) {
+	e.parentID = id
+}
+
+// Lineager defines the lineage of an event, an id and the id of the parent event,
+// which allows to trace a fill back to its order, signal and data event.
+type Lineager interface {
+	EventID() int
+	SetEventID(int)
+	ParentID() int
+	SetParentID(int)
+}
+
 // SignalEvent declares the signal event interface.
 type SignalEvent interface {
 	EventHandler
@@ -66,15 +98,54 @@ type OrderEvent interface {
 	Directioner
 	Quantifier
 	IDer
+	Type() OrderType
 	Status() OrderStatus
+	SetStatus(OrderStatus)
+	QtyFilled() float64
 	Limit() float64
 	Stop() float64
+	Update(FillEvent)
 }
 
 // Quantifier defines a qty interface.
 type Quantifier interface {
-	Qty() int64
-	SetQty(int64)
+	Qty() float64
+	SetQty(float64)
+}
+
+// Displayer defines the visible qty of an iceberg order.
+type Displayer interface {
+	DisplayQty() float64
+	SetDisplayQty(float64)
+}
+
+// StopLosser defines the protective stop price of a signal or an order.
+type StopLosser interface {
+	StopLoss() float64
+	SetStopLoss(float64)
+}
+
+// Weighter defines the conviction of a signal or an order. The strength ranges from -1.0 to 1.0,
+// with negative values for short signals, the confidence from 0.0 to 1.0.
+// An unset strength counts as full size, a strength set to 0 as zero size.
+type Weighter interface {
+	Strength() float64
+	SetStrength(float64)
+	HasStrength() bool
+	Confidence() float64
+	SetConfidence(float64)
+}
+
+// Closer defines if an order or fill closes an existing position instead of opening a new one.
+type Closer interface {
+	Closing() bool
+	SetClosing(bool)
+}
+
+// Sider defines the side of a hedge closed by an exit signal or order in hedging mode.
+type Sider interface {
+	Side() Side
+	SetSide(Side)
 }
 
 // IDer declares setting and retrieving of an Id.
@@ -0,0 +1,132 @@
+package gobacktest
+
+import (
+	"time"
+)
+
+// eventClass returns the processing priority of an event within the same timestamp:
+// data before all other events. The other events keep the order they were added, so the
+// events caused by a data event are processed in the order of the event loop, e.g. the
+// fills of resting orders before the signals of the strategy.
+func eventClass(e EventHandler) int {
+	// fills and orders satisfy the data event interface as well, check them first
+	switch e.(type) {
+	case FillEvent, *Rejection, OrderEvent, SignalEvent:
+		return 1
+	case DataEvent:
+		return 0
+	}
+	return 1
+}
+
+// queuedEvent is an event with its position in the queue.
+// The time is cached, so ordering the queue does not call the event.
+type queuedEvent struct {
+	event EventHandler
+	time  time.Time
+	class int
+	seq   int
+}
+
+// before returns if the event is processed before the other event.
+func (a queuedEvent) before(b queuedEvent) bool {
+	if !a.time.Equal(b.time) {
+		return a.time.Before(b.time)
+	}
+	if a.class != b.class {
+		return a.class < b.class
+	}
+	return a.seq < b.seq
+}
+
+// EventQueue is a deterministic priority queue of events, ordered by timestamp,
+// event class and the sequence the events were added. Events of the same timestamp
+// are processed data first, all other events in the order they were added.
+// The queue is a binary heap on a reused slice, which does not allocate
+// once the slice has grown to the maximum number of queued events.
+type EventQueue struct {
+	items []queuedEvent
+	seq   int
+}
+
+// NewEventQueue creates an event queue with the given events.
+func NewEventQueue(events ...EventHandler) *EventQueue {
+	q := &EventQueue{}
+	for _, e := range events {
+		q.Push(e)
+	}
+	return q
+}
+
+// Push adds an event to the queue.
+func (q *EventQueue) Push(e EventHandler) {
+	q.seq++
+	q.items = append(q.items, queuedEvent{event: e, time: e.Time(), class: eventClass(e), seq: q.seq})
+	q.up(len(q.items) - 1)
+}
+
+// Pop removes and returns the next event of the queue.
+func (q *EventQueue) Pop() (EventHandler, bool) {
+	if q == nil || len(q.items) == 0 {
+		return nil, false
+	}
+
+	n := len(q.items) - 1
+	next := q.items[0]
+	q.items[0] = q.items[n]
+	// release the reference, so the event can be collected
+	q.items[n] = queuedEvent{}
+	q.items = q.items[:n]
+	q.down(0)
+
+	return next.event, true
+}
+
+// Len returns the number of events in the queue.
+func (q *EventQueue) Len() int {
+	if q == nil {
+		return 0
+	}
+	return len(q.items)
+}
+
+// Reset removes all events from the queue.
+func (q *EventQueue) Reset() {
+	for i := range q.items {
+		q.items[i] = queuedEvent{}
+	}
+	q.items = q.items[:0]
+	q.seq = 0
+}
+
+// up moves the item at i towards the root until the heap is ordered.
+func (q *EventQueue) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.items[i].before(q.items[parent]) {
+			break
+		}
+		q.items[i], q.items[parent] = q.items[parent], q.items[i]
+		i = parent
+	}
+}
+
+// down moves the item at i towards the leaves until the heap is ordered.
+func (q *EventQueue) down(i int) {
+	n := len(q.items)
+	for {
+		first := i
+		left, right := 2*i+1, 2*i+2
+		if left < n && q.items[left].before(q.items[first]) {
+			first = left
+		}
+		if right < n && q.items[right].before(q.items[first]) {
+			first = right
+		}
+		if first == i {
+			return
+		}
+		q.items[i], q.items[first] = q.items[first], q.items[i]
+		i = first
+	}
+}
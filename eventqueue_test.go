@@ -0,0 +1,81 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventQueueOrder(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	next := day.AddDate(0, 0, 1)
+
+	fill := &Fill{Event: Event{timestamp: day, symbol: "fill"}}
+	order := &Order{Event: Event{timestamp: day, symbol: "order"}}
+	signalA := &Signal{Event: Event{timestamp: day, symbol: "signalA"}}
+	signalB := &Signal{Event: Event{timestamp: day, symbol: "signalB"}}
+	call := &MarginCall{Event: Event{timestamp: day, symbol: "call"}}
+	bar := &Bar{Event: Event{timestamp: day, symbol: "bar"}}
+	later := &Signal{Event: Event{timestamp: next, symbol: "later"}}
+
+	q := NewEventQueue(later, fill, order, signalA, call, bar, signalB)
+	if q.Len() != 7 {
+		t.Fatalf("Len(): expected 7, actual %v", q.Len())
+	}
+
+	var symbols []string
+	for e, ok := q.Pop(); ok; e, ok = q.Pop() {
+		symbols = append(symbols, e.Symbol())
+	}
+
+	// the events after the data keep their order, e.g. a fill of a resting order before
+	// the signals of the same bar
+	exp := []string{"bar", "fill", "order", "signalA", "call", "signalB", "later"}
+	if !reflect.DeepEqual(symbols, exp) {
+		t.Errorf("Pop(): \nexpected %v, \nactual %v", exp, symbols)
+	}
+
+	if _, ok := q.Pop(); ok {
+		t.Errorf("Pop(): expected empty queue")
+	}
+}
+
+func TestEventQueueAllocs(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	events := []EventHandler{
+		&Bar{Event: Event{timestamp: day, symbol: "bar"}},
+		&Signal{Event: Event{timestamp: day, symbol: "signal"}},
+		&Order{Event: Event{timestamp: day, symbol: "order"}},
+		&Fill{Event: Event{timestamp: day, symbol: "fill"}},
+	}
+
+	// the grown queue is reused without allocations
+	q := NewEventQueue(events...)
+	q.Reset()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, e := range events {
+			q.Push(e)
+		}
+		for _, ok := q.Pop(); ok; _, ok = q.Pop() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Push() and Pop(): expected no allocations, actual %v", allocs)
+	}
+}
+
+func BenchmarkEventQueue(b *testing.B) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	bar := &Bar{Event: Event{timestamp: day, symbol: "bar"}}
+	signal := &Signal{Event: Event{timestamp: day, symbol: "signal"}}
+
+	q := &EventQueue{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.Push(bar)
+		q.Push(signal)
+		q.Pop()
+		q.Pop()
+	}
+}
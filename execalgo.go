@@ -0,0 +1,237 @@
+package gobacktest
+
+import (
+	"math"
+	"time"
+)
+
+// SliceSchedule defines which share of a parent order should be executed
+// after an elapsed time of the execution horizon.
+type SliceSchedule interface {
+	Target(elapsed, horizon time.Duration) float64
+}
+
+// TWAP schedules the execution evenly over the horizon, targeting the time weighted average price.
+type TWAP struct{}
+
+// Target returns the cumulative share of the parent order to execute.
+func (s *TWAP) Target(elapsed, horizon time.Duration) float64 {
+	if horizon <= 0 || elapsed >= horizon {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(elapsed) / float64(horizon)
+}
+
+// VWAP schedules the execution along an expected volume profile, targeting the volume weighted average price.
+// The horizon is split into equal buckets, one for each value of the profile, e.g. a U-shaped intraday profile.
+// Without a profile it executes like TWAP.
+type VWAP struct {
+	Profile []float64
+}
+
+// Target returns the cumulative share of the parent order to execute.
+func (s *VWAP) Target(elapsed, horizon time.Duration) float64 {
+	if len(s.Profile) == 0 {
+		return (&TWAP{}).Target(elapsed, horizon)
+	}
+	if horizon <= 0 || elapsed >= horizon {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+
+	var total float64
+	for _, v := range s.Profile {
+		total += v
+	}
+	if total == 0 {
+		return (&TWAP{}).Target(elapsed, horizon)
+	}
+
+	// sum up all completed buckets and the elapsed part of the current bucket
+	bucket := float64(horizon) / float64(len(s.Profile))
+	position := float64(elapsed) / bucket
+
+	var done float64
+	for i, v := range s.Profile {
+		if float64(i+1) <= position {
+			done += v
+			continue
+		}
+		done += v * (position - float64(i))
+		break
+	}
+
+	return done / total
+}
+
+// ExecutionReport reports the execution of a parent order against its arrival price.
+type ExecutionReport struct {
+	Symbol         string
+	Direction      Direction
+	Start          time.Time
+	Qty            float64
+	QtyFilled      float64
+	ArrivalPrice   float64 // last known price when the parent order arrived
+	AvgFillPrice   float64 // average price of all child fills
+	BenchmarkPrice float64 // volume weighted market price over the execution
+	Shortfall      float64 // implementation shortfall per share, positive is a cost
+	ShortfallValue float64 // implementation shortfall of all filled shares
+}
+
+// ExecutionAlgo wraps an execution handler and slices each parent order
+// into child orders over the time Horizon according to the Schedule.
+// Child orders are rounded down to the lot size of the instrument. In a backtest the child orders get
+// their ids from the event sequence of the engine and are caused by their parent order.
+type ExecutionAlgo struct {
+	ExecutionHandler
+	Schedule    SliceSchedule
+	Horizon     time.Duration
+	Instruments *InstrumentRegistry
+	data        DataHandler
+	parents     []*parentOrder
+	sequence    func(*Order, EventHandler)
+}
+
+// parentOrder tracks the execution of a single parent order.
+type parentOrder struct {
+	order        OrderEvent
+	arrivalPrice float64
+	sent         float64
+	children     []*Order
+	volume       float64
+	value        float64
+}
+
+// NewExecutionAlgo creates an execution algo on top of an execution handler.
+func NewExecutionAlgo(exchange ExecutionHandler, schedule SliceSchedule, horizon time.Duration) *ExecutionAlgo {
+	return &ExecutionAlgo{
+		ExecutionHandler: exchange,
+		Schedule:         schedule,
+		Horizon:          horizon,
+	}
+}
+
+// OnOrder accepts a parent order. The child orders are sent on the following data events.
+func (e *ExecutionAlgo) OnOrder(order OrderEvent, data DataHandler) (*Fill, error) {
+	e.data = data
+	order.SetStatus(OrderSubmitted)
+
+	var arrival float64
+	if latest := data.Latest(order.Symbol()); latest != nil {
+		arrival = latest.Price()
+	}
+
+	e.parents = append(e.parents, &parentOrder{order: order, arrivalPrice: arrival})
+	return nil, nil
+}
+
+// OnData fills resting child orders and sends new child orders for each parent order.
+func (e *ExecutionAlgo) OnData(event DataEvent) ([]*Fill, error) {
+	fills, err := e.ExecutionHandler.OnData(event)
+	if err != nil {
+		return fills, err
+	}
+
+	for _, parent := range e.parents {
+		if parent.order.Symbol() != event.Symbol() || parent.sent >= parent.order.Qty() {
+			continue
+		}
+
+		// track the market benchmark over the execution
+		volume := float64(eventVolume(event))
+		if volume == 0 {
+			volume = 1
+		}
+		parent.volume += volume
+		parent.value += volume * event.Price()
+
+		elapsed := event.Time().Sub(parent.order.Time())
+		target := e.Instruments.Get(event.Symbol()).LotSize.Round(e.Schedule.Target(elapsed, e.Horizon) * parent.order.Qty())
+		qty := target - parent.sent
+		if qty <= 0 {
+			continue
+		}
+
+		child := &Order{
+			Event:     Event{timestamp: event.Time(), symbol: event.Symbol()},
+			orderType: MarketOrder,
+			direction: parent.order.Direction(),
+			qty:       qty,
+		}
+		if e.sequence != nil {
+			e.sequence(child, parent.order)
+		}
+		parent.sent += qty
+		parent.children = append(parent.children, child)
+
+		fill, err := e.ExecutionHandler.OnOrder(child, e.data)
+		if err != nil {
+			return fills, err
+		}
+		if fill != nil {
+			fills = append(fills, fill)
+		}
+	}
+
+	return fills, nil
+}
+
+// SetSequence implements Sequencer to assign the ids of the child orders.
+func (e *ExecutionAlgo) SetSequence(sequence func(*Order, EventHandler)) {
+	e.sequence = sequence
+}
+
+// Reset implements Reseter to remove all parent orders and reset the underlying execution handler.
+func (e *ExecutionAlgo) Reset() error {
+	e.parents = nil
+	if exchange, ok := e.ExecutionHandler.(Reseter); ok {
+		return exchange.Reset()
+	}
+	return nil
+}
+
+// Reports returns an execution report for each parent order.
+func (e *ExecutionAlgo) Reports() []ExecutionReport {
+	var reports []ExecutionReport
+
+	for _, parent := range e.parents {
+		report := ExecutionReport{
+			Symbol:       parent.order.Symbol(),
+			Direction:    parent.order.Direction(),
+			Start:        parent.order.Time(),
+			Qty:          parent.order.Qty(),
+			ArrivalPrice: parent.arrivalPrice,
+		}
+
+		var value float64
+		for _, child := range parent.children {
+			report.QtyFilled += child.QtyFilled()
+			value += child.QtyFilled() * child.AvgFillPrice()
+		}
+
+		if report.QtyFilled > 0 {
+			report.AvgFillPrice = value / report.QtyFilled
+
+			shortfall := report.AvgFillPrice - report.ArrivalPrice
+			if report.Direction == SLD {
+				shortfall = -shortfall
+			}
+			report.Shortfall = math.Round(shortfall*math.Pow10(DP)) / math.Pow10(DP)
+			report.ShortfallValue = math.Round(shortfall*report.QtyFilled*math.Pow10(DP)) / math.Pow10(DP)
+		}
+
+		if parent.volume > 0 {
+			report.BenchmarkPrice = parent.value / parent.volume
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
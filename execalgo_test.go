@@ -0,0 +1,111 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSliceScheduleTarget(t *testing.T) {
+	var testCases = []struct {
+		msg      string
+		schedule SliceSchedule
+		elapsed  time.Duration
+		exp      float64
+	}{
+		{"twap at start:", &TWAP{}, 0, 0},
+		{"twap halfway:", &TWAP{}, 30 * time.Minute, 0.5},
+		{"twap after horizon:", &TWAP{}, 2 * time.Hour, 1},
+		{"vwap without profile:", &VWAP{}, 15 * time.Minute, 0.25},
+		{"vwap after first bucket:", &VWAP{Profile: []float64{3, 1}}, 30 * time.Minute, 0.75},
+		{"vwap within second bucket:", &VWAP{Profile: []float64{3, 1}}, 45 * time.Minute, 0.875},
+	}
+
+	for _, tc := range testCases {
+		target := tc.schedule.Target(tc.elapsed, time.Hour)
+		if target != tc.exp {
+			t.Errorf("%v Target(%v): \nexpected %v, \nactual   %v", tc.msg, tc.elapsed, tc.exp, target)
+		}
+	}
+}
+
+func TestExecutionAlgoTWAP(t *testing.T) {
+	var start, _ = time.Parse("2006-01-02 15:04", "2017-06-01 09:00")
+
+	exchange := NewExchange()
+	algo := NewExecutionAlgo(exchange, &TWAP{}, time.Hour)
+
+	data := &Data{
+		stream: []DataEvent{
+			&Bar{Event: Event{timestamp: start, symbol: "TEST.DE"}, Close: 10, Volume: 100},
+		},
+	}
+	data.Next()
+
+	parent := &Order{
+		Event:     Event{timestamp: start, symbol: "TEST.DE"},
+		direction: BOT,
+		qty:       100,
+	}
+	if fill, _ := algo.OnOrder(parent, data); fill != nil {
+		t.Fatalf("OnOrder(): expected no direct fill, actual %#v", fill)
+	}
+
+	var total float64
+	for i, price := range []float64{10, 11, 12, 13} {
+		bar := &Bar{Event: Event{timestamp: start.Add(time.Duration(i+1) * 15 * time.Minute), symbol: "TEST.DE"}, Close: price, Volume: 100}
+		data.SetStream([]DataEvent{bar})
+		data.Next()
+
+		fills, _ := algo.OnData(bar)
+		if len(fills) != 1 || fills[0].Qty() != 25 {
+			t.Errorf("OnData() %v: expected child fill with qty 25, actual %#v", i, fills)
+		}
+		for _, fill := range fills {
+			total += fill.Qty()
+		}
+	}
+
+	if total != 100 {
+		t.Errorf("OnData(): expected total qty of 100, actual %v", total)
+	}
+
+	reports := algo.Reports()
+	if len(reports) != 1 {
+		t.Fatalf("Reports(): expected 1 report, actual %v", len(reports))
+	}
+	report := reports[0]
+	if report.QtyFilled != 100 || report.AvgFillPrice != 11.5 || report.Shortfall != 1.5 || report.ShortfallValue != 150 {
+		t.Errorf("Reports(): unexpected report %#v", report)
+	}
+}
+
+func TestBacktestExecutionAlgoLineage(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&roundTripStrategy{Strategy: NewStrategy("twap")})
+	test.SetExchange(NewExecutionAlgo(NewExchange(), &TWAP{}, 48*time.Hour))
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the child fills of the parent order trace back to the signal
+	blotter := test.Stats().(*Statistic).Blotter()
+	if len(blotter) != 2 {
+		t.Fatalf("Blotter(): expected 2 child fills, actual %v", len(blotter))
+	}
+	if blotter[0].OrderID == 0 || blotter[0].OrderID == blotter[1].OrderID {
+		t.Errorf("Blotter(): expected a distinct id for each child order, actual %v %v", blotter[0].OrderID, blotter[1].OrderID)
+	}
+	if reserved := test.portfolio.(*Portfolio).reservedValue(); reserved != 0 {
+		t.Errorf("reservedValue(): expected the parent order released by the child fills, actual %v", reserved)
+	}
+	for _, entry := range blotter {
+		if entry.SignalID == 0 || entry.SignalID != blotter[0].SignalID || entry.Qty != 5 {
+			t.Errorf("Blotter(): expected a child fill of qty 5 caused by the signal, actual %+v", entry)
+		}
+	}
+}
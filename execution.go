@@ -1,20 +1,45 @@
 package gobacktest
 
 import (
-// "fmt"
+	"fmt"
+	"math"
+	"time"
 )
 
 // ExecutionHandler is the basic interface for executing orders
 type ExecutionHandler interface {
-	OnData(DataEvent) (*Fill, error)
+	OnData(DataEvent) ([]*Fill, error)
 	OnOrder(OrderEvent, DataHandler) (*Fill, error)
 }
 
-// Exchange is a basic execution handler implementation
+// Exchange is a basic execution handler implementation.
+// Market orders are filled directly. If a FillModel is set,
+// limit and stop orders rest in the order book of the exchange
+// and are filled on the following data events.
+// If an ImpactModel is set, the fill price moves against the trader.
+// If a Latency is set, every order rests in the order book and is only
+// eligible for a fill on data events after its simulated arrival.
+// If MaxParticipation is set, e.g. 0.1 for 10%, a fill is limited to this share
+// of the volume of a data event and the remaining qty rests in the order book,
+// a capped qty is rounded down to the lot size of the instrument, data without volume does not limit a fill.
+// If Instruments is set, orders of registered instruments with a qty not matching the lot size
+// are rejected and fill prices are rounded to the tick size.
+// Iceberg limit orders always rest in the order book and expose only their display qty,
+// which is refreshed as soon as the visible slice is filled.
 type Exchange struct {
-	Symbol      string
-	Commission  CommissionHandler
-	ExchangeFee ExchangeFeeHandler
+	Symbol           string
+	Commission       CommissionHandler
+	ExchangeFee      ExchangeFeeHandler
+	FillModel        FillModeler
+	ImpactModel      ImpactModeler
+	Latency          LatencyModeler
+	MaxParticipation float64
+	Instruments      *InstrumentRegistry
+	orderbook        OrderBook
+	arrival          map[int]time.Time
+	visible          map[int]float64
+	pool             EventPool
+	log              Logger
 }
 
 // NewExchange creates a default exchange with sensible defaults ready for use.
@@ -26,9 +51,71 @@ func NewExchange() *Exchange {
 	}
 }
 
+// Reset implements Reseter to remove all resting orders.
+func (e *Exchange) Reset() error {
+	e.orderbook = OrderBook{}
+	e.arrival = nil
+	e.visible = nil
+	return nil
+}
+
+// SetLogger implements LogSetter.
+func (e *Exchange) SetLogger(l Logger) {
+	e.log = l
+}
+
+// Orders returns all orders resting at the exchange.
+func (e *Exchange) Orders() ([]OrderEvent, bool) {
+	return e.orderbook.Orders()
+}
+
 // OnData executes any open order on new data
-func (e *Exchange) OnData(data DataEvent) (*Fill, error) {
-	return nil, nil
+func (e *Exchange) OnData(data DataEvent) ([]*Fill, error) {
+	var fills []*Fill
+
+	orders, ok := e.orderbook.OrdersBySymbol(data.Symbol())
+	if !ok {
+		return fills, nil
+	}
+
+	fillModel := e.fillModel()
+
+	for _, order := range orders {
+		// order has not yet arrived at the exchange
+		if arrival, ok := e.arrival[order.ID()]; ok && data.Time().Before(arrival) {
+			logger(e.log).Debug("order not yet arrived", "symbol", order.Symbol(), "order", order.ID(), "time", data.Time(), "arrival", arrival)
+			continue
+		}
+
+		qty, price := fillModel.Fill(order, data)
+		qty = e.capQty(qty, data)
+		qty = e.capVisible(order, qty)
+		if qty <= 0 {
+			logger(e.log).Debug("order not filled", "symbol", order.Symbol(), "order", order.ID(), "time", data.Time(), "type", order.Type(), "limit", order.Limit(), "stop", order.Stop(), "price", data.Price())
+			continue
+		}
+		impacted := e.applyImpact(order, qty, price, data)
+
+		f, err := e.fill(order, qty, impacted, data.Time())
+		if err != nil {
+			logger(e.log).Error("order fill failed", "symbol", order.Symbol(), "order", order.ID(), "err", err)
+			return fills, err
+		}
+		f.impact = math.Abs(impacted - price)
+		fills = append(fills, f)
+		logger(e.log).Info("order filled", "symbol", order.Symbol(), "order", order.ID(), "qty", qty, "price", f.Price())
+
+		order.Update(f)
+		if order.Status() == OrderFilled {
+			e.orderbook.Remove(order.ID())
+			delete(e.arrival, order.ID())
+			delete(e.visible, order.ID())
+			continue
+		}
+		e.refreshVisible(order, qty, data)
+	}
+
+	return fills, nil
 }
 
 // OnOrder executes an order event
@@ -36,18 +123,247 @@ func (e *Exchange) OnOrder(order OrderEvent, data DataHandler) (*Fill, error) {
 	// fetch latest known data event for the symbol
 	latest := data.Latest(order.Symbol())
 
+	// reject orders not matching the lot size of a registered instrument
+	if instrument, ok := e.Instruments.Lookup(order.Symbol()); ok && !instrument.LotSize.Valid(order.Qty()) {
+		order.SetStatus(OrderInvalid)
+		reason := fmt.Sprintf("qty %v does not match lot size %v", order.Qty(), instrument.LotSize)
+		logger(e.log).Warn("order rejected", "symbol", order.Symbol(), "qty", order.Qty(), "reason", reason)
+		return nil, NewRejection(order, reason)
+	}
+
+	// limit and stop orders rest in the order book, if a fill model is set,
+	// all orders rest in the order book, if a latency is set
+	if (e.FillModel != nil && order.Type() != MarketOrder) || e.Latency != nil || isIceberg(order) {
+		e.orderbook.Add(order)
+		order.SetStatus(OrderSubmitted)
+
+		if e.Latency != nil {
+			// check for nil map, else initialise the map
+			if e.arrival == nil {
+				e.arrival = make(map[int]time.Time)
+			}
+			e.arrival[order.ID()] = order.Time().Add(e.Latency.Latency())
+		}
+
+		if placer, ok := e.fillModel().(OrderPlacer); ok {
+			placer.Place(order, latest)
+		}
+		logger(e.log).Debug("order resting", "symbol", order.Symbol(), "order", order.ID(), "type", order.Type(), "latency", e.Latency != nil, "iceberg", isIceberg(order))
+		return nil, nil
+	}
+
 	// simple implementation, creates a direct fill from the order
 	// based on the last known data price
-	f := &Fill{
-		Event:    Event{timestamp: order.Time(), symbol: order.Symbol()},
+	qty := e.capQty(order.Qty(), latest)
+
+	// the qty exceeding the max participation rests in the order book
+	if qty < order.Qty() {
+		e.orderbook.Add(order)
+		order.SetStatus(OrderSubmitted)
+		logger(e.log).Debug("order capped by max participation", "symbol", order.Symbol(), "order", order.ID(), "qty", order.Qty(), "fillable", qty)
+		if qty <= 0 {
+			return nil, nil
+		}
+	}
+
+	price := e.applyImpact(order, qty, latest.Price(), latest)
+	f, err := e.fill(order, qty, price, order.Time())
+	if err != nil {
+		logger(e.log).Error("order fill failed", "symbol", order.Symbol(), "order", order.ID(), "err", err)
+		return f, err
+	}
+	f.impact = math.Abs(price - latest.Price())
+	order.Update(f)
+	logger(e.log).Info("order filled", "symbol", order.Symbol(), "order", order.ID(), "qty", qty, "price", f.Price())
+
+	return f, nil
+}
+
+// CancelOrder implements OrderCanceler to cancel a resting order.
+func (e *Exchange) CancelOrder(id int) error {
+	order, ok := e.resting(id)
+	if !ok {
+		return fmt.Errorf("could not cancel order %v, no resting order", id)
+	}
+
+	order.SetStatus(OrderCanceled)
+	e.orderbook.Remove(id)
+	delete(e.arrival, id)
+	delete(e.visible, id)
+	logger(e.log).Info("order canceled", "symbol", order.Symbol(), "order", id)
+	return nil
+}
+
+// ModifyOrder implements OrderModifier to change the qty, limit or stop price of a resting order.
+// The qty can not be reduced below the filled qty.
+func (e *Exchange) ModifyOrder(id int, change OrderChange) error {
+	resting, ok := e.resting(id)
+	if !ok {
+		return fmt.Errorf("could not modify order %v, no resting order", id)
+	}
+	order, ok := resting.(*Order)
+	if !ok {
+		return fmt.Errorf("could not modify order %v of type %T", id, resting)
+	}
+	if change.Qty > 0 && change.Qty <= order.QtyFilled() {
+		return fmt.Errorf("could not modify order %v, qty %v not above the filled qty %v", id, change.Qty, order.QtyFilled())
+	}
+	if instrument, ok := e.Instruments.Lookup(order.Symbol()); ok && change.Qty > 0 && !instrument.LotSize.Valid(change.Qty) {
+		return fmt.Errorf("could not modify order %v, qty %v does not match lot size %v", id, change.Qty, instrument.LotSize)
+	}
+
+	if change.Qty > 0 {
+		order.SetQty(change.Qty)
+	}
+	if change.Limit > 0 {
+		order.SetLimit(change.Limit)
+	}
+	if change.Stop > 0 {
+		order.SetStop(change.Stop)
+	}
+	logger(e.log).Info("order modified", "symbol", order.Symbol(), "order", id, "qty", order.Qty(), "limit", order.Limit(), "stop", order.Stop())
+	return nil
+}
+
+// resting returns a resting order by id.
+func (e *Exchange) resting(id int) (OrderEvent, bool) {
+	orders, _ := e.orderbook.Orders()
+	for _, order := range orders {
+		if order.ID() == id {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+// capVisible limits a qty of an iceberg order to its visible qty.
+func (e *Exchange) capVisible(order OrderEvent, qty float64) float64 {
+	if !isIceberg(order) {
+		return qty
+	}
+
+	visible, ok := e.visible[order.ID()]
+	if !ok {
+		visible = order.(Displayer).DisplayQty()
+	}
+	if qty > visible {
+		return visible
+	}
+	return qty
+}
+
+// refreshVisible reduces the visible qty of an iceberg order by a filled qty.
+// If the visible slice is filled, a new slice is exposed and placed at the end of the queue.
+func (e *Exchange) refreshVisible(order OrderEvent, qty float64, data DataEvent) {
+	if !isIceberg(order) {
+		return
+	}
+
+	// check for nil map, else initialise the map
+	if e.visible == nil {
+		e.visible = make(map[int]float64)
+	}
+
+	display := order.(Displayer).DisplayQty()
+	visible, ok := e.visible[order.ID()]
+	if !ok {
+		visible = display
+	}
+
+	visible -= qty
+	if visible > 0 {
+		e.visible[order.ID()] = visible
+		return
+	}
+
+	// refresh the visible slice, it enters the queue again from the back
+	e.visible[order.ID()] = display
+	if placer, ok := e.fillModel().(OrderPlacer); ok {
+		placer.Place(order, data)
+	}
+}
+
+// isIceberg checks if an order is a limit order with a display qty below its qty.
+func isIceberg(order OrderEvent) bool {
+	iceberg, ok := order.(Displayer)
+	if !ok || order.Type() != LimitOrder {
+		return false
+	}
+
+	display := iceberg.DisplayQty()
+	return display > 0 && display < order.Qty()
+}
+
+// capQty limits a qty to the max participation in the volume of a data event.
+// A data event without volume, e.g. a bar of an index, carries no volume information
+// and does not limit the qty.
+func (e *Exchange) capQty(qty float64, data DataEvent) float64 {
+	volume := eventVolume(data)
+	if e.MaxParticipation <= 0 || volume <= 0 {
+		return qty
+	}
+
+	limit := e.Instruments.Get(data.Symbol()).LotSize.Round(e.MaxParticipation * volume)
+	if qty > limit {
+		return limit
+	}
+	return qty
+}
+
+// fillModel returns the fill model of the exchange, defaults to the TouchFillModel.
+func (e *Exchange) fillModel() FillModeler {
+	if e.FillModel == nil {
+		return &TouchFillModel{}
+	}
+	return e.FillModel
+}
+
+// applyImpact moves the price of a fill against the direction of the order.
+// The price of a limit order is not moved beyond its limit.
+func (e *Exchange) applyImpact(order OrderEvent, qty float64, price float64, data DataEvent) float64 {
+	if e.ImpactModel == nil {
+		return price
+	}
+
+	impact := e.ImpactModel.Impact(qty, price, data)
+	limited := (order.Type() == LimitOrder || order.Type() == StopLimitOrder) && order.Limit() > 0
+	if order.Direction() == SLD {
+		if limited && price-impact < order.Limit() {
+			return math.Min(price, order.Limit())
+		}
+		return price - impact
+	}
+	if limited && price+impact > order.Limit() {
+		return math.Max(price, order.Limit())
+	}
+	return price + impact
+}
+
+// fill creates a fill for an order with commission and exchange fee.
+func (e *Exchange) fill(order OrderEvent, qty float64, price float64, t time.Time) (*Fill, error) {
+	instrument := e.Instruments.Get(order.Symbol())
+	f := e.pool.Fill()
+	*f = Fill{
+		Event:    Event{timestamp: t, symbol: order.Symbol()},
 		Exchange: e.Symbol,
-		qty:      order.Qty(),
-		price:    latest.Price(), // last price from data event
+		qty:      qty,
+		price:    instrument.RoundPrice(price),
+	}
+	// the multiplier of the instrument, 0 for the default of 1
+	if m := instrument.ContractMultiplier(); m != 1 {
+		f.multiplier = m
 	}
 
 	f.direction = order.Direction()
+	if l, ok := order.(Lineager); ok {
+		f.parentID = l.EventID()
+	}
+	copyTags(order, f)
+	if c, ok := order.(Closer); ok {
+		f.closing = c.Closing()
+	}
 
-	commission, err := e.Commission.Calculate(float64(f.qty), f.price)
+	commission, err := e.Commission.Calculate(f.qty, f.price)
 	if err != nil {
 		return f, err
 	}
@@ -64,6 +380,19 @@ func (e *Exchange) OnOrder(order OrderEvent, data DataHandler) (*Fill, error) {
 	return f, nil
 }
 
+// EstimateCost implements CostEstimator with the commission and exchange fee of a fill of qty at price.
+func (e *Exchange) EstimateCost(qty, price float64) (float64, error) {
+	commission, err := e.Commission.Calculate(qty, price)
+	if err != nil {
+		return 0, err
+	}
+	exchangeFee, err := e.ExchangeFee.Fee()
+	if err != nil {
+		return 0, err
+	}
+	return e.calculateCost(commission, exchangeFee), nil
+}
+
 // calculateCost() calculates the total cost for a stock trade
 func (e *Exchange) calculateCost(commission, fee float64) float64 {
 	return commission + fee
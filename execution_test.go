@@ -83,3 +83,280 @@ func TestOnOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestExchangeRestingOrder(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	var e = &Exchange{
+		Symbol:      "TEST",
+		Commission:  &FixedCommission{Commission: 0},
+		ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+		FillModel:   &TouchFillModel{},
+	}
+
+	order := &Order{
+		Event:      Event{timestamp: exampleTime, symbol: "TEST.DE"},
+		orderType:  LimitOrder,
+		direction:  BOT,
+		qty:        10,
+		limitPrice: 9,
+	}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 10},
+		},
+	}
+
+	fill, err := e.OnOrder(order, data)
+	if fill != nil || err != nil || order.Status() != OrderSubmitted {
+		t.Fatalf("OnOrder(): expected resting order, actual %#v %v", fill, err)
+	}
+
+	fills, _ := e.OnData(&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Low: 9.5, High: 10})
+	if len(fills) != 0 {
+		t.Errorf("OnData(): expected no fill, actual %#v", fills)
+	}
+
+	fills, _ = e.OnData(&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Low: 8, High: 10})
+	exp := &Fill{
+		Event:     Event{timestamp: exampleTime, symbol: "TEST.DE"},
+		Exchange:  "TEST",
+		direction: BOT,
+		qty:       10,
+		price:     9,
+	}
+	if len(fills) != 1 || !reflect.DeepEqual(fills[0], exp) {
+		t.Errorf("OnData(): \nexpected %#v, \nactual   %#v", exp, fills)
+	}
+	if _, ok := e.Orders(); ok || order.Status() != OrderFilled {
+		t.Errorf("OnData(): expected filled order removed from the order book")
+	}
+}
+
+func TestExchangeMaxParticipation(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	var e = &Exchange{
+		Symbol:           "TEST",
+		Commission:       &FixedCommission{Commission: 0},
+		ExchangeFee:      &FixedExchangeFee{ExchangeFee: 0},
+		MaxParticipation: 0.1,
+	}
+
+	order := &Order{
+		Event:     Event{timestamp: exampleTime, symbol: "TEST.DE"},
+		orderType: MarketOrder,
+		direction: BOT,
+		qty:       250,
+	}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 10, Volume: 1000},
+		},
+	}
+
+	// first part is filled directly, limited to 10% of the volume
+	fill, _ := e.OnOrder(order, data)
+	if fill == nil || fill.Qty() != 100 {
+		t.Fatalf("OnOrder(): expected fill with qty 100, actual %#v", fill)
+	}
+
+	var expQty = []float64{100, 50}
+	for i, exp := range expQty {
+		fills, _ := e.OnData(&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 10, Volume: 1000})
+		if len(fills) != 1 || fills[0].Qty() != exp {
+			t.Errorf("OnData() %v: expected fill with qty %v, actual %#v", i, exp, fills)
+		}
+	}
+
+	if _, ok := e.Orders(); ok || order.Status() != OrderFilled {
+		t.Errorf("OnData(): expected order to be filled completely")
+	}
+}
+
+func TestExchangeCapQty(t *testing.T) {
+	var e = &Exchange{MaxParticipation: 0.1}
+
+	// testCases is a table for testing the max participation in the volume
+	var testCases = []struct {
+		msg  string
+		qty  float64
+		data DataEvent
+		exp  float64
+	}{
+		{"qty below the participation:", 50, &Bar{Volume: 1000}, 50},
+		{"qty above the participation:", 250, &Bar{Volume: 1000}, 100},
+		{"tick volume:", 250, &Tick{BidVolume: 500, AskVolume: 500}, 100},
+		{"bar without volume:", 250, &Bar{Close: 10}, 250},
+		{"tick without volume:", 250, &Tick{Bid: 10, Ask: 10.1}, 250},
+	}
+
+	for _, tc := range testCases {
+		if qty := e.capQty(tc.qty, tc.data); qty != tc.exp {
+			t.Errorf("%v capQty(%v): \nexpected %v, \nactual   %v", tc.msg, tc.qty, tc.exp, qty)
+		}
+	}
+
+	// an order is filled on data without volume
+	order := &Order{Event: Event{symbol: "TEST.DE"}, orderType: MarketOrder, direction: BOT, qty: 250}
+	e.Commission = &FixedCommission{}
+	e.ExchangeFee = &FixedExchangeFee{}
+	fill, err := e.OnOrder(order, &Data{latest: map[string]DataEvent{"TEST.DE": &Bar{Close: 10}}})
+	if err != nil || fill == nil || fill.Qty() != 250 {
+		t.Errorf("OnOrder(): expected fill of the complete qty without volume, actual %#v %v", fill, err)
+	}
+}
+
+func TestExchangeIcebergOrder(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	var e = &Exchange{
+		Symbol:      "TEST",
+		Commission:  &FixedCommission{Commission: 0},
+		ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+	}
+
+	order := &Order{
+		Event:      Event{timestamp: exampleTime, symbol: "TEST.DE"},
+		orderType:  LimitOrder,
+		direction:  SLD,
+		qty:        250,
+		limitPrice: 10,
+		displayQty: 100,
+	}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 9},
+		},
+	}
+
+	if fill, _ := e.OnOrder(order, data); fill != nil {
+		t.Fatalf("OnOrder(): expected resting iceberg order, actual %#v", fill)
+	}
+
+	// only the visible slice is filled on each touch of the limit
+	var expQty = []float64{100, 100, 50}
+	for i, exp := range expQty {
+		fills, _ := e.OnData(&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Low: 9, High: 11, Close: 10})
+		if len(fills) != 1 || fills[0].Qty() != exp {
+			t.Errorf("OnData() %v: expected fill with qty %v, actual %#v", i, exp, fills)
+		}
+	}
+
+	if _, ok := e.Orders(); ok || order.Status() != OrderFilled {
+		t.Errorf("OnData(): expected iceberg order to be filled completely")
+	}
+}
+
+func TestExchangeInstruments(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	var e = &Exchange{
+		Symbol:      "TEST",
+		Commission:  &FixedCommission{Commission: 0},
+		ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+		Instruments: NewInstrumentRegistry(
+			Instrument{Symbol: "BTCUSD", AssetClass: Crypto, TickSize: 0.5, LotSize: LotSize{Step: 0.001, Min: 0.01}},
+		),
+	}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"BTCUSD": &Bar{Close: 20000.3},
+		},
+	}
+
+	// testCases is a table for testing orders against the lot and tick size of an instrument
+	var testCases = []struct {
+		msg      string
+		qty      float64
+		expPrice float64
+		expErr   bool
+	}{
+		{"fractional qty is filled at tick size:", 0.015, 20000.5, false},
+		{"qty not matching the lot size:", 0.0155, 0, true},
+		{"qty below the min qty:", 0.005, 0, true},
+	}
+
+	for _, tc := range testCases {
+		order := &Order{
+			Event:     Event{timestamp: exampleTime, symbol: "BTCUSD"},
+			direction: BOT,
+			qty:       tc.qty,
+		}
+
+		fill, err := e.OnOrder(order, data)
+		if tc.expErr {
+			if _, ok := err.(*Rejection); !ok || order.Status() != OrderInvalid {
+				t.Errorf("%v OnOrder(): expected rejection, actual %#v %v", tc.msg, fill, err)
+			}
+			continue
+		}
+		if err != nil || fill.Price() != tc.expPrice || fill.Qty() != tc.qty {
+			t.Errorf("%v OnOrder(): \nexpected qty %v price %v, \nactual   %#v %v", tc.msg, tc.qty, tc.expPrice, fill, err)
+		}
+	}
+}
+
+func BenchmarkExchangeOnOrder(b *testing.B) {
+	exampleTime, _ := time.Parse("2006-01-02", "2017-06-01")
+	e := NewExchange()
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 10},
+		},
+	}
+	order := &Order{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, direction: BOT, qty: 10}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.OnOrder(order, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestExchangeCancelModifyOrder(t *testing.T) {
+	exchange := NewExchange()
+	exchange.FillModel = &TouchFillModel{}
+	exchange.Instruments = NewInstrumentRegistry(Instrument{Symbol: "TEST.DE", LotSize: LotSize{Step: 10}})
+
+	data := &Data{}
+	data.SetStream(newStressStream(100))
+	data.Next()
+
+	order := &Order{Event: Event{symbol: "TEST.DE"}, orderType: LimitOrder, direction: BOT, qty: 20, qtyFilled: 10, limitPrice: 90}
+	exchange.OnOrder(order, data)
+
+	// testCases is a table for testing the modification of a resting order
+	var testCases = []struct {
+		msg    string
+		id     int
+		change OrderChange
+		expErr bool
+	}{
+		{"unknown order:", 5, OrderChange{Limit: 95}, true},
+		{"qty not above the filled qty:", order.ID(), OrderChange{Qty: 10}, true},
+		{"qty not matching the lot size:", order.ID(), OrderChange{Qty: 25}, true},
+		{"new qty and limit:", order.ID(), OrderChange{Qty: 30, Limit: 95}, false},
+	}
+
+	for _, tc := range testCases {
+		err := exchange.ModifyOrder(tc.id, tc.change)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v ModifyOrder(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+	if order.Qty() != 30 || order.Limit() != 95 || order.Stop() != 0 {
+		t.Errorf("ModifyOrder(): expected qty 30 limit 95, actual %v %v", order.Qty(), order.Limit())
+	}
+
+	if err := exchange.CancelOrder(order.ID()); err != nil {
+		t.Fatalf("CancelOrder(): unexpected error %v", err)
+	}
+	if _, ok := exchange.Orders(); ok || order.Status() != OrderCanceled {
+		t.Errorf("CancelOrder(): expected a canceled order and an empty order book, actual %v", order.Status())
+	}
+	if err := exchange.CancelOrder(order.ID()); err == nil {
+		t.Errorf("CancelOrder(): expected an error for a canceled order")
+	}
+}
@@ -0,0 +1,242 @@
+package gobacktest
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ExitState describes an open position for the evaluation of exit rules.
+type ExitState struct {
+	Symbol     string
+	Qty        float64   // positive on a long, negative on a short position
+	EntryPrice float64   // average price of the position
+	Price      float64   // last known market price
+	Opened     time.Time // time of the fill which opened the position
+	Time       time.Time // time of the current data or signal event
+	Bars       int       // number of data events since the position was opened
+	Signal     Direction // direction of the last signal of the symbol
+	HasSignal  bool      // a signal was received since the position was opened
+}
+
+// Return returns the unrealised return of the position, positive on a profit.
+func (s ExitState) Return() float64 {
+	if s.EntryPrice == 0 {
+		return 0
+	}
+	r := s.Price/s.EntryPrice - 1
+	if s.Qty < 0 {
+		r = -r
+	}
+	return math.Round(r*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// ExitRule is the basic interface for a rule which closes an open position.
+type ExitRule interface {
+	Exit(ExitState) bool
+}
+
+// MaxHoldingPeriod closes a position held longer than Period.
+type MaxHoldingPeriod struct {
+	Period time.Duration
+}
+
+// Exit checks if the position is held longer than the period.
+func (r *MaxHoldingPeriod) Exit(s ExitState) bool {
+	return s.Time.Sub(s.Opened) >= r.Period
+}
+
+// ProfitTarget closes a position with an unrealised return of at least Target, e.g. 0.1 for 10%.
+type ProfitTarget struct {
+	Target float64
+}
+
+// Exit checks if the position reached the profit target.
+func (r *ProfitTarget) Exit(s ExitState) bool {
+	return s.Return() >= r.Target
+}
+
+// TimeStop closes a position after Bars data events, e.g. when the edge of a signal has decayed.
+type TimeStop struct {
+	Bars int
+}
+
+// Exit checks if the position is open for the number of bars.
+func (r *TimeStop) Exit(s ExitState) bool {
+	return s.Bars >= r.Bars
+}
+
+// OppositeSignal closes a position on a signal against its direction.
+type OppositeSignal struct{}
+
+// Exit checks if the last signal is opposite to the position.
+func (r *OppositeSignal) Exit(s ExitState) bool {
+	if !s.HasSignal {
+		return false
+	}
+	return (s.Qty > 0 && s.Signal == SLD) || (s.Qty < 0 && s.Signal == BOT)
+}
+
+// ExitChecker returns the orders to close the positions hit by an exit rule on a data event.
+type ExitChecker interface {
+	CheckExits(DataEvent) []*Order
+}
+
+// Exits evaluates the exit rules for all open positions of a portfolio.
+// A position is closed as soon as one of the rules hits, the rules of a position
+// are not evaluated again while its close order is pending.
+type Exits struct {
+	Rules   []ExitRule
+	state   map[string]*ExitState
+	pending map[string]*Order
+}
+
+// NewExits creates the evaluation of a set of exit rules.
+func NewExits(rules ...ExitRule) *Exits {
+	return &Exits{Rules: rules}
+}
+
+// Reset implements Reseter to remove the state of all positions.
+func (e *Exits) Reset() error {
+	e.state = nil
+	e.pending = nil
+	return nil
+}
+
+// track updates the state of a position and returns it, nil if the position is closed.
+func (e *Exits) track(pos Position, t time.Time) *ExitState {
+	// check for nil map, else initialise the map
+	if e.state == nil {
+		e.state = make(map[string]*ExitState)
+	}
+
+	s, ok := e.state[pos.symbol]
+	if pos.qty == 0 {
+		delete(e.state, pos.symbol)
+		return nil
+	}
+	// a new or reversed position starts a new state, if it was not opened by a fill
+	if !ok || (s.Qty > 0) != (pos.qty > 0) {
+		s = &ExitState{Symbol: pos.symbol, Opened: t}
+		e.state[pos.symbol] = s
+	}
+
+	s.Qty = pos.qty
+	s.EntryPrice = pos.avgPrice
+	s.Price = pos.marketPrice
+	s.Time = t
+	return s
+}
+
+// onFill starts the state of a position opened or reversed by a fill at the time of the fill
+// and ends the state and the pending exit of a position closed by a fill.
+func (e *Exits) onFill(fill FillEvent, before, after float64) {
+	symbol := fill.Symbol()
+	if after == 0 {
+		delete(e.state, symbol)
+		delete(e.pending, symbol)
+		return
+	}
+	if before != 0 && (before > 0) == (after > 0) {
+		return
+	}
+
+	// check for nil map, else initialise the map
+	if e.state == nil {
+		e.state = make(map[string]*ExitState)
+	}
+	e.state[symbol] = &ExitState{Symbol: symbol, Qty: after, Opened: fill.Time()}
+	delete(e.pending, symbol)
+}
+
+// exit records the pending order which closes the position of a symbol.
+func (e *Exits) exit(symbol string, order *Order) {
+	// check for nil map, else initialise the map
+	if e.pending == nil {
+		e.pending = make(map[string]*Order)
+	}
+	e.pending[symbol] = order
+}
+
+// hit checks if any rule hits the state.
+func (e *Exits) hit(s ExitState) bool {
+	for _, rule := range e.Rules {
+		if rule.Exit(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetExits sets the exit rules which are evaluated for every open position.
+func (p *Portfolio) SetExits(exits *Exits) {
+	p.exits = exits
+}
+
+// CheckExits returns the orders to close all positions hit by an exit rule.
+// A position with a pending close order is skipped until the order is done.
+func (p *Portfolio) CheckExits(data DataEvent) []*Order {
+	if p.exits == nil {
+		return nil
+	}
+
+	// remove the state of closed positions
+	for symbol := range p.exits.state {
+		if _, ok := p.holdings[symbol]; !ok {
+			delete(p.exits.state, symbol)
+		}
+	}
+
+	symbols := make([]string, 0, len(p.holdings))
+	for symbol := range p.holdings {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var orders []*Order
+	for _, symbol := range symbols {
+		pos := p.holdings[symbol]
+		s := p.exits.track(pos, data.Time())
+		if s == nil {
+			continue
+		}
+		// only the data of the symbol advances the bars of the position
+		if data.Symbol() == symbol {
+			s.Bars++
+		}
+		if pendingOrder(p.exits.pending, symbol) {
+			continue
+		}
+		if p.exits.hit(*s) {
+			order := closeOrder(pos, data.Time())
+			p.exits.exit(symbol, order)
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// exitOnSignal records the signal for the position of its symbol
+// and returns true, if an exit rule closes the position.
+func (p *Portfolio) exitOnSignal(signal SignalEvent) bool {
+	if p.exits == nil {
+		return false
+	}
+	pos, ok := p.holdings[signal.Symbol()]
+	if !ok {
+		return false
+	}
+
+	s := p.exits.track(pos, signal.Time())
+	if s == nil {
+		return false
+	}
+	s.Signal = signal.Direction()
+	s.HasSignal = true
+
+	// the position is already being closed
+	if pendingOrder(p.exits.pending, signal.Symbol()) {
+		return false
+	}
+	return p.exits.hit(*s)
+}
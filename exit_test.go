@@ -0,0 +1,168 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExitRules(t *testing.T) {
+	opened, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing the exit rules
+	var testCases = []struct {
+		msg   string
+		rule  ExitRule
+		state ExitState
+		exp   bool
+	}{
+		{"max holding period not reached:",
+			&MaxHoldingPeriod{Period: 48 * time.Hour},
+			ExitState{Opened: opened, Time: opened.Add(24 * time.Hour)},
+			false,
+		},
+		{"max holding period reached:",
+			&MaxHoldingPeriod{Period: 48 * time.Hour},
+			ExitState{Opened: opened, Time: opened.Add(48 * time.Hour)},
+			true,
+		},
+		{"profit target of long position reached:",
+			&ProfitTarget{Target: 0.1},
+			ExitState{Qty: 10, EntryPrice: 100, Price: 110},
+			true,
+		},
+		{"profit target of short position not reached:",
+			&ProfitTarget{Target: 0.1},
+			ExitState{Qty: -10, EntryPrice: 100, Price: 110},
+			false,
+		},
+		{"profit target of short position reached:",
+			&ProfitTarget{Target: 0.1},
+			ExitState{Qty: -10, EntryPrice: 100, Price: 90},
+			true,
+		},
+		{"time stop not reached:",
+			&TimeStop{Bars: 3},
+			ExitState{Bars: 2},
+			false,
+		},
+		{"time stop reached:",
+			&TimeStop{Bars: 3},
+			ExitState{Bars: 3},
+			true,
+		},
+		{"no signal received:",
+			&OppositeSignal{},
+			ExitState{Qty: 10},
+			false,
+		},
+		{"signal in direction of position:",
+			&OppositeSignal{},
+			ExitState{Qty: 10, Signal: BOT, HasSignal: true},
+			false,
+		},
+		{"signal opposite to position:",
+			&OppositeSignal{},
+			ExitState{Qty: 10, Signal: SLD, HasSignal: true},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if exit := tc.rule.Exit(tc.state); exit != tc.exp {
+			t.Errorf("%v Exit(): \nexpected %v, \nactual %v", tc.msg, tc.exp, exit)
+		}
+	}
+}
+
+func TestPortfolioCheckExits(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	p := &Portfolio{
+		holdings: map[string]Position{
+			"TEST.DE": {symbol: "TEST.DE", qty: 10, avgPrice: 100, marketPrice: 100},
+		},
+	}
+	p.SetExits(NewExits(&TimeStop{Bars: 2}))
+
+	if orders := p.CheckExits(&Bar{Event: Event{timestamp: day, symbol: "TEST.DE"}}); len(orders) != 0 {
+		t.Errorf("CheckExits(): expected no orders on the first bar, actual %#v", orders)
+	}
+
+	// data of another symbol does not advance the bars
+	if orders := p.CheckExits(&Bar{Event: Event{timestamp: day, symbol: "OTHER.DE"}}); len(orders) != 0 {
+		t.Errorf("CheckExits(): expected no orders on other data, actual %#v", orders)
+	}
+
+	orders := p.CheckExits(&Bar{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "TEST.DE"}})
+	if len(orders) != 1 || orders[0].Direction() != SLD || orders[0].Qty() != 10 {
+		t.Errorf("CheckExits(): expected closing order on the second bar, actual %#v", orders)
+	}
+}
+
+func TestPortfolioCheckExitsPending(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	bar := func(days int) *Bar {
+		return &Bar{Event: Event{timestamp: day.AddDate(0, 0, days), symbol: "TEST.DE"}, Close: 100}
+	}
+
+	p := &Portfolio{}
+	p.SetExits(NewExits(&MaxHoldingPeriod{Period: 48 * time.Hour}))
+
+	// the entry fill opens the position
+	entry := &Fill{Event: Event{timestamp: day, symbol: "TEST.DE"}, direction: BOT, qty: 10, price: 100}
+	if _, err := p.OnFill(entry, &Data{}); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+
+	if orders := p.CheckExits(bar(1)); len(orders) != 0 {
+		t.Errorf("CheckExits(): expected no orders within the holding period, actual %#v", orders)
+	}
+	if s := p.exits.state["TEST.DE"]; s == nil || !s.Opened.Equal(day) {
+		t.Errorf("CheckExits(): expected the position opened at the entry fill %v, actual %+v", day, s)
+	}
+
+	orders := p.CheckExits(bar(2))
+	if len(orders) != 1 {
+		t.Fatalf("CheckExits(): expected closing order after the holding period, actual %#v", orders)
+	}
+
+	// the resting close order is not duplicated
+	orders[0].SetStatus(OrderSubmitted)
+	if again := p.CheckExits(bar(3)); len(again) != 0 {
+		t.Errorf("CheckExits(): expected no orders with a pending exit, actual %#v", again)
+	}
+
+	// a canceled close order is issued again
+	orders[0].SetStatus(OrderCanceled)
+	orders = p.CheckExits(bar(4))
+	if len(orders) != 1 {
+		t.Fatalf("CheckExits(): expected a new closing order after the cancel, actual %#v", orders)
+	}
+
+	// the close fill ends the pending exit
+	exit := &Fill{Event: Event{timestamp: day.AddDate(0, 0, 4), symbol: "TEST.DE"}, direction: SLD, qty: 10, price: 100}
+	if _, err := p.OnFill(exit, &Data{}); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+	if len(p.exits.state) != 0 || len(p.exits.pending) != 0 {
+		t.Errorf("OnFill(): expected no exit state of the closed position, actual %v %v", p.exits.state, p.exits.pending)
+	}
+}
+
+func TestPortfolioExitOnSignal(t *testing.T) {
+	p := &Portfolio{
+		sizeManager: &Size{DefaultSize: 100, DefaultValue: 100000},
+		riskManager: &Risk{},
+		holdings: map[string]Position{
+			"TEST.DE": {symbol: "TEST.DE", qty: 10, avgPrice: 100, marketPrice: 100},
+		},
+	}
+	p.SetExits(NewExits(&OppositeSignal{}))
+	p.SetNetting(&Netting{})
+	data := &Data{latest: map[string]DataEvent{"TEST.DE": &Bar{Close: 100}}}
+
+	order, err := p.OnSignal(&Signal{Event: Event{symbol: "TEST.DE"}, direction: SLD}, data)
+	if err != nil || order == nil || order.Direction() != SLD || order.Qty() != 10 {
+		t.Errorf("OnSignal(): expected exit of the position, actual %#v %v", order, err)
+	}
+}
@@ -0,0 +1,131 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ExposurePoint is the exposure of the positions at a time as share of the net liquidation
+// value of the portfolio, the cash plus the long minus the short positions.
+type ExposurePoint struct {
+	Time  time.Time
+	Long  float64
+	Short float64 // market value of the short positions, positive
+}
+
+// Gross returns the gross exposure, the sum of the long and short exposure.
+func (e ExposurePoint) Gross() float64 {
+	return e.Long + e.Short
+}
+
+// Net returns the net exposure, the long minus the short exposure.
+func (e ExposurePoint) Net() float64 {
+	return e.Long - e.Short
+}
+
+// LongShortRatio returns the ratio of the long to the short exposure, +Inf without short
+// and 0 without any exposure.
+func (e ExposurePoint) LongShortRatio() float64 {
+	if e.Short == 0 {
+		if e.Long == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return e.Long / e.Short
+}
+
+// Exposure returns the exposure of the positions over time, a point for each timestamp of the
+// data events. The exposure is only recorded of a portfolio with holdings and a positive net
+// liquidation value.
+func (s Statistic) Exposure() []ExposurePoint {
+	var exposure []ExposurePoint
+	for _, ep := range s.exposurePoints() {
+		exposure = append(exposure, ExposurePoint{
+			Time:  ep.timestamp,
+			Long:  ep.long / ep.capital,
+			Short: ep.short / ep.capital,
+		})
+	}
+	return exposure
+}
+
+// AverageGrossExposure returns the average gross exposure over time.
+func (s Statistic) AverageGrossExposure() float64 {
+	exposure := s.Exposure()
+	if len(exposure) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, e := range exposure {
+		sum += e.Gross()
+	}
+	return sum / float64(len(exposure))
+}
+
+// AverageNetExposure returns the average net exposure over time.
+func (s Statistic) AverageNetExposure() float64 {
+	exposure := s.Exposure()
+	if len(exposure) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, e := range exposure {
+		sum += e.Net()
+	}
+	return sum / float64(len(exposure))
+}
+
+// AnnualTurnover returns the annualised turnover of the portfolio, half the traded value of
+// all transactions per year as share of the average net liquidation value, e.g. 1 if the whole
+// portfolio is replaced once a year.
+func (s Statistic) AnnualTurnover() float64 {
+	points := s.exposurePoints()
+	if len(points) == 0 {
+		return 0
+	}
+	var capital float64
+	for _, ep := range points {
+		capital += ep.capital
+	}
+	first, _ := s.firstEquityPoint()
+	last, _ := s.lastEquityPoint()
+	years := last.timestamp.Sub(first.timestamp).Hours() / 24 / 365.25
+	if years <= 0 {
+		return 0
+	}
+
+	var traded float64
+	for _, fill := range s.transactionHistory {
+		traded += math.Abs(fill.Value())
+	}
+
+	return traded / 2 / (capital / float64(len(points))) / years
+}
+
+// exposurePoints returns the last equity point of each timestamp with a positive net
+// liquidation value.
+func (s Statistic) exposurePoints() []equityPoint {
+	var points []equityPoint
+	for i, ep := range s.equity {
+		if i+1 < len(s.equity) && s.equity[i+1].timestamp.Equal(ep.timestamp) {
+			continue
+		}
+		if ep.capital > 0 {
+			points = append(points, ep)
+		}
+	}
+	return points
+}
+
+// printExposure prints the average exposure and the turnover.
+func (s Statistic) printExposure() {
+	if len(s.Exposure()) == 0 {
+		return
+	}
+	fmt.Printf("Average exposure: gross %.4f net %.4f, Annual turnover: %.4f\n",
+		s.AverageGrossExposure(), s.AverageNetExposure(), s.AnnualTurnover())
+}
@@ -0,0 +1,83 @@
+package gobacktest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatisticExposure(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := day.Add(8766 * time.Hour) // a year of 365.25 days
+
+	s := Statistic{
+		equity: []equityPoint{
+			{timestamp: day, long: 500, capital: 1000},
+			{timestamp: day.AddDate(0, 0, 1), long: 100, capital: 1000},
+			{timestamp: day.AddDate(0, 0, 1), long: 600, short: 200, capital: 1000},
+			{timestamp: later, long: 500},
+		},
+		transactionHistory: []FillEvent{
+			&Fill{direction: BOT, qty: 10, price: 100},
+			&Fill{direction: SLD, qty: 10, price: 100},
+		},
+	}
+
+	exp := []ExposurePoint{{Time: day, Long: 0.5}, {Time: day.AddDate(0, 0, 1), Long: 0.6, Short: 0.2}}
+	if exposure := s.Exposure(); !reflect.DeepEqual(exposure, exp) {
+		t.Errorf("Exposure(): \nexpected %+v, \nactual   %+v", exp, exposure)
+	}
+	if gross := s.AverageGrossExposure(); math.Abs(gross-0.65) > 1e-9 {
+		t.Errorf("AverageGrossExposure(): expected 0.65, actual %v", gross)
+	}
+	if net := s.AverageNetExposure(); math.Abs(net-0.45) > 1e-9 {
+		t.Errorf("AverageNetExposure(): expected 0.45, actual %v", net)
+	}
+	if turnover := s.AnnualTurnover(); math.Abs(turnover-1) > 1e-9 {
+		t.Errorf("AnnualTurnover(): expected 1, actual %v", turnover)
+	}
+
+	var empty Statistic
+	if empty.AverageGrossExposure() != 0 || empty.AverageNetExposure() != 0 || empty.AnnualTurnover() != 0 {
+		t.Errorf("expected no exposure and turnover of an empty statistic")
+	}
+}
+
+func TestExposurePointLongShortRatio(t *testing.T) {
+	// testCases is a table for testing the long short ratio
+	var testCases = []struct {
+		msg   string
+		point ExposurePoint
+		exp   float64
+	}{
+		{"long and short:", ExposurePoint{Long: 0.75, Short: 0.25}, 3},
+		{"long only:", ExposurePoint{Long: 0.6}, math.Inf(1)},
+		{"no exposure:", ExposurePoint{}, 0},
+	}
+
+	for _, tc := range testCases {
+		if ratio := tc.point.LongShortRatio(); ratio != tc.exp {
+			t.Errorf("%v LongShortRatio(): expected %v, actual %v", tc.msg, tc.exp, ratio)
+		}
+	}
+}
+
+func TestStatisticUpdateExposure(t *testing.T) {
+	p := &Portfolio{
+		cash: 1000,
+		holdings: map[string]Position{
+			"A.DE": {symbol: "A.DE", qty: 10, marketValue: 600},
+			"B.DE": {symbol: "B.DE", qty: -5, marketValue: 200},
+			"C.DE": {symbol: "C.DE"},
+		},
+	}
+
+	var s Statistic
+	s.Update(&Bar{Event: Event{timestamp: time.Now(), symbol: "A.DE"}}, p)
+
+	exp := []ExposurePoint{{Time: s.equity[0].timestamp, Long: 600.0 / 1400, Short: 200.0 / 1400}}
+	if exposure := s.Exposure(); !reflect.DeepEqual(exposure, exp) {
+		t.Errorf("Update(): expected exposure %+v, actual %+v", exp, exposure)
+	}
+}
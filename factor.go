@@ -0,0 +1,255 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Factor scores a symbol on a rebalance date from its latest data event and the already
+// streamed data, e.g. its momentum or value. A symbol without a score returns false.
+type Factor interface {
+	Score(DataEvent, DataHandler) (float64, bool)
+}
+
+// FactorFunc is a function used as a factor.
+type FactorFunc func(DataEvent, DataHandler) (float64, bool)
+
+// Score implements Factor.
+func (f FactorFunc) Score(event DataEvent, data DataHandler) (float64, bool) {
+	return f(event, data)
+}
+
+// MetricFactor scores a symbol by a metric of its latest data event, e.g. calculated by an algo.
+type MetricFactor string
+
+// Score implements Factor.
+func (f MetricFactor) Score(event DataEvent, _ DataHandler) (float64, bool) {
+	return event.Get(string(f))
+}
+
+// MomentumFactor scores a symbol by the return of the price over the Lookback data events,
+// skipping the most recent Skip data events, e.g. 12 month momentum without the last month.
+type MomentumFactor struct {
+	Lookback int
+	Skip     int
+}
+
+// Score implements Factor.
+func (f MomentumFactor) Score(event DataEvent, data DataHandler) (float64, bool) {
+	window := data.Window(event.Symbol(), f.Lookback+f.Skip+1)
+	if f.Lookback <= 0 || len(window) < f.Lookback+f.Skip+1 {
+		return 0, false
+	}
+
+	first, last := window[0].Price(), window[f.Lookback].Price()
+	if first == 0 {
+		return 0, false
+	}
+	return last/first - 1, true
+}
+
+// FactorModel combines the scores of weighted factors to a composite score of each symbol.
+// The raw scores of each factor are winsorized and normalized to z-scores across the symbols
+// of a date, so factors of different scales add up. A symbol missing the score of any factor
+// is left out.
+type FactorModel struct {
+	Factors   []Factor
+	Weights   []float64 // the weight of each factor, equal weights if empty
+	Winsorize float64   // the share of each tail clipped, e.g. 0.05, no clipping if zero
+}
+
+// Scores returns the composite scores of the symbols of the data events, e.g. a snapshot.
+func (m *FactorModel) Scores(events []DataEvent, data DataHandler) (map[string]float64, error) {
+	if len(m.Factors) == 0 {
+		return nil, errors.New("factor model without factors")
+	}
+	if len(m.Weights) > 0 && len(m.Weights) != len(m.Factors) {
+		return nil, errors.New("factor model with a weight count different from the factors")
+	}
+
+	// the raw scores of the symbols with a score of each factor
+	var symbols []string
+	var raw [][]float64
+	for _, event := range events {
+		values := make([]float64, len(m.Factors))
+		complete := true
+		for i, f := range m.Factors {
+			v, ok := f.Score(event, data)
+			if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+				complete = false
+				break
+			}
+			values[i] = v
+		}
+		if complete {
+			symbols = append(symbols, event.Symbol())
+			raw = append(raw, values)
+		}
+	}
+
+	scores := make(map[string]float64)
+	for _, symbol := range symbols {
+		scores[symbol] = 0
+	}
+	for i := range m.Factors {
+		column := make([]float64, len(raw))
+		for j := range raw {
+			column[j] = raw[j][i]
+		}
+		weight := 1 / float64(len(m.Factors))
+		if len(m.Weights) > 0 {
+			weight = m.Weights[i]
+		}
+		for j, z := range ZScore(Winsorize(column, m.Winsorize)) {
+			scores[symbols[j]] += weight * z
+		}
+	}
+
+	return scores, nil
+}
+
+// Winsorize clips the share p of the values of each tail to the nearest value within, e.g.
+// 0.05 clips the lowest and highest 5% of the values.
+func Winsorize(values []float64, p float64) []float64 {
+	clipped := append([]float64{}, values...)
+	if p <= 0 || len(values) == 0 {
+		return clipped
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	k := int(math.Floor(p*float64(len(sorted)) + 1e-9))
+	if k > (len(sorted)-1)/2 {
+		k = (len(sorted) - 1) / 2
+	}
+	low, high := sorted[k], sorted[len(sorted)-1-k]
+	for i, v := range clipped {
+		clipped[i] = math.Max(low, math.Min(high, v))
+	}
+	return clipped
+}
+
+// ZScore normalizes the values to their distance from the mean in standard deviations,
+// all values are 0 without dispersion.
+func ZScore(values []float64) []float64 {
+	z := make([]float64, len(values))
+	if len(values) < 2 {
+		return z
+	}
+
+	mean, stddev := stat.MeanStdDev(values, nil)
+	if stddev == 0 || math.IsNaN(stddev) {
+		return z
+	}
+	for i, v := range values {
+		z[i] = (v - mean) / stddev
+	}
+	return z
+}
+
+// LongShort constructs a long-short portfolio of target weights from composite scores. It
+// goes long the Long symbols of the highest scores and short the Short symbols of the lowest
+// scores with equal weights of the Gross exposure, e.g. 1 for 100% of the equity.
+//
+// DollarNeutral splits the gross exposure equally between the long and the short side.
+// BetaNeutral scales the sides so that the beta of the portfolio to the Benchmark is zero,
+// with the betas of the daily returns of the Lookback period, which takes precedence over
+// the dollar neutrality.
+type LongShort struct {
+	Long          int
+	Short         int
+	Gross         float64 // 1 if zero
+	DollarNeutral bool
+	BetaNeutral   bool
+	Benchmark     string
+	Lookback      int // 60 if zero
+}
+
+// Weights returns the target weights of the symbols as share of the equity, negative weights
+// are short.
+func (c *LongShort) Weights(scores map[string]float64, data DataHandler) (map[string]float64, error) {
+	symbols := make([]string, 0, len(scores))
+	for symbol := range scores {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if scores[symbols[i]] == scores[symbols[j]] {
+			return symbols[i] < symbols[j]
+		}
+		return scores[symbols[i]] > scores[symbols[j]]
+	})
+
+	long := symbols[:minInt(c.Long, len(symbols))]
+	short := symbols[len(symbols)-minInt(c.Short, len(symbols)-len(long)):]
+
+	gross := c.Gross
+	if gross == 0 {
+		gross = 1
+	}
+	n := float64(len(long) + len(short))
+	longWeight, shortWeight := gross/n, gross/n
+	if c.DollarNeutral && len(long) > 0 && len(short) > 0 {
+		longWeight, shortWeight = gross/2/float64(len(long)), gross/2/float64(len(short))
+	}
+
+	if c.BetaNeutral {
+		if len(long) == 0 || len(short) == 0 {
+			return nil, errors.New("beta neutral portfolio without long or short side")
+		}
+		var longBeta, shortBeta float64
+		for _, symbol := range long {
+			longBeta += c.beta(symbol, data)
+		}
+		for _, symbol := range short {
+			shortBeta += c.beta(symbol, data)
+		}
+		if longBeta <= 0 || shortBeta <= 0 {
+			return nil, errors.New("beta neutral portfolio needs positive betas of both sides")
+		}
+
+		// equal weights within a side, the long beta offsets the short beta
+		scale := gross / (float64(len(long)) + float64(len(short))*longBeta/shortBeta)
+		longWeight, shortWeight = scale, scale*longBeta/shortBeta
+	}
+
+	weights := make(map[string]float64)
+	for _, symbol := range long {
+		weights[symbol] = longWeight
+	}
+	for _, symbol := range short {
+		weights[symbol] = -shortWeight
+	}
+	return weights, nil
+}
+
+// beta returns the beta of the returns of the symbol to the benchmark, 1 without enough data.
+func (c *LongShort) beta(symbol string, data DataHandler) float64 {
+	lookback := c.Lookback
+	if lookback == 0 {
+		lookback = 60
+	}
+
+	x, y := alignedReturns(data.Window(symbol, lookback+1), data.Window(c.Benchmark, lookback+1))
+	if len(x) < 2 {
+		return 1
+	}
+	variance := stat.Variance(y, nil)
+	if variance == 0 {
+		return 1
+	}
+	return stat.Covariance(x, y, nil) / variance
+}
+
+// minInt returns the smaller of two ints, negative ints count as zero.
+func minInt(a, b int) int {
+	if a < 0 {
+		a = 0
+	}
+	if b < a {
+		return b
+	}
+	return a
+}
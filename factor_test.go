@@ -0,0 +1,185 @@
+package gobacktest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWinsorize(t *testing.T) {
+	// testCases is a table for testing the clipping of the tails
+	var testCases = []struct {
+		msg    string
+		values []float64
+		p      float64
+		exp    []float64
+	}{
+		{"clip both tails:", []float64{100, 1, 2, 3, 4, 5, 6, 7, 8, -100}, 0.1, []float64{8, 1, 2, 3, 4, 5, 6, 7, 8, 1}},
+		{"no clipping:", []float64{100, 1, -100}, 0, []float64{100, 1, -100}},
+		{"no values:", nil, 0.1, []float64{}},
+	}
+
+	for _, tc := range testCases {
+		if result := Winsorize(tc.values, tc.p); !reflect.DeepEqual(result, tc.exp) {
+			t.Errorf("%v Winsorize(): expected %v, actual %v", tc.msg, tc.exp, result)
+		}
+	}
+}
+
+func TestZScore(t *testing.T) {
+	// testCases is a table for testing the normalization
+	var testCases = []struct {
+		msg    string
+		values []float64
+		exp    []float64
+	}{
+		{"distance from the mean:", []float64{1, 2, 3}, []float64{-1, 0, 1}},
+		{"no dispersion:", []float64{2, 2, 2}, []float64{0, 0, 0}},
+		{"single value:", []float64{2}, []float64{0}},
+	}
+
+	for _, tc := range testCases {
+		if result := ZScore(tc.values); !reflect.DeepEqual(result, tc.exp) {
+			t.Errorf("%v ZScore(): expected %v, actual %v", tc.msg, tc.exp, result)
+		}
+	}
+}
+
+func TestFactorModelScores(t *testing.T) {
+	bar := func(symbol string, metric Metric) DataEvent {
+		return &Bar{Event: Event{symbol: symbol}, Metric: metric}
+	}
+	events := []DataEvent{
+		bar("AAA", Metric{"MOM": 0.1, "VALUE": 3}),
+		bar("BBB", Metric{"MOM": 0.2, "VALUE": 2}),
+		bar("CCC", Metric{"MOM": 0.3, "VALUE": 1}),
+		bar("DDD", Metric{"MOM": 0.4}),
+	}
+
+	// testCases is a table for testing the composite scores
+	var testCases = []struct {
+		msg    string
+		model  *FactorModel
+		exp    map[string]float64
+		expErr bool
+	}{
+		{"single factor:",
+			&FactorModel{Factors: []Factor{MetricFactor("VALUE")}},
+			map[string]float64{"AAA": 1, "BBB": 0, "CCC": -1}, false},
+		{"equal weights of opposite factors:",
+			&FactorModel{Factors: []Factor{MetricFactor("MOM"), MetricFactor("VALUE")}},
+			map[string]float64{"AAA": 0, "BBB": 0, "CCC": 0}, false},
+		{"weighted factors:",
+			&FactorModel{Factors: []Factor{MetricFactor("MOM"), MetricFactor("VALUE")}, Weights: []float64{2, 1}},
+			map[string]float64{"AAA": -1, "BBB": 0, "CCC": 1}, false},
+		{"factor func:",
+			&FactorModel{Factors: []Factor{FactorFunc(func(e DataEvent, _ DataHandler) (float64, bool) {
+				return float64(len(e.Symbol())), e.Symbol() != "DDD"
+			})}},
+			map[string]float64{"AAA": 0, "BBB": 0, "CCC": 0}, false},
+		{"no factors:", &FactorModel{}, nil, true},
+		{"weights of other factors:", &FactorModel{Factors: []Factor{MetricFactor("MOM")}, Weights: []float64{1, 1}}, nil, true},
+	}
+
+	for _, tc := range testCases {
+		scores, err := tc.model.Scores(events, &Data{})
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Scores(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		for symbol, score := range scores {
+			scores[symbol] = math.Round(score*1e9) / 1e9
+		}
+		if !tc.expErr && !reflect.DeepEqual(scores, tc.exp) {
+			t.Errorf("%v Scores(): expected %v, actual %v", tc.msg, tc.exp, scores)
+		}
+	}
+}
+
+func TestMomentumFactor(t *testing.T) {
+	data := &Data{}
+	for i, price := range []float64{100, 110, 120, 90} {
+		data.SetStream(append(data.Stream(), &Bar{Event: Event{timestamp: time.Unix(int64(i), 0), symbol: "TEST.DE"}, Close: price}))
+	}
+	for _, ok := data.Next(); ok; _, ok = data.Next() {
+	}
+	latest := data.Latest("TEST.DE")
+
+	// testCases is a table for testing the momentum
+	var testCases = []struct {
+		msg    string
+		factor MomentumFactor
+		exp    float64
+		expOk  bool
+	}{
+		{"return of the lookback:", MomentumFactor{Lookback: 3}, -0.1, true},
+		{"skip the last data event:", MomentumFactor{Lookback: 2, Skip: 1}, 0.2, true},
+		{"not enough data:", MomentumFactor{Lookback: 4}, 0, false},
+	}
+
+	for _, tc := range testCases {
+		score, ok := tc.factor.Score(latest, data)
+		if ok != tc.expOk || math.Abs(score-tc.exp) > 1e-9 {
+			t.Errorf("%v Score(): expected %v %v, actual %v %v", tc.msg, tc.exp, tc.expOk, score, ok)
+		}
+	}
+}
+
+func TestLongShortWeights(t *testing.T) {
+	scores := map[string]float64{"AAA": 2, "BBB": 1, "CCC": 0, "DDD": -1, "EEE": -2}
+
+	// the returns of AAA have twice the beta of the market, of EEE and DDD a beta of one
+	data := &Data{}
+	betas := map[string]float64{"MARKET": 1, "AAA": 2, "BBB": 2, "DDD": 1, "EEE": 1}
+	prices := map[string]float64{}
+	for i, r := range []float64{0, 0.01, -0.02, 0.03, -0.02} {
+		for symbol, beta := range betas {
+			if i == 0 {
+				prices[symbol] = 100
+			}
+			prices[symbol] *= 1 + beta*r
+			data.SetStream(append(data.Stream(), &Bar{Event: Event{timestamp: time.Unix(int64(i), 0), symbol: symbol}, Close: prices[symbol]}))
+		}
+	}
+	data.SortStream()
+	for _, ok := data.Next(); ok; _, ok = data.Next() {
+	}
+
+	// testCases is a table for testing the construction of the portfolio
+	var testCases = []struct {
+		msg    string
+		c      *LongShort
+		exp    map[string]float64
+		expErr bool
+	}{
+		{"equal weights:", &LongShort{Long: 2, Short: 1},
+			map[string]float64{"AAA": 1.0 / 3, "BBB": 1.0 / 3, "EEE": -1.0 / 3}, false},
+		{"dollar neutral:", &LongShort{Long: 2, Short: 1, DollarNeutral: true, Gross: 2},
+			map[string]float64{"AAA": 0.5, "BBB": 0.5, "EEE": -1}, false},
+		{"more selected than scored:", &LongShort{Long: 4, Short: 4},
+			map[string]float64{"AAA": 0.2, "BBB": 0.2, "CCC": 0.2, "DDD": 0.2, "EEE": -0.2}, false},
+		{"beta neutral:", &LongShort{Long: 1, Short: 1, BetaNeutral: true, Benchmark: "MARKET"},
+			map[string]float64{"AAA": 1.0 / 3, "EEE": -2.0 / 3}, false},
+		{"beta neutral of equal betas:", &LongShort{Long: 1, Short: 2, BetaNeutral: true, Benchmark: "MARKET", Gross: 2},
+			map[string]float64{"AAA": 2.0 / 3, "DDD": -2.0 / 3, "EEE": -2.0 / 3}, false},
+		{"beta neutral without short side:", &LongShort{Long: 2, BetaNeutral: true, Benchmark: "MARKET"}, nil, true},
+	}
+
+	for _, tc := range testCases {
+		weights, err := tc.c.Weights(scores, data)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Weights(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		for symbol, w := range weights {
+			weights[symbol] = math.Round(w*1e9) / 1e9
+		}
+		for symbol, w := range tc.exp {
+			tc.exp[symbol] = math.Round(w*1e9) / 1e9
+		}
+		if !tc.expErr && !reflect.DeepEqual(weights, tc.exp) {
+			t.Errorf("%v Weights(): expected %v, actual %v", tc.msg, tc.exp, weights)
+		}
+	}
+}
@@ -1,15 +1,46 @@
 package gobacktest
 
+import "time"
+
 // Fill declares a basic fill event
 type Fill struct {
 	Event
 	direction   Direction // BOT for buy, SLD for sell, HLD for hold
 	Exchange    string    // exchange symbol
-	qty         int64
+	qty         float64
 	price       float64
 	commission  float64
 	exchangeFee float64
 	cost        float64 // the total cost of the filled order incl commission and fees
+	impact      float64 // the price change per share by the market impact, against the trader
+	closing     bool    // closes an existing position in hedging mode
+	multiplier  float64 // contract multiplier of the instrument, 0 means 1
+}
+
+// Multiplier defines the contract multiplier of a fill, e.g. the point value of a future.
+type Multiplier interface {
+	Multiplier() float64
+}
+
+// contractMultiplier returns the contract multiplier of a fill, 1 if it has none.
+func contractMultiplier(fill FillEvent) float64 {
+	if m, ok := fill.(Multiplier); ok {
+		return m.Multiplier()
+	}
+	return 1
+}
+
+// NewFill creates a fill of qty at a price with the commission as its cost,
+// e.g. the execution reported by a broker.
+func NewFill(timestamp time.Time, symbol string, direction Direction, qty, price, commission float64) *Fill {
+	return &Fill{
+		Event:      Event{timestamp: timestamp, symbol: symbol},
+		direction:  direction,
+		qty:        qty,
+		price:      price,
+		commission: commission,
+		cost:       commission,
+	}
 }
 
 // Direction returns the direction of a Fill
@@ -23,15 +54,25 @@ func (f *Fill) SetDirection(dir Direction) {
 }
 
 // Qty returns the qty field of a fill
-func (f Fill) Qty() int64 {
+func (f Fill) Qty() float64 {
 	return f.qty
 }
 
 // SetQty sets the Qty field of a Fill
-func (f *Fill) SetQty(i int64) {
+func (f *Fill) SetQty(i float64) {
 	f.qty = i
 }
 
+// Closing returns if the Fill closes an existing position.
+func (f Fill) Closing() bool {
+	return f.closing
+}
+
+// SetClosing sets if the Fill closes an existing position.
+func (f *Fill) SetClosing(closing bool) {
+	f.closing = closing
+}
+
 // Price returns the Price field of a fill
 func (f Fill) Price() float64 {
 	return f.price
@@ -47,26 +88,44 @@ func (f Fill) ExchangeFee() float64 {
 	return f.exchangeFee
 }
 
+// Impact returns the price change per share of the fill by the market impact of the order.
+func (f Fill) Impact() float64 {
+	return f.impact
+}
+
 // Cost returns the Cost field of a Fill
 func (f Fill) Cost() float64 {
 	return f.cost
 }
 
-// Value returns the value without cost.
+// Multiplier returns the contract multiplier of a Fill, defaults to 1.
+func (f Fill) Multiplier() float64 {
+	if f.multiplier <= 0 {
+		return 1
+	}
+	return f.multiplier
+}
+
+// SetMultiplier sets the contract multiplier of a Fill.
+func (f *Fill) SetMultiplier(m float64) {
+	f.multiplier = m
+}
+
+// Value returns the value without cost, including the contract multiplier.
 func (f Fill) Value() float64 {
-	value := float64(f.qty) * f.price
+	value := f.qty * f.price * f.Multiplier()
 	return value
 }
 
 // NetValue returns the net value including cost.
 func (f Fill) NetValue() float64 {
 	if f.direction == BOT {
-		// qty * price + cost
-		netValue := float64(f.qty)*f.price + f.cost
+		// qty * price * multiplier + cost
+		netValue := f.Value() + f.cost
 		return netValue
 	}
 	// SLD
-	//qty * price - cost
-	netValue := float64(f.qty)*f.price - f.cost
+	// qty * price * multiplier - cost
+	netValue := f.Value() - f.cost
 	return netValue
 }
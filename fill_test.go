@@ -3,6 +3,7 @@ package gobacktest
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestFillSetDirection(t *testing.T) {
@@ -32,7 +33,7 @@ func TestFillSetQty(t *testing.T) {
 	var testCases = []struct {
 		msg     string
 		fill    Fill
-		qty     int64
+		qty     float64
 		expFill Fill
 	}{
 		{"simple qty:",
@@ -106,3 +107,16 @@ func TestFillNetValue(t *testing.T) {
 		}
 	}
 }
+
+func TestNewFill(t *testing.T) {
+	timestamp := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	fill := NewFill(timestamp, "TEST.DE", SLD, 10, 5, 1.5)
+
+	exp := &Fill{Event: Event{timestamp: timestamp, symbol: "TEST.DE"}, direction: SLD, qty: 10, price: 5, commission: 1.5, cost: 1.5}
+	if !reflect.DeepEqual(fill, exp) {
+		t.Errorf("NewFill(): \nexpected %#v, \nactual %#v", exp, fill)
+	}
+	if fill.NetValue() != 48.5 {
+		t.Errorf("NewFill(): expected net value 48.5, actual %v", fill.NetValue())
+	}
+}
@@ -0,0 +1,202 @@
+package gobacktest
+
+// FillModeler decides how much of a resting order is filled on a data event and at which price.
+type FillModeler interface {
+	Fill(OrderEvent, DataEvent) (qty float64, price float64)
+}
+
+// OrderPlacer is implemented by fill models which need to know when an order is placed.
+type OrderPlacer interface {
+	Place(OrderEvent, DataEvent)
+}
+
+// TouchFillModel is a basic fill model. A resting limit order is filled completely
+// as soon as the price touches the limit, a stop order is filled at the stop price
+// as soon as the price reaches the stop.
+type TouchFillModel struct{}
+
+// Fill returns the qty and price for a resting order on a data event.
+func (m *TouchFillModel) Fill(order OrderEvent, data DataEvent) (float64, float64) {
+	remaining := order.Qty() - order.QtyFilled()
+
+	switch order.Type() {
+	case MarketOrder, MarketOnOpenOrder, MarketOnCloseOrder:
+		return remaining, data.Price()
+	case LimitOrder:
+		if through, touch := limitReached(order, data); through || touch {
+			return remaining, order.Limit()
+		}
+	case StopMarketOrder, StopLimitOrder:
+		if stopReached(order, data) {
+			return remaining, order.Stop()
+		}
+	}
+
+	return 0, 0
+}
+
+// QueueFillModel models the position of a resting limit order in the queue of its price level.
+// An order which is only touched by the price gets filled only after the volume
+// resting ahead of it at its price level has been traded. If the price trades through
+// the limit, the order is filled completely.
+// The queue ahead is taken from the order book on placement, if the data is a book event,
+// else DefaultQueue is used.
+type QueueFillModel struct {
+	DefaultQueue float64
+	queue        map[int]float64
+	touch        TouchFillModel
+}
+
+// Place records the volume resting ahead of a new order.
+func (m *QueueFillModel) Place(order OrderEvent, data DataEvent) {
+	// check for nil map, else initialise the map
+	if m.queue == nil {
+		m.queue = make(map[int]float64)
+	}
+
+	ahead := m.DefaultQueue
+	if book, ok := data.(OrderBookEvent); ok {
+		ahead = volumeAtLevel(book, order.Direction(), order.Limit())
+	}
+
+	m.queue[order.ID()] = ahead
+}
+
+// Fill returns the qty and price for a resting order on a data event.
+func (m *QueueFillModel) Fill(order OrderEvent, data DataEvent) (float64, float64) {
+	// only limit orders have a queue position
+	if order.Type() != LimitOrder {
+		return m.touch.Fill(order, data)
+	}
+
+	// check for nil map, else initialise the map
+	if m.queue == nil {
+		m.queue = make(map[int]float64)
+	}
+
+	remaining := order.Qty() - order.QtyFilled()
+	ahead, ok := m.queue[order.ID()]
+	if !ok {
+		ahead = m.DefaultQueue
+	}
+
+	through, touch := limitReached(order, data)
+	if through {
+		delete(m.queue, order.ID())
+		return remaining, order.Limit()
+	}
+
+	// an order book shows the current volume at the price level,
+	// the queue ahead can only shrink by trades or cancellations
+	if book, ok := data.(OrderBookEvent); ok {
+		if volume := volumeAtLevel(book, order.Direction(), order.Limit()); volume < ahead {
+			ahead = volume
+		}
+		m.queue[order.ID()] = ahead
+		return 0, 0
+	}
+
+	if !touch {
+		return 0, 0
+	}
+
+	// the traded volume at the price level clears the queue ahead first
+	traded := tradedVolume(order.Direction(), data)
+	if traded <= ahead {
+		m.queue[order.ID()] = ahead - traded
+		return 0, 0
+	}
+	m.queue[order.ID()] = 0
+
+	qty := traded - ahead
+	if qty > remaining {
+		qty = remaining
+	}
+
+	return qty, order.Limit()
+}
+
+// limitReached checks if the price of a data event trades through or touches the limit of an order.
+func limitReached(order OrderEvent, data DataEvent) (through, touch bool) {
+	limit := order.Limit()
+
+	var low, high float64
+	switch d := data.(type) {
+	case *Bar:
+		low, high = d.Low, d.High
+	case *Tick:
+		// a buy order is reached by the ask, a sell order by the bid
+		low, high = d.Ask, d.Bid
+	case OrderBookEvent:
+		ask, okAsk := bestLevel(d.Asks())
+		bid, okBid := bestLevel(d.Bids())
+		if !okAsk {
+			ask.Price = data.Price()
+		}
+		if !okBid {
+			bid.Price = data.Price()
+		}
+		low, high = ask.Price, bid.Price
+	default:
+		low, high = data.Price(), data.Price()
+	}
+
+	if order.Direction() == BOT {
+		return low < limit, low == limit
+	}
+	return high > limit, high == limit
+}
+
+// stopReached checks if the price of a data event reaches the stop price of an order.
+func stopReached(order OrderEvent, data DataEvent) bool {
+	stop := order.Stop()
+
+	var low, high = data.Price(), data.Price()
+	if bar, ok := data.(*Bar); ok {
+		low, high = bar.Low, bar.High
+	}
+
+	if order.Direction() == BOT {
+		return high >= stop
+	}
+	return low <= stop
+}
+
+// tradedVolume returns the volume of a data event relevant for an order direction.
+func tradedVolume(dir Direction, data DataEvent) float64 {
+	switch d := data.(type) {
+	case *Bar:
+		return d.Volume
+	case *Tick:
+		if dir == BOT {
+			return d.BidVolume
+		}
+		return d.AskVolume
+	}
+
+	return 0
+}
+
+// volumeAtLevel returns the volume of a book at a price level on the side of the direction.
+func volumeAtLevel(book OrderBookEvent, dir Direction, price float64) float64 {
+	levels := book.Asks()
+	if dir == BOT {
+		levels = book.Bids()
+	}
+
+	for _, level := range levels {
+		if level.Price == price {
+			return level.Volume
+		}
+	}
+
+	return 0
+}
+
+// bestLevel returns the first level of a sorted slice of book levels.
+func bestLevel(levels []BookLevel) (BookLevel, bool) {
+	if len(levels) == 0 {
+		return BookLevel{}, false
+	}
+	return levels[0], true
+}
@@ -0,0 +1,91 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestTouchFillModelFill(t *testing.T) {
+	var testCases = []struct {
+		msg      string
+		order    OrderEvent
+		data     DataEvent
+		expQty   float64
+		expPrice float64
+	}{
+		{"buy limit not reached:",
+			&Order{orderType: LimitOrder, direction: BOT, qty: 10, limitPrice: 9},
+			&Bar{Low: 9.5, High: 11, Close: 10},
+			0, 0,
+		},
+		{"buy limit touched:",
+			&Order{orderType: LimitOrder, direction: BOT, qty: 10, limitPrice: 9},
+			&Bar{Low: 9, High: 11, Close: 10},
+			10, 9,
+		},
+		{"sell limit with partial fill before:",
+			&Order{orderType: LimitOrder, direction: SLD, qty: 10, qtyFilled: 4, limitPrice: 11},
+			&Bar{Low: 9, High: 12, Close: 10},
+			6, 11,
+		},
+		{"sell stop reached:",
+			&Order{orderType: StopMarketOrder, direction: SLD, qty: 10, stopPrice: 9.5},
+			&Bar{Low: 9, High: 11, Close: 10},
+			10, 9.5,
+		},
+	}
+
+	for _, tc := range testCases {
+		model := &TouchFillModel{}
+		qty, price := model.Fill(tc.order, tc.data)
+		if qty != tc.expQty || price != tc.expPrice {
+			t.Errorf("%v Fill(): \nexpected %v %v, \nactual   %v %v",
+				tc.msg, tc.expQty, tc.expPrice, qty, price)
+		}
+	}
+}
+
+func TestQueueFillModelFill(t *testing.T) {
+	order := &Order{id: 1, orderType: LimitOrder, direction: BOT, qty: 100, limitPrice: 10}
+
+	model := &QueueFillModel{}
+	// the book shows 300 shares resting ahead at the limit price
+	model.Place(order, &Book{
+		BidLevels: []BookLevel{{Price: 10, Volume: 300}},
+		AskLevels: []BookLevel{{Price: 10.5, Volume: 100}},
+	})
+
+	var steps = []struct {
+		msg      string
+		data     DataEvent
+		expQty   float64
+		expPrice float64
+	}{
+		{"queue shrinks by book update:",
+			&Book{
+				BidLevels: []BookLevel{{Price: 10, Volume: 200}},
+				AskLevels: []BookLevel{{Price: 10.5, Volume: 100}},
+			},
+			0, 0,
+		},
+		{"touch with less volume than queue ahead:",
+			&Bar{Low: 10, High: 10.5, Volume: 150},
+			0, 0,
+		},
+		{"touch clears the queue, partial fill:",
+			&Bar{Low: 10, High: 10.5, Volume: 80},
+			30, 10,
+		},
+		{"price trades through the limit:",
+			&Bar{Low: 9.5, High: 10.5, Volume: 10},
+			100, 10,
+		},
+	}
+
+	for _, step := range steps {
+		qty, price := model.Fill(order, step.data)
+		if qty != step.expQty || price != step.expPrice {
+			t.Errorf("%v Fill(): \nexpected %v %v, \nactual   %v %v",
+				step.msg, step.expQty, step.expPrice, qty, price)
+		}
+	}
+}
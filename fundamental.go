@@ -0,0 +1,145 @@
+package gobacktest
+
+import (
+	"sort"
+	"time"
+)
+
+// Fundamental declares a custom event for a point-in-time fundamental report of a symbol,
+// e.g. earnings per share, revenue or book value. The time of the event is the date the report
+// became available, Period is the end of the fiscal period it covers. A restatement is a later
+// report for the same period.
+type Fundamental struct {
+	Event
+	Period time.Time
+	Values map[string]float64
+}
+
+// Kind returns the kind of the custom event.
+func (f Fundamental) Kind() string {
+	return "fundamental"
+}
+
+// Value returns a reported value, e.g. "EPS".
+func (f Fundamental) Value(field string) (float64, bool) {
+	v, ok := f.Values[field]
+	return v, ok
+}
+
+// RecordValues implements ValueRecorder.
+func (f Fundamental) RecordValues() map[string]float64 {
+	return f.Values
+}
+
+// RecordText implements TextRecorder.
+func (f Fundamental) RecordText() map[string]string {
+	return map[string]string{"period": f.Period.Format(time.RFC3339)}
+}
+
+// decodeFundamental restores a fundamental event from its record.
+func decodeFundamental(r EventRecord) (CustomEvent, error) {
+	period, err := time.Parse(time.RFC3339, r.Text["period"])
+	if err != nil {
+		return nil, err
+	}
+	return &Fundamental{Event: r.baseEvent(), Period: period, Values: r.Values}, nil
+}
+
+func init() {
+	RegisterEvent("fundamental", decodeFundamental)
+}
+
+// Fundamentals is a point-in-time store of fundamental reports keyed by symbol.
+// Every query is answered as of a date with the reports available at that date,
+// so a later restatement never leaks into an earlier decision.
+type Fundamentals struct {
+	reports map[string][]*Fundamental
+}
+
+// Add adds fundamental reports to the store.
+func (s *Fundamentals) Add(reports ...*Fundamental) {
+	// check for nil map, else initialise the map
+	if s.reports == nil {
+		s.reports = make(map[string][]*Fundamental)
+	}
+
+	for _, f := range reports {
+		list := append(s.reports[f.Symbol()], f)
+		// keep the reports ordered by their availability
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time().Before(list[j].Time())
+		})
+		s.reports[f.Symbol()] = list
+	}
+}
+
+// AsOf returns the latest report of a symbol available at the date.
+func (s *Fundamentals) AsOf(symbol string, t time.Time) (*Fundamental, bool) {
+	list := s.available(symbol, t)
+	if len(list) == 0 {
+		return nil, false
+	}
+	return list[len(list)-1], true
+}
+
+// Value returns the latest reported value of a field of a symbol available at the date.
+func (s *Fundamentals) Value(symbol, field string, t time.Time) (float64, bool) {
+	list := s.available(symbol, t)
+	for i := len(list) - 1; i >= 0; i-- {
+		if v, ok := list[i].Value(field); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// History returns one report per fiscal period of a symbol as known at the date,
+// the latest restatement available at that date, ordered by period.
+func (s *Fundamentals) History(symbol string, t time.Time) []*Fundamental {
+	var history []*Fundamental
+
+	index := make(map[time.Time]int)
+	for _, f := range s.available(symbol, t) {
+		if i, ok := index[f.Period]; ok {
+			history[i] = f
+			continue
+		}
+		index[f.Period] = len(history)
+		history = append(history, f)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Period.Before(history[j].Period)
+	})
+	return history
+}
+
+// Events returns all reports as custom events, to be added to a backtest with AddEvents.
+func (s *Fundamentals) Events() []CustomEvent {
+	var events []CustomEvent
+	for _, symbol := range sortedSymbols(s.reports) {
+		for _, f := range s.reports[symbol] {
+			events = append(events, f)
+		}
+	}
+	return events
+}
+
+// available returns the reports of a symbol available at the date.
+func (s *Fundamentals) available(symbol string, t time.Time) []*Fundamental {
+	list := s.reports[symbol]
+	n := sort.Search(len(list), func(i int) bool {
+		return list[i].Time().After(t)
+	})
+	return list[:n]
+}
+
+// sortedSymbols returns the symbols of the reports in ascending order.
+func sortedSymbols(m map[string][]*Fundamental) []string {
+	symbols := make([]string, 0, len(m))
+	for s := range m {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
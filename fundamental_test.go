@@ -0,0 +1,75 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFundamentalsAsOf(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 1, d, 0, 0, 0, 0, time.UTC) }
+	q4 := time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC)
+	q3 := time.Date(2017, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	report := &Fundamental{Event: Event{timestamp: day(10), symbol: "TEST"}, Period: q4, Values: map[string]float64{"EPS": 1.2, "Revenue": 100}}
+	restated := &Fundamental{Event: Event{timestamp: day(20), symbol: "TEST"}, Period: q4, Values: map[string]float64{"EPS": 1.0}}
+	previous := &Fundamental{Event: Event{timestamp: day(1), symbol: "TEST"}, Period: q3, Values: map[string]float64{"EPS": 0.9, "Revenue": 90}}
+
+	store := &Fundamentals{}
+	store.Add(restated, report, previous)
+
+	// testCases is a table for testing the point-in-time queries
+	var testCases = []struct {
+		msg        string
+		date       time.Time
+		expReport  *Fundamental
+		expEPS     float64
+		expRevenue float64
+		expHistory []*Fundamental
+	}{
+		{"before the first report:", time.Date(2017, 12, 1, 0, 0, 0, 0, time.UTC), nil, 0, 0, nil},
+		{"after the first report:", day(5), previous, 0.9, 90, []*Fundamental{previous}},
+		{"on the report date:", day(10), report, 1.2, 100, []*Fundamental{previous, report}},
+		{"after the restatement:", day(25), restated, 1.0, 100, []*Fundamental{previous, restated}},
+	}
+
+	for _, tc := range testCases {
+		f, ok := store.AsOf("TEST", tc.date)
+		if ok != (tc.expReport != nil) || f != tc.expReport {
+			t.Errorf("%v AsOf(): expected %v, actual %v", tc.msg, tc.expReport, f)
+		}
+		if eps, _ := store.Value("TEST", "EPS", tc.date); eps != tc.expEPS {
+			t.Errorf("%v Value(EPS): expected %v, actual %v", tc.msg, tc.expEPS, eps)
+		}
+		// the restatement does not report the revenue, the last known revenue is used
+		if rev, _ := store.Value("TEST", "Revenue", tc.date); rev != tc.expRevenue {
+			t.Errorf("%v Value(Revenue): expected %v, actual %v", tc.msg, tc.expRevenue, rev)
+		}
+		if history := store.History("TEST", tc.date); !reflect.DeepEqual(history, tc.expHistory) {
+			t.Errorf("%v History(): expected %v, actual %v", tc.msg, tc.expHistory, history)
+		}
+	}
+
+	if len(store.Events()) != 3 {
+		t.Errorf("Events(): expected 3 events, actual %v", len(store.Events()))
+	}
+	if _, ok := store.AsOf("UNKNOWN", day(25)); ok {
+		t.Errorf("AsOf(UNKNOWN): expected false, actual true")
+	}
+}
+
+func TestFundamentalRecord(t *testing.T) {
+	f := &Fundamental{
+		Event:  Event{timestamp: time.Date(2018, 1, 10, 0, 0, 0, 0, time.UTC), symbol: "TEST", eventID: 4},
+		Period: time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC),
+		Values: map[string]float64{"EPS": 1.2},
+	}
+
+	e, err := NewEventRecord(f).Event()
+	if err != nil {
+		t.Fatalf("Event(): unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(e, f) {
+		t.Errorf("Event(): expected %#v, actual %#v", f, e)
+	}
+}
@@ -0,0 +1,111 @@
+package gobacktest
+
+import (
+	"time"
+)
+
+// GapPnL is the profit and loss of the positions of a day, before costs. Overnight is the gap
+// from the session close marks of the previous day to the open marks of the day, Intraday the
+// move from the open marks to the session close marks of the day, including the difference of
+// the fill prices to the marks of the trades of the day.
+type GapPnL struct {
+	Date      time.Time // midnight of the day in the location of the data
+	Overnight float64
+	Intraday  float64
+}
+
+// gapMark is the last mark of a symbol and the quantity held since.
+type gapMark struct {
+	qty   float64
+	price float64
+	time  time.Time
+}
+
+// GapAttribution returns the profit and loss of each day split into the overnight gap and the
+// intraday move. A position is marked at the session close with the price of the last data
+// event of a day, and at the next open with the open of the first bar of the next day, or the
+// price of the first data event without an open, e.g. a tick.
+func (s Statistic) GapAttribution() []GapPnL {
+	applied := make(map[FillEvent]bool, len(s.transactionHistory))
+	for _, fill := range s.transactionHistory {
+		applied[fill] = true
+	}
+
+	var days []GapPnL
+	book := func(t time.Time, overnight, intraday float64) {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		if len(days) == 0 || day.After(days[len(days)-1].Date) {
+			days = append(days, GapPnL{Date: day})
+		}
+		days[len(days)-1].Overnight += overnight
+		days[len(days)-1].Intraday += intraday
+	}
+
+	marks := make(map[string]*gapMark)
+	mark := func(symbol string) *gapMark {
+		m, ok := marks[symbol]
+		if !ok {
+			m = &gapMark{}
+			marks[symbol] = m
+		}
+		return m
+	}
+
+	for _, e := range s.eventHistory {
+		switch event := e.(type) {
+		case FillEvent:
+			if !applied[event] {
+				continue
+			}
+			m := mark(event.Symbol())
+			qty := event.Qty()
+			if event.Direction() == SLD {
+				qty = -qty
+			}
+			// the traded qty earns the difference of the fill price to the mark
+			if m.price != 0 {
+				book(event.Time(), 0, qty*(m.price-event.Price()))
+			} else {
+				m.price, m.time = event.Price(), event.Time()
+			}
+			m.qty += qty
+
+		case DataEvent:
+			m := mark(event.Symbol())
+			price := event.Price()
+			var overnight, intraday float64
+			if m.qty != 0 && m.price != 0 {
+				if newDay(m.time, event.Time()) {
+					open := openMark(event)
+					overnight = m.qty * (open - m.price)
+					intraday = m.qty * (price - open)
+				} else {
+					intraday = m.qty * (price - m.price)
+				}
+			}
+			m.price, m.time = price, event.Time()
+			book(event.Time(), overnight, intraday)
+		}
+	}
+
+	return days
+}
+
+// GapPnLTotal returns the profit and loss of all days split into the overnight gaps and the
+// intraday moves.
+func (s Statistic) GapPnLTotal() GapPnL {
+	var total GapPnL
+	for _, day := range s.GapAttribution() {
+		total.Overnight += day.Overnight
+		total.Intraday += day.Intraday
+	}
+	return total
+}
+
+// openMark returns the price a data event opens the day with, the open of a bar, else the price.
+func openMark(e DataEvent) float64 {
+	if bar, ok := e.(*Bar); ok && bar.Open != 0 {
+		return bar.Open
+	}
+	return e.Price()
+}
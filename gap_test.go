@@ -0,0 +1,97 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGapAttribution(t *testing.T) {
+	day1 := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	bar := func(t time.Time, open, close float64) *Bar {
+		return &Bar{Event: Event{timestamp: t, symbol: "TEST.DE"}, Open: open, Close: close}
+	}
+	fill := func(t time.Time, direction Direction, qty, price float64) *Fill {
+		return &Fill{Event: Event{timestamp: t, symbol: "TEST.DE"}, direction: direction, qty: qty, price: price}
+	}
+
+	buy := fill(day1.Add(10*time.Hour), BOT, 10, 101)
+	sell := fill(day2.Add(10*time.Hour), SLD, 10, 107)
+	failed := fill(day1.Add(10*time.Hour), BOT, 10, 101)
+
+	// testCases is a table for testing the attribution of the overnight gaps
+	var testCases = []struct {
+		msg          string
+		events       []EventHandler
+		transactions []FillEvent
+		exp          []GapPnL
+	}{
+		{"round trip over night:",
+			[]EventHandler{
+				bar(day1.Add(10*time.Hour), 99, 100), buy,
+				bar(day1.Add(16*time.Hour), 104, 105),
+				bar(day2.Add(10*time.Hour), 110, 108), sell,
+				bar(day2.Add(16*time.Hour), 108, 109),
+			},
+			[]FillEvent{buy, sell},
+			[]GapPnL{{Date: day1, Intraday: 40}, {Date: day2, Overnight: 50, Intraday: -30}},
+		},
+		{"bar without open is marked at the close:",
+			[]EventHandler{
+				bar(day1.Add(10*time.Hour), 99, 100), buy,
+				bar(day2.Add(10*time.Hour), 0, 108),
+			},
+			[]FillEvent{buy},
+			[]GapPnL{{Date: day1, Intraday: -10}, {Date: day2, Overnight: 80}},
+		},
+		{"failed fill is ignored:",
+			[]EventHandler{
+				bar(day1.Add(10*time.Hour), 99, 100), failed,
+				bar(day2.Add(10*time.Hour), 110, 108),
+			},
+			nil,
+			[]GapPnL{{Date: day1}, {Date: day2}},
+		},
+		{"no events:", nil, nil, nil},
+	}
+
+	for _, tc := range testCases {
+		s := Statistic{eventHistory: tc.events, transactionHistory: tc.transactions}
+		if days := s.GapAttribution(); !reflect.DeepEqual(days, tc.exp) {
+			t.Errorf("%v GapAttribution(): \nexpected %+v, \nactual   %+v", tc.msg, tc.exp, days)
+		}
+	}
+}
+
+func TestGapPnLTotal(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	var stream []DataEvent
+	for i, price := range [][2]float64{{100, 102}, {104, 103}, {101, 105}, {106, 108}} {
+		stream = append(stream, &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: "TEST.DE"}, Open: price[0], Close: price[1]})
+	}
+	data := &Data{}
+	data.SetStream(stream)
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the overnight gaps and intraday moves add up to the profit and loss of the positions
+	s := test.statistic.(*Statistic)
+	total := s.GapPnLTotal()
+	var pnl float64
+	for _, pos := range test.portfolio.(*Portfolio).Holdings() {
+		pnl += pos.totalProfitLoss
+	}
+	if total.Overnight == 0 || total.Intraday == 0 {
+		t.Errorf("GapPnLTotal(): expected overnight gaps and intraday moves, actual %+v", total)
+	}
+	if diff := total.Overnight + total.Intraday - pnl; diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("GapPnLTotal(): expected a total of %v, actual %+v", pnl, total)
+	}
+}
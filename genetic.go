@@ -0,0 +1,126 @@
+package gobacktest
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// GeneticAlgorithm searches the parameter space by evolving a population of parameter sets.
+// Each generation keeps the Elite best individuals and breeds the rest by tournament selection,
+// uniform crossover and gaussian mutation.
+type GeneticAlgorithm struct {
+	Budget
+	Population    int     // defaults to 20
+	Generations   int     // defaults to 10
+	CrossoverRate float64 // defaults to 0.9
+	MutationRate  float64 // chance to mutate a gene, defaults to 0.1
+	Elite         int     // defaults to 1
+	Seed          int64
+}
+
+// individual is a point of the unit cube with its score.
+type individual struct {
+	genes []float64
+	score float64
+}
+
+// Optimize evolves the population for the number of generations or until the budget is used.
+func (g *GeneticAlgorithm) Optimize(space []Param, objective Objective) (OptimizationResult, error) {
+	s, err := newSearch(space, objective, g.Budget)
+	if err != nil {
+		return OptimizationResult{}, err
+	}
+	rng := rand.New(rand.NewSource(g.Seed))
+
+	size := g.Population
+	if size <= 0 {
+		size = 20
+	}
+	generations := g.Generations
+	if generations <= 0 {
+		generations = 10
+	}
+	crossover := g.CrossoverRate
+	if crossover <= 0 {
+		crossover = 0.9
+	}
+	mutation := g.MutationRate
+	if mutation <= 0 {
+		mutation = 0.1
+	}
+	elite := g.Elite
+	if elite <= 0 {
+		elite = 1
+	}
+	if elite > size {
+		elite = size
+	}
+
+	// random initial population
+	population := make([]individual, size)
+	for i := range population {
+		genes := make([]float64, len(space))
+		for j := range genes {
+			genes[j] = rng.Float64()
+		}
+		population[i] = individual{genes: genes}
+	}
+
+	for gen := 0; gen < generations; gen++ {
+		for i := range population {
+			score, err := s.evaluate(population[i].genes)
+			if err != nil {
+				return s.result, err
+			}
+			population[i].score = score
+			if s.done() {
+				return s.result, nil
+			}
+		}
+
+		sort.SliceStable(population, func(i, j int) bool {
+			return population[i].score > population[j].score
+		})
+
+		// the elite survives unchanged
+		next := append([]individual{}, population[:elite]...)
+		for len(next) < size {
+			a := tournament(population, rng)
+			b := tournament(population, rng)
+
+			genes := append([]float64{}, a.genes...)
+			if rng.Float64() < crossover {
+				for j := range genes {
+					if rng.Float64() < 0.5 {
+						genes[j] = b.genes[j]
+					}
+				}
+			}
+			for j := range genes {
+				if rng.Float64() < mutation {
+					genes[j] += rng.NormFloat64() * 0.1
+					if genes[j] < 0 {
+						genes[j] = 0
+					}
+					if genes[j] > 1 {
+						genes[j] = 1
+					}
+				}
+			}
+			next = append(next, individual{genes: genes})
+		}
+		population = next
+	}
+
+	return s.result, nil
+}
+
+// tournament returns the better of two random individuals.
+func tournament(population []individual, rng *rand.Rand) individual {
+	a := population[rng.Intn(len(population))]
+	b := population[rng.Intn(len(population))]
+	if b.score > a.score {
+		return b
+	}
+	return a
+}
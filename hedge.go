@@ -0,0 +1,150 @@
+package gobacktest
+
+// AccountMode defines how fills of opposite directions are booked.
+type AccountMode int
+
+// different account modes
+const (
+	// NettingMode nets all fills of a symbol into a single position
+	NettingMode AccountMode = iota // 0
+	// HedgingMode keeps a long and a short position of a symbol side by side
+	HedgingMode
+)
+
+// Hedge holds the separate long and short position of a symbol in hedging mode.
+type Hedge struct {
+	Long  Position
+	Short Position
+}
+
+// Side selects the position of a hedge, which is closed by an exit signal in hedging mode.
+type Side int
+
+// different sides of a hedge
+const (
+	// BothSides closes the long and the short position
+	BothSides Side = iota // 0
+	// LongSide closes only the long position
+	LongSide
+	// ShortSide closes only the short position
+	ShortSide
+)
+
+// ExitSplitter splits an exit signal of both sides of a hedge into an exit signal per side.
+type ExitSplitter interface {
+	SplitExit(SignalEvent) []*Signal
+}
+
+// hedger returns the long and short position of a symbol in hedging mode.
+type hedger interface {
+	Hedge(string) (Hedge, bool)
+}
+
+// Qty returns the net qty of both positions.
+func (h Hedge) Qty() float64 {
+	return h.Long.qty + h.Short.qty
+}
+
+// exit returns the direction and qty of the order closing a side of the hedge, for both sides
+// the long position first. ok is false, if the side holds no position.
+func (h Hedge) exit(side Side) (dir Direction, qty float64, ok bool) {
+	if side != ShortSide && h.Long.qty > 0 {
+		return SLD, h.Long.qty, true
+	}
+	if side != LongSide && h.Short.qty < 0 {
+		return BOT, -h.Short.qty, true
+	}
+	return EXT, 0, false
+}
+
+// OnFill books a fill in hedging mode. A closing fill reduces the position of the
+// opposite direction, any qty exceeding that position opens the other side.
+// A fill which is not closing always adds to the position of its direction.
+func (h *Hedge) OnFill(fill FillEvent) {
+	closing := false
+	if c, ok := fill.(Closer); ok {
+		closing = c.Closing()
+	}
+
+	// select the position to close, if any
+	var open float64
+	var pos, other *Position
+	switch fill.Direction() {
+	case BOT:
+		pos, other = &h.Short, &h.Long
+		open = -h.Short.qty
+	case SLD:
+		pos, other = &h.Long, &h.Short
+		open = h.Long.qty
+	default:
+		return
+	}
+
+	if !closing || open <= 0 {
+		bookFill(other, fill)
+		return
+	}
+
+	if fill.Qty() <= open {
+		bookFill(pos, fill)
+		return
+	}
+
+	// split the fill into a closing and an opening part
+	closePart, openPart := splitFill(fill, open)
+	bookFill(pos, closePart)
+	bookFill(other, openPart)
+}
+
+// UpdateValue updates the market value of both positions.
+func (h *Hedge) UpdateValue(data DataEvent) {
+	if h.Long.qty != 0 {
+		h.Long.UpdateValue(data)
+	}
+	if h.Short.qty != 0 {
+		h.Short.UpdateValue(data)
+	}
+}
+
+// bookFill creates or updates a position with a fill.
+func bookFill(pos *Position, fill FillEvent) {
+	if pos.symbol == "" {
+		pos.Create(fill)
+		return
+	}
+	pos.Update(fill)
+}
+
+// splitFill splits a fill into two fills, the first one with the given qty.
+// The cost is split proportionally to the qty.
+func splitFill(fill FillEvent, qty float64) (FillEvent, FillEvent) {
+	f := &Fill{
+		Event:     Event{timestamp: fill.Time(), symbol: fill.Symbol()},
+		direction: fill.Direction(),
+		qty:       fill.Qty(),
+		price:     fill.Price(),
+	}
+	if concrete, ok := fill.(*Fill); ok {
+		f.Exchange = concrete.Exchange
+	}
+	if m := contractMultiplier(fill); m != 1 {
+		f.multiplier = m
+	}
+
+	share := qty / fill.Qty()
+
+	first := *f
+	first.qty = qty
+	first.commission = fill.Commission() * share
+	first.exchangeFee = fill.ExchangeFee() * share
+	first.cost = fill.Cost() * share
+	first.closing = true
+
+	second := *f
+	second.qty = fill.Qty() - qty
+	second.commission = fill.Commission() - first.commission
+	second.exchangeFee = fill.ExchangeFee() - first.exchangeFee
+	second.cost = fill.Cost() - first.cost
+
+	return &first, &second
+}
@@ -0,0 +1,142 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHedgeOnFill(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	newFill := func(dir Direction, qty float64, closing bool) *Fill {
+		return &Fill{
+			Event:     Event{timestamp: exampleTime, symbol: "EURUSD"},
+			direction: dir,
+			qty:       qty, price: 1.1,
+			commission: 2, cost: 2,
+			closing: closing,
+		}
+	}
+
+	// testCases is a table for testing the booking of fills in hedging mode
+	var testCases = []struct {
+		msg      string
+		fills    []*Fill
+		expLong  float64
+		expShort float64
+		expQty   float64
+	}{
+		{"opposite fills co-exist:",
+			[]*Fill{newFill(BOT, 10, false), newFill(SLD, 4, false)},
+			10, -4, 6,
+		},
+		{"closing fill reduces the opposite position:",
+			[]*Fill{newFill(BOT, 10, false), newFill(SLD, 4, false), newFill(BOT, 3, true)},
+			10, -1, 9,
+		},
+		{"closing fill exceeding the opposite position:",
+			[]*Fill{newFill(SLD, 5, false), newFill(BOT, 8, true)},
+			3, 0, 3,
+		},
+		{"closing fill without opposite position opens:",
+			[]*Fill{newFill(SLD, 5, true)},
+			0, -5, -5,
+		},
+	}
+
+	for _, tc := range testCases {
+		var h Hedge
+		for _, fill := range tc.fills {
+			h.OnFill(fill)
+		}
+
+		if (h.Long.qty != tc.expLong) || (h.Short.qty != tc.expShort) || (h.Qty() != tc.expQty) {
+			t.Errorf("%v OnFill(): \nexpected long %v short %v net %v, \nactual   long %v short %v net %v",
+				tc.msg, tc.expLong, tc.expShort, tc.expQty, h.Long.qty, h.Short.qty, h.Qty())
+		}
+	}
+}
+
+func TestSplitFill(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+	fill := &Fill{
+		Event:     Event{timestamp: exampleTime, symbol: "EURUSD"},
+		direction: BOT,
+		qty:       10, price: 1.1,
+		commission: 4, exchangeFee: 1, cost: 5,
+	}
+
+	first, second := splitFill(fill, 4)
+
+	if (first.Qty() != 4) || (second.Qty() != 6) || (first.Cost() != 2) || (second.Cost() != 3) {
+		t.Errorf("splitFill(): \nexpected qty 4 6 cost 2 3, \nactual   qty %v %v cost %v %v",
+			first.Qty(), second.Qty(), first.Cost(), second.Cost())
+	}
+}
+
+func TestPortfolioHedgeExit(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+	data := &Data{latest: map[string]DataEvent{"EURUSD": &Bar{Close: 1.1}}}
+
+	newFill := func(order *Order) *Fill {
+		return &Fill{
+			Event:     Event{timestamp: exampleTime, symbol: "EURUSD"},
+			direction: order.Direction(),
+			qty:       order.Qty(), price: 1.1,
+			closing: order.Closing(),
+		}
+	}
+
+	// testCases is a table for testing the exit of a hedge by side
+	var testCases = []struct {
+		msg      string
+		long     float64
+		short    float64
+		side     Side
+		expLong  float64
+		expShort float64
+	}{
+		{"exit of both sides closes long and short:", 10, 4, BothSides, 0, 0},
+		{"exit of both sides with a flat net position:", 5, 5, BothSides, 0, 0},
+		{"exit of both sides with only a long position:", 5, 0, BothSides, 0, 0},
+		{"exit of the short side keeps the long side:", 10, 4, ShortSide, 10, 0},
+		{"exit of the long side keeps the short side:", 10, 4, LongSide, 0, -4},
+		{"exit of an empty side is not sized:", 10, 0, ShortSide, 10, 0},
+	}
+
+	for _, tc := range testCases {
+		p := &Portfolio{
+			initialCash:       100000,
+			cash:              100000,
+			sizeManager:       &Size{DefaultSize: 100, DefaultValue: 100000},
+			riskManager:       &Risk{},
+			allowNegativeCash: true,
+		}
+		p.SetAccountMode(HedgingMode)
+		if tc.long != 0 {
+			p.OnFill(newFill(&Order{direction: BOT, qty: tc.long}), data)
+		}
+		if tc.short != 0 {
+			p.OnFill(newFill(&Order{direction: SLD, qty: tc.short}), data)
+		}
+
+		signal := &Signal{Event: Event{timestamp: exampleTime, symbol: "EURUSD"}, direction: EXT, side: tc.side}
+		signals := p.SplitExit(signal)
+		if len(signals) == 0 {
+			signals = []*Signal{signal}
+		}
+		for _, s := range signals {
+			order, err := p.OnSignal(s, data)
+			if err != nil || order == nil || order.Direction() == EXT {
+				continue
+			}
+			p.OnFill(newFill(order), data)
+		}
+
+		h, _ := p.Hedge("EURUSD")
+		if (h.Long.qty != tc.expLong) || (h.Short.qty != tc.expShort) {
+			t.Errorf("%v OnSignal(): \nexpected long %v short %v, \nactual   long %v short %v",
+				tc.msg, tc.expLong, tc.expShort, h.Long.qty, h.Short.qty)
+		}
+	}
+}
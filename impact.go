@@ -0,0 +1,72 @@
+package gobacktest
+
+import (
+	"math"
+)
+
+// ImpactModeler is the basic interface for calculating the market impact of a fill.
+// It returns the price change per share against the trader.
+type ImpactModeler interface {
+	Impact(qty float64, price float64, data DataEvent) float64
+}
+
+// LinearImpact moves the price linear to the participation of the fill
+// in the volume of the data event: price * Coefficient * qty / volume.
+type LinearImpact struct {
+	Coefficient float64
+}
+
+// Impact returns the price change per share of a fill.
+func (m *LinearImpact) Impact(qty float64, price float64, data DataEvent) float64 {
+	participation, ok := participationRate(qty, data)
+	if !ok {
+		return 0
+	}
+
+	return price * m.Coefficient * participation
+}
+
+// SquareRootImpact moves the price with the square root of the participation
+// of the fill in the volume of the data event: price * Coefficient * Volatility * sqrt(qty / volume).
+// A Volatility of 0 is ignored.
+type SquareRootImpact struct {
+	Coefficient float64
+	Volatility  float64
+}
+
+// Impact returns the price change per share of a fill.
+func (m *SquareRootImpact) Impact(qty float64, price float64, data DataEvent) float64 {
+	participation, ok := participationRate(qty, data)
+	if !ok {
+		return 0
+	}
+
+	volatility := m.Volatility
+	if volatility == 0 {
+		volatility = 1
+	}
+
+	return price * m.Coefficient * volatility * math.Sqrt(participation)
+}
+
+// participationRate returns the share of a qty in the volume of a data event.
+func participationRate(qty float64, data DataEvent) (float64, bool) {
+	volume := eventVolume(data)
+	if volume <= 0 || qty == 0 {
+		return 0, false
+	}
+
+	return math.Abs(qty) / volume, true
+}
+
+// eventVolume returns the total volume of a data event.
+func eventVolume(data DataEvent) float64 {
+	switch d := data.(type) {
+	case *Bar:
+		return d.Volume
+	case *Tick:
+		return d.BidVolume + d.AskVolume
+	}
+
+	return 0
+}
@@ -0,0 +1,75 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestImpact(t *testing.T) {
+	var testCases = []struct {
+		msg   string
+		model ImpactModeler
+		qty   float64
+		price float64
+		data  DataEvent
+		exp   float64
+	}{
+		{"linear impact:",
+			&LinearImpact{Coefficient: 0.1},
+			100, 10,
+			&Bar{Volume: 1000},
+			0.1,
+		},
+		{"linear impact without volume:",
+			&LinearImpact{Coefficient: 0.1},
+			100, 10,
+			&Bar{},
+			0,
+		},
+		{"square root impact:",
+			&SquareRootImpact{Coefficient: 0.5},
+			100, 10,
+			&Bar{Volume: 400},
+			2.5,
+		},
+		{"square root impact with volatility:",
+			&SquareRootImpact{Coefficient: 0.5, Volatility: 0.2},
+			100, 10,
+			&Tick{BidVolume: 200, AskVolume: 200},
+			0.5,
+		},
+	}
+
+	for _, tc := range testCases {
+		impact := tc.model.Impact(tc.qty, tc.price, tc.data)
+		if impact != tc.exp {
+			t.Errorf("%v Impact(%v, %v): \nexpected %v, \nactual   %v",
+				tc.msg, tc.qty, tc.price, tc.exp, impact)
+		}
+	}
+}
+
+func TestExchangeApplyImpact(t *testing.T) {
+	var e = &Exchange{ImpactModel: &LinearImpact{Coefficient: 0.1}}
+	var data = &Bar{Volume: 1000}
+
+	// testCases is a table for testing the impact on the price of an order
+	var testCases = []struct {
+		msg   string
+		order OrderEvent
+		exp   float64
+	}{
+		{"buy order pays more:", &Order{direction: BOT}, 10.1},
+		{"sell order receives less:", &Order{direction: SLD}, 9.9},
+		{"buy limit order pays at most the limit:", &Order{direction: BOT, orderType: LimitOrder, limitPrice: 10.05}, 10.05},
+		{"sell limit order receives at least the limit:", &Order{direction: SLD, orderType: LimitOrder, limitPrice: 9.95}, 9.95},
+		{"stop limit order pays at most the limit:", &Order{direction: BOT, orderType: StopLimitOrder, limitPrice: 10}, 10},
+		{"limit order beyond the impact:", &Order{direction: BOT, orderType: LimitOrder, limitPrice: 11}, 10.1},
+	}
+
+	for _, tc := range testCases {
+		price := e.applyImpact(tc.order, 100, 10, data)
+		if price != tc.exp {
+			t.Errorf("%v applyImpact(): \nexpected %v, \nactual   %v", tc.msg, tc.exp, price)
+		}
+	}
+}
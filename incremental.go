@@ -0,0 +1,42 @@
+package gobacktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// streamSetter is a data handler which allows to set its data stream.
+type streamSetter interface {
+	SetStream([]DataEvent)
+}
+
+// Extend appends new data events to a completed backtest and processes only the new events.
+// The portfolio, open orders, strategy and statistics continue from the state of the last run,
+// e.g. to generate the signals of a new bar in a nightly workflow without re-running the history.
+func (t *Backtest) Extend(events ...DataEvent) error {
+	return t.ExtendContext(context.Background(), events...)
+}
+
+// ExtendContext appends new data events to a completed backtest and processes them,
+// the extension stops when the context is cancelled.
+func (t *Backtest) ExtendContext(ctx context.Context, events ...DataEvent) error {
+	data, ok := t.data.(streamSetter)
+	if !ok {
+		return errors.New("data handler does not allow to extend the data stream")
+	}
+
+	// new data may not rewrite the history of the backtest
+	for _, e := range events {
+		if !t.clock.Now.IsZero() && !e.Time().After(t.clock.Now) {
+			return fmt.Errorf("data event of %s at %v not after the last processed data at %v", e.Symbol(), e.Time(), t.clock.Now)
+		}
+	}
+
+	data.SetStream(append(t.data.Stream(), events...))
+	if sorter, ok := t.data.(interface{ SortStream() }); ok {
+		sorter.SortStream()
+	}
+
+	return t.run(ctx)
+}
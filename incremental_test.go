@@ -0,0 +1,55 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestBacktestExtend(t *testing.T) {
+	build := func(stream []DataEvent) *Backtest {
+		data := &Data{}
+		data.SetStream(stream)
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		return test
+	}
+
+	stream := newStressStream(100, 101, 102, 103, 104)
+
+	// the complete run as reference
+	full := build(newStressStream(100, 101, 102, 103, 104))
+	if err := full.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the history is processed once, the new bars are appended
+	strategy := &countingStrategy{Strategy: NewStrategy("counting")}
+	incremental := build(stream[:3])
+	incremental.SetStrategy(strategy)
+	if err := incremental.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	for _, bar := range stream[3:] {
+		if err := incremental.Extend(bar); err != nil {
+			t.Fatalf("Extend(): unexpected error %v", err)
+		}
+	}
+
+	if strategy.events != 5 {
+		t.Errorf("Extend(): expected the strategy to receive 5 events, actual %v", strategy.events)
+	}
+	if incremental.portfolio.Value() != full.portfolio.Value() || incremental.portfolio.Cash() != full.portfolio.Cash() {
+		t.Errorf("Extend(): expected value %v cash %v, actual value %v cash %v",
+			full.portfolio.Value(), full.portfolio.Cash(), incremental.portfolio.Value(), incremental.portfolio.Cash())
+	}
+	if len(incremental.statistic.Transactions()) != len(full.statistic.Transactions()) {
+		t.Errorf("Extend(): expected %v transactions, actual %v", len(full.statistic.Transactions()), len(incremental.statistic.Transactions()))
+	}
+
+	// a data event within the history is rejected
+	if err := incremental.Extend(stream[4]); err == nil {
+		t.Errorf("Extend() with old data: expected error, actual nil")
+	}
+}
@@ -0,0 +1,136 @@
+package gobacktest
+
+import (
+	"math"
+	"sort"
+)
+
+// AssetClass defines the class of an instrument.
+type AssetClass int
+
+// different asset classes
+const (
+	Stock AssetClass = iota // 0
+	ETF
+	Future
+	Option
+	Forex
+	Crypto
+)
+
+// String returns the name of the asset class.
+func (a AssetClass) String() string {
+	switch a {
+	case ETF:
+		return "ETF"
+	case Future:
+		return "Future"
+	case Option:
+		return "Option"
+	case Forex:
+		return "Forex"
+	case Crypto:
+		return "Crypto"
+	}
+	return "Stock"
+}
+
+// Instrument holds the metadata of a tradable symbol.
+type Instrument struct {
+	Symbol     string
+	AssetClass AssetClass
+	TickSize   float64 // min price increment, 0 means no rounding
+	LotSize    LotSize
+	Multiplier float64 // contract multiplier, 0 means 1
+}
+
+// RoundPrice rounds a price to the nearest tick.
+func (i Instrument) RoundPrice(price float64) float64 {
+	if i.TickSize <= 0 {
+		return price
+	}
+
+	ticks := math.Round(price / i.TickSize)
+	return math.Round(ticks*i.TickSize*math.Pow10(DP+4)) / math.Pow10(DP+4)
+}
+
+// ContractMultiplier returns the contract multiplier of the instrument, defaults to 1.
+func (i Instrument) ContractMultiplier() float64 {
+	if i.Multiplier <= 0 {
+		return 1
+	}
+	return i.Multiplier
+}
+
+// Notional returns the notional value of a qty at a price, including the contract multiplier.
+func (i Instrument) Notional(qty, price float64) float64 {
+	return math.Abs(qty) * price * i.ContractMultiplier()
+}
+
+// DefaultInstrument returns the metadata used for symbols without a registered instrument,
+// a stock traded in whole shares.
+func DefaultInstrument(symbol string) Instrument {
+	return Instrument{
+		Symbol:     symbol,
+		AssetClass: Stock,
+		LotSize:    LotSize{Step: 1},
+		Multiplier: 1,
+	}
+}
+
+// InstrumentRegistry holds the instruments of all known symbols.
+// A nil registry returns the default instrument for every symbol.
+type InstrumentRegistry struct {
+	instruments map[string]Instrument
+}
+
+// NewInstrumentRegistry creates a registry with the given instruments.
+func NewInstrumentRegistry(instruments ...Instrument) *InstrumentRegistry {
+	r := &InstrumentRegistry{}
+	for _, i := range instruments {
+		r.Add(i)
+	}
+	return r
+}
+
+// Add adds or replaces an instrument.
+func (r *InstrumentRegistry) Add(i Instrument) {
+	// check for nil map, else initialise the map
+	if r.instruments == nil {
+		r.instruments = make(map[string]Instrument)
+	}
+	r.instruments[i.Symbol] = i
+}
+
+// Lookup returns the instrument of a symbol and if it is registered.
+func (r *InstrumentRegistry) Lookup(symbol string) (Instrument, bool) {
+	if r == nil {
+		return DefaultInstrument(symbol), false
+	}
+	i, ok := r.instruments[symbol]
+	if !ok {
+		return DefaultInstrument(symbol), false
+	}
+	return i, true
+}
+
+// Get returns the instrument of a symbol, or the default instrument if not registered.
+func (r *InstrumentRegistry) Get(symbol string) Instrument {
+	i, _ := r.Lookup(symbol)
+	return i
+}
+
+// Symbols returns all registered symbols sorted by name.
+func (r *InstrumentRegistry) Symbols() []string {
+	if r == nil {
+		return nil
+	}
+
+	var symbols []string
+	for symbol := range r.instruments {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
@@ -0,0 +1,144 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInstrumentRoundPrice(t *testing.T) {
+	// testCases is a table for testing the rounding of a price to the tick size
+	var testCases = []struct {
+		msg        string
+		instrument Instrument
+		price      float64
+		expPrice   float64
+	}{
+		{"no tick size:", Instrument{}, 10.1234, 10.1234},
+		{"cent tick size:", Instrument{TickSize: 0.01}, 10.1234, 10.12},
+		{"quarter tick size:", Instrument{TickSize: 0.25}, 4500.4, 4500.5},
+		{"fx pip:", Instrument{TickSize: 0.00001}, 1.123456, 1.12346},
+	}
+
+	for _, tc := range testCases {
+		price := tc.instrument.RoundPrice(tc.price)
+		if price != tc.expPrice {
+			t.Errorf("%v RoundPrice(%v): \nexpected %v, \nactual   %v", tc.msg, tc.price, tc.expPrice, price)
+		}
+	}
+}
+
+func TestInstrumentNotional(t *testing.T) {
+	// testCases is a table for testing the notional value with a contract multiplier
+	var testCases = []struct {
+		msg        string
+		instrument Instrument
+		qty        float64
+		price      float64
+		expValue   float64
+	}{
+		{"without multiplier:", Instrument{}, 10, 15, 150},
+		{"future with multiplier:", Instrument{AssetClass: Future, Multiplier: 50}, 2, 4500, 450000},
+		{"short qty:", Instrument{Multiplier: 100}, -1, 2.5, 250},
+	}
+
+	for _, tc := range testCases {
+		value := tc.instrument.Notional(tc.qty, tc.price)
+		if value != tc.expValue {
+			t.Errorf("%v Notional(%v, %v): \nexpected %v, \nactual   %v", tc.msg, tc.qty, tc.price, tc.expValue, value)
+		}
+	}
+}
+
+func TestInstrumentRegistryLookup(t *testing.T) {
+	btc := Instrument{Symbol: "BTCUSD", AssetClass: Crypto, TickSize: 0.01, LotSize: LotSize{Step: 0.001}}
+	registry := NewInstrumentRegistry(btc)
+
+	// testCases is a table for testing the lookup of instruments
+	var testCases = []struct {
+		msg      string
+		registry *InstrumentRegistry
+		symbol   string
+		exp      Instrument
+		expOk    bool
+	}{
+		{"registered instrument:", registry, "BTCUSD", btc, true},
+		{"unknown symbol:", registry, "TEST.DE", DefaultInstrument("TEST.DE"), false},
+		{"nil registry:", nil, "TEST.DE", DefaultInstrument("TEST.DE"), false},
+	}
+
+	for _, tc := range testCases {
+		instrument, ok := tc.registry.Lookup(tc.symbol)
+		if !reflect.DeepEqual(instrument, tc.exp) || (ok != tc.expOk) {
+			t.Errorf("%v Lookup(%v): \nexpected %#v %v, \nactual   %#v %v", tc.msg, tc.symbol, tc.exp, tc.expOk, instrument, ok)
+		}
+	}
+
+	if symbols := registry.Symbols(); !reflect.DeepEqual(symbols, []string{"BTCUSD"}) {
+		t.Errorf("Symbols(): \nexpected %v, \nactual   %v", []string{"BTCUSD"}, symbols)
+	}
+}
+
+// roundTripStrategy buys on the first bar and exits on the last bar of the stream.
+type roundTripStrategy struct {
+	*Strategy
+	bars, exit int
+}
+
+func (s *roundTripStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.bars++
+	switch s.bars {
+	case 1:
+		return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}}, nil
+	case s.exit:
+		return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: EXT}}, nil
+	}
+	return nil, nil
+}
+
+func TestBacktestContractMultiplier(t *testing.T) {
+	future := Instrument{Symbol: "TEST.DE", AssetClass: Future, Multiplier: 50}
+	registry := NewInstrumentRegistry(future)
+
+	data := &Data{}
+	data.SetStream(newStressStream(100, 110, 120))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&roundTripStrategy{Strategy: NewStrategy("round trip"), exit: 3})
+	portfolio := test.portfolio.(*Portfolio)
+	portfolio.initialCash = 10000
+	portfolio.SetInstruments(registry)
+	portfolio.SetCostBasis(FIFO)
+	// the default value of 6000 buys a single contract of a notional of 5000
+	portfolio.sizeManager = &Size{DefaultSize: 100, DefaultValue: 6000, Instruments: registry}
+	test.exchange.(*Exchange).Instruments = registry
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	fills := test.Stats().Transactions()
+	if len(fills) != 2 || fills[0].Qty() != 1 || fills[0].Value() != 5000 || fills[1].Value() != 6000 {
+		t.Fatalf("Run(): expected 2 fills of 1 contract valued 5000 and 6000, actual %v", fills)
+	}
+
+	// the open contract is valued with the multiplier, 5000 cash and 110 * 50
+	var expEquity = []float64{10000, 10500, 11000}
+	curve := test.Stats().(*Statistic).EquityCurve()
+	if len(curve) != len(expEquity) {
+		t.Fatalf("EquityCurve(): expected %d points, actual %d", len(expEquity), len(curve))
+	}
+	for i, p := range curve {
+		if p.Equity != expEquity[i] {
+			t.Errorf("EquityCurve(): expected equity %v at %v, actual %v", expEquity[i], i, p.Equity)
+		}
+	}
+
+	if cash := portfolio.Cash(); cash != 11000 {
+		t.Errorf("Run(): expected cash 11000, actual %v", cash)
+	}
+	ledger, _ := portfolio.Ledger()
+	if pnl := ledger.RealizedProfitLoss("TEST.DE"); pnl != 1000 {
+		t.Errorf("RealizedProfitLoss(): expected 1000, actual %v", pnl)
+	}
+}
@@ -0,0 +1,54 @@
+package gobacktest
+
+import (
+	"math"
+	"time"
+)
+
+// InterestHandler is the basic interface for accruing interest on the cash and short balances.
+type InterestHandler interface {
+	Accrue(cash, shortValue, days float64) (credit, debit float64)
+}
+
+// Interest is an interest handler implementation with fixed annual rates,
+// e.g. 0.02 for 2% p.a. Interest accrues per calendar day.
+type Interest struct {
+	CreditRate  float64 // paid on positive cash
+	DebitRate   float64 // charged on negative cash, e.g. a margin loan
+	BorrowRate  float64 // charged on the market value of short positions
+	DaysPerYear float64 // day count convention, defaults to 360
+}
+
+// Accrue returns the interest credit and the financing debit for a number of days.
+func (i *Interest) Accrue(cash, shortValue, days float64) (credit, debit float64) {
+	if days <= 0 {
+		return 0, 0
+	}
+
+	year := i.DaysPerYear
+	if year <= 0 {
+		year = 360
+	}
+
+	if cash > 0 {
+		credit = cash * i.CreditRate * days / year
+	} else {
+		debit = -cash * i.DebitRate * days / year
+	}
+	debit += math.Abs(shortValue) * i.BorrowRate * days / year
+
+	credit = math.Round(credit*math.Pow10(DP)) / math.Pow10(DP)
+	debit = math.Round(debit*math.Pow10(DP)) / math.Pow10(DP)
+	return credit, debit
+}
+
+// accrualDays returns the number of calendar days between two points in time.
+func accrualDays(from, to time.Time) float64 {
+	if from.IsZero() || !to.After(from) {
+		return 0
+	}
+
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return math.Round(toDay.Sub(fromDay).Hours() / 24)
+}
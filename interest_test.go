@@ -0,0 +1,66 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInterestAccrue(t *testing.T) {
+	// testCases is a table for testing the accrual of interest
+	var testCases = []struct {
+		msg        string
+		interest   *Interest
+		cash       float64
+		shortValue float64
+		days       float64
+		expCredit  float64
+		expDebit   float64
+	}{
+		{"credit on positive cash:",
+			&Interest{CreditRate: 0.036}, 10000, 0, 1,
+			1, 0,
+		},
+		{"debit on negative cash with 365 day count:",
+			&Interest{DebitRate: 0.073, DaysPerYear: 365}, -10000, 0, 3,
+			0, 6,
+		},
+		{"borrow fee on short positions:",
+			&Interest{CreditRate: 0.036, BorrowRate: 0.072}, 10000, 5000, 1,
+			1, 1,
+		},
+		{"no accrual without days:",
+			&Interest{CreditRate: 0.036}, 10000, 0, 0,
+			0, 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		credit, debit := tc.interest.Accrue(tc.cash, tc.shortValue, tc.days)
+		if (credit != tc.expCredit) || (debit != tc.expDebit) {
+			t.Errorf("%v Accrue(%v, %v, %v): \nexpected %v %v, \nactual   %v %v",
+				tc.msg, tc.cash, tc.shortValue, tc.days, tc.expCredit, tc.expDebit, credit, debit)
+		}
+	}
+}
+
+func TestPortfolioAccrueInterest(t *testing.T) {
+	day1, _ := time.Parse("2006-01-02", "2017-06-01")
+	day2, _ := time.Parse("2006-01-02", "2017-06-02")
+	day5, _ := time.Parse("2006-01-02", "2017-06-05")
+
+	p := &Portfolio{cash: 36000}
+	p.SetInterest(&Interest{CreditRate: 0.01})
+
+	p.Update(&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}})
+	p.Update(&Bar{Event: Event{timestamp: day1.Add(8 * time.Hour), symbol: "TEST.DE"}})
+	p.Update(&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}})
+	p.Update(&Bar{Event: Event{timestamp: day5, symbol: "TEST.DE"}})
+
+	// one day from day1 to day2, three days from day2 to day5 on the compounded cash
+	cash := math.Round(p.Cash()*math.Pow10(DP)) / math.Pow10(DP)
+	if cash != 36004.0001 || p.InterestEarned() != 4.0001 {
+		t.Errorf("accrue(): \nexpected cash %v interest %v, \nactual   cash %v interest %v",
+			36004.0001, 4.0001, cash, p.InterestEarned())
+	}
+}
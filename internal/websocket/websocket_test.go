@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve starts a websocket server running fn on every connection.
+func serve(t *testing.T, fn func(c *Conn)) (*httptest.Server, string) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		fn(c)
+	}))
+	return ts, "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+func TestConn(t *testing.T) {
+	pong := make(chan string, 1)
+	ts, url := serve(t, func(c *Conn) {
+		// echo the first message in sizes of the different length encodings
+		message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		for _, n := range []int{1, 300, 70000} {
+			c.WriteMessage(bytes.Repeat(message, n))
+		}
+
+		// a fragmented message
+		c.conn.Write([]byte{opText, 3, 'h', 'e', 'l'})
+		c.conn.Write([]byte{0x80 | opContinuation, 2, 'l', 'o'})
+
+		// a ping is answered by the client while reading
+		c.OnPong = func(p []byte) { pong <- string(p) }
+		c.Ping([]byte("beat"))
+		c.ReadMessage()
+	})
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, url+"/stream?feed=iex", http.Header{"Authorization": {"token"}})
+	if err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	defer c.Close()
+
+	if err := c.WriteJSON(map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON(): unexpected error %v", err)
+	}
+	for _, n := range []int{1, 300, 70000} {
+		message, err := c.ReadMessage()
+		if err != nil || len(message) != 7*n || !bytes.HasPrefix(message, []byte(`{"a":1}`)) {
+			t.Fatalf("ReadMessage(): expected the echo of %v bytes, actual %v %v", 7*n, len(message), err)
+		}
+	}
+
+	var s string
+	message, err := c.ReadMessage()
+	if s = string(message); err != nil || s != "hello" {
+		t.Errorf("ReadMessage(): expected the fragmented message, actual %q %v", s, err)
+	}
+
+	// the ping is answered while waiting for the close of the server
+	go c.ReadMessage()
+	select {
+	case p := <-pong:
+		if p != "beat" {
+			t.Errorf("Ping(): expected the payload in the pong, actual %q", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Ping(): expected a pong of the client")
+	}
+	c.WriteMessage([]byte("done"))
+}
+
+func TestConnClose(t *testing.T) {
+	ts, url := serve(t, func(c *Conn) {})
+	defer ts.Close()
+
+	c, err := Dial(context.Background(), url, nil)
+	if err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	if _, err := c.ReadMessage(); err != ErrClosed {
+		t.Errorf("ReadMessage(): expected ErrClosed after the close of the server, actual %v", err)
+	}
+	if err := c.WriteMessage([]byte("late")); err != ErrClosed {
+		t.Errorf("WriteMessage(): expected ErrClosed on a closed connection, actual %v", err)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Upgrade(w, &http.Request{Method: http.MethodPost, Header: r.Header})
+	}))
+	defer ts.Close()
+
+	// testCases is a table for testing failed connections
+	var testCases = []struct {
+		msg    string
+		url    string
+		expErr string
+	}{
+		{"unsupported scheme:", "http://localhost", "unsupported scheme"},
+		{"rejected handshake:", "ws" + strings.TrimPrefix(ts.URL, "http"), "handshake failed with status 400"},
+	}
+
+	for _, tc := range testCases {
+		_, err := Dial(context.Background(), tc.url, nil)
+		if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%v Dial(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package gobacktest
+
+import (
+	"math/rand"
+	"time"
+)
+
+// LatencyModeler is the basic interface for simulating the latency
+// between sending an order and its arrival at the exchange.
+type LatencyModeler interface {
+	Latency() time.Duration
+}
+
+// FixedLatency delays every order by the same duration.
+type FixedLatency struct {
+	Delay time.Duration
+}
+
+// Latency returns the fixed delay.
+func (l *FixedLatency) Latency() time.Duration {
+	return l.Delay
+}
+
+// UniformLatency delays an order by a random duration between Min and Max.
+// If Rand is nil, the default source of the math/rand package is used.
+type UniformLatency struct {
+	Min  time.Duration
+	Max  time.Duration
+	Rand *rand.Rand
+}
+
+// Latency returns a uniform distributed delay.
+func (l *UniformLatency) Latency() time.Duration {
+	if l.Max <= l.Min {
+		return l.Min
+	}
+
+	return l.Min + time.Duration(float64(l.Max-l.Min)*randFloat64(l.Rand))
+}
+
+// NormalLatency delays an order by a normal distributed duration, never below zero.
+// If Rand is nil, the default source of the math/rand package is used.
+type NormalLatency struct {
+	Mean   time.Duration
+	StdDev time.Duration
+	Rand   *rand.Rand
+}
+
+// Latency returns a normal distributed delay.
+func (l *NormalLatency) Latency() time.Duration {
+	var norm float64
+	if l.Rand == nil {
+		norm = rand.NormFloat64()
+	} else {
+		norm = l.Rand.NormFloat64()
+	}
+
+	delay := l.Mean + time.Duration(norm*float64(l.StdDev))
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// SetRand implements RandSetter.
+func (l *UniformLatency) SetRand(r *rand.Rand) {
+	l.Rand = r
+}
+
+// SetRand implements RandSetter.
+func (l *NormalLatency) SetRand(r *rand.Rand) {
+	l.Rand = r
+}
+
+// randFloat64 returns a random float in [0.0,1.0) from the given or the default source.
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
@@ -0,0 +1,120 @@
+package gobacktest
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLatency(t *testing.T) {
+	var testCases = []struct {
+		msg      string
+		latency  LatencyModeler
+		min, max time.Duration
+	}{
+		{"fixed latency:",
+			&FixedLatency{Delay: time.Millisecond},
+			time.Millisecond, time.Millisecond,
+		},
+		{"uniform latency:",
+			&UniformLatency{Min: time.Millisecond, Max: 2 * time.Millisecond, Rand: rand.New(rand.NewSource(1))},
+			time.Millisecond, 2 * time.Millisecond,
+		},
+		{"normal latency is never negative:",
+			&NormalLatency{Mean: 0, StdDev: time.Millisecond, Rand: rand.New(rand.NewSource(1))},
+			0, time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		for i := 0; i < 100; i++ {
+			latency := tc.latency.Latency()
+			if latency < tc.min || latency > tc.max {
+				t.Errorf("%v Latency(): expected between %v and %v, actual %v", tc.msg, tc.min, tc.max, latency)
+			}
+		}
+	}
+}
+
+func TestExchangeLatency(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	var e = &Exchange{
+		Symbol:      "TEST",
+		Commission:  &FixedCommission{Commission: 0},
+		ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+		Latency:     &FixedLatency{Delay: time.Second},
+	}
+
+	order := &Order{
+		Event:     Event{timestamp: exampleTime, symbol: "TEST.DE"},
+		orderType: MarketOrder,
+		direction: BOT,
+		qty:       10,
+	}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Tick{Bid: 10, Ask: 10},
+		},
+	}
+
+	fill, err := e.OnOrder(order, data)
+	if fill != nil || err != nil {
+		t.Fatalf("OnOrder(): expected delayed order, actual %#v %v", fill, err)
+	}
+
+	fills, _ := e.OnData(&Tick{Event: Event{timestamp: exampleTime.Add(500 * time.Millisecond), symbol: "TEST.DE"}, Bid: 11, Ask: 11})
+	if len(fills) != 0 {
+		t.Errorf("OnData(): expected no fill before arrival, actual %#v", fills)
+	}
+
+	fills, _ = e.OnData(&Tick{Event: Event{timestamp: exampleTime.Add(time.Second), symbol: "TEST.DE"}, Bid: 12, Ask: 12})
+	if len(fills) != 1 || fills[0].Price() != 12 {
+		t.Errorf("OnData(): expected fill at arrival price 12, actual %#v", fills)
+	}
+}
+
+// scriptedStrategy sends the signal of the direction of each data event, none for HLD.
+type scriptedStrategy struct {
+	*Strategy
+	directions []Direction
+	i          int
+}
+
+func (s *scriptedStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	if s.i >= len(s.directions) {
+		return nil, nil
+	}
+	direction := s.directions[s.i]
+	s.i++
+	if direction == HLD {
+		return nil, nil
+	}
+	return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: direction}}, nil
+}
+
+func TestBacktestLatencyExit(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	exchange := NewExchange()
+	exchange.Latency = &FixedLatency{Delay: time.Hour}
+
+	test := New()
+	test.SetData(data)
+	test.SetExchange(exchange)
+	test.SetStrategy(&scriptedStrategy{Strategy: NewStrategy("scripted"), directions: []Direction{BOT, EXT}})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the entry rests until the second bar and fills before its exit signal is sized
+	fills := test.statistic.Transactions()
+	if len(fills) != 2 || fills[0].Direction() != BOT || fills[1].Direction() != SLD {
+		t.Fatalf("Run(): expected the entry and the exit, actual %v fills", len(fills))
+	}
+	if pos, ok := test.portfolio.(*Portfolio).Holdings()["TEST.DE"]; ok && pos.qty != 0 {
+		t.Errorf("Run(): expected a closed position, actual qty %v", pos.qty)
+	}
+}
@@ -0,0 +1,118 @@
+package gobacktest
+
+import (
+	"time"
+)
+
+// eventID returns the lineage id of an event, 0 if the event does not carry a lineage.
+func eventID(e interface{}) int {
+	if l, ok := e.(Lineager); ok {
+		return l.EventID()
+	}
+	return 0
+}
+
+// parentID returns the id of the parent of an event, 0 if the event does not carry a lineage.
+func parentID(e interface{}) int {
+	if l, ok := e.(Lineager); ok {
+		return l.ParentID()
+	}
+	return 0
+}
+
+// Sequencer receives a function of the engine, which assigns the next event id of the engine to an
+// order created outside of the event queue and tracks it as caused by its parent event, e.g. implemented
+// by an execution handler sending child orders of its own.
+type Sequencer interface {
+	SetSequence(func(order *Order, parent EventHandler))
+}
+
+// Lineage returns the chain of tracked events which caused the event,
+// starting with the event itself and ending with the root event, e.g. fill, order, signal, bar.
+func (s Statistic) Lineage(e EventHandler) []EventHandler {
+	return lineage(s.eventIndex(), e)
+}
+
+// lineage returns the chain of an event from an index of the tracked events.
+func lineage(index map[int]EventHandler, e EventHandler) []EventHandler {
+	chain := []EventHandler{e}
+	for id := parentID(e); id != 0; {
+		parent, ok := index[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, parent)
+		id = parentID(parent)
+	}
+
+	return chain
+}
+
+// eventIndex maps the tracked events to their lineage id.
+func (s Statistic) eventIndex() map[int]EventHandler {
+	index := make(map[int]EventHandler, len(s.eventHistory))
+	for _, e := range s.eventHistory {
+		if id := eventID(e); id != 0 {
+			index[id] = e
+		}
+	}
+	return index
+}
+
+// BlotterEntry is a line of the trade blotter, a transaction with the ids of the events which caused it.
+type BlotterEntry struct {
+	Time      time.Time
+	Symbol    string
+	Direction Direction
+	Qty       float64
+	Price     float64
+	Cost      float64
+	FillID    int
+	OrderID   int // lineage id of the order event, not the id of the order book
+	SignalID  int // 0 if the order was not caused by a signal
+	DataID    int // id of the data event which started the chain
+	Tags      map[string]string
+}
+
+// Blotter returns the trade blotter of all transactions with their event lineage.
+func (s Statistic) Blotter() []BlotterEntry {
+	var blotter []BlotterEntry
+
+	// the index is built once for all fills, not for the lineage of each fill
+	index := s.eventIndex()
+	for _, fill := range s.transactionHistory {
+		entry := BlotterEntry{
+			Time:      fill.Time(),
+			Symbol:    fill.Symbol(),
+			Direction: fill.Direction(),
+			Qty:       fill.Qty(),
+			Price:     fill.Price(),
+			Cost:      fill.Cost(),
+			FillID:    eventID(fill),
+		}
+		if t, ok := fill.(Tagger); ok {
+			entry.Tags = t.Tags()
+		}
+
+		for _, e := range lineage(index, fill)[1:] {
+			switch e.(type) {
+			case FillEvent:
+				// a fill is never the parent of a fill
+			case OrderEvent:
+				if entry.OrderID == 0 {
+					entry.OrderID = eventID(e)
+				}
+			case SignalEvent:
+				if entry.SignalID == 0 {
+					entry.SignalID = eventID(e)
+				}
+			case DataEvent:
+				entry.DataID = eventID(e)
+			}
+		}
+
+		blotter = append(blotter, entry)
+	}
+
+	return blotter
+}
@@ -0,0 +1,85 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineage(t *testing.T) {
+	bar := &Bar{Event: Event{eventID: 1}}
+	signal := &Signal{Event: Event{eventID: 2, parentID: 1}}
+	order := &Order{Event: Event{eventID: 3, parentID: 2}}
+	fill := &Fill{Event: Event{eventID: 4, parentID: 3}}
+	orphan := &Fill{Event: Event{eventID: 5, parentID: 9}}
+
+	// testCases is a table for testing the lineage of an event
+	var testCases = []struct {
+		msg   string
+		event EventHandler
+		exp   []EventHandler
+	}{
+		{"fill traced to the bar:", fill, []EventHandler{fill, order, signal, bar}},
+		{"root event:", bar, []EventHandler{bar}},
+		{"unknown parent:", orphan, []EventHandler{orphan}},
+	}
+
+	s := Statistic{eventHistory: []EventHandler{bar, signal, order, fill}}
+	for _, tc := range testCases {
+		chain := s.Lineage(tc.event)
+		if !reflect.DeepEqual(chain, tc.exp) {
+			t.Errorf("%v Lineage(): \nexpected %#v, \nactual   %#v", tc.msg, tc.exp, chain)
+		}
+	}
+}
+
+func TestBacktestBlotter(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	blotter := test.statistic.(*Statistic).Blotter()
+	if len(blotter) != 2 {
+		t.Fatalf("Blotter(): expected 2 entries, actual %v", len(blotter))
+	}
+
+	seen := map[int]bool{}
+	for _, entry := range blotter {
+		if entry.FillID == 0 || entry.OrderID == 0 || entry.SignalID == 0 || entry.DataID == 0 {
+			t.Errorf("Blotter(): expected a complete lineage, actual %+v", entry)
+		}
+		for _, id := range []int{entry.FillID, entry.OrderID, entry.SignalID, entry.DataID} {
+			if seen[id] {
+				t.Errorf("Blotter(): expected unique event ids, actual %+v", entry)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func BenchmarkStatisticBlotter(b *testing.B) {
+	s := &Statistic{}
+	for i := 0; i < 1000; i++ {
+		id := 4 * i
+		fill := &Fill{Event: Event{eventID: id + 4, parentID: id + 3}}
+		s.eventHistory = append(s.eventHistory,
+			&Bar{Event: Event{eventID: id + 1}},
+			&Signal{Event: Event{eventID: id + 2, parentID: id + 1}},
+			&Order{Event: Event{eventID: id + 3, parentID: id + 2}},
+			fill,
+		)
+		s.transactionHistory = append(s.transactionHistory, fill)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Blotter()
+	}
+}
@@ -0,0 +1,154 @@
+package gobacktest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ContextStreamer is a data streamer which waits for the next data event,
+// until the data is exhausted or the context is cancelled.
+// The backtest polls a data handler implementing it with the context of the run.
+type ContextStreamer interface {
+	NextContext(context.Context) (DataEvent, bool)
+}
+
+// LiveData is a data handler fed with data events from a live source, e.g. a quote feed.
+// Publish hands a data event to the running backtest, Close ends the data stream.
+// The engine clock follows the wall clock, a data event without a timestamp is stamped on Publish.
+// If MaxAge is set, data events older than MaxAge on arrival are dropped.
+type LiveData struct {
+	Data
+	MaxAge time.Duration
+
+	events  chan DataEvent
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	dropped int
+	now     func() time.Time
+}
+
+// NewLiveData creates a live data handler, which buffers up to n published data events.
+func NewLiveData(n int) *LiveData {
+	return &LiveData{
+		events: make(chan DataEvent, n),
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Publish hands a data event to the backtest. It blocks while the buffer is full
+// and returns false, if the data stream is closed.
+func (d *LiveData) Publish(e DataEvent) bool {
+	if e.Time().IsZero() {
+		e.SetTime(d.now())
+	}
+
+	// check for a closed stream first, as select chooses randomly
+	select {
+	case <-d.done:
+		return false
+	default:
+	}
+
+	select {
+	case d.events <- e:
+		return true
+	case <-d.done:
+		return false
+	}
+}
+
+// Close ends the data stream, the backtest processes the buffered data events and finishes.
+func (d *LiveData) Close() {
+	d.once.Do(func() { close(d.done) })
+}
+
+// Done returns a channel, which is closed when the data stream is closed.
+func (d *LiveData) Done() <-chan struct{} {
+	return d.done
+}
+
+// Dropped returns the number of data events dropped for exceeding MaxAge.
+func (d *LiveData) Dropped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dropped
+}
+
+// Next implements DataStreamer, it waits for the next data event without a deadline.
+func (d *LiveData) Next() (DataEvent, bool) {
+	return d.NextContext(context.Background())
+}
+
+// NextContext implements ContextStreamer. It waits for the next published data event
+// and returns false, if the data stream is closed and drained or the context is cancelled.
+func (d *LiveData) NextContext(ctx context.Context) (DataEvent, bool) {
+	for {
+		var e DataEvent
+		select {
+		case e = <-d.events:
+		case <-ctx.Done():
+			return nil, false
+		case <-d.done:
+			// drain the buffered data events after close
+			select {
+			case e = <-d.events:
+			default:
+				return nil, false
+			}
+		}
+
+		if d.stale(e) {
+			continue
+		}
+
+		d.SetStream(append(d.Stream(), e))
+		return d.Data.Next()
+	}
+}
+
+// Reset implements Reseter. A live data stream can not be replayed,
+// the history is discarded.
+func (d *LiveData) Reset() error {
+	if err := d.Data.Reset(); err != nil {
+		return err
+	}
+	d.SetStream(nil)
+	return nil
+}
+
+// stale checks a data event against MaxAge and counts the dropped data events.
+func (d *LiveData) stale(e DataEvent) bool {
+	if d.MaxAge <= 0 || d.now().Sub(e.Time()) <= d.MaxAge {
+		return false
+	}
+
+	d.mu.Lock()
+	d.dropped++
+	d.mu.Unlock()
+
+	logger(d.log).Warn("stale data event dropped", "symbol", e.Symbol(), "time", e.Time())
+	return true
+}
+
+// NewPaperTrading creates a backtest trading on a live data feed. The orders are filled
+// against the live quotes: market orders on the latest data event, limit and stop orders
+// rest in the order book and are filled by a TouchFillModel. Commission, slippage and
+// latency are set on the exchange as in a backtest, e.g.
+//
+//	feed := gbt.NewLiveData(100)
+//	test := gbt.NewPaperTrading(feed)
+//	test.SetStrategy(strategy)
+//	go quotes(feed) // calls feed.Publish for every quote
+//	err := test.RunContext(ctx)
+func NewPaperTrading(feed *LiveData) *Backtest {
+	exchange := NewExchange()
+	exchange.FillModel = &TouchFillModel{}
+
+	test := New()
+	test.SetData(feed)
+	test.SetExchange(exchange)
+	return test
+}
@@ -0,0 +1,102 @@
+package gobacktest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newQuote creates a tick of the live test data without a timestamp.
+func newQuote(bid, ask float64) *Tick {
+	return &Tick{Event: Event{symbol: "TEST.DE"}, Bid: bid, Ask: ask, BidVolume: 1000, AskVolume: 1000}
+}
+
+func TestPaperTrading(t *testing.T) {
+	feed := NewLiveData(1)
+	test := NewPaperTrading(feed)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	go func() {
+		for _, quote := range []*Tick{newQuote(99, 101), newQuote(101, 103), newQuote(103, 105)} {
+			feed.Publish(quote)
+		}
+		feed.Close()
+	}()
+
+	if err := test.RunContext(context.Background()); err != nil {
+		t.Fatalf("RunContext(): unexpected error %v", err)
+	}
+
+	fills := test.portfolio.(*Portfolio).transactions
+	if len(fills) != 3 {
+		t.Fatalf("RunContext(): expected a fill on every quote, actual %v fills", len(fills))
+	}
+	for i, f := range fills {
+		if exp := 100 + 2*float64(i); f.Price() != exp || f.Time().IsZero() {
+			t.Errorf("RunContext(): expected fill %v at %v on the wall clock, actual %v at %v", i, exp, f.Price(), f.Time())
+		}
+	}
+	if feed.Publish(newQuote(99, 101)) {
+		t.Errorf("Publish(): expected a closed feed to refuse data events")
+	}
+}
+
+func TestPaperTradingCancel(t *testing.T) {
+	feed := NewLiveData(0)
+	test := NewPaperTrading(feed)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := test.RunContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("RunContext(): expected the wait for data to end with the context, actual %v", err)
+	}
+}
+
+func TestLiveDataMaxAge(t *testing.T) {
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	feed := NewLiveData(3)
+	feed.MaxAge = time.Minute
+	feed.now = func() time.Time { return now }
+
+	// testCases is a table for testing the age of published data events
+	var testCases = []struct {
+		msg string
+		age time.Duration
+	}{
+		{"fresh quote:", 0},
+		{"stale quote:", 2 * time.Minute},
+		{"quote at max age:", time.Minute},
+	}
+
+	for _, tc := range testCases {
+		quote := newQuote(99, 101)
+		quote.SetTime(now.Add(-tc.age))
+		if !feed.Publish(quote) {
+			t.Errorf("%v Publish(): expected the quote to be accepted", tc.msg)
+		}
+	}
+	feed.Close()
+	select {
+	case <-feed.Done():
+	default:
+		t.Errorf("Done(): expected a closed channel after Close")
+	}
+
+	var events int
+	for _, ok := feed.Next(); ok; _, ok = feed.Next() {
+		events++
+	}
+	if events != 2 || feed.Dropped() != 1 {
+		t.Errorf("Next(): expected 2 events and 1 dropped, actual %v and %v", events, feed.Dropped())
+	}
+	if len(feed.History()) != 2 {
+		t.Errorf("History(): expected the delivered events, actual %v", len(feed.History()))
+	}
+
+	feed.Reset()
+	if len(feed.Stream()) != 0 || len(feed.History()) != 0 {
+		t.Errorf("Reset(): expected an empty live data stream, actual %v and %v", len(feed.Stream()), len(feed.History()))
+	}
+}
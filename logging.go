@@ -0,0 +1,206 @@
+package gobacktest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of a log record. The values match the levels of log/slog.
+type Level int
+
+// different log levels
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	}
+	return "ERROR"
+}
+
+// Logger is a structured logger with alternating key-value pairs as arguments.
+// The method set matches *slog.Logger, which can be used directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// LogSetter is implemented by a component which logs its decisions.
+type LogSetter interface {
+	SetLogger(Logger)
+}
+
+// nopLogger discards all log records, it is the default of every component.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// logger returns the logger or a logger discarding all records, if none is set.
+func logger(l Logger) Logger {
+	if l == nil {
+		return nopLogger{}
+	}
+	return l
+}
+
+// TextLogger writes log records as lines of key=value pairs.
+type TextLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+// NewTextLogger creates a logger writing all records of at least the level to w.
+func NewTextLogger(w io.Writer, level Level) *TextLogger {
+	return &TextLogger{w: w, level: level}
+}
+
+// Debug logs a record at debug level.
+func (l *TextLogger) Debug(msg string, args ...interface{}) { l.log(LevelDebug, msg, args) }
+
+// Info logs a record at info level.
+func (l *TextLogger) Info(msg string, args ...interface{}) { l.log(LevelInfo, msg, args) }
+
+// Warn logs a record at warn level.
+func (l *TextLogger) Warn(msg string, args ...interface{}) { l.log(LevelWarn, msg, args) }
+
+// Error logs a record at error level.
+func (l *TextLogger) Error(msg string, args ...interface{}) { l.log(LevelError, msg, args) }
+
+// log writes a single record.
+func (l *TextLogger) log(level Level, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "level=%s msg=%q", level, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, " !BADKEY=%v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%v", args[i], formatLogValue(args[i+1]))
+	}
+	b.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, b.String())
+}
+
+// formatLogValue quotes string values containing spaces.
+func formatLogValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if strings.ContainsAny(s, " =\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// Logging configures the logging of the engine components with a level per component,
+// e.g. debug for "execution" to see why an order was or was not filled.
+// The components are "engine", "data", "portfolio" and "execution".
+type Logging struct {
+	Logger Logger
+	Level  Level            // the default level of all components
+	Levels map[string]Level // the levels of single components
+}
+
+// For returns the logger of a component, which adds the component to every record.
+func (l Logging) For(component string) Logger {
+	if l.Logger == nil {
+		return nopLogger{}
+	}
+
+	level, ok := l.Levels[component]
+	if !ok {
+		level = l.Level
+	}
+	return &componentLogger{logger: l.Logger, level: level, component: component}
+}
+
+// componentLogger filters the records of a component by level.
+type componentLogger struct {
+	logger    Logger
+	level     Level
+	component string
+}
+
+func (c *componentLogger) Debug(msg string, args ...interface{}) {
+	if c.level <= LevelDebug {
+		c.logger.Debug(msg, c.args(args)...)
+	}
+}
+
+func (c *componentLogger) Info(msg string, args ...interface{}) {
+	if c.level <= LevelInfo {
+		c.logger.Info(msg, c.args(args)...)
+	}
+}
+
+func (c *componentLogger) Warn(msg string, args ...interface{}) {
+	if c.level <= LevelWarn {
+		c.logger.Warn(msg, c.args(args)...)
+	}
+}
+
+func (c *componentLogger) Error(msg string, args ...interface{}) {
+	if c.level <= LevelError {
+		c.logger.Error(msg, c.args(args)...)
+	}
+}
+
+// args prepends the component to the arguments of a record.
+func (c *componentLogger) args(args []interface{}) []interface{} {
+	return append([]interface{}{"component", c.component}, args...)
+}
+
+// SetLogging sets the logging of the backtest. The logger of each component is handed
+// to the data handler, portfolio and execution handler, if they implement LogSetter.
+func (t *Backtest) SetLogging(l Logging) {
+	t.logging = l
+	t.log = nil
+	if l.Logger != nil {
+		t.log = l.For("engine")
+	}
+}
+
+// setLoggers hands the component loggers to the components of the backtest.
+func (t *Backtest) setLoggers() {
+	if t.logging.Logger == nil {
+		return
+	}
+
+	components := []struct {
+		name      string
+		component interface{}
+	}{
+		{"data", t.data},
+		{"portfolio", t.portfolio},
+		{"execution", t.exchange},
+	}
+	for _, c := range components {
+		if setter, ok := c.component.(LogSetter); ok {
+			setter.SetLogger(t.logging.For(c.name))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package gobacktest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTextLogger(t *testing.T) {
+	// testCases is a table for testing the log records of the text logger
+	var testCases = []struct {
+		msg   string
+		level Level
+		log   func(Logger)
+		exp   string
+	}{
+		{"info record:", LevelInfo,
+			func(l Logger) { l.Info("order filled", "symbol", "TEST", "qty", 10.0) },
+			"level=INFO msg=\"order filled\" symbol=TEST qty=10\n"},
+		{"quoted value:", LevelDebug,
+			func(l Logger) { l.Debug("order rejected", "reason", "not enough cash") },
+			"level=DEBUG msg=\"order rejected\" reason=\"not enough cash\"\n"},
+		{"odd arguments:", LevelInfo,
+			func(l Logger) { l.Warn("odd", "key") },
+			"level=WARN msg=\"odd\" !BADKEY=key\n"},
+		{"below level:", LevelWarn,
+			func(l Logger) { l.Info("hidden") },
+			""},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		tc.log(NewTextLogger(&buf, tc.level))
+		if buf.String() != tc.exp {
+			t.Errorf("%v expected %q, actual %q", tc.msg, tc.exp, buf.String())
+		}
+	}
+}
+
+func TestLoggingFor(t *testing.T) {
+	var buf bytes.Buffer
+	logging := Logging{
+		Logger: NewTextLogger(&buf, LevelDebug),
+		Level:  LevelWarn,
+		Levels: map[string]Level{"execution": LevelDebug},
+	}
+
+	logging.For("execution").Debug("order resting")
+	logging.For("portfolio").Info("order created")
+	logging.For("portfolio").Error("failed")
+
+	exp := "level=DEBUG msg=\"order resting\" component=execution\n" +
+		"level=ERROR msg=\"failed\" component=portfolio\n"
+	if buf.String() != exp {
+		t.Errorf("For(): expected %q, actual %q", exp, buf.String())
+	}
+
+	// no logger discards all records
+	Logging{}.For("data").Error("discarded")
+}
+
+func TestBacktestLogging(t *testing.T) {
+	// testCases is a table for testing the logging of the engine components
+	var testCases = []struct {
+		msg      string
+		cash     float64
+		expLines []string
+		expNot   []string
+	}{
+		{"filled orders:", 100000,
+			[]string{"msg=\"order filled\" component=execution", "msg=\"data event\" component=data"},
+			[]string{"component=portfolio", "component=engine"}},
+		{"rejected orders:", 10,
+			[]string{"msg=\"signal rejected\" component=engine"},
+			[]string{"order filled", "component=portfolio"}},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100))
+
+		var buf bytes.Buffer
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		test.portfolio.(*Portfolio).initialCash = tc.cash
+		test.SetLogging(Logging{
+			Logger: NewTextLogger(&buf, LevelDebug),
+			Level:  LevelInfo,
+			Levels: map[string]Level{"data": LevelDebug, "portfolio": LevelError},
+		})
+
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+		}
+
+		out := buf.String()
+		for _, line := range tc.expLines {
+			if !strings.Contains(out, line) {
+				t.Errorf("%v expected log to contain %q, actual %q", tc.msg, line, out)
+			}
+		}
+		for _, line := range tc.expNot {
+			if strings.Contains(out, line) {
+				t.Errorf("%v expected log not to contain %q, actual %q", tc.msg, line, out)
+			}
+		}
+	}
+}
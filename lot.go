@@ -0,0 +1,304 @@
+package gobacktest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CostBasisMethod defines how closing fills are matched against open lots.
+type CostBasisMethod int
+
+// different cost basis methods
+const (
+	AverageCost CostBasisMethod = iota // 0
+	FIFO
+	LIFO
+)
+
+// String returns the name of the cost basis method.
+func (m CostBasisMethod) String() string {
+	switch m {
+	case FIFO:
+		return "FIFO"
+	case LIFO:
+		return "LIFO"
+	}
+	return "AverageCost"
+}
+
+// Lot represents an open tax lot, a positive qty is long, a negative qty is short.
+type Lot struct {
+	Symbol     string
+	Time       time.Time
+	Qty        float64
+	Price      float64 // price per share including cost
+	Multiplier float64 // contract multiplier, 0 means 1
+}
+
+// contractMultiplier returns the contract multiplier of a lot, defaults to 1.
+func (l Lot) contractMultiplier() float64 {
+	if l.Multiplier <= 0 {
+		return 1
+	}
+	return l.Multiplier
+}
+
+// ClosedLot represents a closed part of a tax lot.
+type ClosedLot struct {
+	Symbol     string
+	Opened     time.Time
+	Closed     time.Time
+	Qty        float64 // positive for a closed long lot, negative for a closed short lot
+	CostBasis  float64
+	Proceeds   float64
+	ProfitLoss float64
+}
+
+// LotLedger tracks open and closed tax lots of all symbols
+// and calculates the realized and unrealized profit/loss with a cost basis method.
+type LotLedger struct {
+	method CostBasisMethod
+	open   map[string][]Lot
+	closed []ClosedLot
+	prices map[string]float64
+}
+
+// NewLotLedger creates a lot ledger with the given cost basis method.
+func NewLotLedger(method CostBasisMethod) *LotLedger {
+	return &LotLedger{method: method}
+}
+
+// Method returns the cost basis method of the ledger.
+func (l *LotLedger) Method() CostBasisMethod {
+	return l.method
+}
+
+// Reset implements Reseter to remove all lots.
+func (l *LotLedger) Reset() error {
+	l.open = nil
+	l.closed = nil
+	l.prices = nil
+	return nil
+}
+
+// OnFill closes open lots of the opposite direction and opens a new lot with the remaining qty.
+func (l *LotLedger) OnFill(fill FillEvent) {
+	// check for nil map, else initialise the map
+	if l.open == nil {
+		l.open = make(map[string][]Lot)
+	}
+
+	symbol := fill.Symbol()
+	qty := fill.Qty()
+	if qty == 0 {
+		return
+	}
+
+	// price per share including the cost of the fill
+	m := contractMultiplier(fill)
+	price := fill.NetValue() / (qty * m)
+	sign := float64(1)
+	if fill.Direction() == SLD {
+		sign = -1
+	}
+
+	lots := l.open[symbol]
+	avg := averageLotPrice(lots)
+
+	// with average cost all lots closed against share the same average price
+	if l.method == AverageCost && len(lots) > 0 && lots[0].Qty*sign < 0 {
+		for i := range lots {
+			lots[i].Price = avg
+		}
+	}
+
+	for qty > 0 && len(lots) > 0 && lots[0].Qty*sign < 0 {
+		// select the lot to close, LIFO closes the latest lot first
+		i := 0
+		if l.method == LIFO {
+			i = len(lots) - 1
+		}
+		lot := lots[i]
+
+		closeQty := absQty(lot.Qty)
+		if qty < closeQty {
+			closeQty = qty
+		}
+
+		lotPrice := lot.Price
+
+		closed := ClosedLot{
+			Symbol: symbol,
+			Opened: lot.Time,
+			Closed: fill.Time(),
+		}
+		if lot.Qty > 0 {
+			closed.Qty = closeQty
+			closed.CostBasis = lotPrice * closeQty * m
+			closed.Proceeds = price * closeQty * m
+		} else {
+			closed.Qty = -closeQty
+			closed.CostBasis = price * closeQty * m
+			closed.Proceeds = lotPrice * closeQty * m
+		}
+		closed.ProfitLoss = roundDP(closed.Proceeds - closed.CostBasis)
+		closed.CostBasis = roundDP(closed.CostBasis)
+		closed.Proceeds = roundDP(closed.Proceeds)
+		l.closed = append(l.closed, closed)
+
+		// reduce or remove the closed lot
+		qty = roundQty(qty - closeQty)
+		if rest := roundQty(lot.Qty + sign*closeQty); rest == 0 {
+			lots = append(lots[:i], lots[i+1:]...)
+		} else {
+			lots[i].Qty = rest
+		}
+	}
+
+	// open a new lot with the remaining qty
+	if qty > 0 {
+		lot := Lot{Symbol: symbol, Time: fill.Time(), Qty: sign * qty, Price: price}
+		if m != 1 {
+			lot.Multiplier = m
+		}
+		lots = append(lots, lot)
+	}
+
+	l.open[symbol] = lots
+	l.Mark(symbol, fill.Price())
+}
+
+// Mark sets the last known market price of a symbol for the unrealized profit/loss.
+func (l *LotLedger) Mark(symbol string, price float64) {
+	// check for nil map, else initialise the map
+	if l.prices == nil {
+		l.prices = make(map[string]float64)
+	}
+	l.prices[symbol] = price
+}
+
+// OpenLots returns the open lots of a symbol.
+func (l *LotLedger) OpenLots(symbol string) []Lot {
+	return l.open[symbol]
+}
+
+// ClosedLots returns all closed lots.
+func (l *LotLedger) ClosedLots() []ClosedLot {
+	return l.closed
+}
+
+// RealizedProfitLoss returns the realized profit/loss of a symbol, or of all symbols if empty.
+func (l *LotLedger) RealizedProfitLoss(symbol string) float64 {
+	var pnl float64
+	for _, closed := range l.closed {
+		if symbol == "" || closed.Symbol == symbol {
+			pnl += closed.ProfitLoss
+		}
+	}
+	return roundDP(pnl)
+}
+
+// UnrealizedProfitLoss returns the unrealized profit/loss of a symbol, or of all symbols if empty.
+func (l *LotLedger) UnrealizedProfitLoss(symbol string) float64 {
+	var pnl float64
+	for s, lots := range l.open {
+		if symbol != "" && s != symbol {
+			continue
+		}
+
+		price := l.prices[s]
+		for _, lot := range lots {
+			pnl += (price - lot.Price) * lot.Qty * lot.contractMultiplier()
+		}
+	}
+	return roundDP(pnl)
+}
+
+// PrintResult prints the realized and unrealized profit/loss of each symbol to the screen.
+func (l *LotLedger) PrintResult() {
+	symbols := l.symbols()
+
+	fmt.Printf("Profit/loss by %v:\n", l.method)
+	for _, symbol := range symbols {
+		fmt.Printf("%s Realized: %f Unrealized: %f\n", symbol, l.RealizedProfitLoss(symbol), l.UnrealizedProfitLoss(symbol))
+	}
+	fmt.Printf("Total Realized: %f Unrealized: %f\n", l.RealizedProfitLoss(""), l.UnrealizedProfitLoss(""))
+}
+
+// WriteCSV exports all closed lots as csv, e.g. for tax reporting.
+func (l *LotLedger) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Symbol", "Opened", "Closed", "Qty", "CostBasis", "Proceeds", "ProfitLoss"})
+	if err != nil {
+		return err
+	}
+
+	for _, closed := range l.closed {
+		err := writer.Write([]string{
+			closed.Symbol,
+			closed.Opened.Format("2006-01-02"),
+			closed.Closed.Format("2006-01-02"),
+			strconv.FormatFloat(closed.Qty, 'f', -1, 64),
+			strconv.FormatFloat(closed.CostBasis, 'f', DP, 64),
+			strconv.FormatFloat(closed.Proceeds, 'f', DP, 64),
+			strconv.FormatFloat(closed.ProfitLoss, 'f', DP, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// symbols returns all symbols of the ledger sorted by name.
+func (l *LotLedger) symbols() []string {
+	set := make(map[string]bool)
+	for symbol := range l.open {
+		set[symbol] = true
+	}
+	for _, closed := range l.closed {
+		set[closed.Symbol] = true
+	}
+
+	var symbols []string
+	for symbol := range set {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+// averageLotPrice returns the qty weighted average price of lots.
+func averageLotPrice(lots []Lot) float64 {
+	var qty, value float64
+	for _, lot := range lots {
+		qty += math.Abs(lot.Qty)
+		value += math.Abs(lot.Qty) * lot.Price
+	}
+	if qty == 0 {
+		return 0
+	}
+	return value / qty
+}
+
+// absQty returns the absolute value of a qty.
+func absQty(i float64) float64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// roundDP rounds a float to the precision DP.
+func roundDP(f float64) float64 {
+	return math.Round(f*math.Pow10(DP)) / math.Pow10(DP)
+}
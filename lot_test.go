@@ -0,0 +1,128 @@
+package gobacktest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLotLedgerOnFill(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01")
+	var day2 = day1.Add(24 * time.Hour)
+	var day3 = day2.Add(24 * time.Hour)
+
+	var fills = []FillEvent{
+		&Fill{Event: Event{timestamp: day1, symbol: "TEST.DE"}, direction: BOT, qty: 10, price: 10},
+		&Fill{Event: Event{timestamp: day2, symbol: "TEST.DE"}, direction: BOT, qty: 10, price: 20},
+		&Fill{Event: Event{timestamp: day3, symbol: "TEST.DE"}, direction: SLD, qty: 15, price: 30},
+	}
+
+	var testCases = []struct {
+		msg           string
+		method        CostBasisMethod
+		expRealized   float64
+		expUnrealized float64
+		expOpen       []Lot
+	}{
+		{"fifo closes the first lot first:",
+			FIFO,
+			// 10 * (30 - 10) + 5 * (30 - 20)
+			250,
+			// 5 * (30 - 20)
+			50,
+			[]Lot{{Symbol: "TEST.DE", Time: day2, Qty: 5, Price: 20}},
+		},
+		{"lifo closes the latest lot first:",
+			LIFO,
+			// 10 * (30 - 20) + 5 * (30 - 10)
+			200,
+			// 5 * (30 - 10)
+			100,
+			[]Lot{{Symbol: "TEST.DE", Time: day1, Qty: 5, Price: 10}},
+		},
+		{"average cost:",
+			AverageCost,
+			// 15 * (30 - 15)
+			225,
+			// 5 * (30 - 15)
+			75,
+			[]Lot{{Symbol: "TEST.DE", Time: day2, Qty: 5, Price: 15}},
+		},
+	}
+
+	for _, tc := range testCases {
+		ledger := NewLotLedger(tc.method)
+		for _, fill := range fills {
+			ledger.OnFill(fill)
+		}
+
+		realized := ledger.RealizedProfitLoss("TEST.DE")
+		unrealized := ledger.UnrealizedProfitLoss("")
+		if realized != tc.expRealized || unrealized != tc.expUnrealized {
+			t.Errorf("%v profit/loss: \nexpected %v %v, \nactual   %v %v",
+				tc.msg, tc.expRealized, tc.expUnrealized, realized, unrealized)
+		}
+
+		open := ledger.OpenLots("TEST.DE")
+		if len(open) != 1 || open[0] != tc.expOpen[0] {
+			t.Errorf("%v OpenLots(): \nexpected %#v, \nactual   %#v", tc.msg, tc.expOpen, open)
+		}
+	}
+}
+
+func TestLotLedgerShort(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01")
+
+	ledger := NewLotLedger(FIFO)
+	ledger.OnFill(&Fill{Event: Event{timestamp: day1, symbol: "TEST.DE"}, direction: SLD, qty: 10, price: 20})
+	ledger.OnFill(&Fill{Event: Event{timestamp: day1, symbol: "TEST.DE"}, direction: BOT, qty: 15, price: 15})
+
+	closed := ledger.ClosedLots()
+	if len(closed) != 1 || closed[0].Qty != -10 || closed[0].ProfitLoss != 50 {
+		t.Errorf("ClosedLots(): unexpected closed lots %#v", closed)
+	}
+
+	open := ledger.OpenLots("TEST.DE")
+	if len(open) != 1 || open[0].Qty != 5 {
+		t.Errorf("OpenLots(): unexpected open lots %#v", open)
+	}
+
+	var buf bytes.Buffer
+	if err := ledger.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Symbol,Opened,Closed,Qty,CostBasis,Proceeds,ProfitLoss\n" +
+		"TEST.DE,2018-06-01,2018-06-01,-10,150.0000,200.0000,50.0000\n"
+	if buf.String() != exp {
+		t.Errorf("WriteCSV(): \nexpected %q, \nactual   %q", exp, buf.String())
+	}
+}
+
+func TestStatisticLedger(t *testing.T) {
+	bar := &Bar{Event: Event{symbol: "TEST.DE"}, Close: 10}
+
+	// testCases is a table for testing the lot ledger of the trade report
+	var testCases = []struct {
+		msg       string
+		method    CostBasisMethod
+		costBasis bool
+	}{
+		{"portfolio with FIFO:", FIFO, true},
+		{"portfolio with LIFO:", LIFO, true},
+		{"portfolio without cost basis:", AverageCost, false},
+	}
+
+	for _, tc := range testCases {
+		p := &Portfolio{cash: 1000}
+		if tc.costBasis {
+			p.SetCostBasis(tc.method)
+		}
+		s := &Statistic{}
+		s.Update(bar, p)
+
+		ledger, ok := p.Ledger()
+		if (s.ledger != nil) != ok || (ok && (s.ledger != ledger || s.ledger.Method() != tc.method)) {
+			t.Errorf("%v Update(): \nexpected ledger %v of %v, \nactual   %#v", tc.msg, ok, tc.method, s.ledger)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gobacktest
+
+import (
+	"math"
+)
+
+// LotSize defines the tradable quantities of an instrument, e.g. Step 0.001 for crypto
+// or Step 1 for whole shares. A zero Step means whole units.
+type LotSize struct {
+	Step float64 // smallest tradable increment
+	Min  float64 // minimum qty of an order
+}
+
+// Round rounds a qty down to the lot size step, returns 0 if the qty is below the minimum.
+func (l LotSize) Round(qty float64) float64 {
+	step := l.step()
+
+	// round to DP first to avoid floating point artefacts like 2.9999999
+	rounded := math.Floor(math.Round(math.Abs(qty)/step*math.Pow10(DP))/math.Pow10(DP)) * step
+	rounded = math.Round(rounded*math.Pow10(DP+4)) / math.Pow10(DP+4)
+
+	if rounded < l.Min {
+		return 0
+	}
+	if qty < 0 {
+		return -rounded
+	}
+	return rounded
+}
+
+// Valid checks if a qty is a multiple of the step and not below the minimum.
+func (l LotSize) Valid(qty float64) bool {
+	if qty == 0 {
+		return false
+	}
+	return l.Round(qty) == qty
+}
+
+// roundQty rounds a qty to the precision of the lot sizes, so the sum of fractional fills,
+// e.g. 0.1 + 0.2 - 0.3, does not leave a residue of floating point artefacts.
+func roundQty(qty float64) float64 {
+	return math.Round(qty*math.Pow10(DP+4)) / math.Pow10(DP+4)
+}
+
+// step returns the step of the lot size, defaults to whole units.
+func (l LotSize) step() float64 {
+	if l.Step <= 0 {
+		return 1
+	}
+	return l.Step
+}
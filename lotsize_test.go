@@ -0,0 +1,51 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestLotSizeRound(t *testing.T) {
+	// testCases is a table for testing the rounding of a qty to the lot size
+	var testCases = []struct {
+		msg    string
+		lot    LotSize
+		qty    float64
+		expQty float64
+	}{
+		{"whole units without step:", LotSize{}, 10.7, 10},
+		{"crypto step:", LotSize{Step: 0.001}, 0.12345, 0.123},
+		{"step without float artefact:", LotSize{Step: 0.1}, 0.3, 0.3},
+		{"round lots:", LotSize{Step: 100}, 250, 200},
+		{"below min qty:", LotSize{Step: 0.01, Min: 1}, 0.5, 0},
+		{"negative qty:", LotSize{Step: 0.5}, -1.7, -1.5},
+	}
+
+	for _, tc := range testCases {
+		qty := tc.lot.Round(tc.qty)
+		if qty != tc.expQty {
+			t.Errorf("%v Round(%v): \nexpected %v, \nactual   %v", tc.msg, tc.qty, tc.expQty, qty)
+		}
+	}
+}
+
+func TestLotSizeValid(t *testing.T) {
+	// testCases is a table for testing the validation of a qty
+	var testCases = []struct {
+		msg string
+		lot LotSize
+		qty float64
+		exp bool
+	}{
+		{"multiple of step:", LotSize{Step: 0.001}, 0.005, true},
+		{"not a multiple of step:", LotSize{Step: 0.01}, 0.005, false},
+		{"below min qty:", LotSize{Step: 1, Min: 10}, 5, false},
+		{"zero qty:", LotSize{Step: 1}, 0, false},
+	}
+
+	for _, tc := range testCases {
+		ok := tc.lot.Valid(tc.qty)
+		if ok != tc.exp {
+			t.Errorf("%v Valid(%v): \nexpected %v, \nactual   %v", tc.msg, tc.qty, tc.exp, ok)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// LiquidationPolicy defines which positions are closed on a margin call.
+type LiquidationPolicy int
+
+// different liquidation policies
+const (
+	// LiquidateAll closes all positions
+	LiquidateAll LiquidationPolicy = iota // 0
+	// LiquidateLargest closes the positions with the largest market value first,
+	// until the maintenance margin is covered again
+	LiquidateLargest
+)
+
+// Margin defines the margin requirements of a portfolio as share of the gross market value,
+// e.g. Initial 0.5 allows a leverage of 2, Maintenance 0.25 issues a margin call
+// if the equity falls below 25% of the gross market value.
+type Margin struct {
+	Initial     float64
+	Maintenance float64
+	Policy      LiquidationPolicy
+}
+
+// MarginCallEvent declares a margin call event.
+type MarginCallEvent interface {
+	EventHandler
+	Equity() float64
+	Requirement() float64
+}
+
+// MarginCall declares an event for an equity below the maintenance margin.
+type MarginCall struct {
+	Event
+	equity      float64
+	requirement float64
+}
+
+// Equity returns the equity of the portfolio at the margin call.
+func (m MarginCall) Equity() float64 {
+	return m.equity
+}
+
+// Requirement returns the maintenance margin requirement at the margin call.
+func (m MarginCall) Requirement() float64 {
+	return m.requirement
+}
+
+// String returns a description of the margin call.
+func (m MarginCall) String() string {
+	return fmt.Sprintf("margin call: equity %.2f below maintenance margin %.2f", m.equity, m.requirement)
+}
+
+// MarginChecker checks the margin of a portfolio on a data event
+// and returns a margin call with the orders to liquidate positions.
+type MarginChecker interface {
+	CheckMargin(DataEvent) (*MarginCall, []*Order)
+}
+
+// SetMargin sets the margin requirements of the portfolio. With a margin set,
+// buy orders may use negative cash up to the initial margin, instead of the available cash.
+func (p *Portfolio) SetMargin(margin *Margin) {
+	p.margin = margin
+}
+
+// Margin returns the margin requirements of the portfolio.
+func (p Portfolio) Margin() (*Margin, bool) {
+	if p.margin == nil {
+		return nil, false
+	}
+	return p.margin, true
+}
+
+// CheckMargin issues a margin call with liquidation orders,
+// if the equity falls below the maintenance margin. A position with a pending liquidation
+// order is not liquidated again, no margin call is issued while all liquidations are pending.
+func (p *Portfolio) CheckMargin(data DataEvent) (*MarginCall, []*Order) {
+	if p.margin == nil {
+		return nil, nil
+	}
+
+	equity := p.Value()
+	gross := p.grossValue()
+	requirement := gross * p.margin.Maintenance
+	if gross == 0 || equity >= requirement {
+		return nil, nil
+	}
+
+	// collect all open positions
+	var positions []Position
+	for _, pos := range p.holdings {
+		if pos.qty != 0 {
+			positions = append(positions, pos)
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].marketValue == positions[j].marketValue {
+			return positions[i].symbol < positions[j].symbol
+		}
+		return positions[i].marketValue > positions[j].marketValue
+	})
+
+	var orders []*Order
+	for _, pos := range positions {
+		if p.margin.Policy == LiquidateLargest && equity >= gross*p.margin.Maintenance {
+			break
+		}
+
+		// the position is already being closed
+		if p.liquidating(pos.symbol) {
+			gross -= pos.marketValue
+			continue
+		}
+
+		order := closeOrder(pos, data.Time())
+		if p.liquidations == nil {
+			p.liquidations = make(map[string]*Order)
+		}
+		p.liquidations[pos.symbol] = order
+		orders = append(orders, order)
+
+		gross -= pos.marketValue
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	call := &MarginCall{
+		Event:       Event{timestamp: data.Time(), symbol: data.Symbol()},
+		equity:      math.Round(equity*math.Pow10(DP)) / math.Pow10(DP),
+		requirement: math.Round(requirement*math.Pow10(DP)) / math.Pow10(DP),
+	}
+
+	return call, orders
+}
+
+// liquidating returns if a liquidation order of the symbol is still pending.
+func (p *Portfolio) liquidating(symbol string) bool {
+	return pendingOrder(p.liquidations, symbol)
+}
+
+// closeOrder returns a market order which closes a position.
+func closeOrder(pos Position, t time.Time) *Order {
+	order := &Order{
+		Event:     Event{timestamp: t, symbol: pos.symbol},
+		orderType: MarketOrder,
+		direction: SLD,
+		qty:       pos.qty,
+		closing:   true,
+	}
+	if pos.qty < 0 {
+		order.direction = BOT
+		order.qty = -pos.qty
+	}
+	return order
+}
+
+// buyingPower returns the value available for new positions under the initial margin.
+func (p Portfolio) buyingPower() float64 {
+	initial := p.margin.Initial
+	if initial <= 0 {
+		initial = 1
+	}
+	return p.Value()/initial - p.grossValue()
+}
+
+// grossValue returns the summed market value of all long and short positions.
+func (p Portfolio) grossValue() float64 {
+	var gross float64
+	for _, pos := range p.holdings {
+		gross += pos.marketValue
+	}
+	return gross
+}
@@ -0,0 +1,181 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPortfolioCheckMargin(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	newPortfolio := func(margin *Margin) *Portfolio {
+		return &Portfolio{
+			cash: -1000,
+			holdings: map[string]Position{
+				"A.DE": {symbol: "A.DE", qty: 100, marketValue: 6000},
+				"B.DE": {symbol: "B.DE", qty: -50, marketValue: 4000},
+			},
+			margin: margin,
+		}
+	}
+
+	// testCases is a table for testing margin calls
+	var testCases = []struct {
+		msg       string
+		portfolio *Portfolio
+		expCall   bool
+		expOrders []Direction
+	}{
+		{"no margin set:",
+			newPortfolio(nil),
+			false, nil,
+		},
+		{"equity above maintenance margin:",
+			newPortfolio(&Margin{Initial: 0.5, Maintenance: 0.1}),
+			false, nil,
+		},
+		{"liquidate all positions:",
+			newPortfolio(&Margin{Initial: 0.5, Maintenance: 0.25, Policy: LiquidateAll}),
+			true, []Direction{SLD, BOT},
+		},
+		{"liquidate largest position:",
+			newPortfolio(&Margin{Initial: 0.5, Maintenance: 0.25, Policy: LiquidateLargest}),
+			true, []Direction{SLD},
+		},
+	}
+
+	for _, tc := range testCases {
+		call, orders := tc.portfolio.CheckMargin(&Bar{Event: Event{timestamp: exampleTime, symbol: "A.DE"}})
+		if (call != nil) != tc.expCall || len(orders) != len(tc.expOrders) {
+			t.Errorf("%v CheckMargin(): \nexpected call %v with %v orders, \nactual   %#v %#v",
+				tc.msg, tc.expCall, len(tc.expOrders), call, orders)
+			continue
+		}
+		for i, order := range orders {
+			if order.Direction() != tc.expOrders[i] || !order.Closing() {
+				t.Errorf("%v CheckMargin(): expected closing order %v, actual %#v", tc.msg, tc.expOrders[i], order)
+			}
+		}
+	}
+}
+
+func TestPortfolioCheckMarginPending(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+	bar := &Bar{Event: Event{timestamp: exampleTime, symbol: "A.DE"}}
+
+	p := &Portfolio{
+		cash: -1000,
+		holdings: map[string]Position{
+			"A.DE": {symbol: "A.DE", qty: 100, marketValue: 6000},
+			"B.DE": {symbol: "B.DE", qty: -50, marketValue: 4000},
+		},
+		margin: &Margin{Initial: 0.5, Maintenance: 0.25, Policy: LiquidateAll},
+	}
+
+	call, orders := p.CheckMargin(bar)
+	if call == nil || len(orders) != 2 {
+		t.Fatalf("CheckMargin(): expected a margin call with 2 orders, actual %v %v", call, orders)
+	}
+
+	// the resting liquidation orders are not duplicated
+	orders[0].SetStatus(OrderSubmitted)
+	if call, again := p.CheckMargin(bar); call != nil || len(again) != 0 {
+		t.Errorf("CheckMargin(): expected no margin call with pending liquidations, actual %v %v", call, again)
+	}
+
+	// a canceled liquidation is issued again
+	orders[1].SetStatus(OrderCanceled)
+	call, again := p.CheckMargin(bar)
+	if call == nil || len(again) != 1 || again[0].Symbol() != orders[1].Symbol() {
+		t.Errorf("CheckMargin(): expected a new liquidation of %v, actual %v %v", orders[1].Symbol(), call, again)
+	}
+
+	// the fill closing the position ends the liquidation
+	fill := &Fill{Event: Event{timestamp: exampleTime, symbol: "A.DE"}, direction: SLD, qty: 100, price: 60}
+	if _, err := p.OnFill(fill, &Data{}); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+	if _, ok := p.liquidations["A.DE"]; ok {
+		t.Errorf("OnFill(): expected the liquidation of A.DE to end with the closed position")
+	}
+}
+
+func TestPortfolioCheckMarginShort(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	p := &Portfolio{initialCash: 1000, cash: 1000, margin: &Margin{Initial: 0.5, Maintenance: 0.25}}
+	fill := &Fill{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, direction: SLD, qty: 20, price: 100}
+	if _, err := p.OnFill(fill, &Data{}); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+
+	// testCases is a table for testing the margin of a losing short position
+	var testCases = []struct {
+		msg       string
+		price     float64
+		expEquity float64
+		expCall   bool
+	}{
+		{"short at the entry price:", 100, 1000, false},
+		{"losing short above maintenance margin:", 120, 600, false},
+		{"losing short below maintenance margin:", 300, -3000, true},
+	}
+
+	for _, tc := range testCases {
+		bar := &Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: tc.price}
+		p.Update(bar)
+		if equity := p.Value(); equity != tc.expEquity {
+			t.Errorf("%v Value(): expected %v, actual %v", tc.msg, tc.expEquity, equity)
+		}
+		call, orders := p.CheckMargin(bar)
+		if (call != nil) != tc.expCall {
+			t.Errorf("%v CheckMargin(): expected call %v, actual %v", tc.msg, tc.expCall, call)
+			continue
+		}
+		if !tc.expCall {
+			continue
+		}
+		if call.Equity() != tc.expEquity || len(orders) != 1 || orders[0].Direction() != BOT || orders[0].Qty() != 20 || !orders[0].Closing() {
+			t.Errorf("%v CheckMargin(): expected the liquidation of the short, actual %v %#v", tc.msg, call, orders)
+		}
+		if power := p.buyingPower(); power >= 0 {
+			t.Errorf("%v buyingPower(): expected no buying power, actual %v", tc.msg, power)
+		}
+	}
+}
+
+func TestPortfolioBuyingPower(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	p := &Portfolio{cash: 10000, margin: &Margin{Initial: 0.5, Maintenance: 0.25}}
+	latest := &Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 100}
+
+	// leverage of 2 allows an order value of 20000
+	order := &Order{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, direction: BOT, qty: 200}
+	if rejection := p.checkOrder(order, latest); rejection != nil {
+		t.Errorf("checkOrder(): expected order within buying power, actual %v", rejection)
+	}
+
+	order.SetQty(201)
+	if rejection := p.checkOrder(order, latest); rejection == nil {
+		t.Errorf("checkOrder(): expected rejection above buying power")
+	}
+
+	// a short position is limited by the buying power as well
+	short := &Order{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, direction: SLD, qty: 200}
+	if rejection := p.checkOrder(short, latest); rejection != nil {
+		t.Errorf("checkOrder(): expected short within buying power, actual %v", rejection)
+	}
+
+	short.SetQty(201)
+	if rejection := p.checkOrder(short, latest); rejection == nil {
+		t.Errorf("checkOrder(): expected rejection of the short above buying power")
+	}
+
+	// selling a long position only limits the qty exceeding it
+	p.holdings = map[string]Position{"TEST.DE": {symbol: "TEST.DE", qty: 50, marketValue: 5000}}
+	short.SetQty(250)
+	if rejection := p.checkOrder(short, latest); rejection != nil {
+		t.Errorf("checkOrder(): expected sale of the long position within buying power, actual %v", rejection)
+	}
+}
@@ -0,0 +1,175 @@
+package gobacktest
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Featurer extracts the features of a signal for the training of a secondary model.
+type Featurer interface {
+	Features(SignalEvent, DataHandler) map[string]float64
+}
+
+// SignalFilter is the secondary model of meta-labeling, which accepts or rejects
+// a primary signal at runtime based on its features.
+type SignalFilter interface {
+	Accept(SignalEvent, map[string]float64) bool
+}
+
+// MetricFeatures uses the metrics of the latest data event of the signal symbol as features,
+// e.g. indicator values or market regimes.
+type MetricFeatures struct{}
+
+// Features returns a copy of the metrics of the latest data event.
+func (f *MetricFeatures) Features(signal SignalEvent, data DataHandler) map[string]float64 {
+	var metric Metric
+	switch e := data.Latest(signal.Symbol()).(type) {
+	case *Bar:
+		metric = e.Metric
+	case *Tick:
+		metric = e.Metric
+	case *Book:
+		metric = e.Metric
+	}
+
+	features := make(map[string]float64)
+	for k, v := range metric {
+		features[k] = v
+	}
+	return features
+}
+
+// MetaSample is a primary signal with its features and the outcome of the trade.
+type MetaSample struct {
+	Time       time.Time
+	Symbol     string
+	Direction  Direction
+	Features   map[string]float64
+	Accepted   bool    // the signal passed the secondary model
+	EntryPrice float64 // latest price at the signal
+	Return     float64 // return of the trade in signal direction
+	Label      int     // 1 for a profitable trade, 0 otherwise
+	Labeled    bool    // the trade is closed and the sample labeled
+}
+
+// MetaLabeler records the features of every long and short signal and labels them
+// with the outcome of the trade, once the position of the symbol is closed.
+// With a Filter set, signals rejected by the secondary model are not traded.
+type MetaLabeler struct {
+	Features Featurer
+	Filter   SignalFilter
+	samples  []*MetaSample
+}
+
+// Samples returns all recorded samples.
+func (m *MetaLabeler) Samples() []*MetaSample {
+	return m.samples
+}
+
+// Reset implements Reseter to remove all samples.
+func (m *MetaLabeler) Reset() error {
+	m.samples = nil
+	return nil
+}
+
+// onSignal records a signal and returns false, if the secondary model rejects it.
+func (m *MetaLabeler) onSignal(signal SignalEvent, data DataHandler) bool {
+	if signal.Direction() != BOT && signal.Direction() != SLD {
+		return true
+	}
+
+	features := map[string]float64{}
+	if m.Features != nil {
+		features = m.Features.Features(signal, data)
+	}
+
+	sample := &MetaSample{
+		Time:      signal.Time(),
+		Symbol:    signal.Symbol(),
+		Direction: signal.Direction(),
+		Features:  features,
+		Accepted:  m.Filter == nil || m.Filter.Accept(signal, features),
+	}
+	if latest := data.Latest(signal.Symbol()); latest != nil {
+		sample.EntryPrice = latest.Price()
+	}
+
+	m.samples = append(m.samples, sample)
+	return sample.Accepted
+}
+
+// close labels all accepted open samples of a symbol with the exit price.
+func (m *MetaLabeler) close(symbol string, price float64) {
+	for _, s := range m.samples {
+		if s.Labeled || !s.Accepted || s.Symbol != symbol || s.EntryPrice == 0 {
+			continue
+		}
+
+		r := price/s.EntryPrice - 1
+		if s.Direction == SLD {
+			r = -r
+		}
+		s.Return = math.Round(r*math.Pow10(DP)) / math.Pow10(DP)
+		if s.Return > 0 {
+			s.Label = 1
+		}
+		s.Labeled = true
+	}
+}
+
+// WriteCSV exports all labeled samples as training dataset,
+// with a column for each feature sorted by name.
+func (m *MetaLabeler) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	// collect the names of all features
+	set := make(map[string]bool)
+	for _, s := range m.samples {
+		for k := range s.Features {
+			set[k] = true
+		}
+	}
+	var names []string
+	for k := range set {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	header := append([]string{"Time", "Symbol", "Direction"}, names...)
+	header = append(header, "Return", "Label")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range m.samples {
+		if !s.Labeled {
+			continue
+		}
+
+		direction := "BOT"
+		if s.Direction == SLD {
+			direction = "SLD"
+		}
+		record := []string{s.Time.Format(time.RFC3339), s.Symbol, direction}
+		for _, name := range names {
+			record = append(record, strconv.FormatFloat(s.Features[name], 'f', -1, 64))
+		}
+		record = append(record, strconv.FormatFloat(s.Return, 'f', DP, 64), strconv.Itoa(s.Label))
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SetMetaLabeler sets the meta-labeling of the signals of the portfolio.
+func (p *Portfolio) SetMetaLabeler(m *MetaLabeler) {
+	p.meta = m
+}
@@ -0,0 +1,74 @@
+package gobacktest
+
+import (
+	"bytes"
+	"testing"
+)
+
+// thresholdFilter accepts signals with a feature value above a threshold.
+type thresholdFilter struct {
+	feature   string
+	threshold float64
+}
+
+func (f *thresholdFilter) Accept(signal SignalEvent, features map[string]float64) bool {
+	return features[f.feature] > f.threshold
+}
+
+func TestMetaLabeler(t *testing.T) {
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 100, Metric: Metric{"SMA20": 95}},
+			"BAD.DE":  &Bar{Close: 100, Metric: Metric{"SMA20": 105}},
+		},
+	}
+
+	meta := &MetaLabeler{Features: &MetricFeatures{}, Filter: &thresholdFilter{feature: "SMA20", threshold: 100}}
+	p := &Portfolio{
+		initialCash:       100000,
+		cash:              100000,
+		sizeManager:       &Size{DefaultSize: 10, DefaultValue: 100000},
+		riskManager:       &Risk{},
+		allowNegativeCash: true,
+	}
+	p.SetMetaLabeler(meta)
+
+	// the secondary model rejects the signal
+	_, err := p.OnSignal(&Signal{Event: Event{symbol: "TEST.DE"}, direction: BOT}, data)
+	if _, ok := err.(*Rejection); !ok {
+		t.Errorf("OnSignal(): expected rejection by meta model, actual %v", err)
+	}
+
+	// the secondary model accepts the signal
+	order, err := p.OnSignal(&Signal{Event: Event{symbol: "BAD.DE"}, direction: BOT}, data)
+	if err != nil || order == nil {
+		t.Fatalf("OnSignal(): expected order, actual %#v %v", order, err)
+	}
+
+	// open and close the position
+	p.OnFill(&Fill{Event: Event{symbol: "BAD.DE"}, direction: BOT, qty: 10, price: 100}, data)
+	if meta.Samples()[1].Labeled {
+		t.Errorf("OnFill(): expected open position to be unlabeled")
+	}
+	p.OnFill(&Fill{Event: Event{symbol: "BAD.DE"}, direction: SLD, qty: 10, price: 110}, data)
+
+	samples := meta.Samples()
+	if len(samples) != 2 {
+		t.Fatalf("Samples(): expected 2 samples, actual %v", len(samples))
+	}
+	if samples[0].Accepted || samples[0].Labeled {
+		t.Errorf("Samples(): expected rejected unlabeled sample, actual %#v", samples[0])
+	}
+	if !samples[1].Labeled || samples[1].Label != 1 || samples[1].Return != 0.1 {
+		t.Errorf("Samples(): expected profitable labeled sample, actual %#v", samples[1])
+	}
+
+	var buf bytes.Buffer
+	if err := meta.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV(): unexpected error %v", err)
+	}
+	exp := "Time,Symbol,Direction,SMA20,Return,Label\n0001-01-01T00:00:00Z,BAD.DE,BOT,105,0.1000,1\n"
+	if buf.String() != exp {
+		t.Errorf("WriteCSV(): \nexpected %q, \nactual %q", exp, buf.String())
+	}
+}
@@ -0,0 +1,102 @@
+package gobacktest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MetricCalculator calculates a custom metric of a run from its equity curve and its
+// transactions, e.g. a proprietary ratio.
+type MetricCalculator interface {
+	Calculate(equity []EquityPoint, trades []FillEvent) float64
+}
+
+// CustomMetricer returns the values of the registered custom metrics of a run.
+type CustomMetricer interface {
+	CustomMetrics() map[string]float64
+}
+
+// MetricCalculatorFunc is a function which implements MetricCalculator.
+type MetricCalculatorFunc func(equity []EquityPoint, trades []FillEvent) float64
+
+// Calculate calls the function.
+func (f MetricCalculatorFunc) Calculate(equity []EquityPoint, trades []FillEvent) float64 {
+	return f(equity, trades)
+}
+
+// metricCalculators holds the registered custom metrics by name.
+var metricCalculators = struct {
+	sync.RWMutex
+	m map[string]MetricCalculator
+}{m: make(map[string]MetricCalculator)}
+
+// RegisterMetric registers a custom metric by name. It is calculated from the statistic at the
+// end of a run and appears with the results of the run, e.g. the printed result, the runner
+// report, the notifications, the server result and the stored runs. A registered metric of the
+// same name is replaced.
+func RegisterMetric(name string, calc MetricCalculator) error {
+	if name == "" {
+		return errors.New("can not register metric without name")
+	}
+	if calc == nil {
+		return fmt.Errorf("can not register metric %q without calculator", name)
+	}
+
+	metricCalculators.Lock()
+	defer metricCalculators.Unlock()
+	metricCalculators.m[name] = calc
+	return nil
+}
+
+// UnregisterMetric removes a registered custom metric.
+func UnregisterMetric(name string) {
+	metricCalculators.Lock()
+	defer metricCalculators.Unlock()
+	delete(metricCalculators.m, name)
+}
+
+// RegisteredMetrics returns the sorted names of the registered custom metrics.
+func RegisteredMetrics() []string {
+	metricCalculators.RLock()
+	defer metricCalculators.RUnlock()
+
+	names := make([]string, 0, len(metricCalculators.m))
+	for name := range metricCalculators.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CustomMetrics returns the values of all registered custom metrics by name, nil if no metric
+// is registered.
+func (s Statistic) CustomMetrics() map[string]float64 {
+	metricCalculators.RLock()
+	defer metricCalculators.RUnlock()
+	if len(metricCalculators.m) == 0 {
+		return nil
+	}
+
+	equity := s.EquityCurve()
+	metrics := make(map[string]float64, len(metricCalculators.m))
+	for name, calc := range metricCalculators.m {
+		metrics[name] = calc.Calculate(equity, s.transactionHistory)
+	}
+	return metrics
+}
+
+// printCustomMetrics prints the custom metrics sorted by name.
+func (s Statistic) printCustomMetrics() {
+	metrics := s.CustomMetrics()
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("%s: %.4f\n", name, metrics[name])
+	}
+}
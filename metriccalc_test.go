@@ -0,0 +1,74 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lastEquity is a custom metric of the last equity of a run.
+var lastEquity = MetricCalculatorFunc(func(equity []EquityPoint, trades []FillEvent) float64 {
+	if len(equity) == 0 {
+		return 0
+	}
+	return equity[len(equity)-1].Equity
+})
+
+// tradeCount is a custom metric of the number of trades of a run.
+var tradeCount = MetricCalculatorFunc(func(equity []EquityPoint, trades []FillEvent) float64 {
+	return float64(len(trades))
+})
+
+func TestRegisterMetric(t *testing.T) {
+	defer UnregisterMetric("trades")
+
+	// testCases is a table for testing the registration of custom metrics
+	var testCases = []struct {
+		msg    string
+		name   string
+		calc   MetricCalculator
+		expErr bool
+	}{
+		{"register a metric:", "trades", tradeCount, false},
+		{"replace a metric:", "trades", tradeCount, false},
+		{"metric without name:", "", tradeCount, true},
+		{"metric without calculator:", "nil", nil, true},
+	}
+
+	for _, tc := range testCases {
+		err := RegisterMetric(tc.name, tc.calc)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v RegisterMetric(%q): expected error %v, actual %v", tc.msg, tc.name, tc.expErr, err)
+		}
+	}
+
+	if names := RegisteredMetrics(); !reflect.DeepEqual(names, []string{"trades"}) {
+		t.Errorf("RegisteredMetrics(): expected [trades], actual %v", names)
+	}
+	UnregisterMetric("trades")
+	if names := RegisteredMetrics(); len(names) != 0 {
+		t.Errorf("UnregisterMetric(): expected no metrics, actual %v", names)
+	}
+}
+
+func TestCustomMetrics(t *testing.T) {
+	s := newDailyStatistic([]float64{0.1, -0.1})
+	s.transactionHistory = []FillEvent{&Fill{}, &Fill{}, &Fill{}}
+
+	if metrics := s.CustomMetrics(); metrics != nil {
+		t.Errorf("CustomMetrics(): expected nil without registered metrics, actual %v", metrics)
+	}
+
+	if err := RegisterMetric("trades", tradeCount); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterMetric("trades")
+	if err := RegisterMetric("last equity", lastEquity); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterMetric("last equity")
+
+	metrics := s.CustomMetrics()
+	if len(metrics) != 2 || metrics["trades"] != 3 || metrics["last equity"] != 1000*1.1*0.9 {
+		t.Errorf("CustomMetrics(): expected 3 trades and the last equity, actual %v", metrics)
+	}
+}
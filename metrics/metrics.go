@@ -0,0 +1,237 @@
+// Package metrics exports the metrics of running backtests in the Prometheus text format,
+// so long running services, e.g. the server or paper trading, can be monitored.
+//
+// The Exporter receives the progress of the runs as a gbt.ProgressReporter and serves
+//
+//	gobacktest_runs_active                       runs currently reporting progress
+//	gobacktest_events_processed_total            events processed by all runs
+//	gobacktest_run_events_total{run}             events processed by a run
+//	gobacktest_run_events_per_second{run}        event rate of a run
+//	gobacktest_run_progress_ratio{run}           completed share of a run, 0 if unknown
+//	gobacktest_run_queue_depth{run}              events waiting in the event queue of a run
+//	gobacktest_run_open_positions{run}           open positions of the portfolio of a run
+//	gobacktest_run_equity{run}                   current value of the portfolio of a run
+//	gobacktest_run_return{run}                   total return of a run so far
+//	gobacktest_run_drawdown{run}                 current drawdown of a run, 0 or negative
+//	gobacktest_run_max_drawdown{run}             max drawdown of a run so far, 0 or negative
+//	gobacktest_run_sharpe{run}                   sharpe ratio of a run so far, NaN if undefined
+//	gobacktest_job_duration_seconds{status}      histogram of the durations of finished jobs
+//
+// The series of a run are removed with its final progress report or Remove.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// DefaultBuckets are the upper bounds of the job duration histogram in seconds.
+var DefaultBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800, 3600}
+
+// Exporter collects the metrics of runs and jobs.
+type Exporter struct {
+	Buckets []float64 // defaults to DefaultBuckets
+
+	mu        sync.Mutex
+	runs      map[string]gbt.Progress
+	processed float64
+	durations map[string]*histogram
+}
+
+// histogram is a cumulative histogram of observations.
+type histogram struct {
+	counts []uint64 // by bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewExporter creates an exporter ready for use.
+func NewExporter() *Exporter {
+	return &Exporter{
+		runs:      make(map[string]gbt.Progress),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// Reporter returns the progress reporter of a run, e.g. for Backtest.SetProgress.
+// The name of the run is the value of the run label.
+func (e *Exporter) Reporter(run string) gbt.ProgressReporter {
+	var last int
+	return gbt.ProgressFunc(func(p gbt.Progress) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		// count the events since the last report, a new run of the reporter starts from 0
+		if p.Events < last {
+			last = 0
+		}
+		e.processed += float64(p.Events - last)
+		last = p.Events
+
+		if p.Done {
+			delete(e.runs, run)
+			last = 0
+			return
+		}
+		e.runs[run] = p
+	})
+}
+
+// Remove removes the series of a run, e.g. of a failed run without a final progress report.
+func (e *Exporter) Remove(run string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.runs, run)
+}
+
+// ObserveJob records the duration of a finished job with its final status, e.g. done or failed.
+func (e *Exporter) ObserveJob(status string, d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	buckets := e.buckets()
+	h, ok := e.durations[status]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		e.durations[status] = h
+	}
+
+	seconds := d.Seconds()
+	for i, bound := range buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// buckets returns the buckets of the histogram.
+func (e *Exporter) buckets() []float64 {
+	if len(e.Buckets) == 0 {
+		return DefaultBuckets
+	}
+	return e.Buckets
+}
+
+// ServeHTTP implements http.Handler, serving the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var b strings.Builder
+
+	header(&b, "gobacktest_runs_active", "gauge", "Runs currently reporting progress.")
+	sample(&b, "gobacktest_runs_active", "", float64(len(e.runs)))
+	header(&b, "gobacktest_events_processed_total", "counter", "Events processed by all runs.")
+	sample(&b, "gobacktest_events_processed_total", "", e.processed)
+
+	runs := make([]string, 0, len(e.runs))
+	for run := range e.runs {
+		runs = append(runs, run)
+	}
+	sort.Strings(runs)
+
+	series := []struct {
+		name, kind, help string
+		value            func(gbt.Progress) float64
+	}{
+		{"gobacktest_run_events_total", "counter", "Events processed by a run.",
+			func(p gbt.Progress) float64 { return float64(p.Events) }},
+		{"gobacktest_run_events_per_second", "gauge", "Event rate of a run.",
+			func(p gbt.Progress) float64 { return p.EventsPerSecond }},
+		{"gobacktest_run_progress_ratio", "gauge", "Completed share of a run, 0 if unknown.",
+			func(p gbt.Progress) float64 { return p.Percent / 100 }},
+		{"gobacktest_run_queue_depth", "gauge", "Events waiting in the event queue of a run.",
+			func(p gbt.Progress) float64 { return float64(p.Queued) }},
+		{"gobacktest_run_open_positions", "gauge", "Open positions of the portfolio of a run.",
+			func(p gbt.Progress) float64 { return float64(p.Positions) }},
+		{"gobacktest_run_equity", "gauge", "Current value of the portfolio of a run.",
+			func(p gbt.Progress) float64 { return p.Equity }},
+		{"gobacktest_run_return", "gauge", "Total return of a run so far.",
+			func(p gbt.Progress) float64 { return p.Stats.Return }},
+		{"gobacktest_run_drawdown", "gauge", "Current drawdown of a run, 0 or negative.",
+			func(p gbt.Progress) float64 { return p.Stats.Drawdown }},
+		{"gobacktest_run_max_drawdown", "gauge", "Max drawdown of a run so far, 0 or negative.",
+			func(p gbt.Progress) float64 { return p.Stats.MaxDrawdown }},
+		{"gobacktest_run_sharpe", "gauge", "Sharpe ratio of a run so far, NaN if undefined.",
+			func(p gbt.Progress) float64 { return p.Stats.Sharpe }},
+	}
+	for _, g := range series {
+		header(&b, g.name, g.kind, g.help)
+		for _, run := range runs {
+			sample(&b, g.name, label("run", run), g.value(e.runs[run]))
+		}
+	}
+
+	header(&b, "gobacktest_job_duration_seconds", "histogram", "Durations of finished jobs.")
+	statuses := make([]string, 0, len(e.durations))
+	for status := range e.durations {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		h := e.durations[status]
+		var cumulative uint64
+		for i, bound := range e.buckets() {
+			cumulative += h.counts[i]
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			sample(&b, "gobacktest_job_duration_seconds_bucket", label("status", status)+","+label("le", le), float64(cumulative))
+		}
+		sample(&b, "gobacktest_job_duration_seconds_bucket", label("status", status)+`,le="+Inf"`, float64(h.count))
+		sample(&b, "gobacktest_job_duration_seconds_sum", label("status", status), h.sum)
+		sample(&b, "gobacktest_job_duration_seconds_count", label("status", status), float64(h.count))
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// header writes the help and type lines of a metric.
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample of a metric with its labels.
+func sample(b *strings.Builder, name, labels string, value float64) {
+	b.WriteString(name)
+	if labels != "" {
+		b.WriteString("{" + labels + "}")
+	}
+	b.WriteString(" " + format(value) + "\n")
+}
+
+// label formats a label with its escaped value.
+func label(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return name + `="` + value + `"`
+}
+
+// format formats a sample value.
+func format(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+func TestExporterWriteTo(t *testing.T) {
+	e := NewExporter()
+	e.Buckets = []float64{1, 10}
+
+	a := e.Reporter(`run "a"`)
+	a.OnProgress(gbt.Progress{Events: 10, EventsPerSecond: 100, Percent: 50, Queued: 2, Positions: 1, Equity: 10500.5})
+	a.OnProgress(gbt.Progress{Events: 25, EventsPerSecond: 120, Percent: 75, Queued: 1, Positions: 2, Equity: 10600,
+		Stats: gbt.RunningStats{Return: 0.06, Drawdown: -0.01, MaxDrawdown: -0.02, Sharpe: math.NaN()}})
+	b := e.Reporter("b")
+	b.OnProgress(gbt.Progress{Events: 5})
+	b.OnProgress(gbt.Progress{Events: 8, Done: true})
+
+	e.ObserveJob("done", 500*time.Millisecond)
+	e.ObserveJob("done", 5*time.Second)
+	e.ObserveJob("failed", time.Minute)
+
+	var out strings.Builder
+	if _, err := e.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo(): unexpected error %v", err)
+	}
+
+	// testCases is a table for testing the samples of the exporter
+	var testCases = []struct {
+		msg    string
+		sample string
+	}{
+		{"active runs:", "gobacktest_runs_active 1\n"},
+		{"events of all runs:", "gobacktest_events_processed_total 33\n"},
+		{"events of a run:", `gobacktest_run_events_total{run="run \"a\""} 25` + "\n"},
+		{"progress of a run:", `gobacktest_run_progress_ratio{run="run \"a\""} 0.75` + "\n"},
+		{"queue depth of a run:", `gobacktest_run_queue_depth{run="run \"a\""} 1` + "\n"},
+		{"positions of a run:", `gobacktest_run_open_positions{run="run \"a\""} 2` + "\n"},
+		{"equity of a run:", `gobacktest_run_equity{run="run \"a\""} 10600` + "\n"},
+		{"return of a run:", `gobacktest_run_return{run="run \"a\""} 0.06` + "\n"},
+		{"drawdown of a run:", `gobacktest_run_drawdown{run="run \"a\""} -0.01` + "\n"},
+		{"max drawdown of a run:", `gobacktest_run_max_drawdown{run="run \"a\""} -0.02` + "\n"},
+		{"undefined sharpe of a run:", `gobacktest_run_sharpe{run="run \"a\""} NaN` + "\n"},
+		{"first bucket:", `gobacktest_job_duration_seconds_bucket{status="done",le="1"} 1` + "\n"},
+		{"cumulative bucket:", `gobacktest_job_duration_seconds_bucket{status="done",le="10"} 2` + "\n"},
+		{"inf bucket:", `gobacktest_job_duration_seconds_bucket{status="failed",le="+Inf"} 1` + "\n"},
+		{"sum:", `gobacktest_job_duration_seconds_sum{status="done"} 5.5` + "\n"},
+		{"type:", "# TYPE gobacktest_job_duration_seconds histogram\n"},
+	}
+
+	for _, tc := range testCases {
+		if !strings.Contains(out.String(), tc.sample) {
+			t.Errorf("%v\nexpected sample %q in\n%s", tc.msg, tc.sample, out.String())
+		}
+	}
+	if strings.Contains(out.String(), `run="b"`) {
+		t.Errorf("WriteTo(): expected the series of the finished run to be removed")
+	}
+
+	e.Remove(`run "a"`)
+	out.Reset()
+	e.WriteTo(&out)
+	if !strings.Contains(out.String(), "gobacktest_runs_active 0\n") {
+		t.Errorf("Remove(): expected no active runs, actual\n%s", out.String())
+	}
+}
+
+func TestExporterBacktest(t *testing.T) {
+	c := config.Config{
+		Symbols:  []string{"SDF.DE"},
+		Data:     config.DataConfig{Dir: "../examples/testdata/bar/", Start: "2016-12-01", End: "2017-06-30"},
+		Size:     config.SizeConfig{DefaultSize: 10, DefaultValue: 1000},
+		Strategy: config.StrategyConfig{Name: "buy-and-hold"},
+	}
+	test, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExporter()
+	// the metrics at the last progress report before the end of the run
+	var active string
+	reporter := e.Reporter("test")
+	test.SetProgress(gbt.ProgressFunc(func(p gbt.Progress) {
+		reporter.OnProgress(p)
+		if !p.Done {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+			active = rec.Body.String()
+		}
+	}), 10)
+
+	if err := test.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(active, "gobacktest_runs_active 1\n") || !strings.Contains(active, `gobacktest_run_open_positions{run="test"} 1`) {
+		t.Errorf("ServeHTTP(): expected an active run with an open position, actual\n%s", active)
+	}
+
+	var out strings.Builder
+	e.WriteTo(&out)
+	events := len(test.Stats().Events())
+	if events == 0 || !strings.Contains(out.String(), "gobacktest_runs_active 0\n") {
+		t.Errorf("WriteTo(): expected no active runs after the run, actual\n%s", out.String())
+	}
+}
@@ -0,0 +1,76 @@
+package gobacktest
+
+import (
+	"fmt"
+	"log"
+)
+
+// EventFunc processes an event of the queue.
+type EventFunc func(EventHandler) error
+
+// Middleware wraps the processing of an event. It may observe the event, pass on a modified
+// event to next, e.g. for what-if experiments, or drop it by not calling next.
+type Middleware func(next EventFunc) EventFunc
+
+// Use adds middleware to the event processing. The first added middleware is the outermost
+// and sees every event first.
+func (t *Backtest) Use(mw ...Middleware) {
+	t.middleware = append(t.middleware, mw...)
+}
+
+// handler returns the event processing wrapped by all middleware.
+func (t *Backtest) handler() EventFunc {
+	var h EventFunc = func(e EventHandler) error {
+		if err := t.eventLoop(e); err != nil {
+			return err
+		}
+		// event in queue found, add to event history
+		t.statistic.TrackEvent(e)
+		return nil
+	}
+
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		h = t.middleware[i](h)
+	}
+	return h
+}
+
+// LogEvents returns a middleware which logs every event before it is processed.
+func LogEvents(logger *log.Logger) Middleware {
+	return func(next EventFunc) EventFunc {
+		return func(e EventHandler) error {
+			logger.Printf("%T %s %s", e, e.Symbol(), e.Time().Format("2006-01-02 15:04:05"))
+			return next(e)
+		}
+	}
+}
+
+// EventCounter counts the processed events per event type.
+type EventCounter struct {
+	counts map[string]int
+}
+
+// Middleware returns the middleware which counts the events.
+func (c *EventCounter) Middleware() Middleware {
+	return func(next EventFunc) EventFunc {
+		return func(e EventHandler) error {
+			// check for nil map, else initialise the map
+			if c.counts == nil {
+				c.counts = make(map[string]int)
+			}
+			c.counts[fmt.Sprintf("%T", e)]++
+			return next(e)
+		}
+	}
+}
+
+// Counts returns the number of processed events per event type, e.g. "*gobacktest.Bar".
+func (c *EventCounter) Counts() map[string]int {
+	return c.counts
+}
+
+// Reset implements Reseter to clear the counts.
+func (c *EventCounter) Reset() error {
+	c.counts = nil
+	return nil
+}
@@ -0,0 +1,72 @@
+package gobacktest
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBacktestMiddleware(t *testing.T) {
+	// dropSignals is a middleware for a what-if run without any trading
+	dropSignals := func(next EventFunc) EventFunc {
+		return func(e EventHandler) error {
+			if _, ok := e.(*Signal); ok {
+				return nil
+			}
+			return next(e)
+		}
+	}
+
+	// testCases is a table for testing the middleware chain
+	var testCases = []struct {
+		msg       string
+		drop      bool
+		expCounts map[string]int
+		expFills  int
+	}{
+		{"counting all events:", false,
+			map[string]int{"*gobacktest.Bar": 2, "*gobacktest.Signal": 2, "*gobacktest.Order": 2, "*gobacktest.Fill": 2}, 2},
+		{"dropping signals:", true,
+			map[string]int{"*gobacktest.Bar": 2, "*gobacktest.Signal": 2}, 0},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100))
+
+		var buf bytes.Buffer
+		counter := &EventCounter{}
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		test.Use(LogEvents(log.New(&buf, "", 0)), counter.Middleware())
+		if tc.drop {
+			test.Use(dropSignals)
+		}
+
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+		}
+
+		if !reflect.DeepEqual(counter.Counts(), tc.expCounts) {
+			t.Errorf("%v Counts(): expected %v, actual %v", tc.msg, tc.expCounts, counter.Counts())
+		}
+		if fills := len(test.statistic.Transactions()); fills != tc.expFills {
+			t.Errorf("%v expected %v fills, actual %v", tc.msg, tc.expFills, fills)
+		}
+		// a dropped event is not tracked
+		if events := len(test.statistic.Events()); events != 2+3*tc.expFills {
+			t.Errorf("%v expected %v tracked events, actual %v", tc.msg, 2+3*tc.expFills, events)
+		}
+		var logged int
+		for _, n := range tc.expCounts {
+			logged += n
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != logged {
+			t.Errorf("%v expected %v logged events, actual %v", tc.msg, logged, lines)
+		}
+	}
+}
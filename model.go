@@ -0,0 +1,77 @@
+package gobacktest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Model is the basic interface for a machine learning model,
+// which predicts an output vector from a feature vector.
+type Model interface {
+	Predict([]float64) ([]float64, error)
+}
+
+// ModelLoader loads a model from a file.
+type ModelLoader func(path string) (Model, error)
+
+// modelLoaders holds the registered model loaders by file extension.
+var modelLoaders = struct {
+	sync.RWMutex
+	m map[string]ModelLoader
+}{m: make(map[string]ModelLoader)}
+
+// RegisterModelLoader registers a loader for model files with the extension, e.g. ".onnx".
+// The onnx package registers a loader for ONNX models when built with the onnx tag.
+func RegisterModelLoader(ext string, loader ModelLoader) {
+	modelLoaders.Lock()
+	defer modelLoaders.Unlock()
+	modelLoaders.m[strings.ToLower(ext)] = loader
+}
+
+// LoadModel loads a model file with the loader registered for its extension.
+func LoadModel(path string) (Model, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	modelLoaders.RLock()
+	loader, ok := modelLoaders.m[ext]
+	modelLoaders.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("could not load model %s, no model loader registered for %q", path, ext)
+	}
+	return loader(path)
+}
+
+// FeatureVector builds the feature vector of a data event from its metrics in the given order.
+func FeatureVector(event DataEvent, names ...string) ([]float64, error) {
+	features := make([]float64, len(names))
+	for i, name := range names {
+		value, ok := event.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("could not build feature vector, metric %s missing", name)
+		}
+		features[i] = value
+	}
+	return features, nil
+}
+
+// LinearModel is a simple linear model with a single output, the weighted sum of the features plus Bias.
+type LinearModel struct {
+	Weights []float64
+	Bias    float64
+}
+
+// Predict returns the weighted sum of the features.
+func (m *LinearModel) Predict(features []float64) ([]float64, error) {
+	if len(features) != len(m.Weights) {
+		return nil, fmt.Errorf("invalid number of features, given %v, needs %v", len(features), len(m.Weights))
+	}
+
+	sum := m.Bias
+	for i, f := range features {
+		sum += f * m.Weights[i]
+	}
+	return []float64{sum}, nil
+}
@@ -0,0 +1,55 @@
+package gobacktest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLoadModel(t *testing.T) {
+	RegisterModelLoader(".test", func(path string) (Model, error) {
+		if path == "missing.test" {
+			return nil, errors.New("file not found")
+		}
+		return &LinearModel{Weights: []float64{1}}, nil
+	})
+
+	// testCases is a table for testing the loading of models
+	var testCases = []struct {
+		msg    string
+		path   string
+		expErr bool
+	}{
+		{"registered loader:", "model.TEST", false},
+		{"loader error:", "missing.test", true},
+		{"no loader registered:", "model.onnx", true},
+	}
+
+	for _, tc := range testCases {
+		model, err := LoadModel(tc.path)
+		if (err != nil) != tc.expErr || (err == nil && model == nil) {
+			t.Errorf("%v LoadModel(): expected error %v, actual %#v %v", tc.msg, tc.expErr, model, err)
+		}
+	}
+}
+
+func TestFeatureVectorPredict(t *testing.T) {
+	bar := &Bar{Metric: Metric{"SMA20": 10, "RSI14": 50}}
+
+	features, err := FeatureVector(bar, "RSI14", "SMA20")
+	if err != nil || !reflect.DeepEqual(features, []float64{50, 10}) {
+		t.Fatalf("FeatureVector(): expected [50 10], actual %v %v", features, err)
+	}
+	if _, err := FeatureVector(bar, "EMA5"); err == nil {
+		t.Errorf("FeatureVector(): expected error for missing metric")
+	}
+
+	model := &LinearModel{Weights: []float64{0.01, -0.1}, Bias: 0.5}
+	out, err := model.Predict(features)
+	if err != nil || !reflect.DeepEqual(out, []float64{0}) {
+		t.Errorf("Predict(): expected [0], actual %v %v", out, err)
+	}
+	if _, err := model.Predict([]float64{1}); err == nil {
+		t.Errorf("Predict(): expected error for invalid number of features")
+	}
+}
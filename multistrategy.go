@@ -0,0 +1,233 @@
+package gobacktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Sleeve is a strategy with its own sub-account within a multi-strategy backtest.
+// Each sleeve runs in its own backtest with its own portfolio, exchange and statistic.
+type Sleeve struct {
+	name       string
+	allocation float64
+	backtest   *Backtest
+}
+
+// Name returns the name of the sleeve.
+func (s Sleeve) Name() string {
+	return s.name
+}
+
+// Allocation returns the share of the total capital allocated to the sleeve.
+func (s Sleeve) Allocation() float64 {
+	return s.allocation
+}
+
+// Backtest returns the backtest of the sleeve, e.g. to set its portfolio or exchange.
+func (s Sleeve) Backtest() *Backtest {
+	return s.backtest
+}
+
+// Stats returns the statistic of the sleeve.
+func (s Sleeve) Stats() StatisticHandler {
+	return s.backtest.statistic
+}
+
+// MultiStrategy runs several strategies concurrently on the same data stream.
+// Each strategy trades a sub-account with its allocated share of the initial cash,
+// the combined statistic tracks the summed equity of all sub-accounts.
+type MultiStrategy struct {
+	initialCash float64
+	data        DataHandler
+	sleeves     []*Sleeve
+	statistic   StatisticHandler
+	combined    *combinedPortfolio
+}
+
+// NewMultiStrategy creates a multi-strategy backtest with the total initial cash.
+func NewMultiStrategy(cash float64) *MultiStrategy {
+	return &MultiStrategy{
+		initialCash: cash,
+		statistic:   &Statistic{},
+	}
+}
+
+// SetData sets the data provider shared by all strategies.
+func (m *MultiStrategy) SetData(data DataHandler) {
+	m.data = data
+}
+
+// SetStatistic sets the statistic provider for the combined result.
+func (m *MultiStrategy) SetStatistic(statistic StatisticHandler) {
+	m.statistic = statistic
+}
+
+// AddStrategy adds a strategy with a share of the total capital, e.g. 0.5 for 50%.
+// The returned sleeve holds a default backtest, whose portfolio and exchange can be replaced.
+func (m *MultiStrategy) AddStrategy(name string, allocation float64, strategy StrategyHandler) *Sleeve {
+	backtest := New()
+	backtest.SetStrategy(strategy)
+
+	sleeve := &Sleeve{name: name, allocation: allocation, backtest: backtest}
+	m.sleeves = append(m.sleeves, sleeve)
+	return sleeve
+}
+
+// Sleeves returns all sleeves of the backtest.
+func (m *MultiStrategy) Sleeves() []*Sleeve {
+	return m.sleeves
+}
+
+// Sleeve returns the sleeve of a strategy by name.
+func (m *MultiStrategy) Sleeve(name string) (*Sleeve, bool) {
+	for _, s := range m.sleeves {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Stats returns the combined statistic of all sleeves.
+func (m *MultiStrategy) Stats() StatisticHandler {
+	return m.statistic
+}
+
+// Run starts the backtest. Every data event is processed by all sleeves in the order they were added.
+func (m *MultiStrategy) Run() error {
+	return m.RunContext(context.Background())
+}
+
+// RunContext starts the backtest, which stops when the context is cancelled.
+// A cancelled backtest returns the error of the context with the partial results.
+func (m *MultiStrategy) RunContext(ctx context.Context) error {
+	if err := m.setup(); err != nil {
+		return err
+	}
+
+	// number of transactions of each sleeve already tracked by the combined statistic
+	tracked := make([]int, len(m.sleeves))
+
+	// the error of a cancelled context
+	var cancelled error
+
+	for {
+		if cancelled = ctx.Err(); cancelled != nil {
+			break
+		}
+
+		data, ok := m.data.Next()
+		if !ok {
+			break
+		}
+
+		for i, s := range m.sleeves {
+			s.backtest.enqueue(data, nil)
+			if err := s.backtest.process(); err != nil {
+				return err
+			}
+
+			transactions := s.backtest.statistic.Transactions()
+			for _, fill := range transactions[tracked[i]:] {
+				m.statistic.TrackTransaction(fill)
+			}
+			tracked[i] = len(transactions)
+		}
+
+		m.statistic.Update(data, m.combined)
+		m.statistic.TrackEvent(data)
+	}
+
+	for _, s := range m.sleeves {
+		if err := s.backtest.teardown(); err != nil {
+			return err
+		}
+	}
+
+	return cancelled
+}
+
+// Reset the backtest into a clean state with loaded data.
+func (m *MultiStrategy) Reset() error {
+	m.data.Reset()
+	for _, s := range m.sleeves {
+		s.backtest.resetRun()
+	}
+	return m.statistic.Reset()
+}
+
+// PrintResult prints the combined result and the result of each sleeve.
+func (m *MultiStrategy) PrintResult() {
+	total, _ := m.statistic.TotalEquityReturn()
+	fmt.Printf("Combined: return %.4f max drawdown %.4f\n", total, m.statistic.MaxDrawdown())
+
+	for _, s := range m.sleeves {
+		r, _ := s.Stats().TotalEquityReturn()
+		fmt.Printf("%s (%.2f%%): return %.4f max drawdown %.4f transactions %d\n",
+			s.name, s.allocation*100, r, s.Stats().MaxDrawdown(), len(s.Stats().Transactions()))
+	}
+}
+
+// setup checks the allocations and prepares the backtest of every sleeve.
+func (m *MultiStrategy) setup() error {
+	if m.data == nil {
+		return errors.New("could not run multi strategy backtest, no data set")
+	}
+	if len(m.sleeves) == 0 {
+		return errors.New("could not run multi strategy backtest, no strategy added")
+	}
+
+	var sum float64
+	for _, s := range m.sleeves {
+		if s.allocation <= 0 {
+			return fmt.Errorf("invalid allocation %v of strategy %s", s.allocation, s.name)
+		}
+		sum += s.allocation
+	}
+	if sum > 1+1e-9 {
+		return fmt.Errorf("allocations sum up to %v, more than the total capital", sum)
+	}
+
+	for _, s := range m.sleeves {
+		s.backtest.SetData(m.data)
+		s.backtest.portfolio.SetInitialCash(m.initialCash * s.allocation)
+		if err := s.backtest.setup(); err != nil {
+			return err
+		}
+	}
+
+	m.combined = &combinedPortfolio{Portfolio: &Portfolio{}, sleeves: m.sleeves}
+	// the unallocated capital is held as cash
+	m.combined.SetInitialCash(m.initialCash * (1 - sum))
+	m.combined.SetCash(m.combined.InitialCash())
+
+	return nil
+}
+
+// combinedPortfolio sums up the values of the portfolios of all sleeves.
+type combinedPortfolio struct {
+	*Portfolio
+	sleeves []*Sleeve
+}
+
+// Value returns the summed value of all sleeves and the unallocated cash.
+func (c *combinedPortfolio) Value() float64 {
+	value := c.Portfolio.Cash()
+	for _, s := range c.sleeves {
+		value += s.backtest.portfolio.Value()
+	}
+	return value
+}
+
+// CashFlows returns the cash flows of all sleeves sorted by time.
+func (c *combinedPortfolio) CashFlows() []CashFlow {
+	var flows []CashFlow
+	for _, s := range c.sleeves {
+		if cf, ok := s.backtest.portfolio.(CashFlower); ok {
+			flows = append(flows, cf.CashFlows()...)
+		}
+	}
+	sortCashFlows(flows)
+	return flows
+}
@@ -0,0 +1,71 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestMultiStrategyRun(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(10, 10, 12, 12))
+
+	multi := NewMultiStrategy(100000)
+	multi.SetData(data)
+	buyer := multi.AddStrategy("buyer", 0.6, &buyOnceStrategy{Strategy: NewStrategy("buyer")})
+	idle := multi.AddStrategy("idle", 0.3, &countingStrategy{Strategy: NewStrategy("idle").SetWarmUp(10)})
+
+	if err := multi.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the default size buys 100 shares for 1000
+	buyerValue := buyer.Backtest().portfolio.Value()
+	if buyerValue != 60200 {
+		t.Errorf("Run(): expected buyer value %v, actual %v", 60200.0, buyerValue)
+	}
+	idleValue := idle.Backtest().portfolio.Value()
+	if idleValue != 30000 {
+		t.Errorf("Run(): expected idle value %v, actual %v", 30000.0, idleValue)
+	}
+
+	// the combined equity includes the unallocated cash
+	total, _ := multi.Stats().TotalEquityReturn()
+	if total != 0.002 {
+		t.Errorf("Run(): expected combined return %v, actual %v", 0.002, total)
+	}
+	if len(multi.Stats().Transactions()) != 1 {
+		t.Errorf("Run(): expected 1 combined transaction, actual %v", len(multi.Stats().Transactions()))
+	}
+
+	if s, ok := multi.Sleeve("idle"); !ok || s.Allocation() != 0.3 {
+		t.Errorf("Sleeve(): expected idle sleeve, actual %#v %v", s, ok)
+	}
+}
+
+func TestMultiStrategyAllocation(t *testing.T) {
+	// testCases is a table for testing the allocation check
+	var testCases = []struct {
+		msg         string
+		allocations []float64
+		expErr      bool
+	}{
+		{"full allocation:", []float64{0.5, 0.5}, false},
+		{"over allocation:", []float64{0.7, 0.5}, true},
+		{"zero allocation:", []float64{0.5, 0}, true},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(10))
+
+		multi := NewMultiStrategy(100000)
+		multi.SetData(data)
+		for _, a := range tc.allocations {
+			multi.AddStrategy("test", a, &buyOnceStrategy{Strategy: NewStrategy("test")})
+		}
+
+		err := multi.Run()
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Run(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+}
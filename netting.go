@@ -0,0 +1,75 @@
+package gobacktest
+
+// OppositePolicy defines how a signal against the direction of an existing position is handled.
+type OppositePolicy int
+
+// different policies for opposite signals
+const (
+	// ReverseOnOpposite closes the existing position and opens a new one in the signal direction
+	ReverseOnOpposite OppositePolicy = iota // 0
+	// CloseOnOpposite only closes the existing position
+	CloseOnOpposite
+	// IgnoreOpposite ignores the signal, the position is kept
+	IgnoreOpposite
+)
+
+// Netting translates desired-direction signals into orders for the existing position of a symbol.
+// A signal in the direction of an existing position is ignored, unless Pyramiding is set.
+// Exit signals close the position and are ignored without a position, hold signals are always ignored.
+// Netting works on the net position and is meant for the default netting account mode.
+type Netting struct {
+	Pyramiding bool
+	Opposite   OppositePolicy
+}
+
+// SetNetting sets the translation of signals into orders. Without netting
+// every signal is sized as is, which is the default.
+func (p *Portfolio) SetNetting(netting *Netting) {
+	p.netting = netting
+}
+
+// Netting returns the signal netting of the portfolio.
+func (p Portfolio) Netting() (*Netting, bool) {
+	if p.netting == nil {
+		return nil, false
+	}
+	return p.netting, true
+}
+
+// net returns the direction of the order for a signal direction, the qty of an existing position
+// to add on a reversal and false if the signal should be ignored.
+func (n *Netting) net(dir Direction, symbol string, pf Investor) (Direction, float64, bool) {
+	var qty float64
+	if pos, ok := pf.IsInvested(symbol); ok {
+		qty = pos.qty
+	}
+
+	switch dir {
+	case HLD:
+		return dir, 0, false
+	case EXT:
+		return dir, 0, qty != 0
+	}
+
+	// no position, the signal opens a new one
+	if qty == 0 {
+		return dir, 0, true
+	}
+
+	// signal in the direction of the position
+	if (dir == BOT && qty > 0) || (dir == SLD && qty < 0) {
+		return dir, 0, n.Pyramiding
+	}
+
+	// signal against the direction of the position
+	switch n.Opposite {
+	case CloseOnOpposite:
+		return EXT, 0, true
+	case IgnoreOpposite:
+		return dir, 0, false
+	}
+	if qty < 0 {
+		qty = -qty
+	}
+	return dir, qty, true
+}
@@ -0,0 +1,65 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestNettingOnSignal(t *testing.T) {
+	data := &Data{
+		latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 10},
+		},
+	}
+
+	// testCases is a table for testing the netting of signals
+	var testCases = []struct {
+		msg     string
+		netting *Netting
+		pos     float64
+		dir     Direction
+		expDir  Direction
+		expQty  float64
+		expNone bool
+	}{
+		{"long signal without position:", &Netting{}, 0, BOT, BOT, 100, false},
+		{"duplicate long signal is ignored:", &Netting{}, 100, BOT, BOT, 0, true},
+		{"duplicate long signal with pyramiding:", &Netting{Pyramiding: true}, 100, BOT, BOT, 100, false},
+		{"short signal reverses long position:", &Netting{}, 50, SLD, SLD, 150, false},
+		{"long signal reverses short position:", &Netting{}, -50, BOT, BOT, 150, false},
+		{"short signal closes long position:", &Netting{Opposite: CloseOnOpposite}, 50, SLD, SLD, 50, false},
+		{"short signal is ignored on long position:", &Netting{Opposite: IgnoreOpposite}, 50, SLD, SLD, 0, true},
+		{"exit signal closes position:", &Netting{}, -50, EXT, BOT, 50, false},
+		{"exit signal without position is ignored:", &Netting{}, 0, EXT, EXT, 0, true},
+		{"hold signal is ignored:", &Netting{}, 50, HLD, HLD, 0, true},
+	}
+
+	for _, tc := range testCases {
+		p := &Portfolio{
+			initialCash:       100000,
+			cash:              100000,
+			sizeManager:       &Size{DefaultSize: 100, DefaultValue: 100000},
+			riskManager:       &Risk{},
+			allowNegativeCash: true,
+		}
+		p.SetNetting(tc.netting)
+		if tc.pos != 0 {
+			p.holdings = map[string]Position{"TEST.DE": {symbol: "TEST.DE", qty: tc.pos}}
+		}
+
+		signal := &Signal{Event: Event{symbol: "TEST.DE"}, direction: tc.dir}
+		order, err := p.OnSignal(signal, data)
+		if err != nil {
+			t.Errorf("%v OnSignal(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		if tc.expNone {
+			if order != nil {
+				t.Errorf("%v OnSignal(): expected no order, actual %#v", tc.msg, order)
+			}
+			continue
+		}
+		if order == nil || order.Direction() != tc.expDir || order.Qty() != tc.expQty {
+			t.Errorf("%v OnSignal(): \nexpected %v %v, \nactual %#v", tc.msg, tc.expDir, tc.expQty, order)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gobacktest
+
+// NewsEvent declares a news event with a headline, its source and a sentiment score.
+type NewsEvent interface {
+	CustomEvent
+	Headline() string
+	Source() string
+	Sentiment() float64
+}
+
+// News declares a custom event for a timestamped news item of a symbol.
+// The sentiment ranges from -1.0 (negative) to 1.0 (positive).
+// News are added to a backtest with AddEvents and received by a strategy
+// implementing CustomEventHandler.
+type News struct {
+	Event
+	headline  string
+	source    string
+	sentiment float64
+}
+
+// NewNews creates a news event.
+func NewNews(e Event, headline, source string, sentiment float64) *News {
+	return &News{Event: e, headline: headline, source: source, sentiment: sentiment}
+}
+
+// Kind returns the kind of the custom event.
+func (n News) Kind() string {
+	return "news"
+}
+
+// Headline returns the headline of the news.
+func (n News) Headline() string {
+	return n.headline
+}
+
+// Source returns the source of the news, e.g. the news agency.
+func (n News) Source() string {
+	return n.source
+}
+
+// Sentiment returns the sentiment score of the news.
+func (n News) Sentiment() float64 {
+	return n.sentiment
+}
+
+// RecordValues implements ValueRecorder.
+func (n News) RecordValues() map[string]float64 {
+	return map[string]float64{"sentiment": n.sentiment}
+}
+
+// RecordText implements TextRecorder.
+func (n News) RecordText() map[string]string {
+	return map[string]string{"headline": n.headline, "source": n.source}
+}
+
+// decodeNews restores a news event from its record.
+func decodeNews(r EventRecord) (CustomEvent, error) {
+	return NewNews(r.baseEvent(), r.Text["headline"], r.Text["source"], r.Values["sentiment"]), nil
+}
+
+func init() {
+	RegisterEvent("news", decodeNews)
+}
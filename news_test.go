@@ -0,0 +1,59 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newsStrategy buys on positive news.
+type newsStrategy struct {
+	*Strategy
+}
+
+func (s *newsStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	return nil, nil
+}
+
+func (s *newsStrategy) OnEvent(event CustomEvent) ([]SignalEvent, error) {
+	if news, ok := event.(NewsEvent); ok && news.Sentiment() > 0.5 {
+		return []SignalEvent{&Signal{Event: Event{timestamp: news.Time(), symbol: news.Symbol()}, direction: BOT}}, nil
+	}
+	return nil, nil
+}
+
+func TestBacktestNews(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&newsStrategy{Strategy: NewStrategy("news")})
+	test.AddEvents(
+		NewNews(Event{timestamp: day, symbol: "TEST.DE"}, "Guidance cut", "wire", -0.8),
+		NewNews(Event{timestamp: day.AddDate(0, 0, 1), symbol: "TEST.DE"}, "Profit up", "wire", 0.6),
+	)
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	if fills := len(test.statistic.Transactions()); fills != 1 {
+		t.Errorf("Run(): expected 1 fill, actual %v", fills)
+	}
+}
+
+func TestNewsRecord(t *testing.T) {
+	ts := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	news := NewNews(Event{timestamp: ts, symbol: "TEST", eventID: 2}, "Profit up", "wire", 0.6)
+
+	e, err := NewEventRecord(news).Event()
+	if err != nil {
+		t.Fatalf("Event(): unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(e, news) {
+		t.Errorf("Event(): expected %#v, actual %#v", news, e)
+	}
+}
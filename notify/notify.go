@@ -0,0 +1,169 @@
+// Package notify posts events of a backtest, e.g. fills, risk limit breaches and the summary
+// of a finished run, to webhooks, Slack or Telegram. It is meant for paper and live trading,
+// where the trades happen unattended.
+//
+//	n := &notify.Notifier{
+//		Senders: []notify.Sender{&notify.Slack{URL: slackWebhook}},
+//		Events:  []string{notify.KindHalt, notify.KindDone},
+//	}
+//	test.Use(n.Middleware())
+//	err := test.Run()
+//	n.Done("sma-cross", test.Stats(), err)
+package notify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// kinds of the notifications
+const (
+	KindFill      = "fill"
+	KindHalt      = "halt"
+	KindRejection = "rejection"
+	KindDone      = "done"
+)
+
+// Message is a notification.
+type Message struct {
+	Kind   string             `json:"kind"`
+	Time   time.Time          `json:"time"`
+	Symbol string             `json:"symbol,omitempty"`
+	Text   string             `json:"text"`
+	Values map[string]float64 `json:"values,omitempty"`
+}
+
+// Sender delivers a message, e.g. to a chat.
+type Sender interface {
+	Send(context.Context, Message) error
+}
+
+// SenderFunc is a function delivering a message.
+type SenderFunc func(context.Context, Message) error
+
+// Send implements Sender.
+func (f SenderFunc) Send(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// Notifier sends the selected kinds of events to all senders. The messages are sent
+// synchronously, a failed delivery never stops the run.
+type Notifier struct {
+	Senders []Sender
+	Events  []string      // kinds to notify, all if empty
+	Timeout time.Duration // of a single delivery, defaults to 10 seconds
+	// OnError receives the errors of the deliveries, which are dropped otherwise
+	OnError func(error)
+}
+
+// Middleware returns the middleware notifying about the processed events.
+func (n *Notifier) Middleware() gbt.Middleware {
+	return func(next gbt.EventFunc) gbt.EventFunc {
+		return func(e gbt.EventHandler) error {
+			if err := next(e); err != nil {
+				return err
+			}
+			if m, ok := message(e); ok {
+				n.Notify(m)
+			}
+			return nil
+		}
+	}
+}
+
+// Done notifies about a finished run with the summary of its statistics or its error.
+func (n *Notifier) Done(name string, stats gbt.StatisticHandler, err error) {
+	m := Message{Kind: KindDone, Time: time.Now()}
+	if err != nil {
+		m.Text = fmt.Sprintf("%s failed: %v", name, err)
+		n.Notify(m)
+		return
+	}
+
+	ret, _ := stats.TotalEquityReturn()
+	m.Values = map[string]float64{
+		"return":      ret,
+		"maxDrawdown": stats.MaxDrawdown(),
+		"sharpe":      stats.SharpRatio(0),
+		"trades":      float64(len(stats.Transactions())),
+	}
+	// the custom metrics do not replace the metrics above
+	if c, ok := stats.(gbt.CustomMetricer); ok {
+		for k, v := range c.CustomMetrics() {
+			if _, ok := m.Values[k]; !ok {
+				m.Values[k] = v
+			}
+		}
+	}
+	// undefined values can not be encoded as json
+	for k, v := range m.Values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			delete(m.Values, k)
+		}
+	}
+	m.Text = fmt.Sprintf("%s finished: return %.2f%%, max drawdown %.2f%%, sharpe %.2f, %d trades",
+		name, ret*100, stats.MaxDrawdown()*100, stats.SharpRatio(0), len(stats.Transactions()))
+	n.Notify(m)
+}
+
+// Notify sends a message of a selected kind to all senders.
+func (n *Notifier) Notify(m Message) {
+	if !n.selected(m.Kind) {
+		return
+	}
+
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	for _, s := range n.Senders {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := s.Send(ctx, m)
+		cancel()
+		if err != nil && n.OnError != nil {
+			n.OnError(fmt.Errorf("could not send %s notification: %v", m.Kind, err))
+		}
+	}
+}
+
+// selected returns if a kind is notified.
+func (n *Notifier) selected(kind string) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, k := range n.Events {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// message returns the notification of an event, false if the event is not notified.
+func message(e gbt.EventHandler) (Message, bool) {
+	m := Message{Time: e.Time(), Symbol: e.Symbol()}
+
+	switch e := e.(type) {
+	case gbt.FillEvent:
+		m.Kind = KindFill
+		m.Text = fmt.Sprintf("filled %s %v %s @ %v, cost %v", e.Direction(), e.Qty(), e.Symbol(), e.Price(), e.Cost())
+		m.Values = map[string]float64{"qty": e.Qty(), "price": e.Price(), "cost": e.Cost()}
+	case gbt.TradingHaltedEvent:
+		m.Kind = KindHalt
+		m.Text = "trading halted: " + e.Reason()
+		if e.Flatten() {
+			m.Text += ", closing all positions"
+		}
+	case gbt.RejectionEvent:
+		m.Kind = KindRejection
+		m.Text = fmt.Sprintf("order for %s rejected: %s", e.Symbol(), e.Reason())
+	default:
+		return m, false
+	}
+
+	return m, true
+}
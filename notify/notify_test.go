@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// recorder is a sender recording the messages.
+type recorder struct {
+	messages []Message
+}
+
+func (r *recorder) Send(ctx context.Context, m Message) error {
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+// kinds returns the number of messages by kind.
+func (r *recorder) kinds() map[string]int {
+	kinds := make(map[string]int)
+	for _, m := range r.messages {
+		kinds[m.Kind]++
+	}
+	return kinds
+}
+
+// newTestBacktest builds a backtest of the test data, which trades and breaches its drawdown limit.
+func newTestBacktest(t *testing.T) *gbt.Backtest {
+	d := &data.BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := d.Load([]string{"SDF.DE"}); err != nil {
+		t.Fatal(err)
+	}
+	var stream []gbt.DataEvent
+	for _, e := range d.Stream() {
+		if e.Time().Year() >= 2016 && e.Time().Before(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)) {
+			stream = append(stream, e)
+		}
+	}
+	d.SetStream(stream)
+
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetSizeManager(&gbt.Size{DefaultSize: 100, DefaultValue: 10000})
+	portfolio.SetRiskRules(&gbt.DrawdownHalt{MaxDrawdown: 0.001})
+
+	test := gbt.New()
+	test.SetSymbols([]string{"SDF.DE"})
+	test.SetData(d)
+	test.SetPortfolio(portfolio)
+	test.SetStrategy(strategy.MovingAverageCross(5, 20))
+	return test
+}
+
+func TestNotifierMiddleware(t *testing.T) {
+	// testCases is a table for testing the selection of the notified events
+	var testCases = []struct {
+		msg    string
+		events []string
+		expect []string
+	}{
+		{"all events:", nil, []string{KindFill, KindHalt, KindRejection, KindDone}},
+		{"selected events:", []string{KindHalt, KindDone}, []string{KindHalt, KindDone}},
+	}
+
+	for _, tc := range testCases {
+		rec := &recorder{}
+		n := &Notifier{Senders: []Sender{rec}, Events: tc.events}
+
+		test := newTestBacktest(t)
+		test.Use(n.Middleware())
+		err := test.Run()
+		n.Done("test", test.Stats(), err)
+
+		kinds := rec.kinds()
+		for _, kind := range tc.expect {
+			if kinds[kind] == 0 {
+				t.Errorf("%v\nexpected %s notifications, actual %v", tc.msg, kind, kinds)
+			}
+		}
+		if len(kinds) != len(tc.expect) {
+			t.Errorf("%v\nexpected only the kinds %v, actual %v", tc.msg, tc.expect, kinds)
+		}
+
+		last := rec.messages[len(rec.messages)-1]
+		if last.Kind != KindDone || !strings.HasPrefix(last.Text, "test finished: return") || last.Values["trades"] == 0 {
+			t.Errorf("%v\nexpected the summary as last notification, actual %+v", tc.msg, last)
+		}
+	}
+}
+
+func TestNotifierErrors(t *testing.T) {
+	var errs []error
+	rec := &recorder{}
+	n := &Notifier{
+		Senders: []Sender{
+			SenderFunc(func(ctx context.Context, m Message) error { return errors.New("offline") }),
+			rec,
+		},
+		OnError: func(err error) { errs = append(errs, err) },
+	}
+
+	n.Done("test", nil, errors.New("no data"))
+
+	if len(errs) != 1 || errs[0].Error() != "could not send done notification: offline" {
+		t.Errorf("Notify(): expected the error of the failed sender, actual %v", errs)
+	}
+	if len(rec.messages) != 1 || rec.messages[0].Text != "test failed: no data" {
+		t.Errorf("Notify(): expected the failed run to be delivered by the other sender, actual %v", rec.messages)
+	}
+}
+
+func TestNotifierCustomMetrics(t *testing.T) {
+	calc := gbt.MetricCalculatorFunc(func(equity []gbt.EquityPoint, trades []gbt.FillEvent) float64 {
+		return float64(len(equity))
+	})
+	if err := gbt.RegisterMetric("points", calc); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("points")
+	// a custom metric does not override a built-in value
+	if err := gbt.RegisterMetric("trades", calc); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("trades")
+
+	rec := &recorder{}
+	n := &Notifier{Senders: []Sender{rec}, Events: []string{KindDone}}
+
+	test := newTestBacktest(t)
+	err := test.Run()
+	n.Done("test", test.Stats(), err)
+
+	values := rec.messages[len(rec.messages)-1].Values
+	if values["points"] == 0 || values["trades"] == values["points"] {
+		t.Errorf("Done(): expected the custom metric besides the built-in values, actual %v", values)
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Webhook posts the message as json to a url.
+type Webhook struct {
+	URL    string
+	Header http.Header  // additional headers, e.g. for authorization
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// Send implements Sender.
+func (w *Webhook) Send(ctx context.Context, m Message) error {
+	return post(ctx, w.Client, w.URL, w.Header, m)
+}
+
+// Slack posts the text of the message to a Slack incoming webhook.
+type Slack struct {
+	URL    string
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// Send implements Sender.
+func (s *Slack) Send(ctx context.Context, m Message) error {
+	return post(ctx, s.Client, s.URL, nil, map[string]string{"text": m.Text})
+}
+
+// Telegram sends the text of the message to a chat with the Telegram bot api.
+type Telegram struct {
+	Token   string
+	ChatID  string
+	BaseURL string       // defaults to https://api.telegram.org
+	Client  *http.Client // defaults to http.DefaultClient
+}
+
+// Send implements Sender.
+func (t *Telegram) Send(ctx context.Context, m Message) error {
+	base := t.BaseURL
+	if base == "" {
+		base = "https://api.telegram.org"
+	}
+	url := strings.TrimSuffix(base, "/") + "/bot" + t.Token + "/sendMessage"
+	err := post(ctx, t.Client, url, nil, map[string]string{"chat_id": t.ChatID, "text": m.Text})
+	if err != nil && t.Token != "" {
+		// the token is part of the url, keep it out of the error
+		return errors.New(strings.ReplaceAll(err.Error(), t.Token, "***"))
+	}
+	return err
+}
+
+// post sends v as json and checks the status of the response.
+func post(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, values := range header {
+		for _, value := range values {
+			req.Header.Add(k, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSenders(t *testing.T) {
+	var path, auth string
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		if strings.HasSuffix(path, "/fail") || strings.Contains(path, "bad-token") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	m := Message{Kind: KindHalt, Time: time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC), Text: "trading halted: max drawdown"}
+
+	// testCases is a table for testing the senders
+	var testCases = []struct {
+		msg     string
+		sender  Sender
+		expPath string
+		expBody map[string]interface{}
+		expErr  bool
+	}{
+		{"webhook:",
+			&Webhook{URL: ts.URL + "/hook", Header: http.Header{"Authorization": {"Bearer secret"}}},
+			"/hook",
+			map[string]interface{}{"kind": "halt", "time": "2017-06-01T00:00:00Z", "text": "trading halted: max drawdown"},
+			false,
+		},
+		{"slack:",
+			&Slack{URL: ts.URL + "/services/T0/B0/X0"},
+			"/services/T0/B0/X0",
+			map[string]interface{}{"text": "trading halted: max drawdown"},
+			false,
+		},
+		{"telegram:",
+			&Telegram{Token: "123:abc", ChatID: "42", BaseURL: ts.URL},
+			"/bot123:abc/sendMessage",
+			map[string]interface{}{"chat_id": "42", "text": "trading halted: max drawdown"},
+			false,
+		},
+		{"failed delivery:",
+			&Webhook{URL: ts.URL + "/fail"},
+			"/fail",
+			map[string]interface{}{"kind": "halt", "time": "2017-06-01T00:00:00Z", "text": "trading halted: max drawdown"},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.sender.Send(context.Background(), m)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v\nSend(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+		}
+		if path != tc.expPath || !equalBody(body, tc.expBody) {
+			t.Errorf("%v\nSend(): expected %v %v, actual %v %v", tc.msg, tc.expPath, tc.expBody, path, body)
+		}
+	}
+	if auth != "" {
+		t.Errorf("Send(): expected the header of the webhook only on its request, actual %q", auth)
+	}
+
+	// the token of a failed telegram delivery is not part of the error
+	err := (&Telegram{Token: "bad-token", ChatID: "42", BaseURL: "http://127.0.0.1:1"}).Send(context.Background(), m)
+	if err == nil || strings.Contains(err.Error(), "bad-token") {
+		t.Errorf("Send(): expected an error without the token, actual %v", err)
+	}
+}
+
+// equalBody compares two decoded json bodies.
+func equalBody(a, b map[string]interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
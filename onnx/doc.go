@@ -0,0 +1,17 @@
+// Package onnx registers a model loader for ONNX models with the extension ".onnx", run by the
+// pure Go runtime of github.com/owulveryck/onnx-go, which needs no cgo. The runtime is not a
+// dependency of the module, the loader is built with the onnx tag, e.g.
+//
+//	go get github.com/owulveryck/onnx-go gorgonia.org/tensor
+//	go build -tags onnx
+//
+// The package is imported for its side effect, the strategy loads the model as any other
+// model of gobacktest,
+//
+//	import _ "github.com/dirkolbrich/gobacktest/onnx"
+//
+//	model, err := gobacktest.LoadModel("signal.onnx")
+//
+// The model takes a single float32 input of shape [1, n] and returns its first output as
+// feature vector, the operators supported are those of onnx-go.
+package onnx
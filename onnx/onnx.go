@@ -0,0 +1,84 @@
+//go:build onnx
+// +build onnx
+
+package onnx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/owulveryck/onnx-go"
+	"github.com/owulveryck/onnx-go/backend/x/gorgonnx"
+	"gorgonia.org/tensor"
+)
+
+func init() {
+	gbt.RegisterModelLoader(".onnx", Load)
+}
+
+// Model is an ONNX model on the graph of gorgonnx. The graph is not safe for concurrent
+// use, Predict runs one inference at a time.
+type Model struct {
+	mu      sync.Mutex
+	backend *gorgonnx.Graph
+	model   *onnx.Model
+}
+
+// Load loads an ONNX model file.
+func Load(path string) (gbt.Model, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load onnx model %s: %v", path, err)
+	}
+
+	backend := gorgonnx.NewGraph()
+	model := onnx.NewModel(backend)
+	if err := model.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("could not load onnx model %s: %v", path, err)
+	}
+	return &Model{backend: backend, model: model}, nil
+}
+
+// Predict runs the model on the features and returns its first output.
+func (m *Model) Predict(features []float64) ([]float64, error) {
+	input := make([]float32, len(features))
+	for i, f := range features {
+		input[i] = float32(f)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := tensor.New(tensor.WithShape(1, len(input)), tensor.WithBacking(input))
+	if err := m.model.SetInput(0, t); err != nil {
+		return nil, fmt.Errorf("could not set onnx model input: %v", err)
+	}
+	if err := m.backend.Run(); err != nil {
+		return nil, fmt.Errorf("could not run onnx model: %v", err)
+	}
+	outputs, err := m.model.GetOutputTensors()
+	if err != nil {
+		return nil, fmt.Errorf("could not read onnx model output: %v", err)
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("could not read onnx model output, no output")
+	}
+
+	switch data := outputs[0].Data().(type) {
+	case []float32:
+		output := make([]float64, len(data))
+		for i, v := range data {
+			output[i] = float64(v)
+		}
+		return output, nil
+	case []float64:
+		return append([]float64(nil), data...), nil
+	case float32:
+		return []float64{float64(data)}, nil
+	case float64:
+		return []float64{data}, nil
+	}
+	return nil, fmt.Errorf("could not read onnx model output of type %T", outputs[0].Data())
+}
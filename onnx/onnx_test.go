@@ -0,0 +1,54 @@
+//go:build onnx
+// +build onnx
+
+package onnx
+
+// The tests of the onnx tag run a model of testdata on the runtime of onnx-go, which is not
+// a dependency of the module, e.g.
+//
+//	go get github.com/owulveryck/onnx-go gorgonia.org/tensor
+//	go test -tags onnx ./onnx
+
+import (
+	"testing"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+func TestLoader(t *testing.T) {
+	// add.onnx adds [0.5, -1] to the input, written by testdata/add.py
+	model, err := gbt.LoadModel("testdata/add.onnx")
+	if err != nil {
+		t.Fatalf("LoadModel(): unexpected error %v", err)
+	}
+
+	// testCases is a table for testing the inference of the model
+	var testCases = []struct {
+		msg      string
+		features []float64
+		exp      []float64
+	}{
+		{"positive features:", []float64{1, 2}, []float64{1.5, 1}},
+		{"negative features:", []float64{-0.5, -3}, []float64{0, -4}},
+	}
+
+	for _, tc := range testCases {
+		output, err := model.Predict(tc.features)
+		if err != nil {
+			t.Fatalf("%v Predict(%v): unexpected error %v", tc.msg, tc.features, err)
+		}
+		if len(output) != len(tc.exp) {
+			t.Fatalf("%v Predict(%v): \nexpected %v, \nactual   %v", tc.msg, tc.features, tc.exp, output)
+		}
+		for i := range output {
+			if output[i] != tc.exp[i] {
+				t.Errorf("%v Predict(%v): \nexpected %v, \nactual   %v", tc.msg, tc.features, tc.exp, output)
+				break
+			}
+		}
+	}
+
+	if _, err := gbt.LoadModel("testdata/missing.onnx"); err == nil {
+		t.Errorf("LoadModel(): expected an error for a missing file")
+	}
+}
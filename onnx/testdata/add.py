@@ -0,0 +1,67 @@
+"""Writes add.onnx, the model of TestLoader of the onnx package.
+
+The model adds the constant b = [0.5, -1] to the input x of shape [1, 2] and returns the
+sum as y. The file is encoded by hand as protobuf, which needs neither the onnx package
+nor a runtime, e.g.
+
+    python3 onnx/testdata/add.py onnx/testdata/add.onnx
+"""
+import struct
+import sys
+
+FLOAT = 1  # TensorProto.FLOAT
+
+
+def varint(n):
+    out = b""
+    while True:
+        bits = n & 0x7F
+        n >>= 7
+        if n:
+            out += bytes([bits | 0x80])
+        else:
+            return out + bytes([bits])
+
+
+def field(number, value):
+    """Encodes an int as varint field and bytes or str as length delimited field."""
+    if isinstance(value, int):
+        return varint(number << 3) + varint(value)
+    if isinstance(value, str):
+        value = value.encode()
+    return varint(number << 3 | 2) + varint(len(value)) + value
+
+
+def tensor_type(name, dims):
+    dim = b"".join(field(1, field(1, d)) for d in dims)  # Dimension.dim_value
+    shape = field(2, dim)  # TensorProto.shape
+    tensor = field(1, FLOAT) + shape  # Tensor.elem_type
+    return field(1, name) + field(2, field(1, tensor))  # ValueInfoProto.name, .type
+
+
+def model():
+    node = field(1, "x") + field(1, "b") + field(2, "y") + field(3, "add") + field(4, "Add")
+    b = (
+        b"".join(field(1, d) for d in (1, 2))  # TensorProto.dims
+        + field(2, FLOAT)  # TensorProto.data_type
+        + field(4, struct.pack("<2f", 0.5, -1.0))  # TensorProto.float_data, packed
+        + field(8, "b")  # TensorProto.name
+    )
+    graph = (
+        field(1, node)  # GraphProto.node
+        + field(2, "add")  # GraphProto.name
+        + field(5, b)  # GraphProto.initializer
+        + field(11, tensor_type("x", (1, 2)))  # GraphProto.input
+        + field(12, tensor_type("y", (1, 2)))  # GraphProto.output
+    )
+    return (
+        field(1, 3)  # ModelProto.ir_version
+        + field(2, "gobacktest")  # ModelProto.producer_name
+        + field(7, graph)  # ModelProto.graph
+        + field(8, field(2, 9))  # ModelProto.opset_import, version 9
+    )
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1] if len(sys.argv) > 1 else "add.onnx", "wb") as f:
+        f.write(model())
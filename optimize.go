@@ -0,0 +1,274 @@
+package gobacktest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Param defines the range of a strategy parameter for the optimization.
+// A Step greater than 0 restricts the values to Min plus multiples of Step, e.g. 1 for integers.
+type Param struct {
+	Name string
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// Params holds a value for each parameter by name.
+type Params map[string]float64
+
+// Objective runs a backtest with the parameters and returns the score to maximise, e.g. the sharpe ratio.
+type Objective func(Params) (float64, error)
+
+// Trial is a single evaluation of the objective.
+type Trial struct {
+	Params Params
+	Score  float64
+}
+
+// OptimizationResult holds the best parameters found and all trials in the order of evaluation.
+type OptimizationResult struct {
+	Best    Params
+	Score   float64
+	Trials  []Trial
+	Stopped string // reason why the optimization stopped early, empty if it completed
+}
+
+// Optimizer searches the parameter space for the parameters with the highest score.
+type Optimizer interface {
+	Optimize([]Param, Objective) (OptimizationResult, error)
+}
+
+// Budget limits an optimization. MaxEvals caps the number of evaluations of the objective,
+// MaxDuration the total time. Patience stops the optimization early, after that many evaluations
+// without an improvement of the best score by more than Tolerance. Zero values disable a limit.
+type Budget struct {
+	MaxEvals    int
+	MaxDuration time.Duration
+	Patience    int
+	Tolerance   float64
+	Context     context.Context // a cancelled context stops the optimization
+}
+
+// search evaluates the objective within the budget and keeps track of all trials.
+type search struct {
+	Budget
+	space     []Param
+	objective Objective
+	start     time.Time
+	result    OptimizationResult
+	cache     map[string]float64
+	stale     int
+}
+
+// newSearch creates a search over a parameter space.
+func newSearch(space []Param, objective Objective, budget Budget) (*search, error) {
+	if len(space) == 0 {
+		return nil, errors.New("could not optimize, no parameters given")
+	}
+	for _, p := range space {
+		if p.Max < p.Min {
+			return nil, errors.New("could not optimize, invalid range of parameter " + p.Name)
+		}
+	}
+
+	return &search{
+		Budget:    budget,
+		space:     space,
+		objective: objective,
+		start:     time.Now(),
+		result:    OptimizationResult{Score: math.Inf(-1)},
+		cache:     make(map[string]float64),
+	}, nil
+}
+
+// decode maps a point of the unit cube to the parameters.
+func (s *search) decode(u []float64) Params {
+	params := make(Params, len(s.space))
+	for i, p := range s.space {
+		x := math.Min(math.Max(u[i], 0), 1)
+		value := p.Min + x*(p.Max-p.Min)
+		if p.Step > 0 {
+			value = p.Min + math.Round((value-p.Min)/p.Step)*p.Step
+			if value > p.Max {
+				value -= p.Step
+			}
+		}
+		params[p.Name] = math.Round(value*math.Pow10(DP)) / math.Pow10(DP)
+	}
+	return params
+}
+
+// key returns a unique key of the parameters.
+func (s *search) key(params Params) string {
+	values := make([]string, len(s.space))
+	for i, p := range s.space {
+		values[i] = strconv.FormatFloat(params[p.Name], 'f', -1, 64)
+	}
+	return strings.Join(values, ",")
+}
+
+// evaluate returns the score of a point of the unit cube. Already evaluated parameters
+// are taken from the cache without using the budget.
+func (s *search) evaluate(u []float64) (float64, error) {
+	params := s.decode(u)
+	key := s.key(params)
+	if score, ok := s.cache[key]; ok {
+		return score, nil
+	}
+
+	score, err := s.objective(params)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(score) {
+		score = math.Inf(-1)
+	}
+	s.cache[key] = score
+	s.result.Trials = append(s.result.Trials, Trial{Params: params, Score: score})
+
+	if score > s.result.Score+s.Tolerance || s.result.Best == nil {
+		s.stale = 0
+	} else {
+		s.stale++
+	}
+	if score > s.result.Score || s.result.Best == nil {
+		s.result.Best = params
+		s.result.Score = score
+	}
+
+	return score, nil
+}
+
+// done checks the budget and records the reason for an early stop.
+func (s *search) done() bool {
+	switch {
+	case s.MaxEvals > 0 && len(s.result.Trials) >= s.MaxEvals:
+		s.result.Stopped = "max evaluations reached"
+	case s.MaxDuration > 0 && time.Since(s.start) >= s.MaxDuration:
+		s.result.Stopped = "max duration reached"
+	case s.Context != nil && s.Context.Err() != nil:
+		s.result.Stopped = "cancelled"
+	case s.Patience > 0 && s.stale >= s.Patience:
+		s.result.Stopped = "no improvement"
+	default:
+		return false
+	}
+	return true
+}
+
+// GridSearch evaluates all combinations of the parameter values. Parameters without
+// a Step are sampled at Points evenly spaced values, which defaults to 10.
+type GridSearch struct {
+	Budget
+	Points int
+}
+
+// Optimize evaluates the grid of the parameter space.
+func (g *GridSearch) Optimize(space []Param, objective Objective) (OptimizationResult, error) {
+	s, err := newSearch(space, objective, g.Budget)
+	if err != nil {
+		return OptimizationResult{}, err
+	}
+
+	axes := g.axes(space)
+	index := make([]int, len(space))
+	for {
+		u := make([]float64, len(space))
+		for i := range space {
+			u[i] = axes[i][index[i]]
+		}
+		if _, err := s.evaluate(u); err != nil {
+			return s.result, err
+		}
+		if s.done() {
+			return s.result, nil
+		}
+
+		// advance to the next combination
+		i := 0
+		for ; i < len(index); i++ {
+			index[i]++
+			if index[i] < len(axes[i]) {
+				break
+			}
+			index[i] = 0
+		}
+		if i == len(index) {
+			return s.result, nil
+		}
+	}
+}
+
+// Grid returns all combinations of the parameter values in the order of Optimize,
+// e.g. to distribute the evaluations.
+func (g *GridSearch) Grid(space []Param) ([]Params, error) {
+	s, err := newSearch(space, nil, g.Budget)
+	if err != nil {
+		return nil, err
+	}
+	axes := g.axes(space)
+
+	var grid []Params
+	index := make([]int, len(space))
+	for {
+		u := make([]float64, len(space))
+		for i := range space {
+			u[i] = axes[i][index[i]]
+		}
+		grid = append(grid, s.decode(u))
+
+		// advance to the next combination
+		i := 0
+		for ; i < len(index); i++ {
+			index[i]++
+			if index[i] < len(axes[i]) {
+				break
+			}
+			index[i] = 0
+		}
+		if i == len(index) {
+			return grid, nil
+		}
+	}
+}
+
+// axes returns the unit coordinates of the grid for each parameter.
+func (g *GridSearch) axes(space []Param) [][]float64 {
+	axes := make([][]float64, len(space))
+	for i, p := range space {
+		n := g.Points
+		if n <= 0 {
+			n = 10
+		}
+		if p.Step > 0 {
+			n = int(math.Floor((p.Max-p.Min)/p.Step+1e-9)) + 1
+		}
+		if n == 1 || p.Max == p.Min {
+			axes[i] = []float64{0}
+			continue
+		}
+		for j := 0; j < n; j++ {
+			axes[i] = append(axes[i], float64(j)/float64(n-1))
+		}
+	}
+
+	return axes
+}
+
+// Top returns the n trials with the highest score.
+func (r OptimizationResult) Top(n int) []Trial {
+	sorted := append([]Trial{}, r.Trials...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
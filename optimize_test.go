@@ -0,0 +1,141 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// quadratic is a test objective with its maximum at x 3, y -1.
+func quadratic(p Params) (float64, error) {
+	return -math.Pow(p["x"]-3, 2) - math.Pow(p["y"]+1, 2), nil
+}
+
+var quadraticSpace = []Param{
+	{Name: "x", Min: 0, Max: 10, Step: 1},
+	{Name: "y", Min: -5, Max: 5},
+}
+
+func TestGridSearchOptimize(t *testing.T) {
+	grid := &GridSearch{Points: 11}
+	result, err := grid.Optimize(quadraticSpace, quadratic)
+	if err != nil {
+		t.Fatalf("Optimize(): unexpected error %v", err)
+	}
+	if len(result.Trials) != 121 {
+		t.Errorf("Optimize(): expected 121 trials, actual %v", len(result.Trials))
+	}
+	if !reflect.DeepEqual(result.Best, Params{"x": 3, "y": -1}) || result.Score != 0 {
+		t.Errorf("Optimize(): expected best x 3 y -1, actual %v %v", result.Best, result.Score)
+	}
+	if top := result.Top(2); len(top) != 2 || top[0].Score != 0 {
+		t.Errorf("Top(): expected best trial first, actual %v", top)
+	}
+}
+
+func TestGridSearchGrid(t *testing.T) {
+	grid := &GridSearch{Points: 3}
+	space := []Param{
+		{Name: "x", Min: 0, Max: 1},
+		{Name: "n", Min: 1, Max: 2, Step: 1},
+	}
+	params, err := grid.Grid(space)
+	if err != nil {
+		t.Fatalf("Grid(): unexpected error %v", err)
+	}
+	expected := []Params{
+		{"x": 0, "n": 1}, {"x": 0.5, "n": 1}, {"x": 1, "n": 1},
+		{"x": 0, "n": 2}, {"x": 0.5, "n": 2}, {"x": 1, "n": 2},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("Grid(): expected %v, actual %v", expected, params)
+	}
+
+	// the grid has the order of the trials of Optimize
+	result, _ := grid.Optimize(space, func(p Params) (float64, error) { return p["x"], nil })
+	for i, trial := range result.Trials {
+		if !reflect.DeepEqual(trial.Params, params[i]) {
+			t.Errorf("Grid(): expected trial %v to be %v, actual %v", i, trial.Params, params[i])
+		}
+	}
+
+	if _, err := grid.Grid(nil); err == nil {
+		t.Errorf("Grid(): expected an error for an empty space")
+	}
+}
+
+func TestOptimizerBudget(t *testing.T) {
+	// testCases is a table for testing the budget of the optimizers
+	var testCases = []struct {
+		msg        string
+		optimizer  Optimizer
+		objective  Objective
+		expTrials  int
+		expStopped string
+	}{
+		{"grid search with max evaluations:",
+			&GridSearch{Budget: Budget{MaxEvals: 5}},
+			quadratic,
+			5, "max evaluations reached",
+		},
+		{"genetic algorithm with max evaluations:",
+			&GeneticAlgorithm{Budget: Budget{MaxEvals: 7}, Seed: 1},
+			quadratic,
+			7, "max evaluations reached",
+		},
+		{"bayesian optimization with patience:",
+			&BayesianOptimization{Budget: Budget{Patience: 3}, Seed: 1},
+			func(p Params) (float64, error) { return 1, nil },
+			4, "no improvement",
+		},
+	}
+
+	for _, tc := range testCases {
+		result, err := tc.optimizer.Optimize(quadraticSpace, tc.objective)
+		if err != nil {
+			t.Errorf("%v Optimize(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		if len(result.Trials) != tc.expTrials || result.Stopped != tc.expStopped {
+			t.Errorf("%v Optimize(): expected %v trials %q, actual %v %q",
+				tc.msg, tc.expTrials, tc.expStopped, len(result.Trials), result.Stopped)
+		}
+	}
+}
+
+func TestOptimizerConverge(t *testing.T) {
+	// testCases is a table for testing the convergence of the optimizers
+	var testCases = []struct {
+		msg       string
+		optimizer Optimizer
+	}{
+		{"genetic algorithm:", &GeneticAlgorithm{Population: 20, Generations: 15, Seed: 42}},
+		{"bayesian optimization:", &BayesianOptimization{InitialSamples: 8, Iterations: 30, Seed: 42}},
+	}
+
+	for _, tc := range testCases {
+		result, err := tc.optimizer.Optimize(quadraticSpace, quadratic)
+		if err != nil {
+			t.Errorf("%v Optimize(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		if result.Score < -0.5 {
+			t.Errorf("%v Optimize(): expected score close to 0, actual %v at %v", tc.msg, result.Score, result.Best)
+		}
+	}
+}
+
+func TestOptimizeErrors(t *testing.T) {
+	failing := func(p Params) (float64, error) { return 0, errors.New("backtest failed") }
+
+	if _, err := (&GeneticAlgorithm{}).Optimize(nil, quadratic); err == nil {
+		t.Errorf("Optimize(): expected error without parameters")
+	}
+	if _, err := (&GridSearch{}).Optimize([]Param{{Name: "x", Min: 1, Max: 0}}, quadratic); err == nil {
+		t.Errorf("Optimize(): expected error for invalid range")
+	}
+	if _, err := (&BayesianOptimization{}).Optimize(quadraticSpace, failing); err == nil {
+		t.Errorf("Optimize(): expected error of the objective")
+	}
+}
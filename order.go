@@ -36,11 +36,18 @@ type Order struct {
 	status       OrderStatus
 	direction    Direction // buy or sell
 	assetType    string
-	qty          int64 // quantity of the order
-	qtyFilled    int64
+	qty          float64 // quantity of the order
+	qtyFilled    float64
 	avgFillPrice float64
 	limitPrice   float64 // limit for the order
 	stopPrice    float64
+	displayQty   float64 // visible qty of an iceberg order
+	closing      bool    // closes an existing position in hedging mode
+	side         Side    // side of the hedge closed by the order in hedging mode
+	stopLoss     float64 // protective stop price of the trade
+	strength     float64 // conviction of the signal from -1.0 to 1.0
+	hasStrength  bool    // the strength is set, an unset strength is full size
+	confidence   float64 // confidence of the signal from 0.0 to 1.0
 }
 
 // ID returns the id of the Order.
@@ -53,6 +60,16 @@ func (o *Order) SetID(id int) {
 	o.id = id
 }
 
+// Type returns the OrderType of an Order.
+func (o Order) Type() OrderType {
+	return o.orderType
+}
+
+// SetType sets the OrderType of an Order.
+func (o *Order) SetType(t OrderType) {
+	o.orderType = t
+}
+
 // Direction returns the Direction of an Order
 func (o Order) Direction() Direction {
 	return o.direction
@@ -64,36 +81,156 @@ func (o *Order) SetDirection(dir Direction) {
 }
 
 // Qty returns the Qty field of an Order
-func (o Order) Qty() int64 {
+func (o Order) Qty() float64 {
 	return o.qty
 }
 
 // SetQty sets the Qty field of an Order
-func (o *Order) SetQty(i int64) {
+func (o *Order) SetQty(i float64) {
 	o.qty = i
 }
 
+// QtyFilled returns the already filled qty of an Order.
+func (o Order) QtyFilled() float64 {
+	return o.qtyFilled
+}
+
+// AvgFillPrice returns the average price of all fills of an Order.
+func (o Order) AvgFillPrice() float64 {
+	return o.avgFillPrice
+}
+
+// DisplayQty returns the visible qty of an iceberg Order, 0 if the complete qty is visible.
+func (o Order) DisplayQty() float64 {
+	return o.displayQty
+}
+
+// SetDisplayQty sets the visible qty of an Order, turning it into an iceberg order.
+func (o *Order) SetDisplayQty(i float64) {
+	o.displayQty = i
+}
+
 // Status returns the status of an Order
 func (o Order) Status() OrderStatus {
 	return o.status
 }
 
+// SetStatus sets the status of an Order
+func (o *Order) SetStatus(s OrderStatus) {
+	o.status = s
+}
+
+// pendingOrder returns if the order of the symbol is still pending. An order is pending
+// until it is filled, canceled or rejected, a done order is removed from the orders.
+func pendingOrder(orders map[string]*Order, symbol string) bool {
+	order, ok := orders[symbol]
+	if !ok {
+		return false
+	}
+
+	switch order.Status() {
+	case OrderFilled, OrderCanceled, OrderInvalid:
+		delete(orders, symbol)
+		return false
+	}
+	return true
+}
+
 // Limit returns the limit price of an Order
 func (o Order) Limit() float64 {
 	return o.limitPrice
 }
 
+// SetLimit sets the limit price of an Order
+func (o *Order) SetLimit(price float64) {
+	o.limitPrice = price
+}
+
+// Closing returns if the Order closes an existing position.
+func (o Order) Closing() bool {
+	return o.closing
+}
+
+// SetClosing sets if the Order closes an existing position.
+func (o *Order) SetClosing(closing bool) {
+	o.closing = closing
+}
+
+// Side returns the side of the hedge closed by an Order in hedging mode.
+func (o Order) Side() Side {
+	return o.side
+}
+
+// SetSide sets the side of the hedge closed by an Order, both sides by default.
+func (o *Order) SetSide(side Side) {
+	o.side = side
+}
+
+// StopLoss returns the protective stop price of the trade of an Order.
+func (o Order) StopLoss() float64 {
+	return o.stopLoss
+}
+
+// SetStopLoss sets the protective stop price of the trade of an Order.
+func (o *Order) SetStopLoss(price float64) {
+	o.stopLoss = price
+}
+
+// Strength returns the strength of the signal of an Order.
+func (o Order) Strength() float64 {
+	return o.strength
+}
+
+// SetStrength sets the strength of the signal of an Order.
+func (o *Order) SetStrength(strength float64) {
+	o.strength = strength
+	o.hasStrength = true
+}
+
+// HasStrength returns if the strength of the signal of an Order is set.
+func (o Order) HasStrength() bool {
+	return o.hasStrength
+}
+
+// Confidence returns the confidence of the signal of an Order.
+func (o Order) Confidence() float64 {
+	return o.confidence
+}
+
+// SetConfidence sets the confidence of the signal of an Order.
+func (o *Order) SetConfidence(confidence float64) {
+	o.confidence = confidence
+}
+
 // Stop returns the stop price of an Order
 func (o Order) Stop() float64 {
 	return o.stopPrice
 }
 
+// SetStop sets the stop price of an Order
+func (o *Order) SetStop(price float64) {
+	o.stopPrice = price
+}
+
 // Cancel cancels an order
 func (o *Order) Cancel() {
 	o.status = OrderCancelPending
 }
 
-// Update updates an order on a fill event
+// Update updates the filled qty, average fill price and status of an order on a fill event.
 func (o *Order) Update(fill FillEvent) {
-	// not implemented
+	qtyFilled := o.qtyFilled + fill.Qty()
+	if qtyFilled == 0 {
+		return
+	}
+
+	// (qtyFilled * avgFillPrice + fillQty * fillPrice) / (qtyFilled + fillQty)
+	o.avgFillPrice = (o.qtyFilled*o.avgFillPrice + fill.Qty()*fill.Price()) / qtyFilled
+	o.qtyFilled = qtyFilled
+
+	if o.qtyFilled >= o.qty {
+		o.status = OrderFilled
+		return
+	}
+	o.status = OrderPartiallyFilled
 }
@@ -0,0 +1,33 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderUpdate(t *testing.T) {
+	var testCases = []struct {
+		msg      string
+		order    *Order
+		fill     FillEvent
+		expOrder *Order
+	}{
+		{"partial fill:",
+			&Order{qty: 100},
+			&Fill{qty: 40, price: 10},
+			&Order{qty: 100, qtyFilled: 40, avgFillPrice: 10, status: OrderPartiallyFilled},
+		},
+		{"complete fill:",
+			&Order{qty: 100, qtyFilled: 40, avgFillPrice: 10, status: OrderPartiallyFilled},
+			&Fill{qty: 60, price: 20},
+			&Order{qty: 100, qtyFilled: 100, avgFillPrice: 16, status: OrderFilled},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.order.Update(tc.fill)
+		if !reflect.DeepEqual(tc.order, tc.expOrder) {
+			t.Errorf("%v Update(): \nexpected %#v, \nactual   %#v", tc.msg, tc.expOrder, tc.order)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package gobacktest
+
+// DefaultSlabSize is the number of events allocated at once by an EventPool.
+const DefaultSlabSize = 256
+
+// EventPool hands out events from preallocated slabs, a slab of Size events is a single
+// allocation instead of one allocation per event. The portfolio creates its orders and the
+// exchange its fills from a pool, data handlers and strategies use a pool for their bars,
+// ticks and signals.
+//
+// Events are never put back into the pool, as the engine keeps them, e.g. in the event history
+// of the statistic. A slab is freed by the garbage collector, once none of its events is
+// referenced. An EventPool is not safe for concurrent use, the zero value is ready for use.
+type EventPool struct {
+	Size int // number of events of a slab, defaults to DefaultSlabSize
+
+	bars    []Bar
+	ticks   []Tick
+	signals []Signal
+	orders  []Order
+	fills   []Fill
+}
+
+// size returns the number of events of a slab.
+func (p *EventPool) size() int {
+	if p.Size <= 0 {
+		return DefaultSlabSize
+	}
+	return p.Size
+}
+
+// Bar returns a new empty bar.
+func (p *EventPool) Bar() *Bar {
+	if len(p.bars) == 0 {
+		p.bars = make([]Bar, p.size())
+	}
+	b := &p.bars[0]
+	p.bars = p.bars[1:]
+	return b
+}
+
+// Tick returns a new empty tick.
+func (p *EventPool) Tick() *Tick {
+	if len(p.ticks) == 0 {
+		p.ticks = make([]Tick, p.size())
+	}
+	t := &p.ticks[0]
+	p.ticks = p.ticks[1:]
+	return t
+}
+
+// Signal returns a new empty signal.
+func (p *EventPool) Signal() *Signal {
+	if len(p.signals) == 0 {
+		p.signals = make([]Signal, p.size())
+	}
+	s := &p.signals[0]
+	p.signals = p.signals[1:]
+	return s
+}
+
+// Order returns a new empty order.
+func (p *EventPool) Order() *Order {
+	if len(p.orders) == 0 {
+		p.orders = make([]Order, p.size())
+	}
+	o := &p.orders[0]
+	p.orders = p.orders[1:]
+	return o
+}
+
+// Fill returns a new empty fill.
+func (p *EventPool) Fill() *Fill {
+	if len(p.fills) == 0 {
+		p.fills = make([]Fill, p.size())
+	}
+	f := &p.fills[0]
+	p.fills = p.fills[1:]
+	return f
+}
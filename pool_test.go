@@ -0,0 +1,76 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestEventPool(t *testing.T) {
+	// testCases is a table for testing the slabs of an event pool
+	var testCases = []struct {
+		msg   string
+		size  int
+		n     int
+		slabs int
+	}{
+		{"default size:", 0, DefaultSlabSize, 1},
+		{"one more than a slab:", 0, DefaultSlabSize + 1, 2},
+		{"small slabs:", 4, 10, 3},
+	}
+
+	for _, tc := range testCases {
+		p := &EventPool{Size: tc.size}
+		seen := make(map[*Order]bool)
+		var slabs int
+		for i := 0; i < tc.n; i++ {
+			if len(p.orders) == 0 {
+				slabs++
+			}
+			o := p.Order()
+			if seen[o] || o.Qty() != 0 {
+				t.Errorf("%v Order(): expected a new empty order, actual %p %+v", tc.msg, o, o)
+			}
+			seen[o] = true
+			o.SetQty(float64(i + 1))
+		}
+		if slabs != tc.slabs {
+			t.Errorf("%v Order(): expected %v slabs, actual %v", tc.msg, tc.slabs, slabs)
+		}
+	}
+}
+
+func TestEventPoolAllocs(t *testing.T) {
+	p := &EventPool{}
+	allocs := testing.AllocsPerRun(10, func() {
+		for i := 0; i < DefaultSlabSize; i++ {
+			p.Bar()
+			p.Tick()
+			p.Signal()
+			p.Order()
+			p.Fill()
+		}
+	})
+	// a slab of each event type per run
+	if allocs > 5 {
+		t.Errorf("EventPool: expected at most 5 allocations for %v events of each type, actual %v", DefaultSlabSize, allocs)
+	}
+}
+
+var poolSink *Fill
+
+func BenchmarkEventPool(b *testing.B) {
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			poolSink = &Fill{qty: float64(i)}
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		p := &EventPool{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f := p.Fill()
+			*f = Fill{qty: float64(i)}
+			poolSink = f
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package gobacktest
 
+import (
+	"fmt"
+	"time"
+)
+
 // PortfolioHandler is the combined interface building block for a portfolio.
 type PortfolioHandler interface {
 	OnSignaler
@@ -54,13 +59,40 @@ type Booker interface {
 
 // Portfolio represent a simple portfolio struct.
 type Portfolio struct {
-	initialCash  float64
-	cash         float64
-	holdings     map[string]Position
-	orderBook    []OrderEvent
-	transactions []FillEvent
-	sizeManager  SizeHandler
-	riskManager  RiskHandler
+	initialCash       float64
+	cash              float64
+	holdings          map[string]Position
+	orderBook         []OrderEvent
+	transactions      []FillEvent
+	sizeManager       SizeHandler
+	riskManager       RiskHandler
+	noShortSelling    bool
+	allowNegativeCash bool
+	ledger            *LotLedger
+	mode              AccountMode
+	hedges            map[string]Hedge
+	instruments       *InstrumentRegistry
+	interest          InterestHandler
+	lastAccrual       time.Time
+	interestEarned    float64
+	financingCost     float64
+	scheduledFlows    []CashFlow
+	bookedFlows       []CashFlow
+	ruleFlows         int // booked cash flows handed to the risk rules
+	margin            *Margin
+	costs             CostEstimator
+	liquidations      map[string]*Order
+	reserved          []reservation
+	constraints       []Constraint
+	rules             []RiskRule
+	clock             Clock
+	netting           *Netting
+	exits             *Exits
+	spreads           map[string]*Spread
+	spreadQty         map[string]float64
+	meta              *MetaLabeler
+	pool              EventPool
+	log               Logger
 }
 
 // NewPortfolio creates a default portfolio with sensible defaults ready for use.
@@ -77,6 +109,11 @@ func (p Portfolio) SizeManager() SizeHandler {
 	return p.sizeManager
 }
 
+// SetLogger implements LogSetter.
+func (p *Portfolio) SetLogger(l Logger) {
+	p.log = l
+}
+
 // SetSizeManager sets the size manager to be used with the portfolio.
 func (p *Portfolio) SetSizeManager(size SizeHandler) {
 	p.sizeManager = size
@@ -92,11 +129,178 @@ func (p *Portfolio) SetRiskManager(risk RiskHandler) {
 	p.riskManager = risk
 }
 
+// SetShortSelling sets if selling more than the held qty of a symbol is allowed, default is true.
+func (p *Portfolio) SetShortSelling(allow bool) {
+	p.noShortSelling = !allow
+}
+
+// SetNegativeCash sets if buying for more than the available cash is allowed, default is false.
+func (p *Portfolio) SetNegativeCash(allow bool) {
+	p.allowNegativeCash = allow
+}
+
+// SetCostBasis enables the tracking of tax lots with the given cost basis method.
+func (p *Portfolio) SetCostBasis(method CostBasisMethod) {
+	p.ledger = NewLotLedger(method)
+}
+
+// Ledger returns the lot ledger of the portfolio, if a cost basis method is set.
+func (p Portfolio) Ledger() (*LotLedger, bool) {
+	if p.ledger == nil {
+		return nil, false
+	}
+	return p.ledger, true
+}
+
+// AccountMode returns the account mode of the portfolio.
+func (p Portfolio) AccountMode() AccountMode {
+	return p.mode
+}
+
+// SetAccountMode sets the account mode of the portfolio, default is NettingMode.
+// In HedgingMode the holdings keep the net position of each symbol,
+// the separate long and short positions are available via Hedge.
+func (p *Portfolio) SetAccountMode(mode AccountMode) {
+	p.mode = mode
+}
+
+// Hedge returns the long and short position of a symbol in hedging mode.
+func (p Portfolio) Hedge(symbol string) (Hedge, bool) {
+	h, ok := p.hedges[symbol]
+	return h, ok
+}
+
+// SplitExit splits an exit signal of both sides of a hedge with an open long and short position
+// into an exit signal per side, as a single order closes only one side. Other signals are not split.
+func (p Portfolio) SplitExit(signal SignalEvent) []*Signal {
+	if p.mode != HedgingMode || signal.Direction() != EXT {
+		return nil
+	}
+	if s, ok := signal.(Sider); ok && s.Side() != BothSides {
+		return nil
+	}
+	h, ok := p.hedges[signal.Symbol()]
+	if !ok || h.Long.qty <= 0 || h.Short.qty >= 0 {
+		return nil
+	}
+
+	signals := make([]*Signal, 0, 2)
+	for _, side := range []Side{LongSide, ShortSide} {
+		s := &Signal{
+			Event:     Event{timestamp: signal.Time(), symbol: signal.Symbol()},
+			direction: EXT,
+			side:      side,
+		}
+		copyTags(signal, s)
+		signals = append(signals, s)
+	}
+	return signals
+}
+
+// Instruments returns the instrument registry of the portfolio.
+func (p Portfolio) Instruments() *InstrumentRegistry {
+	return p.instruments
+}
+
+// SetInstruments sets the instrument registry, used for the contract multiplier of the order value.
+func (p *Portfolio) SetInstruments(r *InstrumentRegistry) {
+	p.instruments = r
+}
+
+// CostEstimator estimates the commission and fees of an order before its execution,
+// e.g. implemented by the exchange.
+type CostEstimator interface {
+	EstimateCost(qty, price float64) (float64, error)
+}
+
+// CostEstimatorSetter receives the cost estimator of a backtest, e.g. implemented by a portfolio.
+type CostEstimatorSetter interface {
+	SetCostEstimator(CostEstimator)
+}
+
+// SetCostEstimator implements CostEstimatorSetter. The cash and the buying power needed by an
+// order include its estimated cost, the backtest sets its exchange as estimator.
+func (p *Portfolio) SetCostEstimator(c CostEstimator) {
+	p.costs = c
+}
+
+// estimateCost returns the estimated cost of an order at a price, 0 without an estimator.
+func (p *Portfolio) estimateCost(qty, price float64) float64 {
+	if p.costs == nil {
+		return 0
+	}
+	cost, err := p.costs.EstimateCost(qty, price)
+	if err != nil {
+		logger(p.log).Warn("order cost not estimated", "qty", qty, "price", price, "err", err)
+		return 0
+	}
+	return cost
+}
+
+// SetInterest sets the interest handler which accrues interest on the cash and short balances daily.
+func (p *Portfolio) SetInterest(interest InterestHandler) {
+	p.interest = interest
+}
+
+// InterestEarned returns the total interest credited on positive cash.
+func (p Portfolio) InterestEarned() float64 {
+	return p.interestEarned
+}
+
+// FinancingCost returns the total interest charged on negative cash and short positions.
+func (p Portfolio) FinancingCost() float64 {
+	return p.financingCost
+}
+
+// SetCashFlows sets a schedule of deposits and withdrawals,
+// which are booked into cash on the first data event at or after their time.
+func (p *Portfolio) SetCashFlows(flows ...CashFlow) {
+	p.scheduledFlows = append([]CashFlow{}, flows...)
+	sortCashFlows(p.scheduledFlows)
+}
+
+// CashFlows returns the deposits and withdrawals booked so far.
+func (p Portfolio) CashFlows() []CashFlow {
+	return p.bookedFlows
+}
+
+// SetConstraints sets the constraints which are checked for every order before its submission.
+func (p *Portfolio) SetConstraints(constraints ...Constraint) {
+	p.constraints = constraints
+}
+
 // Reset the portfolio into a clean state with set initial cash.
 func (p *Portfolio) Reset() error {
 	p.cash = 0
 	p.holdings = nil
 	p.transactions = nil
+	p.hedges = nil
+	p.lastAccrual = time.Time{}
+	p.interestEarned = 0
+	p.financingCost = 0
+	p.bookedFlows = nil
+	p.ruleFlows = 0
+	p.spreadQty = nil
+	p.liquidations = nil
+	p.reserved = nil
+	p.clock = Clock{}
+	if r, ok := p.sizeManager.(Reseter); ok {
+		r.Reset()
+	}
+	for _, rule := range p.rules {
+		if r, ok := rule.(Reseter); ok {
+			r.Reset()
+		}
+	}
+	if p.ledger != nil {
+		p.ledger.Reset()
+	}
+	if p.exits != nil {
+		p.exits.Reset()
+	}
+	if p.meta != nil {
+		p.meta.Reset()
+	}
 	return nil
 }
 
@@ -108,7 +312,8 @@ func (p *Portfolio) OnSignal(signal SignalEvent, data DataHandler) (*Order, erro
 	orderType := MarketOrder // default Market, should be set by risk manager
 	var limit float64
 
-	initialOrder := &Order{
+	initialOrder := p.pool.Order()
+	*initialOrder = Order{
 		Event: Event{
 			timestamp: signal.Time(),
 			symbol:    signal.Symbol(),
@@ -119,28 +324,214 @@ func (p *Portfolio) OnSignal(signal SignalEvent, data DataHandler) (*Order, erro
 		limitPrice: limit,
 	}
 
+	// the order is caused by the signal
+	if l, ok := signal.(Lineager); ok {
+		initialOrder.SetParentID(l.EventID())
+	}
+	// the order carries the tags of the signal
+	copyTags(signal, initialOrder)
+
+	// pass the protective stop of the signal to the order
+	if sl, ok := signal.(StopLosser); ok {
+		initialOrder.SetStopLoss(sl.StopLoss())
+	}
+
+	// pass the conviction of the signal to the order
+	if w, ok := signal.(Weighter); ok {
+		if w.HasStrength() {
+			initialOrder.SetStrength(w.Strength())
+		}
+		initialOrder.SetConfidence(w.Confidence())
+	}
+
+	// in hedging mode only an exit signal closes an existing position, optionally of one side
+	if p.mode == HedgingMode && signal.Direction() == EXT {
+		initialOrder.SetClosing(true)
+		if s, ok := signal.(Sider); ok {
+			initialOrder.SetSide(s.Side())
+		}
+	}
+
+	// the secondary model of the meta-labeling may reject the signal
+	if p.meta != nil && !p.meta.onSignal(signal, data) {
+		logger(p.log).Info("signal rejected by meta model", "symbol", signal.Symbol(), "time", signal.Time())
+		return nil, NewRejection(initialOrder, "signal rejected by meta model")
+	}
+
+	// an exit rule turns the signal into an exit of the position
+	exit := p.exitOnSignal(signal)
+	if exit {
+		initialOrder.SetDirection(EXT)
+	}
+
+	// translate the signal for the existing position
+	var reverseQty float64
+	if p.netting != nil {
+		dir, qty, ok := p.netting.net(initialOrder.Direction(), signal.Symbol(), p)
+		if !ok {
+			logger(p.log).Debug("signal ignored by netting", "symbol", signal.Symbol(), "time", signal.Time(), "direction", signal.Direction())
+			return nil, nil
+		}
+		initialOrder.SetDirection(dir)
+		reverseQty = qty
+	}
+
 	// fetch latest known price for the symbol
 	latest := data.Latest(signal.Symbol())
 
 	sizedOrder, err := p.sizeManager.SizeOrder(initialOrder, latest, p)
 	if err != nil {
+		logger(p.log).Debug("order not sized", "symbol", signal.Symbol(), "time", signal.Time(), "err", err)
+	}
+
+	// a reversal closes the existing position in the same order
+	if reverseQty != 0 && sizedOrder != nil {
+		sizedOrder.SetQty(sizedOrder.Qty() + reverseQty)
 	}
 
 	order, err := p.riskManager.EvaluateOrder(sizedOrder, latest, p.holdings)
 	if err != nil {
+		logger(p.log).Debug("order not evaluated by risk manager", "symbol", signal.Symbol(), "time", signal.Time(), "err", err)
+	}
+
+	// check if the order can be covered by cash or holdings
+	if rejection := p.checkOrder(order, latest); rejection != nil {
+		logger(p.log).Info("order rejected", "symbol", signal.Symbol(), "time", signal.Time(), "reason", rejection.Reason())
+		return nil, rejection
+	}
+
+	// check if the trading is halted by a risk rule
+	if rejection := p.checkHalt(order); rejection != nil {
+		logger(p.log).Info("order rejected", "symbol", signal.Symbol(), "time", signal.Time(), "reason", rejection.Reason())
+		return nil, rejection
+	}
+
+	// check if the order violates a constraint of the portfolio
+	if rejection := p.checkConstraints(order, latest); rejection != nil {
+		logger(p.log).Info("order rejected", "symbol", signal.Symbol(), "time", signal.Time(), "reason", rejection.Reason())
+		return nil, rejection
 	}
 
+	if order != nil {
+		// the exit is pending until the order is done
+		if exit {
+			p.exits.exit(signal.Symbol(), order)
+		}
+		// the value of the order is not available until its fills are booked
+		if latest != nil {
+			p.reserve(order, p.orderValue(order, latest))
+		}
+		logger(p.log).Debug("order created", "symbol", order.Symbol(), "time", order.Time(), "direction", order.Direction(), "qty", order.Qty())
+	}
 	return order, nil
 }
 
+// checkOrder rejects buy orders exceeding the available cash
+// and sell orders exceeding the held qty, if short selling is disabled.
+// With a margin account, buy orders and sell orders opening or increasing
+// a short position are limited by the buying power. The value of an order
+// includes its estimated cost, the value of the accepted but not booked
+// orders is reserved and not available.
+func (p *Portfolio) checkOrder(order *Order, latest DataEvent) *Rejection {
+	if order == nil || latest == nil {
+		return nil
+	}
+
+	if order.Direction() == SLD && p.noShortSelling {
+		var held float64
+		if pos, ok := p.IsLong(order.Symbol()); ok {
+			held = pos.qty
+		}
+		if order.Qty() > held {
+			return NewRejection(order, fmt.Sprintf("insufficient position %v for order qty %v", held, order.Qty()))
+		}
+		return nil
+	}
+
+	value := p.orderValue(order, latest)
+	if p.allowNegativeCash || value == 0 {
+		return nil
+	}
+
+	// with a margin account the order is limited by the buying power
+	if p.margin != nil {
+		kind := "order"
+		if order.Direction() == SLD {
+			kind = "short"
+		}
+		if power := p.buyingPower() - p.reservedValue(); value > power {
+			return NewRejection(order, fmt.Sprintf("insufficient buying power %.2f for %s value %.2f", power, kind, value))
+		}
+		return nil
+	}
+
+	if cash := p.cash - p.reservedValue(); value > cash {
+		return NewRejection(order, fmt.Sprintf("insufficient cash %.2f for order value %.2f", cash, value))
+	}
+	return nil
+}
+
+// orderValue returns the value of an order to be covered by the cash or the buying power,
+// including its estimated cost. Buying back a short position releases no cash, but is always
+// allowed. Selling a long position releases cash, but the qty exceeding it opens or increases
+// a short position, which is covered by the buying power of a margin account.
+func (p *Portfolio) orderValue(order *Order, latest DataEvent) float64 {
+	instrument := p.instruments.Get(order.Symbol())
+
+	switch order.Direction() {
+	case BOT:
+		if pos, ok := p.IsShort(order.Symbol()); ok && order.Qty() <= -pos.qty {
+			return 0
+		}
+		return instrument.Notional(order.Qty(), latest.Price()) + p.estimateCost(order.Qty(), latest.Price())
+	case SLD:
+		var held float64
+		if pos, ok := p.IsLong(order.Symbol()); ok {
+			held = pos.qty
+		}
+		if p.margin == nil || order.Closing() || order.Qty() <= held {
+			return 0
+		}
+		return instrument.Notional(order.Qty()-held, latest.Price()) + p.estimateCost(order.Qty(), latest.Price())
+	}
+	return 0
+}
+
+// checkConstraints rejects an order which violates a constraint of the portfolio.
+func (p *Portfolio) checkConstraints(order *Order, latest DataEvent) *Rejection {
+	if order == nil || latest == nil {
+		return nil
+	}
+
+	equity := p.Value()
+	for _, c := range p.constraints {
+		if err := c.Check(order, latest.Price(), p.holdings, equity); err != nil {
+			return NewRejection(order, err.Error())
+		}
+	}
+
+	return nil
+}
+
 // OnFill handles an incomming fill event
 func (p *Portfolio) OnFill(fill FillEvent, data DataHandler) (*Fill, error) {
+	p.release(fill)
+
+	// a spread is booked as fills of its legs
+	if s, ok := p.spreads[fill.Symbol()]; ok {
+		if err := p.onSpreadFill(s, fill, data); err != nil {
+			return nil, err
+		}
+		return fill.(*Fill), nil
+	}
+
 	// Check for nil map, else initialise the map
 	if p.holdings == nil {
 		p.holdings = make(map[string]Position)
 	}
 
 	// check if portfolio has already a holding of the symbol from this fill
+	before := p.holdings[fill.Symbol()].qty
 	if pos, ok := p.holdings[fill.Symbol()]; ok {
 		// update existing Position
 		pos.Update(fill)
@@ -160,16 +551,47 @@ func (p *Portfolio) OnFill(fill FillEvent, data DataHandler) (*Fill, error) {
 		p.cash = p.cash + fill.NetValue()
 	}
 
+	// update the separate long and short positions
+	if p.mode == HedgingMode {
+		// check for nil map, else initialise the map
+		if p.hedges == nil {
+			p.hedges = make(map[string]Hedge)
+		}
+		h := p.hedges[fill.Symbol()]
+		h.OnFill(fill)
+		p.hedges[fill.Symbol()] = h
+	}
+
 	// add fill to transactions
 	p.transactions = append(p.transactions, fill)
 
+	// label the signals of a closed position
+	if p.meta != nil && p.holdings[fill.Symbol()].qty == 0 {
+		p.meta.close(fill.Symbol(), fill.Price())
+	}
+
+	// a closed position ends its liquidation
+	if p.holdings[fill.Symbol()].qty == 0 {
+		delete(p.liquidations, fill.Symbol())
+	}
+
+	// an opened position starts the evaluation of the exit rules at the time of the fill
+	if p.exits != nil {
+		p.exits.onFill(fill, before, p.holdings[fill.Symbol()].qty)
+	}
+
+	// update tax lots
+	if p.ledger != nil {
+		p.ledger.OnFill(fill)
+	}
+
 	f := fill.(*Fill)
 	return f, nil
 }
 
 // IsInvested checks if the portfolio has an open position on the given symbol
 func (p Portfolio) IsInvested(symbol string) (pos Position, ok bool) {
-	pos, ok = p.holdings[symbol]
+	pos, ok = p.position(symbol)
 	if ok && (pos.qty != 0) {
 		return pos, true
 	}
@@ -178,7 +600,7 @@ func (p Portfolio) IsInvested(symbol string) (pos Position, ok bool) {
 
 // IsLong checks if the portfolio has an open long position on the given symbol
 func (p Portfolio) IsLong(symbol string) (pos Position, ok bool) {
-	pos, ok = p.holdings[symbol]
+	pos, ok = p.position(symbol)
 	if ok && (pos.qty > 0) {
 		return pos, true
 	}
@@ -187,7 +609,7 @@ func (p Portfolio) IsLong(symbol string) (pos Position, ok bool) {
 
 // IsShort checks if the portfolio has an open short position on the given symbol
 func (p Portfolio) IsShort(symbol string) (pos Position, ok bool) {
-	pos, ok = p.holdings[symbol]
+	pos, ok = p.position(symbol)
 	if ok && (pos.qty < 0) {
 		return pos, true
 	}
@@ -196,9 +618,77 @@ func (p Portfolio) IsShort(symbol string) (pos Position, ok bool) {
 
 // Update updates the holding on a data event
 func (p *Portfolio) Update(d DataEvent) {
-	if pos, ok := p.IsInvested(d.Symbol()); ok {
+	p.accrue(d.Time())
+	p.bookCashFlows(d.Time())
+
+	if h, ok := p.hedges[d.Symbol()]; ok {
+		h.UpdateValue(d)
+		p.hedges[d.Symbol()] = h
+	}
+
+	if pos, ok := p.holdings[d.Symbol()]; ok && pos.qty != 0 {
 		pos.UpdateValue(d)
 		p.holdings[d.Symbol()] = pos
+
+		if p.ledger != nil {
+			p.ledger.Mark(d.Symbol(), d.Price())
+		}
+	}
+
+	p.updateEquity(d)
+}
+
+// updateEquity passes the equity to the size manager, if it tracks the equity.
+func (p *Portfolio) updateEquity(d DataEvent) {
+	if updater, ok := p.sizeManager.(EquityUpdater); ok {
+		updater.UpdateEquity(d.Time(), p.Value())
+	}
+}
+
+// Rebalance returns the orders of the size manager to adjust the existing positions.
+func (p *Portfolio) Rebalance(d DataEvent) []*Order {
+	if rebalancer, ok := p.sizeManager.(PositionRebalancer); ok {
+		return rebalancer.Rebalance(p.holdings, d.Time())
+	}
+	return nil
+}
+
+// accrue books the interest on the cash and short balances since the last accrual.
+func (p *Portfolio) accrue(t time.Time) {
+	if p.interest == nil {
+		return
+	}
+
+	days := accrualDays(p.lastAccrual, t)
+	if p.lastAccrual.IsZero() || days > 0 {
+		p.lastAccrual = t
+	}
+	if days == 0 {
+		return
+	}
+
+	var shortValue float64
+	for _, pos := range p.holdings {
+		if pos.qty < 0 {
+			shortValue += pos.marketValue
+		}
+	}
+
+	credit, debit := p.interest.Accrue(p.cash, shortValue, days)
+	p.cash += credit - debit
+	p.interestEarned += credit
+	p.financingCost += debit
+}
+
+// bookCashFlows books all scheduled cash flows up to a point in time into cash.
+func (p *Portfolio) bookCashFlows(t time.Time) {
+	for i := len(p.bookedFlows); i < len(p.scheduledFlows); i++ {
+		flow := p.scheduledFlows[i]
+		if flow.Time.After(t) {
+			return
+		}
+		p.cash += flow.Amount
+		p.bookedFlows = append(p.bookedFlows, flow)
 	}
 }
 
@@ -222,11 +712,15 @@ func (p Portfolio) Cash() float64 {
 	return p.cash
 }
 
-// Value return the current total value of the portfolio
+// Value return the current total value of the portfolio, the cash and the market value of the
+// positions. A short position is a liability, its market value is subtracted.
 func (p Portfolio) Value() float64 {
 	var holdingValue float64
 	for _, pos := range p.holdings {
-
+		if pos.qty < 0 {
+			holdingValue -= pos.marketValue
+			continue
+		}
 		holdingValue += pos.marketValue
 	}
 
@@ -710,3 +710,60 @@ func TestPortfolioValue(t *testing.T) {
 		}
 	}
 }
+
+func TestPortfolioCheckOrder(t *testing.T) {
+	var testCases = []struct {
+		msg       string
+		portfolio *Portfolio
+		order     *Order
+		expReject bool
+	}{
+		{"buy covered by cash:",
+			&Portfolio{cash: 1000},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			false,
+		},
+		{"buy exceeding cash:",
+			&Portfolio{cash: 50},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			true,
+		},
+		{"buy covered by cash, but not its cost:",
+			&Portfolio{cash: 100, costs: &Exchange{Commission: &FixedCommission{Commission: 5}, ExchangeFee: &FixedExchangeFee{}}},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			true,
+		},
+		{"buy exceeding cash with negative cash allowed:",
+			&Portfolio{cash: 50, allowNegativeCash: true},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			false,
+		},
+		{"buy to cover short position:",
+			&Portfolio{cash: 0, holdings: map[string]Position{"TEST.DE": {qty: -10}}},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, qty: 10},
+			false,
+		},
+		{"short sell allowed by default:",
+			&Portfolio{},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, qty: 10},
+			false,
+		},
+		{"sell exceeding position without short selling:",
+			&Portfolio{noShortSelling: true, holdings: map[string]Position{"TEST.DE": {qty: 5}}},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, qty: 10},
+			true,
+		},
+		{"sell held position without short selling:",
+			&Portfolio{noShortSelling: true, holdings: map[string]Position{"TEST.DE": {qty: 10}}},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, qty: 10},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		rejection := tc.portfolio.checkOrder(tc.order, &Bar{Close: 10})
+		if (rejection != nil) != tc.expReject {
+			t.Errorf("%v checkOrder(): \nexpected rejection %v, \nactual   %v", tc.msg, tc.expReject, rejection)
+		}
+	}
+}
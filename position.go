@@ -10,31 +10,67 @@ import (
 type Position struct {
 	timestamp   time.Time
 	symbol      string
-	qty         int64   // current qty of the position, positive on BOT position, negativ on SLD position
-	qtyBOT      int64   // how many BOT
-	qtySLD      int64   // how many SLD
+	qty         float64 // current qty of the position, positive on BOT position, negativ on SLD position
+	qtyBOT      float64 // how many BOT
+	qtySLD      float64 // how many SLD
 	avgPrice    float64 // average price without cost
 	avgPriceNet float64 // average price including cost
 	avgPriceBOT float64 // average price BOT, without cost
 	avgPriceSLD float64 // average price SLD, without cost
-	value       float64 // qty * price
-	valueBOT    float64 // qty BOT * price
-	valueSLD    float64 // qty SLD * price
+	value       float64 // qty * price * multiplier
+	valueBOT    float64 // qty BOT * price * multiplier
+	valueSLD    float64 // qty SLD * price * multiplier
 	netValue    float64 // current value - cost
 	netValueBOT float64 // current BOT value + cost
 	netValueSLD float64 // current SLD value - cost
 	marketPrice float64 // last known market price
-	marketValue float64 // qty * price
+	marketValue float64 // abs(qty) * price * multiplier
 	commission  float64
 	exchangeFee float64
 	cost        float64 // commission + fees
-	costBasis   float64 // absolute qty * avgPriceNet
+	costBasis   float64 // absolute qty * avgPriceNet * multiplier
+	multiplier  float64 // contract multiplier, 0 means 1
 
 	realProfitLoss   float64
 	unrealProfitLoss float64
 	totalProfitLoss  float64
 }
 
+// NewPosition creates a position of qty held at an average price and valued at a market price,
+// e.g. the position reported by a broker. A negative qty is a short position.
+func NewPosition(timestamp time.Time, symbol string, qty, avgPrice, marketPrice float64) Position {
+	fill := &Fill{Event: Event{timestamp: timestamp, symbol: symbol}, direction: BOT, qty: qty, price: avgPrice}
+	if qty < 0 {
+		fill.direction = SLD
+		fill.qty = -qty
+	}
+
+	var p Position
+	p.Create(fill)
+	p.updateValue(marketPrice)
+	return p
+}
+
+// Symbol returns the symbol of a position.
+func (p Position) Symbol() string {
+	return p.symbol
+}
+
+// Qty returns the qty of a position, negative for a short position.
+func (p Position) Qty() float64 {
+	return p.qty
+}
+
+// AvgPrice returns the average price of a position without cost.
+func (p Position) AvgPrice() float64 {
+	return p.avgPrice
+}
+
+// MarketPrice returns the last known market price of a position.
+func (p Position) MarketPrice() float64 {
+	return p.marketPrice
+}
+
 // Create a new position based on a fill event
 func (p *Position) Create(fill FillEvent) {
 	p.timestamp = fill.Time()
@@ -58,10 +94,24 @@ func (p *Position) UpdateValue(data DataEvent) {
 	p.updateValue(latest)
 }
 
+// contractMultiplier returns the contract multiplier of a position, defaults to 1.
+func (p Position) contractMultiplier() float64 {
+	if p.multiplier <= 0 {
+		return 1
+	}
+	return p.multiplier
+}
+
 // internal function to update a position on a new fill event
 func (p *Position) update(fill FillEvent) {
+	// the multiplier of the instrument, 0 for the default of 1
+	if m := contractMultiplier(fill); m != 1 {
+		p.multiplier = m
+	}
+	m := p.contractMultiplier()
+
 	// convert fill to internally used decimal numbers
-	fillQty := float64(fill.Qty())
+	fillQty := fill.Qty()
 	fillPrice := fill.Price()
 	fillCommission := fill.Commission()
 	fillExchangeFee := fill.ExchangeFee()
@@ -69,9 +119,9 @@ func (p *Position) update(fill FillEvent) {
 	fillNetValue := fill.NetValue()
 
 	// convert position to internally used decimal numbers
-	qty := float64(p.qty)
-	qtyBot := float64(p.qtyBOT)
-	qtySld := float64(p.qtySLD)
+	qty := p.qty
+	qtyBot := p.qtyBOT
+	qtySld := p.qtySLD
 	avgPrice := p.avgPrice
 	avgPriceNet := p.avgPriceNet
 	avgPriceBot := p.avgPriceBOT
@@ -95,15 +145,15 @@ func (p *Position) update(fill FillEvent) {
 		} else { // position is short, closing partially out
 			// costBasis + abs(fillQty) / qty * costBasis
 			costBasis += math.Abs(fillQty) / qty * costBasis
-			// realProfitLoss + fillQty * (avgPriceNet - fillPrice) - fillCost
-			realProfitLoss += fillQty*(avgPriceNet-fillPrice) - fillCost
+			// realProfitLoss + fillQty * (avgPriceNet - fillPrice) * multiplier - fillCost
+			realProfitLoss += fillQty*(avgPriceNet-fillPrice)*m - fillCost
 		}
 
 		// update average price for bought stock without cost
 		// ( (abs(qty) * avgPrice) + (fillQty * fillPrice) ) / (abs(qty) + fillQty)
 		avgPrice = ((math.Abs(qty) * avgPrice) + (fillQty * fillPrice)) / (math.Abs(qty) + fillQty)
-		// (abs(qty) * avgPriceNet + fillNetValue) / (abs(qty) * fillQty)
-		avgPriceNet = (math.Abs(qty)*avgPriceNet + fillNetValue) / (math.Abs(qty) + fillQty)
+		// (abs(qty) * avgPriceNet + fillNetValue / multiplier) / (abs(qty) * fillQty)
+		avgPriceNet = (math.Abs(qty)*avgPriceNet + fillNetValue/m) / (math.Abs(qty) + fillQty)
 		// ( (qty + avgPriceBot) + (fillQty * fillPrice) ) / fillQty
 		avgPriceBot = ((qtyBot * avgPriceBot) + (fillQty * fillPrice)) / (qtyBot + fillQty)
 
@@ -112,14 +162,14 @@ func (p *Position) update(fill FillEvent) {
 		qtyBot += fillQty
 
 		// update bought value
-		valueBot = qtyBot * avgPriceBot
+		valueBot = qtyBot * avgPriceBot * m
 		netValueBot += fillNetValue
 
 	case SLD:
 		if p.qty > 0 { // position is long, closing partially out
 			costBasis -= math.Abs(fillQty) / qty * costBasis
-			// realProfitLoss + fillQty * (fillPrice - avgPriceNet) - fillCost
-			realProfitLoss += math.Abs(fillQty)*(fillPrice-avgPriceNet) - fillCost
+			// realProfitLoss + fillQty * (fillPrice - avgPriceNet) * multiplier - fillCost
+			realProfitLoss += math.Abs(fillQty)*(fillPrice-avgPriceNet)*m - fillCost
 		} else { // position is short, adding to position
 			costBasis -= fillNetValue
 		}
@@ -127,8 +177,8 @@ func (p *Position) update(fill FillEvent) {
 		// update average price for bought stock without cost
 		// ( (abs(qty) * avgPrice) + (fillQty * fillPrice) ) / (abs(qty) + fillQty)
 		avgPrice = (math.Abs(qty)*avgPrice + fillQty*fillPrice) / (math.Abs(qty) + fillQty)
-		// (abs(qty) * avgPriceNet + fillNetValue) / (abs(qty) * fillQty)
-		avgPriceNet = (math.Abs(qty)*avgPriceNet + fillNetValue) / (math.Abs(qty) + fillQty)
+		// (abs(qty) * avgPriceNet + fillNetValue / multiplier) / (abs(qty) * fillQty)
+		avgPriceNet = (math.Abs(qty)*avgPriceNet + fillNetValue/m) / (math.Abs(qty) + fillQty)
 		// avgPriceSld + (fillQty * fillPrice) / fillQty
 		avgPriceSld = (qtySld*avgPriceSld + fillQty*fillPrice) / (qtySld + fillQty)
 
@@ -137,7 +187,7 @@ func (p *Position) update(fill FillEvent) {
 		qtySld += fillQty
 
 		// update sold value
-		valueSld = qtySld * avgPriceSld
+		valueSld = qtySld * avgPriceSld * m
 		netValueSld += fillNetValue
 	}
 
@@ -148,9 +198,9 @@ func (p *Position) update(fill FillEvent) {
 	netValue = value - cost
 
 	// convert from internal decimal to float
-	p.qty = int64(qty)
-	p.qtyBOT = int64(qtyBot)
-	p.qtySLD = int64(qtySld)
+	p.qty = roundQty(qty)
+	p.qtyBOT = roundQty(qtyBot)
+	p.qtySLD = roundQty(qtySld)
 	p.avgPrice = math.Round(avgPrice*math.Pow10(DP)) / math.Pow10(DP)
 	p.avgPriceBOT = math.Round(avgPriceBot*math.Pow10(DP)) / math.Pow10(DP)
 	p.avgPriceSLD = math.Round(avgPriceSld*math.Pow10(DP)) / math.Pow10(DP)
@@ -174,18 +224,18 @@ func (p *Position) update(fill FillEvent) {
 func (p *Position) updateValue(l float64) {
 	// convert to internally used decimal numbers
 	latest := l
-	qty := float64(p.qty)
+	qty := p.qty
 	costBasis := p.costBasis
 
 	// update market value
 	marketPrice := latest
 	p.marketPrice = marketPrice
-	// abs(qty) * current
-	marketValue := math.Abs(qty) * latest
+	// abs(qty) * current * multiplier
+	marketValue := math.Abs(qty) * latest * p.contractMultiplier()
 	p.marketValue = marketValue
 
-	// qty * current - costBasis
-	unrealProfitLoss := qty*latest - costBasis
+	// qty * current * multiplier - costBasis
+	unrealProfitLoss := qty*latest*p.contractMultiplier() - costBasis
 	p.unrealProfitLoss = math.Round(unrealProfitLoss*math.Pow10(DP)) / math.Pow10(DP)
 
 	realProfitLoss := p.realProfitLoss
@@ -524,3 +524,87 @@ func TestUpdatePositionValue(t *testing.T) {
 		}
 	}
 }
+
+func TestNewPosition(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing positions reported by a broker
+	var testCases = []struct {
+		msg       string
+		qty       float64
+		expUnreal float64
+	}{
+		{"long position:", 10, 50},
+		{"short position:", -10, -50},
+		{"no position:", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		p := NewPosition(exampleTime, "TEST.DE", tc.qty, 100, 105)
+		if p.Symbol() != "TEST.DE" || p.Qty() != tc.qty || p.MarketPrice() != 105 || p.unrealProfitLoss != tc.expUnreal {
+			t.Errorf("%v NewPosition(): expected qty %v with unrealized %v, actual %+v", tc.msg, tc.qty, tc.expUnreal, p)
+		}
+		if tc.qty != 0 && p.AvgPrice() != 100 {
+			t.Errorf("%v NewPosition(): expected average price 100, actual %v", tc.msg, p.AvgPrice())
+		}
+	}
+}
+
+func TestUpdatePositionFractionalQty(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing fractional fills which close a position
+	var testCases = []struct {
+		msg    string
+		method CostBasisMethod
+		fills  []FillEvent
+	}{
+		{"buy 0.1 and 0.2, sell 0.3:", FIFO, []FillEvent{
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: BOT, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: BOT, qty: 0.2, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.3, price: 110},
+		}},
+		{"buy 0.3, sell 0.1 and 0.2 last in first out:", LIFO, []FillEvent{
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: BOT, qty: 0.3, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 110},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.2, price: 110},
+		}},
+		{"short 0.7 in steps of 0.1, cover 0.7:", AverageCost, []FillEvent{
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: SLD, qty: 0.1, price: 100},
+			&Fill{Event: Event{timestamp: exampleTime, symbol: "BTCUSD"}, direction: BOT, qty: 0.7, price: 90},
+		}},
+	}
+
+	for _, tc := range testCases {
+		pos := &Position{}
+		for _, fill := range tc.fills {
+			pos.Update(fill)
+		}
+		if pos.qty != 0 {
+			t.Errorf("%v Update(): expected a closed position, actual qty %v", tc.msg, pos.qty)
+		}
+
+		// the portfolio removes the closed position, the ledger has no open lot left
+		p := &Portfolio{initialCash: 1000, cash: 1000}
+		p.SetNegativeCash(true)
+		p.SetCostBasis(tc.method)
+		for _, fill := range tc.fills {
+			if _, err := p.OnFill(fill, &Data{}); err != nil {
+				t.Fatalf("%v OnFill(): unexpected error %v", tc.msg, err)
+			}
+		}
+		if pos, ok := p.IsInvested("BTCUSD"); ok {
+			t.Errorf("%v IsInvested(): expected no position, actual qty %v", tc.msg, pos.qty)
+		}
+		ledger, _ := p.Ledger()
+		if lots := ledger.OpenLots("BTCUSD"); len(lots) != 0 {
+			t.Errorf("%v OpenLots(): expected no open lot, actual %+v", tc.msg, lots)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gobacktest
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+)
+
+// Profile configures the profiling of a backtest run with runtime/pprof, e.g. to find
+// the cause of a performance regression between two releases. Unset writers are skipped.
+type Profile struct {
+	CPU  io.Writer // cpu profile of the run
+	Heap io.Writer // heap profile written at the end of the run
+	// Labels are added to the samples of the run, e.g. to tell the jobs of a runner apart
+	Labels map[string]string
+}
+
+// SetProfile sets the profiling of the backtest runs. Only one cpu profile can be active
+// per process, a run fails if another one is already active, e.g. of go test -cpuprofile.
+func (t *Backtest) SetProfile(p Profile) {
+	t.profile = &p
+}
+
+// run executes the backtest run with the profiling.
+func (p *Profile) run(ctx context.Context, run func(context.Context) error) (err error) {
+	if p.CPU != nil {
+		if err := pprof.StartCPUProfile(p.CPU); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	if len(p.Labels) > 0 {
+		pprof.Do(ctx, pprof.Labels(p.labels()...), func(ctx context.Context) {
+			err = run(ctx)
+		})
+	} else {
+		err = run(ctx)
+	}
+
+	if p.Heap != nil {
+		// collect the garbage to get up-to-date statistics
+		runtime.GC()
+		if herr := pprof.WriteHeapProfile(p.Heap); herr != nil && err == nil {
+			err = herr
+		}
+	}
+
+	return err
+}
+
+// labels returns the labels as sorted key-value pairs.
+func (p *Profile) labels() []string {
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k, p.Labels[k])
+	}
+	return pairs
+}
@@ -0,0 +1,58 @@
+package gobacktest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBacktestProfile(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 101, 102))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	var heap bytes.Buffer
+	test.SetProfile(Profile{Heap: &heap, Labels: map[string]string{"job": "test"}})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if heap.Len() == 0 {
+		t.Errorf("Run(): expected a heap profile")
+	}
+	if len(test.portfolio.(*Portfolio).transactions) != 3 {
+		t.Errorf("Run(): expected the profiled run to fill 3 orders, actual %v", len(test.portfolio.(*Portfolio).transactions))
+	}
+}
+
+func TestBacktestProfileCPU(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 101, 102))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+
+	var cpu bytes.Buffer
+	test.SetProfile(Profile{CPU: &cpu})
+
+	// a cpu profile of go test -cpuprofile is already active
+	if err := test.Run(); err != nil {
+		t.Skipf("Run(): cpu profile not available: %v", err)
+	}
+	if cpu.Len() == 0 {
+		t.Errorf("Run(): expected a cpu profile")
+	}
+}
+
+func TestProfileLabels(t *testing.T) {
+	p := Profile{Labels: map[string]string{"symbol": "TEST.DE", "job": "sma"}}
+
+	var exp = []string{"job", "sma", "symbol", "TEST.DE"}
+	if labels := p.labels(); !reflect.DeepEqual(labels, exp) {
+		t.Errorf("labels(): expected %v, actual %v", exp, labels)
+	}
+}
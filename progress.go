@@ -0,0 +1,172 @@
+package gobacktest
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Progress is a snapshot of a running backtest.
+type Progress struct {
+	Processed       int       // data events processed
+	Total           int       // data events of the backtest, 0 if unknown
+	Percent         float64   // percent complete, 0 if unknown
+	Time            time.Time // current simulation time
+	Events          int       // events processed, including signals, orders and fills
+	EventsPerSecond float64
+	Queued          int          // events waiting in the event queue
+	Positions       int          // open positions of the portfolio
+	Equity          float64      // current value of the portfolio
+	Stats           RunningStats // statistics so far, if the statistic is a RunningStatter
+	Elapsed         time.Duration
+	Remaining       time.Duration // estimated time remaining, 0 if unknown
+	Done            bool          // the final report of the run
+}
+
+// String formats the progress as a single line, e.g. for a CLI, with the return and the
+// drawdown so far if known.
+func (p Progress) String() string {
+	line := fmt.Sprintf("%6.2f%% %s %d events %.0f ev/s elapsed %s eta %s",
+		p.Percent,
+		p.Time.Format("2006-01-02"),
+		p.Events,
+		p.EventsPerSecond,
+		p.Elapsed.Round(time.Second),
+		p.Remaining.Round(time.Second),
+	)
+	if p.Stats.Points > 0 {
+		line += fmt.Sprintf(" return %.2f%% drawdown %.2f%%", p.Stats.Return*100, p.Stats.Drawdown*100)
+	}
+	return line
+}
+
+// ProgressReporter receives the progress of a running backtest.
+type ProgressReporter interface {
+	OnProgress(Progress)
+}
+
+// ProgressFunc is a function receiving the progress of a running backtest.
+type ProgressFunc func(Progress)
+
+// OnProgress implements ProgressReporter.
+func (f ProgressFunc) OnProgress(p Progress) {
+	f(p)
+}
+
+// ProgressPrinter returns a reporter printing the progress to w on a single, refreshed line.
+func ProgressPrinter(w io.Writer) ProgressReporter {
+	return ProgressFunc(func(p Progress) {
+		fmt.Fprintf(w, "\r%s", p)
+		if p.Done {
+			fmt.Fprintln(w)
+		}
+	})
+}
+
+// progress tracks the progress of a backtest run.
+type progress struct {
+	reporter ProgressReporter
+	every    int
+	now      func() time.Time
+	start    time.Time
+	total    int
+	data     int
+	events   int
+	last     time.Time
+	// state adds the state of the engine to a snapshot
+	state func(*Progress)
+}
+
+// SetProgress sets the reporter receiving the progress of the backtest every n data events.
+func (t *Backtest) SetProgress(reporter ProgressReporter, every int) {
+	if every < 1 {
+		every = 1
+	}
+	t.progress = &progress{reporter: reporter, every: every, now: time.Now, state: t.state}
+}
+
+// state adds the event queue, the statistics so far and the portfolio of the backtest to
+// the progress.
+func (t *Backtest) state(p *Progress) {
+	p.Queued = t.eventQueue.Len()
+	if r, ok := t.statistic.(RunningStatter); ok {
+		p.Stats = r.RunningStats()
+	}
+	if t.portfolio == nil {
+		return
+	}
+	p.Equity = t.portfolio.Value()
+	if h, ok := t.portfolio.(interface{ Holdings() map[string]Position }); ok {
+		for _, pos := range h.Holdings() {
+			if pos.qty != 0 {
+				p.Positions++
+			}
+		}
+	}
+}
+
+// begin starts the tracking of a new run with the total number of data events.
+func (p *progress) begin(total int) {
+	if p == nil {
+		return
+	}
+	p.start = p.now()
+	p.total = total
+	p.data = 0
+	p.events = 0
+	p.last = time.Time{}
+}
+
+// event counts a processed event and reports the progress every n data events.
+func (p *progress) event(e EventHandler) {
+	if p == nil {
+		return
+	}
+	p.events++
+
+	// only data events advance the backtest
+	if eventClass(e) != 0 {
+		return
+	}
+	p.data++
+	p.last = e.Time()
+	if p.data%p.every == 0 {
+		p.reporter.OnProgress(p.snapshot(false))
+	}
+}
+
+// end sends the final report of a run.
+func (p *progress) end() {
+	if p == nil {
+		return
+	}
+	p.reporter.OnProgress(p.snapshot(true))
+}
+
+// snapshot returns the current progress.
+func (p *progress) snapshot(done bool) Progress {
+	elapsed := p.now().Sub(p.start)
+
+	s := Progress{
+		Processed: p.data,
+		Total:     p.total,
+		Time:      p.last,
+		Events:    p.events,
+		Elapsed:   elapsed,
+		Done:      done,
+	}
+	if p.state != nil {
+		p.state(&s)
+	}
+	if elapsed > 0 {
+		s.EventsPerSecond = float64(p.events) / elapsed.Seconds()
+	}
+	if p.total > 0 {
+		s.Percent = float64(p.data) / float64(p.total) * 100
+	}
+	if p.total > 0 && p.data > 0 && p.data <= p.total {
+		s.Remaining = time.Duration(float64(elapsed) * float64(p.total-p.data) / float64(p.data))
+	}
+
+	return s
+}
@@ -0,0 +1,92 @@
+package gobacktest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBacktestProgress(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100, 100))
+
+	var reports []Progress
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+	test.SetProgress(ProgressFunc(func(p Progress) { reports = append(reports, p) }), 2)
+
+	// a fake clock advancing one second per call
+	clock := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	test.progress.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	if len(reports) != 3 {
+		t.Fatalf("Run(): expected 3 progress reports, actual %v", len(reports))
+	}
+
+	// testCases is a table for testing the progress reports
+	var testCases = []struct {
+		msg          string
+		report       Progress
+		expProcessed int
+		expPercent   float64
+		expEvents    int
+		expRemaining time.Duration
+		expDone      bool
+	}{
+		{"first report:", reports[0], 2, 50, 5, time.Second, false},
+		{"second report:", reports[1], 4, 100, 13, 0, false},
+		{"final report:", reports[2], 4, 100, 16, 0, true},
+	}
+
+	for _, tc := range testCases {
+		p := tc.report
+		if p.Processed != tc.expProcessed || p.Percent != tc.expPercent || p.Events != tc.expEvents || p.Remaining != tc.expRemaining || p.Done != tc.expDone {
+			t.Errorf("%v expected %v %v%% %v events %v remaining %v, actual %+v",
+				tc.msg, tc.expProcessed, tc.expPercent, tc.expEvents, tc.expRemaining, tc.expDone, p)
+		}
+		if p.Total != 4 || p.EventsPerSecond <= 0 {
+			t.Errorf("%v expected total 4 and a positive event rate, actual %+v", tc.msg, p)
+		}
+	}
+
+	last, _ := time.Parse("2006-01-02", "2017-06-04")
+	if !reports[2].Time.Equal(last) {
+		t.Errorf("final report: expected simulation time %v, actual %v", last, reports[2].Time)
+	}
+	if reports[2].Positions != 1 || reports[2].Equity != test.portfolio.Value() || reports[2].Queued != 0 {
+		t.Errorf("final report: expected 1 position, the equity of the portfolio and an empty queue, actual %+v", reports[2])
+	}
+	if reports[0].Stats.Points != 2 || reports[2].Stats.Points != 4 || reports[2].Stats.Trades != len(test.statistic.Transactions()) {
+		t.Errorf("final report: expected the statistics so far, actual %+v %+v", reports[0].Stats, reports[2].Stats)
+	}
+}
+
+func TestProgressPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	printer := ProgressPrinter(&buf)
+
+	printer.OnProgress(Progress{Percent: 50, Events: 10, EventsPerSecond: 5, Elapsed: 2 * time.Second, Remaining: 2 * time.Second})
+	printer.OnProgress(Progress{Percent: 100, Events: 20, Done: true})
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "\r 50.00%") || !strings.Contains(out, "eta 2s") || !strings.HasSuffix(out, "\n") {
+		t.Errorf("ProgressPrinter(): unexpected output %q", out)
+	}
+	if strings.Contains(out, "return") {
+		t.Errorf("ProgressPrinter(): expected no return without statistics, actual %q", out)
+	}
+
+	line := Progress{Stats: RunningStats{Points: 2, Return: 0.05, Drawdown: -0.021}}.String()
+	if !strings.HasSuffix(line, " return 5.00% drawdown -2.10%") {
+		t.Errorf("String(): expected the return and the drawdown so far, actual %q", line)
+	}
+}
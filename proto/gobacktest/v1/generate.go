@@ -0,0 +1,12 @@
+// Package backtestv1 holds the Go code generated from backtest.proto, the messages and the
+// client and server of the gRPC BacktestService. The generated code is not part of the
+// repository, it needs protoc with the plugins protoc-gen-go and protoc-gen-go-grpc, e.g.
+//
+//	go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+//	go generate ./proto/...
+//
+// The server package implements the service with the grpc tag on the generated code.
+package backtestv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative backtest.proto
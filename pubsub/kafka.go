@@ -0,0 +1,556 @@
+package pubsub
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// the api keys and versions of the requests
+const (
+	kafkaProduce         = 0
+	kafkaProduceVersion  = 3 // the first version with record batches
+	kafkaMetadata        = 3
+	kafkaMetadataVersion = 1
+)
+
+// the error codes resolved by a refresh of the metadata
+const (
+	kafkaUnknownTopic       = 3
+	kafkaLeaderNotAvailable = 5
+	kafkaNotLeader          = 6
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Kafka produces messages to the topics of a Kafka cluster over the binary protocol.
+// A message is sent to the leader of its partition and waits for the acknowledgement, the
+// partition of a keyed message is chosen like the default partitioner of the Java client.
+type Kafka struct {
+	ClientID string // defaults to gobacktest
+	// WaitAll waits for the acknowledgement of all in-sync replicas, else of the leader
+	WaitAll bool
+	TLS     *tls.Config
+	// Timeout sets the wait for a request, defaults to 10 seconds
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	bootstrap []string
+	brokers   map[int32]string     // addresses by node id
+	conns     map[int32]*kafkaConn // connections by node id
+	leaders   map[string][]int32   // leaders of the partitions by topic
+	next      map[string]int       // round robin partition of the messages without key
+	meta      *kafkaConn           // connection for the metadata
+	closed    bool
+}
+
+// Dial connects to the first reachable of the bootstrap brokers, given as host:port.
+func (k *Kafka) Dial(ctx context.Context, brokers ...string) error {
+	if len(brokers) == 0 {
+		return errors.New("no kafka brokers to connect")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.bootstrap = brokers
+	k.brokers = make(map[int32]string)
+	k.conns = make(map[int32]*kafkaConn)
+	k.leaders = make(map[string][]int32)
+	k.next = make(map[string]int)
+	k.closed = false
+
+	var err error
+	for _, addr := range brokers {
+		if k.meta, err = k.dial(ctx, addr); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Publish produces a message to a topic. On a change of the leaders, e.g. while a topic is
+// created, the metadata is refreshed and the message is sent again.
+func (k *Kafka) Publish(ctx context.Context, topic string, key, value []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.meta == nil || k.closed {
+		return errors.New("kafka connection not dialed")
+	}
+
+	batch := recordBatch(key, value, time.Now())
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		err = k.send(ctx, topic, key, batch, attempt > 0)
+		if e, ok := err.(*kafkaError); !ok || !e.retriable() {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not produce to %s: %v", topic, err)
+	}
+	return nil
+}
+
+// send sends a record batch to the leader of the partition of the key.
+func (k *Kafka) send(ctx context.Context, topic string, key, batch []byte, refresh bool) error {
+	leaders, err := k.partitions(ctx, topic, refresh)
+	if err != nil {
+		return err
+	}
+	partition := k.partition(topic, key, len(leaders))
+	id := leaders[partition]
+	conn, err := k.leader(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	code, err := k.produce(ctx, conn, topic, int32(partition), batch)
+	if err != nil {
+		// the connection is dropped and reconnected on the next message
+		conn.Close()
+		delete(k.conns, id)
+		return err
+	}
+	if code != 0 {
+		return &kafkaError{code: code, partition: partition}
+	}
+	return nil
+}
+
+// Close closes the connections to the brokers.
+func (k *Kafka) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.closed = true
+	if k.meta != nil {
+		k.meta.Close()
+	}
+	for id, conn := range k.conns {
+		conn.Close()
+		delete(k.conns, id)
+	}
+	return nil
+}
+
+// partitions returns the leaders of the partitions of a topic, refreshed from the metadata.
+func (k *Kafka) partitions(ctx context.Context, topic string, refresh bool) ([]int32, error) {
+	if leaders, ok := k.leaders[topic]; ok && !refresh {
+		return leaders, nil
+	}
+
+	var req kafkaEncoder
+	req.int32(1)
+	req.string(topic)
+	resp, err := k.meta.request(ctx, kafkaMetadata, kafkaMetadataVersion, k.clientID(), req.Bytes(), k.timeout())
+	if err != nil {
+		// the broker of the metadata may be gone, another bootstrap broker is tried next time
+		k.meta.Close()
+		for _, addr := range k.bootstrap {
+			if conn, err := k.dial(ctx, addr); err == nil {
+				k.meta = conn
+				break
+			}
+		}
+		return nil, fmt.Errorf("could not fetch the metadata of %s: %v", topic, err)
+	}
+
+	d := kafkaDecoder{b: resp}
+	for i, n := 0, d.count(); i < n; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		k.brokers[int32(id)] = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	d.int32() // controller
+	var leaders []int32
+	code := int16(kafkaUnknownTopic)
+	for i, n := 0, d.count(); i < n; i++ {
+		topicCode := d.int16()
+		name := d.string()
+		d.int8() // internal
+		partitions := make([]int32, d.count())
+		for j := 0; j < len(partitions); j++ {
+			d.int16() // error code
+			index := d.int32()
+			leader := d.int32()
+			d.skipInt32s() // replicas
+			d.skipInt32s() // in-sync replicas
+			if index >= 0 && index < len(partitions) {
+				partitions[index] = int32(leader)
+			}
+		}
+		if name == topic {
+			leaders, code = partitions, topicCode
+		}
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("could not parse the metadata of %s: %v", topic, d.err)
+	}
+	if code != 0 || len(leaders) == 0 {
+		return nil, &kafkaError{code: code, partition: -1}
+	}
+	k.leaders[topic] = leaders
+	return leaders, nil
+}
+
+// partition returns the partition of a message: by the hash of the key, else round robin.
+func (k *Kafka) partition(topic string, key []byte, n int) int {
+	if len(key) > 0 {
+		return int(murmur2(key)&0x7fffffff) % n
+	}
+	p := k.next[topic] % n
+	k.next[topic] = p + 1
+	return p
+}
+
+// leader returns the connection to a broker.
+func (k *Kafka) leader(ctx context.Context, id int32) (*kafkaConn, error) {
+	if conn, ok := k.conns[id]; ok {
+		return conn, nil
+	}
+	addr, ok := k.brokers[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown kafka broker %d", id)
+	}
+	conn, err := k.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	k.conns[id] = conn
+	return conn, nil
+}
+
+// produce sends a record batch to a partition and returns the error code of the partition.
+func (k *Kafka) produce(ctx context.Context, conn *kafkaConn, topic string, partition int32, batch []byte) (int16, error) {
+	acks := int16(1)
+	if k.WaitAll {
+		acks = -1
+	}
+	timeout := k.timeout()
+
+	var req kafkaEncoder
+	req.int16(-1) // no transactional id
+	req.int16(acks)
+	req.int32(int(timeout / time.Millisecond))
+	req.int32(1)
+	req.string(topic)
+	req.int32(1)
+	req.int32(int(partition))
+	req.bytes(batch)
+
+	resp, err := conn.request(ctx, kafkaProduce, kafkaProduceVersion, k.clientID(), req.Bytes(), timeout)
+	if err != nil {
+		return 0, err
+	}
+	d := kafkaDecoder{b: resp}
+	var code int16
+	for i, n := 0, d.count(); i < n; i++ {
+		d.string()
+		for j, m := 0, d.count(); j < m; j++ {
+			d.int32() // partition
+			code = d.int16()
+			d.int64() // base offset
+			d.int64() // log append time
+		}
+	}
+	if d.err != nil {
+		return 0, fmt.Errorf("could not parse the produce response: %v", d.err)
+	}
+	return code, nil
+}
+
+// dial connects to a broker.
+func (k *Kafka) dial(ctx context.Context, addr string) (*kafkaConn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to kafka broker %s: %v", addr, err)
+	}
+	if k.TLS != nil {
+		config := k.TLS
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, config)
+		tlsConn.SetDeadline(time.Now().Add(k.timeout()))
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not secure the connection to kafka broker %s: %v", addr, err)
+		}
+		tlsConn.SetDeadline(time.Time{})
+		conn = tlsConn
+	}
+	return &kafkaConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (k *Kafka) clientID() string {
+	if k.ClientID == "" {
+		return "gobacktest"
+	}
+	return k.ClientID
+}
+
+func (k *Kafka) timeout() time.Duration {
+	if k.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return k.Timeout
+}
+
+// kafkaError is an error code of a topic or a partition.
+type kafkaError struct {
+	code      int16
+	partition int // -1 for the topic
+}
+
+func (e *kafkaError) Error() string {
+	if e.partition < 0 {
+		return fmt.Sprintf("kafka error %d of the topic", e.code)
+	}
+	return fmt.Sprintf("kafka error %d of partition %d", e.code, e.partition)
+}
+
+// retriable returns if the error is resolved by a refresh of the metadata.
+func (e *kafkaError) retriable() bool {
+	return e.code == kafkaUnknownTopic || e.code == kafkaLeaderNotAvailable || e.code == kafkaNotLeader
+}
+
+// kafkaConn is a connection to a broker, which sends a request at a time.
+type kafkaConn struct {
+	net.Conn
+	r           *bufio.Reader
+	correlation int32
+}
+
+// request sends a request and returns the body of its response.
+func (c *kafkaConn) request(ctx context.Context, apiKey, version int16, clientID string, body []byte, timeout time.Duration) ([]byte, error) {
+	c.correlation++
+	var req kafkaEncoder
+	req.int16(apiKey)
+	req.int16(version)
+	req.int32(int(c.correlation))
+	req.string(clientID)
+	req.Write(body)
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.SetDeadline(deadline)
+
+	msg := make([]byte, 4, 4+req.Len())
+	binary.BigEndian.PutUint32(msg, uint32(req.Len()))
+	if _, err := c.Write(append(msg, req.Bytes()...)); err != nil {
+		return nil, err
+	}
+
+	var head [8]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(head[:4]))
+	if size < 4 || size > 64<<20 {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	if id := int32(binary.BigEndian.Uint32(head[4:])); id != c.correlation {
+		return nil, fmt.Errorf("response %d to request %d", id, c.correlation)
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// recordBatch encodes a message as record batch of the message format v2.
+func recordBatch(key, value []byte, t time.Time) []byte {
+	var record kafkaEncoder
+	record.int8(0)   // attributes
+	record.varint(0) // timestamp delta
+	record.varint(0) // offset delta
+	if key == nil {
+		record.varint(-1)
+	} else {
+		record.varint(int64(len(key)))
+		record.Write(key)
+	}
+	record.varint(int64(len(value)))
+	record.Write(value)
+	record.varint(0) // headers
+
+	ms := t.UnixNano() / int64(time.Millisecond)
+	var rest kafkaEncoder
+	rest.int16(0) // attributes, no compression
+	rest.int32(0) // last offset delta
+	rest.int64(ms)
+	rest.int64(ms)
+	rest.int64(-1) // producer id
+	rest.int16(-1) // producer epoch
+	rest.int32(-1) // base sequence
+	rest.int32(1)  // records
+	rest.varint(int64(record.Len()))
+	rest.Write(record.Bytes())
+
+	var batch kafkaEncoder
+	batch.int64(0) // base offset
+	batch.int32(4 + 1 + 4 + rest.Len())
+	batch.int32(-1) // partition leader epoch
+	batch.int8(2)   // magic
+	batch.int32(int(crc32.Checksum(rest.Bytes(), castagnoli)))
+	batch.Write(rest.Bytes())
+	return batch.Bytes()
+}
+
+// murmur2 is the hash of the default partitioner of the Java client.
+func murmur2(data []byte) int32 {
+	const m = 0x5bd1e995
+	n := len(data)
+	h := uint32(0x9747b28c) ^ uint32(n)
+	for i := 0; i+4 <= n; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> 24
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[n&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// kafkaEncoder encodes the big endian primitives of the protocol.
+type kafkaEncoder struct {
+	bytes.Buffer
+}
+
+func (e *kafkaEncoder) int8(v int8) {
+	e.WriteByte(byte(v))
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	e.Write(b[:])
+}
+
+func (e *kafkaEncoder) int32(v int) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(int32(v)))
+	e.Write(b[:])
+}
+
+func (e *kafkaEncoder) int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	e.Write(b[:])
+}
+
+func (e *kafkaEncoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.Write(b[:binary.PutVarint(b[:], v)])
+}
+
+func (e *kafkaEncoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.WriteString(s)
+}
+
+func (e *kafkaEncoder) bytes(p []byte) {
+	e.int32(len(p))
+	e.Write(p)
+}
+
+// kafkaDecoder decodes the big endian primitives of the protocol, the first error is kept.
+type kafkaDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *kafkaDecoder) next(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.b) < n {
+		d.err = io.ErrUnexpectedEOF
+		return make([]byte, n)
+	}
+	p := d.b[:n]
+	d.b = d.b[n:]
+	return p
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	return int8(d.next(1)[0])
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	return int16(binary.BigEndian.Uint16(d.next(2)))
+}
+
+func (d *kafkaDecoder) int32() int {
+	return int(int32(binary.BigEndian.Uint32(d.next(4))))
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	return int64(binary.BigEndian.Uint64(d.next(8)))
+}
+
+// string decodes a nullable string, empty for null.
+func (d *kafkaDecoder) string() string {
+	n := int(d.int16())
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(n))
+}
+
+// count decodes the length of an array, 0 for null.
+func (d *kafkaDecoder) count() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	// an element takes at least a byte
+	if n > len(d.b) {
+		d.err = fmt.Errorf("array of %d elements exceeds the response", n)
+		return 0
+	}
+	return n
+}
+
+func (d *kafkaDecoder) skipInt32s() {
+	d.next(4 * d.count())
+}
@@ -0,0 +1,311 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// kafkaBroker is a fake broker of a cluster of one node. Its topics have two partitions,
+// the first metadata request of a topic is answered with the leader not available.
+type kafkaBroker struct {
+	ln       net.Listener
+	produced chan produced
+
+	mu       sync.Mutex
+	known    map[string]bool
+	clientID string
+	acks     int16
+}
+
+// produced is a record received by the broker.
+type produced struct {
+	topic     string
+	partition int
+	key       []byte // nil for a null key
+	value     []byte
+}
+
+func newKafkaBroker(t *testing.T) *kafkaBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	b := &kafkaBroker{ln: ln, produced: make(chan produced, 10), known: make(map[string]bool)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *kafkaBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(r, req); err != nil {
+			return
+		}
+		d := kafkaDecoder{b: req}
+		apiKey, version, correlation := d.int16(), d.int16(), d.int32()
+		clientID := d.string()
+		b.mu.Lock()
+		b.clientID = clientID
+		b.mu.Unlock()
+
+		var resp kafkaEncoder
+		resp.int32(correlation)
+		switch {
+		case apiKey == kafkaMetadata && version == kafkaMetadataVersion:
+			b.metadata(&d, &resp)
+		case apiKey == kafkaProduce && version == kafkaProduceVersion:
+			b.produce(&d, &resp)
+		default:
+			return
+		}
+		msg := make([]byte, 4)
+		binary.BigEndian.PutUint32(msg, uint32(resp.Len()))
+		conn.Write(append(msg, resp.Bytes()...))
+	}
+}
+
+func (b *kafkaBroker) metadata(d *kafkaDecoder, resp *kafkaEncoder) {
+	d.int32()
+	topic := d.string()
+	host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	resp.int32(1)
+	resp.int32(1)
+	resp.string(host)
+	resp.int32(p)
+	resp.int16(-1) // no rack
+	resp.int32(1)  // controller
+
+	b.mu.Lock()
+	known := b.known[topic]
+	b.known[topic] = true
+	b.mu.Unlock()
+	resp.int32(1)
+	if !known {
+		resp.int16(kafkaLeaderNotAvailable)
+		resp.string(topic)
+		resp.int8(0)
+		resp.int32(0)
+		return
+	}
+	resp.int16(0)
+	resp.string(topic)
+	resp.int8(0)
+	resp.int32(2)
+	for i := 0; i < 2; i++ {
+		resp.int16(0)
+		resp.int32(i)
+		resp.int32(1) // leader
+		resp.int32(1) // replicas
+		resp.int32(1)
+		resp.int32(1) // in-sync replicas
+		resp.int32(1)
+	}
+}
+
+func (b *kafkaBroker) produce(d *kafkaDecoder, resp *kafkaEncoder) {
+	d.int16() // transactional id
+	acks := d.int16()
+	d.int32() // timeout
+	d.int32()
+	topic := d.string()
+	d.int32()
+	partition := d.int32()
+	batch := d.next(d.int32())
+
+	b.mu.Lock()
+	b.acks = acks
+	b.mu.Unlock()
+	code := int16(0)
+	key, value, err := decodeBatch(batch)
+	if err != nil {
+		code = 2 // corrupt message
+	} else {
+		b.produced <- produced{topic: topic, partition: partition, key: key, value: value}
+	}
+
+	resp.int32(1)
+	resp.string(topic)
+	resp.int32(1)
+	resp.int32(partition)
+	resp.int16(code)
+	resp.int64(0)
+	resp.int64(-1)
+	resp.int32(0) // throttle time
+}
+
+// decodeBatch verifies a record batch of a single record and returns its key and value.
+func decodeBatch(batch []byte) ([]byte, []byte, error) {
+	d := kafkaDecoder{b: batch}
+	d.int64() // base offset
+	if n := d.int32(); n != len(d.b) {
+		return nil, nil, fmt.Errorf("batch length %d of %d bytes", n, len(d.b))
+	}
+	d.int32() // leader epoch
+	if magic := d.int8(); magic != 2 {
+		return nil, nil, fmt.Errorf("magic %d", magic)
+	}
+	crc := uint32(d.int32())
+	if crc != crc32.Checksum(d.b, crc32.MakeTable(crc32.Castagnoli)) {
+		return nil, nil, errors.New("invalid crc")
+	}
+	d.next(2 + 4 + 8 + 8 + 8 + 2 + 4) // attributes to base sequence
+	if n := d.int32(); n != 1 {
+		return nil, nil, fmt.Errorf("%d records", n)
+	}
+
+	varint := func() int {
+		v, n := binary.Varint(d.b)
+		d.b = d.b[n:]
+		return int(v)
+	}
+	if length := varint(); length != len(d.b) {
+		return nil, nil, fmt.Errorf("record length %d of %d bytes", length, len(d.b))
+	}
+	d.int8()
+	varint()
+	varint()
+	var key []byte
+	if n := varint(); n >= 0 {
+		key = d.next(n)
+	}
+	value := d.next(varint())
+	if headers := varint(); headers != 0 || len(d.b) != 0 || d.err != nil {
+		return nil, nil, errors.New("invalid record")
+	}
+	return key, value, nil
+}
+
+func TestKafkaPublish(t *testing.T) {
+	b := newKafkaBroker(t)
+	defer b.ln.Close()
+
+	k := &Kafka{ClientID: "backtest", WaitAll: true, Timeout: time.Second}
+	if err := k.Dial(context.Background(), "127.0.0.1:1", b.ln.Addr().String()); err != nil {
+		t.Fatalf("Dial(): expected the second bootstrap broker, actual %v", err)
+	}
+	defer k.Close()
+
+	// the topic is created on the first message
+	if err := k.Publish(context.Background(), "gobacktest.fill", []byte("TEST.DE"), []byte(`{"qty":10}`)); err != nil {
+		t.Fatalf("Publish(): unexpected error %v", err)
+	}
+	p := <-b.produced
+	expPartition := int(murmur2([]byte("TEST.DE"))&0x7fffffff) % 2
+	if p.topic != "gobacktest.fill" || p.partition != expPartition || string(p.key) != "TEST.DE" || string(p.value) != `{"qty":10}` {
+		t.Errorf("Publish(): unexpected record %+v", p)
+	}
+	if b.clientID != "backtest" || b.acks != -1 {
+		t.Errorf("Publish(): expected the client id and all acks, actual %v %v", b.clientID, b.acks)
+	}
+
+	// the messages without key are spread over the partitions
+	var partitions []int
+	for i := 0; i < 3; i++ {
+		if err := k.Publish(context.Background(), "gobacktest.fill", nil, []byte("{}")); err != nil {
+			t.Fatalf("Publish(): unexpected error %v", err)
+		}
+		p := <-b.produced
+		if p.key != nil {
+			t.Errorf("Publish(): expected a null key, actual %q", p.key)
+		}
+		partitions = append(partitions, p.partition)
+	}
+	if fmt.Sprint(partitions) != "[0 1 0]" {
+		t.Errorf("Publish(): expected round robin partitions, actual %v", partitions)
+	}
+
+	k.Close()
+	if err := k.Publish(context.Background(), "gobacktest.fill", nil, nil); err == nil {
+		t.Errorf("Publish(): expected an error after close")
+	}
+}
+
+func TestKafkaErrors(t *testing.T) {
+	if err := (&Kafka{}).Dial(context.Background()); err == nil {
+		t.Errorf("Dial(): expected an error without brokers")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	if err := (&Kafka{}).Dial(context.Background(), addr); err == nil || !strings.Contains(err.Error(), "could not connect") {
+		t.Errorf("Dial(): expected the unreachable broker, actual %v", err)
+	}
+
+	// a broker, which closes the connection on every request
+	ln, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.Read(make([]byte, 64))
+				conn.Close()
+			}()
+		}
+	}()
+	k := &Kafka{Timeout: time.Second}
+	if err := k.Dial(context.Background(), ln.Addr().String()); err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	if err := k.Publish(context.Background(), "gobacktest.fill", nil, nil); err == nil || !strings.Contains(err.Error(), "could not fetch the metadata") {
+		t.Errorf("Publish(): expected the failed metadata, actual %v", err)
+	}
+	k.Close()
+}
+
+func TestMurmur2(t *testing.T) {
+	// testCases is a table for testing the hash against the Java client
+	var testCases = []struct {
+		key string
+		exp int32
+	}{
+		{"21", -973932308},
+		{"foobar", -790332482},
+		{"a-little-bit-long-string", -985981536},
+		{"a-little-bit-longer-string", -1486304829},
+		{"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", -58897971},
+		{"abc", 479470107},
+	}
+
+	for _, tc := range testCases {
+		if h := murmur2([]byte(tc.key)); h != tc.exp {
+			t.Errorf("murmur2(%q): expected %v, actual %v", tc.key, tc.exp, h)
+		}
+	}
+}
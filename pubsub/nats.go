@@ -0,0 +1,260 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATS publishes messages to the subjects of a NATS server over its text protocol.
+// A subject carries no key, so the key of a message is dropped. The messages are sent
+// at most once, Flush waits until the server processed the messages sent before.
+type NATS struct {
+	Name     string // of the connection, shown by the server
+	User     string
+	Password string
+	Token    string
+	TLS      *tls.Config // upgrades the connection, if set or required by the server
+	// Timeout sets the wait for the connect and for a flush, defaults to 10 seconds
+	Timeout time.Duration
+
+	conn       net.Conn
+	maxPayload int
+	w          *bufio.Writer
+	mu         sync.Mutex // guards w and err
+	err        error      // of the server or the connection
+	pongs      chan struct{}
+	done       chan struct{}
+}
+
+// natsInfo is the INFO of a server.
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// Dial connects to a server at an address like localhost:4222 or nats://localhost:4222.
+func (n *NATS) Dial(ctx context.Context, addr string) error {
+	addr = strings.TrimPrefix(strings.TrimPrefix(addr, "nats://"), "tls://")
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not connect to nats server %s: %v", addr, err)
+	}
+
+	timeout := n.timeout()
+	conn.SetDeadline(time.Now().Add(timeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("could not read the info of nats server %s: %q %v", addr, line, err)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("could not parse the info of nats server %s: %v", addr, err)
+	}
+
+	if n.TLS != nil || info.TLSRequired {
+		config := n.TLS
+		if config == nil {
+			config = &tls.Config{ServerName: strings.Split(addr, ":")[0]}
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return fmt.Errorf("could not secure the connection to nats server %s: %v", addr, err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+
+	connect := map[string]interface{}{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": n.TLS != nil || info.TLSRequired,
+		"name":         n.Name,
+		"lang":         "go",
+		"version":      "1.0.0",
+		"protocol":     1,
+	}
+	if n.User != "" {
+		connect["user"], connect["pass"] = n.User, n.Password
+	}
+	if n.Token != "" {
+		connect["auth_token"] = n.Token
+	}
+	p, err := json.Marshal(connect)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", p); err != nil {
+		conn.Close()
+		return fmt.Errorf("could not connect to nats server %s: %v", addr, err)
+	}
+
+	// the server answers the ping after accepting the connect
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("could not connect to nats server %s: %v", addr, err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return fmt.Errorf("nats server %s refused the connect: %s", addr, natsError(line))
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	n.conn = conn
+	n.maxPayload = info.MaxPayload
+	n.w = bufio.NewWriter(conn)
+	n.err = nil
+	n.pongs = make(chan struct{}, 1)
+	n.done = make(chan struct{})
+	go n.read(r)
+	return nil
+}
+
+// Publish sends a message to a subject. An error of the server is returned on the next
+// publish or flush.
+func (n *NATS) Publish(ctx context.Context, subject string, key, value []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid subject %q", subject)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return errors.New("nats connection not dialed")
+	}
+	if n.err != nil {
+		return n.err
+	}
+	if n.maxPayload > 0 && len(value) > n.maxPayload {
+		return fmt.Errorf("message of %d bytes exceeds the max payload %d of the server", len(value), n.maxPayload)
+	}
+	deadline, _ := ctx.Deadline()
+	n.conn.SetWriteDeadline(deadline)
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", subject, len(value))
+	n.w.Write(value)
+	n.w.WriteString("\r\n")
+	if err := n.w.Flush(); err != nil {
+		n.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush waits until the server processed the messages sent before.
+func (n *NATS) Flush(ctx context.Context) error {
+	n.mu.Lock()
+	if n.conn == nil {
+		n.mu.Unlock()
+		return errors.New("nats connection not dialed")
+	}
+	if n.err != nil {
+		n.mu.Unlock()
+		return n.err
+	}
+	n.w.WriteString("PING\r\n")
+	err := n.w.Flush()
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	timeout := time.NewTimer(n.timeout())
+	defer timeout.Stop()
+	select {
+	case <-n.pongs:
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return n.err
+	case <-n.done:
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return n.err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout.C:
+		return errors.New("no answer of the nats server")
+	}
+}
+
+// Close flushes the sent messages and closes the connection.
+func (n *NATS) Close() error {
+	if n.conn == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout())
+	defer cancel()
+	err := n.Flush(ctx)
+	n.conn.Close()
+	<-n.done
+	return err
+}
+
+// read processes the messages of the server: answers the pings, signals the pongs and
+// records the errors.
+func (n *NATS) read(r *bufio.Reader) {
+	defer close(n.done)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			n.fail(fmt.Errorf("nats connection lost: %v", err))
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			n.mu.Lock()
+			n.w.WriteString("PONG\r\n")
+			n.w.Flush()
+			n.mu.Unlock()
+		case line == "PONG":
+			select {
+			case n.pongs <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			n.fail(fmt.Errorf("nats server error: %s", natsError(line)))
+		}
+	}
+}
+
+// fail records the first error of the connection.
+func (n *NATS) fail(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err == nil {
+		n.err = err
+	}
+}
+
+// timeout returns the wait for the server.
+func (n *NATS) timeout() time.Duration {
+	if n.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return n.Timeout
+}
+
+// natsError returns the text of an -ERR message.
+func natsError(line string) string {
+	return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'")
+}
@@ -0,0 +1,143 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// natsServer is a fake NATS server of a single connection. It answers the pings, records
+// the connect and the published messages and refuses the connect of the user refused.
+type natsServer struct {
+	ln        net.Listener
+	connect   chan string
+	published chan message
+	conn      chan net.Conn
+}
+
+func newNATSServer(t *testing.T) *natsServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	s := &natsServer{ln: ln, connect: make(chan string, 1), published: make(chan message, 10), conn: make(chan net.Conn, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *natsServer) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	s.conn <- conn
+
+	conn.Write([]byte(`INFO {"server_id":"test","max_payload":64}` + "\r\n"))
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "CONNECT":
+			s.connect <- fields[1]
+			if strings.Contains(fields[1], `"user":"refused"`) {
+				conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+				return
+			}
+		case fields[0] == "PING":
+			conn.Write([]byte("PONG\r\n"))
+		case fields[0] == "PUB" && len(fields) == 3:
+			n, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.published <- message{topic: fields[1], value: payload[:n]}
+		}
+	}
+}
+
+func TestNATSPublish(t *testing.T) {
+	s := newNATSServer(t)
+	defer s.ln.Close()
+
+	n := &NATS{Name: "backtest", User: "user", Password: "secret", Timeout: time.Second}
+	if err := n.Dial(context.Background(), "nats://"+s.ln.Addr().String()); err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	if connect := <-s.connect; !strings.Contains(connect, `"user":"user"`) || !strings.Contains(connect, `"pass":"secret"`) || !strings.Contains(connect, `"name":"backtest"`) {
+		t.Errorf("Dial(): unexpected connect %v", connect)
+	}
+
+	if err := n.Publish(context.Background(), "gobacktest.fill", []byte("TEST.DE"), []byte(`{"qty":10}`)); err != nil {
+		t.Fatalf("Publish(): unexpected error %v", err)
+	}
+	if err := n.Flush(context.Background()); err != nil {
+		t.Errorf("Flush(): unexpected error %v", err)
+	}
+	if m := <-s.published; m.topic != "gobacktest.fill" || string(m.value) != `{"qty":10}` {
+		t.Errorf("Publish(): unexpected message %v %s", m.topic, m.value)
+	}
+
+	// testCases is a table for testing invalid messages
+	var testCases = []struct {
+		msg     string
+		subject string
+		value   string
+		expErr  string
+	}{
+		{"no subject:", "", "{}", "invalid subject"},
+		{"space in subject:", "a b", "{}", "invalid subject"},
+		{"too large:", "gobacktest.fill", strings.Repeat("x", 65), "exceeds the max payload 64"},
+	}
+
+	for _, tc := range testCases {
+		if err := n.Publish(context.Background(), tc.subject, nil, []byte(tc.value)); err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("%v Publish(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+		}
+	}
+
+	if err := n.Close(); err != nil {
+		t.Errorf("Close(): unexpected error %v", err)
+	}
+}
+
+func TestNATSErrors(t *testing.T) {
+	s := newNATSServer(t)
+	defer s.ln.Close()
+	n := &NATS{User: "refused", Timeout: time.Second}
+	if err := n.Dial(context.Background(), s.ln.Addr().String()); err == nil || !strings.HasSuffix(err.Error(), "refused the connect: Authorization Violation") {
+		t.Errorf("Dial(): expected the refused connect, actual %v", err)
+	}
+
+	// an error of the server is returned by the next publish
+	s = newNATSServer(t)
+	defer s.ln.Close()
+	n = &NATS{Timeout: time.Second}
+	if err := n.Dial(context.Background(), s.ln.Addr().String()); err != nil {
+		t.Fatalf("Dial(): unexpected error %v", err)
+	}
+	conn := <-s.conn
+	conn.Write([]byte("-ERR 'Permissions Violation for Publish to gobacktest.fill'\r\n"))
+	if err := n.Flush(context.Background()); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("Flush(): expected the error of the server, actual %v", err)
+	}
+	if err := n.Publish(context.Background(), "gobacktest.fill", nil, nil); err == nil {
+		t.Errorf("Publish(): expected the error of the server")
+	}
+	n.Close()
+
+	if err := (&NATS{}).Publish(context.Background(), "gobacktest.fill", nil, nil); err == nil {
+		t.Errorf("Publish(): expected an error without connection")
+	}
+}
@@ -0,0 +1,193 @@
+// Package pubsub publishes the event stream of a run, e.g. the fills, the signals and the
+// snapshots of the portfolio, to Kafka or NATS topics, so downstream systems consume the
+// output of a backtest or of paper trading in real time.
+//
+//	k := &pubsub.Kafka{}
+//	if err := k.Dial(ctx, "localhost:9092"); err != nil {
+//		return err
+//	}
+//	defer k.Close()
+//	s := &pubsub.Streamer{Publisher: k, Portfolio: portfolio}
+//	test.Use(s.Middleware())
+//
+// The events are published as json encoded gobacktest.EventRecord to the topic of their
+// type, e.g. gobacktest.fill, keyed by their symbol.
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// KindPortfolio is the kind of the snapshots of the portfolio.
+const KindPortfolio = "portfolio"
+
+// Publisher publishes a message to a topic, e.g. a Kafka topic or a NATS subject.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+	Close() error
+}
+
+// PublisherFunc is a function publishing a message.
+type PublisherFunc func(ctx context.Context, topic string, key, value []byte) error
+
+// Publish implements Publisher.
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return f(ctx, topic, key, value)
+}
+
+// Close implements Publisher, it does nothing.
+func (f PublisherFunc) Close() error {
+	return nil
+}
+
+// Snapshot is the state of the portfolio after a data event.
+type Snapshot struct {
+	Time      time.Time          `json:"time"`
+	Cash      float64            `json:"cash"`
+	Value     float64            `json:"value"`
+	Positions map[string]Holding `json:"positions,omitempty"`
+}
+
+// Holding is an open position of a snapshot.
+type Holding struct {
+	Qty         float64 `json:"qty"`
+	AvgPrice    float64 `json:"avgPrice"`
+	MarketPrice float64 `json:"marketPrice"`
+}
+
+// holder is a portfolio with holdings.
+type holder interface {
+	Holdings() map[string]gbt.Position
+}
+
+// Streamer publishes the selected types of events of a run. The messages are published
+// synchronously, a failed publish never stops the run.
+type Streamer struct {
+	Publisher Publisher
+	Prefix    string   // of the topics, defaults to gobacktest
+	Events    []string // record types to publish, defaults to fill, order, signal and rejection
+	// Portfolio publishes a snapshot of the portfolio after every data event, if set
+	Portfolio gbt.PortfolioHandler
+	Timeout   time.Duration // of a single publish, defaults to 10 seconds
+	// OnError receives the errors of the publishes, which are dropped otherwise
+	OnError func(error)
+}
+
+// Topic returns the topic of a kind of message, e.g. gobacktest.fill.
+func (s *Streamer) Topic(kind string) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "gobacktest"
+	}
+	return prefix + "." + kind
+}
+
+// Middleware returns the middleware publishing the processed events.
+func (s *Streamer) Middleware() gbt.Middleware {
+	return func(next gbt.EventFunc) gbt.EventFunc {
+		return func(e gbt.EventHandler) error {
+			if err := next(e); err != nil {
+				return err
+			}
+
+			r := gbt.NewEventRecord(e)
+			if s.selected(r.Type) {
+				r.Values, r.Metric = finite(r.Values), finite(r.Metric)
+				s.publish(r.Type, r.Symbol, r)
+			}
+			switch r.Type {
+			case "bar", "tick", "book":
+				if s.Portfolio != nil {
+					s.publish(KindPortfolio, "", s.snapshot(r.Time))
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// snapshot returns the snapshot of the portfolio.
+func (s *Streamer) snapshot(t time.Time) Snapshot {
+	snapshot := Snapshot{Time: t, Cash: s.Portfolio.Cash(), Value: s.Portfolio.Value()}
+	if h, ok := s.Portfolio.(holder); ok {
+		for symbol, p := range h.Holdings() {
+			if p.Qty() == 0 {
+				continue
+			}
+			// check for nil map, else initialise the map
+			if snapshot.Positions == nil {
+				snapshot.Positions = make(map[string]Holding)
+			}
+			snapshot.Positions[symbol] = Holding{Qty: p.Qty(), AvgPrice: p.AvgPrice(), MarketPrice: p.MarketPrice()}
+		}
+	}
+	return snapshot
+}
+
+// publish publishes a message as json.
+func (s *Streamer) publish(kind, key string, v interface{}) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		s.error(fmt.Errorf("could not encode %s message: %v", kind, err))
+		return
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := s.Publisher.Publish(ctx, s.Topic(kind), []byte(key), value); err != nil {
+		s.error(fmt.Errorf("could not publish %s message: %v", kind, err))
+	}
+}
+
+// error hands an error to OnError.
+func (s *Streamer) error(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+// selected returns if a record type is published.
+func (s *Streamer) selected(kind string) bool {
+	events := s.Events
+	if len(events) == 0 {
+		events = []string{"fill", "order", "signal", "rejection"}
+	}
+	for _, k := range events {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// finite returns the values without the undefined values, which can not be encoded as json.
+// The values may belong to the event, so they are copied.
+func finite(values map[string]float64) map[string]float64 {
+	var copied map[string]float64
+	for k, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			continue
+		}
+		if copied == nil {
+			copied = make(map[string]float64, len(values))
+			for k, v := range values {
+				copied[k] = v
+			}
+		}
+		delete(copied, k)
+	}
+	if copied == nil {
+		return values
+	}
+	return copied
+}
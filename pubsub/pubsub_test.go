@@ -0,0 +1,177 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// message is a published message.
+type message struct {
+	topic string
+	key   string
+	value []byte
+}
+
+// recorder is a publisher recording the messages.
+type recorder struct {
+	messages []message
+}
+
+func (r *recorder) Publish(ctx context.Context, topic string, key, value []byte) error {
+	r.messages = append(r.messages, message{topic: topic, key: string(key), value: value})
+	return nil
+}
+
+func (r *recorder) Close() error {
+	return nil
+}
+
+// topics returns the number of messages by topic.
+func (r *recorder) topics() map[string]int {
+	topics := make(map[string]int)
+	for _, m := range r.messages {
+		topics[m.topic]++
+	}
+	return topics
+}
+
+// newTestBacktest builds a trading backtest of the test data and returns it with its portfolio.
+func newTestBacktest(t *testing.T) (*gbt.Backtest, *gbt.Portfolio, int) {
+	d := &data.BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := d.Load([]string{"SDF.DE"}); err != nil {
+		t.Fatal(err)
+	}
+	var stream []gbt.DataEvent
+	for _, e := range d.Stream() {
+		if e.Time().Year() == 2016 {
+			stream = append(stream, e)
+		}
+	}
+	d.SetStream(stream)
+
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetSizeManager(&gbt.Size{DefaultSize: 100, DefaultValue: 10000})
+
+	test := gbt.New()
+	test.SetSymbols([]string{"SDF.DE"})
+	test.SetData(d)
+	test.SetPortfolio(portfolio)
+	test.SetStrategy(strategy.MovingAverageCross(5, 20))
+	return test, portfolio, len(stream)
+}
+
+func TestStreamerMiddleware(t *testing.T) {
+	// testCases is a table for testing the selection of the published events
+	var testCases = []struct {
+		msg       string
+		events    []string
+		portfolio bool
+		expect    []string
+	}{
+		{"default events:", nil, false, []string{"gobacktest.fill", "gobacktest.order", "gobacktest.signal"}},
+		{"selected events with snapshots:", []string{"fill"}, true, []string{"gobacktest.fill", "gobacktest.portfolio"}},
+	}
+
+	for _, tc := range testCases {
+		rec := &recorder{}
+		test, portfolio, bars := newTestBacktest(t)
+		s := &Streamer{Publisher: rec, Events: tc.events}
+		if tc.portfolio {
+			s.Portfolio = portfolio
+		}
+		test.Use(s.Middleware())
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+		}
+
+		topics := rec.topics()
+		for _, topic := range tc.expect {
+			if topics[topic] == 0 {
+				t.Errorf("%v\nexpected messages of %s, actual %v", tc.msg, topic, topics)
+			}
+		}
+		if len(topics) != len(tc.expect) {
+			t.Errorf("%v\nexpected only the topics %v, actual %v", tc.msg, tc.expect, topics)
+		}
+		if tc.portfolio && topics["gobacktest.portfolio"] != bars {
+			t.Errorf("%v\nexpected a snapshot per bar, actual %v of %v", tc.msg, topics["gobacktest.portfolio"], bars)
+		}
+
+		for _, m := range rec.messages {
+			switch m.topic {
+			case "gobacktest.fill":
+				var r gbt.EventRecord
+				if err := json.Unmarshal(m.value, &r); err != nil || m.key != "SDF.DE" || r.Type != "fill" || r.Values["qty"] == 0 {
+					t.Errorf("%v\nunexpected fill %s %s %v", tc.msg, m.key, m.value, err)
+				}
+			case "gobacktest.portfolio":
+				var snapshot Snapshot
+				if err := json.Unmarshal(m.value, &snapshot); err != nil || snapshot.Value == 0 || snapshot.Time.IsZero() {
+					t.Errorf("%v\nunexpected snapshot %s %v", tc.msg, m.value, err)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamerSnapshot(t *testing.T) {
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetInitialCash(1000)
+	fill := gbt.NewFill(time.Now(), "TEST.DE", gbt.BOT, 10, 20, 0)
+	portfolio.OnFill(fill, nil)
+
+	s := &Streamer{Portfolio: portfolio}
+	snapshot := s.snapshot(time.Unix(0, 0))
+	if h, ok := snapshot.Positions["TEST.DE"]; !ok || h.Qty != 10 || h.AvgPrice != 20 || len(snapshot.Positions) != 1 {
+		t.Errorf("snapshot(): expected the position, actual %+v", snapshot)
+	}
+}
+
+func TestStreamerErrors(t *testing.T) {
+	var errs []error
+	s := &Streamer{
+		Publisher: PublisherFunc(func(ctx context.Context, topic string, key, value []byte) error {
+			return errors.New("offline")
+		}),
+		Prefix:  "paper",
+		OnError: func(err error) { errs = append(errs, err) },
+	}
+
+	signal := &gbt.Signal{}
+	signal.SetSymbol("TEST.DE")
+	h := s.Middleware()(func(gbt.EventHandler) error { return nil })
+	if err := h(signal); err != nil {
+		t.Errorf("Middleware(): expected a failed publish to never stop the run, actual %v", err)
+	}
+	if len(errs) != 1 || errs[0].Error() != "could not publish signal message: offline" {
+		t.Errorf("OnError: expected the failed publish, actual %v", errs)
+	}
+	if topic := s.Topic("fill"); topic != "paper.fill" {
+		t.Errorf("Topic(): expected the prefix, actual %v", topic)
+	}
+
+	failed := errors.New("no data")
+	h = s.Middleware()(func(gbt.EventHandler) error { return failed })
+	if err := h(signal); err != failed || len(errs) != 1 {
+		t.Errorf("Middleware(): expected the error of the event without publish, actual %v", err)
+	}
+}
+
+func TestFinite(t *testing.T) {
+	values := map[string]float64{"sma": math.NaN(), "close": 10, "ratio": math.Inf(1)}
+	clean := finite(values)
+	if len(clean) != 1 || clean["close"] != 10 || len(values) != 3 {
+		t.Errorf("finite(): expected the finite values of a copy, actual %v of %v", clean, values)
+	}
+	if valid := map[string]float64{"close": 10}; len(finite(valid)) != 1 {
+		t.Errorf("finite(): expected the valid values")
+	}
+}
@@ -0,0 +1,72 @@
+package gobacktest
+
+import (
+	"sort"
+)
+
+// symbolLister is a data handler which knows the symbols of its data events.
+type symbolLister interface {
+	Symbols() []string
+}
+
+// Symbols returns the sorted symbols of the already streamed data events.
+func (d *Data) Symbols() []string {
+	symbols := []string{}
+	for symbol := range d.latest {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Snapshot returns the cross section of the data at the current time, the latest data event
+// of each symbol ordered by symbol. A data handler of a universe only returns the members
+// at the time, any other data handler all symbols streamed so far. A symbol without a data
+// event at the current time is part of the snapshot with its last data event.
+func Snapshot(data DataHandler) []DataEvent {
+	var symbols []string
+	switch d := data.(type) {
+	case UniverseLister:
+		symbols = d.Members()
+	case symbolLister:
+		symbols = d.Symbols()
+	}
+
+	events := []DataEvent{}
+	for _, symbol := range symbols {
+		if event := data.Latest(symbol); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Rank orders the data events by the value of a metric, highest first, e.g. a momentum
+// indicator of each symbol of a snapshot. Data events without the metric are left out,
+// equal values are ordered by symbol.
+func Rank(events []DataEvent, metric string) []DataEvent {
+	type value struct {
+		event DataEvent
+		v     float64
+	}
+
+	var values []value
+	for _, event := range events {
+		if v, ok := event.Get(metric); ok {
+			values = append(values, value{event: event, v: v})
+		}
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].v == values[j].v {
+			return values[i].event.Symbol() < values[j].event.Symbol()
+		}
+		return values[i].v > values[j].v
+	})
+
+	ranked := make([]DataEvent, len(values))
+	for i := range values {
+		ranked[i] = values[i].event
+	}
+	return ranked
+}
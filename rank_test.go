@@ -0,0 +1,92 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// symbolsOf returns the symbols of the data events.
+func symbolsOf(events []DataEvent) []string {
+	symbols := []string{}
+	for _, e := range events {
+		symbols = append(symbols, e.Symbol())
+	}
+	return symbols
+}
+
+func TestSnapshot(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01")
+	var day2 = day1.AddDate(0, 0, 1)
+
+	stream := func() []DataEvent {
+		return []DataEvent{
+			&Bar{Event: Event{timestamp: day1, symbol: "AAA"}, Close: 10},
+			&Bar{Event: Event{timestamp: day1, symbol: "BBB"}, Close: 20},
+			&Bar{Event: Event{timestamp: day2, symbol: "AAA"}, Close: 11},
+			&Bar{Event: Event{timestamp: day2, symbol: "CCC"}, Close: 30},
+		}
+	}
+	u := &Universe{}
+	u.Add("AAA", day1, time.Time{})
+	u.Add("BBB", day1, day2)
+	u.Add("CCC", day2, time.Time{})
+
+	// testCases is a table for testing the snapshot after a number of data events
+	var testCases = []struct {
+		msg       string
+		data      DataHandler
+		events    int
+		expSymbol []string
+		expPrices []float64
+	}{
+		{"no data events:", &Data{stream: stream()}, 0, []string{}, []float64{}},
+		{"all symbols streamed so far:", &Data{stream: stream()}, 3, []string{"AAA", "BBB"}, []float64{11, 20}},
+		{"latest event of each symbol:", &Data{stream: stream()}, 4, []string{"AAA", "BBB", "CCC"}, []float64{11, 20, 30}},
+		{"members of the universe:", NewUniverseData(&Data{stream: stream()}, u), 4, []string{"AAA", "CCC"}, []float64{11, 30}},
+	}
+
+	for _, tc := range testCases {
+		for i := 0; i < tc.events; i++ {
+			tc.data.Next()
+		}
+		snapshot := Snapshot(tc.data)
+		prices := []float64{}
+		for _, e := range snapshot {
+			prices = append(prices, e.Price())
+		}
+		if !reflect.DeepEqual(symbolsOf(snapshot), tc.expSymbol) || !reflect.DeepEqual(prices, tc.expPrices) {
+			t.Errorf("%v Snapshot(): expected %v %v, actual %v %v", tc.msg, tc.expSymbol, tc.expPrices, symbolsOf(snapshot), prices)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	bar := func(symbol string, metric Metric) DataEvent {
+		return &Bar{Event: Event{symbol: symbol}, Metric: metric}
+	}
+
+	// testCases is a table for testing the ranking by a metric
+	var testCases = []struct {
+		msg    string
+		events []DataEvent
+		exp    []string
+	}{
+		{"highest first:",
+			[]DataEvent{bar("AAA", Metric{"MOM": 0.1}), bar("BBB", Metric{"MOM": 0.3}), bar("CCC", Metric{"MOM": -0.2})},
+			[]string{"BBB", "AAA", "CCC"}},
+		{"equal values by symbol:",
+			[]DataEvent{bar("CCC", Metric{"MOM": 0.1}), bar("AAA", Metric{"MOM": 0.1}), bar("BBB", Metric{"MOM": 0.2})},
+			[]string{"BBB", "AAA", "CCC"}},
+		{"without the metric:",
+			[]DataEvent{bar("AAA", Metric{"SMA": 1}), bar("BBB", Metric{"MOM": 0.3}), bar("CCC", nil)},
+			[]string{"BBB"}},
+		{"no events:", nil, []string{}},
+	}
+
+	for _, tc := range testCases {
+		if ranked := symbolsOf(Rank(tc.events, "MOM")); !reflect.DeepEqual(ranked, tc.exp) {
+			t.Errorf("%v Rank(): expected %v, actual %v", tc.msg, tc.exp, ranked)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package gobacktest
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Regime defines a market regime.
+type Regime int
+
+// different market regimes
+const (
+	// RegimeUnknown is returned without enough data
+	RegimeUnknown Regime = iota // 0
+	// LowVolatility is a calm market
+	LowVolatility
+	// NormalVolatility is a market with usual volatility
+	NormalVolatility
+	// HighVolatility is a turbulent market
+	HighVolatility
+	// TrendUp is a rising market
+	TrendUp
+	// TrendDown is a falling market
+	TrendDown
+	// Range is a market moving sideways
+	Range
+)
+
+// String returns the name of a regime.
+func (r Regime) String() string {
+	switch r {
+	case LowVolatility:
+		return "low volatility"
+	case NormalVolatility:
+		return "normal volatility"
+	case HighVolatility:
+		return "high volatility"
+	case TrendUp:
+		return "trend up"
+	case TrendDown:
+		return "trend down"
+	case Range:
+		return "range"
+	}
+	return "unknown"
+}
+
+// RegimeClassifier classifies the market regime of a symbol from its recent data events.
+// The regime is attached to each data event as metric with the name of the classifier.
+type RegimeClassifier interface {
+	Name() string
+	Lookback() int
+	Classify([]DataEvent) Regime
+}
+
+// VolatilityRegime buckets the annualised volatility of the returns of the Period
+// into low (below Low), normal and high (above High) volatility, e.g. Low 0.1 and High 0.3.
+type VolatilityRegime struct {
+	Period         int
+	Low            float64
+	High           float64
+	PeriodsPerYear float64 // defaults to 252
+}
+
+// Name returns the metric name of the classifier.
+func (v *VolatilityRegime) Name() string {
+	return "VOLREGIME"
+}
+
+// Lookback returns the number of data events needed for the classification.
+func (v *VolatilityRegime) Lookback() int {
+	return v.Period + 1
+}
+
+// Classify returns the volatility regime of the data events.
+func (v *VolatilityRegime) Classify(events []DataEvent) Regime {
+	if v.Period < 2 || len(events) < v.Lookback() {
+		return RegimeUnknown
+	}
+	events = events[len(events)-v.Lookback():]
+
+	var returns []float64
+	for i := 1; i < len(events); i++ {
+		if events[i-1].Price() == 0 {
+			continue
+		}
+		returns = append(returns, events[i].Price()/events[i-1].Price()-1)
+	}
+
+	periods := v.PeriodsPerYear
+	if periods <= 0 {
+		periods = 252
+	}
+	vol := stat.StdDev(returns, nil) * math.Sqrt(periods)
+
+	switch {
+	case math.IsNaN(vol):
+		return RegimeUnknown
+	case vol < v.Low:
+		return LowVolatility
+	case vol > v.High:
+		return HighVolatility
+	}
+	return NormalVolatility
+}
+
+// TrendRegime classifies the trend by the slope of the simple moving average of the Period,
+// measured over the last Slope data events relative to the average. A slope within
+// the Threshold, e.g. 0.01 for 1%, is a range.
+type TrendRegime struct {
+	Period    int
+	Slope     int // defaults to 1
+	Threshold float64
+}
+
+// Name returns the metric name of the classifier.
+func (r *TrendRegime) Name() string {
+	return "TRENDREGIME"
+}
+
+// Lookback returns the number of data events needed for the classification.
+func (r *TrendRegime) Lookback() int {
+	return r.Period + r.slope()
+}
+
+// Classify returns the trend regime of the data events.
+func (r *TrendRegime) Classify(events []DataEvent) Regime {
+	if r.Period < 1 || len(events) < r.Lookback() {
+		return RegimeUnknown
+	}
+	events = events[len(events)-r.Lookback():]
+
+	current := meanPrice(events[r.slope():])
+	previous := meanPrice(events[:r.Period])
+	if previous == 0 {
+		return RegimeUnknown
+	}
+
+	slope := current/previous - 1
+	switch {
+	case slope > r.Threshold:
+		return TrendUp
+	case slope < -r.Threshold:
+		return TrendDown
+	}
+	return Range
+}
+
+// slope returns the number of data events the slope is measured over.
+func (r *TrendRegime) slope() int {
+	if r.Slope <= 0 {
+		return 1
+	}
+	return r.Slope
+}
+
+// meanPrice returns the average price of the data events.
+func meanPrice(events []DataEvent) float64 {
+	var sum float64
+	for _, e := range events {
+		sum += e.Price()
+	}
+	return sum / float64(len(events))
+}
+
+// RegimeOf returns the regime a classifier attached to a data event.
+func RegimeOf(event DataEvent, name string) (Regime, bool) {
+	value, ok := event.Get(name)
+	if !ok {
+		return RegimeUnknown, false
+	}
+	return Regime(value), true
+}
+
+// SetRegimes sets the regime classifiers, which attach their regime to every data event
+// before the event is processed.
+func (t *Backtest) SetRegimes(classifiers ...RegimeClassifier) {
+	t.regimes = classifiers
+}
+
+// classify attaches the regime of every classifier to the data event.
+func (t *Backtest) classify(event DataEvent) {
+	for _, c := range t.regimes {
+		window := t.data.Window(event.Symbol(), c.Lookback())
+		event.Add(c.Name(), float64(c.Classify(window)))
+	}
+}
@@ -0,0 +1,91 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+// newRegimeWindow creates daily bars with the given close prices.
+func newRegimeWindow(closes ...float64) []DataEvent {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	var events []DataEvent
+	for i, c := range closes {
+		events = append(events, &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: "TEST.DE"}, Metric: Metric{}, Close: c})
+	}
+	return events
+}
+
+func TestRegimeClassify(t *testing.T) {
+	// testCases is a table for testing the regime classifiers
+	var testCases = []struct {
+		msg        string
+		classifier RegimeClassifier
+		events     []DataEvent
+		exp        Regime
+	}{
+		{"not enough data:",
+			&VolatilityRegime{Period: 3, Low: 0.1, High: 0.3},
+			newRegimeWindow(100, 101),
+			RegimeUnknown,
+		},
+		{"low volatility:",
+			&VolatilityRegime{Period: 3, Low: 0.1, High: 0.3},
+			newRegimeWindow(100, 100, 100, 100),
+			LowVolatility,
+		},
+		{"normal volatility:",
+			&VolatilityRegime{Period: 3, Low: 0.1, High: 0.3},
+			newRegimeWindow(100, 101, 100, 101),
+			NormalVolatility,
+		},
+		{"high volatility:",
+			&VolatilityRegime{Period: 3, Low: 0.1, High: 0.3},
+			newRegimeWindow(100, 110, 95, 110),
+			HighVolatility,
+		},
+		{"trend up:",
+			&TrendRegime{Period: 2, Slope: 2, Threshold: 0.01},
+			newRegimeWindow(100, 102, 104, 106),
+			TrendUp,
+		},
+		{"trend down:",
+			&TrendRegime{Period: 2, Slope: 2, Threshold: 0.01},
+			newRegimeWindow(106, 104, 102, 100),
+			TrendDown,
+		},
+		{"range:",
+			&TrendRegime{Period: 2, Slope: 2, Threshold: 0.01},
+			newRegimeWindow(100, 101, 100, 101),
+			Range,
+		},
+	}
+
+	for _, tc := range testCases {
+		if regime := tc.classifier.Classify(tc.events); regime != tc.exp {
+			t.Errorf("%v Classify(): \nexpected %v, \nactual %v", tc.msg, tc.exp, regime)
+		}
+	}
+}
+
+func TestBacktestRegimes(t *testing.T) {
+	stream := newRegimeWindow(100, 102, 104, 106)
+	data := &Data{}
+	data.SetStream(stream)
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&buyOnceStrategy{Strategy: NewStrategy("regime")})
+	test.SetRegimes(&TrendRegime{Period: 2, Slope: 1, Threshold: 0.01})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	var exp = []Regime{RegimeUnknown, RegimeUnknown, TrendUp, TrendUp}
+	for i, e := range stream {
+		if regime, ok := RegimeOf(e, "TRENDREGIME"); !ok || regime != exp[i] {
+			t.Errorf("Run(): expected regime %v at %v, actual %v %v", exp[i], i, regime, ok)
+		}
+	}
+}
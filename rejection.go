@@ -0,0 +1,38 @@
+package gobacktest
+
+import (
+	"fmt"
+)
+
+// RejectionEvent declares a rejected order event.
+type RejectionEvent interface {
+	EventHandler
+	Reason() string
+}
+
+// Rejection declares an event for an order which was rejected.
+// It implements the error interface, so it can be returned by the handlers.
+type Rejection struct {
+	Event
+	Order  OrderEvent
+	reason string
+}
+
+// NewRejection creates a rejection event for an order.
+func NewRejection(order OrderEvent, reason string) *Rejection {
+	return &Rejection{
+		Event:  Event{timestamp: order.Time(), symbol: order.Symbol()},
+		Order:  order,
+		reason: reason,
+	}
+}
+
+// Reason returns the reason of the rejection.
+func (r Rejection) Reason() string {
+	return r.reason
+}
+
+// Error implements the error interface.
+func (r Rejection) Error() string {
+	return fmt.Sprintf("order for %s rejected: %s", r.symbol, r.reason)
+}
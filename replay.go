@@ -0,0 +1,309 @@
+package gobacktest
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+)
+
+// EventRecord is the serializable form of an event, used to write the event stream of a run
+// to disk and to replay it later.
+type EventRecord struct {
+	Type      string             `json:"type"`
+	Time      time.Time          `json:"time"`
+	Symbol    string             `json:"symbol"`
+	ID        int                `json:"id,omitempty"`
+	Parent    int                `json:"parent,omitempty"`
+	Direction Direction          `json:"direction,omitempty"`
+	Values    map[string]float64 `json:"values,omitempty"`
+	Metric    map[string]float64 `json:"metric,omitempty"`
+	Bids      []BookLevel        `json:"bids,omitempty"`
+	Asks      []BookLevel        `json:"asks,omitempty"`
+	Reason    string             `json:"reason,omitempty"`
+	Tags      map[string]string  `json:"tags,omitempty"`
+	Text      map[string]string  `json:"text,omitempty"`
+}
+
+// NewEventRecord creates the record of an event.
+// Events of an unknown type are recorded with their type name, time and symbol only.
+func NewEventRecord(e EventHandler) EventRecord {
+	r := EventRecord{
+		Time:   e.Time(),
+		Symbol: e.Symbol(),
+		ID:     eventID(e),
+		Parent: parentID(e),
+	}
+	if t, ok := e.(Tagger); ok {
+		r.Tags = t.Tags()
+	}
+
+	// check fills and orders first, as they also satisfy the data event interface
+	switch e := e.(type) {
+	case *Fill:
+		r.Type = "fill"
+		r.Direction = e.Direction()
+		r.Values = map[string]float64{
+			"qty":         e.Qty(),
+			"price":       e.Price(),
+			"commission":  e.Commission(),
+			"exchangeFee": e.ExchangeFee(),
+			"cost":        e.Cost(),
+			"impact":      e.Impact(),
+		}
+	case *Order:
+		r.Type = "order"
+		r.Direction = e.Direction()
+		r.Values = map[string]float64{
+			"id":     float64(e.ID()),
+			"type":   float64(e.Type()),
+			"status": float64(e.Status()),
+			"qty":    e.Qty(),
+			"filled": e.QtyFilled(),
+			"limit":  e.Limit(),
+			"stop":   e.Stop(),
+		}
+	case *Signal:
+		r.Type = "signal"
+		r.Direction = e.Direction()
+		r.Values = map[string]float64{
+			"stopLoss":   e.StopLoss(),
+			"confidence": e.Confidence(),
+		}
+		if e.HasStrength() {
+			r.Values["strength"] = e.Strength()
+		}
+	case *Rejection:
+		r.Type = "rejection"
+		r.Reason = e.Reason()
+	case *Bar:
+		r.Type = "bar"
+		r.Values = map[string]float64{
+			"open":     e.Open,
+			"high":     e.High,
+			"low":      e.Low,
+			"close":    e.Close,
+			"adjClose": e.AdjClose,
+			"volume":   e.Volume,
+		}
+		r.Metric = recordMetric(e.Metric)
+	case *Tick:
+		r.Type = "tick"
+		r.Values = map[string]float64{
+			"bid":       e.Bid,
+			"ask":       e.Ask,
+			"bidVolume": e.BidVolume,
+			"askVolume": e.AskVolume,
+		}
+		r.Metric = recordMetric(e.Metric)
+	case *Book:
+		r.Type = "book"
+		r.Bids = e.BidLevels
+		r.Asks = e.AskLevels
+		r.Metric = recordMetric(e.Metric)
+	case CustomEvent:
+		r.Type = e.Kind()
+		if v, ok := e.(ValueRecorder); ok {
+			r.Values = v.RecordValues()
+		}
+		if t, ok := e.(TextRecorder); ok {
+			r.Text = t.RecordText()
+		}
+	default:
+		r.Type = reflect.TypeOf(e).String()
+	}
+
+	return r
+}
+
+// Event returns the event of the record.
+func (r EventRecord) Event() (EventHandler, error) {
+	event := r.baseEvent()
+	v := r.Values
+
+	switch r.Type {
+	case "fill":
+		return &Fill{
+			Event:       event,
+			direction:   r.Direction,
+			qty:         v["qty"],
+			price:       v["price"],
+			commission:  v["commission"],
+			exchangeFee: v["exchangeFee"],
+			cost:        v["cost"],
+			impact:      v["impact"],
+		}, nil
+	case "order":
+		return &Order{
+			Event:      event,
+			id:         int(v["id"]),
+			orderType:  OrderType(v["type"]),
+			status:     OrderStatus(v["status"]),
+			direction:  r.Direction,
+			qty:        v["qty"],
+			qtyFilled:  v["filled"],
+			limitPrice: v["limit"],
+			stopPrice:  v["stop"],
+		}, nil
+	case "signal":
+		strength, hasStrength := v["strength"]
+		return &Signal{
+			Event:       event,
+			direction:   r.Direction,
+			stopLoss:    v["stopLoss"],
+			strength:    strength,
+			hasStrength: hasStrength,
+			confidence:  v["confidence"],
+		}, nil
+	case "rejection":
+		return &Rejection{Event: event, reason: r.Reason}, nil
+	case "bar":
+		return &Bar{
+			Event:    event,
+			Metric:   r.metric(),
+			Open:     v["open"],
+			High:     v["high"],
+			Low:      v["low"],
+			Close:    v["close"],
+			AdjClose: v["adjClose"],
+			Volume:   v["volume"],
+		}, nil
+	case "tick":
+		return &Tick{
+			Event:     event,
+			Metric:    r.metric(),
+			Bid:       v["bid"],
+			Ask:       v["ask"],
+			BidVolume: v["bidVolume"],
+			AskVolume: v["askVolume"],
+		}, nil
+	case "book":
+		return &Book{Event: event, Metric: r.metric(), BidLevels: r.Bids, AskLevels: r.Asks}, nil
+	}
+
+	// a registered custom event
+	return decodeEvent(r)
+}
+
+// baseEvent returns the common event fields of the record.
+func (r EventRecord) baseEvent() Event {
+	event := Event{timestamp: r.Time, symbol: r.Symbol, eventID: r.ID, parentID: r.Parent}
+	for k, v := range r.Tags {
+		event.SetTag(k, v)
+	}
+	return event
+}
+
+// metric returns the metric of the record, never nil.
+func (r EventRecord) metric() Metric {
+	m := Metric{}
+	for k, v := range r.Metric {
+		m[k] = v
+	}
+	return m
+}
+
+// recordMetric returns the metric for a record, nil if empty, so records compare equal in every format.
+func recordMetric(m Metric) map[string]float64 {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// NewEventRecords creates the records of an event stream.
+func NewEventRecords(events []EventHandler) []EventRecord {
+	records := make([]EventRecord, len(events))
+	for i, e := range events {
+		records[i] = NewEventRecord(e)
+	}
+	return records
+}
+
+// WriteEvents writes an event stream as JSON Lines, one record per line.
+func WriteEvents(w io.Writer, events []EventHandler) error {
+	enc := json.NewEncoder(w)
+	for _, r := range NewEventRecords(events) {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEvents reads an event stream written as JSON Lines.
+func ReadEvents(r io.Reader) ([]EventRecord, error) {
+	var records []EventRecord
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var record EventRecord
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// WriteEventsBinary writes an event stream in the binary gob format.
+func WriteEventsBinary(w io.Writer, events []EventHandler) error {
+	return gob.NewEncoder(w).Encode(NewEventRecords(events))
+}
+
+// ReadEventsBinary reads an event stream written in the binary gob format.
+func ReadEventsBinary(r io.Reader) ([]EventRecord, error) {
+	var records []EventRecord
+	err := gob.NewDecoder(r).Decode(&records)
+	return records, err
+}
+
+// Replay returns the data events of a recorded event stream in their original order,
+// ready to be set as the data stream of a new run.
+func Replay(records []EventRecord) ([]DataEvent, error) {
+	var stream []DataEvent
+
+	for _, r := range records {
+		switch r.Type {
+		case "bar", "tick", "book":
+		default:
+			continue
+		}
+
+		e, err := r.Event()
+		if err != nil {
+			return stream, err
+		}
+		data := e.(DataEvent)
+		// a replayed run assigns new ids
+		data.(Lineager).SetEventID(0)
+		stream = append(stream, data)
+	}
+
+	return stream, nil
+}
+
+// DiffEvents compares two recorded event streams, e.g. of a run and its replay,
+// and returns the index of the first differing record. It returns -1 if both are equal.
+func DiffEvents(a, b []EventRecord) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			return i
+		}
+	}
+
+	if len(a) != len(b) {
+		return n
+	}
+	return -1
+}
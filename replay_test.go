@@ -0,0 +1,105 @@
+package gobacktest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventRecord(t *testing.T) {
+	ts := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	// testCases is a table for testing the round trip of an event through its record
+	var testCases = []struct {
+		msg   string
+		event EventHandler
+	}{
+		{"bar:", &Bar{Event: Event{timestamp: ts, symbol: "TEST", eventID: 1}, Metric: Metric{"SMA": 10}, Open: 9, High: 11, Low: 8, Close: 10, AdjClose: 10, Volume: 100}},
+		{"tick:", &Tick{Event: Event{timestamp: ts, symbol: "TEST"}, Metric: Metric{}, Bid: 9, Ask: 11, BidVolume: 5, AskVolume: 6}},
+		{"book:", &Book{Event: Event{timestamp: ts, symbol: "TEST"}, Metric: Metric{}, BidLevels: []BookLevel{{9, 1}}, AskLevels: []BookLevel{{11, 2}}}},
+		{"signal:", &Signal{Event: Event{timestamp: ts, symbol: "TEST", eventID: 2, parentID: 1}, direction: SLD, stopLoss: 12, strength: -0.5, hasStrength: true, confidence: 0.8}},
+		{"order:", &Order{Event: Event{timestamp: ts, symbol: "TEST", eventID: 3, parentID: 2}, id: 7, orderType: LimitOrder, status: OrderSubmitted, direction: SLD, qty: 10, limitPrice: 10.5}},
+		{"fill:", &Fill{Event: Event{timestamp: ts, symbol: "TEST", eventID: 4, parentID: 3}, direction: SLD, qty: 10, price: 10.5, commission: 1, exchangeFee: 0.5, cost: 1.5}},
+		{"rejection:", &Rejection{Event: Event{timestamp: ts, symbol: "TEST", eventID: 5, parentID: 3}, reason: "no cash"}},
+	}
+
+	for _, tc := range testCases {
+		event, err := NewEventRecord(tc.event).Event()
+		if err != nil {
+			t.Errorf("%v Event(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(event, tc.event) {
+			t.Errorf("%v Event(): \nexpected %#v, \nactual   %#v", tc.msg, tc.event, event)
+		}
+	}
+
+	if _, err := NewEventRecord(&testEvent{}).Event(); err == nil {
+		t.Errorf("unknown type Event(): expected error, actual nil")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	run := func(stream []DataEvent) *Backtest {
+		data := &Data{}
+		data.SetStream(stream)
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		if err := test.Run(); err != nil {
+			t.Fatalf("Run(): unexpected error %v", err)
+		}
+		return test
+	}
+
+	// testCases is a table for testing the formats of the event log
+	var testCases = []struct {
+		msg   string
+		write func(*bytes.Buffer, []EventHandler) error
+		read  func(*bytes.Buffer) ([]EventRecord, error)
+	}{
+		{"json lines:",
+			func(b *bytes.Buffer, e []EventHandler) error { return WriteEvents(b, e) },
+			func(b *bytes.Buffer) ([]EventRecord, error) { return ReadEvents(b) },
+		},
+		{"binary:",
+			func(b *bytes.Buffer, e []EventHandler) error { return WriteEventsBinary(b, e) },
+			func(b *bytes.Buffer) ([]EventRecord, error) { return ReadEventsBinary(b) },
+		},
+	}
+
+	for _, tc := range testCases {
+		record := func(test *Backtest) []EventRecord {
+			var buf bytes.Buffer
+			if err := tc.write(&buf, test.statistic.Events()); err != nil {
+				t.Fatalf("%v write: unexpected error %v", tc.msg, err)
+			}
+			records, err := tc.read(&buf)
+			if err != nil {
+				t.Fatalf("%v read: unexpected error %v", tc.msg, err)
+			}
+			return records
+		}
+
+		original := record(run(newStressStream(100, 101, 102)))
+
+		stream, err := Replay(original)
+		if err != nil {
+			t.Fatalf("%v Replay(): unexpected error %v", tc.msg, err)
+		}
+		if len(stream) != 3 {
+			t.Errorf("%v Replay(): expected 3 data events, actual %v", tc.msg, len(stream))
+		}
+
+		replayed := record(run(stream))
+		if i := DiffEvents(original, replayed); i != -1 {
+			t.Errorf("%v DiffEvents(): expected equal runs, first difference at %v", tc.msg, i)
+		}
+
+		if i := DiffEvents(original, replayed[:len(replayed)-1]); i != len(replayed)-1 {
+			t.Errorf("%v DiffEvents(): expected difference at %v, actual %v", tc.msg, len(replayed)-1, i)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gobacktest
+
+// reservation is the value of an accepted order, which is not booked yet. The value is
+// reserved in the cash and buying power checks of the following orders, until the fills
+// of the order are booked or the order is canceled or rejected.
+type reservation struct {
+	order    *Order
+	value    float64 // the value of the complete qty of the order
+	booked   float64 // the qty of the booked fills
+	children []int   // event ids of the child orders, e.g. of an execution algo
+}
+
+// caused returns if a fill of the order with the event id is booked against the reservation,
+// the fills of the child orders count for the parent order.
+func (r reservation) caused(id int) bool {
+	if id == r.order.EventID() {
+		return true
+	}
+	for _, child := range r.children {
+		if id == child {
+			return true
+		}
+	}
+	return false
+}
+
+// open returns if the order of a reservation may still be filled.
+func (r reservation) open() bool {
+	switch r.order.Status() {
+	case OrderCanceled, OrderInvalid:
+		return false
+	}
+	return r.booked < r.order.Qty()
+}
+
+// reserve reserves the value of an accepted order, an order without value is not reserved.
+func (p *Portfolio) reserve(order *Order, value float64) {
+	if value <= 0 {
+		return
+	}
+	p.reserved = append(p.reserved, reservation{order: order, value: value})
+}
+
+// reservedValue returns the value reserved for the unbooked qty of the open orders.
+func (p *Portfolio) reservedValue() float64 {
+	var value float64
+	for _, r := range p.reserved {
+		if r.open() {
+			value += r.value * (r.order.Qty() - r.booked) / r.order.Qty()
+		}
+	}
+	return value
+}
+
+// adopter links the child orders created by the exchange to their parent order.
+type adopter interface {
+	adopt(*Order, EventHandler)
+}
+
+// adopt links a child order, e.g. of an execution algo, to the reservation of its parent order.
+func (p *Portfolio) adopt(child *Order, parent EventHandler) {
+	id := eventID(parent)
+	for i, r := range p.reserved {
+		if id != 0 && r.order.EventID() == id {
+			p.reserved[i].children = append(p.reserved[i].children, child.EventID())
+			return
+		}
+	}
+}
+
+// release books the qty of a fill against the reservation of its order. The order of the fill
+// is its parent event, a fill without lineage releases the first reservation of its symbol
+// and direction. Reservations of done orders are removed.
+func (p *Portfolio) release(fill FillEvent) {
+	match := -1
+	for i, r := range p.reserved {
+		if !r.open() || r.order.Symbol() != fill.Symbol() || r.order.Direction() != fill.Direction() {
+			continue
+		}
+		if id := parentID(fill); id != 0 && r.order.EventID() != 0 {
+			if r.caused(id) {
+				match = i
+				break
+			}
+			continue
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match >= 0 {
+		p.reserved[match].booked += fill.Qty()
+	}
+
+	open := p.reserved[:0]
+	for _, r := range p.reserved {
+		if r.open() {
+			open = append(open, r)
+		}
+	}
+	p.reserved = open
+}
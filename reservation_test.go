@@ -0,0 +1,52 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPortfolioReservation(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+	latest := &Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 100}
+	newOrder := func(id int) *Order {
+		return &Order{Event: Event{timestamp: exampleTime, symbol: "TEST.DE", eventID: id}, direction: BOT, qty: 10}
+	}
+	newFill := func(parent int, qty float64) *Fill {
+		return &Fill{Event: Event{timestamp: exampleTime, symbol: "TEST.DE", parentID: parent}, direction: BOT, qty: qty, price: 100}
+	}
+
+	p := &Portfolio{cash: 2500}
+	first, second := newOrder(1), newOrder(2)
+	p.reserve(first, p.orderValue(first, latest))
+	p.reserve(second, p.orderValue(second, latest))
+
+	// testCases is a table for testing the reserved value of the open orders
+	var testCases = []struct {
+		msg         string
+		event       func()
+		expReserved float64
+		expReject   bool
+	}{
+		{"two open orders:", func() {}, 2000, true},
+		{"partial fill of the second order:", func() { p.OnFill(newFill(2, 4), &Data{}) }, 1600, true},
+		{"fill of the first order:", func() { p.OnFill(newFill(1, 10), &Data{}) }, 600, true},
+		{"canceled second order:", func() { second.SetStatus(OrderCanceled) }, 0, false},
+		{"fill without lineage:", func() {
+			third := newOrder(0)
+			p.reserve(third, p.orderValue(third, latest))
+			p.OnFill(newFill(0, 5), &Data{})
+		}, 500, true},
+	}
+
+	for _, tc := range testCases {
+		tc.event()
+		if reserved := p.reservedValue(); reserved != tc.expReserved {
+			t.Errorf("%v reservedValue(): expected %v, actual %v", tc.msg, tc.expReserved, reserved)
+		}
+		// an order of 1000 is covered only by the cash, which is not reserved
+		rejection := p.checkOrder(newOrder(9), latest)
+		if (rejection != nil) != tc.expReject {
+			t.Errorf("%v checkOrder(): expected rejection %v, actual %v with cash %v", tc.msg, tc.expReject, rejection, p.cash)
+		}
+	}
+}
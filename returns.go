@@ -0,0 +1,71 @@
+package gobacktest
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DailyReturn is the return of a day.
+type DailyReturn struct {
+	Date   time.Time // midnight of the day in the location of the data
+	Return float64
+}
+
+// BenchmarkReturns returns the daily returns of the prices of a symbol in a data stream,
+// e.g. of an index to compare the strategy with.
+func BenchmarkReturns(stream []DataEvent, symbol string) []DailyReturn {
+	var prices Statistic
+	for _, e := range stream {
+		if e.Symbol() == symbol {
+			prices.equity = append(prices.equity, equityPoint{timestamp: e.Time(), equity: e.Price()})
+		}
+	}
+	return prices.DatedDailyReturns()
+}
+
+// WriteReturnsCSV exports the daily returns of a strategy and optionally of a benchmark as
+// csv in the layout of the Python libraries quantstats and pyfolio: a date column as index
+// and the returns as decimal fractions, e.g.
+//
+//	Date,Strategy,Benchmark
+//	2016-01-05,0.0123,-0.0042
+//
+// The file is read with pandas.read_csv(path, index_col=0, parse_dates=True), pyfolio expects
+// the index localized to UTC. The benchmark is aligned to the days of the strategy, a day
+// without benchmark return is empty.
+func WriteReturnsCSV(w io.Writer, returns, benchmark []DailyReturn) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Date", "Strategy"}
+	if benchmark != nil {
+		header = append(header, "Benchmark")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	bench := make(map[string]float64, len(benchmark))
+	for _, r := range benchmark {
+		bench[r.Date.Format("2006-01-02")] = r.Return
+	}
+
+	for _, r := range returns {
+		date := r.Date.Format("2006-01-02")
+		record := []string{date, strconv.FormatFloat(r.Return, 'f', -1, 64)}
+		if benchmark != nil {
+			value := ""
+			if b, ok := bench[date]; ok {
+				value = strconv.FormatFloat(b, 'f', -1, 64)
+			}
+			record = append(record, value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
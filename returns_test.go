@@ -0,0 +1,73 @@
+package gobacktest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDatedDailyReturns(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	s := Statistic{equity: []equityPoint{
+		{timestamp: day, equity: 100},
+		{timestamp: day.AddDate(0, 0, 1), equity: 110},
+		{timestamp: day.AddDate(0, 0, 1).Add(4 * time.Hour), equity: 120},
+		{timestamp: day.AddDate(0, 0, 4), equity: 108},
+	}}
+
+	returns := s.DatedDailyReturns()
+	if len(returns) != 2 {
+		t.Fatalf("DatedDailyReturns(): expected 2 returns, actual %v", returns)
+	}
+	if !returns[0].Date.Equal(day.AddDate(0, 0, 1)) || returns[0].Return != 0.2 {
+		t.Errorf("DatedDailyReturns(): expected the return of the last point of the day, actual %+v", returns[0])
+	}
+	if !returns[1].Date.Equal(day.AddDate(0, 0, 4)) || returns[1].Return != -0.1 {
+		t.Errorf("DatedDailyReturns(): expected the return over the weekend, actual %+v", returns[1])
+	}
+}
+
+func TestBenchmarkReturns(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	stream := []DataEvent{
+		&Bar{Event: Event{timestamp: day, symbol: "INDEX"}, Close: 100},
+		&Bar{Event: Event{timestamp: day, symbol: "TEST.DE"}, Close: 10},
+		&Bar{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "INDEX"}, Close: 105},
+		&Bar{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "TEST.DE"}, Close: 20},
+	}
+
+	returns := BenchmarkReturns(stream, "INDEX")
+	if len(returns) != 1 || returns[0].Return != 0.05 {
+		t.Errorf("BenchmarkReturns(): expected the returns of the index, actual %v", returns)
+	}
+	if returns := BenchmarkReturns(stream, "UNKNOWN"); len(returns) != 0 {
+		t.Errorf("BenchmarkReturns(): expected no returns of an unknown symbol, actual %v", returns)
+	}
+}
+
+func TestWriteReturnsCSV(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	returns := []DailyReturn{{day, 0.01}, {day.AddDate(0, 0, 1), -0.005}}
+	benchmark := []DailyReturn{{day.AddDate(0, 0, 1), 0.002}}
+
+	// testCases is a table for testing the csv layout
+	var testCases = []struct {
+		msg       string
+		benchmark []DailyReturn
+		exp       string
+	}{
+		{"strategy only:", nil, "Date,Strategy\n2017-06-01,0.01\n2017-06-02,-0.005\n"},
+		{"with benchmark:", benchmark, "Date,Strategy,Benchmark\n2017-06-01,0.01,\n2017-06-02,-0.005,0.002\n"},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := WriteReturnsCSV(&buf, returns, tc.benchmark); err != nil {
+			t.Fatalf("%v WriteReturnsCSV(): unexpected error %v", tc.msg, err)
+		}
+		if buf.String() != tc.exp {
+			t.Errorf("%v WriteReturnsCSV(): \nexpected %q, \nactual   %q", tc.msg, tc.exp, buf.String())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+)
+
+// RiskPerTrade is a size handler, which sizes an order so that a stop out at the protective
+// stop price of the order loses a fixed share Risk of the equity, e.g. 0.01 for 1%.
+// Orders without a stop price and exit orders are sized by the Fallback size handler.
+type RiskPerTrade struct {
+	Risk        float64
+	Fallback    SizeHandler
+	Instruments *InstrumentRegistry
+}
+
+// SizeOrder sets the qty of an order from the risk budget and the stop distance.
+func (r *RiskPerTrade) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler) (*Order, error) {
+	o := order.(*Order)
+
+	stop := o.StopLoss()
+	if o.Direction() == EXT || stop == 0 {
+		if r.Fallback == nil {
+			return o, errors.New("cannot size order: no stop price and no fallback size handler set,")
+		}
+		return r.Fallback.SizeOrder(order, data, pf)
+	}
+
+	entry := data.Price()
+	distance := entry - stop
+	if o.Direction() == SLD {
+		distance = stop - entry
+	}
+	if distance <= 0 {
+		return o, errors.New("cannot size order: stop price on the wrong side of the entry price,")
+	}
+
+	instrument := r.Instruments.Get(o.Symbol())
+	budget := pf.Value() * r.Risk
+	qty := budget / (distance * instrument.ContractMultiplier())
+
+	o.SetQty(instrument.LotSize.Round(math.Abs(qty)))
+	return o, nil
+}
@@ -0,0 +1,57 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+func TestRiskPerTradeSizeOrder(t *testing.T) {
+	// testCases is a table for testing the risk per trade sizing
+	var testCases = []struct {
+		msg    string
+		sizer  *RiskPerTrade
+		order  *Order
+		price  float64
+		expQty float64
+		expErr bool
+	}{
+		{"long with stop below entry:",
+			&RiskPerTrade{Risk: 0.01},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, stopLoss: 95},
+			100, 20, false,
+		},
+		{"short with stop above entry:",
+			&RiskPerTrade{Risk: 0.01},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, stopLoss: 103},
+			100, 33, false,
+		},
+		{"fractional lot size:",
+			&RiskPerTrade{Risk: 0.01, Instruments: NewInstrumentRegistry(Instrument{Symbol: "BTCUSD", LotSize: LotSize{Step: 0.001}})},
+			&Order{Event: Event{symbol: "BTCUSD"}, direction: BOT, stopLoss: 19000},
+			20000, 0.1, false,
+		},
+		{"stop on wrong side:",
+			&RiskPerTrade{Risk: 0.01},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, stopLoss: 105},
+			100, 0, true,
+		},
+		{"no stop uses fallback:",
+			&RiskPerTrade{Risk: 0.01, Fallback: &Size{DefaultSize: 10, DefaultValue: 10000}},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT},
+			100, 10, false,
+		},
+		{"no stop without fallback:",
+			&RiskPerTrade{Risk: 0.01},
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT},
+			100, 0, true,
+		},
+	}
+
+	for _, tc := range testCases {
+		pf := &Portfolio{cash: 10000}
+		order, err := tc.sizer.SizeOrder(tc.order, &Bar{Close: tc.price}, pf)
+		if (err != nil) != tc.expErr || order.Qty() != tc.expQty {
+			t.Errorf("%v SizeOrder(): \nexpected qty %v error %v, \nactual   qty %v error %v",
+				tc.msg, tc.expQty, tc.expErr, order.Qty(), err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package gobacktest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RiskRule is the basic interface for a rule which halts the trading of a portfolio.
+// Update is called with the equity on every data event and returns a TradingHalted event
+// once the rule is breached. While a rule is halted, orders opening or increasing a position are rejected.
+type RiskRule interface {
+	Update(time.Time, float64) *TradingHalted
+	Halted() bool
+}
+
+// ClockRule is a risk rule which is updated with the clock of the engine instead of the time of
+// the data event, e.g. a trading session which needs the time of the next data event.
+type ClockRule interface {
+	RiskRule
+	UpdateClock(Clock, float64) *TradingHalted
+}
+
+// FlowRule is a risk rule which is notified about the external cash flows booked into the
+// portfolio before its update, e.g. to shift its reference equity, so that a deposit or a
+// withdrawal is no profit or loss.
+type FlowRule interface {
+	RiskRule
+	OnCashFlow(float64)
+}
+
+// ClockSetter receives the clock of the engine on every data event, e.g. implemented by a portfolio.
+type ClockSetter interface {
+	SetClock(Clock)
+}
+
+// TradingHaltedEvent declares a trading halted event.
+type TradingHaltedEvent interface {
+	EventHandler
+	Reason() string
+	Flatten() bool
+}
+
+// TradingHalted declares an event for a breached risk rule.
+type TradingHalted struct {
+	Event
+	reason  string
+	flatten bool
+}
+
+// Reason returns the reason of the trading halt.
+func (h TradingHalted) Reason() string {
+	return h.reason
+}
+
+// Flatten returns if all positions are closed with the trading halt.
+func (h TradingHalted) Flatten() bool {
+	return h.flatten
+}
+
+// HaltObserver is notified about trading halts, e.g. implemented by a strategy.
+type HaltObserver interface {
+	OnHalt(TradingHaltedEvent)
+}
+
+// RuleChecker checks the risk rules of a portfolio on a data event
+// and returns a trading halt with the orders to close positions.
+type RuleChecker interface {
+	CheckRules(DataEvent) (*TradingHalted, []*Order)
+}
+
+// DrawdownHalt halts new entries, if the drawdown of the equity from its high-water mark
+// exceeds MaxDrawdown, e.g. 0.2 for 20%. With Flatten set all positions are closed.
+// The halt lasts until the rule is reset. A cash flow shifts the high-water mark by its amount.
+type DrawdownHalt struct {
+	MaxDrawdown float64
+	Flatten     bool
+	high        float64
+	halted      bool
+}
+
+// Update updates the high-water mark and checks the drawdown.
+func (r *DrawdownHalt) Update(t time.Time, equity float64) *TradingHalted {
+	if equity > r.high {
+		r.high = equity
+	}
+	if r.halted || r.high <= 0 {
+		return nil
+	}
+
+	drawdown := (r.high - equity) / r.high
+	if drawdown <= r.MaxDrawdown {
+		return nil
+	}
+
+	r.halted = true
+	return &TradingHalted{
+		Event:   Event{timestamp: t},
+		reason:  fmt.Sprintf("drawdown %.4f above max drawdown %.4f", drawdown, r.MaxDrawdown),
+		flatten: r.Flatten,
+	}
+}
+
+// OnCashFlow implements FlowRule to shift the high-water mark by a deposit or a withdrawal.
+func (r *DrawdownHalt) OnCashFlow(amount float64) {
+	if r.high > 0 {
+		r.high += amount
+	}
+}
+
+// Halted returns if the rule is breached.
+func (r *DrawdownHalt) Halted() bool {
+	return r.halted
+}
+
+// Reset implements Reseter to reset the high-water mark and the halt.
+func (r *DrawdownHalt) Reset() error {
+	r.high = 0
+	r.halted = false
+	return nil
+}
+
+// DailyLossLimit halts new entries for the rest of the day, if the realized and unrealized loss
+// since the start of the day exceeds MaxLoss as amount or MaxLossPercent as share of the equity
+// at the start of the day, e.g. 0.02 for 2%. The halt is lifted on the first data event of the next day.
+// A cash flow during the day shifts the equity at the start of the day by its amount.
+type DailyLossLimit struct {
+	MaxLoss        float64
+	MaxLossPercent float64
+	Flatten        bool
+	day            time.Time
+	start          float64
+	halted         bool
+}
+
+// Update starts a new session on a new day and checks the loss of the day.
+func (r *DailyLossLimit) Update(t time.Time, equity float64) *TradingHalted {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if day.After(r.day) {
+		r.day = day
+		r.start = equity
+		r.halted = false
+	}
+	if r.halted {
+		return nil
+	}
+
+	loss := r.start - equity
+	if loss <= 0 {
+		return nil
+	}
+
+	breached := r.MaxLoss > 0 && loss > r.MaxLoss
+	if r.MaxLossPercent > 0 && r.start > 0 && loss/r.start > r.MaxLossPercent {
+		breached = true
+	}
+	if !breached {
+		return nil
+	}
+
+	r.halted = true
+	return &TradingHalted{
+		Event:   Event{timestamp: t},
+		reason:  fmt.Sprintf("daily loss %.2f above limit", loss),
+		flatten: r.Flatten,
+	}
+}
+
+// OnCashFlow implements FlowRule to shift the equity at the start of the day by a deposit or a withdrawal.
+func (r *DailyLossLimit) OnCashFlow(amount float64) {
+	if !r.day.IsZero() {
+		r.start += amount
+	}
+}
+
+// Halted returns if the loss limit of the day is breached.
+func (r *DailyLossLimit) Halted() bool {
+	return r.halted
+}
+
+// Reset implements Reseter to reset the session.
+func (r *DailyLossLimit) Reset() error {
+	r.day = time.Time{}
+	r.start = 0
+	r.halted = false
+	return nil
+}
+
+// SetRiskRules sets the risk rules of the portfolio.
+func (p *Portfolio) SetRiskRules(rules ...RiskRule) {
+	p.rules = rules
+}
+
+// SetClock implements ClockSetter, the clock is handed to the risk rules implementing ClockRule.
+func (p *Portfolio) SetClock(c Clock) {
+	p.clock = c
+}
+
+// Halted returns if any risk rule of the portfolio halts the trading.
+func (p Portfolio) Halted() bool {
+	for _, rule := range p.rules {
+		if rule.Halted() {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRules updates all risk rules with the equity and returns the first trading halt,
+// with the orders to close all positions, if the halt flattens the portfolio.
+// The cash flows booked since the last check are handed to the rules implementing FlowRule first.
+func (p *Portfolio) CheckRules(data DataEvent) (*TradingHalted, []*Order) {
+	equity := p.Value()
+
+	// sum up the cash flows booked since the last check
+	var flow float64
+	if p.ruleFlows > len(p.bookedFlows) {
+		p.ruleFlows = 0
+	}
+	for _, f := range p.bookedFlows[p.ruleFlows:] {
+		flow += f.Amount
+	}
+	p.ruleFlows = len(p.bookedFlows)
+
+	// a clock of another time is not set for this data event
+	clock := p.clock
+	if !clock.Now.Equal(data.Time()) {
+		clock = Clock{Now: data.Time()}
+	}
+
+	var halt *TradingHalted
+	for _, rule := range p.rules {
+		if r, ok := rule.(FlowRule); ok && flow != 0 {
+			r.OnCashFlow(flow)
+		}
+		var h *TradingHalted
+		if r, ok := rule.(ClockRule); ok {
+			h = r.UpdateClock(clock, equity)
+		} else {
+			h = rule.Update(data.Time(), equity)
+		}
+		if h != nil && halt == nil {
+			halt = h
+		}
+	}
+	if halt == nil {
+		return nil, nil
+	}
+	halt.symbol = data.Symbol()
+
+	if !halt.flatten {
+		return halt, nil
+	}
+
+	// close all positions sorted by symbol
+	var symbols []string
+	for symbol, pos := range p.holdings {
+		if pos.qty != 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	var orders []*Order
+	for _, symbol := range symbols {
+		orders = append(orders, closeOrder(p.holdings[symbol], data.Time()))
+	}
+
+	return halt, orders
+}
+
+// checkHalt rejects orders opening or increasing a position while the trading is halted.
+func (p *Portfolio) checkHalt(order *Order) *Rejection {
+	if order == nil || !p.Halted() {
+		return nil
+	}
+
+	// only orders reducing a position are allowed
+	before := p.holdings[order.Symbol()].qty
+	if after := qtyAfter(order, before); after*before >= 0 && absQty(after) <= absQty(before) {
+		return nil
+	}
+	return NewRejection(order, "trading halted")
+}
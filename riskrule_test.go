@@ -0,0 +1,145 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrawdownHaltUpdate(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing the drawdown halt
+	var testCases = []struct {
+		msg       string
+		rule      *DrawdownHalt
+		equity    []float64
+		expHalted bool
+		expEvents int
+	}{
+		{"drawdown within limit:",
+			&DrawdownHalt{MaxDrawdown: 0.2},
+			[]float64{1000, 1200, 1000},
+			false, 0,
+		},
+		{"drawdown above limit halts once:",
+			&DrawdownHalt{MaxDrawdown: 0.2},
+			[]float64{1000, 1200, 900, 800},
+			true, 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		var events int
+		for i, equity := range tc.equity {
+			if h := tc.rule.Update(day.AddDate(0, 0, i), equity); h != nil {
+				events++
+			}
+		}
+		if tc.rule.Halted() != tc.expHalted || events != tc.expEvents {
+			t.Errorf("%v Update(): \nexpected halted %v with %v events, \nactual   %v with %v",
+				tc.msg, tc.expHalted, tc.expEvents, tc.rule.Halted(), events)
+		}
+	}
+}
+
+func TestPortfolioCheckRules(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	p := &Portfolio{
+		cash: 500,
+		holdings: map[string]Position{
+			"A.DE": {symbol: "A.DE", qty: 10, marketValue: 500},
+		},
+	}
+	p.SetRiskRules(&DrawdownHalt{MaxDrawdown: 0.1, Flatten: true}, &DrawdownHalt{MaxDrawdown: 0.3})
+
+	if halt, _ := p.CheckRules(&Bar{Event: Event{timestamp: day, symbol: "A.DE"}}); halt != nil {
+		t.Errorf("CheckRules(): expected no halt at the high-water mark, actual %#v", halt)
+	}
+
+	// equity drops by 20%
+	p.cash = 300
+	halt, orders := p.CheckRules(&Bar{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "A.DE"}})
+	if halt == nil || !halt.Flatten() || len(orders) != 1 || orders[0].Direction() != SLD || orders[0].Qty() != 10 {
+		t.Fatalf("CheckRules(): expected flattening halt, actual %#v %#v", halt, orders)
+	}
+	if !p.Halted() {
+		t.Errorf("Halted(): expected portfolio to be halted")
+	}
+
+	// only orders reducing a position pass
+	var testCases = []struct {
+		msg       string
+		order     *Order
+		expReject bool
+	}{
+		{"increase position:", &Order{Event: Event{symbol: "A.DE"}, direction: BOT, qty: 1}, true},
+		{"new position:", &Order{Event: Event{symbol: "B.DE"}, direction: SLD, qty: 1}, true},
+		{"reverse position:", &Order{Event: Event{symbol: "A.DE"}, direction: SLD, qty: 20}, true},
+		{"reduce position:", &Order{Event: Event{symbol: "A.DE"}, direction: SLD, qty: 10}, false},
+	}
+
+	for _, tc := range testCases {
+		if rejection := p.checkHalt(tc.order); (rejection != nil) != tc.expReject {
+			t.Errorf("%v checkHalt(): \nexpected rejection %v, \nactual   %v", tc.msg, tc.expReject, rejection)
+		}
+	}
+}
+
+func TestDailyLossLimitUpdate(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing the daily loss limit
+	var testCases = []struct {
+		msg       string
+		rule      *DailyLossLimit
+		times     []time.Time
+		equity    []float64
+		expHalted bool
+		expEvents int
+	}{
+		{"loss within limit:",
+			&DailyLossLimit{MaxLoss: 100},
+			[]time.Time{day, day.Add(time.Hour), day.Add(2 * time.Hour)},
+			[]float64{1000, 950, 901},
+			false, 0,
+		},
+		{"loss amount above limit:",
+			&DailyLossLimit{MaxLoss: 100},
+			[]time.Time{day, day.Add(time.Hour), day.Add(2 * time.Hour)},
+			[]float64{1000, 950, 899},
+			true, 1,
+		},
+		{"loss percent above limit:",
+			&DailyLossLimit{MaxLossPercent: 0.02},
+			[]time.Time{day, day.Add(time.Hour)},
+			[]float64{1000, 970},
+			true, 1,
+		},
+		{"halt lifted on next day:",
+			&DailyLossLimit{MaxLoss: 100},
+			[]time.Time{day, day.Add(time.Hour), day.AddDate(0, 0, 1)},
+			[]float64{1000, 850, 850},
+			false, 1,
+		},
+		{"loss from previous day does not count:",
+			&DailyLossLimit{MaxLoss: 100},
+			[]time.Time{day, day.AddDate(0, 0, 1), day.AddDate(0, 0, 1).Add(time.Hour)},
+			[]float64{1000, 850, 800},
+			false, 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		var events int
+		for i, equity := range tc.equity {
+			if h := tc.rule.Update(tc.times[i], equity); h != nil {
+				events++
+			}
+		}
+		if tc.rule.Halted() != tc.expHalted || events != tc.expEvents {
+			t.Errorf("%v Update(): \nexpected halted %v with %v events, \nactual   %v with %v",
+				tc.msg, tc.expHalted, tc.expEvents, tc.rule.Halted(), events)
+		}
+	}
+}
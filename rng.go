@@ -0,0 +1,58 @@
+package gobacktest
+
+import (
+	"math/rand"
+)
+
+// RandSetter is implemented by a component which draws random numbers, e.g. a latency model.
+// The backtest hands its seeded source to the component before each run.
+type RandSetter interface {
+	SetRand(*rand.Rand)
+}
+
+// SetSeed seeds all randomness of the backtest. Each run starts a new source with the seed
+// and hands it to the data handler, strategy, portfolio and execution handler implementing
+// RandSetter, so runs with the same seed are exactly reproducible.
+// Without a seed the components use their own or the default source of the math/rand package.
+func (t *Backtest) SetSeed(seed int64) {
+	t.seed = &seed
+}
+
+// Seed returns the seed of the backtest, false if none is set.
+func (t *Backtest) Seed() (int64, bool) {
+	if t.seed == nil {
+		return 0, false
+	}
+	return *t.seed, true
+}
+
+// Rand returns the seeded source of the current run, e.g. for a custom component
+// which is not reached by the backtest. It is nil without a seed.
+func (t *Backtest) Rand() *rand.Rand {
+	return t.rng
+}
+
+// setRand starts a new source with the seed and hands it to the components.
+func (t *Backtest) setRand() {
+	if t.seed == nil {
+		return
+	}
+
+	t.rng = rand.New(rand.NewSource(*t.seed))
+	components := []interface{}{t.data, t.strategy, t.portfolio, t.exchange}
+	for _, c := range components {
+		if setter, ok := c.(RandSetter); ok {
+			setter.SetRand(t.rng)
+		}
+	}
+}
+
+// SetRand implements RandSetter and hands the source to the models of the exchange.
+func (e *Exchange) SetRand(r *rand.Rand) {
+	models := []interface{}{e.Commission, e.ExchangeFee, e.FillModel, e.ImpactModel, e.Latency}
+	for _, m := range models {
+		if setter, ok := m.(RandSetter); ok {
+			setter.SetRand(r)
+		}
+	}
+}
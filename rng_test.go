@@ -0,0 +1,122 @@
+package gobacktest
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// randomStrategy draws a random number on every data event.
+type randomStrategy struct {
+	*Strategy
+	rand  *rand.Rand
+	draws []float64
+}
+
+func (s *randomStrategy) SetRand(r *rand.Rand) {
+	s.rand = r
+}
+
+func (s *randomStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.draws = append(s.draws, s.rand.Float64())
+	return nil, nil
+}
+
+func TestBacktestSeed(t *testing.T) {
+	run := func(seed int64) []float64 {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100, 100))
+
+		strategy := &randomStrategy{Strategy: NewStrategy("random")}
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(strategy)
+		test.SetSeed(seed)
+
+		if err := test.Run(); err != nil {
+			t.Fatalf("Run(): unexpected error %v", err)
+		}
+		return strategy.draws
+	}
+
+	first, second, other := run(42), run(42), run(7)
+	if len(first) != 3 {
+		t.Fatalf("Run(): expected 3 draws, actual %v", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Run(): expected equal draws with the same seed, actual %v and %v", first, second)
+	}
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("Run(): expected different draws with another seed, actual %v", other)
+	}
+}
+
+func TestBacktestSeedReset(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100))
+
+	strategy := &randomStrategy{Strategy: NewStrategy("random")}
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(strategy)
+	test.SetSeed(42)
+
+	if seed, ok := test.Seed(); !ok || seed != 42 {
+		t.Errorf("Seed(): expected 42 true, actual %v %v", seed, ok)
+	}
+
+	test.Run()
+	first := strategy.draws
+	strategy.draws = nil
+
+	test.Reset()
+	test.Run()
+	if !reflect.DeepEqual(first, strategy.draws) {
+		t.Errorf("Run(): expected the same draws after a reset, actual %v and %v", first, strategy.draws)
+	}
+	if test.Rand() == nil {
+		t.Errorf("Rand(): expected the source of the run")
+	}
+}
+
+func TestBacktestNoSeed(t *testing.T) {
+	test := New()
+	if _, ok := test.Seed(); ok {
+		t.Errorf("Seed(): expected no seed")
+	}
+
+	latency := &UniformLatency{Max: time.Second}
+	test.exchange.(*Exchange).Latency = latency
+	test.setRand()
+	if latency.Rand != nil || test.Rand() != nil {
+		t.Errorf("setRand(): expected no source without a seed")
+	}
+}
+
+func TestExchangeSetRand(t *testing.T) {
+	uniform := &UniformLatency{Min: time.Millisecond, Max: time.Second}
+	normal := &NormalLatency{Mean: time.Second, StdDev: time.Millisecond}
+
+	test := New()
+	test.exchange.(*Exchange).Latency = uniform
+	test.SetSeed(1)
+	test.setRand()
+	if uniform.Rand != test.Rand() {
+		t.Errorf("setRand(): expected the source handed to the latency model")
+	}
+
+	exchange := &Exchange{Latency: normal}
+	r := rand.New(rand.NewSource(1))
+	exchange.SetRand(r)
+	if normal.Rand != r {
+		t.Errorf("SetRand(): expected the source handed to the latency model")
+	}
+
+	// the same seed draws the same latencies
+	first := uniform.Latency()
+	test.setRand()
+	if second := uniform.Latency(); first != second {
+		t.Errorf("Latency(): expected %v with the same seed, actual %v", first, second)
+	}
+}
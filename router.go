@@ -0,0 +1,186 @@
+package gobacktest
+
+import (
+	"errors"
+)
+
+// Venue is a simulated exchange with an optional own data feed.
+// Without an own data feed the venue uses the data of the backtest.
+type Venue struct {
+	Exchange *Exchange
+	Data     DataHandler
+}
+
+// latest returns the last known data event of a symbol at the venue.
+func (v *Venue) latest(symbol string, data DataHandler) DataEvent {
+	if v.Data != nil {
+		return v.Data.Latest(symbol)
+	}
+	return data.Latest(symbol)
+}
+
+// RoutingPolicy decides to which venue an order is routed.
+type RoutingPolicy interface {
+	Route(OrderEvent, []*Venue, DataHandler) (*Venue, error)
+}
+
+// BestPriceRouting routes an order to the venue with the best last known price,
+// the lowest price for buy orders, the highest price for sell orders.
+type BestPriceRouting struct{}
+
+// Route returns the venue with the best price.
+func (r *BestPriceRouting) Route(order OrderEvent, venues []*Venue, data DataHandler) (*Venue, error) {
+	var best *Venue
+	var bestPrice float64
+
+	for _, venue := range venues {
+		latest := venue.latest(order.Symbol(), data)
+		if latest == nil {
+			continue
+		}
+
+		price := latest.Price()
+		if best == nil ||
+			(order.Direction() == BOT && price < bestPrice) ||
+			(order.Direction() == SLD && price > bestPrice) {
+			best = venue
+			bestPrice = price
+		}
+	}
+
+	if best == nil {
+		return nil, errors.New("no venue with price data for " + order.Symbol())
+	}
+
+	return best, nil
+}
+
+// LowestFeeRouting routes an order to the venue with the lowest commission and exchange fee.
+type LowestFeeRouting struct{}
+
+// Route returns the venue with the lowest cost.
+func (r *LowestFeeRouting) Route(order OrderEvent, venues []*Venue, data DataHandler) (*Venue, error) {
+	var best *Venue
+	var bestCost float64
+
+	for _, venue := range venues {
+		var price float64
+		if latest := venue.latest(order.Symbol(), data); latest != nil {
+			price = latest.Price()
+		}
+
+		commission, err := venue.Exchange.Commission.Calculate(order.Qty(), price)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := venue.Exchange.ExchangeFee.Fee()
+		if err != nil {
+			return nil, err
+		}
+
+		cost := commission + fee
+		if best == nil || cost < bestCost {
+			best = venue
+			bestCost = cost
+		}
+	}
+
+	if best == nil {
+		return nil, errors.New("no venue available")
+	}
+
+	return best, nil
+}
+
+// RoundRobinRouting routes each order to the next venue in turn.
+type RoundRobinRouting struct {
+	next int
+}
+
+// Route returns the next venue.
+func (r *RoundRobinRouting) Route(order OrderEvent, venues []*Venue, data DataHandler) (*Venue, error) {
+	if len(venues) == 0 {
+		return nil, errors.New("no venue available")
+	}
+
+	venue := venues[r.next%len(venues)]
+	r.next++
+
+	return venue, nil
+}
+
+// Router is an execution handler which routes each order to one of several venues.
+type Router struct {
+	Venues []*Venue
+	Policy RoutingPolicy
+}
+
+// NewRouter creates a router for the given venues and routing policy.
+func NewRouter(policy RoutingPolicy, venues ...*Venue) *Router {
+	return &Router{
+		Venues: venues,
+		Policy: policy,
+	}
+}
+
+// OnOrder routes an order to a venue and executes it there.
+func (r *Router) OnOrder(order OrderEvent, data DataHandler) (*Fill, error) {
+	venue, err := r.Policy.Route(order, r.Venues, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if venue.Data != nil {
+		return venue.Exchange.OnOrder(order, venue.Data)
+	}
+	return venue.Exchange.OnOrder(order, data)
+}
+
+// OnData executes resting orders of all venues. A venue with an own data feed
+// is advanced on its own data events up to the time of the data event.
+func (r *Router) OnData(event DataEvent) ([]*Fill, error) {
+	var fills []*Fill
+
+	for _, venue := range r.Venues {
+		if venue.Data == nil {
+			f, err := venue.Exchange.OnData(event)
+			if err != nil {
+				return fills, err
+			}
+			fills = append(fills, f...)
+			continue
+		}
+
+		// advance the venue data feed up to the current time
+		for stream := venue.Data.Stream(); len(stream) > 0 && !stream[0].Time().After(event.Time()); stream = venue.Data.Stream() {
+			venueEvent, ok := venue.Data.Next()
+			if !ok {
+				break
+			}
+
+			f, err := venue.Exchange.OnData(venueEvent)
+			if err != nil {
+				return fills, err
+			}
+			fills = append(fills, f...)
+		}
+	}
+
+	return fills, nil
+}
+
+// Reset implements Reseter to reset all venues.
+func (r *Router) Reset() error {
+	for _, venue := range r.Venues {
+		venue.Exchange.Reset()
+		if venue.Data != nil {
+			venue.Data.Reset()
+		}
+	}
+
+	if rr, ok := r.Policy.(*RoundRobinRouting); ok {
+		rr.next = 0
+	}
+
+	return nil
+}
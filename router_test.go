@@ -0,0 +1,92 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRouterRoute(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	cheap := &Venue{
+		Exchange: &Exchange{Symbol: "CHEAP", Commission: &FixedCommission{Commission: 1}, ExchangeFee: &FixedExchangeFee{}},
+		Data: &Data{latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 10.5},
+		}},
+	}
+	pricey := &Venue{
+		Exchange: &Exchange{Symbol: "PRICEY", Commission: &FixedCommission{Commission: 5}, ExchangeFee: &FixedExchangeFee{}},
+		Data: &Data{latest: map[string]DataEvent{
+			"TEST.DE": &Bar{Close: 10},
+		}},
+	}
+
+	var testCases = []struct {
+		msg    string
+		policy RoutingPolicy
+		order  *Order
+		exp    []string
+	}{
+		{"best price for buy order:",
+			&BestPriceRouting{},
+			&Order{direction: BOT},
+			[]string{"PRICEY", "PRICEY"},
+		},
+		{"best price for sell order:",
+			&BestPriceRouting{},
+			&Order{direction: SLD},
+			[]string{"CHEAP", "CHEAP"},
+		},
+		{"lowest fee:",
+			&LowestFeeRouting{},
+			&Order{direction: BOT},
+			[]string{"CHEAP", "CHEAP"},
+		},
+		{"round robin:",
+			&RoundRobinRouting{},
+			&Order{direction: BOT},
+			[]string{"CHEAP", "PRICEY"},
+		},
+	}
+
+	for _, tc := range testCases {
+		router := NewRouter(tc.policy, cheap, pricey)
+		for i, exp := range tc.exp {
+			order := *tc.order
+			order.Event = Event{timestamp: exampleTime, symbol: "TEST.DE"}
+			order.qty = 10
+
+			fill, err := router.OnOrder(&order, &Data{})
+			if err != nil || fill.Exchange != exp {
+				t.Errorf("%v OnOrder() %v: expected venue %v, actual %#v %v", tc.msg, i, exp, fill, err)
+			}
+		}
+	}
+}
+
+func TestRouterOnDataWithVenueData(t *testing.T) {
+	var exampleTime, _ = time.Parse("2006-01-02", "2017-06-01")
+
+	venue := &Venue{
+		Exchange: &Exchange{Symbol: "VENUE", Commission: &FixedCommission{}, ExchangeFee: &FixedExchangeFee{}, FillModel: &TouchFillModel{}},
+		Data: &Data{stream: []DataEvent{
+			&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Low: 9, High: 11, Close: 10},
+			&Bar{Event: Event{timestamp: exampleTime.Add(time.Hour), symbol: "TEST.DE"}, Low: 8, High: 9, Close: 8},
+		}},
+	}
+	router := NewRouter(&RoundRobinRouting{}, venue)
+
+	order := &Order{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, orderType: LimitOrder, direction: BOT, qty: 10, limitPrice: 8.5}
+	router.OnOrder(order, &Data{})
+
+	// the venue data feed is advanced up to the time of the backtest data event
+	fills, _ := router.OnData(&Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 10})
+	if len(fills) != 0 {
+		t.Errorf("OnData(): expected no fill, actual %#v", fills)
+	}
+
+	fills, _ = router.OnData(&Bar{Event: Event{timestamp: exampleTime.Add(time.Hour), symbol: "TEST.DE"}, Close: 10})
+	if len(fills) != 1 || fills[0].Price() != 8.5 || fills[0].Exchange != "VENUE" {
+		t.Errorf("OnData(): expected fill at venue price, actual %#v", fills)
+	}
+}
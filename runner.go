@@ -0,0 +1,218 @@
+package gobacktest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job is an independent backtest of a runner, e.g. for a single symbol, parameter set or strategy.
+// Build creates the backtest when a worker picks up the job, so only the running backtests are held in memory.
+type Job struct {
+	Name  string
+	Build func() (*Backtest, error)
+}
+
+// JobResult is the summary of a finished job.
+type JobResult struct {
+	Name        string
+	Return      float64
+	MaxDrawdown float64
+	Sharpe      float64
+	Sortino     float64
+	Trades      int
+	Metrics     map[string]float64 // registered custom metrics, nil if none is registered
+	Duration    time.Duration      // wall time of the backtest
+	Seed        int64              // seed of the backtest, 0 if none is set
+	Err         error
+}
+
+// Runner executes many independent backtests across a pool of workers.
+// Each backtest is summarized and released after its run, which bounds the memory
+// to the number of workers.
+type Runner struct {
+	Workers int // defaults to the number of CPUs
+	// Context cancels the runner, jobs not yet started are reported with the error of the context
+	Context context.Context
+}
+
+// Run executes all jobs and returns the report with the results in the order of the jobs.
+func (r *Runner) Run(jobs ...Job) RunnerReport {
+	ctx := r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	workers := r.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]JobResult, len(jobs))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = runJob(ctx, jobs[i])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return RunnerReport{Results: results}
+}
+
+// runJob builds, runs and summarizes a single job.
+func runJob(ctx context.Context, job Job) JobResult {
+	result := JobResult{Name: job.Name}
+
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	test, err := job.Build()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Seed, _ = test.Seed()
+
+	start := time.Now()
+	err = test.RunContext(ctx)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	stats := test.Stats()
+	result.Return, _ = stats.TotalEquityReturn()
+	result.MaxDrawdown = stats.MaxDrawdown()
+	result.Sharpe = stats.SharpRatio(0)
+	result.Sortino = stats.SortinoRatio(0)
+	result.Trades = len(stats.Transactions())
+	if m, ok := stats.(CustomMetricer); ok {
+		result.Metrics = m.CustomMetrics()
+	}
+
+	return result
+}
+
+// RunnerReport compares the results of all jobs of a runner.
+type RunnerReport struct {
+	Results []JobResult
+}
+
+// Failed returns the results of all jobs with an error.
+func (r RunnerReport) Failed() []JobResult {
+	var failed []JobResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Rank returns the successful results ordered by a score, highest first, e.g.
+// func(r JobResult) float64 { return r.Sharpe }.
+func (r RunnerReport) Rank(score func(JobResult) float64) []JobResult {
+	var ranked []JobResult
+	for _, res := range r.Results {
+		if res.Err == nil {
+			ranked = append(ranked, res)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score(ranked[i]) > score(ranked[j])
+	})
+	return ranked
+}
+
+// WriteCSV exports the report as csv, the custom metrics in columns after the error.
+func (r RunnerReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	names := r.metricNames()
+	header := []string{"Name", "Return", "MaxDrawdown", "Sharpe", "Sortino", "Trades", "Duration", "Seed", "Error"}
+	if err := writer.Write(append(header, names...)); err != nil {
+		return err
+	}
+
+	for _, res := range r.Results {
+		var msg string
+		if res.Err != nil {
+			msg = res.Err.Error()
+		}
+		record := []string{
+			res.Name,
+			strconv.FormatFloat(res.Return, 'f', DP, 64),
+			strconv.FormatFloat(res.MaxDrawdown, 'f', DP, 64),
+			strconv.FormatFloat(res.Sharpe, 'f', DP, 64),
+			strconv.FormatFloat(res.Sortino, 'f', DP, 64),
+			strconv.Itoa(res.Trades),
+			res.Duration.String(),
+			strconv.FormatInt(res.Seed, 10),
+			msg,
+		}
+		for _, name := range names {
+			value := ""
+			if v, ok := res.Metrics[name]; ok {
+				value = strconv.FormatFloat(v, 'f', DP, 64)
+			}
+			record = append(record, value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// metricNames returns the sorted names of the custom metrics of all results.
+func (r RunnerReport) metricNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, res := range r.Results {
+		for name := range res.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrintResult prints the comparison of all jobs to the screen.
+func (r RunnerReport) PrintResult() {
+	fmt.Printf("%-20s %10s %10s %10s %10s %7s\n", "Name", "Return", "MaxDD", "Sharpe", "Sortino", "Trades")
+	for _, res := range r.Results {
+		if res.Err != nil {
+			fmt.Printf("%-20s error: %v\n", res.Name, res.Err)
+			continue
+		}
+		fmt.Printf("%-20s %10.4f %10.4f %10.4f %10.4f %7d\n", res.Name, res.Return, res.MaxDrawdown, res.Sharpe, res.Sortino, res.Trades)
+	}
+}
@@ -0,0 +1,132 @@
+package gobacktest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	build := func(closes ...float64) func() (*Backtest, error) {
+		return func() (*Backtest, error) {
+			data := &Data{}
+			data.SetStream(newStressStream(closes...))
+
+			test := New()
+			test.SetData(data)
+			test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+			return test, nil
+		}
+	}
+
+	jobs := []Job{
+		{Name: "flat", Build: build(100, 100, 100)},
+		{Name: "rising", Build: build(100, 110, 120)},
+		{Name: "broken", Build: func() (*Backtest, error) { return nil, errors.New("no data") }},
+		{Name: "falling", Build: build(100, 90, 80)},
+	}
+
+	for _, workers := range []int{1, 3} {
+		report := (&Runner{Workers: workers}).Run(jobs...)
+
+		if len(report.Results) != 4 {
+			t.Fatalf("workers %v Run(): expected 4 results, actual %v", workers, len(report.Results))
+		}
+		for i, res := range report.Results {
+			if res.Name != jobs[i].Name {
+				t.Errorf("workers %v Run(): expected result %v in job order, actual %v", workers, jobs[i].Name, res.Name)
+			}
+		}
+		if failed := report.Failed(); len(failed) != 1 || failed[0].Name != "broken" {
+			t.Errorf("workers %v Failed(): expected the broken job, actual %+v", workers, failed)
+		}
+
+		ranked := report.Rank(func(r JobResult) float64 { return r.Return })
+		var names []string
+		for _, r := range ranked {
+			names = append(names, r.Name)
+		}
+		if strings.Join(names, ",") != "rising,flat,falling" {
+			t.Errorf("workers %v Rank(): expected rising,flat,falling, actual %v", workers, names)
+		}
+		if report.Results[1].Trades != 3 {
+			t.Errorf("workers %v Run(): expected 3 trades, actual %v", workers, report.Results[1].Trades)
+		}
+
+		var buf bytes.Buffer
+		if err := report.WriteCSV(&buf); err != nil {
+			t.Fatalf("WriteCSV(): unexpected error %v", err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 5 {
+			t.Errorf("WriteCSV(): expected 5 lines, actual %v", lines)
+		}
+	}
+}
+
+func TestRunnerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	built := 0
+	job := Job{Name: "never", Build: func() (*Backtest, error) {
+		built++
+		return New(), nil
+	}}
+
+	report := (&Runner{Workers: 2, Context: ctx}).Run(job, job)
+	if len(report.Failed()) != 2 || built != 0 {
+		t.Errorf("Run(): expected all jobs cancelled without building, actual %v failed, %v built", len(report.Failed()), built)
+	}
+}
+
+func TestRunnerSeed(t *testing.T) {
+	job := Job{Name: "seeded", Build: func() (*Backtest, error) {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100, 100))
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&randomStrategy{Strategy: NewStrategy("random")})
+		test.SetSeed(42)
+		return test, nil
+	}}
+
+	report := (&Runner{Workers: 1}).Run(job)
+	if report.Results[0].Err != nil || report.Results[0].Seed != 42 {
+		t.Errorf("Run(): expected the seed 42 recorded in the result, actual %+v", report.Results[0])
+	}
+}
+
+func TestRunnerCustomMetrics(t *testing.T) {
+	if err := RegisterMetric("trades", tradeCount); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterMetric("trades")
+
+	job := Job{Name: "rising", Build: func() (*Backtest, error) {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 110, 120))
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		return test, nil
+	}}
+	report := (&Runner{Workers: 1}).Run(job)
+
+	res := report.Results[0]
+	if res.Metrics["trades"] != float64(res.Trades) || res.Trades == 0 {
+		t.Errorf("Run(): expected the custom metric of %v trades, actual %v", res.Trades, res.Metrics)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV(): unexpected error %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], ",Error,trades") || !strings.HasSuffix(lines[1], ",3.0000") {
+		t.Errorf("WriteCSV(): expected a column of the custom metric, actual %q", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package gobacktest
+
+import (
+	"math"
+	"time"
+)
+
+// RunningStats are the statistics of a backtest so far, e.g. to display the intermediate
+// performance of a long running backtest. They are updated with each equity point in
+// constant time.
+type RunningStats struct {
+	Time        time.Time // time of the last equity point
+	Equity      float64
+	Return      float64 // total return since the first equity point
+	Drawdown    float64 // current drawdown from the high-water mark, 0 or negative
+	MaxDrawdown float64 // 0 or negative
+	Sharpe      float64 // sharpe ratio of the equity returns so far, as SharpRatio(0)
+	Points      int     // equity points so far
+	Trades      int
+}
+
+// RunningStatter returns the statistics of a backtest so far.
+type RunningStatter interface {
+	RunningStats() RunningStats
+}
+
+// running accumulates the statistics of the equity points, the mean and variance of the
+// returns with the algorithm of Welford.
+type running struct {
+	n           int
+	first       float64
+	mean        float64
+	m2          float64
+	maxDrawdown float64
+}
+
+// add adds an equity point.
+func (r *running) add(e equityPoint) {
+	if r.n == 0 {
+		r.first = e.equity
+	}
+	r.n++
+	delta := e.equityReturn - r.mean
+	r.mean += delta / float64(r.n)
+	r.m2 += delta * (e.equityReturn - r.mean)
+
+	if e.drawdown < r.maxDrawdown {
+		r.maxDrawdown = e.drawdown
+	}
+}
+
+// RunningStats returns the statistics of the backtest so far.
+func (s Statistic) RunningStats() RunningStats {
+	last, ok := s.lastEquityPoint()
+	if !ok {
+		return RunningStats{Sharpe: math.NaN()}
+	}
+
+	r := RunningStats{
+		Time:        last.timestamp,
+		Equity:      last.equity,
+		Drawdown:    last.drawdown,
+		MaxDrawdown: s.running.maxDrawdown,
+		Sharpe:      math.NaN(),
+		Points:      s.running.n,
+		Trades:      len(s.transactionHistory),
+	}
+	if s.running.first != 0 {
+		r.Return = (last.equity - s.running.first) / s.running.first
+	}
+	if s.running.n > 1 {
+		r.Sharpe = s.running.mean / math.Sqrt(s.running.m2/float64(s.running.n-1))
+	}
+	return r
+}
@@ -0,0 +1,58 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRunningStats(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing the statistics so far against the statistics of the run
+	var testCases = []struct {
+		msg    string
+		equity []float64
+	}{
+		{"rising equity:", []float64{1000, 1010, 1030}},
+		{"drawdown and recovery:", []float64{1000, 1100, 990, 1045, 1210, 1150}},
+		{"flat equity:", []float64{1000, 1000, 1000}},
+	}
+
+	for _, tc := range testCases {
+		s := &Statistic{}
+		portfolio := &Portfolio{}
+		for i, equity := range tc.equity {
+			portfolio.cash = equity
+			s.Update(&Bar{Event: Event{timestamp: day.AddDate(0, 0, i)}}, portfolio)
+		}
+
+		r := s.RunningStats()
+		first, last := tc.equity[0], tc.equity[len(tc.equity)-1]
+		if r.Points != len(tc.equity) || r.Equity != last || !r.Time.Equal(day.AddDate(0, 0, len(tc.equity)-1)) {
+			t.Errorf("%v RunningStats(): expected %v points up to %v, actual %+v", tc.msg, len(tc.equity), last, r)
+		}
+		if math.Abs(r.Return-(last-first)/first) > 1e-12 || r.MaxDrawdown != s.MaxDrawdown() {
+			t.Errorf("%v RunningStats(): expected return %v and max drawdown %v, actual %+v", tc.msg, (last-first)/first, s.MaxDrawdown(), r)
+		}
+		sharpe := s.SharpRatio(0)
+		if math.IsNaN(sharpe) != math.IsNaN(r.Sharpe) || (!math.IsNaN(sharpe) && math.Abs(r.Sharpe-sharpe) > 1e-9) {
+			t.Errorf("%v RunningStats(): expected sharpe %v, actual %v", tc.msg, sharpe, r.Sharpe)
+		}
+	}
+}
+
+func TestRunningStatsReset(t *testing.T) {
+	s := &Statistic{}
+	if r := s.RunningStats(); r.Points != 0 || !math.IsNaN(r.Sharpe) {
+		t.Errorf("RunningStats(): expected no points and an undefined sharpe ratio, actual %+v", r)
+	}
+
+	portfolio := &Portfolio{cash: 1000}
+	s.Update(&Bar{}, portfolio)
+	s.Update(&Bar{}, portfolio)
+	s.Reset()
+	if r := s.RunningStats(); r.Points != 0 || r.Equity != 0 {
+		t.Errorf("Reset(): expected no statistics so far, actual %+v", r)
+	}
+}
@@ -0,0 +1,110 @@
+package gobacktest
+
+import (
+	"time"
+)
+
+// Clock is the time of the engine on a data event. Data events with the same timestamp,
+// e.g. bars of several symbols, share a bar of the clock.
+type Clock struct {
+	Now      time.Time
+	Previous time.Time // timestamp of the previous bar, zero on the first bar
+	Next     time.Time // timestamp of the next data event, zero on the last event
+	Bar      int       // number of the bar, starting with 1
+}
+
+// Schedule decides if a scheduled strategy runs at a time of the clock.
+type Schedule interface {
+	Fire(Clock) bool
+}
+
+// Scheduler declares the schedule of a strategy.
+type Scheduler interface {
+	Schedule() Schedule
+}
+
+// EveryNthBar fires on the first and every Nth bar after.
+type EveryNthBar struct {
+	N int
+}
+
+// Fire checks if the bar is the first or a multiple of N after it.
+func (s *EveryNthBar) Fire(c Clock) bool {
+	if s.N <= 1 {
+		return true
+	}
+	return (c.Bar-1)%s.N == 0
+}
+
+// OnWeekday fires on the first bar of a weekday, e.g. every Monday.
+type OnWeekday struct {
+	Weekday time.Weekday
+}
+
+// Fire checks if the bar is the first of the weekday.
+func (s *OnWeekday) Fire(c Clock) bool {
+	return c.Now.Weekday() == s.Weekday && newDay(c.Previous, c.Now)
+}
+
+// DailyAtClose fires on the last data event of each day.
+type DailyAtClose struct{}
+
+// Fire checks if the next data event is on a later day.
+func (s *DailyAtClose) Fire(c Clock) bool {
+	return c.Next.IsZero() || newDay(c.Now, c.Next)
+}
+
+// FirstBarOfMonth fires on the first bar of each month.
+type FirstBarOfMonth struct{}
+
+// Fire checks if the bar is the first of a month.
+func (s *FirstBarOfMonth) Fire(c Clock) bool {
+	return c.Previous.IsZero() || c.Previous.Year() != c.Now.Year() || c.Previous.Month() != c.Now.Month()
+}
+
+// newDay checks if to is on a later day than from, a zero from is always a new day.
+func newDay(from, to time.Time) bool {
+	if from.IsZero() {
+		return true
+	}
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	return y1 != y2 || m1 != m2 || d1 != d2
+}
+
+// Schedule returns the schedule of the strategy.
+func (s *Strategy) Schedule() Schedule {
+	return s.schedule
+}
+
+// SetSchedule sets the schedule of the strategy. A scheduled strategy runs only on the
+// data events the schedule fires, without a schedule it runs on every data event.
+func (s *Strategy) SetSchedule(schedule Schedule) *Strategy {
+	s.schedule = schedule
+	return s
+}
+
+// tick advances the clock of the engine to a data event.
+func (t *Backtest) tick(event DataEvent) Clock {
+	if !event.Time().Equal(t.clock.Now) || t.clock.Bar == 0 {
+		t.clock.Previous = t.clock.Now
+		t.clock.Now = event.Time()
+		t.clock.Bar++
+	}
+
+	t.clock.Next = time.Time{}
+	if stream := t.data.Stream(); len(stream) > 0 {
+		t.clock.Next = stream[0].Time()
+	}
+
+	return t.clock
+}
+
+// scheduled checks if the strategy runs at the clock.
+func (t *Backtest) scheduled(c Clock) bool {
+	s, ok := t.strategy.(Scheduler)
+	if !ok || s.Schedule() == nil {
+		return true
+	}
+	return s.Schedule().Fire(c)
+}
@@ -0,0 +1,89 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleFire(t *testing.T) {
+	// 2017-06-05 is a Monday
+	monday, _ := time.Parse("2006-01-02 15:04", "2017-06-05 09:00")
+
+	// testCases is a table for testing the schedules
+	var testCases = []struct {
+		msg      string
+		schedule Schedule
+		clock    Clock
+		exp      bool
+	}{
+		{"first bar of every 3rd:", &EveryNthBar{N: 3}, Clock{Bar: 1}, true},
+		{"second bar of every 3rd:", &EveryNthBar{N: 3}, Clock{Bar: 2}, false},
+		{"fourth bar of every 3rd:", &EveryNthBar{N: 3}, Clock{Bar: 4}, true},
+		{"first bar on monday:",
+			&OnWeekday{Weekday: time.Monday},
+			Clock{Now: monday, Previous: monday.AddDate(0, 0, -3)},
+			true,
+		},
+		{"second bar on monday:",
+			&OnWeekday{Weekday: time.Monday},
+			Clock{Now: monday.Add(time.Hour), Previous: monday},
+			false,
+		},
+		{"first bar on tuesday:",
+			&OnWeekday{Weekday: time.Monday},
+			Clock{Now: monday.AddDate(0, 0, 1), Previous: monday},
+			false,
+		},
+		{"intraday bar before close:",
+			&DailyAtClose{},
+			Clock{Now: monday, Next: monday.Add(time.Hour)},
+			false,
+		},
+		{"last bar of the day:",
+			&DailyAtClose{},
+			Clock{Now: monday, Next: monday.AddDate(0, 0, 1)},
+			true,
+		},
+		{"last bar of the data:",
+			&DailyAtClose{},
+			Clock{Now: monday},
+			true,
+		},
+		{"first bar of the month:",
+			&FirstBarOfMonth{},
+			Clock{Now: monday.AddDate(0, 0, -4), Previous: monday.AddDate(0, 0, -5)},
+			true,
+		},
+		{"bar within the month:",
+			&FirstBarOfMonth{},
+			Clock{Now: monday, Previous: monday.AddDate(0, 0, -3)},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if fire := tc.schedule.Fire(tc.clock); fire != tc.exp {
+			t.Errorf("%v Fire(): \nexpected %v, \nactual %v", tc.msg, tc.exp, fire)
+		}
+	}
+}
+
+func TestBacktestSchedule(t *testing.T) {
+	data := &Data{}
+	// 2017-06-01 is a Thursday, the stream covers two Mondays
+	data.SetStream(newStressStream(100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100))
+
+	strategy := &countingStrategy{Strategy: NewStrategy("monday")}
+	strategy.SetSchedule(&OnWeekday{Weekday: time.Monday})
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(strategy)
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if strategy.events != 2 {
+		t.Errorf("Run(): expected the strategy to run on 2 Mondays, actual %v", strategy.events)
+	}
+}
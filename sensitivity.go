@@ -0,0 +1,219 @@
+package gobacktest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// HeatmapCell is the average score of all trials with a pair of parameter values.
+type HeatmapCell struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Score float64 `json:"score"`
+	Count int     `json:"count"`
+}
+
+// Heatmap shows the score as a function of a pair of parameters,
+// averaged over the values of all other parameters.
+type Heatmap struct {
+	X       string        `json:"x"`
+	Y       string        `json:"y"`
+	XValues []float64     `json:"xValues"`
+	YValues []float64     `json:"yValues"`
+	Cells   []HeatmapCell `json:"cells"`
+}
+
+// NewHeatmap creates the heatmap of a parameter pair from the trials of an optimization.
+// Trials without a finite score are left out.
+func NewHeatmap(result OptimizationResult, x, y string) Heatmap {
+	type pair struct{ x, y float64 }
+	sums := make(map[pair]float64)
+	counts := make(map[pair]int)
+	xs := make(map[float64]bool)
+	ys := make(map[float64]bool)
+
+	for _, trial := range result.Trials {
+		if math.IsInf(trial.Score, 0) || math.IsNaN(trial.Score) {
+			continue
+		}
+		p := pair{trial.Params[x], trial.Params[y]}
+		sums[p] += trial.Score
+		counts[p]++
+		xs[p.x] = true
+		ys[p.y] = true
+	}
+
+	h := Heatmap{X: x, Y: y, XValues: sortedKeys(xs), YValues: sortedKeys(ys)}
+	for _, vx := range h.XValues {
+		for _, vy := range h.YValues {
+			p := pair{vx, vy}
+			if counts[p] == 0 {
+				continue
+			}
+			score := sums[p] / float64(counts[p])
+			h.Cells = append(h.Cells, HeatmapCell{
+				X:     vx,
+				Y:     vy,
+				Score: math.Round(score*math.Pow10(DP)) / math.Pow10(DP),
+				Count: counts[p],
+			})
+		}
+	}
+	return h
+}
+
+// Cell returns the cell of a pair of parameter values.
+func (h Heatmap) Cell(x, y float64) (HeatmapCell, bool) {
+	for _, c := range h.Cells {
+		if c.X == x && c.Y == y {
+			return c, true
+		}
+	}
+	return HeatmapCell{}, false
+}
+
+// Best returns the cell with the highest score.
+func (h Heatmap) Best() (HeatmapCell, bool) {
+	if len(h.Cells) == 0 {
+		return HeatmapCell{}, false
+	}
+	best := h.Cells[0]
+	for _, c := range h.Cells[1:] {
+		if c.Score > best.Score {
+			best = c
+		}
+	}
+	return best, true
+}
+
+// Plateau returns the average score of the neighbouring cells of the best cell relative to its score.
+// A value close to 1 indicates a stable plateau, a low value an isolated peak.
+func (h Heatmap) Plateau() float64 {
+	best, ok := h.Best()
+	if !ok || best.Score == 0 {
+		return 0
+	}
+	xi := indexOf(h.XValues, best.X)
+	yi := indexOf(h.YValues, best.Y)
+
+	var sum float64
+	var n int
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			i, j := xi+dx, yi+dy
+			if i < 0 || j < 0 || i >= len(h.XValues) || j >= len(h.YValues) {
+				continue
+			}
+			if c, ok := h.Cell(h.XValues[i], h.YValues[j]); ok {
+				sum += c.Score
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+
+	plateau := sum / float64(n) / best.Score
+	return math.Round(plateau*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// SensitivityReport holds the heatmaps of all parameter pairs of an optimization.
+type SensitivityReport struct {
+	Best     Params    `json:"best"`
+	Score    float64   `json:"score"`
+	Heatmaps []Heatmap `json:"heatmaps"`
+}
+
+// Sensitivity creates the heatmaps of all parameter pairs from the trials of an optimization.
+// With a single parameter the heatmap pairs the parameter with itself.
+func Sensitivity(result OptimizationResult) SensitivityReport {
+	set := make(map[string]bool)
+	for _, trial := range result.Trials {
+		for name := range trial.Params {
+			set[name] = true
+		}
+	}
+	var names []string
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := SensitivityReport{Best: result.Best, Score: result.Score}
+	if math.IsInf(report.Score, 0) {
+		report.Score = 0
+	}
+	if len(names) == 1 {
+		report.Heatmaps = append(report.Heatmaps, NewHeatmap(result, names[0], names[0]))
+	}
+	for i := 0; i < len(names); i++ {
+		for j := i + 1; j < len(names); j++ {
+			report.Heatmaps = append(report.Heatmaps, NewHeatmap(result, names[i], names[j]))
+		}
+	}
+	return report
+}
+
+// WriteCSV exports all heatmap cells as csv, one row per cell.
+func (r SensitivityReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"X", "Y", "XValue", "YValue", "Score", "Count"})
+	if err != nil {
+		return err
+	}
+
+	for _, h := range r.Heatmaps {
+		for _, c := range h.Cells {
+			err := writer.Write([]string{
+				h.X,
+				h.Y,
+				strconv.FormatFloat(c.X, 'f', -1, 64),
+				strconv.FormatFloat(c.Y, 'f', -1, 64),
+				strconv.FormatFloat(c.Score, 'f', DP, 64),
+				strconv.Itoa(c.Count),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON exports the report as json.
+func (r SensitivityReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// sortedKeys returns the keys of a set sorted ascending.
+func sortedKeys(set map[float64]bool) []float64 {
+	keys := make([]float64, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+// indexOf returns the index of a value in a slice, -1 if not found.
+func indexOf(values []float64, v float64) int {
+	for i, value := range values {
+		if value == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package gobacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSensitivity(t *testing.T) {
+	result := OptimizationResult{
+		Best:  Params{"a": 1, "b": 1, "c": 0},
+		Score: 10,
+		Trials: []Trial{
+			{Params: Params{"a": 0, "b": 0, "c": 0}, Score: 2},
+			{Params: Params{"a": 0, "b": 1, "c": 0}, Score: 4},
+			{Params: Params{"a": 1, "b": 0, "c": 0}, Score: 6},
+			{Params: Params{"a": 1, "b": 1, "c": 0}, Score: 10},
+			{Params: Params{"a": 1, "b": 1, "c": 1}, Score: 8},
+		},
+	}
+
+	report := Sensitivity(result)
+	if len(report.Heatmaps) != 3 {
+		t.Fatalf("Sensitivity(): expected 3 heatmaps, actual %v", len(report.Heatmaps))
+	}
+
+	h := report.Heatmaps[0]
+	if h.X != "a" || h.Y != "b" || len(h.Cells) != 4 {
+		t.Fatalf("Sensitivity(): expected heatmap a/b with 4 cells, actual %#v", h)
+	}
+	// the trials with a 1 and b 1 are averaged over c
+	if c, ok := h.Cell(1, 1); !ok || c.Score != 9 || c.Count != 2 {
+		t.Errorf("Cell(): expected score 9 of 2 trials, actual %#v", c)
+	}
+	if best, _ := h.Best(); best.X != 1 || best.Y != 1 {
+		t.Errorf("Best(): expected cell 1/1, actual %#v", best)
+	}
+	// neighbours 2, 4 and 6 average 4, relative to 9
+	if p := h.Plateau(); p != 0.4444 {
+		t.Errorf("Plateau(): expected 0.4444, actual %v", p)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV(): unexpected error %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "X,Y,XValue,YValue,Score,Count\na,b,0,0,2.0000,1\n") {
+		t.Errorf("WriteCSV(): unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON(): unexpected error %v", err)
+	}
+	var decoded SensitivityReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Heatmaps) != 3 {
+		t.Errorf("WriteJSON(): expected decodable report, actual %v", err)
+	}
+}
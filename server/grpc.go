@@ -0,0 +1,306 @@
+//go:build grpc
+// +build grpc
+
+package server
+
+// The gRPC service of proto/gobacktest/v1/backtest.proto on the generated code of the package
+// backtestv1, which is generated with go generate ./proto/... and needs grpc and protobuf, e.g.
+//
+//	go get google.golang.org/grpc google.golang.org/protobuf
+//	go build -tags grpc
+//
+// A server serves the service next to the REST API, e.g.
+//
+//	g := grpc.NewServer()
+//	server.RegisterGRPC(g, s)
+//	g.Serve(lis)
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+	backtestv1 "github.com/dirkolbrich/gobacktest/proto/gobacktest/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RegisterGRPC registers the BacktestService of the server on a gRPC server.
+func RegisterGRPC(r grpc.ServiceRegistrar, s *Server) {
+	backtestv1.RegisterBacktestServiceServer(r, &grpcService{server: s})
+}
+
+// grpcService implements the BacktestService by delegating to the methods of the server.
+type grpcService struct {
+	backtestv1.UnimplementedBacktestServiceServer
+	server *Server
+}
+
+// SubmitJob builds the backtest of the config and queues it as a new job.
+func (g *grpcService) SubmitJob(ctx context.Context, req *backtestv1.SubmitJobRequest) (*backtestv1.JobStatus, error) {
+	s, err := g.server.Submit(configFromProto(req.GetConfig()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobStatusToProto(s), nil
+}
+
+// GetJob returns the status and progress of a job.
+func (g *grpcService) GetJob(ctx context.Context, req *backtestv1.JobRequest) (*backtestv1.JobStatus, error) {
+	s, err := g.server.Job(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobStatusToProto(s), nil
+}
+
+// ListJobs returns all jobs in the order of submission.
+func (g *grpcService) ListJobs(ctx context.Context, req *backtestv1.ListJobsRequest) (*backtestv1.ListJobsResponse, error) {
+	resp := &backtestv1.ListJobsResponse{}
+	for _, s := range g.server.Jobs() {
+		resp.Jobs = append(resp.Jobs, jobStatusToProto(s))
+	}
+	return resp, nil
+}
+
+// CancelJob cancels a queued or running job.
+func (g *grpcService) CancelJob(ctx context.Context, req *backtestv1.JobRequest) (*backtestv1.JobStatus, error) {
+	s, err := g.server.Cancel(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobStatusToProto(s), nil
+}
+
+// WatchJob streams the status of a job on every progress update, until it is finished
+// or the client cancels the stream.
+func (g *grpcService) WatchJob(req *backtestv1.JobRequest, stream backtestv1.BacktestService_WatchJobServer) error {
+	err := g.server.Watch(stream.Context(), req.GetId(), func(s JobStatus) error {
+		return stream.Send(jobStatusToProto(s))
+	})
+	return grpcError(err)
+}
+
+// GetResult returns the metrics of a finished job.
+func (g *grpcService) GetResult(ctx context.Context, req *backtestv1.JobRequest) (*backtestv1.Result, error) {
+	r, err := g.server.Result(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &backtestv1.Result{
+		Id:                  r.ID,
+		Return:              r.Return,
+		MaxDrawdown:         r.MaxDrawdown,
+		MaxDrawdownDuration: r.MaxDrawdownDuration,
+		Sharpe:              r.Sharpe,
+		Sortino:             r.Sortino,
+		Trades:              int64(r.Trades),
+		Events:              int64(r.Events),
+		Seed:                r.Seed,
+		Metrics:             r.Metrics,
+	}, nil
+}
+
+// GetEquity returns the equity curve of a finished job.
+func (g *grpcService) GetEquity(ctx context.Context, req *backtestv1.JobRequest) (*backtestv1.EquityResponse, error) {
+	points, err := g.server.Equity(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &backtestv1.EquityResponse{Points: make([]*backtestv1.EquityPoint, len(points))}
+	for i, p := range points {
+		resp.Points[i] = &backtestv1.EquityPoint{
+			Time:     timestamp(p.Time),
+			Equity:   p.Equity,
+			Return:   p.Return,
+			Drawdown: p.Drawdown,
+		}
+	}
+	return resp, nil
+}
+
+// GetTrades returns the trades of a finished job.
+func (g *grpcService) GetTrades(ctx context.Context, req *backtestv1.JobRequest) (*backtestv1.TradesResponse, error) {
+	trades, err := g.server.Trades(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &backtestv1.TradesResponse{Trades: make([]*backtestv1.Trade, len(trades))}
+	for i, t := range trades {
+		resp.Trades[i] = &backtestv1.Trade{
+			Time:      timestamp(t.Time),
+			Symbol:    t.Symbol,
+			Direction: t.Direction,
+			Qty:       t.Qty,
+			Price:     t.Price,
+			Cost:      t.Cost,
+			FillId:    int64(t.FillID),
+			OrderId:   int64(t.OrderID),
+			SignalId:  int64(t.SignalID),
+		}
+	}
+	return resp, nil
+}
+
+// StreamEvents streams the recorded events of a finished job.
+func (g *grpcService) StreamEvents(req *backtestv1.JobRequest, stream backtestv1.BacktestService_StreamEventsServer) error {
+	records, err := g.server.Events(req.GetId())
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, r := range records {
+		if err := stream.Send(eventToProto(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcError maps the errors of the service to the status codes of gRPC.
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrNotFinished):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrInvalidConfig):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// timestamp converts a time, the zero time is not set.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// configFromProto converts the config of a request, unset messages are zero configs.
+func configFromProto(c *backtestv1.Config) config.Config {
+	data, commission, size := c.GetData(), c.GetCommission(), c.GetSize()
+	risk, strategy, notify := c.GetRisk(), c.GetStrategy(), c.GetNotify()
+
+	cfg := config.Config{
+		Symbols: c.GetSymbols(),
+		Cash:    c.GetCash(),
+		Seed:    c.Seed,
+		Data: config.DataConfig{
+			Type:         data.GetType(),
+			Dir:          data.GetDir(),
+			Start:        data.GetStart(),
+			End:          data.GetEnd(),
+			Suffix:       data.GetSuffix(),
+			Timezone:     data.GetTimezone(),
+			SecurityType: data.GetSecurityType(),
+			Market:       data.GetMarket(),
+			Resolution:   data.GetResolution(),
+		},
+		Commission: config.CommissionConfig{
+			Type:     commission.GetType(),
+			Value:    commission.GetValue(),
+			MinValue: commission.GetMinValue(),
+			Min:      commission.GetMin(),
+			Max:      commission.GetMax(),
+		},
+		ExchangeFee: c.GetExchangeFee(),
+		Size: config.SizeConfig{
+			DefaultSize:  size.GetDefaultSize(),
+			DefaultValue: size.GetDefaultValue(),
+		},
+		Risk: config.RiskConfig{
+			MaxDrawdown:    risk.GetMaxDrawdown(),
+			MaxDailyLoss:   risk.GetMaxDailyLoss(),
+			MaxDailyLossPc: risk.GetMaxDailyLossPercent(),
+			Flatten:        risk.GetFlatten(),
+			ShortSelling:   risk.GetShortSelling(),
+			Session: config.SessionConfig{
+				Start:    risk.GetSession().GetStart(),
+				End:      risk.GetSession().GetEnd(),
+				FlatAt:   risk.GetSession().GetFlatAt(),
+				Timezone: risk.GetSession().GetTimezone(),
+			},
+		},
+		Strategy: config.StrategyConfig{
+			Name:   strategy.GetName(),
+			Params: gbt.Params(strategy.GetParams()),
+		},
+		Notify: config.NotifyConfig{
+			Webhook: notify.GetWebhook(),
+			Slack:   notify.GetSlack(),
+			Telegram: config.TelegramConfig{
+				Token: notify.GetTelegram().GetToken(),
+				Chat:  notify.GetTelegram().GetChat(),
+			},
+			Events: notify.GetEvents(),
+		},
+	}
+	return cfg
+}
+
+// jobStatusToProto converts the status of a job.
+func jobStatusToProto(s JobStatus) *backtestv1.JobStatus {
+	p := s.Progress
+	progress := &backtestv1.Progress{
+		Processed: int64(p.Processed),
+		Total:     int64(p.Total),
+		Percent:   p.Percent,
+		Time:      timestamp(p.Time),
+		Elapsed:   p.Elapsed,
+		Remaining: p.Remaining,
+	}
+	if stats := p.Stats; stats != nil {
+		progress.Stats = &backtestv1.RunningStats{
+			Equity:      stats.Equity,
+			Return:      stats.Return,
+			Drawdown:    stats.Drawdown,
+			MaxDrawdown: stats.MaxDrawdown,
+			Sharpe:      stats.Sharpe,
+			Trades:      int64(stats.Trades),
+		}
+	}
+
+	return &backtestv1.JobStatus{
+		Id:        s.ID,
+		Name:      s.Name,
+		Status:    string(s.Status),
+		Submitted: timestamp(s.Submitted),
+		Progress:  progress,
+		Error:     s.Error,
+	}
+}
+
+// eventToProto converts a recorded event.
+func eventToProto(r gbt.EventRecord) *backtestv1.Event {
+	e := &backtestv1.Event{
+		Type:      r.Type,
+		Time:      timestamp(r.Time),
+		Symbol:    r.Symbol,
+		Id:        int64(r.ID),
+		Parent:    int64(r.Parent),
+		Direction: int32(r.Direction),
+		Values:    r.Values,
+		Metric:    r.Metric,
+		Reason:    r.Reason,
+		Tags:      r.Tags,
+		Text:      r.Text,
+	}
+	for _, l := range r.Bids {
+		e.Bids = append(e.Bids, &backtestv1.BookLevel{Price: l.Price, Volume: l.Volume})
+	}
+	for _, l := range r.Asks {
+		e.Asks = append(e.Asks, &backtestv1.BookLevel{Price: l.Price, Volume: l.Volume})
+	}
+	return e
+}
@@ -0,0 +1,173 @@
+//go:build grpc
+// +build grpc
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/dirkolbrich/gobacktest/config"
+	backtestv1 "github.com/dirkolbrich/gobacktest/proto/gobacktest/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// newTestGRPC serves the service of a server with the test data and returns a client.
+func newTestGRPC(t *testing.T) backtestv1.BacktestServiceClient {
+	s := New()
+	s.DataDir = "../examples/testdata"
+	s.ProgressEvery = 10
+	t.Cleanup(s.Close)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	RegisterGRPC(g, s)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return backtestv1.NewBacktestServiceClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	client := newTestGRPC(t)
+	ctx := context.Background()
+
+	// the test config of the REST API is a valid Config in the JSON mapping of protobuf
+	c := &backtestv1.Config{}
+	if err := protojson.Unmarshal([]byte(testConfig), c); err != nil {
+		t.Fatalf("protojson.Unmarshal(): unexpected error %v", err)
+	}
+	submitted, err := client.SubmitJob(ctx, &backtestv1.SubmitJobRequest{Config: c})
+	if err != nil {
+		t.Fatalf("SubmitJob(): unexpected error %v", err)
+	}
+	if submitted.GetName() != "moving-average-cross" {
+		t.Errorf("SubmitJob(): unexpected job %v", submitted)
+	}
+
+	stream, err := client.WatchJob(ctx, &backtestv1.JobRequest{Id: submitted.GetId()})
+	if err != nil {
+		t.Fatalf("WatchJob(): unexpected error %v", err)
+	}
+	var updates []*backtestv1.JobStatus
+	for {
+		s, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("WatchJob(): unexpected error %v", err)
+		}
+		updates = append(updates, s)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("WatchJob(): expected progress updates, actual %v", len(updates))
+	}
+	if last := updates[len(updates)-1]; last.GetStatus() != string(StatusDone) || last.GetProgress().GetPercent() != 100 {
+		t.Errorf("WatchJob(): expected the final status done, actual %v", last)
+	}
+
+	result, err := client.GetResult(ctx, &backtestv1.JobRequest{Id: submitted.GetId()})
+	if err != nil {
+		t.Fatalf("GetResult(): unexpected error %v", err)
+	}
+	if result.GetTrades() == 0 || result.Seed == nil || result.GetSeed() != 7 {
+		t.Errorf("GetResult(): expected trades with seed 7, actual %v", result)
+	}
+
+	trades, err := client.GetTrades(ctx, &backtestv1.JobRequest{Id: submitted.GetId()})
+	if err != nil || int64(len(trades.GetTrades())) != result.GetTrades() {
+		t.Errorf("GetTrades(): expected %v trades, actual %v %v", result.GetTrades(), len(trades.GetTrades()), err)
+	}
+
+	events, err := client.StreamEvents(ctx, &backtestv1.JobRequest{Id: submitted.GetId()})
+	if err != nil {
+		t.Fatalf("StreamEvents(): unexpected error %v", err)
+	}
+	if e, err := events.Recv(); err != nil || e.GetType() == "" {
+		t.Errorf("StreamEvents(): expected a recorded event, actual %v %v", e, err)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	client := newTestGRPC(t)
+	ctx := context.Background()
+
+	// testCases is a table for testing the status codes of the errors of the service
+	var testCases = []struct {
+		msg  string
+		call func() error
+		code codes.Code
+	}{
+		{"GetJob of an unknown job:",
+			func() error { _, err := client.GetJob(ctx, &backtestv1.JobRequest{Id: "99"}); return err },
+			codes.NotFound},
+		{"SubmitJob of an unknown strategy:",
+			func() error {
+				c := &backtestv1.Config{Strategy: &backtestv1.StrategyConfig{Name: "unknown"}}
+				_, err := client.SubmitJob(ctx, &backtestv1.SubmitJobRequest{Config: c})
+				return err
+			},
+			codes.InvalidArgument},
+		{"WatchJob of an unknown job:",
+			func() error {
+				stream, err := client.WatchJob(ctx, &backtestv1.JobRequest{Id: "99"})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			codes.NotFound},
+	}
+
+	for _, tc := range testCases {
+		if code := status.Code(tc.call()); code != tc.code {
+			t.Errorf("%v expected code %v, actual %v", tc.msg, tc.code, code)
+		}
+	}
+}
+
+func TestConfigFromProto(t *testing.T) {
+	// every field of the config is set in the JSON mapping of protobuf
+	const full = `{
+	"symbols": ["SDF.DE"], "cash": 5000, "seed": 7, "exchangeFee": 1,
+	"data": {"type": "lean", "dir": "bar", "start": "2016-01-01", "end": "2017-06-30", "suffix": "1440",
+		"timezone": "Europe/Athens", "securityType": "equity", "market": "usa", "resolution": "daily"},
+	"commission": {"type": "value", "value": 0.01, "minValue": 1, "min": 2, "max": 3},
+	"size": {"defaultSize": 10, "defaultValue": 1000},
+	"risk": {"maxDrawdown": 0.2, "maxDailyLoss": 100, "maxDailyLossPercent": 0.05, "flatten": true, "shortSelling": true,
+		"session": {"start": "09:45", "end": "15:30", "flatAt": "15:55", "timezone": "America/New_York"}},
+	"strategy": {"name": "moving-average-cross", "params": {"short": 5, "long": 20}},
+	"notify": {"webhook": "http://localhost/hook", "slack": "http://localhost/slack",
+		"telegram": {"token": "secret", "chat": "42"}, "events": ["fill", "done"]}
+}`
+
+	c := &backtestv1.Config{}
+	if err := protojson.Unmarshal([]byte(full), c); err != nil {
+		t.Fatalf("protojson.Unmarshal(): unexpected error %v", err)
+	}
+	var exp config.Config
+	if err := json.Unmarshal([]byte(full), &exp); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := configFromProto(c); !reflect.DeepEqual(cfg, exp) {
+		t.Errorf("configFromProto(): \nexpected %+v, \nactual   %+v", exp, cfg)
+	}
+}
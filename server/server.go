@@ -0,0 +1,300 @@
+// Package server exposes the engine over a REST API, so web frontends can submit
+// backtest jobs, poll their progress and fetch the results.
+//
+// The endpoints are
+//
+//	POST   /jobs              submit a job, the body is a config in JSON or TOML (Content-Type application/toml)
+//	GET    /jobs              list all jobs
+//	GET    /jobs/{id}         status and progress of a job
+//	DELETE /jobs/{id}         cancel a job
+//	GET    /jobs/{id}/result  metrics of a finished job
+//	GET    /jobs/{id}/equity  equity curve of a finished job
+//	GET    /jobs/{id}/trades  trades of a finished job
+//	GET    /jobs/{id}/events  recorded event stream of a finished job
+//	GET    /jobs/{id}/watch   status of a job on every progress update as JSON Lines, until it is finished
+//	GET    /metrics           metrics of the jobs in the Prometheus text format, if Metrics is set
+//
+// The server reads the data of a job from the local disk. It is meant for trusted networks,
+// set DataDir to restrict the data directories of the jobs.
+//
+// The same service is defined for gRPC in proto/gobacktest/v1/backtest.proto, RegisterGRPC
+// serves it with the grpc tag, WatchJob streams the progress of a job. The messages of the
+// schema are also the JSON documents of the REST API.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dirkolbrich/gobacktest/config"
+	"github.com/dirkolbrich/gobacktest/metrics"
+)
+
+// Status is the state of a job.
+type Status string
+
+// different job states
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// maxBody limits the size of a submitted config.
+const maxBody = 1 << 20
+
+// Server runs the submitted backtest jobs, at most Workers at the same time.
+type Server struct {
+	Workers int    // defaults to the number of CPUs
+	DataDir string // if set, the data directories of the jobs are resolved within it
+	// ProgressEvery sets the data events between progress updates, defaults to 100
+	ProgressEvery int
+	// Metrics exports the metrics of the jobs on GET /metrics, the run label is the job id
+	Metrics *metrics.Exporter
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	order  []string
+	nextID int
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a server ready for use.
+func New() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{ctx: ctx, cancel: cancel}
+}
+
+// Close cancels all queued and running jobs.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// JobStatus is the response of the status of a job.
+type JobStatus struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Submitted time.Time `json:"submitted"`
+	Progress  Progress  `json:"progress"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Progress is the progress of a running job.
+type Progress struct {
+	Processed int       `json:"processed"`
+	Total     int       `json:"total"`
+	Percent   float64   `json:"percent"`
+	Time      time.Time `json:"time"`
+	Elapsed   float64   `json:"elapsed"`   // seconds
+	Remaining float64   `json:"remaining"` // estimated seconds, 0 if unknown
+	// Stats are the statistics of the job so far, set after the first progress update
+	Stats *RunningStats `json:"stats,omitempty"`
+}
+
+// RunningStats are the statistics of a running job so far.
+// The sharpe ratio is null while it is not defined, e.g. for less than two equity points.
+type RunningStats struct {
+	Equity      float64  `json:"equity"`
+	Return      float64  `json:"return"`
+	Drawdown    float64  `json:"drawdown"`
+	MaxDrawdown float64  `json:"maxDrawdown"`
+	Sharpe      *float64 `json:"sharpe"`
+	Trades      int      `json:"trades"`
+}
+
+// Result is the response of the metrics of a finished job.
+// Metrics which are not defined, e.g. the sharpe ratio without volatility, are null.
+type Result struct {
+	ID                  string   `json:"id"`
+	Return              float64  `json:"return"`
+	MaxDrawdown         float64  `json:"maxDrawdown"`
+	MaxDrawdownDuration float64  `json:"maxDrawdownDuration"` // seconds
+	Sharpe              *float64 `json:"sharpe"`
+	Sortino             *float64 `json:"sortino"`
+	Trades              int      `json:"trades"`
+	Events              int      `json:"events"`
+	Seed                *int64   `json:"seed,omitempty"`
+	// the registered custom metrics, undefined values are left out
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// EquityPoint is a point of the equity curve response.
+type EquityPoint struct {
+	Time     time.Time `json:"time"`
+	Equity   float64   `json:"equity"`
+	Return   float64   `json:"return"`
+	Drawdown float64   `json:"drawdown"`
+}
+
+// Trade is a trade of the trades response.
+type Trade struct {
+	Time      time.Time `json:"time"`
+	Symbol    string    `json:"symbol"`
+	Direction string    `json:"direction"`
+	Qty       float64   `json:"qty"`
+	Price     float64   `json:"price"`
+	Cost      float64   `json:"cost"`
+	FillID    int       `json:"fillId"`
+	OrderID   int       `json:"orderId"`
+	SignalID  int       `json:"signalId,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/metrics" && s.Metrics != nil && r.Method == http.MethodGet {
+		s.Metrics.ServeHTTP(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodPost:
+			s.submit(w, r)
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.Jobs())
+		default:
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		}
+		return
+	}
+
+	id := parts[1]
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			respond(w, http.StatusOK)(s.Job(id))
+		case http.MethodDelete:
+			respond(w, http.StatusAccepted)(s.Cancel(id))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		}
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	switch parts[2] {
+	case "result":
+		respond(w, http.StatusOK)(s.Result(id))
+	case "equity":
+		respond(w, http.StatusOK)(s.Equity(id))
+	case "trades":
+		respond(w, http.StatusOK)(s.Trades(id))
+	case "events":
+		respond(w, http.StatusOK)(s.Events(id))
+	case "watch":
+		s.watch(w, r, id)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("resource %s not found", parts[2]))
+	}
+}
+
+// submit parses the config and submits the job.
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ext := ".json"
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/toml" {
+		ext = ".toml"
+	}
+	c, err := config.Parse(body, ext)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	status, err := s.Submit(c)
+	if err == nil {
+		w.Header().Set("Location", "/jobs/"+status.ID)
+	}
+	respond(w, http.StatusAccepted)(status, err)
+}
+
+// watch streams the status of a job as JSON Lines until the job is finished.
+func (s *Server) watch(w http.ResponseWriter, r *http.Request, id string) {
+	if _, err := s.Job(id); err != nil {
+		writeError(w, errorCode(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	s.Watch(r.Context(), id, func(status JobStatus) error {
+		if err := encoder.Encode(status); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// respond returns a function writing the result of a service method.
+func respond(w http.ResponseWriter, code int) func(interface{}, error) {
+	return func(v interface{}, err error) {
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJSON(w, code, v)
+	}
+}
+
+// errorCode returns the http status code of an error of the service.
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotFinished):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidConfig):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// writeJSON writes a json response.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
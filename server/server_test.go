@@ -0,0 +1,245 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/metrics"
+)
+
+var testConfig = `{
+	"symbols": ["SDF.DE"],
+	"seed": 7,
+	"data": {"dir": "bar", "start": "2016-01-01", "end": "2017-06-30"},
+	"strategy": {"name": "moving-average-cross", "params": {"short": 5, "long": 20}}
+}`
+
+// newTestServer starts a server with the test data.
+func newTestServer() (*Server, *httptest.Server) {
+	s := New()
+	s.DataDir = "../examples/testdata"
+	return s, httptest.NewServer(s)
+}
+
+// decode sends a request and decodes the json response.
+func decode(t *testing.T, method, url, contentType, body string, v interface{}) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: could not decode response: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// wait polls the status of a job until it is no longer queued or running.
+func wait(t *testing.T, url string) JobStatus {
+	for i := 0; i < 500; i++ {
+		var status JobStatus
+		decode(t, http.MethodGet, url, "", "", &status)
+		if status.Status != StatusQueued && status.Status != StatusRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", url)
+	return JobStatus{}
+}
+
+func TestServerJob(t *testing.T) {
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+
+	var submitted JobStatus
+	if code := decode(t, http.MethodPost, ts.URL+"/jobs", "application/json", testConfig, &submitted); code != http.StatusAccepted {
+		t.Fatalf("POST /jobs: expected status 202, actual %v", code)
+	}
+	if submitted.ID != "1" || submitted.Name != "moving-average-cross" {
+		t.Errorf("POST /jobs: unexpected job %+v", submitted)
+	}
+
+	status := wait(t, ts.URL+"/jobs/1")
+	if status.Status != StatusDone || status.Progress.Percent != 100 || status.Progress.Processed == 0 {
+		t.Fatalf("GET /jobs/1: expected a done job with full progress, actual %+v", status)
+	}
+	if stats := status.Progress.Stats; stats == nil || stats.Equity == 0 || stats.Sharpe == nil || stats.MaxDrawdown > 0 {
+		t.Errorf("GET /jobs/1: expected the statistics so far, actual %+v", stats)
+	}
+
+	var result Result
+	if code := decode(t, http.MethodGet, ts.URL+"/jobs/1/result", "", "", &result); code != http.StatusOK {
+		t.Fatalf("GET /jobs/1/result: expected status 200, actual %v", code)
+	}
+	if result.Trades == 0 || result.Events == 0 || result.Sharpe == nil || result.Seed == nil || *result.Seed != 7 {
+		t.Errorf("GET /jobs/1/result: unexpected result %+v", result)
+	}
+
+	var curve []EquityPoint
+	decode(t, http.MethodGet, ts.URL+"/jobs/1/equity", "", "", &curve)
+	if len(curve) != status.Progress.Processed {
+		t.Errorf("GET /jobs/1/equity: expected %v points, actual %v", status.Progress.Processed, len(curve))
+	}
+
+	var trades []Trade
+	decode(t, http.MethodGet, ts.URL+"/jobs/1/trades", "", "", &trades)
+	if len(trades) != result.Trades || trades[0].Symbol != "SDF.DE" || trades[0].Direction != "buy" {
+		t.Errorf("GET /jobs/1/trades: expected %v trades starting with a buy, actual %+v", result.Trades, trades)
+	}
+
+	var list []JobStatus
+	decode(t, http.MethodGet, ts.URL+"/jobs", "", "", &list)
+	if len(list) != 1 || list[0].ID != "1" {
+		t.Errorf("GET /jobs: expected the submitted job, actual %+v", list)
+	}
+}
+
+func TestServerCustomMetrics(t *testing.T) {
+	trades := gbt.MetricCalculatorFunc(func(equity []gbt.EquityPoint, trades []gbt.FillEvent) float64 {
+		return float64(len(trades))
+	})
+	undefined := gbt.MetricCalculatorFunc(func(equity []gbt.EquityPoint, trades []gbt.FillEvent) float64 {
+		return math.NaN()
+	})
+	if err := gbt.RegisterMetric("custom trades", trades); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("custom trades")
+	if err := gbt.RegisterMetric("undefined", undefined); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("undefined")
+
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+
+	decode(t, http.MethodPost, ts.URL+"/jobs", "application/json", testConfig, nil)
+	wait(t, ts.URL+"/jobs/1")
+
+	var result Result
+	decode(t, http.MethodGet, ts.URL+"/jobs/1/result", "", "", &result)
+	if len(result.Metrics) != 1 || result.Metrics["custom trades"] != float64(result.Trades) {
+		t.Errorf("GET /jobs/1/result: expected the defined custom metric, actual %v", result.Metrics)
+	}
+}
+
+func TestServerTOML(t *testing.T) {
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+
+	config := "symbols = [\"SDF.DE\"]\n[data]\ndir = \"bar\"\nstart = \"2017-01-01\"\n[strategy]\nname = \"buy-and-hold\"\n"
+	var submitted JobStatus
+	if code := decode(t, http.MethodPost, ts.URL+"/jobs", "application/toml; charset=utf-8", config, &submitted); code != http.StatusAccepted {
+		t.Fatalf("POST /jobs: expected status 202, actual %v", code)
+	}
+	if status := wait(t, ts.URL+"/jobs/"+submitted.ID); status.Status != StatusDone {
+		t.Errorf("GET /jobs/%v: expected a done job, actual %+v", submitted.ID, status)
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	s := New()
+	s.DataDir = "../examples/testdata"
+	s.Workers = 1
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	// occupy the only worker, so the job stays queued
+	s.add("blocker", nil)
+	s.slots <- struct{}{}
+
+	var submitted JobStatus
+	decode(t, http.MethodPost, ts.URL+"/jobs", "", testConfig, &submitted)
+	if code := decode(t, http.MethodDelete, ts.URL+"/jobs/"+submitted.ID, "", "", nil); code != http.StatusAccepted {
+		t.Errorf("DELETE /jobs/%v: expected status 202, actual %v", submitted.ID, code)
+	}
+
+	status := wait(t, ts.URL+"/jobs/"+submitted.ID)
+	if status.Status != StatusCancelled || status.Error == "" {
+		t.Errorf("GET /jobs/%v: expected a cancelled job, actual %+v", submitted.ID, status)
+	}
+
+	var errResp map[string]string
+	if code := decode(t, http.MethodGet, ts.URL+"/jobs/"+submitted.ID+"/result", "", "", &errResp); code != http.StatusConflict {
+		t.Errorf("GET /jobs/%v/result: expected status 409, actual %v %v", submitted.ID, code, errResp)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+
+	// testCases is a table for testing the error responses
+	var testCases = []struct {
+		msg     string
+		method  string
+		path    string
+		body    string
+		expCode int
+	}{
+		{"unknown path:", http.MethodGet, "/backtests", "", http.StatusNotFound},
+		{"unknown job:", http.MethodGet, "/jobs/42", "", http.StatusNotFound},
+		{"invalid method:", http.MethodPut, "/jobs", "", http.StatusMethodNotAllowed},
+		{"invalid config:", http.MethodPost, "/jobs", "{", http.StatusBadRequest},
+		{"unknown strategy:", http.MethodPost, "/jobs", `{"symbols": ["SDF.DE"], "data": {"dir": "bar"}, "strategy": {"name": "unknown"}}`, http.StatusBadRequest},
+		{"data outside of the data dir:", http.MethodPost, "/jobs", `{"symbols": ["SDF.DE"], "data": {"dir": "../../examples/testdata/bar"}}`, http.StatusBadRequest},
+		{"absolute data dir:", http.MethodPost, "/jobs", `{"symbols": ["SDF.DE"], "data": {"dir": "/etc"}}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		var resp map[string]string
+		code := decode(t, tc.method, ts.URL+tc.path, "", tc.body, &resp)
+		if code != tc.expCode || resp["error"] == "" {
+			t.Errorf("%v %s %s: expected status %v with an error, actual %v %v", tc.msg, tc.method, tc.path, tc.expCode, code, resp)
+		}
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+	s.Metrics = metrics.NewExporter()
+
+	decode(t, http.MethodPost, ts.URL+"/jobs", "application/json", testConfig, nil)
+	wait(t, ts.URL+"/jobs/1")
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	for _, sample := range []string{
+		`gobacktest_job_duration_seconds_count{status="done"} 1`,
+		"gobacktest_runs_active 0",
+	} {
+		if !strings.Contains(string(body), sample) {
+			t.Errorf("GET /metrics: expected sample %q, actual\n%s", sample, body)
+		}
+	}
+}
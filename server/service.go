@@ -0,0 +1,403 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// The methods of this file are the service of the server independent of the transport.
+// The REST API and the gRPC service of grpc.go delegate to them.
+
+var (
+	// ErrNotFound is returned for an unknown job.
+	ErrNotFound = errors.New("job not found")
+	// ErrNotFinished is returned for the results of a job which is not done.
+	ErrNotFinished = errors.New("job not finished")
+	// ErrInvalidConfig is returned for a config which can not be built into a backtest.
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+// Submit builds the backtest of the config and queues it as a new job.
+// Errors of the config are wrapped in ErrInvalidConfig.
+func (s *Server) Submit(c config.Config) (JobStatus, error) {
+	if err := s.resolveData(&c); err != nil {
+		return JobStatus{}, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	test, err := c.Build()
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	j := s.add(c.Strategy.Name, test)
+	go s.run(j)
+
+	return s.status(j), nil
+}
+
+// Job returns the status of a job.
+func (s *Server) Job(id string) (JobStatus, error) {
+	j, err := s.job(id)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	return s.status(j), nil
+}
+
+// Jobs returns the status of all jobs in the order of submission.
+func (s *Server) Jobs() []JobStatus {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id])
+	}
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, len(jobs))
+	for i, j := range jobs {
+		statuses[i] = s.status(j)
+	}
+	return statuses
+}
+
+// Cancel cancels a queued or running job.
+func (s *Server) Cancel(id string) (JobStatus, error) {
+	j, err := s.job(id)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	j.cancel()
+	return s.status(j), nil
+}
+
+// Watch calls fn with the status of a job on every progress update, until the job is finished,
+// fn returns an error or the context is cancelled. The last call receives the final status.
+func (s *Server) Watch(ctx context.Context, id string, fn func(JobStatus) error) error {
+	j, err := s.job(id)
+	if err != nil {
+		return err
+	}
+
+	for {
+		s.mu.Lock()
+		changed := j.changed
+		s.mu.Unlock()
+
+		status := s.status(j)
+		if err := fn(status); err != nil {
+			return err
+		}
+		if status.finished() {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Result returns the metrics of a finished job.
+func (s *Server) Result(id string) (Result, error) {
+	j, stats, err := s.finished(id)
+	if err != nil {
+		return Result{}, err
+	}
+
+	r := Result{
+		ID:                  j.id,
+		MaxDrawdown:         stats.MaxDrawdown(),
+		MaxDrawdownDuration: stats.MaxDrawdownDuration().Seconds(),
+		Sharpe:              number(stats.SharpRatio(0)),
+		Sortino:             number(stats.SortinoRatio(0)),
+		Trades:              len(stats.Transactions()),
+		Events:              len(stats.Events()),
+	}
+	r.Return, _ = stats.TotalEquityReturn()
+	if seed, ok := j.test.Seed(); ok {
+		r.Seed = &seed
+	}
+	for name, v := range stats.CustomMetrics() {
+		if number(v) == nil {
+			continue
+		}
+		if r.Metrics == nil {
+			r.Metrics = make(map[string]float64)
+		}
+		r.Metrics[name] = v
+	}
+	return r, nil
+}
+
+// Equity returns the equity curve of a finished job.
+func (s *Server) Equity(id string) ([]EquityPoint, error) {
+	_, stats, err := s.finished(id)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := stats.EquityCurve()
+	points := make([]EquityPoint, len(curve))
+	for i, p := range curve {
+		points[i] = EquityPoint{Time: p.Time, Equity: p.Equity, Return: p.Return, Drawdown: p.Drawdown}
+	}
+	return points, nil
+}
+
+// Trades returns the trades of a finished job.
+func (s *Server) Trades(id string) ([]Trade, error) {
+	_, stats, err := s.finished(id)
+	if err != nil {
+		return nil, err
+	}
+
+	blotter := stats.Blotter()
+	trades := make([]Trade, len(blotter))
+	for i, e := range blotter {
+		trades[i] = Trade{
+			Time:      e.Time,
+			Symbol:    e.Symbol,
+			Direction: e.Direction.String(),
+			Qty:       e.Qty,
+			Price:     e.Price,
+			Cost:      e.Cost,
+			FillID:    e.FillID,
+			OrderID:   e.OrderID,
+			SignalID:  e.SignalID,
+		}
+	}
+	return trades, nil
+}
+
+// Events returns the recorded event stream of a finished job.
+func (s *Server) Events(id string) ([]gbt.EventRecord, error) {
+	_, stats, err := s.finished(id)
+	if err != nil {
+		return nil, err
+	}
+	return gbt.NewEventRecords(stats.Events()), nil
+}
+
+// job is a submitted backtest.
+type job struct {
+	id        string
+	name      string
+	status    Status
+	progress  gbt.Progress
+	err       error
+	submitted time.Time
+	started   time.Time
+	test      *gbt.Backtest
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// changed is closed and replaced on every update of the job
+	changed chan struct{}
+}
+
+// job returns a job by id.
+func (s *Server) job(id string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return j, nil
+}
+
+// resolveData restricts the data directory of a config to the data directory of the server.
+func (s *Server) resolveData(c *config.Config) error {
+	if s.DataDir == "" {
+		return nil
+	}
+
+	dir := filepath.Clean(filepath.FromSlash(c.Data.Dir))
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("data directory %q outside of the data directory of the server", c.Data.Dir)
+	}
+	c.Data.Dir = filepath.Join(s.DataDir, dir) + string(filepath.Separator)
+	return nil
+}
+
+// add registers a new job.
+func (s *Server) add(name string, test *gbt.Backtest) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// check for nil map, else initialise the map
+	if s.jobs == nil {
+		s.jobs = make(map[string]*job)
+	}
+	if s.slots == nil {
+		workers := s.Workers
+		if workers <= 0 {
+			workers = runtime.NumCPU()
+		}
+		s.slots = make(chan struct{}, workers)
+	}
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+
+	s.nextID++
+	j := &job{
+		id:        strconv.Itoa(s.nextID),
+		name:      name,
+		status:    StatusQueued,
+		submitted: time.Now(),
+		test:      test,
+		changed:   make(chan struct{}),
+	}
+	j.ctx, j.cancel = context.WithCancel(s.ctx)
+	s.jobs[j.id] = j
+	s.order = append(s.order, j.id)
+	return j
+}
+
+// run waits for a free slot and runs the job.
+func (s *Server) run(j *job) {
+	defer j.cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-j.ctx.Done():
+		s.finish(j, j.ctx.Err())
+		return
+	}
+
+	s.update(j, func() {
+		j.status = StatusRunning
+		j.started = time.Now()
+	})
+
+	every := s.ProgressEvery
+	if every <= 0 {
+		every = 100
+	}
+	var exporter gbt.ProgressReporter
+	if s.Metrics != nil {
+		exporter = s.Metrics.Reporter(j.id)
+	}
+	j.test.SetProgress(gbt.ProgressFunc(func(p gbt.Progress) {
+		s.update(j, func() { j.progress = p })
+		if exporter != nil {
+			exporter.OnProgress(p)
+		}
+	}), every)
+
+	s.finish(j, j.test.RunContext(j.ctx))
+}
+
+// finish sets the final status of a job.
+func (s *Server) finish(j *job, err error) {
+	s.update(j, func() {
+		switch {
+		case err == context.Canceled:
+			j.status = StatusCancelled
+		case err != nil:
+			j.status = StatusFailed
+		default:
+			j.status = StatusDone
+		}
+		j.err = err
+	})
+
+	if s.Metrics != nil {
+		s.Metrics.Remove(j.id)
+		if !j.started.IsZero() {
+			s.Metrics.ObserveJob(string(j.status), time.Since(j.started))
+		}
+	}
+}
+
+// update changes a job and wakes up its watchers.
+func (s *Server) update(j *job, change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// status returns the status of a job.
+func (s *Server) status(j *job) JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := JobStatus{
+		ID:        j.id,
+		Name:      j.name,
+		Status:    j.status,
+		Submitted: j.submitted,
+		Progress: Progress{
+			Processed: j.progress.Processed,
+			Total:     j.progress.Total,
+			Percent:   j.progress.Percent,
+			Time:      j.progress.Time,
+			Elapsed:   j.progress.Elapsed.Seconds(),
+			Remaining: j.progress.Remaining.Seconds(),
+		},
+	}
+	if r := j.progress.Stats; r.Points > 0 {
+		status.Progress.Stats = &RunningStats{
+			Equity:      r.Equity,
+			Return:      r.Return,
+			Drawdown:    r.Drawdown,
+			MaxDrawdown: r.MaxDrawdown,
+			Sharpe:      number(r.Sharpe),
+			Trades:      r.Trades,
+		}
+	}
+	if j.err != nil {
+		status.Error = j.err.Error()
+	}
+	return status
+}
+
+// finished returns a finished job and its statistics.
+func (s *Server) finished(id string) (*job, *gbt.Statistic, error) {
+	j, err := s.job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j.status != StatusDone {
+		return nil, nil, fmt.Errorf("%w: job %s is %s", ErrNotFinished, j.id, j.status)
+	}
+	stats, ok := j.test.Stats().(*gbt.Statistic)
+	if !ok {
+		return nil, nil, fmt.Errorf("job %s has no statistic", j.id)
+	}
+	return j, stats, nil
+}
+
+// finished returns if the job is no longer queued or running.
+func (s JobStatus) finished() bool {
+	return s.Status != StatusQueued && s.Status != StatusRunning
+}
+
+// number returns nil for values which can not be encoded as json.
+func number(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"testing"
+
+	"github.com/dirkolbrich/gobacktest/config"
+)
+
+// submitTest parses and submits the test config to the server.
+func submitTest(t *testing.T, s *Server) JobStatus {
+	c, err := config.Parse([]byte(testConfig), ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := s.Submit(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestServiceWatch(t *testing.T) {
+	s := New()
+	s.DataDir = "../examples/testdata"
+	s.ProgressEvery = 10
+	defer s.Close()
+
+	status := submitTest(t, s)
+
+	var updates []JobStatus
+	err := s.Watch(context.Background(), status.ID, func(status JobStatus) error {
+		updates = append(updates, status)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch: unexpected error %v", err)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("Watch: expected progress updates, actual %v", len(updates))
+	}
+	last := updates[len(updates)-1]
+	if last.Status != StatusDone || last.Progress.Percent != 100 {
+		t.Errorf("Watch: expected the final status done, actual %+v", last)
+	}
+
+	events, err := s.Events(status.ID)
+	if err != nil {
+		t.Fatalf("Events: unexpected error %v", err)
+	}
+	if len(events) == 0 {
+		t.Errorf("Events: expected recorded events")
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	s := New()
+	s.DataDir = "../examples/testdata"
+	defer s.Close()
+
+	// a job which never gets a slot stays queued
+	s.Workers = 1
+	blocked := s.add("blocker", nil)
+	s.slots <- struct{}{}
+
+	stop := errors.New("stop")
+
+	// testCases is a table for testing the errors of the service
+	var testCases = []struct {
+		msg  string
+		call func() error
+		err  error
+	}{
+		{"Job of an unknown job:",
+			func() error { _, err := s.Job("99"); return err },
+			ErrNotFound,
+		},
+		{"Cancel of an unknown job:",
+			func() error { _, err := s.Cancel("99"); return err },
+			ErrNotFound,
+		},
+		{"Result of a queued job:",
+			func() error { _, err := s.Result(blocked.id); return err },
+			ErrNotFinished,
+		},
+		{"Trades of a queued job:",
+			func() error { _, err := s.Trades(blocked.id); return err },
+			ErrNotFinished,
+		},
+		{"Submit of an unknown strategy:",
+			func() error {
+				_, err := s.Submit(config.Config{Strategy: config.StrategyConfig{Name: "unknown"}})
+				return err
+			},
+			ErrInvalidConfig,
+		},
+		{"Watch stopped by the callback:",
+			func() error {
+				return s.Watch(context.Background(), blocked.id, func(JobStatus) error { return stop })
+			},
+			stop,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.call()
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%v\nexpected error %v, actual %v", tc.msg, tc.err, err)
+		}
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	s, ts := newTestServer()
+	defer ts.Close()
+	defer s.Close()
+
+	status := submitTest(t, s)
+
+	resp, err := http.Get(ts.URL + "/jobs/" + status.ID + "/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /watch: expected status 200, actual %v", resp.StatusCode)
+	}
+
+	var last JobStatus
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			t.Fatalf("GET /watch: could not decode line %q: %v", scanner.Text(), err)
+		}
+	}
+	if last.Status != StatusDone {
+		t.Errorf("GET /watch: expected the last line to be done, actual %+v", last)
+	}
+
+	if code := decode(t, http.MethodGet, ts.URL+"/jobs/99/watch", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET /jobs/99/watch: expected status 404, actual %v", code)
+	}
+
+	var events []json.RawMessage
+	if code := decode(t, http.MethodGet, ts.URL+"/jobs/"+status.ID+"/events", "", "", &events); code != http.StatusOK || len(events) == 0 {
+		t.Errorf("GET /events: expected recorded events, actual status %v with %v events", code, len(events))
+	}
+}
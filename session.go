@@ -0,0 +1,126 @@
+package gobacktest
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradingSession restricts the trading of an intraday strategy to a time of day, e.g. only
+// from 09:45 to 15:30 and flat by 15:55. Start, End and FlatAt are offsets since midnight in
+// Location, UTC if nil. A zero Start opens the session at midnight, a zero End keeps it open
+// until the end of the day.
+//
+// As a risk rule of the portfolio, orders opening or increasing a position are rejected
+// outside of the session. With FlatAt set, a trading halt closes all positions once a day,
+// so no position is carried overnight. The halt is returned on the last data event of a day
+// with a session, known in a backtest from the time of the next data event, and the positions
+// are filled at the close of the day. Without a known next data event, e.g. on live data, the
+// halt is returned on the first data event at or after FlatAt, or on the first data event of a
+// later day, if the session of the day before was not flattened. Data without an event within
+// the session, e.g. daily bars at midnight, is never flattened.
+//
+// As a schedule of a strategy, it fires only on data events within the session.
+type TradingSession struct {
+	Start    time.Duration
+	End      time.Duration
+	FlatAt   time.Duration
+	Location *time.Location
+	flatDay  time.Time // last day which is flattened
+	openDay  time.Time // last day with a data event within the session
+	halted   bool
+}
+
+// Update checks the time of the data event against the session without a known next data event.
+func (r *TradingSession) Update(t time.Time, equity float64) *TradingHalted {
+	return r.UpdateClock(Clock{Now: t}, equity)
+}
+
+// UpdateClock implements ClockRule. It checks the time of the clock against the session and
+// returns a flattening trading halt on the last data event of a day with a session, on the
+// first data event at or after FlatAt or on a later day, once for each day.
+func (r *TradingSession) UpdateClock(c Clock, equity float64) *TradingHalted {
+	local := r.local(c.Now)
+	tod := timeOfDay(local)
+	r.halted = !r.open(tod)
+
+	if r.FlatAt <= 0 {
+		return nil
+	}
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	// the session of an earlier day ended without a flattening data event
+	flatten := r.openDay.After(r.flatDay) && day.After(r.openDay)
+	if flatten {
+		r.flatDay = r.openDay
+	}
+
+	if !r.halted {
+		r.openDay = day
+	}
+	if day.After(r.flatDay) {
+		last := r.openDay.Equal(day) && !c.Next.IsZero() && newDay(local, r.local(c.Next))
+		if last || tod >= r.FlatAt {
+			// no new position is opened after the flattening
+			r.halted = true
+			r.flatDay = day
+			flatten = true
+		}
+	}
+	if !flatten {
+		return nil
+	}
+
+	return &TradingHalted{
+		Event:   Event{timestamp: c.Now},
+		reason:  fmt.Sprintf("trading session flat at %s", clockString(r.FlatAt)),
+		flatten: true,
+	}
+}
+
+// Halted returns if the time of the last data event is outside of the session.
+func (r *TradingSession) Halted() bool {
+	return r.halted
+}
+
+// Fire implements Schedule and checks if the time of the clock is within the session.
+func (r *TradingSession) Fire(c Clock) bool {
+	return r.open(timeOfDay(r.local(c.Now)))
+}
+
+// Reset implements Reseter to reset the session.
+func (r *TradingSession) Reset() error {
+	r.flatDay = time.Time{}
+	r.openDay = time.Time{}
+	r.halted = false
+	return nil
+}
+
+// open checks if the time of day is within the session and before FlatAt.
+func (r *TradingSession) open(tod time.Duration) bool {
+	if tod < r.Start {
+		return false
+	}
+	if r.End > 0 && tod >= r.End {
+		return false
+	}
+	return r.FlatAt <= 0 || tod < r.FlatAt
+}
+
+// local returns the time in the location of the session.
+func (r *TradingSession) local(t time.Time) time.Time {
+	if r.Location == nil {
+		return t.UTC()
+	}
+	return t.In(r.Location)
+}
+
+// timeOfDay returns the duration since midnight of the time.
+func timeOfDay(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// clockString formats a duration since midnight as 15:04.
+func clockString(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -0,0 +1,199 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTradingSessionUpdate(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d int, h, m int) time.Time {
+		return day.AddDate(0, 0, d).Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	session := func() *TradingSession {
+		return &TradingSession{Start: 9*time.Hour + 45*time.Minute, End: 15*time.Hour + 30*time.Minute, FlatAt: 15*time.Hour + 55*time.Minute}
+	}
+
+	// testCases is a table for testing the trading session
+	var testCases = []struct {
+		msg       string
+		rule      *TradingSession
+		times     []time.Time
+		expHalted bool
+		expEvents int
+	}{
+		{"before the start:", session(), []time.Time{at(0, 9, 30)}, true, 0},
+		{"start is included:", session(), []time.Time{at(0, 9, 30), at(0, 9, 45)}, false, 0},
+		{"end is excluded:", session(), []time.Time{at(0, 15, 29), at(0, 15, 30)}, true, 0},
+		{"flat once a day:", session(), []time.Time{at(0, 15, 55), at(0, 16, 0), at(0, 16, 5)}, true, 1},
+		{"flat on each day:", session(), []time.Time{at(0, 16, 0), at(1, 10, 0), at(1, 16, 0)}, true, 2},
+		{"open on the next day:", session(), []time.Time{at(0, 16, 0), at(1, 10, 0)}, false, 1},
+		{"daily bars are never flat:", session(), []time.Time{at(0, 0, 0), at(1, 0, 0)}, true, 0},
+		{"session without event after the flat time:", session(), []time.Time{at(0, 10, 0), at(0, 15, 0), at(1, 10, 0)}, false, 1},
+		{"flat on a later day:", session(), []time.Time{at(0, 10, 0), at(3, 9, 0)}, true, 1},
+		{"missed and flat on the next day at once:", session(), []time.Time{at(0, 10, 0), at(1, 16, 0)}, true, 1},
+		{"without flat time:",
+			&TradingSession{Start: 10 * time.Hour, End: 16 * time.Hour},
+			[]time.Time{at(0, 10, 0), at(0, 17, 0), at(0, 23, 59)}, true, 0},
+		{"flat time before the end:",
+			&TradingSession{Start: 10 * time.Hour, End: 16 * time.Hour, FlatAt: 15 * time.Hour},
+			[]time.Time{at(0, 15, 0)}, true, 1},
+		{"session in a location:",
+			&TradingSession{Start: 9*time.Hour + 30*time.Minute, End: 16 * time.Hour, Location: time.FixedZone("EDT", -4*3600)},
+			[]time.Time{at(0, 13, 30)}, false, 0},
+	}
+
+	for _, tc := range testCases {
+		var events int
+		for _, ts := range tc.times {
+			if h := tc.rule.Update(ts, 1000); h != nil && h.Flatten() {
+				events++
+			}
+		}
+		if tc.rule.Halted() != tc.expHalted || events != tc.expEvents {
+			t.Errorf("%v Update(): \nexpected halted %v with %v flattening events, \nactual   %v with %v",
+				tc.msg, tc.expHalted, tc.expEvents, tc.rule.Halted(), events)
+		}
+	}
+}
+
+func TestTradingSessionUpdateClock(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d int, h, m int) time.Time {
+		return day.AddDate(0, 0, d).Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+
+	// testCases is a table for testing the flattening with the time of the next data event
+	var testCases = []struct {
+		msg     string
+		session *TradingSession
+		clocks  []Clock
+		expFlat []bool
+	}{
+		{"last event of the day:",
+			&TradingSession{Start: 10 * time.Hour, End: 16 * time.Hour, FlatAt: 16 * time.Hour},
+			[]Clock{{Now: at(0, 10, 0), Next: at(0, 15, 0)}, {Now: at(0, 15, 0), Next: at(1, 10, 0)}, {Now: at(1, 10, 0), Next: at(1, 11, 0)}},
+			[]bool{false, true, false},
+		},
+		{"last event after the end of the session:",
+			&TradingSession{Start: 10 * time.Hour, End: 15 * time.Hour, FlatAt: 16 * time.Hour},
+			[]Clock{{Now: at(0, 14, 0), Next: at(0, 15, 30)}, {Now: at(0, 15, 30), Next: at(1, 10, 0)}},
+			[]bool{false, true},
+		},
+		{"flat time before the last event:",
+			&TradingSession{Start: 10 * time.Hour, End: 16 * time.Hour, FlatAt: 15 * time.Hour},
+			[]Clock{{Now: at(0, 15, 0), Next: at(0, 15, 30)}, {Now: at(0, 15, 30), Next: at(1, 10, 0)}},
+			[]bool{true, false},
+		},
+		{"last event of the data:",
+			&TradingSession{Start: 10 * time.Hour, End: 16 * time.Hour, FlatAt: 16 * time.Hour},
+			[]Clock{{Now: at(0, 10, 0), Next: at(0, 11, 0)}, {Now: at(0, 11, 0)}},
+			[]bool{false, false},
+		},
+		{"day in the location of the session:",
+			&TradingSession{Start: 9 * time.Hour, End: 16 * time.Hour, FlatAt: 16 * time.Hour, Location: time.FixedZone("EDT", -4*3600)},
+			[]Clock{{Now: at(0, 19, 0), Next: at(1, 1, 0)}},
+			[]bool{false},
+		},
+	}
+
+	for _, tc := range testCases {
+		for i, c := range tc.clocks {
+			h := tc.session.UpdateClock(c, 1000)
+			if flat := h != nil && h.Flatten(); flat != tc.expFlat[i] {
+				t.Errorf("%v UpdateClock() %v: expected flattening %v, actual %v", tc.msg, i, tc.expFlat[i], flat)
+			}
+		}
+	}
+}
+
+func TestBacktestTradingSession(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	bar := func(d, h int, close float64) DataEvent {
+		return &Bar{Event: Event{timestamp: day.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour), symbol: "TEST.DE"}, Close: close}
+	}
+
+	// the data of each day ends before the flat time
+	data := &Data{}
+	data.SetStream([]DataEvent{bar(0, 10, 100), bar(0, 14, 102), bar(1, 10, 90), bar(1, 14, 91)})
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&buyOnceStrategy{Strategy: NewStrategy("buy once")})
+	test.portfolio.(*Portfolio).SetRiskRules(&TradingSession{Start: 9 * time.Hour, End: 15 * time.Hour, FlatAt: 15 * time.Hour})
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// the position is closed at the close of the first day, not at the open of the next day
+	var fills []FillEvent
+	for _, e := range test.Stats().Events() {
+		if f, ok := e.(FillEvent); ok {
+			fills = append(fills, f)
+		}
+	}
+	if len(fills) != 2 || fills[1].Direction() != SLD || fills[1].Price() != 102 || !fills[1].Time().Equal(day.Add(14*time.Hour)) {
+		t.Fatalf("Run(): expected the flattening fill at the close of the first day, actual %+v", fills)
+	}
+	if pos, ok := test.portfolio.IsInvested("TEST.DE"); ok {
+		t.Errorf("Run(): expected no position carried overnight, actual %+v", pos)
+	}
+}
+
+func TestTradingSessionFire(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := &TradingSession{Start: 9*time.Hour + 45*time.Minute, End: 15*time.Hour + 30*time.Minute}
+
+	// testCases is a table for testing the session as a schedule
+	var testCases = []struct {
+		msg     string
+		now     time.Time
+		expFire bool
+	}{
+		{"before the session:", day.Add(9 * time.Hour), false},
+		{"within the session:", day.Add(12 * time.Hour), true},
+		{"after the session:", day.Add(15*time.Hour + 30*time.Minute), false},
+	}
+
+	for _, tc := range testCases {
+		if fire := s.Fire(Clock{Now: tc.now}); fire != tc.expFire {
+			t.Errorf("%v Fire(): expected %v, actual %v", tc.msg, tc.expFire, fire)
+		}
+	}
+}
+
+func TestPortfolioCheckRulesSession(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	p := &Portfolio{
+		cash: 500,
+		holdings: map[string]Position{
+			"A.DE": {symbol: "A.DE", qty: -10, marketValue: 500},
+		},
+	}
+	session := &TradingSession{Start: 9 * time.Hour, End: 17 * time.Hour, FlatAt: 17*time.Hour + 25*time.Minute}
+	p.SetRiskRules(session)
+
+	if halt, _ := p.CheckRules(&Bar{Event: Event{timestamp: day.Add(17 * time.Hour), symbol: "A.DE"}}); halt != nil || !p.Halted() {
+		t.Errorf("CheckRules(): expected a halt without event after the session, actual %#v", halt)
+	}
+	if rejection := p.checkHalt(&Order{Event: Event{symbol: "B.DE"}, direction: BOT, qty: 1}); rejection == nil {
+		t.Errorf("checkHalt(): expected a rejection of a new position after the session")
+	}
+
+	halt, orders := p.CheckRules(&Bar{Event: Event{timestamp: day.Add(17*time.Hour + 30*time.Minute), symbol: "A.DE"}})
+	if halt == nil || !halt.Flatten() || len(orders) != 1 || orders[0].Direction() != BOT || orders[0].Qty() != 10 {
+		t.Fatalf("CheckRules(): expected flattening halt, actual %#v %#v", halt, orders)
+	}
+	if halt.Reason() != "trading session flat at 17:25" {
+		t.Errorf("Reason(): unexpected reason %q", halt.Reason())
+	}
+
+	if err := session.Reset(); err != nil || session.Halted() {
+		t.Errorf("Reset(): expected the session to be reset, actual halted %v, error %v", session.Halted(), err)
+	}
+}
+
+// check the interfaces of the trading session
+var _ RiskRule = &TradingSession{}
+var _ Schedule = &TradingSession{}
@@ -1,5 +1,7 @@
 package gobacktest
 
+import "strconv"
+
 // Direction defines which direction a signal indicates
 type Direction int
 
@@ -15,10 +17,30 @@ const (
 	EXT
 )
 
+// String returns the name of a Direction, e.g. buy for BOT.
+func (d Direction) String() string {
+	switch d {
+	case BOT:
+		return "buy"
+	case SLD:
+		return "sell"
+	case HLD:
+		return "hold"
+	case EXT:
+		return "exit"
+	}
+	return strconv.Itoa(int(d))
+}
+
 // Signal declares a basic signal event
 type Signal struct {
 	Event
-	direction Direction // long, short, exit or hold
+	direction   Direction // long, short, exit or hold
+	stopLoss    float64   // protective stop price of the trade
+	strength    float64   // conviction from -1.0 to 1.0
+	hasStrength bool      // the strength is set, an unset strength is full size
+	confidence  float64   // confidence from 0.0 to 1.0, 0 is full confidence
+	side        Side      // side of the hedge closed by an exit in hedging mode
 }
 
 // Direction returns the Direction of a Signal
@@ -30,3 +52,49 @@ func (s Signal) Direction() Direction {
 func (s *Signal) SetDirection(dir Direction) {
 	s.direction = dir
 }
+
+// Side returns the side of the hedge closed by an exit Signal in hedging mode.
+func (s Signal) Side() Side {
+	return s.side
+}
+
+// SetSide sets the side of the hedge closed by an exit Signal, both sides by default.
+func (s *Signal) SetSide(side Side) {
+	s.side = side
+}
+
+// StopLoss returns the protective stop price of a Signal.
+func (s Signal) StopLoss() float64 {
+	return s.stopLoss
+}
+
+// SetStopLoss sets the protective stop price of a Signal.
+func (s *Signal) SetStopLoss(price float64) {
+	s.stopLoss = price
+}
+
+// Strength returns the strength of a Signal from -1.0 to 1.0.
+func (s Signal) Strength() float64 {
+	return s.strength
+}
+
+// SetStrength sets the strength of a Signal from -1.0 to 1.0.
+func (s *Signal) SetStrength(strength float64) {
+	s.strength = strength
+	s.hasStrength = true
+}
+
+// HasStrength returns if the strength of a Signal is set.
+func (s Signal) HasStrength() bool {
+	return s.hasStrength
+}
+
+// Confidence returns the confidence of a Signal from 0.0 to 1.0.
+func (s Signal) Confidence() float64 {
+	return s.confidence
+}
+
+// SetConfidence sets the confidence of a Signal from 0.0 to 1.0.
+func (s *Signal) SetConfidence(confidence float64) {
+	s.confidence = confidence
+}
@@ -48,3 +48,24 @@ func TestSignalGetDirection(t *testing.T) {
 		}
 	}
 }
+
+func TestDirectionString(t *testing.T) {
+	// testCases is a table for testing the names of the directions
+	var testCases = []struct {
+		msg string
+		dir Direction
+		exp string
+	}{
+		{"buy:", BOT, "buy"},
+		{"sell:", SLD, "sell"},
+		{"hold:", HLD, "hold"},
+		{"exit:", EXT, "exit"},
+		{"unknown direction:", Direction(7), "7"},
+	}
+
+	for _, tc := range testCases {
+		if s := tc.dir.String(); s != tc.exp {
+			t.Errorf("%v String(): expected %v, actual %v", tc.msg, tc.exp, s)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package gobacktest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Significance is the result of a significance test, the observed value of the test statistic
+// and the probability to observe at least this value by chance.
+type Significance struct {
+	Observed float64
+	PValue   float64
+}
+
+// SignificanceTest estimates the probability that the result of a backtest arose by chance.
+// The p-value is the share of the random samples with a test statistic at least as high as
+// the observed one, with the observed sample counted as one of them.
+type SignificanceTest struct {
+	Iterations int // random samples, defaults to 1000
+	Seed       int64
+}
+
+// iterations returns the number of random samples.
+func (t *SignificanceTest) iterations() int {
+	if t.Iterations <= 0 {
+		return 1000
+	}
+	return t.Iterations
+}
+
+// Bootstrap tests the sharpe ratio per period of the returns against the null hypothesis of a
+// zero mean return. The returns are centred on zero and resampled with replacement.
+func (t *SignificanceTest) Bootstrap(returns []float64) (Significance, error) {
+	if len(returns) < 2 {
+		return Significance{}, errors.New("could not run bootstrap test, not enough returns")
+	}
+
+	observed := SharpeOfReturns(returns)
+	mean := stat.Mean(returns, nil)
+	centred := make([]float64, len(returns))
+	for i, r := range returns {
+		centred[i] = r - mean
+	}
+
+	rng := rand.New(rand.NewSource(t.Seed))
+	sample := make([]float64, len(returns))
+	exceed := 1
+	for i := 0; i < t.iterations(); i++ {
+		for j := range sample {
+			sample[j] = centred[rng.Intn(len(centred))]
+		}
+		if SharpeOfReturns(sample) >= observed {
+			exceed++
+		}
+	}
+
+	return Significance{Observed: observed, PValue: float64(exceed) / float64(t.iterations()+1)}, nil
+}
+
+// Permutation tests the timing of the positions against the null hypothesis, that the entry
+// dates have no skill. Each position, e.g. the net exposure held into a period, earns the
+// market return of the period, the positions are shuffled across the periods. The observed
+// value is the sum of the returns of the positions.
+func (t *SignificanceTest) Permutation(positions, market []float64) (Significance, error) {
+	if len(positions) != len(market) {
+		return Significance{}, errors.New("could not run permutation test, positions and market returns of unequal length")
+	}
+	if len(positions) < 2 {
+		return Significance{}, errors.New("could not run permutation test, not enough returns")
+	}
+
+	observed := timedReturn(positions, market)
+
+	rng := rand.New(rand.NewSource(t.Seed))
+	shuffled := make([]float64, len(positions))
+	copy(shuffled, positions)
+	exceed := 1
+	for i := 0; i < t.iterations(); i++ {
+		rng.Shuffle(len(shuffled), func(a, b int) {
+			shuffled[a], shuffled[b] = shuffled[b], shuffled[a]
+		})
+		if timedReturn(shuffled, market) >= observed {
+			exceed++
+		}
+	}
+
+	return Significance{Observed: observed, PValue: float64(exceed) / float64(t.iterations()+1)}, nil
+}
+
+// timedReturn returns the sum of the market returns weighted with the positions.
+func timedReturn(positions, market []float64) float64 {
+	var sum float64
+	for i, p := range positions {
+		sum += p * market[i]
+	}
+	return sum
+}
+
+// SharpeSignificance tests the sharpe ratio of the daily returns of the backtest with a
+// bootstrap against a zero mean return.
+func (s Statistic) SharpeSignificance(test *SignificanceTest) (Significance, error) {
+	return test.Bootstrap(s.DailyReturns())
+}
+
+// TimingSignificance tests the entry dates of the backtest against randomly shuffled dates.
+// The net exposure at the end of each day earns the market return of the next day of the
+// market, e.g. the benchmark returns of the traded symbol.
+func (s Statistic) TimingSignificance(test *SignificanceTest, market []DailyReturn) (Significance, error) {
+	exposure := s.Exposure()
+
+	var positions, returns []float64
+	var held float64
+	var last time.Time
+	i := 0
+	for _, r := range market {
+		// the exposure of the last day before the market return is held
+		for i < len(exposure) && exposure[i].Time.Before(r.Date) {
+			held = exposure[i].Net()
+			last = exposure[i].Time
+			i++
+		}
+		if last.IsZero() {
+			continue
+		}
+		positions = append(positions, held)
+		returns = append(returns, r.Return)
+	}
+
+	return test.Permutation(positions, returns)
+}
+
+// printSignificance prints the p-value of the sharpe ratio.
+func (s Statistic) printSignificance() {
+	significance, err := s.SharpeSignificance(&SignificanceTest{})
+	if err != nil {
+		return
+	}
+	fmt.Printf("Sharpe ratio per day: %.4f, p-value: %.4f\n", significance.Observed, significance.PValue)
+}
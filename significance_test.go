@@ -0,0 +1,112 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// alternating returns n returns alternating between a and b.
+func alternating(n int, a, b float64) []float64 {
+	returns := make([]float64, n)
+	for i := range returns {
+		returns[i] = a
+		if i%2 == 1 {
+			returns[i] = b
+		}
+	}
+	return returns
+}
+
+func TestSignificanceTestBootstrap(t *testing.T) {
+	// testCases is a table for testing the bootstrap of the sharpe ratio
+	var testCases = []struct {
+		msg      string
+		returns  []float64
+		expAbove float64
+		expBelow float64
+		expErr   bool
+	}{
+		{"positive returns:", alternating(100, 0.01, 0.02), 0, 0.01, false},
+		{"zero mean returns:", alternating(100, 0.01, -0.01), 0.2, 1, false},
+		{"not enough returns:", []float64{0.01}, 0, 0, true},
+	}
+
+	test := &SignificanceTest{Iterations: 500, Seed: 1}
+	for _, tc := range testCases {
+		significance, err := test.Bootstrap(tc.returns)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Bootstrap(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if tc.expErr {
+			continue
+		}
+		if significance.PValue <= tc.expAbove || significance.PValue > tc.expBelow {
+			t.Errorf("%v Bootstrap(): expected a p-value in (%v, %v], actual %+v", tc.msg, tc.expAbove, tc.expBelow, significance)
+		}
+	}
+
+	// the same seed returns the same p-value
+	returns := alternating(50, 0.01, -0.008)
+	a, _ := test.Bootstrap(returns)
+	b, _ := test.Bootstrap(returns)
+	if a != b {
+		t.Errorf("Bootstrap(): expected the same result of the same seed, actual %+v and %+v", a, b)
+	}
+}
+
+func TestSignificanceTestPermutation(t *testing.T) {
+	market := alternating(100, 0.01, -0.01)
+
+	// testCases is a table for testing the permutation of the entry dates
+	var testCases = []struct {
+		msg       string
+		positions []float64
+		market    []float64
+		expP      float64
+		expErr    bool
+	}{
+		{"perfect timing:", alternating(100, 1, 0), market, 1.0 / 1001, false},
+		{"constant positions:", alternating(100, 1, 1), market, 1, false},
+		{"unequal length:", []float64{1}, market, 0, true},
+		{"not enough returns:", []float64{1}, []float64{0.01}, 0, true},
+	}
+
+	test := &SignificanceTest{Seed: 1}
+	for _, tc := range testCases {
+		significance, err := test.Permutation(tc.positions, tc.market)
+		if (err != nil) != tc.expErr {
+			t.Errorf("%v Permutation(): expected error %v, actual %v", tc.msg, tc.expErr, err)
+			continue
+		}
+		if !tc.expErr && math.Abs(significance.PValue-tc.expP) > 1e-9 {
+			t.Errorf("%v Permutation(): expected p-value %v, actual %+v", tc.msg, tc.expP, significance)
+		}
+	}
+}
+
+func TestStatisticTimingSignificance(t *testing.T) {
+	day := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// the strategy is long at the close of the days before a rising market
+	var s Statistic
+	var market []DailyReturn
+	for i := 0; i < 40; i++ {
+		long := 0.0
+		if i%2 == 0 {
+			long = 1000
+		}
+		s.equity = append(s.equity, equityPoint{timestamp: day.AddDate(0, 0, i).Add(16 * time.Hour), long: long, capital: 1000})
+		market = append(market, DailyReturn{Date: day.AddDate(0, 0, i), Return: alternating(40, -0.01, 0.01)[i]})
+	}
+
+	significance, err := s.TimingSignificance(&SignificanceTest{Seed: 1}, market)
+	if err != nil {
+		t.Fatalf("TimingSignificance(): unexpected error %v", err)
+	}
+	// the first market day has no position before, the 20 long days earn 1%
+	if math.Abs(significance.Observed-0.2) > 1e-9 || significance.PValue > 0.01 {
+		t.Errorf("TimingSignificance(): expected a significant return of 0.2, actual %+v", significance)
+	}
+}
@@ -2,7 +2,6 @@ package gobacktest
 
 import (
 	"errors"
-	"math"
 )
 
 // SizeHandler is the basic interface for setting the size of an order
@@ -10,10 +9,12 @@ type SizeHandler interface {
 	SizeOrder(OrderEvent, DataEvent, PortfolioHandler) (*Order, error)
 }
 
-// Size is a basic size handler implementation
+// Size is a basic size handler implementation.
+// The qty is rounded down to the lot size of the instrument, without Instruments to whole units.
 type Size struct {
-	DefaultSize  int64
+	DefaultSize  float64
 	DefaultValue float64
+	Instruments  *InstrumentRegistry
 }
 
 // SizeOrder adjusts the size of an order
@@ -29,11 +30,22 @@ func (s *Size) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler)
 	switch o.Direction() {
 	case BOT:
 		o.SetDirection(BOT)
-		o.SetQty(s.setDefaultSize(data.Price()))
+		o.SetQty(s.setDefaultSize(o.Symbol(), data.Price()))
 	case SLD:
 		o.SetDirection(SLD)
-		o.SetQty(s.setDefaultSize(data.Price()))
+		o.SetQty(s.setDefaultSize(o.Symbol(), data.Price()))
 	case EXT: // all shares should be sold or bought, depending on position
+		// a closing order in hedging mode exits a side of the hedge, not the net position
+		if h, ok := pf.(hedger); ok && o.Closing() {
+			hedge, _ := h.Hedge(o.Symbol())
+			dir, qty, ok := hedge.exit(o.Side())
+			if !ok {
+				return o, errors.New("cannot exit order: no position to side of symbol in portfolio,")
+			}
+			o.SetDirection(dir)
+			o.SetQty(qty)
+			break
+		}
 		// poll postions
 		if _, ok := pf.IsInvested(o.Symbol()); !ok {
 
@@ -52,10 +64,14 @@ func (s *Size) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler)
 	return o, nil
 }
 
-func (s *Size) setDefaultSize(price float64) int64 {
-	if (float64(s.DefaultSize) * price) > s.DefaultValue {
-		correctedQty := int64(math.Floor(s.DefaultValue / price))
+// setDefaultSize returns the default size, limited by the default value of the notional
+// including the contract multiplier.
+func (s *Size) setDefaultSize(symbol string, price float64) float64 {
+	instrument := s.Instruments.Get(symbol)
+	lot := instrument.LotSize
+	if instrument.Notional(s.DefaultSize, price) > s.DefaultValue {
+		correctedQty := lot.Round(s.DefaultValue / instrument.Notional(1, price))
 		return correctedQty
 	}
-	return s.DefaultSize
+	return lot.Round(s.DefaultSize)
 }
@@ -102,7 +102,7 @@ func TestSetDefaultSize(t *testing.T) {
 		msg    string // test message
 		size   Size
 		price  float64
-		expQty int64 // expected error output
+		expQty float64 // expected error output
 	}{
 		{"Empty SizeManager without default values:",
 			Size{},
@@ -119,10 +119,20 @@ func TestSetDefaultSize(t *testing.T) {
 			8,
 			100,
 		},
+		{"fractional qty with lot size:",
+			Size{DefaultSize: 1, DefaultValue: 1000, Instruments: NewInstrumentRegistry(Instrument{Symbol: "TEST.DE", LotSize: LotSize{Step: 0.001}})},
+			15000,
+			0.066,
+		},
+		{"fractional qty below min qty:",
+			Size{DefaultSize: 1, DefaultValue: 1000, Instruments: NewInstrumentRegistry(Instrument{Symbol: "TEST.DE", LotSize: LotSize{Step: 0.001, Min: 0.1}})},
+			15000,
+			0,
+		},
 	}
 
 	for _, tc := range testCases {
-		qty := tc.size.setDefaultSize(tc.price)
+		qty := tc.size.setDefaultSize("TEST.DE", tc.price)
 		if qty != tc.expQty {
 			t.Errorf("%v setDefaultSize(%v): \nexpected %v, \nactual   %v",
 				tc.msg, tc.price, tc.expQty, qty)
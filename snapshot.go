@@ -0,0 +1,617 @@
+package gobacktest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Snapshotter is implemented by components which save their state into a checkpoint and restore
+// it on resume. The portfolio, the exchange and the statistic implement it, a strategy with
+// state, e.g. indicators or counters, implements it to continue where it stopped.
+// The state of a component without it starts fresh on resume.
+type Snapshotter interface {
+	Snapshot() (json.RawMessage, error)
+	Restore(json.RawMessage) error
+}
+
+// snapshot returns the state of a component, nil if it is no Snapshotter.
+func snapshot(component interface{}) (json.RawMessage, error) {
+	s, ok := component.(Snapshotter)
+	if !ok {
+		return nil, nil
+	}
+	return s.Snapshot()
+}
+
+// restore restores the state of a component, if it is a Snapshotter and the state was saved.
+func restore(component interface{}, state json.RawMessage) error {
+	s, ok := component.(Snapshotter)
+	if !ok || len(state) == 0 {
+		return nil
+	}
+	return s.Restore(state)
+}
+
+// eventState is the serializable form of the common fields of an event.
+type eventState struct {
+	Time   time.Time         `json:"time"`
+	Symbol string            `json:"symbol"`
+	ID     int               `json:"id,omitempty"`
+	Parent int               `json:"parent,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+func newEventState(e Event) eventState {
+	return eventState{Time: e.timestamp, Symbol: e.symbol, ID: e.eventID, Parent: e.parentID, Tags: e.tags}
+}
+
+func (s eventState) event() Event {
+	return Event{timestamp: s.Time, symbol: s.Symbol, eventID: s.ID, parentID: s.Parent, tags: s.Tags}
+}
+
+// orderState is the serializable form of an order.
+type orderState struct {
+	Event        eventState  `json:"event"`
+	ID           int         `json:"id"`
+	Type         OrderType   `json:"type"`
+	Status       OrderStatus `json:"status"`
+	Direction    Direction   `json:"direction"`
+	AssetType    string      `json:"assetType,omitempty"`
+	Qty          float64     `json:"qty"`
+	QtyFilled    float64     `json:"qtyFilled"`
+	AvgFillPrice float64     `json:"avgFillPrice"`
+	Limit        float64     `json:"limit,omitempty"`
+	Stop         float64     `json:"stop,omitempty"`
+	DisplayQty   float64     `json:"displayQty,omitempty"`
+	Closing      bool        `json:"closing,omitempty"`
+	StopLoss     float64     `json:"stopLoss,omitempty"`
+	Strength     float64     `json:"strength,omitempty"`
+	HasStrength  bool        `json:"hasStrength,omitempty"`
+	Confidence   float64     `json:"confidence,omitempty"`
+}
+
+func newOrderState(o *Order) orderState {
+	return orderState{
+		Event: newEventState(o.Event), ID: o.id, Type: o.orderType, Status: o.status, Direction: o.direction,
+		AssetType: o.assetType, Qty: o.qty, QtyFilled: o.qtyFilled, AvgFillPrice: o.avgFillPrice,
+		Limit: o.limitPrice, Stop: o.stopPrice, DisplayQty: o.displayQty, Closing: o.closing,
+		StopLoss: o.stopLoss, Strength: o.strength, HasStrength: o.hasStrength, Confidence: o.confidence,
+	}
+}
+
+func (s orderState) order() *Order {
+	return &Order{
+		Event: s.Event.event(), id: s.ID, orderType: s.Type, status: s.Status, direction: s.Direction,
+		assetType: s.AssetType, qty: s.Qty, qtyFilled: s.QtyFilled, avgFillPrice: s.AvgFillPrice,
+		limitPrice: s.Limit, stopPrice: s.Stop, displayQty: s.DisplayQty, closing: s.Closing,
+		stopLoss: s.StopLoss, strength: s.Strength, hasStrength: s.HasStrength, confidence: s.Confidence,
+	}
+}
+
+func newOrderStates(orders []OrderEvent) ([]orderState, error) {
+	states := make([]orderState, len(orders))
+	for i, order := range orders {
+		o, ok := order.(*Order)
+		if !ok {
+			return nil, fmt.Errorf("could not snapshot order of type %T", order)
+		}
+		states[i] = newOrderState(o)
+	}
+	return states, nil
+}
+
+func restoreOrders(states []orderState) []OrderEvent {
+	var orders []OrderEvent
+	for _, s := range states {
+		orders = append(orders, s.order())
+	}
+	return orders
+}
+
+// newPendingStates returns the states of the pending orders by symbol.
+func newPendingStates(orders map[string]*Order) map[string]orderState {
+	if len(orders) == 0 {
+		return nil
+	}
+	states := make(map[string]orderState, len(orders))
+	for symbol, order := range orders {
+		states[symbol] = newOrderState(order)
+	}
+	return states
+}
+
+func restorePending(states map[string]orderState) map[string]*Order {
+	if len(states) == 0 {
+		return nil
+	}
+	orders := make(map[string]*Order, len(states))
+	for symbol, s := range states {
+		orders[symbol] = s.order()
+	}
+	return orders
+}
+
+// reservationState is the serializable form of a reservation. The restored order is a copy,
+// its reservation is released by the booked fills of the order.
+type reservationState struct {
+	Order    orderState `json:"order"`
+	Value    float64    `json:"value"`
+	Booked   float64    `json:"booked,omitempty"`
+	Children []int      `json:"children,omitempty"`
+}
+
+// fillState is the serializable form of a fill.
+type fillState struct {
+	Event       eventState `json:"event"`
+	Direction   Direction  `json:"direction"`
+	Exchange    string     `json:"exchange,omitempty"`
+	Qty         float64    `json:"qty"`
+	Price       float64    `json:"price"`
+	Commission  float64    `json:"commission"`
+	ExchangeFee float64    `json:"exchangeFee"`
+	Cost        float64    `json:"cost"`
+	Impact      float64    `json:"impact,omitempty"`
+	Closing     bool       `json:"closing,omitempty"`
+	Multiplier  float64    `json:"multiplier,omitempty"`
+}
+
+func newFillStates(fills []FillEvent) ([]fillState, error) {
+	states := make([]fillState, len(fills))
+	for i, fill := range fills {
+		f, ok := fill.(*Fill)
+		if !ok {
+			return nil, fmt.Errorf("could not snapshot fill of type %T", fill)
+		}
+		states[i] = fillState{
+			Event: newEventState(f.Event), Direction: f.direction, Exchange: f.Exchange, Qty: f.qty, Price: f.price,
+			Commission: f.commission, ExchangeFee: f.exchangeFee, Cost: f.cost, Impact: f.impact, Closing: f.closing,
+			Multiplier: f.multiplier,
+		}
+	}
+	return states, nil
+}
+
+func restoreFills(states []fillState) []FillEvent {
+	var fills []FillEvent
+	for _, s := range states {
+		fills = append(fills, &Fill{
+			Event: s.Event.event(), direction: s.Direction, Exchange: s.Exchange, qty: s.Qty, price: s.Price,
+			commission: s.Commission, exchangeFee: s.ExchangeFee, cost: s.Cost, impact: s.Impact, closing: s.Closing,
+			multiplier: s.Multiplier,
+		})
+	}
+	return fills
+}
+
+// positionState is the serializable form of a position.
+type positionState struct {
+	Time             time.Time `json:"time"`
+	Symbol           string    `json:"symbol"`
+	Qty              float64   `json:"qty"`
+	QtyBOT           float64   `json:"qtyBOT"`
+	QtySLD           float64   `json:"qtySLD"`
+	AvgPrice         float64   `json:"avgPrice"`
+	AvgPriceNet      float64   `json:"avgPriceNet"`
+	AvgPriceBOT      float64   `json:"avgPriceBOT"`
+	AvgPriceSLD      float64   `json:"avgPriceSLD"`
+	Value            float64   `json:"value"`
+	ValueBOT         float64   `json:"valueBOT"`
+	ValueSLD         float64   `json:"valueSLD"`
+	NetValue         float64   `json:"netValue"`
+	NetValueBOT      float64   `json:"netValueBOT"`
+	NetValueSLD      float64   `json:"netValueSLD"`
+	MarketPrice      float64   `json:"marketPrice"`
+	MarketValue      float64   `json:"marketValue"`
+	Commission       float64   `json:"commission"`
+	ExchangeFee      float64   `json:"exchangeFee"`
+	Cost             float64   `json:"cost"`
+	CostBasis        float64   `json:"costBasis"`
+	RealProfitLoss   float64   `json:"realProfitLoss"`
+	UnrealProfitLoss float64   `json:"unrealProfitLoss"`
+	TotalProfitLoss  float64   `json:"totalProfitLoss"`
+	Multiplier       float64   `json:"multiplier,omitempty"`
+}
+
+func newPositionState(p Position) positionState {
+	return positionState{
+		Time: p.timestamp, Symbol: p.symbol, Qty: p.qty, QtyBOT: p.qtyBOT, QtySLD: p.qtySLD,
+		AvgPrice: p.avgPrice, AvgPriceNet: p.avgPriceNet, AvgPriceBOT: p.avgPriceBOT, AvgPriceSLD: p.avgPriceSLD,
+		Value: p.value, ValueBOT: p.valueBOT, ValueSLD: p.valueSLD,
+		NetValue: p.netValue, NetValueBOT: p.netValueBOT, NetValueSLD: p.netValueSLD,
+		MarketPrice: p.marketPrice, MarketValue: p.marketValue,
+		Commission: p.commission, ExchangeFee: p.exchangeFee, Cost: p.cost, CostBasis: p.costBasis,
+		RealProfitLoss: p.realProfitLoss, UnrealProfitLoss: p.unrealProfitLoss, TotalProfitLoss: p.totalProfitLoss,
+		Multiplier: p.multiplier,
+	}
+}
+
+func (s positionState) position() Position {
+	return Position{
+		timestamp: s.Time, symbol: s.Symbol, qty: s.Qty, qtyBOT: s.QtyBOT, qtySLD: s.QtySLD,
+		avgPrice: s.AvgPrice, avgPriceNet: s.AvgPriceNet, avgPriceBOT: s.AvgPriceBOT, avgPriceSLD: s.AvgPriceSLD,
+		value: s.Value, valueBOT: s.ValueBOT, valueSLD: s.ValueSLD,
+		netValue: s.NetValue, netValueBOT: s.NetValueBOT, netValueSLD: s.NetValueSLD,
+		marketPrice: s.MarketPrice, marketValue: s.MarketValue,
+		commission: s.Commission, exchangeFee: s.ExchangeFee, cost: s.Cost, costBasis: s.CostBasis,
+		realProfitLoss: s.RealProfitLoss, unrealProfitLoss: s.UnrealProfitLoss, totalProfitLoss: s.TotalProfitLoss,
+		multiplier: s.Multiplier,
+	}
+}
+
+// hedgeState is the serializable form of the positions of a symbol in hedging mode.
+type hedgeState struct {
+	Long  positionState `json:"long"`
+	Short positionState `json:"short"`
+}
+
+// ledgerState is the serializable form of the lots of a lot ledger.
+type ledgerState struct {
+	Open   map[string][]Lot   `json:"open,omitempty"`
+	Closed []ClosedLot        `json:"closed,omitempty"`
+	Prices map[string]float64 `json:"prices,omitempty"`
+}
+
+// portfolioState is the serializable form of a portfolio.
+type portfolioState struct {
+	Cash           float64                  `json:"cash"`
+	Holdings       map[string]positionState `json:"holdings,omitempty"`
+	Hedges         map[string]hedgeState    `json:"hedges,omitempty"`
+	Orders         []orderState             `json:"orders,omitempty"`
+	Transactions   []fillState              `json:"transactions,omitempty"`
+	LastAccrual    time.Time                `json:"lastAccrual"`
+	InterestEarned float64                  `json:"interestEarned"`
+	FinancingCost  float64                  `json:"financingCost"`
+	BookedFlows    []CashFlow               `json:"bookedFlows,omitempty"`
+	SpreadQty      map[string]float64       `json:"spreadQty,omitempty"`
+	Ledger         *ledgerState             `json:"ledger,omitempty"`
+	Exits          map[string]*ExitState    `json:"exits,omitempty"`
+	ExitOrders     map[string]orderState    `json:"exitOrders,omitempty"`
+	Liquidations   map[string]orderState    `json:"liquidations,omitempty"`
+	Reserved       []reservationState       `json:"reserved,omitempty"`
+	Size           json.RawMessage          `json:"size,omitempty"`
+	Rules          []json.RawMessage        `json:"rules,omitempty"`
+}
+
+// Snapshot implements Snapshotter. The size manager and the risk rules are saved, if they are
+// a Snapshotter themselves.
+func (p *Portfolio) Snapshot() (json.RawMessage, error) {
+	s := portfolioState{
+		Cash:           p.cash,
+		LastAccrual:    p.lastAccrual,
+		InterestEarned: p.interestEarned,
+		FinancingCost:  p.financingCost,
+		BookedFlows:    p.bookedFlows,
+		SpreadQty:      p.spreadQty,
+	}
+	if len(p.holdings) > 0 {
+		s.Holdings = make(map[string]positionState, len(p.holdings))
+		for symbol, pos := range p.holdings {
+			s.Holdings[symbol] = newPositionState(pos)
+		}
+	}
+	if len(p.hedges) > 0 {
+		s.Hedges = make(map[string]hedgeState, len(p.hedges))
+		for symbol, h := range p.hedges {
+			s.Hedges[symbol] = hedgeState{Long: newPositionState(h.Long), Short: newPositionState(h.Short)}
+		}
+	}
+
+	var err error
+	if s.Orders, err = newOrderStates(p.orderBook); err != nil {
+		return nil, err
+	}
+	if s.Transactions, err = newFillStates(p.transactions); err != nil {
+		return nil, err
+	}
+	if p.ledger != nil {
+		s.Ledger = &ledgerState{Open: p.ledger.open, Closed: p.ledger.closed, Prices: p.ledger.prices}
+	}
+	if p.exits != nil {
+		s.Exits = p.exits.state
+		s.ExitOrders = newPendingStates(p.exits.pending)
+	}
+	s.Liquidations = newPendingStates(p.liquidations)
+	for _, r := range p.reserved {
+		s.Reserved = append(s.Reserved, reservationState{Order: newOrderState(r.order), Value: r.value, Booked: r.booked, Children: r.children})
+	}
+
+	if s.Size, err = snapshot(p.sizeManager); err != nil {
+		return nil, err
+	}
+	for _, rule := range p.rules {
+		state, err := snapshot(rule)
+		if err != nil {
+			return nil, err
+		}
+		s.Rules = append(s.Rules, state)
+	}
+
+	return json.Marshal(s)
+}
+
+// Restore implements Snapshotter.
+func (p *Portfolio) Restore(state json.RawMessage) error {
+	var s portfolioState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return fmt.Errorf("could not restore portfolio: %v", err)
+	}
+	if len(s.Rules) > 0 && len(s.Rules) != len(p.rules) {
+		return fmt.Errorf("could not restore portfolio: %d risk rules, the checkpoint has %d", len(p.rules), len(s.Rules))
+	}
+
+	p.cash = s.Cash
+	p.holdings = nil
+	if len(s.Holdings) > 0 {
+		p.holdings = make(map[string]Position, len(s.Holdings))
+		for symbol, pos := range s.Holdings {
+			p.holdings[symbol] = pos.position()
+		}
+	}
+	p.hedges = nil
+	if len(s.Hedges) > 0 {
+		p.hedges = make(map[string]Hedge, len(s.Hedges))
+		for symbol, h := range s.Hedges {
+			p.hedges[symbol] = Hedge{Long: h.Long.position(), Short: h.Short.position()}
+		}
+	}
+	p.orderBook = restoreOrders(s.Orders)
+	p.transactions = restoreFills(s.Transactions)
+	p.lastAccrual = s.LastAccrual
+	p.interestEarned = s.InterestEarned
+	p.financingCost = s.FinancingCost
+	p.bookedFlows = s.BookedFlows
+	p.ruleFlows = len(s.BookedFlows)
+	p.spreadQty = s.SpreadQty
+	if p.ledger != nil && s.Ledger != nil {
+		p.ledger.open, p.ledger.closed, p.ledger.prices = s.Ledger.Open, s.Ledger.Closed, s.Ledger.Prices
+	}
+	if p.exits != nil {
+		p.exits.state = s.Exits
+		p.exits.pending = restorePending(s.ExitOrders)
+	}
+	p.liquidations = restorePending(s.Liquidations)
+	p.reserved = nil
+	for _, r := range s.Reserved {
+		p.reserved = append(p.reserved, reservation{order: r.Order.order(), value: r.Value, booked: r.Booked, children: r.Children})
+	}
+
+	if err := restore(p.sizeManager, s.Size); err != nil {
+		return err
+	}
+	for i, state := range s.Rules {
+		if err := restore(p.rules[i], state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exchangeState is the serializable form of an exchange.
+type exchangeState struct {
+	Counter   int               `json:"counter"`
+	Orders    []orderState      `json:"orders,omitempty"`
+	History   []orderState      `json:"history,omitempty"`
+	Arrival   map[int]time.Time `json:"arrival,omitempty"`
+	Visible   map[int]float64   `json:"visible,omitempty"`
+	FillModel json.RawMessage   `json:"fillModel,omitempty"`
+}
+
+// Snapshot implements Snapshotter with the resting orders of the order book. The fill model is
+// saved, if it is a Snapshotter itself.
+func (e *Exchange) Snapshot() (json.RawMessage, error) {
+	s := exchangeState{Counter: e.orderbook.counter, Arrival: e.arrival, Visible: e.visible}
+
+	var err error
+	if s.Orders, err = newOrderStates(e.orderbook.orders); err != nil {
+		return nil, err
+	}
+	if s.History, err = newOrderStates(e.orderbook.history); err != nil {
+		return nil, err
+	}
+	if s.FillModel, err = snapshot(e.FillModel); err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// Restore implements Snapshotter.
+func (e *Exchange) Restore(state json.RawMessage) error {
+	var s exchangeState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return fmt.Errorf("could not restore exchange: %v", err)
+	}
+
+	e.orderbook = OrderBook{counter: s.Counter, orders: restoreOrders(s.Orders), history: restoreOrders(s.History)}
+	e.arrival = s.Arrival
+	e.visible = s.Visible
+	return restore(e.FillModel, s.FillModel)
+}
+
+// Snapshot implements Snapshotter with the volume queued ahead of the resting orders.
+func (m *QueueFillModel) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(m.queue)
+}
+
+// Restore implements Snapshotter.
+func (m *QueueFillModel) Restore(state json.RawMessage) error {
+	m.queue = nil
+	return json.Unmarshal(state, &m.queue)
+}
+
+// equityState is the serializable form of an equity point.
+type equityState struct {
+	Time     time.Time `json:"time"`
+	Equity   float64   `json:"equity"`
+	Return   float64   `json:"return"`
+	Drawdown float64   `json:"drawdown"`
+	CashFlow float64   `json:"cashFlow,omitempty"`
+	Long     float64   `json:"long,omitempty"`
+	Short    float64   `json:"short,omitempty"`
+	Capital  float64   `json:"capital"`
+}
+
+func newEquityState(e equityPoint) equityState {
+	return equityState{
+		Time: e.timestamp, Equity: e.equity, Return: e.equityReturn, Drawdown: e.drawdown,
+		CashFlow: e.cashFlow, Long: e.long, Short: e.short, Capital: e.capital,
+	}
+}
+
+func (s equityState) point() equityPoint {
+	return equityPoint{
+		timestamp: s.Time, equity: s.Equity, equityReturn: s.Return, drawdown: s.Drawdown,
+		cashFlow: s.CashFlow, long: s.Long, short: s.Short, capital: s.Capital,
+	}
+}
+
+// trackedState is the serializable form of a tracked event. Orders and fills are saved with all
+// their fields, other events as their record.
+type trackedState struct {
+	Order  *orderState  `json:"order,omitempty"`
+	Fill   *fillState   `json:"fill,omitempty"`
+	Record *EventRecord `json:"record,omitempty"`
+}
+
+// clearMetric keeps the metric of a restored data event unset, if it was not set when saved.
+func clearMetric(e EventHandler, metric map[string]float64) {
+	if metric != nil {
+		return
+	}
+	switch e := e.(type) {
+	case *Bar:
+		e.Metric = nil
+	case *Tick:
+		e.Metric = nil
+	case *Book:
+		e.Metric = nil
+	}
+}
+
+// transactionState is the serializable form of a transaction, the index of the tracked event
+// of the fill or the fill itself.
+type transactionState struct {
+	Event *int       `json:"event,omitempty"`
+	Fill  *fillState `json:"fill,omitempty"`
+}
+
+// statisticState is the serializable form of a statistic.
+type statisticState struct {
+	Events       []trackedState     `json:"events,omitempty"`
+	Transactions []transactionState `json:"transactions,omitempty"`
+	Equity       []equityState      `json:"equity,omitempty"`
+	High         equityState        `json:"high"`
+	Low          equityState        `json:"low"`
+	FlowCount    int                `json:"flowCount"`
+	Running      struct {
+		N           int     `json:"n"`
+		First       float64 `json:"first"`
+		Mean        float64 `json:"mean"`
+		M2          float64 `json:"m2"`
+		MaxDrawdown float64 `json:"maxDrawdown"`
+	} `json:"running"`
+}
+
+// Snapshot implements Snapshotter with the tracked events and the equity curve so far.
+func (s *Statistic) Snapshot() (json.RawMessage, error) {
+	st := statisticState{
+		Events:    make([]trackedState, len(s.eventHistory)),
+		High:      newEquityState(s.high),
+		Low:       newEquityState(s.low),
+		FlowCount: s.flowCount,
+	}
+
+	index := make(map[EventHandler]int)
+	for i, e := range s.eventHistory {
+		switch e := e.(type) {
+		case *Order:
+			orders, _ := newOrderStates([]OrderEvent{e})
+			st.Events[i].Order = &orders[0]
+		case *Fill:
+			fills, _ := newFillStates([]FillEvent{e})
+			st.Events[i].Fill = &fills[0]
+			index[e] = i
+		default:
+			r := NewEventRecord(e)
+			st.Events[i].Record = &r
+		}
+	}
+
+	st.Transactions = make([]transactionState, len(s.transactionHistory))
+	for i, fill := range s.transactionHistory {
+		if n, ok := index[fill]; ok {
+			st.Transactions[i].Event = &n
+			continue
+		}
+		fills, err := newFillStates([]FillEvent{fill})
+		if err != nil {
+			return nil, err
+		}
+		st.Transactions[i].Fill = &fills[0]
+	}
+
+	for _, e := range s.equity {
+		st.Equity = append(st.Equity, newEquityState(e))
+	}
+	st.Running.N, st.Running.First, st.Running.Mean = s.running.n, s.running.first, s.running.mean
+	st.Running.M2, st.Running.MaxDrawdown = s.running.m2, s.running.maxDrawdown
+
+	return json.Marshal(st)
+}
+
+// Restore implements Snapshotter. Tracked events of a type without a record keep only
+// their time, symbol and lineage.
+func (s *Statistic) Restore(state json.RawMessage) error {
+	var st statisticState
+	if err := json.Unmarshal(state, &st); err != nil {
+		return fmt.Errorf("could not restore statistic: %v", err)
+	}
+
+	s.eventHistory = make([]EventHandler, 0, len(st.Events))
+	for _, tracked := range st.Events {
+		switch {
+		case tracked.Order != nil:
+			s.eventHistory = append(s.eventHistory, restoreOrders([]orderState{*tracked.Order})[0])
+		case tracked.Fill != nil:
+			s.eventHistory = append(s.eventHistory, restoreFills([]fillState{*tracked.Fill})[0])
+		case tracked.Record != nil:
+			e, err := tracked.Record.Event()
+			if err != nil {
+				// e.g. a margin call
+				base := tracked.Record.baseEvent()
+				e = &base
+			}
+			clearMetric(e, tracked.Record.Metric)
+			s.eventHistory = append(s.eventHistory, e)
+		}
+	}
+
+	s.transactionHistory = nil
+	for _, t := range st.Transactions {
+		switch {
+		case t.Event != nil && *t.Event < len(s.eventHistory):
+			fill, ok := s.eventHistory[*t.Event].(FillEvent)
+			if !ok {
+				return fmt.Errorf("could not restore statistic: transaction refers to event %d, not a fill", *t.Event)
+			}
+			s.transactionHistory = append(s.transactionHistory, fill)
+		case t.Fill != nil:
+			s.transactionHistory = append(s.transactionHistory, restoreFills([]fillState{*t.Fill})[0])
+		default:
+			return errors.New("could not restore statistic: transaction without a fill")
+		}
+	}
+
+	s.equity = nil
+	for _, e := range st.Equity {
+		s.equity = append(s.equity, e.point())
+	}
+	s.high, s.low = st.High.point(), st.Low.point()
+	s.flowCount = st.FlowCount
+	s.running = running{
+		n: st.Running.N, first: st.Running.First, mean: st.Running.Mean,
+		m2: st.Running.M2, maxDrawdown: st.Running.MaxDrawdown,
+	}
+	return nil
+}
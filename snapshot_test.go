@@ -0,0 +1,75 @@
+package gobacktest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	// a run with orders resting in the exchange until the end of the data
+	build := func() *Backtest {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 101, 102))
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		test.SetExchange(&Exchange{
+			Symbol:      "TEST",
+			Commission:  &FixedCommission{Commission: 1},
+			ExchangeFee: &FixedExchangeFee{ExchangeFee: 0},
+			Latency:     &FixedLatency{Delay: 24 * time.Hour},
+		})
+		return test
+	}
+
+	test := build()
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	// testCases is a table for testing the restore of the snapshot of a component
+	var testCases = []struct {
+		msg       string
+		component func(*Backtest) Snapshotter
+	}{
+		{"portfolio:", func(b *Backtest) Snapshotter { return b.portfolio.(*Portfolio) }},
+		{"exchange:", func(b *Backtest) Snapshotter { return b.exchange.(*Exchange) }},
+		{"statistic:", func(b *Backtest) Snapshotter { return b.statistic.(*Statistic) }},
+	}
+
+	for _, tc := range testCases {
+		state, err := tc.component(test).Snapshot()
+		if err != nil {
+			t.Fatalf("%v Snapshot(): unexpected error %v", tc.msg, err)
+		}
+
+		restored := tc.component(build())
+		if err := restored.Restore(state); err != nil {
+			t.Fatalf("%v Restore(): unexpected error %v", tc.msg, err)
+		}
+		// the slabs of the event pools, the cost estimator and the clock set by the run are no state
+		for _, c := range []Snapshotter{restored, tc.component(test)} {
+			switch c := c.(type) {
+			case *Portfolio:
+				c.pool = EventPool{}
+				c.costs = nil
+				c.clock = Clock{}
+			case *Exchange:
+				c.pool = EventPool{}
+			}
+		}
+		if !reflect.DeepEqual(restored, tc.component(test)) {
+			t.Errorf("%v Restore(): expected %+v, actual %+v", tc.msg, tc.component(test), restored)
+		}
+		if again, err := restored.Snapshot(); err != nil || !bytes.Equal(again, state) {
+			t.Errorf("%v Snapshot(): expected the restored state %s, actual %s %v", tc.msg, state, again, err)
+		}
+	}
+
+	if orders, ok := test.exchange.(*Exchange).orderbook.Orders(); !ok {
+		t.Errorf("Run(): expected resting orders in the snapshot, actual %v", orders)
+	}
+}
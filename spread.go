@@ -0,0 +1,172 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+)
+
+// Spread is a synthetic instrument over two symbols, long the Long leg and short
+// Ratio times the Short leg. Its price is the price of the Long leg minus Ratio times
+// the price of the Short leg. An order of the spread is legged into both symbols on its fill.
+type Spread struct {
+	Symbol string
+	Long   string
+	Short  string
+	Ratio  float64 // hedge ratio, defaults to 1
+}
+
+// ratio returns the hedge ratio of the spread.
+func (s *Spread) ratio() float64 {
+	if s.Ratio == 0 {
+		return 1
+	}
+	return s.Ratio
+}
+
+// Price returns the spread price from the prices of both legs.
+func (s *Spread) Price(long, short float64) float64 {
+	price := long - s.ratio()*short
+	return math.Round(price*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// Apply returns a copy of the data stream with a spread bar inserted
+// after both legs received a data event with the same timestamp.
+func (s *Spread) Apply(stream []DataEvent) []DataEvent {
+	var result []DataEvent
+	var long, short DataEvent
+
+	for _, e := range stream {
+		result = append(result, e)
+
+		switch e.Symbol() {
+		case s.Long:
+			long = e
+		case s.Short:
+			short = e
+		default:
+			continue
+		}
+
+		if long == nil || short == nil || !long.Time().Equal(short.Time()) {
+			continue
+		}
+		result = append(result, s.bar(long, short))
+		long, short = nil, nil
+	}
+
+	return result
+}
+
+// bar creates a spread bar from the data events of both legs.
+func (s *Spread) bar(long, short DataEvent) *Bar {
+	closePrice := s.Price(long.Price(), short.Price())
+	open := closePrice
+	if l, ok := long.(*Bar); ok {
+		if sh, ok := short.(*Bar); ok {
+			open = s.Price(l.Open, sh.Open)
+		}
+	}
+
+	return &Bar{
+		Event:    Event{timestamp: long.Time(), symbol: s.Symbol},
+		Metric:   Metric{},
+		Open:     open,
+		High:     math.Max(open, closePrice),
+		Low:      math.Min(open, closePrice),
+		Close:    closePrice,
+		AdjClose: closePrice,
+	}
+}
+
+// legs splits a fill of the spread into the fills of both legs at their latest prices.
+// The commission and fees of the spread fill are booked on the Long leg.
+func (s *Spread) legs(fill FillEvent, data DataHandler) (*Fill, *Fill, bool) {
+	long := data.Latest(s.Long)
+	short := data.Latest(s.Short)
+	if long == nil || short == nil {
+		return nil, nil, false
+	}
+
+	opposite := SLD
+	if fill.Direction() == SLD {
+		opposite = BOT
+	}
+
+	longFill := &Fill{
+		Event:       Event{timestamp: fill.Time(), symbol: s.Long},
+		direction:   fill.Direction(),
+		qty:         fill.Qty(),
+		price:       long.Price(),
+		commission:  fill.Commission(),
+		exchangeFee: fill.ExchangeFee(),
+		cost:        fill.Cost(),
+	}
+	shortFill := &Fill{
+		Event:     Event{timestamp: fill.Time(), symbol: s.Short},
+		direction: opposite,
+		qty:       fill.Qty() * s.ratio(),
+		price:     short.Price(),
+	}
+
+	copyTags(fill, longFill)
+	copyTags(fill, shortFill)
+
+	return longFill, shortFill, true
+}
+
+// SetSpreads sets the spread instruments traded by the portfolio.
+func (p *Portfolio) SetSpreads(spreads ...*Spread) {
+	p.spreads = make(map[string]*Spread)
+	for _, s := range spreads {
+		p.spreads[s.Symbol] = s
+	}
+}
+
+// Spread returns a spread instrument of the portfolio by symbol.
+func (p Portfolio) Spread(symbol string) (*Spread, bool) {
+	s, ok := p.spreads[symbol]
+	return s, ok
+}
+
+// onSpreadFill books the legs of a spread fill and tracks the qty of the spread position.
+func (p *Portfolio) onSpreadFill(s *Spread, fill FillEvent, data DataHandler) error {
+	long, short, ok := s.legs(fill, data)
+	if !ok {
+		return fmt.Errorf("could not leg spread %s, no price for its legs", s.Symbol)
+	}
+	// the legs are booked with the contract multiplier of their instruments
+	for _, leg := range []*Fill{long, short} {
+		if m := p.instruments.Get(leg.Symbol()).ContractMultiplier(); m != 1 {
+			leg.SetMultiplier(m)
+		}
+	}
+
+	if _, err := p.OnFill(long, data); err != nil {
+		return err
+	}
+	if _, err := p.OnFill(short, data); err != nil {
+		return err
+	}
+
+	// check for nil map, else initialise the map
+	if p.spreadQty == nil {
+		p.spreadQty = make(map[string]float64)
+	}
+	if fill.Direction() == BOT {
+		p.spreadQty[s.Symbol] += fill.Qty()
+	} else {
+		p.spreadQty[s.Symbol] -= fill.Qty()
+	}
+	return nil
+}
+
+// position returns the position of a symbol, for a spread the qty of the spread position.
+func (p Portfolio) position(symbol string) (Position, bool) {
+	if pos, ok := p.holdings[symbol]; ok {
+		return pos, true
+	}
+	if qty, ok := p.spreadQty[symbol]; ok {
+		return Position{symbol: symbol, qty: qty}, true
+	}
+	return Position{}, false
+}
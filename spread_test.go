@@ -0,0 +1,76 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpreadApply(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	spread := &Spread{Symbol: "A-B", Long: "A", Short: "B", Ratio: 2}
+
+	stream := []DataEvent{
+		&Bar{Event: Event{timestamp: day, symbol: "A"}, Open: 100, Close: 110},
+		&Bar{Event: Event{timestamp: day, symbol: "B"}, Open: 40, Close: 50},
+		&Bar{Event: Event{timestamp: day, symbol: "C"}, Close: 10},
+		&Bar{Event: Event{timestamp: day.AddDate(0, 0, 1), symbol: "A"}, Open: 110, Close: 100},
+	}
+
+	result := spread.Apply(stream)
+	if len(result) != 5 {
+		t.Fatalf("Apply(): expected 5 events, actual %v", len(result))
+	}
+
+	bar, ok := result[2].(*Bar)
+	if !ok || bar.Symbol() != "A-B" {
+		t.Fatalf("Apply(): expected spread bar after both legs, actual %#v", result[2])
+	}
+	if bar.Open != 20 || bar.Close != 10 || bar.High != 20 || bar.Low != 10 {
+		t.Errorf("Apply(): expected spread bar 20/20/10/10, actual %v/%v/%v/%v", bar.Open, bar.High, bar.Low, bar.Close)
+	}
+}
+
+func TestPortfolioSpreadFill(t *testing.T) {
+	spread := &Spread{Symbol: "A-B", Long: "A", Short: "B", Ratio: 2}
+	data := &Data{
+		latest: map[string]DataEvent{
+			"A":   &Bar{Close: 110},
+			"B":   &Bar{Close: 50},
+			"A-B": &Bar{Close: 10},
+		},
+	}
+
+	p := &Portfolio{initialCash: 10000, cash: 10000}
+	p.SetSpreads(spread)
+
+	// buy the spread
+	fill := &Fill{Event: Event{symbol: "A-B"}, direction: BOT, qty: 10, price: 10, commission: 5, cost: 5}
+	if _, err := p.OnFill(fill, data); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+
+	if pos, ok := p.IsLong("A"); !ok || pos.qty != 10 {
+		t.Errorf("OnFill(): expected long leg of 10, actual %#v", pos)
+	}
+	if pos, ok := p.IsShort("B"); !ok || pos.qty != -20 {
+		t.Errorf("OnFill(): expected short leg of -20, actual %#v", pos)
+	}
+	if pos, ok := p.IsLong("A-B"); !ok || pos.qty != 10 {
+		t.Errorf("IsLong(): expected spread position of 10, actual %#v", pos)
+	}
+	// 10 * 110 + 5 paid, 20 * 50 received
+	if p.Cash() != 9895 {
+		t.Errorf("OnFill(): expected cash %v, actual %v", 9895.0, p.Cash())
+	}
+
+	// sell the spread
+	fill = &Fill{Event: Event{symbol: "A-B"}, direction: SLD, qty: 10, price: 10}
+	if _, err := p.OnFill(fill, data); err != nil {
+		t.Fatalf("OnFill(): unexpected error %v", err)
+	}
+	for _, symbol := range []string{"A", "B", "A-B"} {
+		if pos, ok := p.IsInvested(symbol); ok {
+			t.Errorf("OnFill(): expected %s to be closed, actual %#v", symbol, pos)
+		}
+	}
+}
@@ -58,6 +58,10 @@ type Statistic struct {
 	equity             []equityPoint
 	high               equityPoint
 	low                equityPoint
+	flowCount          int
+	confidence         []float64
+	running            running
+	ledger             *LotLedger // lot ledger of the portfolio, if a cost basis method is set
 }
 
 type equityPoint struct {
@@ -65,6 +69,10 @@ type equityPoint struct {
 	equity       float64
 	equityReturn float64
 	drawdown     float64
+	cashFlow     float64 // external cash flow booked before this equity point
+	long         float64 // market value of the long positions
+	short        float64 // market value of the short positions, positive
+	capital      float64 // net liquidation value, the short positions as liability
 }
 
 // Update the complete statistics to a given data event.
@@ -74,13 +82,44 @@ func (s *Statistic) Update(d DataEvent, p PortfolioHandler) {
 	e.timestamp = d.Time()
 	e.equity = p.Value()
 
+	// record the exposure of the positions
+	if h, ok := p.(interface{ Holdings() map[string]Position }); ok {
+		for _, pos := range h.Holdings() {
+			switch {
+			case pos.qty > 0:
+				e.long += pos.marketValue
+			case pos.qty < 0:
+				e.short += pos.marketValue
+			}
+		}
+		e.capital = p.Cash() + e.long - e.short
+	}
+
+	// the realized profit/loss of the trade report follows the cost basis method of the portfolio
+	if l, ok := p.(interface{ Ledger() (*LotLedger, bool) }); ok {
+		s.ledger, _ = l.Ledger()
+	}
+
+	// sum up the cash flows booked since the last equity point
+	if cf, ok := p.(CashFlower); ok {
+		flows := cf.CashFlows()
+		if s.flowCount > len(flows) {
+			s.flowCount = 0
+		}
+		for _, flow := range flows[s.flowCount:] {
+			e.cashFlow += flow.Amount
+		}
+		s.flowCount = len(flows)
+	}
+
 	// calc equity return for current equity point
 	if len(s.equity) > 0 {
 		e = s.calcEquityReturn(e)
 	}
 
-	// calc drawdown for current equity point
+	// calc drawdown for current equity point, a cash flow shifts the high-water mark
 	if len(s.equity) > 0 {
+		s.high.equity += e.cashFlow
 		e = s.calcDrawdown(e)
 	}
 
@@ -94,6 +133,22 @@ func (s *Statistic) Update(d DataEvent, p PortfolioHandler) {
 
 	// append new quity point
 	s.equity = append(s.equity, e)
+	s.running.add(e)
+}
+
+// Reserve preallocates the statistics for a backtest of n data events,
+// which avoids growing the histories during the run.
+func (s *Statistic) Reserve(n int) {
+	if cap(s.equity)-len(s.equity) < n {
+		equity := make([]equityPoint, len(s.equity), len(s.equity)+n)
+		copy(equity, s.equity)
+		s.equity = equity
+	}
+	if cap(s.eventHistory)-len(s.eventHistory) < n {
+		events := make([]EventHandler, len(s.eventHistory), len(s.eventHistory)+n)
+		copy(events, s.eventHistory)
+		s.eventHistory = events
+	}
 }
 
 // TrackEvent tracks an event
@@ -123,6 +178,9 @@ func (s *Statistic) Reset() error {
 	s.equity = nil
 	s.high = equityPoint{}
 	s.low = equityPoint{}
+	s.flowCount = 0
+	s.running = running{}
+	s.ledger = nil
 	return nil
 }
 
@@ -133,11 +191,27 @@ func (s Statistic) PrintResult() {
 
 	fmt.Printf("Counted %d total transactions:\n", len(s.Transactions()))
 	for k, v := range s.Transactions() {
-		fmt.Printf("%d. Transaction: %v Action: %v Price: %f Qty: %d\n", k+1, v.Time().Format("2006-01-02"), v.Direction(), v.Price(), v.Qty())
+		fmt.Printf("%d. Transaction: %v Action: %v Price: %f Qty: %v", k+1, v.Time().Format("2006-01-02"), v.Direction(), v.Price(), v.Qty())
+		if t, ok := v.(Tagger); ok && len(t.Tags()) > 0 {
+			fmt.Printf(" Tags: %s", formatTags(t.Tags()))
+		}
+		fmt.Println()
+	}
+	if s.ledger != nil {
+		s.ledger.PrintResult()
 	}
+
+	s.printRisk()
+	s.printExposure()
+	s.printCosts()
+	s.printDrawdownRatios()
+	s.printDistribution()
+	s.printSignificance()
+	s.printCustomMetrics()
 }
 
-// TotalEquityReturn calculates the the total return on the first and last equity point
+// TotalEquityReturn calculates the the total return on the first and last equity point,
+// the cash flows booked after the first equity point are no return.
 func (s Statistic) TotalEquityReturn() (r float64, err error) {
 	firstEquityPoint, ok := s.firstEquityPoint()
 	if !ok {
@@ -151,7 +225,12 @@ func (s Statistic) TotalEquityReturn() (r float64, err error) {
 	// }
 	lastEquity := lastEquityPoint.equity
 
-	totalEquityReturn := (lastEquity - firstEquity) / firstEquity
+	var flows float64
+	for _, e := range s.equity[1:] {
+		flows += e.cashFlow
+	}
+
+	totalEquityReturn := (lastEquity - flows - firstEquity) / firstEquity
 	total := math.Round(totalEquityReturn*math.Pow10(DP)) / math.Pow10(DP)
 	return total, nil
 }
@@ -188,6 +267,28 @@ func (s Statistic) MaxDrawdownDuration() (d time.Duration) {
 	return d
 }
 
+// EquityPoint is a point of the equity curve.
+type EquityPoint struct {
+	Time     time.Time
+	Equity   float64
+	Return   float64 // return since the previous point
+	Drawdown float64 // drawdown from the high-water mark, 0 or negative
+}
+
+// EquityCurve returns the equity curve of the backtest, a point for each data event.
+func (s Statistic) EquityCurve() []EquityPoint {
+	curve := make([]EquityPoint, len(s.equity))
+	for i, ep := range s.equity {
+		curve[i] = EquityPoint{
+			Time:     ep.timestamp,
+			Equity:   ep.equity,
+			Return:   ep.equityReturn,
+			Drawdown: ep.drawdown,
+		}
+	}
+	return curve
+}
+
 // SharpRatio returns the Sharp ratio compared to a risk free benchmark return.
 func (s *Statistic) SharpRatio(riskfree float64) float64 {
 	var equityReturns = make([]float64, len(s.equity))
@@ -261,7 +362,8 @@ func (s Statistic) calcEquityReturn(e equityPoint) equityPoint {
 		return e
 	}
 
-	equityReturn := (currentEquity - lastEquity) / lastEquity
+	// a cash flow is no return
+	equityReturn := (currentEquity - e.cashFlow - lastEquity) / lastEquity
 	e.equityReturn = math.Round(equityReturn*math.Pow10(DP)) / math.Pow10(DP)
 
 	return e
@@ -173,8 +173,9 @@ func TestResetStatistic(t *testing.T) {
 					{equity: 100},
 					{equity: 90},
 				},
-				high: equityPoint{equity: 100},
-				low:  equityPoint{equity: 90},
+				high:   equityPoint{equity: 100},
+				low:    equityPoint{equity: 90},
+				ledger: NewLotLedger(FIFO),
 			},
 			Statistic{},
 		},
@@ -607,3 +608,49 @@ func TestSortinoRatio(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkStatisticUpdate(b *testing.B) {
+	exampleTime, _ := time.Parse("2006-01-02", "2017-06-01")
+	bar := &Bar{Event: Event{timestamp: exampleTime, symbol: "TEST.DE"}, Close: 10}
+	portfolio := &Portfolio{initialCash: 100000, cash: 100000}
+
+	s := &Statistic{}
+	s.Reserve(b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Update(bar, portfolio)
+	}
+}
+
+func BenchmarkStatisticSharpRatio(b *testing.B) {
+	s := &Statistic{}
+	for i := 0; i < 1000; i++ {
+		s.equity = append(s.equity, equityPoint{equityReturn: float64(i%7-3) / 100})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.SharpRatio(0)
+	}
+}
+
+func TestEquityCurve(t *testing.T) {
+	exampleTime, _ := time.Parse("2006-01-02", "2017-06-01")
+	s := Statistic{equity: []equityPoint{
+		{timestamp: exampleTime, equity: 100},
+		{timestamp: exampleTime.AddDate(0, 0, 1), equity: 90, equityReturn: -0.1, drawdown: -0.1},
+	}}
+
+	var exp = []EquityPoint{
+		{Time: exampleTime, Equity: 100},
+		{Time: exampleTime.AddDate(0, 0, 1), Equity: 90, Return: -0.1, Drawdown: -0.1},
+	}
+	if curve := s.EquityCurve(); !reflect.DeepEqual(curve, exp) {
+		t.Errorf("EquityCurve(): expected %+v, actual %+v", exp, curve)
+	}
+	if curve := (Statistic{}).EquityCurve(); len(curve) != 0 {
+		t.Errorf("EquityCurve(): expected an empty curve, actual %+v", curve)
+	}
+}
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// Period is the length of the periods to compare the returns of runs in.
+type Period int
+
+// The periods of a comparison.
+const (
+	Monthly Period = iota
+	Yearly
+	Daily
+)
+
+// start returns the start of the period of a time.
+func (p Period) start(t time.Time) time.Time {
+	switch p {
+	case Yearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	case Daily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// format returns the layout of the periods.
+func (p Period) format() string {
+	switch p {
+	case Yearly:
+		return "2006"
+	case Daily:
+		return "2006-01-02"
+	}
+	return "2006-01"
+}
+
+// Comparison is the side-by-side report of runs. The first run is the baseline, the other
+// runs are compared against it, e.g. the strategy before and after a change.
+type Comparison struct {
+	Runs   []Run
+	Equity []ComparePoint // the overlaid equity curves
+	// Deltas are the metrics of each run minus the metrics of the baseline, zero for the baseline
+	Deltas  []Metrics
+	Period  Period
+	Periods []PeriodReturns
+	// Outperformed is the number of periods each run returned more than the baseline
+	Outperformed []int
+}
+
+// ComparePoint is the equity of the compared runs at a time, each normalised to a start
+// equity of 1, so runs with different initial cash overlay. It is NaN before the first
+// point of a run, after its last point the last equity is carried forward.
+type ComparePoint struct {
+	Time   time.Time
+	Equity []float64
+}
+
+// PeriodReturns are the returns of the compared runs in a period and their excess over the
+// return of the baseline. A return is NaN for a run without equity in the period.
+type PeriodReturns struct {
+	Start   time.Time
+	Returns []float64
+	Excess  []float64
+}
+
+// Compare loads stored runs and compares them in periods, the first run is the baseline.
+func (s *Store) Compare(period Period, ids ...int64) (*Comparison, error) {
+	runs := make([]Run, len(ids))
+	curves := make([][]gbt.EquityPoint, len(ids))
+	for i, id := range ids {
+		var err error
+		if runs[i], err = s.Run(id); err != nil {
+			return nil, err
+		}
+		if curves[i], err = s.Equity(id); err != nil {
+			return nil, err
+		}
+	}
+	return Compare(period, runs, curves)
+}
+
+// Compare compares runs with their equity curves in periods, the first run is the baseline.
+func Compare(period Period, runs []Run, curves [][]gbt.EquityPoint) (*Comparison, error) {
+	if len(runs) < 2 {
+		return nil, errors.New("could not compare less than two runs")
+	}
+	if len(runs) != len(curves) {
+		return nil, fmt.Errorf("could not compare %d runs with %d equity curves", len(runs), len(curves))
+	}
+
+	c := &Comparison{Runs: runs, Period: period, Outperformed: make([]int, len(runs))}
+	for _, r := range runs {
+		c.Deltas = append(c.Deltas, r.Metrics.sub(runs[0].Metrics))
+	}
+	c.Equity = overlay(curves)
+	c.Periods = periodReturns(period, c.Equity, len(runs))
+
+	for _, p := range c.Periods {
+		for i, excess := range p.Excess {
+			if i > 0 && excess > 0 {
+				c.Outperformed[i]++
+			}
+		}
+	}
+	return c, nil
+}
+
+// sub returns the difference of two metrics.
+func (m Metrics) sub(o Metrics) Metrics {
+	return Metrics{
+		Return:              m.Return - o.Return,
+		MaxDrawdown:         m.MaxDrawdown - o.MaxDrawdown,
+		MaxDrawdownDuration: m.MaxDrawdownDuration - o.MaxDrawdownDuration,
+		Sharpe:              m.Sharpe - o.Sharpe,
+		Sortino:             m.Sortino - o.Sortino,
+		Trades:              m.Trades - o.Trades,
+	}
+}
+
+// overlay merges the equity curves at the times of all curves.
+func overlay(curves [][]gbt.EquityPoint) []ComparePoint {
+	var times []time.Time
+	seen := make(map[int64]bool)
+	for _, curve := range curves {
+		for _, p := range curve {
+			if !seen[p.Time.UnixNano()] {
+				seen[p.Time.UnixNano()] = true
+				times = append(times, p.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	points := make([]ComparePoint, len(times))
+	next := make([]int, len(curves))
+	for i, t := range times {
+		points[i] = ComparePoint{Time: t, Equity: make([]float64, len(curves))}
+		for j, curve := range curves {
+			for next[j] < len(curve) && !curve[next[j]].Time.After(t) {
+				next[j]++
+			}
+			if next[j] == 0 || curve[0].Equity == 0 {
+				points[i].Equity[j] = math.NaN()
+				continue
+			}
+			points[i].Equity[j] = curve[next[j]-1].Equity / curve[0].Equity
+		}
+	}
+	return points
+}
+
+// periodReturns returns the returns of the overlaid curves in each period, from the last
+// equity of the previous period, or the start equity, to the last equity of the period.
+func periodReturns(period Period, points []ComparePoint, n int) []PeriodReturns {
+	var periods []PeriodReturns
+	prev := make([]float64, n)
+	for j := range prev {
+		prev[j] = 1
+	}
+	for i := 0; i < len(points); {
+		start := period.start(points[i].Time)
+		for i < len(points) && period.start(points[i].Time).Equal(start) {
+			i++
+		}
+		last := points[i-1].Equity
+
+		p := PeriodReturns{Start: start, Returns: make([]float64, n), Excess: make([]float64, n)}
+		for j := range last {
+			p.Returns[j] = last[j]/prev[j] - 1
+			if !math.IsNaN(last[j]) {
+				prev[j] = last[j]
+			}
+		}
+		for j := range last {
+			p.Excess[j] = p.Returns[j] - p.Returns[0]
+		}
+		periods = append(periods, p)
+	}
+	return periods
+}
+
+// Print prints the metrics of the runs with their deltas to the baseline and the returns
+// of the periods.
+func (c *Comparison) Print(w io.Writer) {
+	fmt.Fprintf(w, "%-24s %10s %10s %12s %8s %8s %8s\n", "Run", "Return", "Drawdown", "DD Duration", "Sharpe", "Sortino", "Trades")
+	for i, r := range c.Runs {
+		m := r.Metrics
+		fmt.Fprintf(w, "%-24s %9.2f%% %9.2f%% %12s %8.2f %8.2f %8d\n", c.label(i),
+			m.Return*100, m.MaxDrawdown*100, m.MaxDrawdownDuration, m.Sharpe, m.Sortino, m.Trades)
+	}
+
+	fmt.Fprintf(w, "\nDelta to %s\n", c.label(0))
+	for i := 1; i < len(c.Runs); i++ {
+		d := c.Deltas[i]
+		fmt.Fprintf(w, "%-24s %+9.2f%% %+9.2f%% %12s %+8.2f %+8.2f %+8d\n", c.label(i),
+			d.Return*100, d.MaxDrawdown*100, d.MaxDrawdownDuration, d.Sharpe, d.Sortino, d.Trades)
+	}
+	c.printCustom(w)
+
+	fmt.Fprintf(w, "\n%-12s", "Period")
+	for i := range c.Runs {
+		fmt.Fprintf(w, " %10s", "#"+strconv.FormatInt(c.Runs[i].ID, 10))
+		if i > 0 {
+			fmt.Fprintf(w, " %10s", "Excess")
+		}
+	}
+	fmt.Fprintln(w)
+	for _, p := range c.Periods {
+		fmt.Fprintf(w, "%-12s", p.Start.Format(c.Period.format()))
+		for i := range c.Runs {
+			fmt.Fprintf(w, " %9.2f%%", p.Returns[i]*100)
+			if i > 0 {
+				fmt.Fprintf(w, " %+9.2f%%", p.Excess[i]*100)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	for i := 1; i < len(c.Runs); i++ {
+		fmt.Fprintf(w, "%s outperformed in %d of %d periods\n", c.label(i), c.Outperformed[i], len(c.Periods))
+	}
+}
+
+// printCustom prints the custom metrics of the runs, a column for each run.
+func (c *Comparison) printCustom(w io.Writer) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range c.Runs {
+		for name := range r.Custom {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "\n%-24s", "Metric")
+	for i := range c.Runs {
+		fmt.Fprintf(w, " %10s", "#"+strconv.FormatInt(c.Runs[i].ID, 10))
+	}
+	fmt.Fprintln(w)
+	for _, name := range names {
+		fmt.Fprintf(w, "%-24s", name)
+		for _, r := range c.Runs {
+			v, ok := r.Custom[name]
+			if !ok {
+				v = math.NaN()
+			}
+			fmt.Fprintf(w, " %10.4f", v)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// label returns the id and name of a run.
+func (c *Comparison) label(i int) string {
+	return fmt.Sprintf("#%d %s", c.Runs[i].ID, c.Runs[i].Name)
+}
+
+// WriteCSV writes the overlaid equity curves as csv, a column for each run.
+func (c *Comparison) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Time"}
+	for i := range c.Runs {
+		header = append(header, c.label(i))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, p := range c.Equity {
+		record := []string{p.Time.Format(time.RFC3339)}
+		for _, equity := range p.Equity {
+			value := ""
+			if !math.IsNaN(equity) {
+				value = strconv.FormatFloat(equity, 'f', -1, 64)
+			}
+			record = append(record, value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// curve returns an equity curve of a point per day from a date.
+func curve(start time.Time, equity ...float64) []gbt.EquityPoint {
+	var points []gbt.EquityPoint
+	for i, e := range equity {
+		points = append(points, gbt.EquityPoint{Time: start.AddDate(0, 0, i), Equity: e})
+	}
+	return points
+}
+
+func TestCompare(t *testing.T) {
+	jan30 := time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{ID: 1, Name: "base", Metrics: Metrics{Return: 0.1, Sharpe: 1, Trades: 10}},
+		{ID: 2, Name: "change", Metrics: Metrics{Return: 0.15, Sharpe: 0.5, Trades: 12}},
+	}
+	curves := [][]gbt.EquityPoint{
+		// 30.01. to 02.02.
+		curve(jan30, 100, 110, 121, 110),
+		// starts a day later with another initial cash
+		curve(jan30.AddDate(0, 0, 1), 1000, 1000, 1100),
+	}
+
+	c, err := Compare(Monthly, runs, curves)
+	if err != nil {
+		t.Fatalf("Compare(): unexpected error %v", err)
+	}
+
+	if d := c.Deltas[1]; math.Abs(d.Return-0.05) > 1e-9 || d.Sharpe != -0.5 || d.Trades != 2 || c.Deltas[0] != (Metrics{}) {
+		t.Errorf("Compare(): unexpected deltas %+v", c.Deltas)
+	}
+
+	// testCases is a table for testing the overlaid equity
+	var testCases = []struct {
+		day    int
+		expEq0 float64
+		expEq1 float64
+	}{
+		{0, 1, math.NaN()},
+		{1, 1.1, 1},
+		{2, 1.21, 1},
+		{3, 1.1, 1.1},
+	}
+
+	if len(c.Equity) != len(testCases) {
+		t.Fatalf("Compare(): expected %d points, actual %+v", len(testCases), c.Equity)
+	}
+	for _, tc := range testCases {
+		p := c.Equity[tc.day]
+		if !p.Time.Equal(jan30.AddDate(0, 0, tc.day)) || !equal(p.Equity[0], tc.expEq0) || !equal(p.Equity[1], tc.expEq1) {
+			t.Errorf("Compare(): expected equity %v %v on day %d, actual %+v", tc.expEq0, tc.expEq1, tc.day, p)
+		}
+	}
+
+	// january returns 10% and 0%, february 0% and 10%
+	if len(c.Periods) != 2 || c.Periods[1].Start != time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("Compare(): expected two monthly periods, actual %+v", c.Periods)
+	}
+	jan, feb := c.Periods[0], c.Periods[1]
+	if !equal(jan.Returns[0], 0.1) || !equal(jan.Returns[1], 0) || !equal(jan.Excess[1], -0.1) {
+		t.Errorf("Compare(): unexpected returns of january %+v", jan)
+	}
+	if !equal(feb.Returns[0], 0) || !equal(feb.Returns[1], 0.1) || !equal(feb.Excess[1], 0.1) || feb.Excess[0] != 0 {
+		t.Errorf("Compare(): unexpected returns of february %+v", feb)
+	}
+	if c.Outperformed[1] != 1 {
+		t.Errorf("Compare(): expected a period outperformed, actual %v", c.Outperformed)
+	}
+
+	if _, err := Compare(Monthly, runs[:1], curves[:1]); err == nil {
+		t.Errorf("Compare(): expected an error of a single run")
+	}
+	if _, err := Compare(Monthly, runs, curves[:1]); err == nil {
+		t.Errorf("Compare(): expected an error of the missing curve")
+	}
+}
+
+func TestComparisonReport(t *testing.T) {
+	jan30 := time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC)
+	runs := []Run{{ID: 1, Name: "base", Custom: map[string]float64{"edge": 0.5}}, {ID: 2, Name: "change"}}
+	c, err := Compare(Yearly, runs, [][]gbt.EquityPoint{curve(jan30, 100, 110), curve(jan30.AddDate(0, 0, 1), 50)})
+	if err != nil {
+		t.Fatalf("Compare(): unexpected error %v", err)
+	}
+
+	var buf bytes.Buffer
+	c.Print(&buf)
+	for _, exp := range []string{"#2 change", "Delta to #1 base", "edge", "0.5000        NaN", "2020", "#2 change outperformed in 0 of 1 periods"} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("Print(): expected %q, actual\n%s", exp, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := c.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV(): unexpected error %v", err)
+	}
+	exp := "Time,#1 base,#2 change\n2020-01-30T00:00:00Z,1,\n2020-01-31T00:00:00Z,1.1,1\n"
+	if buf.String() != exp {
+		t.Errorf("WriteCSV(): expected\n%s\nactual\n%s", exp, buf.String())
+	}
+}
+
+func TestStoreCompare(t *testing.T) {
+	stats := newTestStatistic(t)
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	first, err := s.Save("first", nil, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	second, err := s.Save("second", nil, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+
+	c, err := s.Compare(Monthly, first.ID, second.ID)
+	if err != nil {
+		t.Fatalf("Compare(): unexpected error %v", err)
+	}
+	if len(c.Equity) != len(stats.EquityCurve()) || len(c.Periods) != 12 || c.Outperformed[1] != 0 {
+		t.Errorf("Compare(): expected the same runs over 12 months, actual %d points %d periods", len(c.Equity), len(c.Periods))
+	}
+	if _, err := s.Compare(Monthly, first.ID, 42); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Compare(): expected the unknown run not found, actual %v", err)
+	}
+}
+
+// equal compares two floats, NaN equal to NaN.
+func equal(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,338 @@
+package store
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakedb is an in-memory database/sql driver for the tests, which understands the statements
+// of the store: CREATE TABLE, INSERT with columns, DELETE and SELECT with conditions like
+// col = ?, joined by AND, ORDER BY and LIMIT. The databases are kept by name.
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+var fakeDBs = struct {
+	sync.Mutex
+	m map[string]*fakeDB
+}{m: make(map[string]*fakeDB)}
+
+type fakeDB struct {
+	mu     sync.Mutex
+	tables map[string]*fakeTable
+	fail   string // statements with this prefix fail
+}
+
+type fakeTable struct {
+	autoID bool
+	nextID int64
+	rows   []map[string]driver.Value
+}
+
+// openFakeDB returns the database of a name.
+func openFakeDB(name string) *fakeDB {
+	fakeDBs.Lock()
+	defer fakeDBs.Unlock()
+	db, ok := fakeDBs.m[name]
+	if !ok {
+		db = &fakeDB{tables: make(map[string]*fakeTable)}
+		fakeDBs.m[name] = db
+	}
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{db: openFakeDB(name)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+// Begin keeps a copy of the tables to restore on rollback.
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	saved := make(map[string]fakeTable)
+	for name, t := range c.db.tables {
+		saved[name] = fakeTable{autoID: t.autoID, nextID: t.nextID, rows: append([]map[string]driver.Value(nil), t.rows...)}
+	}
+	return &fakeTx{db: c.db, saved: saved}, nil
+}
+
+type fakeTx struct {
+	db    *fakeDB
+	saved map[string]fakeTable
+}
+
+func (tx *fakeTx) Commit() error {
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.tables = make(map[string]*fakeTable)
+	for name, t := range tx.saved {
+		t := t
+		tx.db.tables[name] = &t
+	}
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if s.db.fail != "" && strings.HasPrefix(s.query, s.db.fail) {
+		return nil, errors.New("disk I/O error")
+	}
+	q := s.query
+
+	switch {
+	case strings.HasPrefix(q, "CREATE TABLE IF NOT EXISTS "):
+		name := strings.Fields(q)[5]
+		if _, ok := s.db.tables[name]; !ok {
+			s.db.tables[name] = &fakeTable{autoID: strings.Contains(q, "AUTOINCREMENT")}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(q, "CREATE INDEX "):
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(q, "INSERT INTO "):
+		t, err := s.table(strings.Fields(q)[2])
+		if err != nil {
+			return nil, err
+		}
+		columns := strings.Split(q[strings.Index(q, "(")+1:strings.Index(q, ")")], ", ")
+		if len(columns) != len(args) {
+			return nil, fmt.Errorf("%d values for %d columns", len(args), len(columns))
+		}
+		row := make(map[string]driver.Value)
+		for i, c := range columns {
+			row[c] = args[i]
+		}
+		if t.autoID {
+			t.nextID++
+			row["id"] = t.nextID
+		}
+		t.rows = append(t.rows, row)
+		return fakeResult(t.nextID), nil
+
+	case strings.HasPrefix(q, "DELETE FROM "):
+		t, err := s.table(strings.Fields(q)[2])
+		if err != nil {
+			return nil, err
+		}
+		match, _, err := where(q, args)
+		if err != nil {
+			return nil, err
+		}
+		var kept []map[string]driver.Value
+		for _, row := range t.rows {
+			if !match(row) {
+				kept = append(kept, row)
+			}
+		}
+		affected := len(t.rows) - len(kept)
+		t.rows = kept
+		return driver.RowsAffected(affected), nil
+	}
+	return nil, fmt.Errorf("unsupported statement %q", q)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	q := s.query
+	if !strings.HasPrefix(q, "SELECT ") {
+		return nil, fmt.Errorf("unsupported query %q", q)
+	}
+	from := strings.Index(q, " FROM ")
+	columns := strings.Split(q[len("SELECT "):from], ", ")
+	t, err := s.table(strings.Fields(q[from:])[1])
+	if err != nil {
+		return nil, err
+	}
+	match, rest, err := where(q, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]driver.Value
+	for _, row := range t.rows {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	if i := strings.Index(q, " ORDER BY "); i >= 0 {
+		order := q[i+len(" ORDER BY "):]
+		if j := strings.Index(order, " LIMIT "); j >= 0 {
+			order = order[:j]
+		}
+		keys := strings.Split(order, ", ")
+		sort.SliceStable(rows, func(a, b int) bool {
+			for _, key := range keys {
+				desc := strings.HasSuffix(key, " DESC")
+				c := compare(rows[a][strings.TrimSuffix(key, " DESC")], rows[b][strings.TrimSuffix(key, " DESC")])
+				if c != 0 {
+					return c < 0 != desc
+				}
+			}
+			return false
+		})
+	}
+	if strings.Contains(q, " LIMIT ?") {
+		if len(rest) != 1 {
+			return nil, errors.New("missing limit")
+		}
+		if n := int(rest[0].(int64)); n < len(rows) {
+			rows = rows[:n]
+		}
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+func (s *fakeStmt) table(name string) (*fakeTable, error) {
+	t, ok := s.db.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", name)
+	}
+	return t, nil
+}
+
+// where returns the match of the conditions of a statement and the remaining arguments.
+func where(q string, args []driver.Value) (func(map[string]driver.Value) bool, []driver.Value, error) {
+	i := strings.Index(q, " WHERE ")
+	if i < 0 {
+		return func(map[string]driver.Value) bool { return true }, args, nil
+	}
+	clause := q[i+len(" WHERE "):]
+	for _, end := range []string{" ORDER BY ", " LIMIT "} {
+		if j := strings.Index(clause, end); j >= 0 {
+			clause = clause[:j]
+		}
+	}
+
+	type condition struct {
+		column, op string
+		value      driver.Value
+	}
+	var conditions []condition
+	for _, c := range strings.Split(clause, " AND ") {
+		f := strings.Fields(c)
+		if len(f) != 3 || f[2] != "?" || len(args) == 0 {
+			return nil, nil, fmt.Errorf("unsupported condition %q", c)
+		}
+		conditions = append(conditions, condition{f[0], f[1], args[0]})
+		args = args[1:]
+	}
+
+	return func(row map[string]driver.Value) bool {
+		for _, c := range conditions {
+			v := compare(row[c.column], c.value)
+			ok := map[string]bool{"=": v == 0, "<": v < 0, "<=": v <= 0, ">": v > 0, ">=": v >= 0}[c.op]
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}, args, nil
+}
+
+// compare compares two values, null first.
+func compare(a, b driver.Value) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	if s, ok := a.(string); ok {
+		return strings.Compare(s, fmt.Sprint(b))
+	}
+	x, y := number(a), number(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func number(v driver.Value) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    []map[string]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, c := range r.columns {
+		dest[i] = r.rows[0][c]
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
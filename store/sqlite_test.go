@@ -0,0 +1,123 @@
+//go:build sqlite
+// +build sqlite
+
+package store
+
+// The tests of the sqlite tag run the store against a real SQLite database of the pure Go
+// driver modernc.org/sqlite, which is not a dependency of the module, e.g.
+//
+//	go get modernc.org/sqlite
+//	go test -tags sqlite ./store
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite opens a store of a SQLite file with a clock starting at day.
+func openSQLite(t *testing.T, path string, day time.Time) *Store {
+	s, err := Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open(): unexpected error %v", err)
+	}
+	s.now = func() time.Time {
+		day = day.Add(24 * time.Hour)
+		return day
+	}
+	return s
+}
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "runs.db")
+
+	stats := newTestStatistic(t)
+	s := openSQLite(t, path, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	first, err := s.Save("sma-cross", map[string]interface{}{"fast": 5}, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	flat, err := s.Save("flat", nil, &gbt.Statistic{})
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close(): unexpected error %v", err)
+	}
+
+	// the runs persist in the file, the tables are not created again
+	s = openSQLite(t, path, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	stored, err := s.Run(first.ID)
+	if err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if stored.Name != "sma-cross" || !stored.Created.Equal(first.Created) || stored.Metrics != first.Metrics ||
+		string(stored.Config) != `{"fast":5}` {
+		t.Errorf("Run(): expected the saved run %+v, actual %+v", first, stored)
+	}
+	if stored, err := s.Run(flat.ID); err != nil || !math.IsNaN(stored.Metrics.Sharpe) || stored.Config != nil {
+		t.Errorf("Run(): expected a NaN sharpe ratio without config, actual %+v %v", stored, err)
+	}
+
+	curve, err := s.Equity(first.ID)
+	if err != nil {
+		t.Fatalf("Equity(): unexpected error %v", err)
+	}
+	expCurve := stats.EquityCurve()
+	if len(curve) != len(expCurve) || len(curve) == 0 {
+		t.Fatalf("Equity(): expected %d points, actual %d", len(expCurve), len(curve))
+	}
+	for i, p := range curve {
+		exp := expCurve[i]
+		if !p.Time.Equal(exp.Time) || p.Equity != exp.Equity || p.Drawdown != exp.Drawdown {
+			t.Errorf("Equity(): expected point %+v, actual %+v", exp, p)
+			break
+		}
+	}
+
+	trades, err := s.Trades(first.ID)
+	if err != nil {
+		t.Fatalf("Trades(): unexpected error %v", err)
+	}
+	expTrades := stats.Blotter()
+	if len(trades) != len(expTrades) {
+		t.Fatalf("Trades(): expected %d trades, actual %d", len(expTrades), len(trades))
+	}
+	for i, trade := range trades {
+		exp := expTrades[i]
+		if !trade.Time.Equal(exp.Time) || trade.Symbol != exp.Symbol || trade.Direction != exp.Direction ||
+			trade.Qty != exp.Qty || trade.Price != exp.Price || trade.FillID != exp.FillID {
+			t.Errorf("Trades(): expected trade %+v, actual %+v", exp, trade)
+		}
+	}
+
+	runs, err := s.Runs(Query{Name: "sma-cross", OrderBy: "sharpe", Desc: true, Limit: 1})
+	if err != nil || len(runs) != 1 || runs[0].ID != first.ID {
+		t.Errorf("Runs(): expected the run sma-cross, actual %v %v", runs, err)
+	}
+
+	if _, err := s.Compare(Monthly, first.ID, first.ID); err != nil {
+		t.Errorf("Compare(): unexpected error %v", err)
+	}
+
+	if err := s.Delete(first.ID); err != nil {
+		t.Fatalf("Delete(): unexpected error %v", err)
+	}
+	if _, err := s.Trades(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Trades(): expected the deleted run not found, actual %v", err)
+	}
+}
@@ -0,0 +1,452 @@
+// Package store persists the runs of backtests, their configuration, metrics, equity curve
+// and trades, into a SQLite database, so the history of the runs can be queried and compared.
+//
+// The store uses database/sql, the SQLite driver is registered by the application, e.g.
+//
+//	import _ "modernc.org/sqlite" // registers the driver sqlite
+//
+//	s, err := store.Open("sqlite", "runs.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer s.Close()
+//	run, err := s.Save("sma-cross", cfg, statistic)
+//
+// The tables are created on open, the statements use the SQL dialect of SQLite.
+//
+// Compare reports stored runs side by side, e.g. a strategy before and after a change.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+)
+
+// ErrNotFound is returned for an unknown run.
+var ErrNotFound = errors.New("run not found")
+
+// schema creates the tables, a statement at a time.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		created INTEGER NOT NULL,
+		config TEXT,
+		total_return REAL,
+		max_drawdown REAL,
+		max_drawdown_duration INTEGER,
+		sharpe REAL,
+		sortino REAL,
+		trades INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS runs_name ON runs (name, created)`,
+	`CREATE TABLE IF NOT EXISTS equity (
+		run INTEGER NOT NULL REFERENCES runs (id),
+		time INTEGER NOT NULL,
+		equity REAL,
+		period_return REAL,
+		drawdown REAL
+	)`,
+	`CREATE INDEX IF NOT EXISTS equity_run ON equity (run, time)`,
+	`CREATE TABLE IF NOT EXISTS trades (
+		run INTEGER NOT NULL REFERENCES runs (id),
+		time INTEGER NOT NULL,
+		symbol TEXT,
+		direction INTEGER,
+		qty REAL,
+		price REAL,
+		cost REAL,
+		fill_id INTEGER,
+		order_id INTEGER,
+		signal_id INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS trades_run ON trades (run, time)`,
+	`CREATE TABLE IF NOT EXISTS metrics (
+		run INTEGER NOT NULL REFERENCES runs (id),
+		name TEXT NOT NULL,
+		value REAL
+	)`,
+	`CREATE INDEX IF NOT EXISTS metrics_run ON metrics (run)`,
+}
+
+// orderColumns are the columns to order the runs by.
+var orderColumns = map[string]string{
+	"created":     "created",
+	"return":      "total_return",
+	"maxDrawdown": "max_drawdown",
+	"sharpe":      "sharpe",
+	"sortino":     "sortino",
+	"trades":      "trades",
+}
+
+// runColumns are the columns of a run, in the order of scan.
+const runColumns = "id, name, created, config, total_return, max_drawdown, max_drawdown_duration, sharpe, sortino, trades"
+
+// Run is a stored run.
+type Run struct {
+	ID      int64
+	Name    string
+	Created time.Time
+	Config  json.RawMessage // the configuration of the run as json, e.g. a config.Config
+	Metrics Metrics
+	Custom  map[string]float64 // the registered custom metrics of the run by name
+}
+
+// Metrics are the metrics of a run. Metrics which are not defined, e.g. the sharpe ratio
+// without volatility, are NaN.
+type Metrics struct {
+	Return              float64
+	MaxDrawdown         float64
+	MaxDrawdownDuration time.Duration
+	Sharpe              float64
+	Sortino             float64
+	Trades              int
+}
+
+// NewMetrics returns the metrics of a statistic.
+func NewMetrics(stats *gbt.Statistic) Metrics {
+	m := Metrics{
+		MaxDrawdown:         stats.MaxDrawdown(),
+		MaxDrawdownDuration: stats.MaxDrawdownDuration(),
+		Sharpe:              stats.SharpRatio(0),
+		Sortino:             stats.SortinoRatio(0),
+		Trades:              len(stats.Transactions()),
+	}
+	m.Return, _ = stats.TotalEquityReturn()
+	return m
+}
+
+// Query selects stored runs.
+type Query struct {
+	Name  string    // runs of the name, all if empty
+	Since time.Time // runs created at or after, if set
+	Until time.Time // runs created before, if set
+	// OrderBy orders the runs by created, return, maxDrawdown, sharpe, sortino or trades,
+	// the newest run first if empty
+	OrderBy string
+	Desc    bool
+	Limit   int // maximum number of runs, all if 0
+}
+
+// Store is a store of runs in a database.
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Open opens the database of a driver, e.g. the path of a SQLite file, as store.
+func Open(driver, dsn string) (*Store, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New creates a store in an open database and creates the missing tables.
+func New(db *sql.DB) (*Store, error) {
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("could not create the tables: %v", err)
+		}
+	}
+	return &Store{db: db, now: time.Now}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save stores a run with its configuration, encoded as json, and the metrics, including the
+// custom metrics, the equity curve and the trades of its statistic.
+func (s *Store) Save(name string, config interface{}, stats *gbt.Statistic) (Run, error) {
+	if stats == nil {
+		return Run{}, errors.New("could not save run without statistic")
+	}
+	var raw json.RawMessage
+	if config != nil {
+		var err error
+		if raw, err = json.Marshal(config); err != nil {
+			return Run{}, fmt.Errorf("could not encode the config of run %s: %v", name, err)
+		}
+	}
+	run := Run{Name: name, Created: s.now(), Config: raw, Metrics: NewMetrics(stats), Custom: stats.CustomMetrics()}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Run{}, err
+	}
+	if err := s.save(tx, &run, stats); err != nil {
+		tx.Rollback()
+		return Run{}, fmt.Errorf("could not save run %s: %v", name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return Run{}, fmt.Errorf("could not save run %s: %v", name, err)
+	}
+	return run, nil
+}
+
+// save inserts a run, its equity curve and trades.
+func (s *Store) save(tx *sql.Tx, run *Run, stats *gbt.Statistic) error {
+	m := run.Metrics
+	var config interface{}
+	if run.Config != nil {
+		config = string(run.Config)
+	}
+	res, err := tx.Exec(`INSERT INTO runs (name, created, config, total_return, max_drawdown, max_drawdown_duration, sharpe, sortino, trades) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Name, run.Created.UnixNano(), config, nullable(m.Return), nullable(m.MaxDrawdown), int64(m.MaxDrawdownDuration), nullable(m.Sharpe), nullable(m.Sortino), m.Trades)
+	if err != nil {
+		return err
+	}
+	if run.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	equity, err := tx.Prepare(`INSERT INTO equity (run, time, equity, period_return, drawdown) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer equity.Close()
+	for _, p := range stats.EquityCurve() {
+		if _, err := equity.Exec(run.ID, p.Time.UnixNano(), nullable(p.Equity), nullable(p.Return), nullable(p.Drawdown)); err != nil {
+			return err
+		}
+	}
+
+	trades, err := tx.Prepare(`INSERT INTO trades (run, time, symbol, direction, qty, price, cost, fill_id, order_id, signal_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer trades.Close()
+	for _, t := range stats.Blotter() {
+		if _, err := trades.Exec(run.ID, t.Time.UnixNano(), t.Symbol, int(t.Direction), t.Qty, t.Price, t.Cost, t.FillID, t.OrderID, t.SignalID); err != nil {
+			return err
+		}
+	}
+
+	for name, v := range run.Custom {
+		if _, err := tx.Exec(`INSERT INTO metrics (run, name, value) VALUES (?, ?, ?)`, run.ID, name, nullable(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run returns a stored run.
+func (s *Store) Run(id int64) (Run, error) {
+	runs, err := s.query(`SELECT `+runColumns+` FROM runs WHERE id = ?`, id)
+	if err != nil {
+		return Run{}, err
+	}
+	if len(runs) == 0 {
+		return Run{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return runs[0], nil
+}
+
+// Runs returns the stored runs selected by a query.
+func (s *Store) Runs(q Query) ([]Run, error) {
+	var where []string
+	var args []interface{}
+	if q.Name != "" {
+		where = append(where, "name = ?")
+		args = append(args, q.Name)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "created >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "created < ?")
+		args = append(args, q.Until.UnixNano())
+	}
+
+	stmt := `SELECT ` + runColumns + ` FROM runs`
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	if q.OrderBy == "" {
+		stmt += " ORDER BY id DESC"
+	} else {
+		column, ok := orderColumns[q.OrderBy]
+		if !ok {
+			return nil, fmt.Errorf("could not order runs by unknown metric %q", q.OrderBy)
+		}
+		stmt += " ORDER BY " + column
+		if q.Desc {
+			stmt += " DESC"
+		}
+		stmt += ", id"
+	}
+	if q.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return s.query(stmt, args...)
+}
+
+// query returns the runs of a select of the run columns.
+func (s *Store) query(stmt string, args ...interface{}) ([]Run, error) {
+	rows, err := s.db.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query runs: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		var created, duration, trades int64
+		var config sql.NullString
+		var ret, drawdown, sharpe, sortino sql.NullFloat64
+		if err := rows.Scan(&r.ID, &r.Name, &created, &config, &ret, &drawdown, &duration, &sharpe, &sortino, &trades); err != nil {
+			return nil, fmt.Errorf("could not read run: %v", err)
+		}
+		r.Created = time.Unix(0, created)
+		if config.Valid {
+			r.Config = json.RawMessage(config.String)
+		}
+		r.Metrics = Metrics{
+			Return:              float(ret),
+			MaxDrawdown:         float(drawdown),
+			MaxDrawdownDuration: time.Duration(duration),
+			Sharpe:              float(sharpe),
+			Sortino:             float(sortino),
+			Trades:              int(trades),
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range runs {
+		if err := s.custom(&runs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// custom reads the custom metrics of a run.
+func (s *Store) custom(r *Run) error {
+	rows, err := s.db.Query(`SELECT name, value FROM metrics WHERE run = ?`, r.ID)
+	if err != nil {
+		return fmt.Errorf("could not query the metrics of run %d: %v", r.ID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var value sql.NullFloat64
+		if err := rows.Scan(&name, &value); err != nil {
+			return fmt.Errorf("could not read the metrics of run %d: %v", r.ID, err)
+		}
+		if r.Custom == nil {
+			r.Custom = make(map[string]float64)
+		}
+		r.Custom[name] = float(value)
+	}
+	return rows.Err()
+}
+
+// Equity returns the equity curve of a stored run.
+func (s *Store) Equity(id int64) ([]gbt.EquityPoint, error) {
+	if _, err := s.Run(id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT time, equity, period_return, drawdown FROM equity WHERE run = ? ORDER BY time`, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not query the equity of run %d: %v", id, err)
+	}
+	defer rows.Close()
+
+	var curve []gbt.EquityPoint
+	for rows.Next() {
+		var t int64
+		var equity, ret, drawdown sql.NullFloat64
+		if err := rows.Scan(&t, &equity, &ret, &drawdown); err != nil {
+			return nil, fmt.Errorf("could not read the equity of run %d: %v", id, err)
+		}
+		curve = append(curve, gbt.EquityPoint{Time: time.Unix(0, t), Equity: float(equity), Return: float(ret), Drawdown: float(drawdown)})
+	}
+	return curve, rows.Err()
+}
+
+// Trades returns the trade blotter of a stored run, without the tags and the data events.
+func (s *Store) Trades(id int64) ([]gbt.BlotterEntry, error) {
+	if _, err := s.Run(id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT time, symbol, direction, qty, price, cost, fill_id, order_id, signal_id FROM trades WHERE run = ? ORDER BY time`, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not query the trades of run %d: %v", id, err)
+	}
+	defer rows.Close()
+
+	var trades []gbt.BlotterEntry
+	for rows.Next() {
+		var t gbt.BlotterEntry
+		var timestamp, direction int64
+		if err := rows.Scan(&timestamp, &t.Symbol, &direction, &t.Qty, &t.Price, &t.Cost, &t.FillID, &t.OrderID, &t.SignalID); err != nil {
+			return nil, fmt.Errorf("could not read the trades of run %d: %v", id, err)
+		}
+		t.Time = time.Unix(0, timestamp)
+		t.Direction = gbt.Direction(direction)
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+// Delete removes a stored run with its equity curve, trades and custom metrics.
+func (s *Store) Delete(id int64) error {
+	if _, err := s.Run(id); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`DELETE FROM metrics WHERE run = ?`,
+		`DELETE FROM trades WHERE run = ?`,
+		`DELETE FROM equity WHERE run = ?`,
+		`DELETE FROM runs WHERE id = ?`,
+	} {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not delete run %d: %v", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// nullable returns nil for the values which are not stored as number.
+func nullable(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+// float returns the value of a nullable number, NaN for null.
+func float(f sql.NullFloat64) float64 {
+	if !f.Valid {
+		return math.NaN()
+	}
+	return f.Float64
+}
@@ -0,0 +1,295 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	gbt "github.com/dirkolbrich/gobacktest"
+	"github.com/dirkolbrich/gobacktest/data"
+	"github.com/dirkolbrich/gobacktest/strategy"
+)
+
+// newTestStatistic runs a trading backtest of the test data and returns its statistic.
+func newTestStatistic(t *testing.T) *gbt.Statistic {
+	d := &data.BarEventFromCSVFile{FileDir: "../examples/testdata/bar/"}
+	if err := d.Load([]string{"SDF.DE"}); err != nil {
+		t.Fatal(err)
+	}
+	var stream []gbt.DataEvent
+	for _, e := range d.Stream() {
+		if e.Time().Year() == 2016 {
+			stream = append(stream, e)
+		}
+	}
+	d.SetStream(stream)
+
+	portfolio := gbt.NewPortfolio()
+	portfolio.SetSizeManager(&gbt.Size{DefaultSize: 100, DefaultValue: 10000})
+
+	test := gbt.New()
+	test.SetSymbols([]string{"SDF.DE"})
+	test.SetData(d)
+	test.SetPortfolio(portfolio)
+	test.SetStrategy(strategy.MovingAverageCross(5, 20))
+	if err := test.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return test.Stats().(*gbt.Statistic)
+}
+
+// newTestStore opens an empty store of the fake driver with a clock starting at day.
+func newTestStore(t *testing.T, day time.Time) *Store {
+	s, err := Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatalf("Open(): unexpected error %v", err)
+	}
+	s.now = func() time.Time {
+		day = day.Add(24 * time.Hour)
+		return day
+	}
+	return s
+}
+
+func TestSave(t *testing.T) {
+	stats := newTestStatistic(t)
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	config := map[string]interface{}{"strategy": "sma-cross", "fast": 5}
+	run, err := s.Save("sma-cross", config, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	if run.ID != 1 || run.Metrics.Trades == 0 {
+		t.Errorf("Save(): expected the first run with trades, actual %+v", run)
+	}
+
+	stored, err := s.Run(run.ID)
+	if err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if stored.Name != "sma-cross" || !stored.Created.Equal(run.Created) || stored.Metrics != run.Metrics {
+		t.Errorf("Run(): expected the saved run %+v, actual %+v", run, stored)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(stored.Config, &decoded); err != nil || decoded["strategy"] != "sma-cross" {
+		t.Errorf("Run(): expected the config, actual %s %v", stored.Config, err)
+	}
+
+	curve, err := s.Equity(run.ID)
+	if err != nil {
+		t.Fatalf("Equity(): unexpected error %v", err)
+	}
+	expCurve := stats.EquityCurve()
+	if len(curve) != len(expCurve) || len(curve) == 0 {
+		t.Fatalf("Equity(): expected %d points, actual %d", len(expCurve), len(curve))
+	}
+	last, expLast := curve[len(curve)-1], expCurve[len(expCurve)-1]
+	if !last.Time.Equal(expLast.Time) || last.Equity != expLast.Equity || last.Drawdown != expLast.Drawdown {
+		t.Errorf("Equity(): expected the last point %+v, actual %+v", expLast, last)
+	}
+
+	trades, err := s.Trades(run.ID)
+	if err != nil {
+		t.Fatalf("Trades(): unexpected error %v", err)
+	}
+	expTrades := stats.Blotter()
+	if len(trades) != len(expTrades) {
+		t.Fatalf("Trades(): expected %d trades, actual %d", len(expTrades), len(trades))
+	}
+	for i, trade := range trades {
+		exp := expTrades[i]
+		if !trade.Time.Equal(exp.Time) || trade.Symbol != exp.Symbol || trade.Direction != exp.Direction ||
+			trade.Qty != exp.Qty || trade.Price != exp.Price || trade.FillID != exp.FillID {
+			t.Errorf("Trades(): expected trade %+v, actual %+v", exp, trade)
+		}
+	}
+
+	if _, err := s.Save("empty", nil, nil); err == nil {
+		t.Errorf("Save(): expected an error without statistic")
+	}
+	if _, err := s.Save("invalid", math.NaN(), stats); err == nil || !strings.Contains(err.Error(), "could not encode the config") {
+		t.Errorf("Save(): expected an error of the config, actual %v", err)
+	}
+}
+
+func TestSaveUndefinedMetrics(t *testing.T) {
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	// a statistic without equity has no sharpe ratio
+	run, err := s.Save("flat", nil, &gbt.Statistic{})
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	stored, err := s.Run(run.ID)
+	if err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+	if !math.IsNaN(stored.Metrics.Sharpe) || stored.Config != nil {
+		t.Errorf("Run(): expected a NaN sharpe ratio without config, actual %+v", stored)
+	}
+}
+
+func TestSaveRollback(t *testing.T) {
+	stats := newTestStatistic(t)
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	openFakeDB(t.Name()).fail = "INSERT INTO trades"
+	if _, err := s.Save("sma-cross", nil, stats); err == nil || !strings.Contains(err.Error(), "disk I/O error") {
+		t.Fatalf("Save(): expected the failed insert, actual %v", err)
+	}
+	if _, err := s.Run(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Run(): expected the rolled back run, actual %v", err)
+	}
+	if curve, err := s.Equity(1); !errors.Is(err, ErrNotFound) || curve != nil {
+		t.Errorf("Equity(): expected no equity, actual %v %v", curve, err)
+	}
+}
+
+func TestSaveCustomMetrics(t *testing.T) {
+	stats := newTestStatistic(t)
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	trades := gbt.MetricCalculatorFunc(func(equity []gbt.EquityPoint, trades []gbt.FillEvent) float64 {
+		return float64(len(trades))
+	})
+	undefined := gbt.MetricCalculatorFunc(func(equity []gbt.EquityPoint, trades []gbt.FillEvent) float64 {
+		return math.NaN()
+	})
+	if err := gbt.RegisterMetric("trades", trades); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("trades")
+	if err := gbt.RegisterMetric("undefined", undefined); err != nil {
+		t.Fatal(err)
+	}
+	defer gbt.UnregisterMetric("undefined")
+
+	run, err := s.Save("custom", nil, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	runs, err := s.Runs(Query{})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Runs(): expected the saved run, actual %v %v", runs, err)
+	}
+	custom := runs[0].Custom
+	if len(custom) != 2 || custom["trades"] != float64(run.Metrics.Trades) || !math.IsNaN(custom["undefined"]) {
+		t.Errorf("Runs(): expected the custom metrics, actual %v", custom)
+	}
+
+	if err := s.Delete(run.ID); err != nil {
+		t.Fatalf("Delete(): unexpected error %v", err)
+	}
+	if rows := openFakeDB(t.Name()).tables["metrics"].rows; len(rows) != 0 {
+		t.Errorf("Delete(): expected no custom metrics, actual %v", rows)
+	}
+}
+
+func TestRuns(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestStore(t, day)
+	defer s.Close()
+
+	// the runs are created on the following days, their returns are set in the table
+	returns := []float64{3, 1, 5, 2, 4}
+	for i, name := range []string{"a", "b", "a", "b", "a"} {
+		if _, err := s.Save(name, nil, &gbt.Statistic{}); err != nil {
+			t.Fatalf("Save(): unexpected error %v", err)
+		}
+		openFakeDB(t.Name()).tables["runs"].rows[i]["total_return"] = returns[i]
+	}
+
+	// testCases is a table for testing the queries of runs
+	var testCases = []struct {
+		msg    string
+		query  Query
+		expIDs []int64
+		expErr string
+	}{
+		{"all runs newest first:", Query{}, []int64{5, 4, 3, 2, 1}, ""},
+		{"by name:", Query{Name: "a"}, []int64{5, 3, 1}, ""},
+		{"created since:", Query{Since: day.Add(3 * 24 * time.Hour)}, []int64{5, 4, 3}, ""},
+		{"created until:", Query{Until: day.Add(3 * 24 * time.Hour)}, []int64{2, 1}, ""},
+		{"best return:", Query{OrderBy: "return", Desc: true, Limit: 2}, []int64{3, 5}, ""},
+		{"worst return of name:", Query{Name: "b", OrderBy: "return"}, []int64{2, 4}, ""},
+		{"by created:", Query{OrderBy: "created", Limit: 1}, []int64{1}, ""},
+		{"unknown metric:", Query{OrderBy: "alpha"}, nil, "unknown metric"},
+	}
+
+	for _, tc := range testCases {
+		runs, err := s.Runs(tc.query)
+		if tc.expErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				t.Errorf("%v Runs(): expected error %q, actual %v", tc.msg, tc.expErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v Runs(): unexpected error %v", tc.msg, err)
+			continue
+		}
+		var ids []int64
+		for _, r := range runs {
+			ids = append(ids, r.ID)
+		}
+		if len(ids) != len(tc.expIDs) {
+			t.Errorf("%v Runs(): expected %v, actual %v", tc.msg, tc.expIDs, ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tc.expIDs[i] {
+				t.Errorf("%v Runs(): expected %v, actual %v", tc.msg, tc.expIDs, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	stats := newTestStatistic(t)
+	s := newTestStore(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer s.Close()
+
+	first, err := s.Save("first", nil, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+	second, err := s.Save("second", nil, stats)
+	if err != nil {
+		t.Fatalf("Save(): unexpected error %v", err)
+	}
+
+	if err := s.Delete(first.ID); err != nil {
+		t.Fatalf("Delete(): unexpected error %v", err)
+	}
+	if _, err := s.Run(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Run(): expected the deleted run not found, actual %v", err)
+	}
+	if _, err := s.Trades(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Trades(): expected the deleted run not found, actual %v", err)
+	}
+	if err := s.Delete(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete(): expected the deleted run not found, actual %v", err)
+	}
+
+	db := openFakeDB(t.Name())
+	for _, table := range []string{"equity", "trades"} {
+		for _, row := range db.tables[table].rows {
+			if row["run"] != second.ID {
+				t.Errorf("Delete(): expected only the %s of the second run, actual run %v", table, row["run"])
+				break
+			}
+		}
+	}
+	if trades, err := s.Trades(second.ID); err != nil || len(trades) != len(stats.Blotter()) {
+		t.Errorf("Trades(): expected the trades of the second run, actual %d %v", len(trades), err)
+	}
+}
@@ -23,6 +23,8 @@ type Strategy struct {
 	portfolio PortfolioHandler
 	event     DataEvent
 	signals   []SignalEvent
+	warmUp    int
+	schedule  Schedule
 }
 
 // NewStrategy return a new strategy node ready to use.
@@ -37,3 +37,12 @@ func MovingAverageCross(short, long int) *gbt.Strategy {
 
 	return strategy
 }
+
+// MovingAverageCrossRule is the vectorized form of MovingAverageCross for fast parameter sweeps
+// with gbt.RunVectorized. It is long while the short SMA is above the long SMA and flat otherwise.
+func MovingAverageCrossRule(short, long int) gbt.VectorRule {
+	return gbt.CrossRule(
+		func(v *gbt.VectorData) []float64 { return v.SMA(short) },
+		func(v *gbt.VectorData) []float64 { return v.SMA(long) },
+	)
+}
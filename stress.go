@@ -0,0 +1,204 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Scenario is the basic interface for a stress scenario, which transforms the data stream of a backtest.
+type Scenario interface {
+	Name() string
+	Apply([]DataEvent) []DataEvent
+}
+
+// PriceShock applies a price gap to the symbols on a date, e.g. Shock -0.2 for a 20% drop.
+// All prices from the date on are shifted by the gap. Without Symbols all symbols are shocked.
+type PriceShock struct {
+	Label   string
+	Date    time.Time
+	Shock   float64
+	Symbols []string
+}
+
+// Name returns the name of the scenario.
+func (s *PriceShock) Name() string {
+	return s.Label
+}
+
+// Apply returns a copy of the data stream with the price shock applied.
+func (s *PriceShock) Apply(stream []DataEvent) []DataEvent {
+	shocked := make([]DataEvent, len(stream))
+	for i, e := range stream {
+		if e.Time().Before(s.Date) || !containsSymbol(s.Symbols, e.Symbol()) {
+			shocked[i] = scaleEvent(e, 1)
+			continue
+		}
+		shocked[i] = scaleEvent(e, 1+s.Shock)
+	}
+	return shocked
+}
+
+// VolatilityShock multiplies the returns of the symbols between Start and End by Factor,
+// e.g. 2 to double the volatility for a month. After End the prices keep the level reached.
+// Without Symbols all symbols are shocked.
+type VolatilityShock struct {
+	Label   string
+	Start   time.Time
+	End     time.Time
+	Factor  float64
+	Symbols []string
+}
+
+// Name returns the name of the scenario.
+func (s *VolatilityShock) Name() string {
+	return s.Label
+}
+
+// Apply returns a copy of the data stream with the amplified returns.
+func (s *VolatilityShock) Apply(stream []DataEvent) []DataEvent {
+	lastPrice := make(map[string]float64)
+	ratio := make(map[string]float64)
+
+	shocked := make([]DataEvent, len(stream))
+	for i, e := range stream {
+		symbol := e.Symbol()
+		price := e.Price()
+
+		r, ok := ratio[symbol]
+		if !ok {
+			r = 1
+		}
+
+		inWindow := !e.Time().Before(s.Start) && !e.Time().After(s.End)
+		if inWindow && containsSymbol(s.Symbols, symbol) && lastPrice[symbol] != 0 && price != 0 {
+			// amplify the return of the original prices on the shocked price level
+			ret := price/lastPrice[symbol] - 1
+			shockedPrice := lastPrice[symbol] * r * (1 + s.Factor*ret)
+			r = shockedPrice / price
+		}
+
+		ratio[symbol] = r
+		lastPrice[symbol] = price
+		shocked[i] = scaleEvent(e, r)
+	}
+	return shocked
+}
+
+// StressResult reports the impact of a stress scenario against the baseline run.
+type StressResult struct {
+	Scenario    string
+	FinalEquity float64
+	MaxDrawdown float64
+	Impact      float64 // change of the final equity against the baseline
+	MarginCalls int
+	Halts       int
+}
+
+// Stress runs the backtest once without changes as baseline and once for each scenario
+// on a transformed copy of the data stream. The first result is the baseline.
+// The original data of the backtest is left untouched, the portfolio and statistic are reset afterwards.
+func (t *Backtest) Stress(scenarios ...Scenario) ([]StressResult, error) {
+	if t.data == nil {
+		return nil, errors.New("could not run stress test, no data set")
+	}
+
+	// the complete data stream, whether the backtest did run or not
+	stream := append(append([]DataEvent{}, t.data.History()...), t.data.Stream()...)
+	original := t.data
+	defer func() {
+		t.data = original
+		t.resetRun()
+	}()
+
+	var results []StressResult
+	runs := append([]Scenario{&baseline{}}, scenarios...)
+	for _, scenario := range runs {
+		if err := t.resetRun(); err != nil {
+			return results, err
+		}
+
+		data := &Data{}
+		data.SetStream(scenario.Apply(stream))
+		t.data = data
+
+		if err := t.Run(); err != nil {
+			return results, err
+		}
+
+		result := StressResult{
+			Scenario:    scenario.Name(),
+			FinalEquity: t.portfolio.Value(),
+			MaxDrawdown: t.statistic.MaxDrawdown(),
+		}
+		for _, e := range t.statistic.Events() {
+			switch e.(type) {
+			case *MarginCall:
+				result.MarginCalls++
+			case *TradingHalted:
+				result.Halts++
+			}
+		}
+		if len(results) > 0 && results[0].FinalEquity != 0 {
+			impact := result.FinalEquity/results[0].FinalEquity - 1
+			result.Impact = math.Round(impact*math.Pow10(DP)) / math.Pow10(DP)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// baseline is the scenario without changes.
+type baseline struct{}
+
+// Name returns the name of the baseline.
+func (b *baseline) Name() string {
+	return "baseline"
+}
+
+// Apply returns a copy of the unchanged data stream.
+func (b *baseline) Apply(stream []DataEvent) []DataEvent {
+	copied := make([]DataEvent, len(stream))
+	for i, e := range stream {
+		copied[i] = scaleEvent(e, 1)
+	}
+	return copied
+}
+
+// scaleEvent returns a copy of a bar or tick event with all prices multiplied by a factor,
+// the copy has empty metrics. Other events are returned unchanged.
+func scaleEvent(e DataEvent, factor float64) DataEvent {
+	switch event := e.(type) {
+	case *Bar:
+		bar := *event
+		bar.Metric = Metric{}
+		bar.Open *= factor
+		bar.High *= factor
+		bar.Low *= factor
+		bar.Close *= factor
+		bar.AdjClose *= factor
+		return &bar
+	case *Tick:
+		tick := *event
+		tick.Metric = Metric{}
+		tick.Bid *= factor
+		tick.Ask *= factor
+		return &tick
+	}
+	return e
+}
+
+// containsSymbol checks if a symbol is in a list, an empty list contains all symbols.
+func containsSymbol(symbols []string, symbol string) bool {
+	if len(symbols) == 0 {
+		return true
+	}
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
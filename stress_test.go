@@ -0,0 +1,101 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// buyOnceStrategy buys on the first data event.
+type buyOnceStrategy struct {
+	*Strategy
+	bought bool
+}
+
+func (s *buyOnceStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	if s.bought {
+		return nil, nil
+	}
+	s.bought = true
+	return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}}, nil
+}
+
+func (s *buyOnceStrategy) Reset() error {
+	s.bought = false
+	return nil
+}
+
+// newStressStream creates a stream of daily bars with the given close prices.
+func newStressStream(closes ...float64) []DataEvent {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	var stream []DataEvent
+	for i, c := range closes {
+		stream = append(stream, &Bar{Event: Event{timestamp: day.AddDate(0, 0, i), symbol: "TEST.DE"}, Close: c})
+	}
+	return stream
+}
+
+func TestPriceShockApply(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	stream := newStressStream(100, 100, 100)
+
+	shock := &PriceShock{Date: day.AddDate(0, 0, 1), Shock: -0.2}
+	shocked := shock.Apply(stream)
+
+	var exp = []float64{100, 80, 80}
+	for i, e := range shocked {
+		if e.Price() != exp[i] {
+			t.Errorf("Apply(): expected price %v at %v, actual %v", exp[i], i, e.Price())
+		}
+	}
+	if stream[1].Price() != 100 {
+		t.Errorf("Apply(): expected original stream to be unchanged")
+	}
+}
+
+func TestVolatilityShockApply(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	stream := newStressStream(100, 110, 99, 99, 108.9)
+
+	shock := &VolatilityShock{Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 2), Factor: 2}
+	shocked := shock.Apply(stream)
+
+	// returns of 10% and -10% are doubled, afterwards the original returns apply on the new level
+	var exp = []float64{100, 120, 96, 96, 105.6}
+	for i, e := range shocked {
+		if price := math.Round(e.Price()*math.Pow10(DP)) / math.Pow10(DP); price != exp[i] {
+			t.Errorf("Apply(): expected price %v at %v, actual %v", exp[i], i, price)
+		}
+	}
+}
+
+func TestBacktestStress(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100, 100, 100))
+
+	test := New()
+	test.SetData(data)
+	strategy := &buyOnceStrategy{Strategy: NewStrategy("buy once")}
+	test.SetStrategy(strategy)
+
+	scenario := &PriceShock{Label: "gap", Date: day.AddDate(0, 0, 2), Shock: -0.2}
+
+	// the strategy is reset before every run
+	results, err := test.Stress(scenario)
+	if err != nil {
+		t.Fatalf("Stress(): unexpected error %v", err)
+	}
+
+	if len(results) != 2 || results[0].Scenario != "baseline" || results[1].Scenario != "gap" {
+		t.Fatalf("Stress(): expected baseline and gap results, actual %#v", results)
+	}
+	if results[0].FinalEquity != 100000 || results[1].FinalEquity != 99800 || results[1].Impact != -0.002 {
+		t.Errorf("Stress(): unexpected results %#v", results)
+	}
+	if len(data.Stream()) != 4 {
+		t.Errorf("Stress(): expected original data to be untouched, actual %v events", len(data.Stream()))
+	}
+}
@@ -0,0 +1,73 @@
+package gobacktest
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tagger declares a key-value metadata bag of an event, e.g. the name of a setup or the version of a model.
+// The tags of a signal propagate to its order and the tags of an order to its fills.
+type Tagger interface {
+	Tags() map[string]string
+	Tag(string) (string, bool)
+	SetTag(string, string)
+}
+
+// Tags returns a copy of the tags of the event, nil if the event is not tagged.
+func (e Event) Tags() map[string]string {
+	if len(e.tags) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(e.tags))
+	for k, v := range e.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// Tag returns the value of a tag of the event.
+func (e Event) Tag(key string) (string, bool) {
+	v, ok := e.tags[key]
+	return v, ok
+}
+
+// SetTag tags the event with a key and value.
+func (e *Event) SetTag(key, value string) {
+	// check for nil map, else initialise the map
+	if e.tags == nil {
+		e.tags = make(map[string]string)
+	}
+	e.tags[key] = value
+}
+
+// copyTags passes the tags of an event on to the event caused by it.
+func copyTags(from, to interface{}) {
+	src, ok := from.(Tagger)
+	if !ok {
+		return
+	}
+	dst, ok := to.(Tagger)
+	if !ok {
+		return
+	}
+
+	for k, v := range src.Tags() {
+		dst.SetTag(k, v)
+	}
+}
+
+// formatTags formats tags as sorted key=value pairs, e.g. for a trade report.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + tags[k]
+	}
+	return strings.Join(pairs, " ")
+}
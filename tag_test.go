@@ -0,0 +1,90 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventTags(t *testing.T) {
+	e := &Event{}
+	if tags := e.Tags(); tags != nil {
+		t.Errorf("Tags(): expected nil for an untagged event, actual %v", tags)
+	}
+
+	e.SetTag("setup", "breakout")
+	e.SetTag("model", "v2")
+
+	tags := e.Tags()
+	exp := map[string]string{"setup": "breakout", "model": "v2"}
+	if !reflect.DeepEqual(tags, exp) {
+		t.Errorf("Tags(): expected %v, actual %v", exp, tags)
+	}
+
+	// the returned tags are a copy
+	tags["setup"] = "changed"
+	if v, ok := e.Tag("setup"); !ok || v != "breakout" {
+		t.Errorf("Tag(setup): expected breakout true, actual %v %v", v, ok)
+	}
+	if _, ok := e.Tag("unknown"); ok {
+		t.Errorf("Tag(unknown): expected false, actual true")
+	}
+
+	if s := formatTags(exp); s != "model=v2 setup=breakout" {
+		t.Errorf("formatTags(): expected %q, actual %q", "model=v2 setup=breakout", s)
+	}
+}
+
+// taggingStrategy buys on every data event with a tagged signal.
+type taggingStrategy struct {
+	*Strategy
+}
+
+func (s *taggingStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	signal := &Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}
+	signal.SetTag("setup", "always long")
+	return []SignalEvent{signal}, nil
+}
+
+func TestBacktestTags(t *testing.T) {
+	data := &Data{}
+	data.SetStream(newStressStream(100, 100))
+
+	test := New()
+	test.SetData(data)
+	test.SetStrategy(&taggingStrategy{Strategy: NewStrategy("tagging")})
+
+	if err := test.Run(); err != nil {
+		t.Fatalf("Run(): unexpected error %v", err)
+	}
+
+	exp := map[string]string{"setup": "always long"}
+	for _, e := range test.statistic.Events() {
+		if _, ok := e.(*Order); !ok {
+			continue
+		}
+		if tags := e.(Tagger).Tags(); !reflect.DeepEqual(tags, exp) {
+			t.Errorf("order Tags(): expected %v, actual %v", exp, tags)
+		}
+	}
+
+	blotter := test.statistic.(*Statistic).Blotter()
+	if len(blotter) != 2 {
+		t.Fatalf("Blotter(): expected 2 entries, actual %v", len(blotter))
+	}
+	for _, entry := range blotter {
+		if !reflect.DeepEqual(entry.Tags, exp) {
+			t.Errorf("Blotter(): expected tags %v, actual %v", exp, entry.Tags)
+		}
+	}
+
+	// tags survive the serialization of the event stream
+	for _, r := range NewEventRecords(test.statistic.Events()) {
+		e, err := r.Event()
+		if err != nil {
+			t.Fatalf("Event(): unexpected error %v", err)
+		}
+		if _, ok := e.(*Fill); ok && !reflect.DeepEqual(e.(Tagger).Tags(), exp) {
+			t.Errorf("replayed fill Tags(): expected %v, actual %v", exp, e.(Tagger).Tags())
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package gobacktest
+
+import (
+	"time"
+)
+
+// TimeframeLister defines access to data events aggregated to a higher timeframe.
+type TimeframeLister interface {
+	Timeframes() []time.Duration
+	LatestTimeframe(string, time.Duration) DataEvent
+	ListTimeframe(string, time.Duration) []DataEvent
+}
+
+// MultiTimeframeData wraps a data handler and aggregates the base data stream
+// into bars of higher timeframes, e.g. 5 minute bars into daily bars.
+// Only completed bars of a higher timeframe are made available, so a strategy
+// can not peek into a bar which is still forming.
+type MultiTimeframeData struct {
+	DataHandler
+	timeframes []time.Duration
+	forming    map[time.Duration]map[string]*Bar
+	list       map[time.Duration]map[string][]DataEvent
+}
+
+// NewMultiTimeframeData creates a multi timeframe data handler on top of a base data handler.
+func NewMultiTimeframeData(data DataHandler, timeframes ...time.Duration) *MultiTimeframeData {
+	return &MultiTimeframeData{
+		DataHandler: data,
+		timeframes:  timeframes,
+	}
+}
+
+// Timeframes returns the higher timeframes generated from the base data stream.
+func (d *MultiTimeframeData) Timeframes() []time.Duration {
+	return d.timeframes
+}
+
+// Next returns the next event of the base data stream and updates the higher timeframe bars.
+func (d *MultiTimeframeData) Next() (DataEvent, bool) {
+	event, ok := d.DataHandler.Next()
+	if !ok {
+		return event, false
+	}
+
+	for _, tf := range d.timeframes {
+		d.aggregate(event, tf)
+	}
+
+	return event, true
+}
+
+// Reset implements Reseter to reset the base data handler and all aggregated bars.
+func (d *MultiTimeframeData) Reset() error {
+	d.forming = nil
+	d.list = nil
+	return d.DataHandler.Reset()
+}
+
+// LatestTimeframe returns the last completed bar of a symbol for the given timeframe.
+func (d *MultiTimeframeData) LatestTimeframe(symbol string, tf time.Duration) DataEvent {
+	list := d.list[tf][symbol]
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[len(list)-1]
+}
+
+// ListTimeframe returns all completed bars of a symbol for the given timeframe.
+func (d *MultiTimeframeData) ListTimeframe(symbol string, tf time.Duration) []DataEvent {
+	return d.list[tf][symbol]
+}
+
+// aggregate merges a base data event into the forming bar of a timeframe.
+// If the event starts a new period, the forming bar is completed and appended to the list.
+func (d *MultiTimeframeData) aggregate(event DataEvent, tf time.Duration) {
+	// check for nil maps, else initialise the maps
+	if d.forming == nil {
+		d.forming = make(map[time.Duration]map[string]*Bar)
+	}
+	if d.list == nil {
+		d.list = make(map[time.Duration]map[string][]DataEvent)
+	}
+	if d.forming[tf] == nil {
+		d.forming[tf] = make(map[string]*Bar)
+	}
+	if d.list[tf] == nil {
+		d.list[tf] = make(map[string][]DataEvent)
+	}
+
+	symbol := event.Symbol()
+	start := event.Time().Truncate(tf)
+
+	bar, ok := d.forming[tf][symbol]
+	if ok && bar.Time().Equal(start) {
+		mergeBar(bar, event)
+		return
+	}
+
+	// new period started, complete the forming bar
+	if ok {
+		d.list[tf][symbol] = append(d.list[tf][symbol], bar)
+	}
+	d.forming[tf][symbol] = newBarFrom(event, start)
+}
+
+// Resample aggregates a slice of data events into bars of the given timeframe.
+// The events are expected in ascending order, the last bar of each symbol
+// is included even if its period is not completed.
+func Resample(events []DataEvent, tf time.Duration) []DataEvent {
+	var result []DataEvent
+	forming := make(map[string]*Bar)
+
+	for _, event := range events {
+		symbol := event.Symbol()
+		start := event.Time().Truncate(tf)
+
+		bar, ok := forming[symbol]
+		if ok && bar.Time().Equal(start) {
+			mergeBar(bar, event)
+			continue
+		}
+
+		bar = newBarFrom(event, start)
+		forming[symbol] = bar
+		result = append(result, bar)
+	}
+
+	return result
+}
+
+// newBarFrom creates a new bar starting at the given time from a data event.
+func newBarFrom(event DataEvent, start time.Time) *Bar {
+	bar := &Bar{
+		Event:  Event{timestamp: start, symbol: event.Symbol()},
+		Metric: Metric{},
+	}
+
+	switch e := event.(type) {
+	case *Bar:
+		bar.Open = e.Open
+		bar.High = e.High
+		bar.Low = e.Low
+		bar.Close = e.Close
+		bar.AdjClose = e.AdjClose
+		bar.Volume = e.Volume
+	default:
+		price := event.Price()
+		bar.Open = price
+		bar.High = price
+		bar.Low = price
+		bar.Close = price
+		bar.AdjClose = price
+	}
+
+	return bar
+}
+
+// mergeBar merges a data event into an existing bar of the same period.
+func mergeBar(bar *Bar, event DataEvent) {
+	switch e := event.(type) {
+	case *Bar:
+		if e.High > bar.High {
+			bar.High = e.High
+		}
+		if e.Low < bar.Low {
+			bar.Low = e.Low
+		}
+		bar.Close = e.Close
+		bar.AdjClose = e.AdjClose
+		bar.Volume += e.Volume
+	default:
+		price := event.Price()
+		if price > bar.High {
+			bar.High = price
+		}
+		if price < bar.Low {
+			bar.Low = price
+		}
+		bar.Close = price
+		bar.AdjClose = price
+	}
+}
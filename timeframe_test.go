@@ -0,0 +1,87 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02 15:04", "2018-06-01 09:00")
+	var day2, _ = time.Parse("2006-01-02 15:04", "2018-06-02 09:00")
+
+	var testCases = []struct {
+		msg    string
+		events []DataEvent
+		tf     time.Duration
+		exp    []DataEvent
+	}{
+		{"resample intraday bars to daily bars:",
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11, AdjClose: 11, Volume: 100},
+				&Bar{Event: Event{timestamp: day1.Add(5 * time.Minute), symbol: "TEST.DE"}, Open: 11, High: 15, Low: 10, Close: 14, AdjClose: 14, Volume: 50},
+				&Bar{Event: Event{timestamp: day1.Add(10 * time.Minute), symbol: "TEST.DE"}, Open: 14, High: 14, Low: 8, Close: 9, AdjClose: 9, Volume: 25},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 9, High: 10, Low: 9, Close: 10, AdjClose: 10, Volume: 10},
+			},
+			24 * time.Hour,
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1.Truncate(24 * time.Hour), symbol: "TEST.DE"}, Metric: Metric{}, Open: 10, High: 15, Low: 8, Close: 9, AdjClose: 9, Volume: 175},
+				&Bar{Event: Event{timestamp: day2.Truncate(24 * time.Hour), symbol: "TEST.DE"}, Metric: Metric{}, Open: 9, High: 10, Low: 9, Close: 10, AdjClose: 10, Volume: 10},
+			},
+		},
+		{"resample ticks to bars:",
+			[]DataEvent{
+				&Tick{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Bid: 10, Ask: 12},
+				&Tick{Event: Event{timestamp: day1.Add(time.Second), symbol: "TEST.DE"}, Bid: 12, Ask: 14},
+			},
+			time.Minute,
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Metric: Metric{}, Open: 11, High: 13, Low: 11, Close: 13, AdjClose: 13},
+			},
+		},
+		{"resample empty events:",
+			[]DataEvent{},
+			time.Hour,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		result := Resample(tc.events, tc.tf)
+		if !reflect.DeepEqual(result, tc.exp) {
+			t.Errorf("%v Resample(): \nexpected %#v, \nactual   %#v", tc.msg, tc.exp, result)
+		}
+	}
+}
+
+func TestMultiTimeframeDataNext(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02 15:04", "2018-06-01 09:00")
+	var day2, _ = time.Parse("2006-01-02 15:04", "2018-06-02 09:00")
+
+	data := NewMultiTimeframeData(&Data{
+		stream: []DataEvent{
+			&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11, Volume: 100},
+			&Bar{Event: Event{timestamp: day1.Add(time.Hour), symbol: "TEST.DE"}, Open: 11, High: 13, Low: 10, Close: 12, Volume: 100},
+			&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 12, High: 12, Low: 11, Close: 11, Volume: 100},
+		},
+	}, 24*time.Hour)
+
+	// the first two events are within the same day, no daily bar completed
+	data.Next()
+	data.Next()
+	if list := data.ListTimeframe("TEST.DE", 24*time.Hour); len(list) != 0 {
+		t.Errorf("ListTimeframe(): expected no completed bar, actual %#v", list)
+	}
+
+	// the next event starts a new day, the first daily bar is completed
+	data.Next()
+	exp := &Bar{Event: Event{timestamp: day1.Truncate(24 * time.Hour), symbol: "TEST.DE"}, Metric: Metric{}, Open: 10, High: 13, Low: 9, Close: 12, Volume: 200}
+	if latest := data.LatestTimeframe("TEST.DE", 24*time.Hour); !reflect.DeepEqual(latest, exp) {
+		t.Errorf("LatestTimeframe(): \nexpected %#v, \nactual   %#v", exp, latest)
+	}
+
+	data.Reset()
+	if latest := data.LatestTimeframe("TEST.DE", 24*time.Hour); latest != nil {
+		t.Errorf("Reset(): expected no bar, actual %#v", latest)
+	}
+}
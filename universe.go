@@ -0,0 +1,149 @@
+package gobacktest
+
+import (
+	"sort"
+	"time"
+)
+
+// Universe is the time-varying set of tradable symbols, e.g. the constituents of an index
+// with the dates they were added and removed. A symbol is a member from the date it was
+// added until before the date it was removed, it can be added again later.
+type Universe struct {
+	members map[string][]membership
+}
+
+// membership is a period of a symbol in the universe, an open period has a zero removal.
+type membership struct {
+	added   time.Time
+	removed time.Time
+}
+
+// Add adds a period of a symbol to the universe, a zero removed date keeps the symbol
+// until the end.
+func (u *Universe) Add(symbol string, added, removed time.Time) {
+	// check for nil map, else initialise the map
+	if u.members == nil {
+		u.members = make(map[string][]membership)
+	}
+
+	list := append(u.members[symbol], membership{added: added, removed: removed})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].added.Before(list[j].added)
+	})
+	u.members[symbol] = list
+}
+
+// Member returns true if the symbol is a member of the universe at the time.
+func (u *Universe) Member(symbol string, t time.Time) bool {
+	for _, m := range u.members[symbol] {
+		if !t.Before(m.added) && (m.removed.IsZero() || t.Before(m.removed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Members returns the sorted symbols of the universe at the time.
+func (u *Universe) Members(t time.Time) []string {
+	symbols := []string{}
+	for symbol := range u.members {
+		if u.Member(symbol, t) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Symbols returns all sorted symbols which were ever a member of the universe, e.g. to load
+// their data including the delisted symbols.
+func (u *Universe) Symbols() []string {
+	symbols := []string{}
+	for symbol := range u.members {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Filter returns the data events of the stream of symbols which are members of the universe
+// at the time of the event.
+func (u *Universe) Filter(stream []DataEvent) []DataEvent {
+	var members []DataEvent
+	for _, event := range stream {
+		if u.Member(event.Symbol(), event.Time()) {
+			members = append(members, event)
+		}
+	}
+	return members
+}
+
+// UniverseLister defines access to the members of the universe at the current time.
+type UniverseLister interface {
+	Members() []string
+}
+
+// UniverseData wraps a data handler and streams only the data events of symbols which are
+// members of the universe at the time of the event, so a cross-sectional strategy never
+// sees a symbol before it was added or after it was removed, and the delisted symbols of
+// the past are not missing. The latest data event of a removed symbol stays available,
+// e.g. to value a remaining position.
+//
+// The stream of the base data handler is filtered if it allows to set its stream, else the
+// events of other symbols are skipped by Next, but remain in the history of the base.
+type UniverseData struct {
+	DataHandler
+	universe *Universe
+	now      time.Time
+}
+
+// NewUniverseData creates a data handler of the universe on top of a base data handler.
+func NewUniverseData(data DataHandler, universe *Universe) *UniverseData {
+	d := &UniverseData{
+		DataHandler: data,
+		universe:    universe,
+	}
+	d.SetStream(data.Stream())
+	return d
+}
+
+// Universe returns the universe of the data handler.
+func (d *UniverseData) Universe() *Universe {
+	return d.universe
+}
+
+// SetStream sets the data stream of the members of the universe, if the base data handler
+// allows to set its stream.
+func (d *UniverseData) SetStream(stream []DataEvent) {
+	if setter, ok := d.DataHandler.(streamSetter); ok {
+		setter.SetStream(d.universe.Filter(stream))
+	}
+}
+
+// Next returns the next data event of the base data stream of a member of the universe,
+// the events of other symbols are skipped.
+func (d *UniverseData) Next() (DataEvent, bool) {
+	for {
+		event, ok := d.DataHandler.Next()
+		if !ok {
+			return event, false
+		}
+		d.now = event.Time()
+
+		if d.universe.Member(event.Symbol(), event.Time()) {
+			return event, true
+		}
+	}
+}
+
+// Members implements UniverseLister and returns the members of the universe at the time
+// of the last data event.
+func (d *UniverseData) Members() []string {
+	return d.universe.Members(d.now)
+}
+
+// Reset implements Reseter to reset the base data handler and the current time.
+func (d *UniverseData) Reset() error {
+	d.now = time.Time{}
+	return d.DataHandler.Reset()
+}
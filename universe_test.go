@@ -0,0 +1,103 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUniverseMembers(t *testing.T) {
+	date := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+
+	u := &Universe{}
+	u.Add("AAA", date("2010-01-01"), time.Time{})
+	u.Add("BBB", date("2010-01-01"), date("2012-06-01"))
+	u.Add("CCC", date("2014-01-01"), date("2015-01-01"))
+	u.Add("CCC", date("2011-01-01"), date("2012-01-01"))
+
+	// testCases is a table for testing the members at a date
+	var testCases = []struct {
+		msg string
+		t   time.Time
+		exp []string
+	}{
+		{"before all additions:", date("2009-12-31"), []string{}},
+		{"added date is included:", date("2010-01-01"), []string{"AAA", "BBB"}},
+		{"first period of a symbol:", date("2011-06-01"), []string{"AAA", "BBB", "CCC"}},
+		{"removed date is excluded:", date("2012-06-01"), []string{"AAA"}},
+		{"symbol added again:", date("2014-06-01"), []string{"AAA", "CCC"}},
+		{"open period:", date("2030-01-01"), []string{"AAA"}},
+	}
+
+	for _, tc := range testCases {
+		if members := u.Members(tc.t); !reflect.DeepEqual(members, tc.exp) {
+			t.Errorf("%v Members(): expected %v, actual %v", tc.msg, tc.exp, members)
+		}
+	}
+
+	if symbols := u.Symbols(); !reflect.DeepEqual(symbols, []string{"AAA", "BBB", "CCC"}) {
+		t.Errorf("Symbols(): expected all symbols, actual %v", symbols)
+	}
+	if (&Universe{}).Member("AAA", date("2010-01-01")) {
+		t.Errorf("Member(): expected no member of an empty universe")
+	}
+}
+
+func TestUniverseDataNext(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01")
+	var day2 = day1.AddDate(0, 0, 1)
+
+	u := &Universe{}
+	u.Add("OLD.DE", day1.AddDate(-1, 0, 0), day2)
+	u.Add("NEW.DE", day2, time.Time{})
+
+	data := NewUniverseData(&Data{
+		stream: []DataEvent{
+			&Bar{Event: Event{timestamp: day1, symbol: "NEW.DE"}, Close: 10},
+			&Bar{Event: Event{timestamp: day1, symbol: "OLD.DE"}, Close: 20},
+			&Bar{Event: Event{timestamp: day2, symbol: "NEW.DE"}, Close: 11},
+			&Bar{Event: Event{timestamp: day2, symbol: "OLD.DE"}, Close: 21},
+		},
+	}, u)
+
+	var streamed []string
+	for event, ok := data.Next(); ok; event, ok = data.Next() {
+		streamed = append(streamed, event.Symbol()+" "+event.Time().Format("2006-01-02"))
+		if event.Time().Equal(day1) && !reflect.DeepEqual(data.Members(), []string{"OLD.DE"}) {
+			t.Errorf("Members(): expected the members of the first day, actual %v", data.Members())
+		}
+	}
+
+	exp := []string{"OLD.DE 2018-06-01", "NEW.DE 2018-06-02"}
+	if !reflect.DeepEqual(streamed, exp) {
+		t.Errorf("Next(): expected the events of the members %v, actual %v", exp, streamed)
+	}
+	if !reflect.DeepEqual(data.Members(), []string{"NEW.DE"}) {
+		t.Errorf("Members(): expected the members of the last day, actual %v", data.Members())
+	}
+	if latest := data.Latest("OLD.DE"); latest == nil || latest.Price() != 20 {
+		t.Errorf("Latest(): expected the last event of the removed symbol, actual %v", latest)
+	}
+
+	// a base data handler without a stream setter skips the events of other symbols
+	base := NewUniverseData(&Data{}, u)
+	base.DataHandler = struct{ DataHandler }{&Data{stream: []DataEvent{
+		&Bar{Event: Event{timestamp: day1, symbol: "NEW.DE"}, Close: 10},
+		&Bar{Event: Event{timestamp: day2, symbol: "NEW.DE"}, Close: 11},
+	}}}
+	if event, ok := base.Next(); !ok || !event.Time().Equal(day2) {
+		t.Errorf("Next(): expected the event of the member, actual %v", event)
+	}
+
+	data.Reset()
+	if members := data.Members(); len(members) != 0 {
+		t.Errorf("Reset(): expected no members before the first event, actual %v", members)
+	}
+}
+
+// check the interfaces of the universe data
+var _ DataHandler = &UniverseData{}
+var _ UniverseLister = &UniverseData{}
@@ -0,0 +1,262 @@
+package gobacktest
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidationPolicy defines how a validator handles invalid data events.
+type ValidationPolicy int
+
+// different types of validation policies
+const (
+	// return an error on the first found issue
+	ValidationError ValidationPolicy = iota // 0
+	// drop invalid data events from the stream
+	ValidationSkip
+	// repair invalid data events where possible, drop them otherwise
+	ValidationRepair
+)
+
+// IssueType defines which type of issue was found in the data.
+type IssueType int
+
+// different types of data issues
+const (
+	IssueMissing IssueType = iota // 0
+	IssuePrice
+	IssueHighLow
+	IssueDuplicate
+)
+
+// ValidationIssue describes a single issue found in the data stream.
+type ValidationIssue struct {
+	Type    IssueType
+	Symbol  string
+	Time    time.Time
+	Message string
+}
+
+// Error implements the error interface.
+func (i ValidationIssue) Error() string {
+	return fmt.Sprintf("%s %s: %s", i.Symbol, i.Time.Format("2006-01-02 15:04:05"), i.Message)
+}
+
+// Validator checks data events for missing bars, invalid prices,
+// high/low inconsistencies and duplicate timestamps.
+// It can be used on a complete stream via Validate or as streaming checker via Check.
+type Validator struct {
+	Policy       ValidationPolicy
+	Interval     time.Duration // expected interval between two events, 0 disables gap detection
+	SkipWeekends bool          // do not expect events on saturday and sunday
+	last         map[string]DataEvent
+}
+
+// Reset implements Reseter to reset the validator to a clean state.
+func (v *Validator) Reset() error {
+	v.last = nil
+	return nil
+}
+
+// Validate checks a complete data stream and returns the validated stream.
+func (v *Validator) Validate(stream []DataEvent) ([]DataEvent, []ValidationIssue, error) {
+	var result = []DataEvent{}
+	var issues []ValidationIssue
+
+	for _, event := range stream {
+		events, found, err := v.Check(event)
+		issues = append(issues, found...)
+		if err != nil {
+			return result, issues, err
+		}
+		result = append(result, events...)
+	}
+
+	return result, issues, nil
+}
+
+// Check validates a single data event against the last valid event of the same symbol.
+// It returns the events to use in place of the checked event, which are none
+// if the event is dropped, or additional events if missing bars are repaired.
+func (v *Validator) Check(event DataEvent) ([]DataEvent, []ValidationIssue, error) {
+	// check for nil map, else initialise the map
+	if v.last == nil {
+		v.last = make(map[string]DataEvent)
+	}
+
+	var issues []ValidationIssue
+	last, hasLast := v.last[event.Symbol()]
+
+	// duplicate or out of order timestamp
+	if hasLast && !event.Time().After(last.Time()) {
+		issue := v.issue(IssueDuplicate, event, "duplicate or out of order timestamp")
+		issues = append(issues, issue)
+		if v.Policy == ValidationError {
+			return nil, issues, issue
+		}
+		return nil, issues, nil
+	}
+
+	// price and high/low consistency
+	checked, found := v.checkPrices(event, last)
+	issues = append(issues, found...)
+	if len(found) > 0 && v.Policy == ValidationError {
+		return nil, issues, found[0]
+	}
+	if checked == nil {
+		return nil, issues, nil
+	}
+
+	var events []DataEvent
+
+	// missing events since the last known event
+	if hasLast && v.Interval > 0 {
+		missing := v.missing(last.Time(), checked.Time())
+		if len(missing) > 0 {
+			issue := v.issue(IssueMissing, checked, fmt.Sprintf("%d missing events", len(missing)))
+			issues = append(issues, issue)
+			if v.Policy == ValidationError {
+				return nil, issues, issue
+			}
+			if v.Policy == ValidationRepair {
+				events = append(events, fillForward(last, missing)...)
+			}
+		}
+	}
+
+	v.last[checked.Symbol()] = checked
+	events = append(events, checked)
+
+	return events, issues, nil
+}
+
+// checkPrices checks a data event for invalid prices. It returns nil if the event should be dropped.
+func (v *Validator) checkPrices(event DataEvent, last DataEvent) (DataEvent, []ValidationIssue) {
+	var issues []ValidationIssue
+
+	switch e := event.(type) {
+	case *Bar:
+		bar := *e
+		if bar.Open <= 0 || bar.High <= 0 || bar.Low <= 0 || bar.Close <= 0 {
+			issues = append(issues, v.issue(IssuePrice, event, "zero or negative price"))
+			if v.Policy != ValidationRepair || last == nil {
+				return nil, issues
+			}
+			bar.Open = positiveOr(bar.Open, last.Price())
+			bar.High = positiveOr(bar.High, last.Price())
+			bar.Low = positiveOr(bar.Low, last.Price())
+			bar.Close = positiveOr(bar.Close, last.Price())
+			bar.AdjClose = positiveOr(bar.AdjClose, bar.Close)
+		}
+
+		if bar.High < bar.Low || bar.High < bar.Open || bar.High < bar.Close || bar.Low > bar.Open || bar.Low > bar.Close {
+			issues = append(issues, v.issue(IssueHighLow, event, "high and low inconsistent with open and close"))
+			if v.Policy != ValidationRepair {
+				return nil, issues
+			}
+			bar.High, bar.Low = maxOf(bar.Open, bar.High, bar.Low, bar.Close), minOf(bar.Open, bar.High, bar.Low, bar.Close)
+		}
+
+		if len(issues) > 0 {
+			return &bar, issues
+		}
+	case *Tick:
+		if e.Bid <= 0 || e.Ask <= 0 {
+			issues = append(issues, v.issue(IssuePrice, event, "zero or negative price"))
+			return nil, issues
+		}
+	default:
+		if event.Price() <= 0 {
+			issues = append(issues, v.issue(IssuePrice, event, "zero or negative price"))
+			return nil, issues
+		}
+	}
+
+	return event, issues
+}
+
+// missing returns the timestamps of expected but missing events between two times.
+func (v *Validator) missing(from, to time.Time) []time.Time {
+	var missing []time.Time
+
+	for t := from.Add(v.Interval); t.Before(to); t = t.Add(v.Interval) {
+		if v.SkipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+			continue
+		}
+		missing = append(missing, t)
+	}
+
+	return missing
+}
+
+// issue creates a validation issue for a data event.
+func (v *Validator) issue(t IssueType, event DataEvent, msg string) ValidationIssue {
+	return ValidationIssue{
+		Type:    t,
+		Symbol:  event.Symbol(),
+		Time:    event.Time(),
+		Message: msg,
+	}
+}
+
+// Validate checks the data stream with the given validator and replaces it with the validated stream.
+func (d *Data) Validate(v *Validator) ([]ValidationIssue, error) {
+	stream, issues, err := v.Validate(d.stream)
+	if err != nil {
+		return issues, err
+	}
+
+	d.stream = stream
+	return issues, nil
+}
+
+// fillForward creates flat bars with the last known price for each missing timestamp.
+func fillForward(last DataEvent, missing []time.Time) []DataEvent {
+	var events []DataEvent
+	price := last.Price()
+
+	for _, t := range missing {
+		bar := &Bar{
+			Event:    Event{timestamp: t, symbol: last.Symbol()},
+			Metric:   Metric{},
+			Open:     price,
+			High:     price,
+			Low:      price,
+			Close:    price,
+			AdjClose: price,
+		}
+		events = append(events, bar)
+	}
+
+	return events
+}
+
+// positiveOr returns the value if it is positive, else the replacement.
+func positiveOr(value, replacement float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return replacement
+}
+
+// maxOf returns the biggest of the given values.
+func maxOf(values ...float64) float64 {
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// minOf returns the smallest of the given values.
+func minOf(values ...float64) float64 {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
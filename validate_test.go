@@ -0,0 +1,99 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidatorValidate(t *testing.T) {
+	var day1, _ = time.Parse("2006-01-02", "2018-06-01") // friday
+	var day2 = day1.Add(24 * time.Hour)
+	var day4 = day1.Add(4 * 24 * time.Hour) // tuesday
+
+	var testCases = []struct {
+		msg       string
+		validator *Validator
+		stream    []DataEvent
+		expStream []DataEvent
+		expIssues []IssueType
+		expErr    bool
+	}{
+		{"valid stream:",
+			&Validator{Policy: ValidationError, Interval: 24 * time.Hour},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 11, High: 12, Low: 10, Close: 12},
+			},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 11, High: 12, Low: 10, Close: 12},
+			},
+			nil,
+			false,
+		},
+		{"error on zero price:",
+			&Validator{Policy: ValidationError},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 0, Close: 11},
+			},
+			[]DataEvent{},
+			[]IssueType{IssuePrice},
+			true,
+		},
+		{"skip duplicate and high low inconsistency:",
+			&Validator{Policy: ValidationSkip},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 11, High: 9, Low: 12, Close: 10},
+			},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+			},
+			[]IssueType{IssueDuplicate, IssueHighLow},
+			false,
+		},
+		{"repair high low inconsistency and zero price:",
+			&Validator{Policy: ValidationRepair},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 11, High: 9, Low: 12, Close: 0},
+			},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day2, symbol: "TEST.DE"}, Open: 11, High: 12, Low: 9, Close: 11, AdjClose: 11},
+			},
+			[]IssueType{IssuePrice, IssueHighLow},
+			false,
+		},
+		{"repair missing bars without weekends:",
+			&Validator{Policy: ValidationRepair, Interval: 24 * time.Hour, SkipWeekends: true},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day4, symbol: "TEST.DE"}, Open: 11, High: 12, Low: 10, Close: 12},
+			},
+			[]DataEvent{
+				&Bar{Event: Event{timestamp: day1, symbol: "TEST.DE"}, Open: 10, High: 12, Low: 9, Close: 11},
+				&Bar{Event: Event{timestamp: day4.Add(-24 * time.Hour), symbol: "TEST.DE"}, Metric: Metric{}, Open: 11, High: 11, Low: 11, Close: 11, AdjClose: 11},
+				&Bar{Event: Event{timestamp: day4, symbol: "TEST.DE"}, Open: 11, High: 12, Low: 10, Close: 12},
+			},
+			[]IssueType{IssueMissing},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		stream, issues, err := tc.validator.Validate(tc.stream)
+
+		var issueTypes []IssueType
+		for _, issue := range issues {
+			issueTypes = append(issueTypes, issue.Type)
+		}
+
+		if !reflect.DeepEqual(stream, tc.expStream) || !reflect.DeepEqual(issueTypes, tc.expIssues) || ((err != nil) != tc.expErr) {
+			t.Errorf("%v Validate(): \nexpected %#v %v %v, \nactual   %#v %v %v",
+				tc.msg, tc.expStream, tc.expIssues, tc.expErr, stream, issueTypes, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package gobacktest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// defaultConfidence are the confidence levels of the risk statistics, if none are set.
+var defaultConfidence = []float64{0.95, 0.99}
+
+// SetConfidence sets the confidence levels of the printed risk statistics, e.g. 0.95 and 0.99.
+func (s *Statistic) SetConfidence(levels ...float64) {
+	s.confidence = levels
+}
+
+// Confidence returns the confidence levels of the printed risk statistics.
+func (s Statistic) Confidence() []float64 {
+	if len(s.confidence) == 0 {
+		return defaultConfidence
+	}
+	return s.confidence
+}
+
+// HistoricalVaR returns the value at risk of the daily returns at a confidence level,
+// taken from the empirical distribution. A loss is returned as positive value.
+func (s Statistic) HistoricalVaR(confidence float64) float64 {
+	returns := s.DailyReturns()
+	if len(returns) == 0 {
+		return 0
+	}
+	sort.Float64s(returns)
+
+	q := stat.Quantile(1-confidence, stat.Empirical, returns, nil)
+	return math.Round(-q*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// ParametricVaR returns the value at risk of the daily returns at a confidence level,
+// assuming normally distributed returns. A loss is returned as positive value.
+func (s Statistic) ParametricVaR(confidence float64) float64 {
+	returns := s.DailyReturns()
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean, stddev := stat.MeanStdDev(returns, nil)
+	z := distuv.UnitNormal.Quantile(1 - confidence)
+
+	return math.Round(-(mean+z*stddev)*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// ExpectedShortfall returns the average daily loss beyond the historical value at risk
+// at a confidence level. A loss is returned as positive value.
+func (s Statistic) ExpectedShortfall(confidence float64) float64 {
+	returns := s.DailyReturns()
+	if len(returns) == 0 {
+		return 0
+	}
+	sort.Float64s(returns)
+
+	q := stat.Quantile(1-confidence, stat.Empirical, returns, nil)
+
+	var sum float64
+	var count int
+	for _, r := range returns {
+		if r > q {
+			break
+		}
+		sum += r
+		count++
+	}
+
+	return math.Round(-sum/float64(count)*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// DailyReturns returns the returns of the equity at the end of each day,
+// adjusted for external cash flows.
+func (s Statistic) DailyReturns() []float64 {
+	var returns []float64
+	for _, r := range s.DatedDailyReturns() {
+		returns = append(returns, r.Return)
+	}
+	return returns
+}
+
+// DatedDailyReturns returns the returns of the equity at the end of each day with their date,
+// adjusted for external cash flows. The first day has no return.
+func (s Statistic) DatedDailyReturns() []DailyReturn {
+	var returns []DailyReturn
+	var lastDay time.Time
+	var last, flow float64
+
+	for i, ep := range s.equity {
+		day := time.Date(ep.timestamp.Year(), ep.timestamp.Month(), ep.timestamp.Day(), 0, 0, 0, 0, ep.timestamp.Location())
+		flow += ep.cashFlow
+		if i == 0 {
+			lastDay, last, flow = day, ep.equity, 0
+			continue
+		}
+
+		// only the last equity point of a day counts
+		if i+1 < len(s.equity) && s.equity[i+1].timestamp.Before(day.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		if day.After(lastDay) && last != 0 {
+			returns = append(returns, DailyReturn{Date: day, Return: (ep.equity - flow - last) / last})
+		}
+		lastDay, last, flow = day, ep.equity, 0
+	}
+
+	return returns
+}
+
+// printRisk prints the value at risk and expected shortfall for all confidence levels.
+func (s Statistic) printRisk() {
+	for _, c := range s.Confidence() {
+		fmt.Printf("VaR %.1f%%: historical %.4f parametric %.4f, Expected Shortfall: %.4f\n",
+			c*100, s.HistoricalVaR(c), s.ParametricVaR(c), s.ExpectedShortfall(c))
+	}
+}
@@ -0,0 +1,81 @@
+package gobacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newDailyStatistic creates a statistic with one equity point per day from daily returns.
+func newDailyStatistic(returns []float64) Statistic {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	equity := 1000.0
+
+	s := Statistic{equity: []equityPoint{{timestamp: day, equity: equity}}}
+	for i, r := range returns {
+		equity = equity * (1 + r)
+		s.equity = append(s.equity, equityPoint{timestamp: day.AddDate(0, 0, i+1), equity: equity})
+	}
+	return s
+}
+
+func TestDailyReturns(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	s := Statistic{equity: []equityPoint{
+		{timestamp: day, equity: 100},
+		{timestamp: day.Add(4 * time.Hour), equity: 105},
+		{timestamp: day.AddDate(0, 0, 1), equity: 110},
+		{timestamp: day.AddDate(0, 0, 1).Add(4 * time.Hour), equity: 126, cashFlow: 10},
+		{timestamp: day.AddDate(0, 0, 2), equity: 116},
+	}}
+
+	// intraday points are ignored, the cash flow is removed from the return
+	exp := []float64{(116.0 - 105) / 105, (116.0 - 126) / 126}
+	returns := s.DailyReturns()
+	if !reflect.DeepEqual(returns, exp) {
+		t.Errorf("DailyReturns(): \nexpected %v, \nactual   %v", exp, returns)
+	}
+}
+
+func TestValueAtRisk(t *testing.T) {
+	returns := []float64{-0.05, -0.04, -0.03, -0.02, -0.01, 0, 0.01, 0.02, 0.03, 0.04}
+	s := newDailyStatistic(returns)
+
+	// testCases is a table for testing the value at risk
+	var testCases = []struct {
+		msg        string
+		confidence float64
+		expVaR     float64
+		expES      float64
+	}{
+		{"90% confidence:", 0.9, 0.05, 0.05},
+		{"80% confidence:", 0.8, 0.04, 0.045},
+	}
+
+	for _, tc := range testCases {
+		if v := s.HistoricalVaR(tc.confidence); v != tc.expVaR {
+			t.Errorf("%v HistoricalVaR(): \nexpected %v, \nactual   %v", tc.msg, tc.expVaR, v)
+		}
+		if es := s.ExpectedShortfall(tc.confidence); es != tc.expES {
+			t.Errorf("%v ExpectedShortfall(): \nexpected %v, \nactual   %v", tc.msg, tc.expES, es)
+		}
+	}
+
+	// the parametric value at risk grows with the confidence level
+	if v95, v99 := s.ParametricVaR(0.95), s.ParametricVaR(0.99); v95 <= 0 || v99 <= v95 {
+		t.Errorf("ParametricVaR(): expected 0 < VaR95 < VaR99, actual %v %v", v95, v99)
+	}
+}
+
+func TestStatisticConfidence(t *testing.T) {
+	s := Statistic{}
+	if !reflect.DeepEqual(s.Confidence(), []float64{0.95, 0.99}) {
+		t.Errorf("Confidence(): expected default levels, actual %v", s.Confidence())
+	}
+
+	s.SetConfidence(0.975)
+	if !reflect.DeepEqual(s.Confidence(), []float64{0.975}) {
+		t.Errorf("Confidence(): expected %v, actual %v", []float64{0.975}, s.Confidence())
+	}
+}
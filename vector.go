@@ -0,0 +1,245 @@
+package gobacktest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// ErrNotVectorizable is returned by a vector rule which can not be evaluated over whole arrays,
+// e.g. because it depends on the state of the portfolio. RunVectorized falls back to the event loop.
+var ErrNotVectorizable = errors.New("rule is not vectorizable")
+
+// VectorData holds the prices of a single symbol as arrays, with a cache of the indicators
+// computed on them. The cache is shared by all rules run on the data, so a parameter sweep
+// computes each indicator only once.
+type VectorData struct {
+	Symbol string
+	Times  []time.Time
+	Prices []float64
+	cache  map[string][]float64
+}
+
+// NewVectorData creates the arrays of a symbol from a stream of data events.
+func NewVectorData(symbol string, stream []DataEvent) *VectorData {
+	v := &VectorData{Symbol: symbol}
+	for _, e := range stream {
+		if e.Symbol() != symbol {
+			continue
+		}
+		v.Times = append(v.Times, e.Time())
+		v.Prices = append(v.Prices, e.Price())
+	}
+	return v
+}
+
+// Len returns the number of bars.
+func (v *VectorData) Len() int {
+	return len(v.Prices)
+}
+
+// SMA returns the simple moving average over the period, aligned with the prices.
+// Values before the first complete period are NaN.
+func (v *VectorData) SMA(period int) []float64 {
+	return v.indicator(fmt.Sprintf("sma(%d)", period), func() []float64 {
+		sma := nanSlice(len(v.Prices))
+		if period <= 0 {
+			return sma
+		}
+
+		var sum float64
+		for i, p := range v.Prices {
+			sum += p
+			if i >= period {
+				sum -= v.Prices[i-period]
+			}
+			if i+1 >= period {
+				sma[i] = sum / float64(period)
+			}
+		}
+		return sma
+	})
+}
+
+// EMA returns the exponential moving average over the period, aligned with the prices.
+// It starts with the SMA of the first period, values before are NaN.
+func (v *VectorData) EMA(period int) []float64 {
+	return v.indicator(fmt.Sprintf("ema(%d)", period), func() []float64 {
+		sma := v.SMA(period)
+		ema := nanSlice(len(v.Prices))
+		if period <= 0 || len(v.Prices) < period {
+			return ema
+		}
+
+		multiplier := 2 / float64(period+1)
+		ema[period-1] = sma[period-1]
+		for i := period; i < len(v.Prices); i++ {
+			ema[i] = (v.Prices[i]-ema[i-1])*multiplier + ema[i-1]
+		}
+		return ema
+	})
+}
+
+// Indicator returns a custom indicator by name, computed once by calc and cached.
+func (v *VectorData) Indicator(name string, calc func(prices []float64) []float64) []float64 {
+	return v.indicator("custom:"+name, func() []float64 {
+		return calc(v.Prices)
+	})
+}
+
+// indicator returns a cached indicator or computes it.
+func (v *VectorData) indicator(key string, calc func() []float64) []float64 {
+	// check for nil map, else initialise the map
+	if v.cache == nil {
+		v.cache = make(map[string][]float64)
+	}
+
+	if values, ok := v.cache[key]; ok {
+		return values
+	}
+	values := calc()
+	v.cache[key] = values
+	return values
+}
+
+// nanSlice returns a slice of n NaN values.
+func nanSlice(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
+
+// VectorRule evaluates a simple rule-based strategy over whole arrays. It returns the
+// target exposure for each bar as fraction of the equity, e.g. 1 for long, -1 for short
+// and 0 for flat. NaN keeps the exposure of the previous bar.
+type VectorRule func(v *VectorData) ([]float64, error)
+
+// VectorCost sets the transaction costs of a vectorized run.
+type VectorCost struct {
+	Commission float64 // fraction of the traded value, e.g. 0.001 for 10 basis points
+}
+
+// VectorResult is the summary of a vectorized run. Equity is nil for a run which fell back
+// to the event loop.
+type VectorResult struct {
+	Return      float64
+	MaxDrawdown float64
+	Sharpe      float64
+	Trades      int
+	Equity      []float64
+	Vectorized  bool // false if the run fell back to the event loop
+}
+
+// RunVectorized evaluates the rule over the whole data, which is much faster than
+// the event loop for parameter sweeps.
+// The target exposure of a bar is traded at its price, like a market order in the event loop,
+// and earns the return up to the next bar. The run ignores the sizing, risk management and
+// order types of the event loop, so its results approximate a backtest with fractional sizing.
+// A rule returning ErrNotVectorizable runs the backtest built by fallback, if one is given.
+func RunVectorized(v *VectorData, rule VectorRule, cost VectorCost, fallback func() (*Backtest, error)) (VectorResult, error) {
+	target, err := rule(v)
+	if err == ErrNotVectorizable && fallback != nil {
+		return runFallback(fallback)
+	}
+	if err != nil {
+		return VectorResult{}, err
+	}
+	if len(target) != v.Len() {
+		return VectorResult{}, fmt.Errorf("could not run vectorized, rule returned %v values for %v prices", len(target), v.Len())
+	}
+
+	return evaluateVector(v.Prices, target, cost), nil
+}
+
+// evaluateVector calculates the equity curve of the exposures.
+func evaluateVector(prices, target []float64, cost VectorCost) VectorResult {
+	result := VectorResult{Vectorized: true}
+	if len(prices) == 0 {
+		return result
+	}
+
+	equity := make([]float64, len(prices))
+	returns := make([]float64, len(prices))
+	equity[0] = 1
+
+	var exposure, high, drawdown float64
+	high = 1
+	for i := range prices {
+		if i > 0 {
+			equity[i] = equity[i-1]
+			if exposure != 0 && prices[i-1] != 0 {
+				equity[i] += equity[i-1] * exposure * (prices[i]/prices[i-1] - 1)
+			}
+		}
+
+		// trade to the target exposure at the price of the bar
+		if next := target[i]; !math.IsNaN(next) && next != exposure {
+			equity[i] -= equity[i] * math.Abs(next-exposure) * cost.Commission
+			exposure = next
+			result.Trades++
+		}
+
+		if i > 0 && equity[i-1] != 0 {
+			returns[i] = equity[i]/equity[i-1] - 1
+		}
+		if equity[i] > high {
+			high = equity[i]
+		}
+		if dd := (equity[i] - high) / high; dd < drawdown {
+			drawdown = dd
+		}
+	}
+
+	result.Equity = equity
+	result.Return = roundDP(equity[len(equity)-1] - 1)
+	result.MaxDrawdown = roundDP(drawdown)
+	if mean, stddev := stat.MeanStdDev(returns, nil); stddev != 0 {
+		result.Sharpe = mean / stddev
+	}
+	return result
+}
+
+// runFallback runs a backtest in the event loop and summarizes it as vector result.
+func runFallback(fallback func() (*Backtest, error)) (VectorResult, error) {
+	test, err := fallback()
+	if err != nil {
+		return VectorResult{}, err
+	}
+	if err := test.Run(); err != nil {
+		return VectorResult{}, err
+	}
+
+	stats := test.Stats()
+	result := VectorResult{
+		MaxDrawdown: stats.MaxDrawdown(),
+		Sharpe:      stats.SharpRatio(0),
+		Trades:      len(stats.Transactions()),
+	}
+	result.Return, _ = stats.TotalEquityReturn()
+	return result, nil
+}
+
+// CrossRule returns a rule which is long while the fast indicator is above the slow one and
+// flat otherwise, e.g. CrossRule(func(v *VectorData) []float64 { return v.SMA(10) }, ...).
+func CrossRule(fast, slow func(v *VectorData) []float64) VectorRule {
+	return func(v *VectorData) ([]float64, error) {
+		f, s := fast(v), slow(v)
+		target := make([]float64, v.Len())
+		for i := range target {
+			switch {
+			case math.IsNaN(f[i]) || math.IsNaN(s[i]):
+				target[i] = math.NaN()
+			case f[i] > s[i]:
+				target[i] = 1
+			default:
+				target[i] = 0
+			}
+		}
+		return target, nil
+	}
+}
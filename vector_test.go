@@ -0,0 +1,201 @@
+package gobacktest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewVectorData(t *testing.T) {
+	stream := newStressStream(100, 101, 102)
+	stream = append(stream, &Bar{Event: Event{symbol: "OTHER.DE"}, Close: 50})
+
+	v := NewVectorData("TEST.DE", stream)
+	if !reflect.DeepEqual(v.Prices, []float64{100, 101, 102}) {
+		t.Errorf("NewVectorData(): expected prices %v, actual %v", []float64{100, 101, 102}, v.Prices)
+	}
+	if len(v.Times) != 3 || !v.Times[0].Equal(stream[0].Time()) {
+		t.Errorf("NewVectorData(): expected the times of the symbol, actual %v", v.Times)
+	}
+}
+
+func TestVectorDataIndicators(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(1, 2, 3, 4, 5))
+
+	// testCases is a table for testing the indicators of the vector data
+	var testCases = []struct {
+		msg    string
+		values []float64
+		exp    []float64
+	}{
+		{"sma of 3:", v.SMA(3), []float64{math.NaN(), math.NaN(), 2, 3, 4}},
+		{"sma of 1:", v.SMA(1), []float64{1, 2, 3, 4, 5}},
+		{"sma longer than the data:", v.SMA(6), []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}},
+		{"ema of 3:", v.EMA(3), []float64{math.NaN(), math.NaN(), 2, 3, 4}},
+	}
+
+	for _, tc := range testCases {
+		if !equalNaN(tc.values, tc.exp) {
+			t.Errorf("%v expected %v, actual %v", tc.msg, tc.exp, tc.values)
+		}
+	}
+}
+
+func TestVectorDataIndicatorCache(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(1, 2, 3))
+
+	var calls int
+	calc := func(prices []float64) []float64 {
+		calls++
+		return prices
+	}
+	v.Indicator("same", calc)
+	v.Indicator("same", calc)
+	if calls != 1 {
+		t.Errorf("Indicator(): expected the indicator to be computed once, actual %v", calls)
+	}
+
+	if &v.SMA(2)[0] != &v.SMA(2)[0] {
+		t.Errorf("SMA(): expected the cached indicator")
+	}
+}
+
+func TestRunVectorized(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(100, 110, 99, 99))
+
+	// testCases is a table for testing the evaluation of vector rules
+	var testCases = []struct {
+		msg       string
+		target    []float64
+		cost      VectorCost
+		expReturn float64
+		expDD     float64
+		expTrades int
+		expEquity []float64
+	}{
+		{"flat:", []float64{0, 0, 0, 0}, VectorCost{}, 0, 0, 0, []float64{1, 1, 1, 1}},
+		{"long from the first bar:", []float64{1, 1, 1, 1}, VectorCost{}, -0.01, -0.1, 1, []float64{1, 1.1, 0.99, 0.99}},
+		{"long for one bar:", []float64{1, 0, 0, 0}, VectorCost{}, 0.1, 0, 2, []float64{1, 1.1, 1.1, 1.1}},
+		{"short for one bar:", []float64{0, -1, 0, 0}, VectorCost{}, 0.1, 0, 2, []float64{1, 1, 1.1, 1.1}},
+		{"NaN keeps the exposure:", []float64{math.NaN(), 1, math.NaN(), math.NaN()}, VectorCost{}, -0.1, -0.1, 1, []float64{1, 1, 0.9, 0.9}},
+		{"commission on each trade:", []float64{1, 0, 0, 0}, VectorCost{Commission: 0.01}, 0.0781, -0.01, 2, []float64{0.99, 1.07811, 1.07811, 1.07811}},
+	}
+
+	for _, tc := range testCases {
+		target := tc.target
+		rule := func(v *VectorData) ([]float64, error) { return target, nil }
+
+		result, err := RunVectorized(v, rule, tc.cost, nil)
+		if err != nil {
+			t.Fatalf("%v RunVectorized(): unexpected error %v", tc.msg, err)
+		}
+		if !result.Vectorized {
+			t.Errorf("%v RunVectorized(): expected a vectorized result", tc.msg)
+		}
+		if result.Return != tc.expReturn || result.MaxDrawdown != tc.expDD || result.Trades != tc.expTrades {
+			t.Errorf("%v RunVectorized(): expected return %v, drawdown %v, trades %v, actual %v, %v, %v",
+				tc.msg, tc.expReturn, tc.expDD, tc.expTrades, result.Return, result.MaxDrawdown, result.Trades)
+		}
+		for i := range tc.expEquity {
+			if math.Abs(result.Equity[i]-tc.expEquity[i]) > 1e-9 {
+				t.Errorf("%v RunVectorized(): expected equity %v, actual %v", tc.msg, tc.expEquity, result.Equity)
+				break
+			}
+		}
+	}
+}
+
+func TestRunVectorizedInvalidRule(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(100, 110))
+
+	rule := func(v *VectorData) ([]float64, error) { return []float64{1}, nil }
+	if _, err := RunVectorized(v, rule, VectorCost{}, nil); err == nil {
+		t.Errorf("RunVectorized(): expected an error for a target of the wrong length")
+	}
+
+	rule = func(v *VectorData) ([]float64, error) { return nil, ErrNotVectorizable }
+	if _, err := RunVectorized(v, rule, VectorCost{}, nil); err != ErrNotVectorizable {
+		t.Errorf("RunVectorized(): expected ErrNotVectorizable without a fallback, actual %v", err)
+	}
+}
+
+func TestRunVectorizedFallback(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(100, 100, 100))
+
+	fallback := func() (*Backtest, error) {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100, 100))
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(&countingStrategy{Strategy: NewStrategy("counting")})
+		return test, nil
+	}
+	rule := func(v *VectorData) ([]float64, error) { return nil, ErrNotVectorizable }
+
+	result, err := RunVectorized(v, rule, VectorCost{}, fallback)
+	if err != nil {
+		t.Fatalf("RunVectorized(): unexpected error %v", err)
+	}
+	if result.Vectorized || result.Equity != nil {
+		t.Errorf("RunVectorized(): expected a result of the event loop, actual %+v", result)
+	}
+	if result.Trades != 3 {
+		t.Errorf("RunVectorized(): expected 3 trades of the event loop, actual %v", result.Trades)
+	}
+}
+
+func TestCrossRule(t *testing.T) {
+	v := NewVectorData("TEST.DE", newStressStream(1, 2, 3, 2, 1))
+
+	rule := CrossRule(
+		func(v *VectorData) []float64 { return v.SMA(1) },
+		func(v *VectorData) []float64 { return v.SMA(2) },
+	)
+	target, err := rule(v)
+	if err != nil {
+		t.Fatalf("CrossRule(): unexpected error %v", err)
+	}
+
+	var exp = []float64{math.NaN(), 1, 1, 0, 0}
+	if !equalNaN(target, exp) {
+		t.Errorf("CrossRule(): expected %v, actual %v", exp, target)
+	}
+}
+
+func BenchmarkRunVectorized(b *testing.B) {
+	closes := make([]float64, 5000)
+	for i := range closes {
+		closes[i] = 100 + 10*math.Sin(float64(i)/50)
+	}
+	v := NewVectorData("TEST.DE", newStressStream(closes...))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		short, long := 5+i%20, 50+i%100
+		rule := CrossRule(
+			func(v *VectorData) []float64 { return v.SMA(short) },
+			func(v *VectorData) []float64 { return v.SMA(long) },
+		)
+		if _, err := RunVectorized(v, rule, VectorCost{}, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// equalNaN compares two slices, with NaN values being equal.
+func equalNaN(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.IsNaN(a[i]) && math.IsNaN(b[i]) {
+			continue
+		}
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package gobacktest
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// EquityUpdater receives the equity of the portfolio on every data event.
+type EquityUpdater interface {
+	UpdateEquity(time.Time, float64)
+}
+
+// PositionRebalancer returns orders to adjust the existing positions of a portfolio.
+type PositionRebalancer interface {
+	Rebalance(map[string]Position, time.Time) []*Order
+}
+
+// Rebalancer returns orders to rebalance a portfolio on a data event.
+type Rebalancer interface {
+	Rebalance(DataEvent) []*Order
+}
+
+// VolatilityTarget is a size handler overlay, which scales the qty of the underlying size handler
+// to hit a target annualised volatility of the portfolio, e.g. 0.1 for 10%.
+// The volatility is estimated from the daily equity returns of the Lookback period
+// and the scale is recalculated every Schedule, e.g. weekly. Existing positions are
+// adjusted to a new scale via Rebalance.
+type VolatilityTarget struct {
+	SizeHandler
+	Target         float64
+	Lookback       int           // number of daily returns, defaults to 20
+	PeriodsPerYear float64       // defaults to 252
+	MaxLeverage    float64       // max scale, defaults to 1
+	Schedule       time.Duration // interval between recalculations, 0 recalculates daily
+	Instruments    *InstrumentRegistry
+	equity         []float64
+	lastDay        time.Time
+	lastCalc       time.Time
+	scale          float64
+	applied        float64
+}
+
+// SizeOrder sizes an order with the underlying size handler and scales its qty.
+func (v *VolatilityTarget) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler) (*Order, error) {
+	o, err := v.SizeHandler.SizeOrder(order, data, pf)
+	if err != nil || o == nil {
+		return o, err
+	}
+
+	// exit orders close the complete position
+	if order.Direction() == EXT {
+		return o, nil
+	}
+
+	o.SetQty(v.Instruments.Get(o.Symbol()).LotSize.Round(o.Qty() * v.Scale()))
+	return o, nil
+}
+
+// Scale returns the current scale of the exposure.
+func (v *VolatilityTarget) Scale() float64 {
+	if v.scale == 0 {
+		return 1
+	}
+	return v.scale
+}
+
+// UpdateEquity samples the equity once per day and recalculates the scale on schedule.
+func (v *VolatilityTarget) UpdateEquity(t time.Time, equity float64) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if !v.lastDay.IsZero() && !day.After(v.lastDay) {
+		// update the sample of the current day
+		if len(v.equity) > 0 {
+			v.equity[len(v.equity)-1] = equity
+		}
+		return
+	}
+	v.lastDay = day
+	v.equity = append(v.equity, equity)
+
+	// keep only the samples of the lookback period
+	if limit := v.lookback() + 1; len(v.equity) > limit {
+		v.equity = v.equity[len(v.equity)-limit:]
+	}
+
+	if !v.lastCalc.IsZero() && t.Sub(v.lastCalc) < v.Schedule {
+		return
+	}
+	v.lastCalc = t
+	v.scale = v.calcScale()
+}
+
+// Rebalance returns orders to adjust all positions to a changed scale.
+func (v *VolatilityTarget) Rebalance(positions map[string]Position, t time.Time) []*Order {
+	scale := v.Scale()
+	if v.applied == 0 {
+		v.applied = scale
+		return nil
+	}
+	if scale == v.applied {
+		return nil
+	}
+
+	var orders []*Order
+	for symbol, pos := range positions {
+		if pos.qty == 0 {
+			continue
+		}
+
+		target := v.Instruments.Get(symbol).LotSize.Round(pos.qty * scale / v.applied)
+		diff := target - pos.qty
+		if diff == 0 {
+			continue
+		}
+
+		order := &Order{
+			Event:     Event{timestamp: t, symbol: symbol},
+			orderType: MarketOrder,
+			direction: BOT,
+			qty:       diff,
+		}
+		if diff < 0 {
+			order.direction = SLD
+			order.qty = -diff
+		}
+		orders = append(orders, order)
+	}
+
+	v.applied = scale
+	return orders
+}
+
+// Reset implements Reseter to remove all equity samples.
+func (v *VolatilityTarget) Reset() error {
+	v.equity = nil
+	v.lastDay = time.Time{}
+	v.lastCalc = time.Time{}
+	v.scale = 0
+	v.applied = 0
+	return nil
+}
+
+// calcScale returns the target volatility divided by the realised volatility, capped by the max leverage.
+func (v *VolatilityTarget) calcScale() float64 {
+	if len(v.equity) < 3 {
+		return v.Scale()
+	}
+
+	var returns []float64
+	for i := 1; i < len(v.equity); i++ {
+		if v.equity[i-1] == 0 {
+			continue
+		}
+		returns = append(returns, v.equity[i]/v.equity[i-1]-1)
+	}
+
+	periods := v.PeriodsPerYear
+	if periods <= 0 {
+		periods = 252
+	}
+	vol := stat.StdDev(returns, nil) * math.Sqrt(periods)
+
+	leverage := v.MaxLeverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	if vol == 0 || math.IsNaN(vol) {
+		return leverage
+	}
+
+	scale := v.Target / vol
+	if scale > leverage {
+		scale = leverage
+	}
+	return math.Round(scale*math.Pow10(DP)) / math.Pow10(DP)
+}
+
+// lookback returns the number of returns for the volatility estimate.
+func (v *VolatilityTarget) lookback() int {
+	if v.Lookback <= 0 {
+		return 20
+	}
+	return v.Lookback
+}
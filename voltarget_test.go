@@ -0,0 +1,80 @@
+package gobacktest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVolatilityTargetScale(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	// testCases is a table for testing the scale of the volatility target
+	var testCases = []struct {
+		msg      string
+		vt       *VolatilityTarget
+		equity   []float64
+		expScale float64
+	}{
+		{"not enough samples:",
+			&VolatilityTarget{Target: 0.1},
+			[]float64{100, 101},
+			1,
+		},
+		{"no volatility uses max leverage:",
+			&VolatilityTarget{Target: 0.1, MaxLeverage: 2},
+			[]float64{100, 100, 100, 100},
+			2,
+		},
+		{"high volatility reduces exposure:",
+			&VolatilityTarget{Target: 0.1, PeriodsPerYear: 1},
+			[]float64{100, 110, 99, 108.9},
+			0.866,
+		},
+	}
+
+	for _, tc := range testCases {
+		for i, equity := range tc.equity {
+			tc.vt.UpdateEquity(day.AddDate(0, 0, i), equity)
+		}
+		if tc.vt.Scale() != tc.expScale {
+			t.Errorf("%v Scale(): \nexpected %v, \nactual   %v", tc.msg, tc.expScale, tc.vt.Scale())
+		}
+	}
+}
+
+func TestVolatilityTargetSizeOrder(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+
+	vt := &VolatilityTarget{SizeHandler: &Size{DefaultSize: 100, DefaultValue: 10000}, scale: 0.5}
+	order := &Order{Event: Event{timestamp: day, symbol: "TEST.DE"}, direction: BOT}
+
+	o, err := vt.SizeOrder(order, &Bar{Close: 10}, &Portfolio{})
+	if err != nil || o.Qty() != 50 {
+		t.Errorf("SizeOrder(): \nexpected qty %v, \nactual   %v %v", 50, o.Qty(), err)
+	}
+}
+
+func TestVolatilityTargetRebalance(t *testing.T) {
+	day, _ := time.Parse("2006-01-02", "2017-06-01")
+	positions := map[string]Position{
+		"A.DE": {symbol: "A.DE", qty: 100},
+		"B.DE": {symbol: "B.DE", qty: -40},
+	}
+
+	vt := &VolatilityTarget{scale: 1}
+	if orders := vt.Rebalance(positions, day); len(orders) != 0 {
+		t.Errorf("Rebalance(): expected no orders on first call, actual %v", len(orders))
+	}
+
+	vt.scale = 0.5
+	orders := vt.Rebalance(positions, day)
+	if len(orders) != 2 {
+		t.Fatalf("Rebalance(): expected 2 orders, actual %v", len(orders))
+	}
+	for _, o := range orders {
+		if (o.Symbol() == "A.DE" && (o.Direction() != SLD || o.Qty() != 50)) ||
+			(o.Symbol() == "B.DE" && (o.Direction() != BOT || o.Qty() != 20)) {
+			t.Errorf("Rebalance(): unexpected order %#v", o)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package gobacktest
+
+// WarmUpper declares the number of data events per symbol a strategy needs before
+// its signals are valid, e.g. 200 bars for a 200 day moving average.
+type WarmUpper interface {
+	WarmUp() int
+}
+
+// WarmUp returns the warm-up period of the strategy in data events per symbol.
+func (s *Strategy) WarmUp() int {
+	return s.warmUp
+}
+
+// SetWarmUp sets the warm-up period of the strategy in data events per symbol.
+// During the warm-up the strategy receives all data events, but its signals are discarded.
+func (s *Strategy) SetWarmUp(bars int) *Strategy {
+	s.warmUp = bars
+	return s
+}
+
+// warmingUp counts the data events of a symbol and returns true,
+// while the warm-up period of the strategy is not satisfied.
+func (t *Backtest) warmingUp(event DataEvent) bool {
+	w, ok := t.strategy.(WarmUpper)
+	if !ok || w.WarmUp() <= 0 {
+		return false
+	}
+
+	// check for nil map, else initialise the map
+	if t.warmUpCount == nil {
+		t.warmUpCount = make(map[string]int)
+	}
+	t.warmUpCount[event.Symbol()]++
+
+	return t.warmUpCount[event.Symbol()] < w.WarmUp()
+}
@@ -0,0 +1,53 @@
+package gobacktest
+
+import (
+	"testing"
+)
+
+// countingStrategy buys on every data event and counts the received events.
+type countingStrategy struct {
+	*Strategy
+	events int
+}
+
+func (s *countingStrategy) OnData(event DataEvent) ([]SignalEvent, error) {
+	s.events++
+	return []SignalEvent{&Signal{Event: Event{timestamp: event.Time(), symbol: event.Symbol()}, direction: BOT}}, nil
+}
+
+func TestBacktestWarmUp(t *testing.T) {
+	// testCases is a table for testing the warm-up period of a strategy
+	var testCases = []struct {
+		msg      string
+		warmUp   int
+		expFills int
+	}{
+		{"no warm-up:", 0, 5},
+		{"warm-up of three bars:", 3, 3},
+		{"warm-up longer than the data:", 10, 0},
+	}
+
+	for _, tc := range testCases {
+		data := &Data{}
+		data.SetStream(newStressStream(100, 100, 100, 100, 100))
+
+		strategy := &countingStrategy{Strategy: NewStrategy("counting")}
+		strategy.SetWarmUp(tc.warmUp)
+
+		test := New()
+		test.SetData(data)
+		test.SetStrategy(strategy)
+
+		if err := test.Run(); err != nil {
+			t.Fatalf("%v Run(): unexpected error %v", tc.msg, err)
+		}
+
+		if strategy.events != 5 {
+			t.Errorf("%v Run(): expected the strategy to receive 5 events, actual %v", tc.msg, strategy.events)
+		}
+		fills := len(test.portfolio.(*Portfolio).transactions)
+		if fills != tc.expFills {
+			t.Errorf("%v Run(): expected %v fills, actual %v", tc.msg, tc.expFills, fills)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gobacktest
+
+import (
+	"errors"
+	"math"
+)
+
+// WeightSize is a size handler, which sizes an order to move the position of the symbol to a
+// target weight of the equity. The target weight is the strength of the order, e.g. 0.05 for
+// a long position of 5% or -0.05 for a short position of 5%, as set by the signal, a weight of 0
+// closes the position. The order buys or sells the difference to the current position,
+// exit orders close the position.
+type WeightSize struct {
+	Instruments *InstrumentRegistry
+}
+
+// SizeOrder sets the direction and qty of an order from the target weight.
+func (w *WeightSize) SizeOrder(order OrderEvent, data DataEvent, pf PortfolioHandler) (*Order, error) {
+	o := order.(*Order)
+
+	pos, _ := pf.IsInvested(o.Symbol())
+	if o.Direction() == EXT {
+		if pos.qty == 0 {
+			return o, errors.New("cannot exit order: no position to symbol in portfolio,")
+		}
+		o.SetDirection(SLD)
+		if pos.qty < 0 {
+			o.SetDirection(BOT)
+		}
+		o.SetQty(math.Abs(pos.qty))
+		return o, nil
+	}
+
+	weight := o.Strength()
+	if !o.HasStrength() || data == nil || data.Price() == 0 {
+		return nil, errors.New("cannot size order: no target weight or price,")
+	}
+
+	instrument := w.Instruments.Get(o.Symbol())
+	target := pf.Value() * weight / (data.Price() * instrument.ContractMultiplier())
+	diff := instrument.LotSize.Round(target - pos.qty)
+	if diff == 0 {
+		return nil, errors.New("cannot size order: position at the target weight,")
+	}
+
+	o.SetDirection(BOT)
+	if diff < 0 {
+		o.SetDirection(SLD)
+	}
+	o.SetQty(math.Abs(diff))
+	return o, nil
+}
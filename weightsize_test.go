@@ -0,0 +1,55 @@
+package gobacktest
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWeightSizeSizeOrder(t *testing.T) {
+	// testCases is a table for testing the sizing to target weights
+	var testCases = []struct {
+		msg      string
+		qty      float64
+		order    *Order
+		expDir   Direction
+		expQty   float64
+		expOrder bool
+	}{
+		{"long from no position:", 0,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0.1, hasStrength: true}, BOT, 100, true},
+		{"short from no position:", 0,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, strength: -0.05, hasStrength: true}, SLD, 50, true},
+		{"increase a long position:", 50,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0.1, hasStrength: true}, BOT, 50, true},
+		{"reduce a long position:", 150,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0.1, hasStrength: true}, SLD, 50, true},
+		{"reverse a long position:", 100,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, strength: -0.1, hasStrength: true}, SLD, 200, true},
+		{"reduce a short position:", -100,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: SLD, strength: -0.05, hasStrength: true}, BOT, 50, true},
+		{"exit a short position:", -30,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: EXT}, BOT, 30, true},
+		{"position at the target weight:", 100,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0.1, hasStrength: true}, BOT, 0, false},
+		{"zero target weight closes the position:", 100,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT, strength: 0, hasStrength: true}, SLD, 100, true},
+		{"no target weight:", 0,
+			&Order{Event: Event{symbol: "TEST.DE"}, direction: BOT}, BOT, 0, false},
+	}
+
+	for _, tc := range testCases {
+		pf := &Portfolio{cash: 100000 - tc.qty*100}
+		if tc.qty != 0 {
+			pf.holdings = map[string]Position{"TEST.DE": {symbol: "TEST.DE", qty: tc.qty, marketValue: math.Abs(tc.qty) * 100}}
+		}
+
+		order, err := (&WeightSize{}).SizeOrder(tc.order, &Bar{Close: 100}, pf)
+		if (order != nil) != tc.expOrder || (err == nil) != tc.expOrder {
+			t.Errorf("%v SizeOrder(): expected an order %v, actual %v %v", tc.msg, tc.expOrder, order, err)
+			continue
+		}
+		if order != nil && (order.Direction() != tc.expDir || order.Qty() != tc.expQty) {
+			t.Errorf("%v SizeOrder(): expected %v %v, actual %v %v", tc.msg, tc.expDir, tc.expQty, order.Direction(), order.Qty())
+		}
+	}
+}